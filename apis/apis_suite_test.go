@@ -19,6 +19,7 @@ import (
 const (
 	defaultServerURL = "https://api.example.org"
 	jsonHeader       = "application/json"
+	authHeader       = "Bearer my-token"
 )
 
 var (
@@ -90,6 +91,30 @@ func expectUnprocessableEntityError(detail string) {
 		}`, detail))
 }
 
+func expectUnauthorizedError() {
+	expectJSONResponse(http.StatusUnauthorized, `{
+			"errors": [
+				{
+					"title": "CF-NotAuthenticated",
+					"detail": "No auth token was given, but authentication is required for this endpoint",
+					"code": 10002
+				}
+			]
+		}`)
+}
+
+func expectBadQueryParameterError(detail string) {
+	expectJSONResponse(http.StatusBadRequest, fmt.Sprintf(`{
+			"errors": [
+				{
+					"title": "CF-BadQueryParameter",
+					"detail": %q,
+					"code": 10005
+				}
+			]
+		}`, "The query parameter is invalid: "+detail))
+}
+
 func expectBadRequestError() {
 	expectJSONResponse(http.StatusBadRequest, `{
         "errors": [
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"code.cloudfoundry.org/cf-k8s-controllers/webhooks/workloads"
 
@@ -14,9 +16,11 @@ import (
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 
+	"github.com/go-http-utils/headers"
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -24,39 +28,53 @@ import (
 const (
 	AppCreateEndpoint            = "/v3/apps"
 	AppGetEndpoint               = "/v3/apps/{guid}"
+	AppPatchEndpoint             = "/v3/apps/{guid}"
+	AppDeleteEndpoint            = "/v3/apps/{guid}"
+	AppGetEnvVarsEndpoint        = "/v3/apps/{guid}/environment_variables"
+	AppPatchEnvVarsEndpoint      = "/v3/apps/{guid}/environment_variables"
+	AppGetEnvEndpoint            = "/v3/apps/{guid}/env"
 	AppListEndpoint              = "/v3/apps"
 	AppSetCurrentDropletEndpoint = "/v3/apps/{guid}/relationships/current_droplet"
 	AppGetProcessesEndpoint      = "/v3/apps/{guid}/processes"
+	AppScaleProcessEndpoint      = "/v3/apps/{guid}/processes/{type}/actions/scale"
+	AppGetProcessStatsEndpoint   = "/v3/apps/{guid}/processes/{type}/stats"
+	AppProcessInstanceEndpoint   = "/v3/apps/{guid}/processes/{type}/instances/{index}"
 	AppGetRoutesEndpoint         = "/v3/apps/{guid}/routes"
 	AppStartEndpoint             = "/v3/apps/{guid}/actions/start"
 	AppStopEndpoint              = "/v3/apps/{guid}/actions/stop"
+	AppRestartEndpoint           = "/v3/apps/{guid}/actions/restart"
 	invalidDropletMsg            = "Unable to assign current droplet. Ensure the droplet exists and belongs to this app."
-
-	AppStartedState = "STARTED"
-	AppStoppedState = "STOPPED"
 )
 
 //counterfeiter:generate -o fake -fake-name CFAppRepository . CFAppRepository
 type CFAppRepository interface {
 	FetchApp(context.Context, client.Client, string) (repositories.AppRecord, error)
-	FetchAppList(context.Context, client.Client) ([]repositories.AppRecord, error)
+	FetchAppList(context.Context, client.Client, repositories.AppListMessage) ([]repositories.AppRecord, int, error)
 	FetchNamespace(context.Context, client.Client, string) (repositories.SpaceRecord, error)
 	CreateAppEnvironmentVariables(context.Context, client.Client, repositories.AppEnvVarsRecord) (repositories.AppEnvVarsRecord, error)
+	FetchAppEnvVars(context.Context, client.Client, repositories.AppRecord) (repositories.AppEnvVarsRecord, error)
+	PatchAppEnvVars(context.Context, client.Client, repositories.PatchAppEnvVarsMessage) (repositories.AppEnvVarsRecord, error)
 	CreateApp(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
 	SetCurrentDroplet(context.Context, client.Client, repositories.SetCurrentDropletMessage) (repositories.CurrentDropletRecord, error)
-	SetAppDesiredState(context.Context, client.Client, repositories.SetAppDesiredStateMessage) (repositories.AppRecord, error)
+	StartApp(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
+	StopApp(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
+	RestartApp(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
+	PatchApp(context.Context, client.Client, repositories.PatchAppMessage) (repositories.AppRecord, error)
+	DeleteApp(context.Context, client.Client, repositories.DeleteAppMessage) error
 }
 
 type AppHandler struct {
-	logger      logr.Logger
-	serverURL   url.URL
-	appRepo     CFAppRepository
-	dropletRepo CFDropletRepository
-	processRepo CFProcessRepository
-	routeRepo   CFRouteRepository
-	domainRepo  CFDomainRepository
-	buildClient ClientBuilder
-	k8sConfig   *rest.Config // TODO: this would be global for all requests, not what we want
+	logger       logr.Logger
+	serverURL    url.URL
+	appRepo      CFAppRepository
+	dropletRepo  CFDropletRepository
+	processRepo  CFProcessRepository
+	routeRepo    CFRouteRepository
+	domainRepo   CFDomainRepository
+	jobRepo      CFJobRepository
+	revisionRepo CFRevisionRepository
+	buildClient  ClientBuilder
+	k8sConfig    *rest.Config
 }
 
 func NewAppHandler(
@@ -67,18 +85,22 @@ func NewAppHandler(
 	processRepo CFProcessRepository,
 	routeRepo CFRouteRepository,
 	domainRepo CFDomainRepository,
+	jobRepo CFJobRepository,
+	revisionRepo CFRevisionRepository,
 	buildClient ClientBuilder,
 	k8sConfig *rest.Config) *AppHandler {
 	return &AppHandler{
-		logger:      logger,
-		serverURL:   serverURL,
-		appRepo:     appRepo,
-		dropletRepo: dropletRepo,
-		processRepo: processRepo,
-		routeRepo:   routeRepo,
-		domainRepo:  domainRepo,
-		buildClient: buildClient,
-		k8sConfig:   k8sConfig,
+		logger:       logger,
+		serverURL:    serverURL,
+		appRepo:      appRepo,
+		dropletRepo:  dropletRepo,
+		processRepo:  processRepo,
+		routeRepo:    routeRepo,
+		domainRepo:   domainRepo,
+		jobRepo:      jobRepo,
+		revisionRepo: revisionRepo,
+		buildClient:  buildClient,
+		k8sConfig:    k8sConfig,
 	}
 }
 
@@ -89,12 +111,9 @@ func (h *AppHandler) appGetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	appGUID := vars["guid"]
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "AppGUID", appGUID)
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err, "AppGUID", appGUID)
 		return
 	}
 
@@ -133,12 +152,9 @@ func (h *AppHandler) appCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client")
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
 
@@ -208,23 +224,27 @@ func (h *AppHandler) appListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	appListMessage, err := parseAppListMessage(r)
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client")
-		writeUnknownErrorResponse(w)
+		h.logger.Info("Invalid app list query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
 		return
 	}
 
-	appList, err := h.appRepo.FetchAppList(ctx, client)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	appList, totalResults, err := h.appRepo.FetchAppList(ctx, client, appListMessage)
 	if err != nil {
 		h.logger.Error(err, "Failed to fetch app(s) from Kubernetes")
 		writeUnknownErrorResponse(w)
 		return
 	}
 
-	responseBody, err := json.Marshal(presenter.ForAppList(appList, h.serverURL))
+	responseBody, err := json.Marshal(presenter.ForAppList(appList, h.serverURL, newListPage(r, appListMessage.Page, totalResults)))
 	if err != nil {
 		h.logger.Error(err, "Failed to render response")
 		writeUnknownErrorResponse(w)
@@ -234,25 +254,57 @@ func (h *AppHandler) appListHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(responseBody)
 }
 
-func (h *AppHandler) appSetCurrentDropletHandler(w http.ResponseWriter, r *http.Request) {
+// parseAppListMessage reads the filters, ordering and page of an app list request
+func parseAppListMessage(r *http.Request) (repositories.AppListMessage, error) {
+	err := checkQueryParameters(r, "names", "guids", "space_guids", "organization_guids", "stacks", "order_by")
+	if err != nil {
+		return repositories.AppListMessage{}, err
+	}
+
+	orderBy, err := parseOrderBy(r, "created_at", "updated_at", "name", "state")
+	if err != nil {
+		return repositories.AppListMessage{}, err
+	}
+
+	labelSelector, err := parseLabelSelector(r)
+	if err != nil {
+		return repositories.AppListMessage{}, err
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		return repositories.AppListMessage{}, err
+	}
+
+	query := r.URL.Query()
+	return repositories.AppListMessage{
+		Names:         parseCommaSeparatedList(query.Get("names")),
+		GUIDs:         parseCommaSeparatedList(query.Get("guids")),
+		SpaceGUIDs:    parseCommaSeparatedList(query.Get("space_guids")),
+		OrgGUIDs:      parseCommaSeparatedList(query.Get("organization_guids")),
+		Stacks:        parseCommaSeparatedList(query.Get("stacks")),
+		LabelSelector: labelSelector,
+		OrderBy:       orderBy,
+		Page:          pageRequest,
+	}, nil
+}
+
+func (h *AppHandler) appPatchHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
 	appGUID := vars["guid"]
 
-	var payload payloads.AppSetCurrentDroplet
+	var payload payloads.AppPatch
 	rme := DecodeAndValidatePayload(r, &payload)
 	if rme != nil {
 		writeErrorResponse(w, rme)
 		return
 	}
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client")
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
 
@@ -267,37 +319,104 @@ func (h *AppHandler) appSetCurrentDropletHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	dropletGUID := payload.Data.GUID
-	droplet, err := h.dropletRepo.FetchDroplet(ctx, client, dropletGUID)
+	appName := app.Name
+	if payload.Name != nil {
+		appName = *payload.Name
+	}
+
+	app, err = h.appRepo.PatchApp(ctx, client, payload.ToMessage(app))
+	if err != nil {
+		switch {
+		case errors.As(err, new(repositories.NotFoundError)):
+			writeNotFoundErrorResponse(w, "App")
+		case errors.As(err, new(repositories.ConflictError)):
+			h.logger.Info("App was changed concurrently", "AppGUID", appGUID)
+			writeConflictError(w, "The app was changed by another request. Fetch it again and retry.")
+		case workloads.HasErrorCode(err, workloads.DuplicateAppError):
+			errorDetail := fmt.Sprintf("App with the name '%s' already exists.", appName)
+			h.logger.Info(errorDetail, "AppGUID", appGUID)
+			writeUniquenessError(w, errorDetail)
+		default:
+			h.logger.Error(err, "Error patching app", "AppGUID", appGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForApp(app, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *AppHandler) appDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	appGUID := vars["guid"]
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	app, err := h.appRepo.FetchApp(ctx, client, appGUID)
 	if err != nil {
 		if errors.As(err, new(repositories.NotFoundError)) {
-			writeUnprocessableEntityError(w, invalidDropletMsg)
+			writeNotFoundErrorResponse(w, "App")
 		} else {
-			h.logger.Error(err, "Error fetching droplet")
+			h.logger.Error(err, "Error fetching app")
 			writeUnknownErrorResponse(w)
 		}
 		return
 	}
 
-	if droplet.AppGUID != appGUID {
-		writeUnprocessableEntityError(w, invalidDropletMsg)
+	job, err := h.jobRepo.RunJob(ctx, repositories.AppDeleteJobOperation, app.SpaceGUID, func(ctx context.Context) error {
+		err := h.appRepo.DeleteApp(ctx, client, repositories.DeleteAppMessage{
+			AppGUID:   appGUID,
+			SpaceGUID: app.SpaceGUID,
+		})
+		if err != nil {
+			h.logger.Error(err, "Error deleting app", "AppGUID", appGUID)
+			return newJobError(err, "App")
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error(err, "Error starting app delete job", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
 		return
 	}
 
-	currentDroplet, err := h.appRepo.SetCurrentDroplet(ctx, client, repositories.SetCurrentDropletMessage{
-		AppGUID:     appGUID,
-		DropletGUID: dropletGUID,
-		SpaceGUID:   app.SpaceGUID,
-	})
+	w.Header().Set("Location", presenter.JobURL(job.GUID, h.serverURL))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AppHandler) appGetEnvVarsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	appGUID := mux.Vars(r)["guid"]
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
+
+	envVars, err := h.appRepo.FetchAppEnvVars(ctx, client, app)
 	if err != nil {
-		h.logger.Error(err, "Error setting current droplet")
+		h.logger.Error(err, "Failed to fetch app environment variables", "AppGUID", appGUID)
 		writeUnknownErrorResponse(w)
 		return
 	}
 
-	responseBody, err := json.Marshal(presenter.ForCurrentDroplet(currentDroplet, h.serverURL))
-	if err != nil { // untested
-		h.logger.Error(err, "Failed to render response")
+	responseBody, err := json.Marshal(presenter.ForAppEnvVars(envVars, h.serverURL))
+	if err != nil {
+		h.logger.Error(err, "Failed to render response", "AppGUID", appGUID)
 		writeUnknownErrorResponse(w)
 		return
 	}
@@ -305,53 +424,66 @@ func (h *AppHandler) appSetCurrentDropletHandler(w http.ResponseWriter, r *http.
 	w.Write(responseBody)
 }
 
-func (h *AppHandler) appStartHandler(w http.ResponseWriter, r *http.Request) {
+func (h *AppHandler) appPatchEnvVarsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
+	appGUID := mux.Vars(r)["guid"]
 
-	vars := mux.Vars(r)
-	appGUID := vars["guid"]
+	var payload payloads.AppPatchEnvVars
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	envVars, err := h.appRepo.PatchAppEnvVars(ctx, client, payload.ToMessage(app))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "AppGUID", appGUID)
+		h.logger.Error(err, "Failed to patch app environment variables", "AppGUID", appGUID)
 		writeUnknownErrorResponse(w)
 		return
 	}
+	recordRevision(ctx, h.logger, h.revisionRepo, client, app.GUID, app.SpaceGUID)
 
-	app, err := h.appRepo.FetchApp(ctx, client, appGUID)
+	responseBody, err := json.Marshal(presenter.ForAppEnvVars(envVars, h.serverURL))
 	if err != nil {
-		switch err.(type) {
-		case repositories.NotFoundError:
-			h.logger.Info("App not found", "AppGUID", appGUID)
-			writeNotFoundErrorResponse(w, "App")
-			return
-		default:
-			h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
-			writeUnknownErrorResponse(w)
-			return
-		}
+		h.logger.Error(err, "Failed to render response", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
 	}
-	if app.DropletGUID == "" {
-		h.logger.Info("App droplet not set before start", "AppGUID", appGUID)
-		writeUnprocessableEntityError(w, "Assign a droplet before starting this app.")
+
+	w.Write(responseBody)
+}
+
+func (h *AppHandler) appGetEnvHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	appGUID := mux.Vars(r)["guid"]
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
 		return
 	}
 
-	app, err = h.appRepo.SetAppDesiredState(ctx, client, repositories.SetAppDesiredStateMessage{
-		AppGUID:      app.GUID,
-		SpaceGUID:    app.SpaceGUID,
-		DesiredState: AppStartedState,
-	})
+	envVars, err := h.appRepo.FetchAppEnvVars(ctx, client, app)
 	if err != nil {
-		h.logger.Error(err, "Failed to update app in Kubernetes", "AppGUID", appGUID)
+		h.logger.Error(err, "Failed to fetch app environment variables", "AppGUID", appGUID)
 		writeUnknownErrorResponse(w)
 		return
 	}
 
-	responseBody, err := json.Marshal(presenter.ForApp(app, h.serverURL))
+	routes, err := h.lookupAppRouteAndDomainList(ctx, client, app.GUID, app.SpaceGUID, nil)
+	if err != nil {
+		h.logger.Error(err, "Failed to fetch route or domains from Kubernetes", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForAppEnv(app, envVars, routes, h.serverURL))
 	if err != nil {
 		h.logger.Error(err, "Failed to render response", "AppGUID", appGUID)
 		writeUnknownErrorResponse(w)
@@ -361,43 +493,136 @@ func (h *AppHandler) appStartHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(responseBody)
 }
 
-func (h *AppHandler) appStopHandler(w http.ResponseWriter, r *http.Request) {
+// clientAndApp builds a client for the user of r and fetches the app with it. The error response has been written
+// when ok is false.
+func (h *AppHandler) clientAndApp(w http.ResponseWriter, r *http.Request, appGUID string) (client.Client, repositories.AppRecord, bool) {
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "AppGUID", appGUID)
+		return nil, repositories.AppRecord{}, false
+	}
+
+	app, err := h.appRepo.FetchApp(r.Context(), client, appGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("App not found", "AppGUID", appGUID)
+			writeNotFoundErrorResponse(w, "App")
+		} else {
+			h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return nil, repositories.AppRecord{}, false
+	}
+
+	return client, app, true
+}
+
+func (h *AppHandler) appSetCurrentDropletHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
-
 	vars := mux.Vars(r)
 	appGUID := vars["guid"]
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	var payload payloads.AppSetCurrentDroplet
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "AppGUID", appGUID)
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
 
 	app, err := h.appRepo.FetchApp(ctx, client, appGUID)
 	if err != nil {
-		switch err.(type) {
-		case repositories.NotFoundError:
-			h.logger.Info("App not found", "AppGUID", appGUID)
+		if errors.As(err, new(repositories.NotFoundError)) {
 			writeNotFoundErrorResponse(w, "App")
-			return
-		default:
-			h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
+		} else {
+			h.logger.Error(err, "Error fetching app")
 			writeUnknownErrorResponse(w)
-			return
 		}
+		return
+	}
+
+	dropletGUID := payload.Data.GUID
+	droplet, err := h.dropletRepo.FetchDroplet(ctx, client, dropletGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			writeUnprocessableEntityError(w, invalidDropletMsg)
+		} else {
+			h.logger.Error(err, "Error fetching droplet")
+			writeUnknownErrorResponse(w)
+		}
+		return
+	}
+
+	if droplet.AppGUID != appGUID {
+		writeUnprocessableEntityError(w, invalidDropletMsg)
+		return
 	}
 
-	app, err = h.appRepo.SetAppDesiredState(ctx, client, repositories.SetAppDesiredStateMessage{
-		AppGUID:      app.GUID,
-		SpaceGUID:    app.SpaceGUID,
-		DesiredState: AppStoppedState,
+	currentDroplet, err := h.appRepo.SetCurrentDroplet(ctx, client, repositories.SetCurrentDropletMessage{
+		AppGUID:     appGUID,
+		DropletGUID: dropletGUID,
+		SpaceGUID:   app.SpaceGUID,
 	})
 	if err != nil {
-		h.logger.Error(err, "Failed to update app in Kubernetes", "AppGUID", appGUID)
+		h.logger.Error(err, "Error setting current droplet")
+		writeUnknownErrorResponse(w)
+		return
+	}
+	recordRevision(ctx, h.logger, h.revisionRepo, client, app.GUID, app.SpaceGUID)
+
+	responseBody, err := json.Marshal(presenter.ForCurrentDroplet(currentDroplet, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *AppHandler) appStartHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeAppState(w, r, "start", h.appRepo.StartApp)
+}
+
+func (h *AppHandler) appStopHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeAppState(w, r, "stop", h.appRepo.StopApp)
+}
+
+func (h *AppHandler) appRestartHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeAppState(w, r, "restart", h.appRepo.RestartApp)
+}
+
+// changeAppState fetches the app of the request, applies one of the state transitions of the AppRepo to it and
+// responds with the resulting app
+func (h *AppHandler) changeAppState(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	transition func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error),
+) {
+	w.Header().Set("Content-Type", "application/json")
+	appGUID := mux.Vars(r)["guid"]
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
+
+	app, err := transition(r.Context(), client, app)
+	if err != nil {
+		notStartableErr := new(repositories.AppNotStartableError)
+		if errors.As(err, notStartableErr) {
+			h.logger.Info("App cannot be started", "AppGUID", appGUID, "Reason", notStartableErr.Reason)
+			writeUnprocessableEntityError(w, notStartableErr.Reason)
+			return
+		}
+		h.logger.Error(err, "Failed to "+action+" app", "AppGUID", appGUID)
 		writeUnknownErrorResponse(w)
 		return
 	}
@@ -419,12 +644,37 @@ func (h *AppHandler) getProcessesForAppHandler(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	appGUID := vars["guid"]
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	err := checkQueryParameters(r, "guids", "types", "order_by")
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "AppGUID", appGUID)
-		writeUnknownErrorResponse(w)
+		h.logger.Info("Invalid process list query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	orderBy, err := parseOrderBy(r, "created_at", "updated_at")
+	if err != nil {
+		h.logger.Info("Invalid process list query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	labelSelector, err := parseLabelSelector(r)
+	if err != nil {
+		h.logger.Info("Invalid label selector", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "AppGUID", appGUID)
 		return
 	}
 
@@ -442,14 +692,19 @@ func (h *AppHandler) getProcessesForAppHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	processList, err := h.processRepo.FetchProcessesForApp(ctx, client, appGUID, app.SpaceGUID)
+	processList, err := h.processRepo.FetchProcessesForApp(ctx, client, appGUID, app.SpaceGUID, labelSelector)
 	if err != nil {
 		h.logger.Error(err, "Failed to fetch app Process(es) from Kubernetes")
 		writeUnknownErrorResponse(w)
 		return
 	}
 
-	responseBody, err := json.Marshal(presenter.ForProcessList(processList, h.serverURL, appGUID))
+	processList = filterProcesses(processList, parseCommaSeparatedList(r.URL.Query().Get("guids")), parseCommaSeparatedList(r.URL.Query().Get("types")))
+	orderProcesses(processList, orderBy)
+
+	start, end := pageRequest.Bounds(len(processList))
+	listPage := newListPage(r, pageRequest, len(processList))
+	responseBody, err := json.Marshal(presenter.ForProcessList(processList[start:end], h.serverURL, appGUID, listPage))
 	if err != nil { // untested
 		h.logger.Error(err, "Failed to render response")
 		writeUnknownErrorResponse(w)
@@ -459,19 +714,165 @@ func (h *AppHandler) getProcessesForAppHandler(w http.ResponseWriter, r *http.Re
 	w.Write(responseBody)
 }
 
-func (h *AppHandler) getRoutesForAppHandler(w http.ResponseWriter, r *http.Request) {
+func (h *AppHandler) appScaleProcessHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	appGUID := vars["guid"]
+	processType := vars["type"]
+
+	var payload payloads.ProcessScale
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
+
+	process, ok := h.appProcessOfType(w, r, client, app, processType)
+	if !ok {
+		return
+	}
+
+	process, err := h.processRepo.ScaleProcess(ctx, client, payload.ToMessage(process.GUID, process.SpaceGUID))
+	if err != nil {
+		writeScaleProcessError(h.logger, w, process.GUID, err)
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForProcess(process, h.serverURL))
+	if err != nil {
+		h.logger.Error(err, "Failed to render response", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *AppHandler) appGetProcessStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	appGUID := vars["guid"]
+	processType := vars["type"]
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
+
+	process, ok := h.appProcessOfType(w, r, client, app, processType)
+	if !ok {
+		return
+	}
+
+	writeProcessStats(ctx, h.logger, w, h.processRepo, client, process)
+}
+
+func (h *AppHandler) appTerminateProcessInstanceHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
 	vars := mux.Vars(r)
 	appGUID := vars["guid"]
+	processType := vars["type"]
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	process, ok := h.appProcessOfType(w, r, client, app, processType)
+	if !ok {
+		return
+	}
+
+	terminateProcessInstance(ctx, h.logger, w, h.processRepo, client, process, vars["index"])
+}
+
+// appProcessOfType fetches the process of app with the given type. When it cannot, it writes the error response
+// and returns false.
+func (h *AppHandler) appProcessOfType(w http.ResponseWriter, r *http.Request, client client.Client, app repositories.AppRecord, processType string) (repositories.ProcessRecord, bool) {
+	processList, err := h.processRepo.FetchProcessesForApp(r.Context(), client, app.GUID, app.SpaceGUID, nil)
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "AppGUID", appGUID)
+		h.logger.Error(err, "Failed to fetch app Process(es) from Kubernetes", "AppGUID", app.GUID)
 		writeUnknownErrorResponse(w)
+		return repositories.ProcessRecord{}, false
+	}
+
+	processList = filterProcesses(processList, nil, []string{processType})
+	if len(processList) == 0 {
+		h.logger.Info("Process not found", "AppGUID", app.GUID, "ProcessType", processType)
+		writeNotFoundErrorResponse(w, "Process")
+		return repositories.ProcessRecord{}, false
+	}
+
+	return processList[0], true
+}
+
+// filterProcesses keeps the processes that match the guids and types filters. Empty filters match every process.
+func filterProcesses(processes []repositories.ProcessRecord, guids, types []string) []repositories.ProcessRecord {
+	filtered := []repositories.ProcessRecord{}
+	for _, process := range processes {
+		if matchesFilter(guids, process.GUID) && matchesFilter(types, process.Type) {
+			filtered = append(filtered, process)
+		}
+	}
+	return filtered
+}
+
+// orderProcesses sorts processes by their created_at or updated_at timestamp, as named by orderBy
+func orderProcesses(processes []repositories.ProcessRecord, orderBy string) {
+	if orderBy == "" {
+		return
+	}
+
+	field := strings.TrimPrefix(orderBy, "-")
+	timestamp := func(i int) string {
+		if field == "updated_at" {
+			return processes[i].UpdatedAt
+		}
+		return processes[i].CreatedAt
+	}
+	sort.SliceStable(processes, func(i, j int) bool {
+		if field != orderBy {
+			return timestamp(j) < timestamp(i)
+		}
+		return timestamp(i) < timestamp(j)
+	})
+}
+
+func (h *AppHandler) getRoutesForAppHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	appGUID := vars["guid"]
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	labelSelector, err := parseLabelSelector(r)
+	if err != nil {
+		h.logger.Info("Invalid label selector", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "AppGUID", appGUID)
 		return
 	}
 
@@ -489,14 +890,16 @@ func (h *AppHandler) getRoutesForAppHandler(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	routes, err := h.lookupAppRouteAndDomainList(ctx, client, app.GUID, app.SpaceGUID)
+	routes, err := h.lookupAppRouteAndDomainList(ctx, client, app.GUID, app.SpaceGUID, labelSelector)
 	if err != nil {
 		h.logger.Error(err, "Failed to fetch route or domains from Kubernetes")
 		writeUnknownErrorResponse(w)
 		return
 	}
 
-	responseBody, err := json.Marshal(presenter.ForAppRouteList(routes, h.serverURL, app.GUID))
+	start, end := pageRequest.Bounds(len(routes))
+	listPage := newListPage(r, pageRequest, len(routes))
+	responseBody, err := json.Marshal(presenter.ForAppRouteList(routes[start:end], h.serverURL, app.GUID, listPage))
 	if err != nil {
 		h.logger.Error(err, "Failed to render response")
 		writeUnknownErrorResponse(w)
@@ -506,9 +909,9 @@ func (h *AppHandler) getRoutesForAppHandler(w http.ResponseWriter, r *http.Reque
 	w.Write(responseBody)
 }
 
-func (h *AppHandler) lookupAppRouteAndDomainList(ctx context.Context, client client.Client, appGUID, spaceGUID string) ([]repositories.RouteRecord, error) {
+func (h *AppHandler) lookupAppRouteAndDomainList(ctx context.Context, client client.Client, appGUID, spaceGUID string, labelSelector labels.Selector) ([]repositories.RouteRecord, error) {
 
-	routeRecords, err := h.routeRepo.FetchRoutesForApp(ctx, client, appGUID, spaceGUID)
+	routeRecords, err := h.routeRepo.FetchRoutesForApp(ctx, client, appGUID, spaceGUID, labelSelector)
 	if err != nil {
 		return []repositories.RouteRecord{}, err
 	}
@@ -520,9 +923,18 @@ func (h *AppHandler) RegisterRoutes(router *mux.Router) {
 	router.Path(AppGetEndpoint).Methods("GET").HandlerFunc(h.appGetHandler)
 	router.Path(AppListEndpoint).Methods("GET").HandlerFunc(h.appListHandler)
 	router.Path(AppCreateEndpoint).Methods("POST").HandlerFunc(h.appCreateHandler)
+	router.Path(AppPatchEndpoint).Methods("PATCH").HandlerFunc(h.appPatchHandler)
+	router.Path(AppDeleteEndpoint).Methods("DELETE").HandlerFunc(h.appDeleteHandler)
+	router.Path(AppGetEnvVarsEndpoint).Methods("GET").HandlerFunc(h.appGetEnvVarsHandler)
+	router.Path(AppPatchEnvVarsEndpoint).Methods("PATCH").HandlerFunc(h.appPatchEnvVarsHandler)
+	router.Path(AppGetEnvEndpoint).Methods("GET").HandlerFunc(h.appGetEnvHandler)
 	router.Path(AppSetCurrentDropletEndpoint).Methods("PATCH").HandlerFunc(h.appSetCurrentDropletHandler)
 	router.Path(AppStartEndpoint).Methods("POST").HandlerFunc(h.appStartHandler)
 	router.Path(AppStopEndpoint).Methods("POST").HandlerFunc(h.appStopHandler)
+	router.Path(AppRestartEndpoint).Methods("POST").HandlerFunc(h.appRestartHandler)
 	router.Path(AppGetProcessesEndpoint).Methods("GET").HandlerFunc(h.getProcessesForAppHandler)
+	router.Path(AppScaleProcessEndpoint).Methods("POST").HandlerFunc(h.appScaleProcessHandler)
+	router.Path(AppGetProcessStatsEndpoint).Methods("GET").HandlerFunc(h.appGetProcessStatsHandler)
+	router.Path(AppProcessInstanceEndpoint).Methods("DELETE").HandlerFunc(h.appTerminateProcessInstanceHandler)
 	router.Path(AppGetRoutesEndpoint).Methods("GET").HandlerFunc(h.getRoutesForAppHandler)
 }
@@ -1,6 +1,7 @@
 package apis_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,11 +10,13 @@ import (
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -32,6 +35,8 @@ var _ = Describe("AppHandler", func() {
 		processRepo   *fake.CFProcessRepository
 		routeRepo     *fake.CFRouteRepository
 		domainRepo    *fake.CFDomainRepository
+		jobRepo       *fake.CFJobRepository
+		revisionRepo  *fake.CFRevisionRepository
 		clientBuilder *fake.ClientBuilder
 	)
 
@@ -41,6 +46,8 @@ var _ = Describe("AppHandler", func() {
 		processRepo = new(fake.CFProcessRepository)
 		routeRepo = new(fake.CFRouteRepository)
 		domainRepo = new(fake.CFDomainRepository)
+		jobRepo = new(fake.CFJobRepository)
+		revisionRepo = new(fake.CFRevisionRepository)
 		clientBuilder = new(fake.ClientBuilder)
 
 		apiHandler := NewAppHandler(
@@ -51,6 +58,8 @@ var _ = Describe("AppHandler", func() {
 			processRepo,
 			routeRepo,
 			domainRepo,
+			jobRepo,
+			revisionRepo,
 			clientBuilder.Spy,
 			&rest.Config{},
 		)
@@ -82,6 +91,26 @@ var _ = Describe("AppHandler", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		When("the app has reserved annotations", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{
+					GUID:      appGUID,
+					SpaceGUID: spaceGUID,
+					Labels:    map[string]string{"env": "prod"},
+					Annotations: map[string]string{
+						"example.org/contact":               "jane",
+						"cloudfoundry.org/task-sequence-id": "4",
+						"cloudfoundry.org/revision-version": "2",
+					},
+				}, nil)
+			})
+
+			It("leaves them out of the response", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(rr.Body.String()).To(ContainSubstring(`"metadata":{"labels":{"env":"prod"},"annotations":{"example.org/contact":"jane"}}`))
+			})
+		})
+
 		When("on the happy path", func() {
 			It("returns status 200 OK", func() {
 				Expect(rr.Code).To(Equal(http.StatusOK), "Matching HTTP response code:")
@@ -372,6 +401,21 @@ var _ = Describe("AppHandler", func() {
 		})
 
 		When("the namespace exists and app does not exist and", func() {
+			When("the app has a docker lifecycle", func() {
+				BeforeEach(func() {
+					queuePostRequest(`{
+						"name": "` + testAppName + `",
+						"lifecycle": { "type": "docker", "data": {} },
+						"relationships": { "space": { "data": { "guid": "` + spaceGUID + `" } } }
+					}`)
+				})
+
+				It("returns an error, as docker lifecycles are not supported", func() {
+					expectUnprocessableEntityError("Feature Disabled: diego_docker")
+					Expect(appRepo.CreateAppCallCount()).To(Equal(0))
+				})
+			})
+
 			When("a plain POST test app request is sent without env vars or metadata", func() {
 				BeforeEach(func() {
 					appRepo.CreateAppReturns(repositories.AppRecord{
@@ -565,6 +609,20 @@ var _ = Describe("AppHandler", func() {
 					Expect(createAppRecord.Annotations).To(Equal(testAnnotations))
 				})
 			})
+
+			When("a POST test app request is sent with a reserved annotation", func() {
+				BeforeEach(func() {
+					requestBody := initializeCreateAppRequestBody(testAppName, spaceGUID, nil, nil, map[string]string{
+						"cloudfoundry.org/revision-version": "100",
+					})
+					queuePostRequest(requestBody)
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Metadata annotation key error: prefix 'cloudfoundry.org' is reserved")
+					Expect(appRepo.CreateAppCallCount()).To(Equal(0))
+				})
+			})
 		})
 	})
 
@@ -597,7 +655,7 @@ var _ = Describe("AppHandler", func() {
 						},
 					},
 				},
-			}, nil)
+			}, 2, nil)
 
 			var err error
 			req, err = http.NewRequest("GET", "/v3/apps", nil)
@@ -620,10 +678,10 @@ var _ = Describe("AppHandler", func() {
 				  "total_results": 2,
 				  "total_pages": 1,
 				  "first": {
-					"href": "%[1]s/v3/apps?page=1"
+					"href": "%[1]s/v3/apps?page=1&per_page=50"
 				  },
 				  "last": {
-					"href": "%[1]s/v3/apps?page=1"
+					"href": "%[1]s/v3/apps?page=1&per_page=50"
 				  },
 				  "next": null,
 				  "previous": null
@@ -765,47 +823,824 @@ var _ = Describe("AppHandler", func() {
 						  }
 						}
 					}
-				]
-			}`, defaultServerURL)), "Response body matches response:")
-			})
+				]
+			}`, defaultServerURL)), "Response body matches response:")
+			})
+		})
+
+		When("no apps can be found", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppListReturns([]repositories.AppRecord{}, 0, nil)
+			})
+
+			It("returns status 200 OK", func() {
+				Expect(rr.Code).Should(Equal(http.StatusOK), "Matching HTTP response code:")
+			})
+
+			It("returns Content-Type as JSON in header", func() {
+				contentTypeHeader := rr.Header().Get("Content-Type")
+				Expect(contentTypeHeader).Should(Equal(jsonHeader), "Matching Content-Type header:")
+			})
+
+			It("returns a CF API formatted Error response", func() {
+				Expect(rr.Body.String()).Should(MatchJSON(fmt.Sprintf(`{
+				"pagination": {
+				  "total_results": 0,
+				  "total_pages": 1,
+				  "first": {
+					"href": "%[1]s/v3/apps?page=1&per_page=50"
+				  },
+				  "last": {
+					"href": "%[1]s/v3/apps?page=1&per_page=50"
+				  },
+				  "next": null,
+				  "previous": null
+				},
+				"resources": []
+			}`, defaultServerURL)), "Response body matches response:")
+			})
+		})
+
+		It("fetches the first page of 50 apps by default", func() {
+			Expect(appRepo.FetchAppListCallCount()).To(Equal(1))
+			_, _, message := appRepo.FetchAppListArgsForCall(0)
+			Expect(message).To(Equal(repositories.AppListMessage{Page: repositories.PageRequest{Page: 1, PerPage: 50}}))
+		})
+
+		When("a page in the middle of the list is requested", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppListReturns([]repositories.AppRecord{{GUID: "second-test-app-guid"}}, 3, nil)
+
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?page=2&per_page=1", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("fetches the requested page", func() {
+				_, _, message := appRepo.FetchAppListArgsForCall(0)
+				Expect(message.Page).To(Equal(repositories.PageRequest{Page: 2, PerPage: 1}))
+			})
+
+			It("returns links to the surrounding pages", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				var response map[string]interface{}
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response["pagination"]).To(Equal(map[string]interface{}{
+					"total_results": float64(3),
+					"total_pages":   float64(3),
+					"first":         map[string]interface{}{"href": defaultServerURI("/v3/apps?page=1&per_page=1")},
+					"last":          map[string]interface{}{"href": defaultServerURI("/v3/apps?page=3&per_page=1")},
+					"next":          map[string]interface{}{"href": defaultServerURI("/v3/apps?page=3&per_page=1")},
+					"previous":      map[string]interface{}{"href": defaultServerURI("/v3/apps?page=1&per_page=1")},
+				}))
+			})
+		})
+
+		When("the page is not a positive integer", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?page=0", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Page must be a positive integer")
+				Expect(appRepo.FetchAppListCallCount()).To(Equal(0))
+			})
+		})
+
+		When("per_page is too large", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?per_page=5001", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Per page must be between 1 and 5000")
+			})
+		})
+
+		When("filters and an order are requested", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?names=app1,app2&guids=guid1&space_guids=space1,space2&organization_guids=org1&stacks=cflinuxfs3&order_by=-name", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("passes them to the repository", func() {
+				_, _, message := appRepo.FetchAppListArgsForCall(0)
+				Expect(message).To(Equal(repositories.AppListMessage{
+					Names:      []string{"app1", "app2"},
+					GUIDs:      []string{"guid1"},
+					SpaceGUIDs: []string{"space1", "space2"},
+					OrgGUIDs:   []string{"org1"},
+					Stacks:     []string{"cflinuxfs3"},
+					OrderBy:    "-name",
+					Page:       repositories.PageRequest{Page: 1, PerPage: 50},
+				}))
+			})
+
+			It("keeps the filters in the pagination links", func() {
+				var response map[string]interface{}
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				first := response["pagination"].(map[string]interface{})["first"].(map[string]interface{})
+				Expect(first["href"]).To(ContainSubstring("names=app1%2Capp2"))
+				Expect(first["href"]).To(ContainSubstring("order_by=-name"))
+			})
+		})
+
+		When("a label selector is requested", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?label_selector=team%3Dpayments,cost-center", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("passes it to the repository", func() {
+				_, _, message := appRepo.FetchAppListArgsForCall(0)
+				Expect(message.LabelSelector).NotTo(BeNil())
+				Expect(message.LabelSelector.Matches(labels.Set{"team": "payments", "cost-center": "42"})).To(BeTrue())
+				Expect(message.LabelSelector.Matches(labels.Set{"team": "payments"})).To(BeFalse())
+			})
+		})
+
+		When("the label selector is invalid", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?label_selector=team%3D%3D%3D", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Invalid label_selector value")
+				Expect(appRepo.FetchAppListCallCount()).To(Equal(0))
+			})
+		})
+
+		When("order_by has a + prefix", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?order_by=%2Bcreated_at", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("orders in ascending order", func() {
+				_, _, message := appRepo.FetchAppListArgsForCall(0)
+				Expect(message.OrderBy).To(Equal("created_at"))
+			})
+		})
+
+		When("order_by names a field apps cannot be ordered by", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?order_by=stack", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Order by can only be: 'created_at', 'updated_at', 'name', 'state'")
+				Expect(appRepo.FetchAppListCallCount()).To(Equal(0))
+			})
+		})
+
+		When("an unknown query parameter is given", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("GET", "/v3/apps?names=app1&foo=bar&bar=baz", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Unknown query parameter(s): 'bar', 'foo'. Valid parameters are: 'page', 'per_page', 'label_selector', 'names', 'guids', 'space_guids', 'organization_guids', 'stacks', 'order_by'")
+				Expect(appRepo.FetchAppListCallCount()).To(Equal(0))
+			})
+		})
+
+		When("there is some other error fetching apps", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppListReturns([]repositories.AppRecord{}, 0, errors.New("unknown!"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the PATCH /v3/apps/:guid endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID, Name: appName, ResourceVersion: "42"}, nil)
+			appRepo.PatchAppReturns(repositories.AppRecord{
+				GUID:        appGUID,
+				Name:        appName,
+				SpaceGUID:   spaceGUID,
+				State:       "STOPPED",
+				Labels:      map[string]string{"env": "prod"},
+				Annotations: map[string]string{"example.org/contact": "jane@example.org"},
+			}, nil)
+		})
+
+		makePatchRequest := func(body string) {
+			var err error
+			req, err = http.NewRequest("PATCH", "/v3/apps/"+appGUID, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		When("on the happy path", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{
+					"metadata": {
+						"labels": { "env": "prod", "tier": null },
+						"annotations": { "example.org/contact": "jane@example.org" }
+					}
+				}`)
+			})
+
+			It("responds with a 200 code", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+			})
+
+			It("patches the metadata of the app in its space", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(1))
+				_, _, message := appRepo.PatchAppArgsForCall(0)
+				Expect(message.AppGUID).To(Equal(appGUID))
+				Expect(message.SpaceGUID).To(Equal(spaceGUID))
+				Expect(message.Name).To(BeNil())
+				Expect(message.Buildpacks).To(BeNil())
+				Expect(message.Stack).To(BeNil())
+				Expect(message.Labels).To(HaveLen(2))
+				Expect(*message.Labels["env"]).To(Equal("prod"))
+				Expect(message.Labels).To(HaveKeyWithValue("tier", BeNil()))
+				Expect(*message.Annotations["example.org/contact"]).To(Equal("jane@example.org"))
+			})
+
+			It("patches the version of the app it fetched", func() {
+				_, _, message := appRepo.PatchAppArgsForCall(0)
+				Expect(message.ResourceVersion).To(Equal("42"))
+			})
+
+			It("responds with the patched app", func() {
+				Expect(rr.Header().Get("Content-Type")).To(Equal(jsonHeader))
+
+				var response presenter.AppResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.GUID).To(Equal(appGUID))
+				Expect(response.Metadata.Labels).To(Equal(map[string]string{"env": "prod"}))
+				Expect(response.Metadata.Annotations).To(Equal(map[string]string{"example.org/contact": "jane@example.org"}))
+			})
+		})
+
+		When("the name and lifecycle are changed", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{
+					"name": "new-name",
+					"lifecycle": {
+						"type": "buildpack",
+						"data": { "buildpacks": ["java_buildpack"], "stack": "cflinuxfs3" }
+					}
+				}`)
+			})
+
+			It("responds with a 200 code", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+			})
+
+			It("patches the name, buildpacks and stack of the app", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(1))
+				_, _, message := appRepo.PatchAppArgsForCall(0)
+				Expect(*message.Name).To(Equal("new-name"))
+				Expect(*message.Buildpacks).To(Equal([]string{"java_buildpack"}))
+				Expect(*message.Stack).To(Equal("cflinuxfs3"))
+				Expect(message.Labels).To(BeEmpty())
+			})
+		})
+
+		When("only the stack is changed", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "lifecycle": { "data": { "stack": "cflinuxfs3" } } }`)
+			})
+
+			It("leaves the buildpacks as they are", func() {
+				_, _, message := appRepo.PatchAppArgsForCall(0)
+				Expect(*message.Stack).To(Equal("cflinuxfs3"))
+				Expect(message.Buildpacks).To(BeNil())
+			})
+		})
+
+		When("the name is empty", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "name": "" }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Name must be at least 1 character in length")
+			})
+		})
+
+		When("the lifecycle type is not buildpack", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "lifecycle": { "type": "docker" } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Type must be one of [buildpack]")
+			})
+
+			It("doesn't patch the app", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(0))
+			})
+		})
+
+		When("another app in the space has the new name", func() {
+			BeforeEach(func() {
+				controllerError := new(k8serrors.StatusError)
+				controllerError.ErrStatus.Reason = `{"code":1,"message":"CFApp with the same spec.name exists"}`
+				appRepo.PatchAppReturns(repositories.AppRecord{}, controllerError)
+				makePatchRequest(`{ "name": "taken-name" }`)
+			})
+
+			It("returns a uniqueness error", func() {
+				expectJSONResponse(http.StatusUnprocessableEntity, `{
+					"errors": [
+						{
+							"title": "CF-UniquenessError",
+							"detail": "App with the name 'taken-name' already exists.",
+							"code": 10016
+						}
+					]
+				}`)
+			})
+		})
+
+		When("the app was changed since it was fetched", func() {
+			BeforeEach(func() {
+				appRepo.PatchAppReturns(repositories.AppRecord{}, repositories.ConflictError{})
+				makePatchRequest(`{ "name": "new-name" }`)
+			})
+
+			It("returns a conflict error", func() {
+				expectJSONResponse(http.StatusConflict, `{
+					"errors": [
+						{
+							"title": "CF-ConcurrencyError",
+							"detail": "The app was changed by another request. Fetch it again and retry.",
+							"code": 10018
+						}
+					]
+				}`)
+			})
+		})
+
+		When("a label key uses the reserved prefix", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "cloudfoundry.org/app-guid": "foo" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata label key error: prefix 'cloudfoundry.org' is reserved")
+			})
+
+			It("doesn't patch the app", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(0))
+			})
+		})
+
+		When("a label key prefix is not a DNS subdomain", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "-example.org/env": "prod" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata label key error: prefix '-example.org' must be in valid dns format")
+			})
+		})
+
+		When("a label key has more than one slash", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "example.org/env/tier": "prod" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata label key error: key has more than one '/'")
+			})
+		})
+
+		When("a label key contains invalid characters", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "my env": "prod" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata label key error: 'my env' contains invalid characters")
+			})
+		})
+
+		When("a label key is too long", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "` + strings.Repeat("a", 64) + `": "prod" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata label key error: '" + strings.Repeat("a", 64) + "' is greater than 63 characters")
+			})
+		})
+
+		When("a label value contains invalid characters", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "env": "prod!" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata label value error: 'prod!' contains invalid characters")
+			})
+		})
+
+		When("a label value is empty", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "labels": { "env": "" } } }`)
+			})
+
+			It("patches the app", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(appRepo.PatchAppCallCount()).To(Equal(1))
+			})
+		})
+
+		When("an annotation key is invalid", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "annotations": { "": "foo" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata annotation key error: key cannot be empty string")
+			})
+		})
+
+		When("an annotation value is too long", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "metadata": { "annotations": { "description": "` + strings.Repeat("a", 5001) + `" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Metadata annotation value error: value is greater than 5000 characters")
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+				makePatchRequest(`{ "metadata": { "labels": { "env": "prod" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+
+			It("doesn't patch the app", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(0))
+			})
+		})
+
+		When("patching the app errors", func() {
+			BeforeEach(func() {
+				appRepo.PatchAppReturns(repositories.AppRecord{}, errors.New("boom"))
+				makePatchRequest(`{ "metadata": { "labels": { "env": "prod" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the DELETE /v3/apps/:guid endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID}, nil)
+			jobRepo.RunJobStub = func(ctx context.Context, operation string, _ string, task repositories.JobTask) (repositories.JobRecord, error) {
+				return repositories.JobRecord{GUID: "test-job-guid", Operation: operation}, task(ctx)
+			}
+
+			var err error
+			req, err = http.NewRequest("DELETE", "/v3/apps/"+appGUID, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("responds with a 202 code", func() {
+				Expect(rr.Code).To(Equal(http.StatusAccepted))
+			})
+
+			It("points the Location header at the job", func() {
+				Expect(rr.Header().Get("Location")).To(Equal(defaultServerURL + "/v3/jobs/test-job-guid"))
+			})
+
+			It("deletes the app in a job", func() {
+				Expect(jobRepo.RunJobCallCount()).To(Equal(1))
+				_, operation, resourceNamespace, _ := jobRepo.RunJobArgsForCall(0)
+				Expect(operation).To(Equal(repositories.AppDeleteJobOperation))
+				Expect(resourceNamespace).To(Equal(spaceGUID))
+
+				Expect(appRepo.DeleteAppCallCount()).To(Equal(1))
+				_, _, message := appRepo.DeleteAppArgsForCall(0)
+				Expect(message).To(Equal(repositories.DeleteAppMessage{AppGUID: appGUID, SpaceGUID: spaceGUID}))
+			})
+		})
+
+		When("the app does not exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+
+			It("does not start a job", func() {
+				Expect(jobRepo.RunJobCallCount()).To(Equal(0))
+			})
+		})
+
+		When("fetching the app errors", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+
+		When("the job cannot be started", func() {
+			BeforeEach(func() {
+				jobRepo.RunJobStub = nil
+				jobRepo.RunJobReturns(repositories.JobRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+
+		When("the app is gone by the time the job deletes it", func() {
+			var jobErr error
+
+			BeforeEach(func() {
+				appRepo.DeleteAppReturns(repositories.NotFoundError{})
+				jobRepo.RunJobStub = func(ctx context.Context, operation string, _ string, task repositories.JobTask) (repositories.JobRecord, error) {
+					jobErr = task(ctx)
+					return repositories.JobRecord{GUID: "test-job-guid", Operation: operation}, nil
+				}
+			})
+
+			It("fails the job with a CF not found error", func() {
+				Expect(jobErr).To(Equal(repositories.JobError{Title: "CF-ResourceNotFound", Detail: "App not found", Code: 10010}))
+			})
+		})
+	})
+
+	Describe("the GET /v3/apps/:guid/environment_variables endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID, EnvSecretName: appGUID + "-env"}, nil)
+			appRepo.FetchAppEnvVarsReturns(repositories.AppEnvVarsRecord{
+				AppGUID:              appGUID,
+				SpaceGUID:            spaceGUID,
+				EnvironmentVariables: map[string]string{"RAILS_ENV": "production"},
+			}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/environment_variables", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fetches the environment variables of the app", func() {
+			Expect(appRepo.FetchAppEnvVarsCallCount()).To(Equal(1))
+			_, _, app := appRepo.FetchAppEnvVarsArgsForCall(0)
+			Expect(app.EnvSecretName).To(Equal(appGUID + "-env"))
+		})
+
+		It("returns the environment variables", func() {
+			expectJSONResponse(http.StatusOK, `{
+				"var": {
+					"RAILS_ENV": "production"
+				},
+				"links": {
+					"self": {
+						"href": "`+defaultServerURL+`/v3/apps/`+appGUID+`/environment_variables"
+					},
+					"app": {
+						"href": "`+defaultServerURL+`/v3/apps/`+appGUID+`"
+					}
+				}
+			}`)
+		})
+
+		When("the app does not exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+
+		When("fetching the environment variables errors", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppEnvVarsReturns(repositories.AppEnvVarsRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the PATCH /v3/apps/:guid/environment_variables endpoint", func() {
+		makePatchRequest := func(body string) {
+			var err error
+			req, err = http.NewRequest("PATCH", "/v3/apps/"+appGUID+"/environment_variables", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID, EnvSecretName: appGUID + "-env"}, nil)
+			appRepo.PatchAppEnvVarsReturns(repositories.AppEnvVarsRecord{
+				AppGUID:              appGUID,
+				SpaceGUID:            spaceGUID,
+				EnvironmentVariables: map[string]string{"RAILS_ENV": "production"},
+			}, nil)
+			makePatchRequest(`{ "var": { "RAILS_ENV": "production", "DEBUG": null } }`)
+		})
+
+		It("patches the environment variables of the app", func() {
+			Expect(appRepo.PatchAppEnvVarsCallCount()).To(Equal(1))
+			_, _, message := appRepo.PatchAppEnvVarsArgsForCall(0)
+			Expect(message.AppGUID).To(Equal(appGUID))
+			Expect(message.SpaceGUID).To(Equal(spaceGUID))
+			Expect(message.EnvSecretName).To(Equal(appGUID + "-env"))
+			Expect(message.EnvironmentVariables).To(HaveLen(2))
+			Expect(*message.EnvironmentVariables["RAILS_ENV"]).To(Equal("production"))
+			Expect(message.EnvironmentVariables).To(HaveKeyWithValue("DEBUG", BeNil()))
+		})
+
+		It("returns the environment variables", func() {
+			Expect(rr.Code).To(Equal(http.StatusOK))
+
+			var response presenter.AppEnvVarsResponse
+			Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.Var).To(Equal(map[string]string{"RAILS_ENV": "production"}))
+		})
+
+		It("records a revision of the app", func() {
+			Expect(revisionRepo.RecordRevisionCallCount()).To(Equal(1))
+			_, _, message := revisionRepo.RecordRevisionArgsForCall(0)
+			Expect(message).To(Equal(repositories.RevisionCreateMessage{AppGUID: appGUID, SpaceGUID: spaceGUID}))
+		})
+
+		When("recording the revision fails", func() {
+			BeforeEach(func() {
+				revisionRepo.RecordRevisionReturns(repositories.RevisionRecord{}, errors.New("boom"))
+			})
+
+			It("returns the environment variables, as they have been changed", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+			})
+		})
+
+		When("a variable starts with VCAP_", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "var": { "VCAP_SERVICES": "{}" } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Var cannot start with VCAP_")
+			})
+
+			It("does not patch the environment variables", func() {
+				Expect(appRepo.PatchAppEnvVarsCallCount()).To(Equal(0))
+			})
+		})
+
+		When("a variable sets PORT", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "var": { "PORT": "8081" } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Var cannot set PORT")
+			})
+		})
+
+		When("a variable name has characters that cannot be stored", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "var": { "MY VAR": "foo" } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Var key 'MY VAR' contains invalid characters")
+			})
+		})
+
+		When("var is missing", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{}`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Var is a required field")
+			})
+		})
+
+		When("the app does not exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+
+		When("patching the environment variables errors", func() {
+			BeforeEach(func() {
+				appRepo.PatchAppEnvVarsReturns(repositories.AppEnvVarsRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/apps/:guid/env endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, Name: appName, SpaceGUID: spaceGUID}, nil)
+			appRepo.FetchAppEnvVarsReturns(repositories.AppEnvVarsRecord{
+				AppGUID:              appGUID,
+				SpaceGUID:            spaceGUID,
+				EnvironmentVariables: map[string]string{"RAILS_ENV": "production"},
+			}, nil)
+			routeRepo.FetchRoutesForAppReturns([]repositories.RouteRecord{{
+				GUID:      "test-route-guid",
+				Host:      "my-app",
+				DomainRef: repositories.DomainRecord{GUID: "test-domain-guid"},
+			}}, nil)
+			domainRepo.FetchDomainReturns(repositories.DomainRecord{GUID: "test-domain-guid", Name: "example.org"}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/env", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the environment of the app", func() {
+			expectJSONResponse(http.StatusOK, `{
+				"staging_env_json": {},
+				"running_env_json": {},
+				"environment_variables": {
+					"RAILS_ENV": "production"
+				},
+				"system_env_json": {
+					"VCAP_SERVICES": {}
+				},
+				"application_env_json": {
+					"VCAP_APPLICATION": {
+						"application_id": "`+appGUID+`",
+						"application_name": "`+appName+`",
+						"name": "`+appName+`",
+						"space_id": "`+spaceGUID+`",
+						"application_uris": ["my-app.example.org"],
+						"uris": ["my-app.example.org"],
+						"cf_api": "`+defaultServerURL+`"
+					}
+				}
+			}`)
 		})
 
-		When("no apps can be found", func() {
+		When("the app does not exist", func() {
 			BeforeEach(func() {
-				appRepo.FetchAppListReturns([]repositories.AppRecord{}, nil)
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
 			})
 
-			It("returns status 200 OK", func() {
-				Expect(rr.Code).Should(Equal(http.StatusOK), "Matching HTTP response code:")
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
 			})
+		})
 
-			It("returns Content-Type as JSON in header", func() {
-				contentTypeHeader := rr.Header().Get("Content-Type")
-				Expect(contentTypeHeader).Should(Equal(jsonHeader), "Matching Content-Type header:")
+		When("fetching the environment variables errors", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppEnvVarsReturns(repositories.AppEnvVarsRecord{}, errors.New("boom"))
 			})
 
-			It("returns a CF API formatted Error response", func() {
-				Expect(rr.Body.String()).Should(MatchJSON(fmt.Sprintf(`{
-				"pagination": {
-				  "total_results": 0,
-				  "total_pages": 1,
-				  "first": {
-					"href": "%[1]s/v3/apps?page=1"
-				  },
-				  "last": {
-					"href": "%[1]s/v3/apps?page=1"
-				  },
-				  "next": null,
-				  "previous": null
-				},
-				"resources": []
-			}`, defaultServerURL)), "Response body matches response:")
+			It("returns an error", func() {
+				expectUnknownError()
 			})
 		})
 
-		When("there is some other error fetching apps", func() {
+		When("fetching the routes errors", func() {
 			BeforeEach(func() {
-				appRepo.FetchAppListReturns([]repositories.AppRecord{}, errors.New("unknown!"))
+				routeRepo.FetchRoutesForAppReturns(nil, errors.New("boom"))
 			})
 
 			It("returns an error", func() {
@@ -861,6 +1696,22 @@ var _ = Describe("AppHandler", func() {
 				Expect(message.SpaceGUID).To(Equal(spaceGUID))
 			})
 
+			It("records a revision of the app", func() {
+				Expect(revisionRepo.RecordRevisionCallCount()).To(Equal(1))
+				_, _, message := revisionRepo.RecordRevisionArgsForCall(0)
+				Expect(message).To(Equal(repositories.RevisionCreateMessage{AppGUID: appGUID, SpaceGUID: spaceGUID}))
+			})
+
+			When("recording the revision fails", func() {
+				BeforeEach(func() {
+					revisionRepo.RecordRevisionReturns(repositories.RevisionRecord{}, errors.New("boom"))
+				})
+
+				It("returns the current droplet, as it has been set", func() {
+					Expect(rr.Code).To(Equal(http.StatusOK))
+				})
+			})
+
 			It("responds with JSON", func() {
 				contentTypeHeader := rr.Header().Get("Content-Type")
 				Expect(contentTypeHeader).To(Equal(jsonHeader), "Matching Content-Type header:")
@@ -1007,7 +1858,7 @@ var _ = Describe("AppHandler", func() {
 			appRepo.FetchAppReturns(fetchAppRecord, nil)
 			setAppDesiredStateRecord := fetchAppRecord
 			setAppDesiredStateRecord.State = "STARTED"
-			appRepo.SetAppDesiredStateReturns(setAppDesiredStateRecord, nil)
+			appRepo.StartAppReturns(setAppDesiredStateRecord, nil)
 
 			var err error
 			req, err = http.NewRequest("POST", "/v3/apps/"+appGUID+"/actions/start", nil)
@@ -1015,6 +1866,13 @@ var _ = Describe("AppHandler", func() {
 		})
 
 		When("on the happy path", func() {
+			It("starts the fetched app", func() {
+				Expect(appRepo.StartAppCallCount()).To(Equal(1))
+				_, _, app := appRepo.StartAppArgsForCall(0)
+				Expect(app.GUID).To(Equal(appGUID))
+				Expect(app.DropletGUID).To(Equal("some-droplet-guid"))
+			})
+
 			It("returns status 200 OK", func() {
 				Expect(rr.Code).To(Equal(http.StatusOK), "Matching HTTP response code:")
 			})
@@ -1115,23 +1973,11 @@ var _ = Describe("AppHandler", func() {
 			})
 		})
 
-		When("the app has no droplet", func() {
+		When("the app cannot be started", func() {
 			BeforeEach(func() {
-				fetchAppRecord := repositories.AppRecord{
-					Name:        appName,
-					GUID:        appGUID,
-					SpaceGUID:   spaceGUID,
-					DropletGUID: "",
-					State:       "STOPPED",
-					Lifecycle: repositories.Lifecycle{
-						Type: "buildpack",
-						Data: repositories.LifecycleData{
-							Buildpacks: []string{},
-							Stack:      "",
-						},
-					},
-				}
-				appRepo.FetchAppReturns(fetchAppRecord, nil)
+				appRepo.StartAppReturns(repositories.AppRecord{}, repositories.AppNotStartableError{
+					Reason: "Assign a droplet before starting this app.",
+				})
 			})
 
 			It("returns an error", func() {
@@ -1141,7 +1987,7 @@ var _ = Describe("AppHandler", func() {
 
 		When("there is some other error updating app desiredState", func() {
 			BeforeEach(func() {
-				appRepo.SetAppDesiredStateReturns(repositories.AppRecord{}, errors.New("unknown!"))
+				appRepo.StartAppReturns(repositories.AppRecord{}, errors.New("unknown!"))
 			})
 
 			It("returns an error", func() {
@@ -1169,7 +2015,7 @@ var _ = Describe("AppHandler", func() {
 			appRepo.FetchAppReturns(fetchAppRecord, nil)
 			setAppDesiredStateRecord := fetchAppRecord
 			setAppDesiredStateRecord.State = "STOPPED"
-			appRepo.SetAppDesiredStateReturns(setAppDesiredStateRecord, nil)
+			appRepo.StopAppReturns(setAppDesiredStateRecord, nil)
 
 			var err error
 			req, err = http.NewRequest("POST", "/v3/apps/"+appGUID+"/actions/stop", nil)
@@ -1275,7 +2121,7 @@ var _ = Describe("AppHandler", func() {
 				appRepo.FetchAppReturns(fetchAppRecord, nil)
 				setAppDesiredStateRecord := fetchAppRecord
 				setAppDesiredStateRecord.State = "STOPPED"
-				appRepo.SetAppDesiredStateReturns(setAppDesiredStateRecord, nil)
+				appRepo.StopAppReturns(setAppDesiredStateRecord, nil)
 
 				var err error
 				req, err = http.NewRequest("POST", "/v3/apps/"+appGUID+"/actions/stop", nil)
@@ -1481,7 +2327,85 @@ var _ = Describe("AppHandler", func() {
 
 		When("there is some other error updating app desiredState", func() {
 			BeforeEach(func() {
-				appRepo.SetAppDesiredStateReturns(repositories.AppRecord{}, errors.New("unknown!"))
+				appRepo.StopAppReturns(repositories.AppRecord{}, errors.New("unknown!"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the POST /v3/apps/:guid/actions/restart endpoint", func() {
+		BeforeEach(func() {
+			fetchAppRecord := repositories.AppRecord{
+				Name:        appName,
+				GUID:        appGUID,
+				SpaceGUID:   spaceGUID,
+				DropletGUID: "some-droplet-guid",
+				State:       "STARTED",
+			}
+			appRepo.FetchAppReturns(fetchAppRecord, nil)
+			restartedAppRecord := fetchAppRecord
+			restartedAppRecord.State = "STARTED"
+			appRepo.RestartAppReturns(restartedAppRecord, nil)
+
+			var err error
+			req, err = http.NewRequest("POST", "/v3/apps/"+appGUID+"/actions/restart", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("returns status 200 OK", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+			})
+
+			It("restarts the fetched app", func() {
+				Expect(appRepo.RestartAppCallCount()).To(Equal(1))
+				_, _, app := appRepo.RestartAppArgsForCall(0)
+				Expect(app.GUID).To(Equal(appGUID))
+				Expect(app.DropletGUID).To(Equal("some-droplet-guid"))
+			})
+
+			It("returns the restarted app", func() {
+				Expect(rr.Header().Get("Content-Type")).To(Equal(jsonHeader))
+
+				var response presenter.AppResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.GUID).To(Equal(appGUID))
+				Expect(response.State).To(Equal("STARTED"))
+			})
+		})
+
+		When("the app cannot be found", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+
+			It("doesn't restart the app", func() {
+				Expect(appRepo.RestartAppCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the app cannot be started", func() {
+			BeforeEach(func() {
+				appRepo.RestartAppReturns(repositories.AppRecord{}, repositories.AppNotStartableError{
+					Reason: "The current droplet of this app did not stage successfully. Assign a droplet before starting this app.",
+				})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("The current droplet of this app did not stage successfully. Assign a droplet before starting this app.")
+			})
+		})
+
+		When("restarting the app fails", func() {
+			BeforeEach(func() {
+				appRepo.RestartAppReturns(repositories.AppRecord{}, errors.New("processes did not stop"))
 			})
 
 			It("returns an error", func() {
@@ -1551,10 +2475,10 @@ var _ = Describe("AppHandler", func() {
 						  "total_results": 2,
 						  "total_pages": 1,
 						  "first": {
-							"href": "%[1]s/v3/apps/%[2]s/processes?page=1"
+							"href": "%[1]s/v3/apps/%[2]s/processes?page=1&per_page=50"
 						  },
 						  "last": {
-							"href": "%[1]s/v3/apps/%[2]s/processes?page=1"
+							"href": "%[1]s/v3/apps/%[2]s/processes?page=1&per_page=50"
 						  },
 						  "next": null,
 						  "previous": null
@@ -1674,10 +2598,10 @@ var _ = Describe("AppHandler", func() {
 						  "total_results": 0,
 						  "total_pages": 1,
 						  "first": {
-							"href": "%[1]s/v3/apps/%[2]s/processes?page=1"
+							"href": "%[1]s/v3/apps/%[2]s/processes?page=1&per_page=50"
 						  },
 						  "last": {
-							"href": "%[1]s/v3/apps/%[2]s/processes?page=1"
+							"href": "%[1]s/v3/apps/%[2]s/processes?page=1&per_page=50"
 						  },
 						  "next": null,
 						  "previous": null
@@ -1686,8 +2610,91 @@ var _ = Describe("AppHandler", func() {
 					}`, defaultServerURL, appGUID)), "Response body matches response:")
 				})
 			})
+
+			When("the second page of one process is requested", func() {
+				BeforeEach(func() {
+					var err error
+					req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/processes?page=2&per_page=1", nil)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns only the second process", func() {
+					Expect(rr.Code).To(Equal(http.StatusOK))
+
+					var response presenter.ProcessListResponse
+					Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+					Expect(response.Resources).To(HaveLen(1))
+					Expect(response.Resources[0].GUID).To(Equal(process2Record.GUID))
+					Expect(response.PaginationData.TotalResults).To(Equal(2))
+					Expect(response.PaginationData.Next).To(BeNil())
+					Expect(response.PaginationData.Previous).To(Equal(&presenter.PageRef{
+						HREF: defaultServerURI("/v3/apps/", appGUID, "/processes?page=1&per_page=1"),
+					}))
+				})
+			})
+
+			When("the processes are filtered by type", func() {
+				BeforeEach(func() {
+					var err error
+					req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/processes?types=worker", nil)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns only the processes of that type", func() {
+					Expect(rr.Code).To(Equal(http.StatusOK))
+
+					var response presenter.ProcessListResponse
+					Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+					Expect(response.Resources).To(HaveLen(1))
+					Expect(response.Resources[0].GUID).To(Equal(process2Record.GUID))
+					Expect(response.PaginationData.TotalResults).To(Equal(1))
+				})
+			})
+
+			When("the processes are ordered by descending creation time", func() {
+				BeforeEach(func() {
+					process2Record.CreatedAt = "2016-03-24T18:48:22Z"
+					processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{*process1Record, *process2Record}, nil)
+
+					var err error
+					req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/processes?order_by=-created_at", nil)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns the newest process first", func() {
+					var response presenter.ProcessListResponse
+					Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+					Expect(response.Resources).To(HaveLen(2))
+					Expect(response.Resources[0].GUID).To(Equal(process2Record.GUID))
+					Expect(response.Resources[1].GUID).To(Equal(process1Record.GUID))
+				})
+			})
 		})
 		When("On the sad path and", func() {
+			When("an unknown query parameter is given", func() {
+				BeforeEach(func() {
+					var err error
+					req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/processes?names=web", nil)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns a bad query parameter error", func() {
+					expectBadQueryParameterError("Unknown query parameter(s): 'names'. Valid parameters are: 'page', 'per_page', 'label_selector', 'guids', 'types', 'order_by'")
+				})
+			})
+
+			When("per_page is not a number", func() {
+				BeforeEach(func() {
+					var err error
+					req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/processes?per_page=all", nil)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("returns a bad query parameter error", func() {
+					expectBadQueryParameterError("Per page must be between 1 and 5000")
+				})
+			})
+
 			When("the app cannot be found", func() {
 				BeforeEach(func() {
 					appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
@@ -1719,6 +2726,191 @@ var _ = Describe("AppHandler", func() {
 		})
 	})
 
+	Describe("the POST /v3/apps/:guid/processes/:type/actions/scale endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID}, nil)
+			processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+				{GUID: "web-process-guid", SpaceGUID: spaceGUID, Type: "web"},
+				{GUID: "worker-process-guid", SpaceGUID: spaceGUID, Type: "worker"},
+			}, nil)
+			processRepo.ScaleProcessReturns(repositories.ProcessRecord{
+				GUID:        "worker-process-guid",
+				SpaceGUID:   spaceGUID,
+				Type:        "worker",
+				Instances:   2,
+				HealthCheck: repositories.HealthCheck{Type: "process"},
+			}, nil)
+
+			var err error
+			req, err = http.NewRequest("POST", "/v3/apps/"+appGUID+"/processes/worker/actions/scale", strings.NewReader(`{ "instances": 2 }`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("scales the process of the app with the given type", func() {
+				Expect(processRepo.ScaleProcessCallCount()).To(Equal(1))
+				_, _, message := processRepo.ScaleProcessArgsForCall(0)
+				Expect(message.GUID).To(Equal("worker-process-guid"))
+				Expect(message.SpaceGUID).To(Equal(spaceGUID))
+				Expect(*message.Instances).To(Equal(2))
+			})
+
+			It("returns the scaled process", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				var response presenter.ProcessResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.GUID).To(Equal("worker-process-guid"))
+				Expect(response.Instances).To(Equal(2))
+			})
+		})
+
+		When("the app has no process of the type", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+					{GUID: "web-process-guid", SpaceGUID: spaceGUID, Type: "web"},
+				}, nil)
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+
+			It("doesn't scale a process", func() {
+				Expect(processRepo.ScaleProcessCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+
+		When("the new scale exceeds a quota", func() {
+			BeforeEach(func() {
+				processRepo.ScaleProcessReturns(repositories.ProcessRecord{}, repositories.QuotaExceededError{Reason: "app_instance_limit quota_exceeded"})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("app_instance_limit quota_exceeded")
+			})
+		})
+	})
+
+	Describe("the GET /v3/apps/:guid/processes/:type/stats endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID}, nil)
+			processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+				{GUID: "web-process-guid", SpaceGUID: spaceGUID, Type: "web"},
+				{GUID: "worker-process-guid", SpaceGUID: spaceGUID, Type: "worker"},
+			}, nil)
+			processRepo.FetchProcessStatsReturns([]repositories.ProcessInstanceStatsRecord{
+				{Type: "worker", Index: 0, State: repositories.ProcessInstanceCrashed, Details: "back-off restarting failed container"},
+			}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/processes/worker/stats", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("fetches the stats of the process of the app with the given type", func() {
+				Expect(processRepo.FetchProcessStatsCallCount()).To(Equal(1))
+				_, _, process := processRepo.FetchProcessStatsArgsForCall(0)
+				Expect(process.GUID).To(Equal("worker-process-guid"))
+			})
+
+			It("returns the stats of each instance", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+
+				var response presenter.ProcessStatsResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Resources).To(HaveLen(1))
+				Expect(response.Resources[0].Type).To(Equal("worker"))
+				Expect(response.Resources[0].State).To(Equal("CRASHED"))
+				Expect(*response.Resources[0].Details).To(Equal("back-off restarting failed container"))
+			})
+		})
+
+		When("the app has no process of the type", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+					{GUID: "web-process-guid", SpaceGUID: spaceGUID, Type: "web"},
+				}, nil)
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+	})
+
+	Describe("the DELETE /v3/apps/:guid/processes/:type/instances/:index endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppReturns(repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID}, nil)
+			processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+				{GUID: "web-process-guid", SpaceGUID: spaceGUID, Type: "web", Instances: 1},
+				{GUID: "worker-process-guid", SpaceGUID: spaceGUID, Type: "worker", Instances: 3},
+			}, nil)
+
+			var err error
+			req, err = http.NewRequest("DELETE", "/v3/apps/"+appGUID+"/processes/worker/instances/2", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("terminates the instance of the process of the app with the given type", func() {
+				Expect(processRepo.TerminateProcessInstanceCallCount()).To(Equal(1))
+				_, _, process, index := processRepo.TerminateProcessInstanceArgsForCall(0)
+				Expect(process.GUID).To(Equal("worker-process-guid"))
+				Expect(index).To(Equal(2))
+			})
+
+			It("returns no content", func() {
+				Expect(rr.Code).To(Equal(http.StatusNoContent))
+			})
+		})
+
+		When("the index is out of range", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("DELETE", "/v3/apps/"+appGUID+"/processes/web/instances/1", nil)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Instance not found")
+			})
+		})
+
+		When("the app has no process of the type", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+					{GUID: "web-process-guid", SpaceGUID: spaceGUID, Type: "web"},
+				}, nil)
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+		})
+	})
+
 	Describe("the GET /v3/apps/:guid/routes endpoint", func() {
 		const (
 			testDomainGUID = "test-domain-guid"
@@ -1786,10 +2978,10 @@ var _ = Describe("AppHandler", func() {
 							"total_results": 1,
 							"total_pages": 1,
 							"first": {
-								"href": "%[1]s/v3/apps/%[2]s/routes?page=1"
+								"href": "%[1]s/v3/apps/%[2]s/routes?page=1&per_page=50"
 							},
 							"last": {
-								"href": "%[1]s/v3/apps/%[2]s/routes?page=1"
+								"href": "%[1]s/v3/apps/%[2]s/routes?page=1&per_page=50"
 							},
 							"next": null,
 							"previous": null
@@ -1859,10 +3051,10 @@ var _ = Describe("AppHandler", func() {
 						  "total_results": 0,
 						  "total_pages": 1,
 						  "first": {
-							"href": "%[1]s/v3/apps/%[2]s/routes?page=1"
+							"href": "%[1]s/v3/apps/%[2]s/routes?page=1&per_page=50"
 						  },
 						  "last": {
-							"href": "%[1]s/v3/apps/%[2]s/routes?page=1"
+							"href": "%[1]s/v3/apps/%[2]s/routes?page=1&per_page=50"
 						  },
 						  "next": null,
 						  "previous": null
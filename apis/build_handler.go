@@ -11,6 +11,7 @@ import (
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 
+	"github.com/go-http-utils/headers"
 	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
 	"k8s.io/client-go/rest"
@@ -20,12 +21,14 @@ import (
 const (
 	BuildGetEndpoint    = "/v3/builds/{guid}"
 	BuildCreateEndpoint = "/v3/builds"
+	BuildPatchEndpoint  = "/v3/builds/{guid}"
 )
 
 //counterfeiter:generate -o fake -fake-name CFBuildRepository . CFBuildRepository
 type CFBuildRepository interface {
 	FetchBuild(context.Context, client.Client, string) (repositories.BuildRecord, error)
 	CreateBuild(context.Context, client.Client, repositories.BuildCreateMessage) (repositories.BuildRecord, error)
+	PatchBuildMetadata(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.BuildRecord, error)
 }
 
 type BuildHandler struct {
@@ -61,12 +64,9 @@ func (h *BuildHandler) buildGetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	buildGUID := vars["guid"]
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "BuildGUID", buildGUID)
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err, "BuildGUID", buildGUID)
 		return
 	}
 
@@ -103,10 +103,9 @@ func (h *BuildHandler) buildCreateHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, req.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Info("Error building k8s client", "error", err.Error())
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
 
@@ -142,7 +141,65 @@ func (h *BuildHandler) buildCreateHandler(w http.ResponseWriter, req *http.Reque
 	}
 }
 
+func (h *BuildHandler) buildPatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	buildGUID := vars["guid"]
+
+	var payload payloads.BuildPatch
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "BuildGUID", buildGUID)
+		return
+	}
+
+	build, err := h.buildRepo.FetchBuild(ctx, client, buildGUID)
+	if err != nil {
+		switch err.(type) {
+		case repositories.NotFoundError:
+			h.logger.Info("Build not found", "BuildGUID", buildGUID)
+			writeNotFoundErrorResponse(w, "Build")
+			return
+		default:
+			h.logger.Error(err, "Failed to fetch build from Kubernetes", "BuildGUID", buildGUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+	}
+
+	build, err = h.buildRepo.PatchBuildMetadata(ctx, client, payload.ToMessage(buildGUID, build.SpaceGUID))
+	if err != nil {
+		switch err.(type) {
+		case repositories.NotFoundError:
+			h.logger.Info("Build not found", "BuildGUID", buildGUID)
+			writeNotFoundErrorResponse(w, "Build")
+			return
+		default:
+			h.logger.Error(err, "Failed to patch build metadata", "BuildGUID", buildGUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+	}
+
+	responseBody, err := json.Marshal(presenter.ForBuild(build, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "BuildGUID", buildGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+	w.Write(responseBody)
+}
+
 func (h *BuildHandler) RegisterRoutes(router *mux.Router) {
 	router.Path(BuildGetEndpoint).Methods("GET").HandlerFunc(h.buildGetHandler)
 	router.Path(BuildCreateEndpoint).Methods("POST").HandlerFunc(h.buildCreateHandler)
+	router.Path(BuildPatchEndpoint).Methods("PATCH").HandlerFunc(h.buildPatchHandler)
 }
@@ -7,10 +7,12 @@ import (
 	"strings"
 
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
 
+	"github.com/go-http-utils/headers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/rest"
@@ -342,6 +344,7 @@ var _ = Describe("BuildHandler", func() {
 		makePostRequest := func(body string) {
 			req, err := http.NewRequest("POST", "/v3/builds", strings.NewReader(body))
 			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
 
 			router.ServeHTTP(rr, req)
 		}
@@ -422,8 +425,10 @@ var _ = Describe("BuildHandler", func() {
 				Expect(contentTypeHeader).To(Equal(jsonHeader), "Matching Content-Type header:")
 			})
 
-			It("configures the client", func() {
+			It("configures the client with the request's authorization header", func() {
 				Expect(clientBuilder.CallCount()).To(Equal(1))
+				_, actualAuthHeader := clientBuilder.ArgsForCall(0)
+				Expect(actualAuthHeader).To(Equal(authHeader))
 			})
 
 			When("examining the BuildCreate message", func() {
@@ -542,6 +547,18 @@ var _ = Describe("BuildHandler", func() {
 			itDoesntCreateABuild()
 		})
 
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+				makePostRequest(validBody)
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+			itDoesntCreateABuild()
+		})
+
 		When("creating the build in the repo errors", func() {
 			BeforeEach(func() {
 				buildRepo.CreateBuildReturns(repositories.BuildRecord{}, errors.New("boom"))
@@ -580,4 +597,90 @@ var _ = Describe("BuildHandler", func() {
 			})
 		})
 	})
+
+	Describe("the PATCH /v3/builds/{guid} endpoint", func() {
+		const (
+			buildGUID = "test-build-guid"
+			spaceGUID = "test-space-guid"
+		)
+
+		var (
+			buildRepo *fake.CFBuildRepository
+			body      string
+		)
+
+		BeforeEach(func() {
+			buildRepo = new(fake.CFBuildRepository)
+			buildRepo.FetchBuildReturns(repositories.BuildRecord{GUID: buildGUID, SpaceGUID: spaceGUID}, nil)
+			buildRepo.PatchBuildMetadataReturns(repositories.BuildRecord{
+				GUID:        buildGUID,
+				SpaceGUID:   spaceGUID,
+				State:       "STAGING",
+				Annotations: map[string]string{"example.org/commit": "abc123"},
+			}, nil)
+
+			buildHandler := NewBuildHandler(
+				logf.Log.WithName(testBuildHandlerLoggerName),
+				*serverURL,
+				buildRepo,
+				new(fake.CFPackageRepository),
+				new(fake.ClientBuilder).Spy,
+				&rest.Config{},
+			)
+			buildHandler.RegisterRoutes(router)
+
+			body = `{"metadata": {"annotations": {"example.org/commit": "abc123"}}}`
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("PATCH", "/v3/builds/"+buildGUID, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			router.ServeHTTP(rr, req)
+		})
+
+		It("patches the build metadata in the space of the build", func() {
+			Expect(buildRepo.PatchBuildMetadataCallCount()).To(Equal(1))
+			_, _, message := buildRepo.PatchBuildMetadataArgsForCall(0)
+			Expect(message.GUID).To(Equal(buildGUID))
+			Expect(message.SpaceGUID).To(Equal(spaceGUID))
+			Expect(*message.Annotations["example.org/commit"]).To(Equal("abc123"))
+		})
+
+		It("responds with the patched build", func() {
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`"annotations":{"example.org/commit":"abc123"}`))
+		})
+
+		When("the metadata is invalid", func() {
+			BeforeEach(func() {
+				body = `{"metadata": {"annotations": {"example.org/": "abc123"}}}`
+			})
+
+			It("returns an error without patching the build", func() {
+				expectUnprocessableEntityError("Metadata annotation key error: '' contains invalid characters")
+				Expect(buildRepo.PatchBuildMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the build cannot be found", func() {
+			BeforeEach(func() {
+				buildRepo.FetchBuildReturns(repositories.BuildRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Build not found")
+			})
+		})
+
+		When("patching the build fails", func() {
+			BeforeEach(func() {
+				buildRepo.PatchBuildMetadataReturns(repositories.BuildRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
 })
@@ -0,0 +1,334 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"github.com/go-http-utils/headers"
+	"github.com/go-logr/logr"
+	"github.com/gorilla/mux"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	invalidRevisionMsg = "Unable to use revision. Ensure that the revision exists and you have access to it."
+
+	DeploymentCreateEndpoint   = "/v3/deployments"
+	DeploymentListEndpoint     = "/v3/deployments"
+	DeploymentGetEndpoint      = "/v3/deployments/{guid}"
+	DeploymentCancelEndpoint   = "/v3/deployments/{guid}/actions/cancel"
+	DeploymentContinueEndpoint = "/v3/deployments/{guid}/actions/continue"
+)
+
+//counterfeiter:generate -o fake -fake-name CFDeploymentRepository . CFDeploymentRepository
+
+type CFDeploymentRepository interface {
+	CreateDeployment(context.Context, client.Client, repositories.DeploymentCreateMessage) (repositories.DeploymentRecord, error)
+	FetchDeployment(context.Context, client.Client, string) (repositories.DeploymentRecord, error)
+	FetchDeploymentList(context.Context, client.Client, repositories.DeploymentListMessage) ([]repositories.DeploymentRecord, error)
+	CancelDeployment(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error)
+	ContinueDeployment(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error)
+}
+
+type DeploymentHandler struct {
+	logger         logr.Logger
+	serverURL      url.URL
+	deploymentRepo CFDeploymentRepository
+	appRepo        CFAppRepository
+	dropletRepo    CFDropletRepository
+	revisionRepo   CFRevisionRepository
+	buildClient    ClientBuilder
+	k8sConfig      *rest.Config
+}
+
+func NewDeploymentHandler(
+	logger logr.Logger,
+	serverURL url.URL,
+	deploymentRepo CFDeploymentRepository,
+	appRepo CFAppRepository,
+	dropletRepo CFDropletRepository,
+	revisionRepo CFRevisionRepository,
+	buildClient ClientBuilder,
+	k8sConfig *rest.Config) *DeploymentHandler {
+	return &DeploymentHandler{
+		logger:         logger,
+		serverURL:      serverURL,
+		deploymentRepo: deploymentRepo,
+		appRepo:        appRepo,
+		dropletRepo:    dropletRepo,
+		revisionRepo:   revisionRepo,
+		buildClient:    buildClient,
+		k8sConfig:      k8sConfig,
+	}
+}
+
+func (h *DeploymentHandler) deploymentCreateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	var payload payloads.DeploymentCreate
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	appGUID := payload.Relationships.App.Data.GUID
+	app, err := h.appRepo.FetchApp(ctx, client, appGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("App not found", "AppGUID", appGUID)
+			writeUnprocessableEntityError(w, "Unable to use app. Ensure that the app exists and you have access to it.")
+			return
+		}
+		h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	if payload.Droplet != nil && payload.Revision != nil {
+		writeUnprocessableEntityError(w, "Cannot set both droplet and revision.")
+		return
+	}
+
+	dropletGUID := app.DropletGUID
+	if payload.Droplet != nil {
+		dropletGUID = payload.Droplet.GUID
+	}
+
+	var revision *repositories.RevisionRecord
+	if payload.Revision != nil {
+		revisionGUID := payload.Revision.GUID
+		record, err := h.revisionRepo.FetchRevision(ctx, client, revisionGUID)
+		if err != nil {
+			if errors.As(err, new(repositories.NotFoundError)) {
+				h.logger.Info("Revision not found", "RevisionGUID", revisionGUID)
+				writeUnprocessableEntityError(w, invalidRevisionMsg)
+				return
+			}
+			h.logger.Error(err, "Failed to fetch revision from Kubernetes", "RevisionGUID", revisionGUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+		if record.AppGUID != appGUID {
+			h.logger.Info("Revision belongs to another app", "RevisionGUID", revisionGUID, "AppGUID", appGUID)
+			writeUnprocessableEntityError(w, invalidRevisionMsg)
+			return
+		}
+		revision = &record
+		dropletGUID = revision.DropletGUID
+	}
+
+	if dropletGUID == "" {
+		h.logger.Info("App has no current droplet", "AppGUID", appGUID)
+		writeUnprocessableEntityError(w, invalidDropletMsg)
+		return
+	}
+
+	droplet, err := h.dropletRepo.FetchDroplet(ctx, client, dropletGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Droplet not found", "DropletGUID", dropletGUID)
+			if revision != nil {
+				writeUnprocessableEntityError(w, "Unable to deploy this revision, the droplet for this revision no longer exists.")
+				return
+			}
+			writeUnprocessableEntityError(w, invalidDropletMsg)
+			return
+		}
+		h.logger.Error(err, "Failed to fetch droplet from Kubernetes", "DropletGUID", dropletGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+	if droplet.AppGUID != appGUID {
+		h.logger.Info("Droplet belongs to another app", "DropletGUID", dropletGUID, "AppGUID", appGUID)
+		writeUnprocessableEntityError(w, invalidDropletMsg)
+		return
+	}
+
+	deployment, err := h.deploymentRepo.CreateDeployment(ctx, client, payload.ToMessage(app, droplet, revision))
+	if err != nil {
+		if errors.As(err, new(repositories.ActiveDeploymentError)) {
+			h.logger.Info("App already has an active deployment", "AppGUID", appGUID)
+			writeUnprocessableEntityError(w, err.Error())
+			return
+		}
+		h.logger.Error(err, "Failed to create deployment", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	h.writeDeployment(w, http.StatusCreated, deployment)
+}
+
+func (h *DeploymentHandler) deploymentListHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := checkQueryParameters(r, "app_guids", "status_values"); err != nil {
+		h.logger.Info("Unknown query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	deployments, err := h.deploymentRepo.FetchDeploymentList(ctx, client, repositories.DeploymentListMessage{
+		AppGUIDs:     parseCommaSeparatedList(query.Get("app_guids")),
+		StatusValues: parseCommaSeparatedList(query.Get("status_values")),
+	})
+	if err != nil {
+		h.logger.Error(err, "Failed to fetch deployments from Kubernetes")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	start, end := pageRequest.Bounds(len(deployments))
+	listPage := newListPage(r, pageRequest, len(deployments))
+	responseBody, err := json.Marshal(presenter.ForDeploymentList(deployments[start:end], h.serverURL, listPage))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *DeploymentHandler) deploymentGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, deployment, ok := h.clientAndDeployment(w, r, mux.Vars(r)["guid"])
+	if !ok {
+		return
+	}
+
+	h.writeDeployment(w, http.StatusOK, deployment)
+}
+
+func (h *DeploymentHandler) deploymentCancelHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeDeploymentStatus(w, r, "cancel", h.deploymentRepo.CancelDeployment)
+}
+
+func (h *DeploymentHandler) deploymentContinueHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeDeploymentStatus(w, r, "continue", h.deploymentRepo.ContinueDeployment)
+}
+
+// changeDeploymentStatus fetches the deployment of the request, applies one of the actions of the deployment
+// repository to it and responds with the resulting deployment
+func (h *DeploymentHandler) changeDeploymentStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	change func(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error),
+) {
+	w.Header().Set("Content-Type", "application/json")
+	deploymentGUID := mux.Vars(r)["guid"]
+
+	client, deployment, ok := h.clientAndDeployment(w, r, deploymentGUID)
+	if !ok {
+		return
+	}
+
+	deployment, err := change(r.Context(), client, deployment)
+	if err != nil {
+		var statusErr repositories.DeploymentStatusError
+		switch {
+		case errors.As(err, &statusErr):
+			h.logger.Info("Deployment status doesn't allow action", "DeploymentGUID", deploymentGUID, "Action", action)
+			writeUnprocessableEntityError(w, statusErr.Error())
+		case errors.As(err, new(repositories.DeploymentPromotingError)):
+			h.logger.Info("Deployment is promoting", "DeploymentGUID", deploymentGUID, "Action", action)
+			writeUnprocessableEntityError(w, err.Error())
+		case errors.As(err, new(repositories.NotFoundError)):
+			h.logger.Info("Deployment not found", "DeploymentGUID", deploymentGUID)
+			writeNotFoundErrorResponse(w, "Deployment")
+		case errors.As(err, new(repositories.ConflictError)):
+			h.logger.Info("Deployment was changed by another request", "DeploymentGUID", deploymentGUID)
+			writeConflictError(w, "The deployment was changed while the request was made. Fetch it again and retry.")
+		default:
+			h.logger.Error(err, "Failed to "+action+" deployment", "DeploymentGUID", deploymentGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return
+	}
+
+	h.writeDeployment(w, http.StatusOK, deployment)
+}
+
+// clientAndDeployment builds a client for the user of r and fetches the deployment with it. The error response has
+// been written when ok is false.
+func (h *DeploymentHandler) clientAndDeployment(w http.ResponseWriter, r *http.Request, deploymentGUID string) (client.Client, repositories.DeploymentRecord, bool) {
+	client, ok := h.client(w, r)
+	if !ok {
+		return nil, repositories.DeploymentRecord{}, false
+	}
+
+	deployment, err := h.deploymentRepo.FetchDeployment(r.Context(), client, deploymentGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Deployment not found", "DeploymentGUID", deploymentGUID)
+			writeNotFoundErrorResponse(w, "Deployment")
+		} else {
+			h.logger.Error(err, "Failed to fetch deployment from Kubernetes", "DeploymentGUID", deploymentGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return nil, repositories.DeploymentRecord{}, false
+	}
+
+	return client, deployment, true
+}
+
+func (h *DeploymentHandler) client(w http.ResponseWriter, r *http.Request) (client.Client, bool) {
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (h *DeploymentHandler) writeDeployment(w http.ResponseWriter, status int, deployment repositories.DeploymentRecord) {
+	responseBody, err := json.Marshal(presenter.ForDeployment(deployment, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "DeploymentGUID", deployment.GUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(responseBody)
+}
+
+func (h *DeploymentHandler) RegisterRoutes(router *mux.Router) {
+	router.Path(DeploymentCreateEndpoint).Methods("POST").HandlerFunc(h.deploymentCreateHandler)
+	router.Path(DeploymentListEndpoint).Methods("GET").HandlerFunc(h.deploymentListHandler)
+	router.Path(DeploymentGetEndpoint).Methods("GET").HandlerFunc(h.deploymentGetHandler)
+	router.Path(DeploymentCancelEndpoint).Methods("POST").HandlerFunc(h.deploymentCancelHandler)
+	router.Path(DeploymentContinueEndpoint).Methods("POST").HandlerFunc(h.deploymentContinueHandler)
+}
@@ -0,0 +1,537 @@
+package apis_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	. "code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("DeploymentHandler", func() {
+	const (
+		appGUID        = "app-guid"
+		spaceGUID      = "space-guid"
+		dropletGUID    = "droplet-guid"
+		deploymentGUID = "deployment-guid"
+	)
+
+	var (
+		deploymentRepo   *fake.CFDeploymentRepository
+		appRepo          *fake.CFAppRepository
+		dropletRepo      *fake.CFDropletRepository
+		revisionRepo     *fake.CFRevisionRepository
+		clientBuilder    *fake.ClientBuilder
+		appRecord        repositories.AppRecord
+		dropletRecord    repositories.DropletRecord
+		deploymentRecord repositories.DeploymentRecord
+	)
+
+	BeforeEach(func() {
+		deploymentRepo = new(fake.CFDeploymentRepository)
+		appRepo = new(fake.CFAppRepository)
+		dropletRepo = new(fake.CFDropletRepository)
+		revisionRepo = new(fake.CFRevisionRepository)
+		clientBuilder = new(fake.ClientBuilder)
+
+		appRecord = repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID, DropletGUID: "current-droplet-guid", State: repositories.StartedState}
+		dropletRecord = repositories.DropletRecord{GUID: dropletGUID, AppGUID: appGUID}
+		deploymentRecord = repositories.DeploymentRecord{
+			GUID:                deploymentGUID,
+			AppGUID:             appGUID,
+			SpaceGUID:           spaceGUID,
+			Strategy:            repositories.DeploymentStrategyRolling,
+			DropletGUID:         dropletGUID,
+			PreviousDropletGUID: "current-droplet-guid",
+			StatusValue:         repositories.DeploymentStatusValueActive,
+			StatusReason:        repositories.DeploymentStatusReasonDeploying,
+			LastStatusChange:    "2021-10-12T15:00:00Z",
+			CreatedAt:           "2021-10-12T15:00:00Z",
+			UpdatedAt:           "2021-10-12T15:00:01Z",
+		}
+		appRepo.FetchAppReturns(appRecord, nil)
+		dropletRepo.FetchDropletReturns(dropletRecord, nil)
+		deploymentRepo.FetchDeploymentReturns(deploymentRecord, nil)
+
+		apiHandler := NewDeploymentHandler(
+			logf.Log.WithName("TestDeploymentHandler"),
+			*serverURL,
+			deploymentRepo,
+			appRepo,
+			dropletRepo,
+			revisionRepo,
+			clientBuilder.Spy,
+			&rest.Config{},
+		)
+		apiHandler.RegisterRoutes(router)
+	})
+
+	JustBeforeEach(func() {
+		router.ServeHTTP(rr, req)
+	})
+
+	deploymentJSON := func(state, reason string) string {
+		value := "ACTIVE"
+		if state == "DEPLOYED" || state == "CANCELED" {
+			value = "FINALIZED"
+		}
+		return `{
+			"guid": "deployment-guid",
+			"state": "` + state + `",
+			"status": {
+				"value": "` + value + `",
+				"reason": "` + reason + `",
+				"details": { "last_status_change": "2021-10-12T15:00:00Z" }
+			},
+			"strategy": "rolling",
+			"droplet": { "guid": "droplet-guid" },
+			"previous_droplet": { "guid": "current-droplet-guid" },
+			"new_processes": [],
+			"relationships": {
+				"app": { "data": { "guid": "app-guid" } }
+			},
+			"metadata": { "labels": {}, "annotations": {} },
+			"created_at": "2021-10-12T15:00:00Z",
+			"updated_at": "2021-10-12T15:00:01Z",
+			"links": {
+				"self": { "href": "` + defaultServerURL + `/v3/deployments/deployment-guid" },
+				"app": { "href": "` + defaultServerURL + `/v3/apps/app-guid" },
+				"cancel": { "href": "` + defaultServerURL + `/v3/deployments/deployment-guid/actions/cancel", "method": "POST" }
+			}
+		}`
+	}
+
+	Describe("the POST /v3/deployments endpoint", func() {
+		makeCreateRequest := func(body string) {
+			var err error
+			req, err = http.NewRequest("POST", "/v3/deployments", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			deploymentRepo.CreateDeploymentReturns(deploymentRecord, nil)
+			makeCreateRequest(`{
+				"droplet": { "guid": "droplet-guid" },
+				"relationships": { "app": { "data": { "guid": "app-guid" } } }
+			}`)
+		})
+
+		When("on the happy path", func() {
+			It("deploys the droplet with the rolling strategy", func() {
+				Expect(deploymentRepo.CreateDeploymentCallCount()).To(Equal(1))
+				_, _, message := deploymentRepo.CreateDeploymentArgsForCall(0)
+				Expect(message).To(Equal(repositories.DeploymentCreateMessage{
+					App:      appRecord,
+					Droplet:  dropletRecord,
+					Strategy: repositories.DeploymentStrategyRolling,
+				}))
+			})
+
+			It("returns the deployment", func() {
+				expectJSONResponse(http.StatusCreated, deploymentJSON("DEPLOYING", "DEPLOYING"))
+			})
+		})
+
+		When("no droplet is given", func() {
+			BeforeEach(func() {
+				makeCreateRequest(`{ "strategy": "rolling", "relationships": { "app": { "data": { "guid": "app-guid" } } } }`)
+			})
+
+			It("deploys the current droplet of the app", func() {
+				Expect(dropletRepo.FetchDropletCallCount()).To(Equal(1))
+				_, _, actualDropletGUID := dropletRepo.FetchDropletArgsForCall(0)
+				Expect(actualDropletGUID).To(Equal("current-droplet-guid"))
+
+				_, _, message := deploymentRepo.CreateDeploymentArgsForCall(0)
+				Expect(message.Strategy).To(Equal(repositories.DeploymentStrategyRolling))
+			})
+		})
+
+		When("the canary strategy is given", func() {
+			BeforeEach(func() {
+				canary := deploymentRecord
+				canary.Strategy = repositories.DeploymentStrategyCanary
+				canary.NewProcesses = []repositories.DeploymentProcessRecord{{GUID: "new-process-guid", Type: "web"}}
+				deploymentRepo.CreateDeploymentReturns(canary, nil)
+				makeCreateRequest(`{ "strategy": "canary", "relationships": { "app": { "data": { "guid": "app-guid" } } } }`)
+			})
+
+			It("deploys the droplet with the canary strategy", func() {
+				_, _, message := deploymentRepo.CreateDeploymentArgsForCall(0)
+				Expect(message.Strategy).To(Equal(repositories.DeploymentStrategyCanary))
+			})
+
+			It("returns the deployment with its new processes and a link to continue it", func() {
+				expectJSONResponse(http.StatusCreated, `{
+					"guid": "deployment-guid",
+					"state": "DEPLOYING",
+					"status": {
+						"value": "ACTIVE",
+						"reason": "DEPLOYING",
+						"details": { "last_status_change": "2021-10-12T15:00:00Z" }
+					},
+					"strategy": "canary",
+					"droplet": { "guid": "droplet-guid" },
+					"previous_droplet": { "guid": "current-droplet-guid" },
+					"new_processes": [ { "guid": "new-process-guid", "type": "web" } ],
+					"relationships": {
+						"app": { "data": { "guid": "app-guid" } }
+					},
+					"metadata": { "labels": {}, "annotations": {} },
+					"created_at": "2021-10-12T15:00:00Z",
+					"updated_at": "2021-10-12T15:00:01Z",
+					"links": {
+						"self": { "href": "`+defaultServerURL+`/v3/deployments/deployment-guid" },
+						"app": { "href": "`+defaultServerURL+`/v3/apps/app-guid" },
+						"cancel": { "href": "`+defaultServerURL+`/v3/deployments/deployment-guid/actions/cancel", "method": "POST" },
+						"continue": { "href": "`+defaultServerURL+`/v3/deployments/deployment-guid/actions/continue", "method": "POST" }
+					}
+				}`)
+			})
+		})
+
+		When("a revision is given", func() {
+			var revisionRecord repositories.RevisionRecord
+
+			BeforeEach(func() {
+				revisionRecord = repositories.RevisionRecord{
+					GUID:        "revision-guid",
+					Version:     2,
+					AppGUID:     appGUID,
+					DropletGUID: "revision-droplet-guid",
+				}
+				revisionRepo.FetchRevisionReturns(revisionRecord, nil)
+				makeCreateRequest(`{ "revision": { "guid": "revision-guid" }, "relationships": { "app": { "data": { "guid": "app-guid" } } } }`)
+			})
+
+			It("rolls the app back to the revision", func() {
+				Expect(revisionRepo.FetchRevisionCallCount()).To(Equal(1))
+				_, _, actualRevisionGUID := revisionRepo.FetchRevisionArgsForCall(0)
+				Expect(actualRevisionGUID).To(Equal("revision-guid"))
+
+				_, _, actualDropletGUID := dropletRepo.FetchDropletArgsForCall(0)
+				Expect(actualDropletGUID).To(Equal("revision-droplet-guid"))
+
+				_, _, message := deploymentRepo.CreateDeploymentArgsForCall(0)
+				Expect(message.Revision).To(Equal(&revisionRecord))
+			})
+
+			When("the revision doesn't exist", func() {
+				BeforeEach(func() {
+					revisionRepo.FetchRevisionReturns(repositories.RevisionRecord{}, repositories.NotFoundError{})
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Unable to use revision. Ensure that the revision exists and you have access to it.")
+				})
+			})
+
+			When("the revision belongs to another app", func() {
+				BeforeEach(func() {
+					revisionRecord.AppGUID = "other-app-guid"
+					revisionRepo.FetchRevisionReturns(revisionRecord, nil)
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Unable to use revision. Ensure that the revision exists and you have access to it.")
+				})
+			})
+
+			When("the droplet of the revision has been deleted", func() {
+				BeforeEach(func() {
+					dropletRepo.FetchDropletReturns(repositories.DropletRecord{}, repositories.NotFoundError{})
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Unable to deploy this revision, the droplet for this revision no longer exists.")
+				})
+			})
+
+			When("a droplet is given as well", func() {
+				BeforeEach(func() {
+					makeCreateRequest(`{
+						"droplet": { "guid": "droplet-guid" },
+						"revision": { "guid": "revision-guid" },
+						"relationships": { "app": { "data": { "guid": "app-guid" } } }
+					}`)
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Cannot set both droplet and revision.")
+				})
+			})
+		})
+
+		When("the strategy is unknown", func() {
+			BeforeEach(func() {
+				makeCreateRequest(`{ "strategy": "blue-green", "relationships": { "app": { "data": { "guid": "app-guid" } } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Strategy must be one of [rolling canary]")
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Unable to use app. Ensure that the app exists and you have access to it.")
+			})
+		})
+
+		When("the droplet doesn't exist", func() {
+			BeforeEach(func() {
+				dropletRepo.FetchDropletReturns(repositories.DropletRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Unable to assign current droplet. Ensure the droplet exists and belongs to this app.")
+			})
+		})
+
+		When("the droplet belongs to another app", func() {
+			BeforeEach(func() {
+				dropletRepo.FetchDropletReturns(repositories.DropletRecord{GUID: dropletGUID, AppGUID: "other-app-guid"}, nil)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Unable to assign current droplet. Ensure the droplet exists and belongs to this app.")
+			})
+
+			It("doesn't create a deployment", func() {
+				Expect(deploymentRepo.CreateDeploymentCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the app has an active deployment", func() {
+			BeforeEach(func() {
+				deploymentRepo.CreateDeploymentReturns(repositories.DeploymentRecord{}, repositories.ActiveDeploymentError{})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError(repositories.ActiveDeploymentError{}.Error())
+			})
+		})
+
+		When("creating the deployment fails", func() {
+			BeforeEach(func() {
+				deploymentRepo.CreateDeploymentReturns(repositories.DeploymentRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/deployments endpoint", func() {
+		BeforeEach(func() {
+			deploymentRepo.FetchDeploymentListReturns([]repositories.DeploymentRecord{deploymentRecord}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/deployments", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("returns the deployments", func() {
+				expectJSONResponse(http.StatusOK, `{
+					"pagination": {
+						"total_results": 1,
+						"total_pages": 1,
+						"first": { "href": "`+defaultServerURL+`/v3/deployments?page=1&per_page=50" },
+						"last": { "href": "`+defaultServerURL+`/v3/deployments?page=1&per_page=50" },
+						"next": null,
+						"previous": null
+					},
+					"resources": [`+deploymentJSON("DEPLOYING", "DEPLOYING")+`]
+				}`)
+			})
+		})
+
+		When("filters are given", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "app_guids=app1,app2&status_values=ACTIVE"
+			})
+
+			It("passes them to the repository", func() {
+				Expect(deploymentRepo.FetchDeploymentListCallCount()).To(Equal(1))
+				_, _, message := deploymentRepo.FetchDeploymentListArgsForCall(0)
+				Expect(message).To(Equal(repositories.DeploymentListMessage{
+					AppGUIDs:     []string{"app1", "app2"},
+					StatusValues: []string{"ACTIVE"},
+				}))
+			})
+		})
+
+		When("fetching the deployments fails", func() {
+			BeforeEach(func() {
+				deploymentRepo.FetchDeploymentListReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/deployments/:guid endpoint", func() {
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("GET", "/v3/deployments/"+deploymentGUID, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("returns the deployment", func() {
+				expectJSONResponse(http.StatusOK, deploymentJSON("DEPLOYING", "DEPLOYING"))
+			})
+		})
+
+		When("the deployment has finished", func() {
+			BeforeEach(func() {
+				deploymentRecord.StatusValue = repositories.DeploymentStatusValueFinalized
+				deploymentRecord.StatusReason = repositories.DeploymentStatusReasonDeployed
+				deploymentRepo.FetchDeploymentReturns(deploymentRecord, nil)
+			})
+
+			It("returns the deployment as deployed", func() {
+				expectJSONResponse(http.StatusOK, deploymentJSON("DEPLOYED", "DEPLOYED"))
+			})
+		})
+
+		When("the deployment doesn't exist", func() {
+			BeforeEach(func() {
+				deploymentRepo.FetchDeploymentReturns(repositories.DeploymentRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Deployment not found")
+			})
+		})
+	})
+
+	Describe("the POST /v3/deployments/:guid/actions/cancel endpoint", func() {
+		BeforeEach(func() {
+			canceling := deploymentRecord
+			canceling.StatusReason = repositories.DeploymentStatusReasonCanceling
+			deploymentRepo.CancelDeploymentReturns(canceling, nil)
+
+			var err error
+			req, err = http.NewRequest("POST", "/v3/deployments/"+deploymentGUID+"/actions/cancel", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("cancels the deployment", func() {
+				Expect(deploymentRepo.CancelDeploymentCallCount()).To(Equal(1))
+				_, _, actualDeployment := deploymentRepo.CancelDeploymentArgsForCall(0)
+				Expect(actualDeployment).To(Equal(deploymentRecord))
+			})
+
+			It("returns the canceling deployment", func() {
+				expectJSONResponse(http.StatusOK, deploymentJSON("CANCELING", "CANCELING"))
+			})
+		})
+
+		When("the deployment has finished", func() {
+			BeforeEach(func() {
+				deploymentRepo.CancelDeploymentReturns(repositories.DeploymentRecord{}, repositories.DeploymentStatusError{
+					Action: "cancel",
+					Value:  repositories.DeploymentStatusValueFinalized,
+					Reason: repositories.DeploymentStatusReasonDeployed,
+				})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Cannot cancel a deployment with status: FINALIZED and reason: DEPLOYED.")
+			})
+		})
+
+		When("the deployment doesn't exist", func() {
+			BeforeEach(func() {
+				deploymentRepo.FetchDeploymentReturns(repositories.DeploymentRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Deployment not found")
+			})
+		})
+
+		When("the app of the deployment already runs the new droplet", func() {
+			BeforeEach(func() {
+				deploymentRepo.CancelDeploymentReturns(repositories.DeploymentRecord{}, repositories.DeploymentPromotingError{})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Cannot cancel a deployment once its app runs the new droplet.")
+			})
+		})
+
+		When("the deployment was changed concurrently", func() {
+			BeforeEach(func() {
+				deploymentRepo.CancelDeploymentReturns(repositories.DeploymentRecord{}, repositories.ConflictError{})
+			})
+
+			It("returns an error", func() {
+				Expect(rr.Code).To(Equal(http.StatusConflict))
+			})
+		})
+	})
+
+	Describe("the POST /v3/deployments/:guid/actions/continue endpoint", func() {
+		BeforeEach(func() {
+			deploymentRepo.ContinueDeploymentReturns(deploymentRecord, nil)
+
+			var err error
+			req, err = http.NewRequest("POST", "/v3/deployments/"+deploymentGUID+"/actions/continue", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("continues the deployment", func() {
+				Expect(deploymentRepo.ContinueDeploymentCallCount()).To(Equal(1))
+				_, _, actualDeployment := deploymentRepo.ContinueDeploymentArgsForCall(0)
+				Expect(actualDeployment).To(Equal(deploymentRecord))
+			})
+
+			It("returns the deploying deployment", func() {
+				expectJSONResponse(http.StatusOK, deploymentJSON("DEPLOYING", "DEPLOYING"))
+			})
+		})
+
+		When("the deployment is not paused", func() {
+			BeforeEach(func() {
+				deploymentRepo.ContinueDeploymentReturns(repositories.DeploymentRecord{}, repositories.DeploymentStatusError{
+					Action: "continue",
+					Value:  repositories.DeploymentStatusValueActive,
+					Reason: repositories.DeploymentStatusReasonDeploying,
+				})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Cannot continue a deployment with status: ACTIVE and reason: DEPLOYING.")
+			})
+		})
+
+		When("the deployment doesn't exist", func() {
+			BeforeEach(func() {
+				deploymentRepo.FetchDeploymentReturns(repositories.DeploymentRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Deployment not found")
+			})
+		})
+	})
+})
@@ -11,6 +11,7 @@ import (
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 
+	"github.com/go-http-utils/headers"
 	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
 	"k8s.io/client-go/rest"
@@ -55,12 +56,9 @@ func (h *DropletHandler) dropletGetHandler(w http.ResponseWriter, r *http.Reques
 	vars := mux.Vars(r)
 	dropletGUID := vars["guid"]
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "dropletGUID", dropletGUID)
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err, "dropletGUID", dropletGUID)
 		return
 	}
 
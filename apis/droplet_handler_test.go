@@ -5,9 +5,11 @@ import (
 	"net/http"
 
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"github.com/go-http-utils/headers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/rest"
@@ -37,6 +39,7 @@ var _ = Describe("DropletHandler", func() {
 			var err error
 			req, err = http.NewRequest("GET", "/v3/droplets/"+dropletGUID, nil)
 			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
 
 			clientBuilder = new(fake.ClientBuilder)
 
@@ -86,6 +89,8 @@ var _ = Describe("DropletHandler", func() {
 
 				It("configures the client", func() {
 					Expect(clientBuilder.CallCount()).To(Equal(1))
+					_, actualAuthHeader := clientBuilder.ArgsForCall(0)
+					Expect(actualAuthHeader).To(Equal(authHeader))
 				})
 
 				It("fetches the right droplet", func() {
@@ -160,6 +165,17 @@ var _ = Describe("DropletHandler", func() {
 			})
 		})
 
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+				router.ServeHTTP(rr, req)
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+		})
+
 		When("the droplet cannot be found", func() {
 			BeforeEach(func() {
 				dropletRepo.FetchDropletReturns(repositories.DropletRecord{}, repositories.NotFoundError{})
@@ -41,6 +41,19 @@ type CFAppRepository struct {
 		result1 repositories.AppEnvVarsRecord
 		result2 error
 	}
+	DeleteAppStub        func(context.Context, client.Client, repositories.DeleteAppMessage) error
+	deleteAppMutex       sync.RWMutex
+	deleteAppArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeleteAppMessage
+	}
+	deleteAppReturns struct {
+		result1 error
+	}
+	deleteAppReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FetchAppStub        func(context.Context, client.Client, string) (repositories.AppRecord, error)
 	fetchAppMutex       sync.RWMutex
 	fetchAppArgsForCall []struct {
@@ -56,19 +69,37 @@ type CFAppRepository struct {
 		result1 repositories.AppRecord
 		result2 error
 	}
-	FetchAppListStub        func(context.Context, client.Client) ([]repositories.AppRecord, error)
+	FetchAppEnvVarsStub        func(context.Context, client.Client, repositories.AppRecord) (repositories.AppEnvVarsRecord, error)
+	fetchAppEnvVarsMutex       sync.RWMutex
+	fetchAppEnvVarsArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}
+	fetchAppEnvVarsReturns struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}
+	fetchAppEnvVarsReturnsOnCall map[int]struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}
+	FetchAppListStub        func(context.Context, client.Client, repositories.AppListMessage) ([]repositories.AppRecord, int, error)
 	fetchAppListMutex       sync.RWMutex
 	fetchAppListArgsForCall []struct {
 		arg1 context.Context
 		arg2 client.Client
+		arg3 repositories.AppListMessage
 	}
 	fetchAppListReturns struct {
 		result1 []repositories.AppRecord
-		result2 error
+		result2 int
+		result3 error
 	}
 	fetchAppListReturnsOnCall map[int]struct {
 		result1 []repositories.AppRecord
-		result2 error
+		result2 int
+		result3 error
 	}
 	FetchNamespaceStub        func(context.Context, client.Client, string) (repositories.SpaceRecord, error)
 	fetchNamespaceMutex       sync.RWMutex
@@ -85,18 +116,48 @@ type CFAppRepository struct {
 		result1 repositories.SpaceRecord
 		result2 error
 	}
-	SetAppDesiredStateStub        func(context.Context, client.Client, repositories.SetAppDesiredStateMessage) (repositories.AppRecord, error)
-	setAppDesiredStateMutex       sync.RWMutex
-	setAppDesiredStateArgsForCall []struct {
+	PatchAppStub        func(context.Context, client.Client, repositories.PatchAppMessage) (repositories.AppRecord, error)
+	patchAppMutex       sync.RWMutex
+	patchAppArgsForCall []struct {
 		arg1 context.Context
 		arg2 client.Client
-		arg3 repositories.SetAppDesiredStateMessage
+		arg3 repositories.PatchAppMessage
 	}
-	setAppDesiredStateReturns struct {
+	patchAppReturns struct {
 		result1 repositories.AppRecord
 		result2 error
 	}
-	setAppDesiredStateReturnsOnCall map[int]struct {
+	patchAppReturnsOnCall map[int]struct {
+		result1 repositories.AppRecord
+		result2 error
+	}
+	PatchAppEnvVarsStub        func(context.Context, client.Client, repositories.PatchAppEnvVarsMessage) (repositories.AppEnvVarsRecord, error)
+	patchAppEnvVarsMutex       sync.RWMutex
+	patchAppEnvVarsArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.PatchAppEnvVarsMessage
+	}
+	patchAppEnvVarsReturns struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}
+	patchAppEnvVarsReturnsOnCall map[int]struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}
+	RestartAppStub        func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
+	restartAppMutex       sync.RWMutex
+	restartAppArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}
+	restartAppReturns struct {
+		result1 repositories.AppRecord
+		result2 error
+	}
+	restartAppReturnsOnCall map[int]struct {
 		result1 repositories.AppRecord
 		result2 error
 	}
@@ -115,6 +176,36 @@ type CFAppRepository struct {
 		result1 repositories.CurrentDropletRecord
 		result2 error
 	}
+	StartAppStub        func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
+	startAppMutex       sync.RWMutex
+	startAppArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}
+	startAppReturns struct {
+		result1 repositories.AppRecord
+		result2 error
+	}
+	startAppReturnsOnCall map[int]struct {
+		result1 repositories.AppRecord
+		result2 error
+	}
+	StopAppStub        func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)
+	stopAppMutex       sync.RWMutex
+	stopAppArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}
+	stopAppReturns struct {
+		result1 repositories.AppRecord
+		result2 error
+	}
+	stopAppReturnsOnCall map[int]struct {
+		result1 repositories.AppRecord
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -251,6 +342,69 @@ func (fake *CFAppRepository) CreateAppEnvironmentVariablesReturnsOnCall(i int, r
 	}{result1, result2}
 }
 
+func (fake *CFAppRepository) DeleteApp(arg1 context.Context, arg2 client.Client, arg3 repositories.DeleteAppMessage) error {
+	fake.deleteAppMutex.Lock()
+	ret, specificReturn := fake.deleteAppReturnsOnCall[len(fake.deleteAppArgsForCall)]
+	fake.deleteAppArgsForCall = append(fake.deleteAppArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeleteAppMessage
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteAppStub
+	fakeReturns := fake.deleteAppReturns
+	fake.recordInvocation("DeleteApp", []interface{}{arg1, arg2, arg3})
+	fake.deleteAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *CFAppRepository) DeleteAppCallCount() int {
+	fake.deleteAppMutex.RLock()
+	defer fake.deleteAppMutex.RUnlock()
+	return len(fake.deleteAppArgsForCall)
+}
+
+func (fake *CFAppRepository) DeleteAppCalls(stub func(context.Context, client.Client, repositories.DeleteAppMessage) error) {
+	fake.deleteAppMutex.Lock()
+	defer fake.deleteAppMutex.Unlock()
+	fake.DeleteAppStub = stub
+}
+
+func (fake *CFAppRepository) DeleteAppArgsForCall(i int) (context.Context, client.Client, repositories.DeleteAppMessage) {
+	fake.deleteAppMutex.RLock()
+	defer fake.deleteAppMutex.RUnlock()
+	argsForCall := fake.deleteAppArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFAppRepository) DeleteAppReturns(result1 error) {
+	fake.deleteAppMutex.Lock()
+	defer fake.deleteAppMutex.Unlock()
+	fake.DeleteAppStub = nil
+	fake.deleteAppReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *CFAppRepository) DeleteAppReturnsOnCall(i int, result1 error) {
+	fake.deleteAppMutex.Lock()
+	defer fake.deleteAppMutex.Unlock()
+	fake.DeleteAppStub = nil
+	if fake.deleteAppReturnsOnCall == nil {
+		fake.deleteAppReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteAppReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *CFAppRepository) FetchApp(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.AppRecord, error) {
 	fake.fetchAppMutex.Lock()
 	ret, specificReturn := fake.fetchAppReturnsOnCall[len(fake.fetchAppArgsForCall)]
@@ -317,24 +471,91 @@ func (fake *CFAppRepository) FetchAppReturnsOnCall(i int, result1 repositories.A
 	}{result1, result2}
 }
 
-func (fake *CFAppRepository) FetchAppList(arg1 context.Context, arg2 client.Client) ([]repositories.AppRecord, error) {
+func (fake *CFAppRepository) FetchAppEnvVars(arg1 context.Context, arg2 client.Client, arg3 repositories.AppRecord) (repositories.AppEnvVarsRecord, error) {
+	fake.fetchAppEnvVarsMutex.Lock()
+	ret, specificReturn := fake.fetchAppEnvVarsReturnsOnCall[len(fake.fetchAppEnvVarsArgsForCall)]
+	fake.fetchAppEnvVarsArgsForCall = append(fake.fetchAppEnvVarsArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}{arg1, arg2, arg3})
+	stub := fake.FetchAppEnvVarsStub
+	fakeReturns := fake.fetchAppEnvVarsReturns
+	fake.recordInvocation("FetchAppEnvVars", []interface{}{arg1, arg2, arg3})
+	fake.fetchAppEnvVarsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFAppRepository) FetchAppEnvVarsCallCount() int {
+	fake.fetchAppEnvVarsMutex.RLock()
+	defer fake.fetchAppEnvVarsMutex.RUnlock()
+	return len(fake.fetchAppEnvVarsArgsForCall)
+}
+
+func (fake *CFAppRepository) FetchAppEnvVarsCalls(stub func(context.Context, client.Client, repositories.AppRecord) (repositories.AppEnvVarsRecord, error)) {
+	fake.fetchAppEnvVarsMutex.Lock()
+	defer fake.fetchAppEnvVarsMutex.Unlock()
+	fake.FetchAppEnvVarsStub = stub
+}
+
+func (fake *CFAppRepository) FetchAppEnvVarsArgsForCall(i int) (context.Context, client.Client, repositories.AppRecord) {
+	fake.fetchAppEnvVarsMutex.RLock()
+	defer fake.fetchAppEnvVarsMutex.RUnlock()
+	argsForCall := fake.fetchAppEnvVarsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFAppRepository) FetchAppEnvVarsReturns(result1 repositories.AppEnvVarsRecord, result2 error) {
+	fake.fetchAppEnvVarsMutex.Lock()
+	defer fake.fetchAppEnvVarsMutex.Unlock()
+	fake.FetchAppEnvVarsStub = nil
+	fake.fetchAppEnvVarsReturns = struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) FetchAppEnvVarsReturnsOnCall(i int, result1 repositories.AppEnvVarsRecord, result2 error) {
+	fake.fetchAppEnvVarsMutex.Lock()
+	defer fake.fetchAppEnvVarsMutex.Unlock()
+	fake.FetchAppEnvVarsStub = nil
+	if fake.fetchAppEnvVarsReturnsOnCall == nil {
+		fake.fetchAppEnvVarsReturnsOnCall = make(map[int]struct {
+			result1 repositories.AppEnvVarsRecord
+			result2 error
+		})
+	}
+	fake.fetchAppEnvVarsReturnsOnCall[i] = struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) FetchAppList(arg1 context.Context, arg2 client.Client, arg3 repositories.AppListMessage) ([]repositories.AppRecord, int, error) {
 	fake.fetchAppListMutex.Lock()
 	ret, specificReturn := fake.fetchAppListReturnsOnCall[len(fake.fetchAppListArgsForCall)]
 	fake.fetchAppListArgsForCall = append(fake.fetchAppListArgsForCall, struct {
 		arg1 context.Context
 		arg2 client.Client
-	}{arg1, arg2})
+		arg3 repositories.AppListMessage
+	}{arg1, arg2, arg3})
 	stub := fake.FetchAppListStub
 	fakeReturns := fake.fetchAppListReturns
-	fake.recordInvocation("FetchAppList", []interface{}{arg1, arg2})
+	fake.recordInvocation("FetchAppList", []interface{}{arg1, arg2, arg3})
 	fake.fetchAppListMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
 func (fake *CFAppRepository) FetchAppListCallCount() int {
@@ -343,43 +564,46 @@ func (fake *CFAppRepository) FetchAppListCallCount() int {
 	return len(fake.fetchAppListArgsForCall)
 }
 
-func (fake *CFAppRepository) FetchAppListCalls(stub func(context.Context, client.Client) ([]repositories.AppRecord, error)) {
+func (fake *CFAppRepository) FetchAppListCalls(stub func(context.Context, client.Client, repositories.AppListMessage) ([]repositories.AppRecord, int, error)) {
 	fake.fetchAppListMutex.Lock()
 	defer fake.fetchAppListMutex.Unlock()
 	fake.FetchAppListStub = stub
 }
 
-func (fake *CFAppRepository) FetchAppListArgsForCall(i int) (context.Context, client.Client) {
+func (fake *CFAppRepository) FetchAppListArgsForCall(i int) (context.Context, client.Client, repositories.AppListMessage) {
 	fake.fetchAppListMutex.RLock()
 	defer fake.fetchAppListMutex.RUnlock()
 	argsForCall := fake.fetchAppListArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *CFAppRepository) FetchAppListReturns(result1 []repositories.AppRecord, result2 error) {
+func (fake *CFAppRepository) FetchAppListReturns(result1 []repositories.AppRecord, result2 int, result3 error) {
 	fake.fetchAppListMutex.Lock()
 	defer fake.fetchAppListMutex.Unlock()
 	fake.FetchAppListStub = nil
 	fake.fetchAppListReturns = struct {
 		result1 []repositories.AppRecord
-		result2 error
-	}{result1, result2}
+		result2 int
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *CFAppRepository) FetchAppListReturnsOnCall(i int, result1 []repositories.AppRecord, result2 error) {
+func (fake *CFAppRepository) FetchAppListReturnsOnCall(i int, result1 []repositories.AppRecord, result2 int, result3 error) {
 	fake.fetchAppListMutex.Lock()
 	defer fake.fetchAppListMutex.Unlock()
 	fake.FetchAppListStub = nil
 	if fake.fetchAppListReturnsOnCall == nil {
 		fake.fetchAppListReturnsOnCall = make(map[int]struct {
 			result1 []repositories.AppRecord
-			result2 error
+			result2 int
+			result3 error
 		})
 	}
 	fake.fetchAppListReturnsOnCall[i] = struct {
 		result1 []repositories.AppRecord
-		result2 error
-	}{result1, result2}
+		result2 int
+		result3 error
+	}{result1, result2, result3}
 }
 
 func (fake *CFAppRepository) FetchNamespace(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.SpaceRecord, error) {
@@ -448,18 +672,84 @@ func (fake *CFAppRepository) FetchNamespaceReturnsOnCall(i int, result1 reposito
 	}{result1, result2}
 }
 
-func (fake *CFAppRepository) SetAppDesiredState(arg1 context.Context, arg2 client.Client, arg3 repositories.SetAppDesiredStateMessage) (repositories.AppRecord, error) {
-	fake.setAppDesiredStateMutex.Lock()
-	ret, specificReturn := fake.setAppDesiredStateReturnsOnCall[len(fake.setAppDesiredStateArgsForCall)]
-	fake.setAppDesiredStateArgsForCall = append(fake.setAppDesiredStateArgsForCall, struct {
+func (fake *CFAppRepository) PatchApp(arg1 context.Context, arg2 client.Client, arg3 repositories.PatchAppMessage) (repositories.AppRecord, error) {
+	fake.patchAppMutex.Lock()
+	ret, specificReturn := fake.patchAppReturnsOnCall[len(fake.patchAppArgsForCall)]
+	fake.patchAppArgsForCall = append(fake.patchAppArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.PatchAppMessage
+	}{arg1, arg2, arg3})
+	stub := fake.PatchAppStub
+	fakeReturns := fake.patchAppReturns
+	fake.recordInvocation("PatchApp", []interface{}{arg1, arg2, arg3})
+	fake.patchAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFAppRepository) PatchAppCallCount() int {
+	fake.patchAppMutex.RLock()
+	defer fake.patchAppMutex.RUnlock()
+	return len(fake.patchAppArgsForCall)
+}
+
+func (fake *CFAppRepository) PatchAppCalls(stub func(context.Context, client.Client, repositories.PatchAppMessage) (repositories.AppRecord, error)) {
+	fake.patchAppMutex.Lock()
+	defer fake.patchAppMutex.Unlock()
+	fake.PatchAppStub = stub
+}
+
+func (fake *CFAppRepository) PatchAppArgsForCall(i int) (context.Context, client.Client, repositories.PatchAppMessage) {
+	fake.patchAppMutex.RLock()
+	defer fake.patchAppMutex.RUnlock()
+	argsForCall := fake.patchAppArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFAppRepository) PatchAppReturns(result1 repositories.AppRecord, result2 error) {
+	fake.patchAppMutex.Lock()
+	defer fake.patchAppMutex.Unlock()
+	fake.PatchAppStub = nil
+	fake.patchAppReturns = struct {
+		result1 repositories.AppRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) PatchAppReturnsOnCall(i int, result1 repositories.AppRecord, result2 error) {
+	fake.patchAppMutex.Lock()
+	defer fake.patchAppMutex.Unlock()
+	fake.PatchAppStub = nil
+	if fake.patchAppReturnsOnCall == nil {
+		fake.patchAppReturnsOnCall = make(map[int]struct {
+			result1 repositories.AppRecord
+			result2 error
+		})
+	}
+	fake.patchAppReturnsOnCall[i] = struct {
+		result1 repositories.AppRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) PatchAppEnvVars(arg1 context.Context, arg2 client.Client, arg3 repositories.PatchAppEnvVarsMessage) (repositories.AppEnvVarsRecord, error) {
+	fake.patchAppEnvVarsMutex.Lock()
+	ret, specificReturn := fake.patchAppEnvVarsReturnsOnCall[len(fake.patchAppEnvVarsArgsForCall)]
+	fake.patchAppEnvVarsArgsForCall = append(fake.patchAppEnvVarsArgsForCall, struct {
 		arg1 context.Context
 		arg2 client.Client
-		arg3 repositories.SetAppDesiredStateMessage
+		arg3 repositories.PatchAppEnvVarsMessage
 	}{arg1, arg2, arg3})
-	stub := fake.SetAppDesiredStateStub
-	fakeReturns := fake.setAppDesiredStateReturns
-	fake.recordInvocation("SetAppDesiredState", []interface{}{arg1, arg2, arg3})
-	fake.setAppDesiredStateMutex.Unlock()
+	stub := fake.PatchAppEnvVarsStub
+	fakeReturns := fake.patchAppEnvVarsReturns
+	fake.recordInvocation("PatchAppEnvVars", []interface{}{arg1, arg2, arg3})
+	fake.patchAppEnvVarsMutex.Unlock()
 	if stub != nil {
 		return stub(arg1, arg2, arg3)
 	}
@@ -469,46 +759,112 @@ func (fake *CFAppRepository) SetAppDesiredState(arg1 context.Context, arg2 clien
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *CFAppRepository) SetAppDesiredStateCallCount() int {
-	fake.setAppDesiredStateMutex.RLock()
-	defer fake.setAppDesiredStateMutex.RUnlock()
-	return len(fake.setAppDesiredStateArgsForCall)
+func (fake *CFAppRepository) PatchAppEnvVarsCallCount() int {
+	fake.patchAppEnvVarsMutex.RLock()
+	defer fake.patchAppEnvVarsMutex.RUnlock()
+	return len(fake.patchAppEnvVarsArgsForCall)
 }
 
-func (fake *CFAppRepository) SetAppDesiredStateCalls(stub func(context.Context, client.Client, repositories.SetAppDesiredStateMessage) (repositories.AppRecord, error)) {
-	fake.setAppDesiredStateMutex.Lock()
-	defer fake.setAppDesiredStateMutex.Unlock()
-	fake.SetAppDesiredStateStub = stub
+func (fake *CFAppRepository) PatchAppEnvVarsCalls(stub func(context.Context, client.Client, repositories.PatchAppEnvVarsMessage) (repositories.AppEnvVarsRecord, error)) {
+	fake.patchAppEnvVarsMutex.Lock()
+	defer fake.patchAppEnvVarsMutex.Unlock()
+	fake.PatchAppEnvVarsStub = stub
 }
 
-func (fake *CFAppRepository) SetAppDesiredStateArgsForCall(i int) (context.Context, client.Client, repositories.SetAppDesiredStateMessage) {
-	fake.setAppDesiredStateMutex.RLock()
-	defer fake.setAppDesiredStateMutex.RUnlock()
-	argsForCall := fake.setAppDesiredStateArgsForCall[i]
+func (fake *CFAppRepository) PatchAppEnvVarsArgsForCall(i int) (context.Context, client.Client, repositories.PatchAppEnvVarsMessage) {
+	fake.patchAppEnvVarsMutex.RLock()
+	defer fake.patchAppEnvVarsMutex.RUnlock()
+	argsForCall := fake.patchAppEnvVarsArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *CFAppRepository) SetAppDesiredStateReturns(result1 repositories.AppRecord, result2 error) {
-	fake.setAppDesiredStateMutex.Lock()
-	defer fake.setAppDesiredStateMutex.Unlock()
-	fake.SetAppDesiredStateStub = nil
-	fake.setAppDesiredStateReturns = struct {
+func (fake *CFAppRepository) PatchAppEnvVarsReturns(result1 repositories.AppEnvVarsRecord, result2 error) {
+	fake.patchAppEnvVarsMutex.Lock()
+	defer fake.patchAppEnvVarsMutex.Unlock()
+	fake.PatchAppEnvVarsStub = nil
+	fake.patchAppEnvVarsReturns = struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) PatchAppEnvVarsReturnsOnCall(i int, result1 repositories.AppEnvVarsRecord, result2 error) {
+	fake.patchAppEnvVarsMutex.Lock()
+	defer fake.patchAppEnvVarsMutex.Unlock()
+	fake.PatchAppEnvVarsStub = nil
+	if fake.patchAppEnvVarsReturnsOnCall == nil {
+		fake.patchAppEnvVarsReturnsOnCall = make(map[int]struct {
+			result1 repositories.AppEnvVarsRecord
+			result2 error
+		})
+	}
+	fake.patchAppEnvVarsReturnsOnCall[i] = struct {
+		result1 repositories.AppEnvVarsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) RestartApp(arg1 context.Context, arg2 client.Client, arg3 repositories.AppRecord) (repositories.AppRecord, error) {
+	fake.restartAppMutex.Lock()
+	ret, specificReturn := fake.restartAppReturnsOnCall[len(fake.restartAppArgsForCall)]
+	fake.restartAppArgsForCall = append(fake.restartAppArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}{arg1, arg2, arg3})
+	stub := fake.RestartAppStub
+	fakeReturns := fake.restartAppReturns
+	fake.recordInvocation("RestartApp", []interface{}{arg1, arg2, arg3})
+	fake.restartAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFAppRepository) RestartAppCallCount() int {
+	fake.restartAppMutex.RLock()
+	defer fake.restartAppMutex.RUnlock()
+	return len(fake.restartAppArgsForCall)
+}
+
+func (fake *CFAppRepository) RestartAppCalls(stub func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)) {
+	fake.restartAppMutex.Lock()
+	defer fake.restartAppMutex.Unlock()
+	fake.RestartAppStub = stub
+}
+
+func (fake *CFAppRepository) RestartAppArgsForCall(i int) (context.Context, client.Client, repositories.AppRecord) {
+	fake.restartAppMutex.RLock()
+	defer fake.restartAppMutex.RUnlock()
+	argsForCall := fake.restartAppArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFAppRepository) RestartAppReturns(result1 repositories.AppRecord, result2 error) {
+	fake.restartAppMutex.Lock()
+	defer fake.restartAppMutex.Unlock()
+	fake.RestartAppStub = nil
+	fake.restartAppReturns = struct {
 		result1 repositories.AppRecord
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *CFAppRepository) SetAppDesiredStateReturnsOnCall(i int, result1 repositories.AppRecord, result2 error) {
-	fake.setAppDesiredStateMutex.Lock()
-	defer fake.setAppDesiredStateMutex.Unlock()
-	fake.SetAppDesiredStateStub = nil
-	if fake.setAppDesiredStateReturnsOnCall == nil {
-		fake.setAppDesiredStateReturnsOnCall = make(map[int]struct {
+func (fake *CFAppRepository) RestartAppReturnsOnCall(i int, result1 repositories.AppRecord, result2 error) {
+	fake.restartAppMutex.Lock()
+	defer fake.restartAppMutex.Unlock()
+	fake.RestartAppStub = nil
+	if fake.restartAppReturnsOnCall == nil {
+		fake.restartAppReturnsOnCall = make(map[int]struct {
 			result1 repositories.AppRecord
 			result2 error
 		})
 	}
-	fake.setAppDesiredStateReturnsOnCall[i] = struct {
+	fake.restartAppReturnsOnCall[i] = struct {
 		result1 repositories.AppRecord
 		result2 error
 	}{result1, result2}
@@ -580,6 +936,138 @@ func (fake *CFAppRepository) SetCurrentDropletReturnsOnCall(i int, result1 repos
 	}{result1, result2}
 }
 
+func (fake *CFAppRepository) StartApp(arg1 context.Context, arg2 client.Client, arg3 repositories.AppRecord) (repositories.AppRecord, error) {
+	fake.startAppMutex.Lock()
+	ret, specificReturn := fake.startAppReturnsOnCall[len(fake.startAppArgsForCall)]
+	fake.startAppArgsForCall = append(fake.startAppArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}{arg1, arg2, arg3})
+	stub := fake.StartAppStub
+	fakeReturns := fake.startAppReturns
+	fake.recordInvocation("StartApp", []interface{}{arg1, arg2, arg3})
+	fake.startAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFAppRepository) StartAppCallCount() int {
+	fake.startAppMutex.RLock()
+	defer fake.startAppMutex.RUnlock()
+	return len(fake.startAppArgsForCall)
+}
+
+func (fake *CFAppRepository) StartAppCalls(stub func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)) {
+	fake.startAppMutex.Lock()
+	defer fake.startAppMutex.Unlock()
+	fake.StartAppStub = stub
+}
+
+func (fake *CFAppRepository) StartAppArgsForCall(i int) (context.Context, client.Client, repositories.AppRecord) {
+	fake.startAppMutex.RLock()
+	defer fake.startAppMutex.RUnlock()
+	argsForCall := fake.startAppArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFAppRepository) StartAppReturns(result1 repositories.AppRecord, result2 error) {
+	fake.startAppMutex.Lock()
+	defer fake.startAppMutex.Unlock()
+	fake.StartAppStub = nil
+	fake.startAppReturns = struct {
+		result1 repositories.AppRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) StartAppReturnsOnCall(i int, result1 repositories.AppRecord, result2 error) {
+	fake.startAppMutex.Lock()
+	defer fake.startAppMutex.Unlock()
+	fake.StartAppStub = nil
+	if fake.startAppReturnsOnCall == nil {
+		fake.startAppReturnsOnCall = make(map[int]struct {
+			result1 repositories.AppRecord
+			result2 error
+		})
+	}
+	fake.startAppReturnsOnCall[i] = struct {
+		result1 repositories.AppRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) StopApp(arg1 context.Context, arg2 client.Client, arg3 repositories.AppRecord) (repositories.AppRecord, error) {
+	fake.stopAppMutex.Lock()
+	ret, specificReturn := fake.stopAppReturnsOnCall[len(fake.stopAppArgsForCall)]
+	fake.stopAppArgsForCall = append(fake.stopAppArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}{arg1, arg2, arg3})
+	stub := fake.StopAppStub
+	fakeReturns := fake.stopAppReturns
+	fake.recordInvocation("StopApp", []interface{}{arg1, arg2, arg3})
+	fake.stopAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFAppRepository) StopAppCallCount() int {
+	fake.stopAppMutex.RLock()
+	defer fake.stopAppMutex.RUnlock()
+	return len(fake.stopAppArgsForCall)
+}
+
+func (fake *CFAppRepository) StopAppCalls(stub func(context.Context, client.Client, repositories.AppRecord) (repositories.AppRecord, error)) {
+	fake.stopAppMutex.Lock()
+	defer fake.stopAppMutex.Unlock()
+	fake.StopAppStub = stub
+}
+
+func (fake *CFAppRepository) StopAppArgsForCall(i int) (context.Context, client.Client, repositories.AppRecord) {
+	fake.stopAppMutex.RLock()
+	defer fake.stopAppMutex.RUnlock()
+	argsForCall := fake.stopAppArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFAppRepository) StopAppReturns(result1 repositories.AppRecord, result2 error) {
+	fake.stopAppMutex.Lock()
+	defer fake.stopAppMutex.Unlock()
+	fake.StopAppStub = nil
+	fake.stopAppReturns = struct {
+		result1 repositories.AppRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFAppRepository) StopAppReturnsOnCall(i int, result1 repositories.AppRecord, result2 error) {
+	fake.stopAppMutex.Lock()
+	defer fake.stopAppMutex.Unlock()
+	fake.StopAppStub = nil
+	if fake.stopAppReturnsOnCall == nil {
+		fake.stopAppReturnsOnCall = make(map[int]struct {
+			result1 repositories.AppRecord
+			result2 error
+		})
+	}
+	fake.stopAppReturnsOnCall[i] = struct {
+		result1 repositories.AppRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFAppRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -587,16 +1075,28 @@ func (fake *CFAppRepository) Invocations() map[string][][]interface{} {
 	defer fake.createAppMutex.RUnlock()
 	fake.createAppEnvironmentVariablesMutex.RLock()
 	defer fake.createAppEnvironmentVariablesMutex.RUnlock()
+	fake.deleteAppMutex.RLock()
+	defer fake.deleteAppMutex.RUnlock()
 	fake.fetchAppMutex.RLock()
 	defer fake.fetchAppMutex.RUnlock()
+	fake.fetchAppEnvVarsMutex.RLock()
+	defer fake.fetchAppEnvVarsMutex.RUnlock()
 	fake.fetchAppListMutex.RLock()
 	defer fake.fetchAppListMutex.RUnlock()
 	fake.fetchNamespaceMutex.RLock()
 	defer fake.fetchNamespaceMutex.RUnlock()
-	fake.setAppDesiredStateMutex.RLock()
-	defer fake.setAppDesiredStateMutex.RUnlock()
+	fake.patchAppMutex.RLock()
+	defer fake.patchAppMutex.RUnlock()
+	fake.patchAppEnvVarsMutex.RLock()
+	defer fake.patchAppEnvVarsMutex.RUnlock()
+	fake.restartAppMutex.RLock()
+	defer fake.restartAppMutex.RUnlock()
 	fake.setCurrentDropletMutex.RLock()
 	defer fake.setCurrentDropletMutex.RUnlock()
+	fake.startAppMutex.RLock()
+	defer fake.startAppMutex.RUnlock()
+	fake.stopAppMutex.RLock()
+	defer fake.stopAppMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
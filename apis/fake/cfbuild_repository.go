@@ -41,6 +41,21 @@ type CFBuildRepository struct {
 		result1 repositories.BuildRecord
 		result2 error
 	}
+	PatchBuildMetadataStub        func(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.BuildRecord, error)
+	patchBuildMetadataMutex       sync.RWMutex
+	patchBuildMetadataArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.MetadataPatchMessage
+	}
+	patchBuildMetadataReturns struct {
+		result1 repositories.BuildRecord
+		result2 error
+	}
+	patchBuildMetadataReturnsOnCall map[int]struct {
+		result1 repositories.BuildRecord
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -177,6 +192,72 @@ func (fake *CFBuildRepository) FetchBuildReturnsOnCall(i int, result1 repositori
 	}{result1, result2}
 }
 
+func (fake *CFBuildRepository) PatchBuildMetadata(arg1 context.Context, arg2 client.Client, arg3 repositories.MetadataPatchMessage) (repositories.BuildRecord, error) {
+	fake.patchBuildMetadataMutex.Lock()
+	ret, specificReturn := fake.patchBuildMetadataReturnsOnCall[len(fake.patchBuildMetadataArgsForCall)]
+	fake.patchBuildMetadataArgsForCall = append(fake.patchBuildMetadataArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.MetadataPatchMessage
+	}{arg1, arg2, arg3})
+	stub := fake.PatchBuildMetadataStub
+	fakeReturns := fake.patchBuildMetadataReturns
+	fake.recordInvocation("PatchBuildMetadata", []interface{}{arg1, arg2, arg3})
+	fake.patchBuildMetadataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFBuildRepository) PatchBuildMetadataCallCount() int {
+	fake.patchBuildMetadataMutex.RLock()
+	defer fake.patchBuildMetadataMutex.RUnlock()
+	return len(fake.patchBuildMetadataArgsForCall)
+}
+
+func (fake *CFBuildRepository) PatchBuildMetadataCalls(stub func(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.BuildRecord, error)) {
+	fake.patchBuildMetadataMutex.Lock()
+	defer fake.patchBuildMetadataMutex.Unlock()
+	fake.PatchBuildMetadataStub = stub
+}
+
+func (fake *CFBuildRepository) PatchBuildMetadataArgsForCall(i int) (context.Context, client.Client, repositories.MetadataPatchMessage) {
+	fake.patchBuildMetadataMutex.RLock()
+	defer fake.patchBuildMetadataMutex.RUnlock()
+	argsForCall := fake.patchBuildMetadataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFBuildRepository) PatchBuildMetadataReturns(result1 repositories.BuildRecord, result2 error) {
+	fake.patchBuildMetadataMutex.Lock()
+	defer fake.patchBuildMetadataMutex.Unlock()
+	fake.PatchBuildMetadataStub = nil
+	fake.patchBuildMetadataReturns = struct {
+		result1 repositories.BuildRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFBuildRepository) PatchBuildMetadataReturnsOnCall(i int, result1 repositories.BuildRecord, result2 error) {
+	fake.patchBuildMetadataMutex.Lock()
+	defer fake.patchBuildMetadataMutex.Unlock()
+	fake.PatchBuildMetadataStub = nil
+	if fake.patchBuildMetadataReturnsOnCall == nil {
+		fake.patchBuildMetadataReturnsOnCall = make(map[int]struct {
+			result1 repositories.BuildRecord
+			result2 error
+		})
+	}
+	fake.patchBuildMetadataReturnsOnCall[i] = struct {
+		result1 repositories.BuildRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFBuildRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -184,6 +265,8 @@ func (fake *CFBuildRepository) Invocations() map[string][][]interface{} {
 	defer fake.createBuildMutex.RUnlock()
 	fake.fetchBuildMutex.RLock()
 	defer fake.fetchBuildMutex.RUnlock()
+	fake.patchBuildMetadataMutex.RLock()
+	defer fake.patchBuildMetadataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,455 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type CFDeploymentRepository struct {
+	CancelDeploymentStub        func(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error)
+	cancelDeploymentMutex       sync.RWMutex
+	cancelDeploymentArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentRecord
+	}
+	cancelDeploymentReturns struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	cancelDeploymentReturnsOnCall map[int]struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	ContinueDeploymentStub        func(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error)
+	continueDeploymentMutex       sync.RWMutex
+	continueDeploymentArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentRecord
+	}
+	continueDeploymentReturns struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	continueDeploymentReturnsOnCall map[int]struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	CreateDeploymentStub        func(context.Context, client.Client, repositories.DeploymentCreateMessage) (repositories.DeploymentRecord, error)
+	createDeploymentMutex       sync.RWMutex
+	createDeploymentArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentCreateMessage
+	}
+	createDeploymentReturns struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	createDeploymentReturnsOnCall map[int]struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	FetchDeploymentStub        func(context.Context, client.Client, string) (repositories.DeploymentRecord, error)
+	fetchDeploymentMutex       sync.RWMutex
+	fetchDeploymentArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}
+	fetchDeploymentReturns struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	fetchDeploymentReturnsOnCall map[int]struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}
+	FetchDeploymentListStub        func(context.Context, client.Client, repositories.DeploymentListMessage) ([]repositories.DeploymentRecord, error)
+	fetchDeploymentListMutex       sync.RWMutex
+	fetchDeploymentListArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentListMessage
+	}
+	fetchDeploymentListReturns struct {
+		result1 []repositories.DeploymentRecord
+		result2 error
+	}
+	fetchDeploymentListReturnsOnCall map[int]struct {
+		result1 []repositories.DeploymentRecord
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CFDeploymentRepository) CancelDeployment(arg1 context.Context, arg2 client.Client, arg3 repositories.DeploymentRecord) (repositories.DeploymentRecord, error) {
+	fake.cancelDeploymentMutex.Lock()
+	ret, specificReturn := fake.cancelDeploymentReturnsOnCall[len(fake.cancelDeploymentArgsForCall)]
+	fake.cancelDeploymentArgsForCall = append(fake.cancelDeploymentArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentRecord
+	}{arg1, arg2, arg3})
+	stub := fake.CancelDeploymentStub
+	fakeReturns := fake.cancelDeploymentReturns
+	fake.recordInvocation("CancelDeployment", []interface{}{arg1, arg2, arg3})
+	fake.cancelDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFDeploymentRepository) CancelDeploymentCallCount() int {
+	fake.cancelDeploymentMutex.RLock()
+	defer fake.cancelDeploymentMutex.RUnlock()
+	return len(fake.cancelDeploymentArgsForCall)
+}
+
+func (fake *CFDeploymentRepository) CancelDeploymentCalls(stub func(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error)) {
+	fake.cancelDeploymentMutex.Lock()
+	defer fake.cancelDeploymentMutex.Unlock()
+	fake.CancelDeploymentStub = stub
+}
+
+func (fake *CFDeploymentRepository) CancelDeploymentArgsForCall(i int) (context.Context, client.Client, repositories.DeploymentRecord) {
+	fake.cancelDeploymentMutex.RLock()
+	defer fake.cancelDeploymentMutex.RUnlock()
+	argsForCall := fake.cancelDeploymentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFDeploymentRepository) CancelDeploymentReturns(result1 repositories.DeploymentRecord, result2 error) {
+	fake.cancelDeploymentMutex.Lock()
+	defer fake.cancelDeploymentMutex.Unlock()
+	fake.CancelDeploymentStub = nil
+	fake.cancelDeploymentReturns = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) CancelDeploymentReturnsOnCall(i int, result1 repositories.DeploymentRecord, result2 error) {
+	fake.cancelDeploymentMutex.Lock()
+	defer fake.cancelDeploymentMutex.Unlock()
+	fake.CancelDeploymentStub = nil
+	if fake.cancelDeploymentReturnsOnCall == nil {
+		fake.cancelDeploymentReturnsOnCall = make(map[int]struct {
+			result1 repositories.DeploymentRecord
+			result2 error
+		})
+	}
+	fake.cancelDeploymentReturnsOnCall[i] = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) ContinueDeployment(arg1 context.Context, arg2 client.Client, arg3 repositories.DeploymentRecord) (repositories.DeploymentRecord, error) {
+	fake.continueDeploymentMutex.Lock()
+	ret, specificReturn := fake.continueDeploymentReturnsOnCall[len(fake.continueDeploymentArgsForCall)]
+	fake.continueDeploymentArgsForCall = append(fake.continueDeploymentArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentRecord
+	}{arg1, arg2, arg3})
+	stub := fake.ContinueDeploymentStub
+	fakeReturns := fake.continueDeploymentReturns
+	fake.recordInvocation("ContinueDeployment", []interface{}{arg1, arg2, arg3})
+	fake.continueDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFDeploymentRepository) ContinueDeploymentCallCount() int {
+	fake.continueDeploymentMutex.RLock()
+	defer fake.continueDeploymentMutex.RUnlock()
+	return len(fake.continueDeploymentArgsForCall)
+}
+
+func (fake *CFDeploymentRepository) ContinueDeploymentCalls(stub func(context.Context, client.Client, repositories.DeploymentRecord) (repositories.DeploymentRecord, error)) {
+	fake.continueDeploymentMutex.Lock()
+	defer fake.continueDeploymentMutex.Unlock()
+	fake.ContinueDeploymentStub = stub
+}
+
+func (fake *CFDeploymentRepository) ContinueDeploymentArgsForCall(i int) (context.Context, client.Client, repositories.DeploymentRecord) {
+	fake.continueDeploymentMutex.RLock()
+	defer fake.continueDeploymentMutex.RUnlock()
+	argsForCall := fake.continueDeploymentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFDeploymentRepository) ContinueDeploymentReturns(result1 repositories.DeploymentRecord, result2 error) {
+	fake.continueDeploymentMutex.Lock()
+	defer fake.continueDeploymentMutex.Unlock()
+	fake.ContinueDeploymentStub = nil
+	fake.continueDeploymentReturns = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) ContinueDeploymentReturnsOnCall(i int, result1 repositories.DeploymentRecord, result2 error) {
+	fake.continueDeploymentMutex.Lock()
+	defer fake.continueDeploymentMutex.Unlock()
+	fake.ContinueDeploymentStub = nil
+	if fake.continueDeploymentReturnsOnCall == nil {
+		fake.continueDeploymentReturnsOnCall = make(map[int]struct {
+			result1 repositories.DeploymentRecord
+			result2 error
+		})
+	}
+	fake.continueDeploymentReturnsOnCall[i] = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) CreateDeployment(arg1 context.Context, arg2 client.Client, arg3 repositories.DeploymentCreateMessage) (repositories.DeploymentRecord, error) {
+	fake.createDeploymentMutex.Lock()
+	ret, specificReturn := fake.createDeploymentReturnsOnCall[len(fake.createDeploymentArgsForCall)]
+	fake.createDeploymentArgsForCall = append(fake.createDeploymentArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentCreateMessage
+	}{arg1, arg2, arg3})
+	stub := fake.CreateDeploymentStub
+	fakeReturns := fake.createDeploymentReturns
+	fake.recordInvocation("CreateDeployment", []interface{}{arg1, arg2, arg3})
+	fake.createDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFDeploymentRepository) CreateDeploymentCallCount() int {
+	fake.createDeploymentMutex.RLock()
+	defer fake.createDeploymentMutex.RUnlock()
+	return len(fake.createDeploymentArgsForCall)
+}
+
+func (fake *CFDeploymentRepository) CreateDeploymentCalls(stub func(context.Context, client.Client, repositories.DeploymentCreateMessage) (repositories.DeploymentRecord, error)) {
+	fake.createDeploymentMutex.Lock()
+	defer fake.createDeploymentMutex.Unlock()
+	fake.CreateDeploymentStub = stub
+}
+
+func (fake *CFDeploymentRepository) CreateDeploymentArgsForCall(i int) (context.Context, client.Client, repositories.DeploymentCreateMessage) {
+	fake.createDeploymentMutex.RLock()
+	defer fake.createDeploymentMutex.RUnlock()
+	argsForCall := fake.createDeploymentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFDeploymentRepository) CreateDeploymentReturns(result1 repositories.DeploymentRecord, result2 error) {
+	fake.createDeploymentMutex.Lock()
+	defer fake.createDeploymentMutex.Unlock()
+	fake.CreateDeploymentStub = nil
+	fake.createDeploymentReturns = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) CreateDeploymentReturnsOnCall(i int, result1 repositories.DeploymentRecord, result2 error) {
+	fake.createDeploymentMutex.Lock()
+	defer fake.createDeploymentMutex.Unlock()
+	fake.CreateDeploymentStub = nil
+	if fake.createDeploymentReturnsOnCall == nil {
+		fake.createDeploymentReturnsOnCall = make(map[int]struct {
+			result1 repositories.DeploymentRecord
+			result2 error
+		})
+	}
+	fake.createDeploymentReturnsOnCall[i] = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) FetchDeployment(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.DeploymentRecord, error) {
+	fake.fetchDeploymentMutex.Lock()
+	ret, specificReturn := fake.fetchDeploymentReturnsOnCall[len(fake.fetchDeploymentArgsForCall)]
+	fake.fetchDeploymentArgsForCall = append(fake.fetchDeploymentArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FetchDeploymentStub
+	fakeReturns := fake.fetchDeploymentReturns
+	fake.recordInvocation("FetchDeployment", []interface{}{arg1, arg2, arg3})
+	fake.fetchDeploymentMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentCallCount() int {
+	fake.fetchDeploymentMutex.RLock()
+	defer fake.fetchDeploymentMutex.RUnlock()
+	return len(fake.fetchDeploymentArgsForCall)
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentCalls(stub func(context.Context, client.Client, string) (repositories.DeploymentRecord, error)) {
+	fake.fetchDeploymentMutex.Lock()
+	defer fake.fetchDeploymentMutex.Unlock()
+	fake.FetchDeploymentStub = stub
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentArgsForCall(i int) (context.Context, client.Client, string) {
+	fake.fetchDeploymentMutex.RLock()
+	defer fake.fetchDeploymentMutex.RUnlock()
+	argsForCall := fake.fetchDeploymentArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentReturns(result1 repositories.DeploymentRecord, result2 error) {
+	fake.fetchDeploymentMutex.Lock()
+	defer fake.fetchDeploymentMutex.Unlock()
+	fake.FetchDeploymentStub = nil
+	fake.fetchDeploymentReturns = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentReturnsOnCall(i int, result1 repositories.DeploymentRecord, result2 error) {
+	fake.fetchDeploymentMutex.Lock()
+	defer fake.fetchDeploymentMutex.Unlock()
+	fake.FetchDeploymentStub = nil
+	if fake.fetchDeploymentReturnsOnCall == nil {
+		fake.fetchDeploymentReturnsOnCall = make(map[int]struct {
+			result1 repositories.DeploymentRecord
+			result2 error
+		})
+	}
+	fake.fetchDeploymentReturnsOnCall[i] = struct {
+		result1 repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentList(arg1 context.Context, arg2 client.Client, arg3 repositories.DeploymentListMessage) ([]repositories.DeploymentRecord, error) {
+	fake.fetchDeploymentListMutex.Lock()
+	ret, specificReturn := fake.fetchDeploymentListReturnsOnCall[len(fake.fetchDeploymentListArgsForCall)]
+	fake.fetchDeploymentListArgsForCall = append(fake.fetchDeploymentListArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.DeploymentListMessage
+	}{arg1, arg2, arg3})
+	stub := fake.FetchDeploymentListStub
+	fakeReturns := fake.fetchDeploymentListReturns
+	fake.recordInvocation("FetchDeploymentList", []interface{}{arg1, arg2, arg3})
+	fake.fetchDeploymentListMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentListCallCount() int {
+	fake.fetchDeploymentListMutex.RLock()
+	defer fake.fetchDeploymentListMutex.RUnlock()
+	return len(fake.fetchDeploymentListArgsForCall)
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentListCalls(stub func(context.Context, client.Client, repositories.DeploymentListMessage) ([]repositories.DeploymentRecord, error)) {
+	fake.fetchDeploymentListMutex.Lock()
+	defer fake.fetchDeploymentListMutex.Unlock()
+	fake.FetchDeploymentListStub = stub
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentListArgsForCall(i int) (context.Context, client.Client, repositories.DeploymentListMessage) {
+	fake.fetchDeploymentListMutex.RLock()
+	defer fake.fetchDeploymentListMutex.RUnlock()
+	argsForCall := fake.fetchDeploymentListArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentListReturns(result1 []repositories.DeploymentRecord, result2 error) {
+	fake.fetchDeploymentListMutex.Lock()
+	defer fake.fetchDeploymentListMutex.Unlock()
+	fake.FetchDeploymentListStub = nil
+	fake.fetchDeploymentListReturns = struct {
+		result1 []repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) FetchDeploymentListReturnsOnCall(i int, result1 []repositories.DeploymentRecord, result2 error) {
+	fake.fetchDeploymentListMutex.Lock()
+	defer fake.fetchDeploymentListMutex.Unlock()
+	fake.FetchDeploymentListStub = nil
+	if fake.fetchDeploymentListReturnsOnCall == nil {
+		fake.fetchDeploymentListReturnsOnCall = make(map[int]struct {
+			result1 []repositories.DeploymentRecord
+			result2 error
+		})
+	}
+	fake.fetchDeploymentListReturnsOnCall[i] = struct {
+		result1 []repositories.DeploymentRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDeploymentRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cancelDeploymentMutex.RLock()
+	defer fake.cancelDeploymentMutex.RUnlock()
+	fake.continueDeploymentMutex.RLock()
+	defer fake.continueDeploymentMutex.RUnlock()
+	fake.createDeploymentMutex.RLock()
+	defer fake.createDeploymentMutex.RUnlock()
+	fake.fetchDeploymentMutex.RLock()
+	defer fake.fetchDeploymentMutex.RUnlock()
+	fake.fetchDeploymentListMutex.RLock()
+	defer fake.fetchDeploymentListMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CFDeploymentRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.CFDeploymentRepository = new(CFDeploymentRepository)
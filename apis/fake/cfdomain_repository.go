@@ -26,6 +26,21 @@ type CFDomainRepository struct {
 		result1 repositories.DomainRecord
 		result2 error
 	}
+	FetchDomainByNameStub        func(context.Context, client.Client, string) (repositories.DomainRecord, error)
+	fetchDomainByNameMutex       sync.RWMutex
+	fetchDomainByNameArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}
+	fetchDomainByNameReturns struct {
+		result1 repositories.DomainRecord
+		result2 error
+	}
+	fetchDomainByNameReturnsOnCall map[int]struct {
+		result1 repositories.DomainRecord
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -96,11 +111,79 @@ func (fake *CFDomainRepository) FetchDomainReturnsOnCall(i int, result1 reposito
 	}{result1, result2}
 }
 
+func (fake *CFDomainRepository) FetchDomainByName(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.DomainRecord, error) {
+	fake.fetchDomainByNameMutex.Lock()
+	ret, specificReturn := fake.fetchDomainByNameReturnsOnCall[len(fake.fetchDomainByNameArgsForCall)]
+	fake.fetchDomainByNameArgsForCall = append(fake.fetchDomainByNameArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FetchDomainByNameStub
+	fakeReturns := fake.fetchDomainByNameReturns
+	fake.recordInvocation("FetchDomainByName", []interface{}{arg1, arg2, arg3})
+	fake.fetchDomainByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFDomainRepository) FetchDomainByNameCallCount() int {
+	fake.fetchDomainByNameMutex.RLock()
+	defer fake.fetchDomainByNameMutex.RUnlock()
+	return len(fake.fetchDomainByNameArgsForCall)
+}
+
+func (fake *CFDomainRepository) FetchDomainByNameCalls(stub func(context.Context, client.Client, string) (repositories.DomainRecord, error)) {
+	fake.fetchDomainByNameMutex.Lock()
+	defer fake.fetchDomainByNameMutex.Unlock()
+	fake.FetchDomainByNameStub = stub
+}
+
+func (fake *CFDomainRepository) FetchDomainByNameArgsForCall(i int) (context.Context, client.Client, string) {
+	fake.fetchDomainByNameMutex.RLock()
+	defer fake.fetchDomainByNameMutex.RUnlock()
+	argsForCall := fake.fetchDomainByNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFDomainRepository) FetchDomainByNameReturns(result1 repositories.DomainRecord, result2 error) {
+	fake.fetchDomainByNameMutex.Lock()
+	defer fake.fetchDomainByNameMutex.Unlock()
+	fake.FetchDomainByNameStub = nil
+	fake.fetchDomainByNameReturns = struct {
+		result1 repositories.DomainRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFDomainRepository) FetchDomainByNameReturnsOnCall(i int, result1 repositories.DomainRecord, result2 error) {
+	fake.fetchDomainByNameMutex.Lock()
+	defer fake.fetchDomainByNameMutex.Unlock()
+	fake.FetchDomainByNameStub = nil
+	if fake.fetchDomainByNameReturnsOnCall == nil {
+		fake.fetchDomainByNameReturnsOnCall = make(map[int]struct {
+			result1 repositories.DomainRecord
+			result2 error
+		})
+	}
+	fake.fetchDomainByNameReturnsOnCall[i] = struct {
+		result1 repositories.DomainRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFDomainRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.fetchDomainMutex.RLock()
 	defer fake.fetchDomainMutex.RUnlock()
+	fake.fetchDomainByNameMutex.RLock()
+	defer fake.fetchDomainByNameMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
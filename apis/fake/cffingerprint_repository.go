@@ -0,0 +1,206 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"io"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+type CFFingerprintRepository struct {
+	MatchResourcesStub        func([]repositories.ResourceRecord) ([]repositories.ResourceRecord, error)
+	matchResourcesMutex       sync.RWMutex
+	matchResourcesArgsForCall []struct {
+		arg1 []repositories.ResourceRecord
+	}
+	matchResourcesReturns struct {
+		result1 []repositories.ResourceRecord
+		result2 error
+	}
+	matchResourcesReturnsOnCall map[int]struct {
+		result1 []repositories.ResourceRecord
+		result2 error
+	}
+	ReadPackageAsTarStub        func(io.Reader, []repositories.ResourceRecord, string) io.ReadCloser
+	readPackageAsTarMutex       sync.RWMutex
+	readPackageAsTarArgsForCall []struct {
+		arg1 io.Reader
+		arg2 []repositories.ResourceRecord
+		arg3 string
+	}
+	readPackageAsTarReturns struct {
+		result1 io.ReadCloser
+	}
+	readPackageAsTarReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CFFingerprintRepository) MatchResources(arg1 []repositories.ResourceRecord) ([]repositories.ResourceRecord, error) {
+	var arg1Copy []repositories.ResourceRecord
+	if arg1 != nil {
+		arg1Copy = make([]repositories.ResourceRecord, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.matchResourcesMutex.Lock()
+	ret, specificReturn := fake.matchResourcesReturnsOnCall[len(fake.matchResourcesArgsForCall)]
+	fake.matchResourcesArgsForCall = append(fake.matchResourcesArgsForCall, struct {
+		arg1 []repositories.ResourceRecord
+	}{arg1Copy})
+	stub := fake.MatchResourcesStub
+	fakeReturns := fake.matchResourcesReturns
+	fake.recordInvocation("MatchResources", []interface{}{arg1Copy})
+	fake.matchResourcesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFFingerprintRepository) MatchResourcesCallCount() int {
+	fake.matchResourcesMutex.RLock()
+	defer fake.matchResourcesMutex.RUnlock()
+	return len(fake.matchResourcesArgsForCall)
+}
+
+func (fake *CFFingerprintRepository) MatchResourcesCalls(stub func([]repositories.ResourceRecord) ([]repositories.ResourceRecord, error)) {
+	fake.matchResourcesMutex.Lock()
+	defer fake.matchResourcesMutex.Unlock()
+	fake.MatchResourcesStub = stub
+}
+
+func (fake *CFFingerprintRepository) MatchResourcesArgsForCall(i int) []repositories.ResourceRecord {
+	fake.matchResourcesMutex.RLock()
+	defer fake.matchResourcesMutex.RUnlock()
+	argsForCall := fake.matchResourcesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *CFFingerprintRepository) MatchResourcesReturns(result1 []repositories.ResourceRecord, result2 error) {
+	fake.matchResourcesMutex.Lock()
+	defer fake.matchResourcesMutex.Unlock()
+	fake.MatchResourcesStub = nil
+	fake.matchResourcesReturns = struct {
+		result1 []repositories.ResourceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFFingerprintRepository) MatchResourcesReturnsOnCall(i int, result1 []repositories.ResourceRecord, result2 error) {
+	fake.matchResourcesMutex.Lock()
+	defer fake.matchResourcesMutex.Unlock()
+	fake.MatchResourcesStub = nil
+	if fake.matchResourcesReturnsOnCall == nil {
+		fake.matchResourcesReturnsOnCall = make(map[int]struct {
+			result1 []repositories.ResourceRecord
+			result2 error
+		})
+	}
+	fake.matchResourcesReturnsOnCall[i] = struct {
+		result1 []repositories.ResourceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFFingerprintRepository) ReadPackageAsTar(arg1 io.Reader, arg2 []repositories.ResourceRecord, arg3 string) io.ReadCloser {
+	var arg2Copy []repositories.ResourceRecord
+	if arg2 != nil {
+		arg2Copy = make([]repositories.ResourceRecord, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.readPackageAsTarMutex.Lock()
+	ret, specificReturn := fake.readPackageAsTarReturnsOnCall[len(fake.readPackageAsTarArgsForCall)]
+	fake.readPackageAsTarArgsForCall = append(fake.readPackageAsTarArgsForCall, struct {
+		arg1 io.Reader
+		arg2 []repositories.ResourceRecord
+		arg3 string
+	}{arg1, arg2Copy, arg3})
+	stub := fake.ReadPackageAsTarStub
+	fakeReturns := fake.readPackageAsTarReturns
+	fake.recordInvocation("ReadPackageAsTar", []interface{}{arg1, arg2Copy, arg3})
+	fake.readPackageAsTarMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *CFFingerprintRepository) ReadPackageAsTarCallCount() int {
+	fake.readPackageAsTarMutex.RLock()
+	defer fake.readPackageAsTarMutex.RUnlock()
+	return len(fake.readPackageAsTarArgsForCall)
+}
+
+func (fake *CFFingerprintRepository) ReadPackageAsTarCalls(stub func(io.Reader, []repositories.ResourceRecord, string) io.ReadCloser) {
+	fake.readPackageAsTarMutex.Lock()
+	defer fake.readPackageAsTarMutex.Unlock()
+	fake.ReadPackageAsTarStub = stub
+}
+
+func (fake *CFFingerprintRepository) ReadPackageAsTarArgsForCall(i int) (io.Reader, []repositories.ResourceRecord, string) {
+	fake.readPackageAsTarMutex.RLock()
+	defer fake.readPackageAsTarMutex.RUnlock()
+	argsForCall := fake.readPackageAsTarArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFFingerprintRepository) ReadPackageAsTarReturns(result1 io.ReadCloser) {
+	fake.readPackageAsTarMutex.Lock()
+	defer fake.readPackageAsTarMutex.Unlock()
+	fake.ReadPackageAsTarStub = nil
+	fake.readPackageAsTarReturns = struct {
+		result1 io.ReadCloser
+	}{result1}
+}
+
+func (fake *CFFingerprintRepository) ReadPackageAsTarReturnsOnCall(i int, result1 io.ReadCloser) {
+	fake.readPackageAsTarMutex.Lock()
+	defer fake.readPackageAsTarMutex.Unlock()
+	fake.ReadPackageAsTarStub = nil
+	if fake.readPackageAsTarReturnsOnCall == nil {
+		fake.readPackageAsTarReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
+		})
+	}
+	fake.readPackageAsTarReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
+	}{result1}
+}
+
+func (fake *CFFingerprintRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.matchResourcesMutex.RLock()
+	defer fake.matchResourcesMutex.RUnlock()
+	fake.readPackageAsTarMutex.RLock()
+	defer fake.readPackageAsTarMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CFFingerprintRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.CFFingerprintRepository = new(CFFingerprintRepository)
@@ -0,0 +1,208 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type CFJobRepository struct {
+	FetchJobStub        func(context.Context, client.Client, string) (repositories.JobRecord, error)
+	fetchJobMutex       sync.RWMutex
+	fetchJobArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}
+	fetchJobReturns struct {
+		result1 repositories.JobRecord
+		result2 error
+	}
+	fetchJobReturnsOnCall map[int]struct {
+		result1 repositories.JobRecord
+		result2 error
+	}
+	RunJobStub        func(context.Context, string, string, repositories.JobTask) (repositories.JobRecord, error)
+	runJobMutex       sync.RWMutex
+	runJobArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 repositories.JobTask
+	}
+	runJobReturns struct {
+		result1 repositories.JobRecord
+		result2 error
+	}
+	runJobReturnsOnCall map[int]struct {
+		result1 repositories.JobRecord
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CFJobRepository) FetchJob(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.JobRecord, error) {
+	fake.fetchJobMutex.Lock()
+	ret, specificReturn := fake.fetchJobReturnsOnCall[len(fake.fetchJobArgsForCall)]
+	fake.fetchJobArgsForCall = append(fake.fetchJobArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FetchJobStub
+	fakeReturns := fake.fetchJobReturns
+	fake.recordInvocation("FetchJob", []interface{}{arg1, arg2, arg3})
+	fake.fetchJobMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFJobRepository) FetchJobCallCount() int {
+	fake.fetchJobMutex.RLock()
+	defer fake.fetchJobMutex.RUnlock()
+	return len(fake.fetchJobArgsForCall)
+}
+
+func (fake *CFJobRepository) FetchJobCalls(stub func(context.Context, client.Client, string) (repositories.JobRecord, error)) {
+	fake.fetchJobMutex.Lock()
+	defer fake.fetchJobMutex.Unlock()
+	fake.FetchJobStub = stub
+}
+
+func (fake *CFJobRepository) FetchJobArgsForCall(i int) (context.Context, client.Client, string) {
+	fake.fetchJobMutex.RLock()
+	defer fake.fetchJobMutex.RUnlock()
+	argsForCall := fake.fetchJobArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFJobRepository) FetchJobReturns(result1 repositories.JobRecord, result2 error) {
+	fake.fetchJobMutex.Lock()
+	defer fake.fetchJobMutex.Unlock()
+	fake.FetchJobStub = nil
+	fake.fetchJobReturns = struct {
+		result1 repositories.JobRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFJobRepository) FetchJobReturnsOnCall(i int, result1 repositories.JobRecord, result2 error) {
+	fake.fetchJobMutex.Lock()
+	defer fake.fetchJobMutex.Unlock()
+	fake.FetchJobStub = nil
+	if fake.fetchJobReturnsOnCall == nil {
+		fake.fetchJobReturnsOnCall = make(map[int]struct {
+			result1 repositories.JobRecord
+			result2 error
+		})
+	}
+	fake.fetchJobReturnsOnCall[i] = struct {
+		result1 repositories.JobRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFJobRepository) RunJob(arg1 context.Context, arg2 string, arg3 string, arg4 repositories.JobTask) (repositories.JobRecord, error) {
+	fake.runJobMutex.Lock()
+	ret, specificReturn := fake.runJobReturnsOnCall[len(fake.runJobArgsForCall)]
+	fake.runJobArgsForCall = append(fake.runJobArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 repositories.JobTask
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RunJobStub
+	fakeReturns := fake.runJobReturns
+	fake.recordInvocation("RunJob", []interface{}{arg1, arg2, arg3, arg4})
+	fake.runJobMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFJobRepository) RunJobCallCount() int {
+	fake.runJobMutex.RLock()
+	defer fake.runJobMutex.RUnlock()
+	return len(fake.runJobArgsForCall)
+}
+
+func (fake *CFJobRepository) RunJobCalls(stub func(context.Context, string, string, repositories.JobTask) (repositories.JobRecord, error)) {
+	fake.runJobMutex.Lock()
+	defer fake.runJobMutex.Unlock()
+	fake.RunJobStub = stub
+}
+
+func (fake *CFJobRepository) RunJobArgsForCall(i int) (context.Context, string, string, repositories.JobTask) {
+	fake.runJobMutex.RLock()
+	defer fake.runJobMutex.RUnlock()
+	argsForCall := fake.runJobArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *CFJobRepository) RunJobReturns(result1 repositories.JobRecord, result2 error) {
+	fake.runJobMutex.Lock()
+	defer fake.runJobMutex.Unlock()
+	fake.RunJobStub = nil
+	fake.runJobReturns = struct {
+		result1 repositories.JobRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFJobRepository) RunJobReturnsOnCall(i int, result1 repositories.JobRecord, result2 error) {
+	fake.runJobMutex.Lock()
+	defer fake.runJobMutex.Unlock()
+	fake.RunJobStub = nil
+	if fake.runJobReturnsOnCall == nil {
+		fake.runJobReturnsOnCall = make(map[int]struct {
+			result1 repositories.JobRecord
+			result2 error
+		})
+	}
+	fake.runJobReturnsOnCall[i] = struct {
+		result1 repositories.JobRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFJobRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fetchJobMutex.RLock()
+	defer fake.fetchJobMutex.RUnlock()
+	fake.runJobMutex.RLock()
+	defer fake.runJobMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CFJobRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.CFJobRepository = new(CFJobRepository)
@@ -7,6 +7,7 @@ import (
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type CFOrgRepository struct {
@@ -24,11 +25,12 @@ type CFOrgRepository struct {
 		result1 repositories.OrgRecord
 		result2 error
 	}
-	FetchOrgsStub        func(context.Context, []string) ([]repositories.OrgRecord, error)
+	FetchOrgsStub        func(context.Context, []string, labels.Selector) ([]repositories.OrgRecord, error)
 	fetchOrgsMutex       sync.RWMutex
 	fetchOrgsArgsForCall []struct {
 		arg1 context.Context
 		arg2 []string
+		arg3 labels.Selector
 	}
 	fetchOrgsReturns struct {
 		result1 []repositories.OrgRecord
@@ -38,6 +40,20 @@ type CFOrgRepository struct {
 		result1 []repositories.OrgRecord
 		result2 error
 	}
+	PatchOrgMetadataStub        func(context.Context, repositories.MetadataPatchMessage) (repositories.OrgRecord, error)
+	patchOrgMetadataMutex       sync.RWMutex
+	patchOrgMetadataArgsForCall []struct {
+		arg1 context.Context
+		arg2 repositories.MetadataPatchMessage
+	}
+	patchOrgMetadataReturns struct {
+		result1 repositories.OrgRecord
+		result2 error
+	}
+	patchOrgMetadataReturnsOnCall map[int]struct {
+		result1 repositories.OrgRecord
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -107,7 +123,7 @@ func (fake *CFOrgRepository) CreateOrgReturnsOnCall(i int, result1 repositories.
 	}{result1, result2}
 }
 
-func (fake *CFOrgRepository) FetchOrgs(arg1 context.Context, arg2 []string) ([]repositories.OrgRecord, error) {
+func (fake *CFOrgRepository) FetchOrgs(arg1 context.Context, arg2 []string, arg3 labels.Selector) ([]repositories.OrgRecord, error) {
 	var arg2Copy []string
 	if arg2 != nil {
 		arg2Copy = make([]string, len(arg2))
@@ -118,13 +134,14 @@ func (fake *CFOrgRepository) FetchOrgs(arg1 context.Context, arg2 []string) ([]r
 	fake.fetchOrgsArgsForCall = append(fake.fetchOrgsArgsForCall, struct {
 		arg1 context.Context
 		arg2 []string
-	}{arg1, arg2Copy})
+		arg3 labels.Selector
+	}{arg1, arg2Copy, arg3})
 	stub := fake.FetchOrgsStub
 	fakeReturns := fake.fetchOrgsReturns
-	fake.recordInvocation("FetchOrgs", []interface{}{arg1, arg2Copy})
+	fake.recordInvocation("FetchOrgs", []interface{}{arg1, arg2Copy, arg3})
 	fake.fetchOrgsMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -138,17 +155,17 @@ func (fake *CFOrgRepository) FetchOrgsCallCount() int {
 	return len(fake.fetchOrgsArgsForCall)
 }
 
-func (fake *CFOrgRepository) FetchOrgsCalls(stub func(context.Context, []string) ([]repositories.OrgRecord, error)) {
+func (fake *CFOrgRepository) FetchOrgsCalls(stub func(context.Context, []string, labels.Selector) ([]repositories.OrgRecord, error)) {
 	fake.fetchOrgsMutex.Lock()
 	defer fake.fetchOrgsMutex.Unlock()
 	fake.FetchOrgsStub = stub
 }
 
-func (fake *CFOrgRepository) FetchOrgsArgsForCall(i int) (context.Context, []string) {
+func (fake *CFOrgRepository) FetchOrgsArgsForCall(i int) (context.Context, []string, labels.Selector) {
 	fake.fetchOrgsMutex.RLock()
 	defer fake.fetchOrgsMutex.RUnlock()
 	argsForCall := fake.fetchOrgsArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *CFOrgRepository) FetchOrgsReturns(result1 []repositories.OrgRecord, result2 error) {
@@ -177,6 +194,71 @@ func (fake *CFOrgRepository) FetchOrgsReturnsOnCall(i int, result1 []repositorie
 	}{result1, result2}
 }
 
+func (fake *CFOrgRepository) PatchOrgMetadata(arg1 context.Context, arg2 repositories.MetadataPatchMessage) (repositories.OrgRecord, error) {
+	fake.patchOrgMetadataMutex.Lock()
+	ret, specificReturn := fake.patchOrgMetadataReturnsOnCall[len(fake.patchOrgMetadataArgsForCall)]
+	fake.patchOrgMetadataArgsForCall = append(fake.patchOrgMetadataArgsForCall, struct {
+		arg1 context.Context
+		arg2 repositories.MetadataPatchMessage
+	}{arg1, arg2})
+	stub := fake.PatchOrgMetadataStub
+	fakeReturns := fake.patchOrgMetadataReturns
+	fake.recordInvocation("PatchOrgMetadata", []interface{}{arg1, arg2})
+	fake.patchOrgMetadataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFOrgRepository) PatchOrgMetadataCallCount() int {
+	fake.patchOrgMetadataMutex.RLock()
+	defer fake.patchOrgMetadataMutex.RUnlock()
+	return len(fake.patchOrgMetadataArgsForCall)
+}
+
+func (fake *CFOrgRepository) PatchOrgMetadataCalls(stub func(context.Context, repositories.MetadataPatchMessage) (repositories.OrgRecord, error)) {
+	fake.patchOrgMetadataMutex.Lock()
+	defer fake.patchOrgMetadataMutex.Unlock()
+	fake.PatchOrgMetadataStub = stub
+}
+
+func (fake *CFOrgRepository) PatchOrgMetadataArgsForCall(i int) (context.Context, repositories.MetadataPatchMessage) {
+	fake.patchOrgMetadataMutex.RLock()
+	defer fake.patchOrgMetadataMutex.RUnlock()
+	argsForCall := fake.patchOrgMetadataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CFOrgRepository) PatchOrgMetadataReturns(result1 repositories.OrgRecord, result2 error) {
+	fake.patchOrgMetadataMutex.Lock()
+	defer fake.patchOrgMetadataMutex.Unlock()
+	fake.PatchOrgMetadataStub = nil
+	fake.patchOrgMetadataReturns = struct {
+		result1 repositories.OrgRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFOrgRepository) PatchOrgMetadataReturnsOnCall(i int, result1 repositories.OrgRecord, result2 error) {
+	fake.patchOrgMetadataMutex.Lock()
+	defer fake.patchOrgMetadataMutex.Unlock()
+	fake.PatchOrgMetadataStub = nil
+	if fake.patchOrgMetadataReturnsOnCall == nil {
+		fake.patchOrgMetadataReturnsOnCall = make(map[int]struct {
+			result1 repositories.OrgRecord
+			result2 error
+		})
+	}
+	fake.patchOrgMetadataReturnsOnCall[i] = struct {
+		result1 repositories.OrgRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFOrgRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -184,6 +266,8 @@ func (fake *CFOrgRepository) Invocations() map[string][][]interface{} {
 	defer fake.createOrgMutex.RUnlock()
 	fake.fetchOrgsMutex.RLock()
 	defer fake.fetchOrgsMutex.RUnlock()
+	fake.patchOrgMetadataMutex.RLock()
+	defer fake.patchOrgMetadataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
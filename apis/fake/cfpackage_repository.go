@@ -41,6 +41,21 @@ type CFPackageRepository struct {
 		result1 repositories.PackageRecord
 		result2 error
 	}
+	PatchPackageMetadataStub        func(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.PackageRecord, error)
+	patchPackageMetadataMutex       sync.RWMutex
+	patchPackageMetadataArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.MetadataPatchMessage
+	}
+	patchPackageMetadataReturns struct {
+		result1 repositories.PackageRecord
+		result2 error
+	}
+	patchPackageMetadataReturnsOnCall map[int]struct {
+		result1 repositories.PackageRecord
+		result2 error
+	}
 	UpdatePackageSourceStub        func(context.Context, client.Client, repositories.PackageUpdateSourceMessage) (repositories.PackageRecord, error)
 	updatePackageSourceMutex       sync.RWMutex
 	updatePackageSourceArgsForCall []struct {
@@ -192,6 +207,72 @@ func (fake *CFPackageRepository) FetchPackageReturnsOnCall(i int, result1 reposi
 	}{result1, result2}
 }
 
+func (fake *CFPackageRepository) PatchPackageMetadata(arg1 context.Context, arg2 client.Client, arg3 repositories.MetadataPatchMessage) (repositories.PackageRecord, error) {
+	fake.patchPackageMetadataMutex.Lock()
+	ret, specificReturn := fake.patchPackageMetadataReturnsOnCall[len(fake.patchPackageMetadataArgsForCall)]
+	fake.patchPackageMetadataArgsForCall = append(fake.patchPackageMetadataArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.MetadataPatchMessage
+	}{arg1, arg2, arg3})
+	stub := fake.PatchPackageMetadataStub
+	fakeReturns := fake.patchPackageMetadataReturns
+	fake.recordInvocation("PatchPackageMetadata", []interface{}{arg1, arg2, arg3})
+	fake.patchPackageMetadataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFPackageRepository) PatchPackageMetadataCallCount() int {
+	fake.patchPackageMetadataMutex.RLock()
+	defer fake.patchPackageMetadataMutex.RUnlock()
+	return len(fake.patchPackageMetadataArgsForCall)
+}
+
+func (fake *CFPackageRepository) PatchPackageMetadataCalls(stub func(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.PackageRecord, error)) {
+	fake.patchPackageMetadataMutex.Lock()
+	defer fake.patchPackageMetadataMutex.Unlock()
+	fake.PatchPackageMetadataStub = stub
+}
+
+func (fake *CFPackageRepository) PatchPackageMetadataArgsForCall(i int) (context.Context, client.Client, repositories.MetadataPatchMessage) {
+	fake.patchPackageMetadataMutex.RLock()
+	defer fake.patchPackageMetadataMutex.RUnlock()
+	argsForCall := fake.patchPackageMetadataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFPackageRepository) PatchPackageMetadataReturns(result1 repositories.PackageRecord, result2 error) {
+	fake.patchPackageMetadataMutex.Lock()
+	defer fake.patchPackageMetadataMutex.Unlock()
+	fake.PatchPackageMetadataStub = nil
+	fake.patchPackageMetadataReturns = struct {
+		result1 repositories.PackageRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFPackageRepository) PatchPackageMetadataReturnsOnCall(i int, result1 repositories.PackageRecord, result2 error) {
+	fake.patchPackageMetadataMutex.Lock()
+	defer fake.patchPackageMetadataMutex.Unlock()
+	fake.PatchPackageMetadataStub = nil
+	if fake.patchPackageMetadataReturnsOnCall == nil {
+		fake.patchPackageMetadataReturnsOnCall = make(map[int]struct {
+			result1 repositories.PackageRecord
+			result2 error
+		})
+	}
+	fake.patchPackageMetadataReturnsOnCall[i] = struct {
+		result1 repositories.PackageRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFPackageRepository) UpdatePackageSource(arg1 context.Context, arg2 client.Client, arg3 repositories.PackageUpdateSourceMessage) (repositories.PackageRecord, error) {
 	fake.updatePackageSourceMutex.Lock()
 	ret, specificReturn := fake.updatePackageSourceReturnsOnCall[len(fake.updatePackageSourceArgsForCall)]
@@ -265,6 +346,8 @@ func (fake *CFPackageRepository) Invocations() map[string][][]interface{} {
 	defer fake.createPackageMutex.RUnlock()
 	fake.fetchPackageMutex.RLock()
 	defer fake.fetchPackageMutex.RUnlock()
+	fake.patchPackageMetadataMutex.RLock()
+	defer fake.patchPackageMetadataMutex.RUnlock()
 	fake.updatePackageSourceMutex.RLock()
 	defer fake.updatePackageSourceMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -7,10 +7,26 @@ import (
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type CFProcessRepository struct {
+	CreateProcessStub        func(context.Context, client.Client, repositories.ProcessCreateMessage) (repositories.ProcessRecord, error)
+	createProcessMutex       sync.RWMutex
+	createProcessArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessCreateMessage
+	}
+	createProcessReturns struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}
+	createProcessReturnsOnCall map[int]struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}
 	FetchProcessStub        func(context.Context, client.Client, string) (repositories.ProcessRecord, error)
 	fetchProcessMutex       sync.RWMutex
 	fetchProcessArgsForCall []struct {
@@ -26,13 +42,29 @@ type CFProcessRepository struct {
 		result1 repositories.ProcessRecord
 		result2 error
 	}
-	FetchProcessesForAppStub        func(context.Context, client.Client, string, string) ([]repositories.ProcessRecord, error)
+	FetchProcessStatsStub        func(context.Context, client.Client, repositories.ProcessRecord) ([]repositories.ProcessInstanceStatsRecord, error)
+	fetchProcessStatsMutex       sync.RWMutex
+	fetchProcessStatsArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessRecord
+	}
+	fetchProcessStatsReturns struct {
+		result1 []repositories.ProcessInstanceStatsRecord
+		result2 error
+	}
+	fetchProcessStatsReturnsOnCall map[int]struct {
+		result1 []repositories.ProcessInstanceStatsRecord
+		result2 error
+	}
+	FetchProcessesForAppStub        func(context.Context, client.Client, string, string, labels.Selector) ([]repositories.ProcessRecord, error)
 	fetchProcessesForAppMutex       sync.RWMutex
 	fetchProcessesForAppArgsForCall []struct {
 		arg1 context.Context
 		arg2 client.Client
 		arg3 string
 		arg4 string
+		arg5 labels.Selector
 	}
 	fetchProcessesForAppReturns struct {
 		result1 []repositories.ProcessRecord
@@ -42,10 +74,120 @@ type CFProcessRepository struct {
 		result1 []repositories.ProcessRecord
 		result2 error
 	}
+	PatchProcessStub        func(context.Context, client.Client, repositories.ProcessPatchMessage) (repositories.ProcessRecord, error)
+	patchProcessMutex       sync.RWMutex
+	patchProcessArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessPatchMessage
+	}
+	patchProcessReturns struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}
+	patchProcessReturnsOnCall map[int]struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}
+	ScaleProcessStub        func(context.Context, client.Client, repositories.ProcessScaleMessage) (repositories.ProcessRecord, error)
+	scaleProcessMutex       sync.RWMutex
+	scaleProcessArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessScaleMessage
+	}
+	scaleProcessReturns struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}
+	scaleProcessReturnsOnCall map[int]struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}
+	TerminateProcessInstanceStub        func(context.Context, client.Client, repositories.ProcessRecord, int) error
+	terminateProcessInstanceMutex       sync.RWMutex
+	terminateProcessInstanceArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessRecord
+		arg4 int
+	}
+	terminateProcessInstanceReturns struct {
+		result1 error
+	}
+	terminateProcessInstanceReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *CFProcessRepository) CreateProcess(arg1 context.Context, arg2 client.Client, arg3 repositories.ProcessCreateMessage) (repositories.ProcessRecord, error) {
+	fake.createProcessMutex.Lock()
+	ret, specificReturn := fake.createProcessReturnsOnCall[len(fake.createProcessArgsForCall)]
+	fake.createProcessArgsForCall = append(fake.createProcessArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessCreateMessage
+	}{arg1, arg2, arg3})
+	stub := fake.CreateProcessStub
+	fakeReturns := fake.createProcessReturns
+	fake.recordInvocation("CreateProcess", []interface{}{arg1, arg2, arg3})
+	fake.createProcessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFProcessRepository) CreateProcessCallCount() int {
+	fake.createProcessMutex.RLock()
+	defer fake.createProcessMutex.RUnlock()
+	return len(fake.createProcessArgsForCall)
+}
+
+func (fake *CFProcessRepository) CreateProcessCalls(stub func(context.Context, client.Client, repositories.ProcessCreateMessage) (repositories.ProcessRecord, error)) {
+	fake.createProcessMutex.Lock()
+	defer fake.createProcessMutex.Unlock()
+	fake.CreateProcessStub = stub
+}
+
+func (fake *CFProcessRepository) CreateProcessArgsForCall(i int) (context.Context, client.Client, repositories.ProcessCreateMessage) {
+	fake.createProcessMutex.RLock()
+	defer fake.createProcessMutex.RUnlock()
+	argsForCall := fake.createProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFProcessRepository) CreateProcessReturns(result1 repositories.ProcessRecord, result2 error) {
+	fake.createProcessMutex.Lock()
+	defer fake.createProcessMutex.Unlock()
+	fake.CreateProcessStub = nil
+	fake.createProcessReturns = struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) CreateProcessReturnsOnCall(i int, result1 repositories.ProcessRecord, result2 error) {
+	fake.createProcessMutex.Lock()
+	defer fake.createProcessMutex.Unlock()
+	fake.CreateProcessStub = nil
+	if fake.createProcessReturnsOnCall == nil {
+		fake.createProcessReturnsOnCall = make(map[int]struct {
+			result1 repositories.ProcessRecord
+			result2 error
+		})
+	}
+	fake.createProcessReturnsOnCall[i] = struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFProcessRepository) FetchProcess(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.ProcessRecord, error) {
 	fake.fetchProcessMutex.Lock()
 	ret, specificReturn := fake.fetchProcessReturnsOnCall[len(fake.fetchProcessArgsForCall)]
@@ -112,7 +254,73 @@ func (fake *CFProcessRepository) FetchProcessReturnsOnCall(i int, result1 reposi
 	}{result1, result2}
 }
 
-func (fake *CFProcessRepository) FetchProcessesForApp(arg1 context.Context, arg2 client.Client, arg3 string, arg4 string) ([]repositories.ProcessRecord, error) {
+func (fake *CFProcessRepository) FetchProcessStats(arg1 context.Context, arg2 client.Client, arg3 repositories.ProcessRecord) ([]repositories.ProcessInstanceStatsRecord, error) {
+	fake.fetchProcessStatsMutex.Lock()
+	ret, specificReturn := fake.fetchProcessStatsReturnsOnCall[len(fake.fetchProcessStatsArgsForCall)]
+	fake.fetchProcessStatsArgsForCall = append(fake.fetchProcessStatsArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessRecord
+	}{arg1, arg2, arg3})
+	stub := fake.FetchProcessStatsStub
+	fakeReturns := fake.fetchProcessStatsReturns
+	fake.recordInvocation("FetchProcessStats", []interface{}{arg1, arg2, arg3})
+	fake.fetchProcessStatsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFProcessRepository) FetchProcessStatsCallCount() int {
+	fake.fetchProcessStatsMutex.RLock()
+	defer fake.fetchProcessStatsMutex.RUnlock()
+	return len(fake.fetchProcessStatsArgsForCall)
+}
+
+func (fake *CFProcessRepository) FetchProcessStatsCalls(stub func(context.Context, client.Client, repositories.ProcessRecord) ([]repositories.ProcessInstanceStatsRecord, error)) {
+	fake.fetchProcessStatsMutex.Lock()
+	defer fake.fetchProcessStatsMutex.Unlock()
+	fake.FetchProcessStatsStub = stub
+}
+
+func (fake *CFProcessRepository) FetchProcessStatsArgsForCall(i int) (context.Context, client.Client, repositories.ProcessRecord) {
+	fake.fetchProcessStatsMutex.RLock()
+	defer fake.fetchProcessStatsMutex.RUnlock()
+	argsForCall := fake.fetchProcessStatsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFProcessRepository) FetchProcessStatsReturns(result1 []repositories.ProcessInstanceStatsRecord, result2 error) {
+	fake.fetchProcessStatsMutex.Lock()
+	defer fake.fetchProcessStatsMutex.Unlock()
+	fake.FetchProcessStatsStub = nil
+	fake.fetchProcessStatsReturns = struct {
+		result1 []repositories.ProcessInstanceStatsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) FetchProcessStatsReturnsOnCall(i int, result1 []repositories.ProcessInstanceStatsRecord, result2 error) {
+	fake.fetchProcessStatsMutex.Lock()
+	defer fake.fetchProcessStatsMutex.Unlock()
+	fake.FetchProcessStatsStub = nil
+	if fake.fetchProcessStatsReturnsOnCall == nil {
+		fake.fetchProcessStatsReturnsOnCall = make(map[int]struct {
+			result1 []repositories.ProcessInstanceStatsRecord
+			result2 error
+		})
+	}
+	fake.fetchProcessStatsReturnsOnCall[i] = struct {
+		result1 []repositories.ProcessInstanceStatsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) FetchProcessesForApp(arg1 context.Context, arg2 client.Client, arg3 string, arg4 string, arg5 labels.Selector) ([]repositories.ProcessRecord, error) {
 	fake.fetchProcessesForAppMutex.Lock()
 	ret, specificReturn := fake.fetchProcessesForAppReturnsOnCall[len(fake.fetchProcessesForAppArgsForCall)]
 	fake.fetchProcessesForAppArgsForCall = append(fake.fetchProcessesForAppArgsForCall, struct {
@@ -120,13 +328,14 @@ func (fake *CFProcessRepository) FetchProcessesForApp(arg1 context.Context, arg2
 		arg2 client.Client
 		arg3 string
 		arg4 string
-	}{arg1, arg2, arg3, arg4})
+		arg5 labels.Selector
+	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.FetchProcessesForAppStub
 	fakeReturns := fake.fetchProcessesForAppReturns
-	fake.recordInvocation("FetchProcessesForApp", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("FetchProcessesForApp", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.fetchProcessesForAppMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -140,17 +349,17 @@ func (fake *CFProcessRepository) FetchProcessesForAppCallCount() int {
 	return len(fake.fetchProcessesForAppArgsForCall)
 }
 
-func (fake *CFProcessRepository) FetchProcessesForAppCalls(stub func(context.Context, client.Client, string, string) ([]repositories.ProcessRecord, error)) {
+func (fake *CFProcessRepository) FetchProcessesForAppCalls(stub func(context.Context, client.Client, string, string, labels.Selector) ([]repositories.ProcessRecord, error)) {
 	fake.fetchProcessesForAppMutex.Lock()
 	defer fake.fetchProcessesForAppMutex.Unlock()
 	fake.FetchProcessesForAppStub = stub
 }
 
-func (fake *CFProcessRepository) FetchProcessesForAppArgsForCall(i int) (context.Context, client.Client, string, string) {
+func (fake *CFProcessRepository) FetchProcessesForAppArgsForCall(i int) (context.Context, client.Client, string, string, labels.Selector) {
 	fake.fetchProcessesForAppMutex.RLock()
 	defer fake.fetchProcessesForAppMutex.RUnlock()
 	argsForCall := fake.fetchProcessesForAppArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *CFProcessRepository) FetchProcessesForAppReturns(result1 []repositories.ProcessRecord, result2 error) {
@@ -179,13 +388,219 @@ func (fake *CFProcessRepository) FetchProcessesForAppReturnsOnCall(i int, result
 	}{result1, result2}
 }
 
+func (fake *CFProcessRepository) PatchProcess(arg1 context.Context, arg2 client.Client, arg3 repositories.ProcessPatchMessage) (repositories.ProcessRecord, error) {
+	fake.patchProcessMutex.Lock()
+	ret, specificReturn := fake.patchProcessReturnsOnCall[len(fake.patchProcessArgsForCall)]
+	fake.patchProcessArgsForCall = append(fake.patchProcessArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessPatchMessage
+	}{arg1, arg2, arg3})
+	stub := fake.PatchProcessStub
+	fakeReturns := fake.patchProcessReturns
+	fake.recordInvocation("PatchProcess", []interface{}{arg1, arg2, arg3})
+	fake.patchProcessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFProcessRepository) PatchProcessCallCount() int {
+	fake.patchProcessMutex.RLock()
+	defer fake.patchProcessMutex.RUnlock()
+	return len(fake.patchProcessArgsForCall)
+}
+
+func (fake *CFProcessRepository) PatchProcessCalls(stub func(context.Context, client.Client, repositories.ProcessPatchMessage) (repositories.ProcessRecord, error)) {
+	fake.patchProcessMutex.Lock()
+	defer fake.patchProcessMutex.Unlock()
+	fake.PatchProcessStub = stub
+}
+
+func (fake *CFProcessRepository) PatchProcessArgsForCall(i int) (context.Context, client.Client, repositories.ProcessPatchMessage) {
+	fake.patchProcessMutex.RLock()
+	defer fake.patchProcessMutex.RUnlock()
+	argsForCall := fake.patchProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFProcessRepository) PatchProcessReturns(result1 repositories.ProcessRecord, result2 error) {
+	fake.patchProcessMutex.Lock()
+	defer fake.patchProcessMutex.Unlock()
+	fake.PatchProcessStub = nil
+	fake.patchProcessReturns = struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) PatchProcessReturnsOnCall(i int, result1 repositories.ProcessRecord, result2 error) {
+	fake.patchProcessMutex.Lock()
+	defer fake.patchProcessMutex.Unlock()
+	fake.PatchProcessStub = nil
+	if fake.patchProcessReturnsOnCall == nil {
+		fake.patchProcessReturnsOnCall = make(map[int]struct {
+			result1 repositories.ProcessRecord
+			result2 error
+		})
+	}
+	fake.patchProcessReturnsOnCall[i] = struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) ScaleProcess(arg1 context.Context, arg2 client.Client, arg3 repositories.ProcessScaleMessage) (repositories.ProcessRecord, error) {
+	fake.scaleProcessMutex.Lock()
+	ret, specificReturn := fake.scaleProcessReturnsOnCall[len(fake.scaleProcessArgsForCall)]
+	fake.scaleProcessArgsForCall = append(fake.scaleProcessArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessScaleMessage
+	}{arg1, arg2, arg3})
+	stub := fake.ScaleProcessStub
+	fakeReturns := fake.scaleProcessReturns
+	fake.recordInvocation("ScaleProcess", []interface{}{arg1, arg2, arg3})
+	fake.scaleProcessMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFProcessRepository) ScaleProcessCallCount() int {
+	fake.scaleProcessMutex.RLock()
+	defer fake.scaleProcessMutex.RUnlock()
+	return len(fake.scaleProcessArgsForCall)
+}
+
+func (fake *CFProcessRepository) ScaleProcessCalls(stub func(context.Context, client.Client, repositories.ProcessScaleMessage) (repositories.ProcessRecord, error)) {
+	fake.scaleProcessMutex.Lock()
+	defer fake.scaleProcessMutex.Unlock()
+	fake.ScaleProcessStub = stub
+}
+
+func (fake *CFProcessRepository) ScaleProcessArgsForCall(i int) (context.Context, client.Client, repositories.ProcessScaleMessage) {
+	fake.scaleProcessMutex.RLock()
+	defer fake.scaleProcessMutex.RUnlock()
+	argsForCall := fake.scaleProcessArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFProcessRepository) ScaleProcessReturns(result1 repositories.ProcessRecord, result2 error) {
+	fake.scaleProcessMutex.Lock()
+	defer fake.scaleProcessMutex.Unlock()
+	fake.ScaleProcessStub = nil
+	fake.scaleProcessReturns = struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) ScaleProcessReturnsOnCall(i int, result1 repositories.ProcessRecord, result2 error) {
+	fake.scaleProcessMutex.Lock()
+	defer fake.scaleProcessMutex.Unlock()
+	fake.ScaleProcessStub = nil
+	if fake.scaleProcessReturnsOnCall == nil {
+		fake.scaleProcessReturnsOnCall = make(map[int]struct {
+			result1 repositories.ProcessRecord
+			result2 error
+		})
+	}
+	fake.scaleProcessReturnsOnCall[i] = struct {
+		result1 repositories.ProcessRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFProcessRepository) TerminateProcessInstance(arg1 context.Context, arg2 client.Client, arg3 repositories.ProcessRecord, arg4 int) error {
+	fake.terminateProcessInstanceMutex.Lock()
+	ret, specificReturn := fake.terminateProcessInstanceReturnsOnCall[len(fake.terminateProcessInstanceArgsForCall)]
+	fake.terminateProcessInstanceArgsForCall = append(fake.terminateProcessInstanceArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.ProcessRecord
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.TerminateProcessInstanceStub
+	fakeReturns := fake.terminateProcessInstanceReturns
+	fake.recordInvocation("TerminateProcessInstance", []interface{}{arg1, arg2, arg3, arg4})
+	fake.terminateProcessInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *CFProcessRepository) TerminateProcessInstanceCallCount() int {
+	fake.terminateProcessInstanceMutex.RLock()
+	defer fake.terminateProcessInstanceMutex.RUnlock()
+	return len(fake.terminateProcessInstanceArgsForCall)
+}
+
+func (fake *CFProcessRepository) TerminateProcessInstanceCalls(stub func(context.Context, client.Client, repositories.ProcessRecord, int) error) {
+	fake.terminateProcessInstanceMutex.Lock()
+	defer fake.terminateProcessInstanceMutex.Unlock()
+	fake.TerminateProcessInstanceStub = stub
+}
+
+func (fake *CFProcessRepository) TerminateProcessInstanceArgsForCall(i int) (context.Context, client.Client, repositories.ProcessRecord, int) {
+	fake.terminateProcessInstanceMutex.RLock()
+	defer fake.terminateProcessInstanceMutex.RUnlock()
+	argsForCall := fake.terminateProcessInstanceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *CFProcessRepository) TerminateProcessInstanceReturns(result1 error) {
+	fake.terminateProcessInstanceMutex.Lock()
+	defer fake.terminateProcessInstanceMutex.Unlock()
+	fake.TerminateProcessInstanceStub = nil
+	fake.terminateProcessInstanceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *CFProcessRepository) TerminateProcessInstanceReturnsOnCall(i int, result1 error) {
+	fake.terminateProcessInstanceMutex.Lock()
+	defer fake.terminateProcessInstanceMutex.Unlock()
+	fake.TerminateProcessInstanceStub = nil
+	if fake.terminateProcessInstanceReturnsOnCall == nil {
+		fake.terminateProcessInstanceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.terminateProcessInstanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *CFProcessRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.createProcessMutex.RLock()
+	defer fake.createProcessMutex.RUnlock()
 	fake.fetchProcessMutex.RLock()
 	defer fake.fetchProcessMutex.RUnlock()
+	fake.fetchProcessStatsMutex.RLock()
+	defer fake.fetchProcessStatsMutex.RUnlock()
 	fake.fetchProcessesForAppMutex.RLock()
 	defer fake.fetchProcessesForAppMutex.RUnlock()
+	fake.patchProcessMutex.RLock()
+	defer fake.patchProcessMutex.RUnlock()
+	fake.scaleProcessMutex.RLock()
+	defer fake.scaleProcessMutex.RUnlock()
+	fake.terminateProcessInstanceMutex.RLock()
+	defer fake.terminateProcessInstanceMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
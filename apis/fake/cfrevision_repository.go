@@ -0,0 +1,374 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type CFRevisionRepository struct {
+	FetchDeployedRevisionsStub        func(context.Context, client.Client, repositories.AppRecord) ([]repositories.RevisionRecord, error)
+	fetchDeployedRevisionsMutex       sync.RWMutex
+	fetchDeployedRevisionsArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}
+	fetchDeployedRevisionsReturns struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}
+	fetchDeployedRevisionsReturnsOnCall map[int]struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}
+	FetchRevisionStub        func(context.Context, client.Client, string) (repositories.RevisionRecord, error)
+	fetchRevisionMutex       sync.RWMutex
+	fetchRevisionArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}
+	fetchRevisionReturns struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}
+	fetchRevisionReturnsOnCall map[int]struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}
+	FetchRevisionsForAppStub        func(context.Context, client.Client, string, string) ([]repositories.RevisionRecord, error)
+	fetchRevisionsForAppMutex       sync.RWMutex
+	fetchRevisionsForAppArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+		arg4 string
+	}
+	fetchRevisionsForAppReturns struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}
+	fetchRevisionsForAppReturnsOnCall map[int]struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}
+	RecordRevisionStub        func(context.Context, client.Client, repositories.RevisionCreateMessage) (repositories.RevisionRecord, error)
+	recordRevisionMutex       sync.RWMutex
+	recordRevisionArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.RevisionCreateMessage
+	}
+	recordRevisionReturns struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}
+	recordRevisionReturnsOnCall map[int]struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CFRevisionRepository) FetchDeployedRevisions(arg1 context.Context, arg2 client.Client, arg3 repositories.AppRecord) ([]repositories.RevisionRecord, error) {
+	fake.fetchDeployedRevisionsMutex.Lock()
+	ret, specificReturn := fake.fetchDeployedRevisionsReturnsOnCall[len(fake.fetchDeployedRevisionsArgsForCall)]
+	fake.fetchDeployedRevisionsArgsForCall = append(fake.fetchDeployedRevisionsArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.AppRecord
+	}{arg1, arg2, arg3})
+	stub := fake.FetchDeployedRevisionsStub
+	fakeReturns := fake.fetchDeployedRevisionsReturns
+	fake.recordInvocation("FetchDeployedRevisions", []interface{}{arg1, arg2, arg3})
+	fake.fetchDeployedRevisionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFRevisionRepository) FetchDeployedRevisionsCallCount() int {
+	fake.fetchDeployedRevisionsMutex.RLock()
+	defer fake.fetchDeployedRevisionsMutex.RUnlock()
+	return len(fake.fetchDeployedRevisionsArgsForCall)
+}
+
+func (fake *CFRevisionRepository) FetchDeployedRevisionsCalls(stub func(context.Context, client.Client, repositories.AppRecord) ([]repositories.RevisionRecord, error)) {
+	fake.fetchDeployedRevisionsMutex.Lock()
+	defer fake.fetchDeployedRevisionsMutex.Unlock()
+	fake.FetchDeployedRevisionsStub = stub
+}
+
+func (fake *CFRevisionRepository) FetchDeployedRevisionsArgsForCall(i int) (context.Context, client.Client, repositories.AppRecord) {
+	fake.fetchDeployedRevisionsMutex.RLock()
+	defer fake.fetchDeployedRevisionsMutex.RUnlock()
+	argsForCall := fake.fetchDeployedRevisionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFRevisionRepository) FetchDeployedRevisionsReturns(result1 []repositories.RevisionRecord, result2 error) {
+	fake.fetchDeployedRevisionsMutex.Lock()
+	defer fake.fetchDeployedRevisionsMutex.Unlock()
+	fake.FetchDeployedRevisionsStub = nil
+	fake.fetchDeployedRevisionsReturns = struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) FetchDeployedRevisionsReturnsOnCall(i int, result1 []repositories.RevisionRecord, result2 error) {
+	fake.fetchDeployedRevisionsMutex.Lock()
+	defer fake.fetchDeployedRevisionsMutex.Unlock()
+	fake.FetchDeployedRevisionsStub = nil
+	if fake.fetchDeployedRevisionsReturnsOnCall == nil {
+		fake.fetchDeployedRevisionsReturnsOnCall = make(map[int]struct {
+			result1 []repositories.RevisionRecord
+			result2 error
+		})
+	}
+	fake.fetchDeployedRevisionsReturnsOnCall[i] = struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) FetchRevision(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.RevisionRecord, error) {
+	fake.fetchRevisionMutex.Lock()
+	ret, specificReturn := fake.fetchRevisionReturnsOnCall[len(fake.fetchRevisionArgsForCall)]
+	fake.fetchRevisionArgsForCall = append(fake.fetchRevisionArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FetchRevisionStub
+	fakeReturns := fake.fetchRevisionReturns
+	fake.recordInvocation("FetchRevision", []interface{}{arg1, arg2, arg3})
+	fake.fetchRevisionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFRevisionRepository) FetchRevisionCallCount() int {
+	fake.fetchRevisionMutex.RLock()
+	defer fake.fetchRevisionMutex.RUnlock()
+	return len(fake.fetchRevisionArgsForCall)
+}
+
+func (fake *CFRevisionRepository) FetchRevisionCalls(stub func(context.Context, client.Client, string) (repositories.RevisionRecord, error)) {
+	fake.fetchRevisionMutex.Lock()
+	defer fake.fetchRevisionMutex.Unlock()
+	fake.FetchRevisionStub = stub
+}
+
+func (fake *CFRevisionRepository) FetchRevisionArgsForCall(i int) (context.Context, client.Client, string) {
+	fake.fetchRevisionMutex.RLock()
+	defer fake.fetchRevisionMutex.RUnlock()
+	argsForCall := fake.fetchRevisionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFRevisionRepository) FetchRevisionReturns(result1 repositories.RevisionRecord, result2 error) {
+	fake.fetchRevisionMutex.Lock()
+	defer fake.fetchRevisionMutex.Unlock()
+	fake.FetchRevisionStub = nil
+	fake.fetchRevisionReturns = struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) FetchRevisionReturnsOnCall(i int, result1 repositories.RevisionRecord, result2 error) {
+	fake.fetchRevisionMutex.Lock()
+	defer fake.fetchRevisionMutex.Unlock()
+	fake.FetchRevisionStub = nil
+	if fake.fetchRevisionReturnsOnCall == nil {
+		fake.fetchRevisionReturnsOnCall = make(map[int]struct {
+			result1 repositories.RevisionRecord
+			result2 error
+		})
+	}
+	fake.fetchRevisionReturnsOnCall[i] = struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) FetchRevisionsForApp(arg1 context.Context, arg2 client.Client, arg3 string, arg4 string) ([]repositories.RevisionRecord, error) {
+	fake.fetchRevisionsForAppMutex.Lock()
+	ret, specificReturn := fake.fetchRevisionsForAppReturnsOnCall[len(fake.fetchRevisionsForAppArgsForCall)]
+	fake.fetchRevisionsForAppArgsForCall = append(fake.fetchRevisionsForAppArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FetchRevisionsForAppStub
+	fakeReturns := fake.fetchRevisionsForAppReturns
+	fake.recordInvocation("FetchRevisionsForApp", []interface{}{arg1, arg2, arg3, arg4})
+	fake.fetchRevisionsForAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFRevisionRepository) FetchRevisionsForAppCallCount() int {
+	fake.fetchRevisionsForAppMutex.RLock()
+	defer fake.fetchRevisionsForAppMutex.RUnlock()
+	return len(fake.fetchRevisionsForAppArgsForCall)
+}
+
+func (fake *CFRevisionRepository) FetchRevisionsForAppCalls(stub func(context.Context, client.Client, string, string) ([]repositories.RevisionRecord, error)) {
+	fake.fetchRevisionsForAppMutex.Lock()
+	defer fake.fetchRevisionsForAppMutex.Unlock()
+	fake.FetchRevisionsForAppStub = stub
+}
+
+func (fake *CFRevisionRepository) FetchRevisionsForAppArgsForCall(i int) (context.Context, client.Client, string, string) {
+	fake.fetchRevisionsForAppMutex.RLock()
+	defer fake.fetchRevisionsForAppMutex.RUnlock()
+	argsForCall := fake.fetchRevisionsForAppArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *CFRevisionRepository) FetchRevisionsForAppReturns(result1 []repositories.RevisionRecord, result2 error) {
+	fake.fetchRevisionsForAppMutex.Lock()
+	defer fake.fetchRevisionsForAppMutex.Unlock()
+	fake.FetchRevisionsForAppStub = nil
+	fake.fetchRevisionsForAppReturns = struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) FetchRevisionsForAppReturnsOnCall(i int, result1 []repositories.RevisionRecord, result2 error) {
+	fake.fetchRevisionsForAppMutex.Lock()
+	defer fake.fetchRevisionsForAppMutex.Unlock()
+	fake.FetchRevisionsForAppStub = nil
+	if fake.fetchRevisionsForAppReturnsOnCall == nil {
+		fake.fetchRevisionsForAppReturnsOnCall = make(map[int]struct {
+			result1 []repositories.RevisionRecord
+			result2 error
+		})
+	}
+	fake.fetchRevisionsForAppReturnsOnCall[i] = struct {
+		result1 []repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) RecordRevision(arg1 context.Context, arg2 client.Client, arg3 repositories.RevisionCreateMessage) (repositories.RevisionRecord, error) {
+	fake.recordRevisionMutex.Lock()
+	ret, specificReturn := fake.recordRevisionReturnsOnCall[len(fake.recordRevisionArgsForCall)]
+	fake.recordRevisionArgsForCall = append(fake.recordRevisionArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.RevisionCreateMessage
+	}{arg1, arg2, arg3})
+	stub := fake.RecordRevisionStub
+	fakeReturns := fake.recordRevisionReturns
+	fake.recordInvocation("RecordRevision", []interface{}{arg1, arg2, arg3})
+	fake.recordRevisionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFRevisionRepository) RecordRevisionCallCount() int {
+	fake.recordRevisionMutex.RLock()
+	defer fake.recordRevisionMutex.RUnlock()
+	return len(fake.recordRevisionArgsForCall)
+}
+
+func (fake *CFRevisionRepository) RecordRevisionCalls(stub func(context.Context, client.Client, repositories.RevisionCreateMessage) (repositories.RevisionRecord, error)) {
+	fake.recordRevisionMutex.Lock()
+	defer fake.recordRevisionMutex.Unlock()
+	fake.RecordRevisionStub = stub
+}
+
+func (fake *CFRevisionRepository) RecordRevisionArgsForCall(i int) (context.Context, client.Client, repositories.RevisionCreateMessage) {
+	fake.recordRevisionMutex.RLock()
+	defer fake.recordRevisionMutex.RUnlock()
+	argsForCall := fake.recordRevisionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFRevisionRepository) RecordRevisionReturns(result1 repositories.RevisionRecord, result2 error) {
+	fake.recordRevisionMutex.Lock()
+	defer fake.recordRevisionMutex.Unlock()
+	fake.RecordRevisionStub = nil
+	fake.recordRevisionReturns = struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) RecordRevisionReturnsOnCall(i int, result1 repositories.RevisionRecord, result2 error) {
+	fake.recordRevisionMutex.Lock()
+	defer fake.recordRevisionMutex.Unlock()
+	fake.RecordRevisionStub = nil
+	if fake.recordRevisionReturnsOnCall == nil {
+		fake.recordRevisionReturnsOnCall = make(map[int]struct {
+			result1 repositories.RevisionRecord
+			result2 error
+		})
+	}
+	fake.recordRevisionReturnsOnCall[i] = struct {
+		result1 repositories.RevisionRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRevisionRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fetchDeployedRevisionsMutex.RLock()
+	defer fake.fetchDeployedRevisionsMutex.RUnlock()
+	fake.fetchRevisionMutex.RLock()
+	defer fake.fetchRevisionMutex.RUnlock()
+	fake.fetchRevisionsForAppMutex.RLock()
+	defer fake.fetchRevisionsForAppMutex.RUnlock()
+	fake.recordRevisionMutex.RLock()
+	defer fake.recordRevisionMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CFRevisionRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.CFRevisionRepository = new(CFRevisionRepository)
@@ -7,10 +7,26 @@ import (
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type CFRouteRepository struct {
+	AddDestinationsToRouteStub        func(context.Context, client.Client, repositories.RouteAddDestinationsMessage) (repositories.RouteRecord, error)
+	addDestinationsToRouteMutex       sync.RWMutex
+	addDestinationsToRouteArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.RouteAddDestinationsMessage
+	}
+	addDestinationsToRouteReturns struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}
+	addDestinationsToRouteReturnsOnCall map[int]struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}
 	CreateRouteStub        func(context.Context, client.Client, repositories.RouteRecord) (repositories.RouteRecord, error)
 	createRouteMutex       sync.RWMutex
 	createRouteArgsForCall []struct {
@@ -41,27 +57,31 @@ type CFRouteRepository struct {
 		result1 repositories.RouteRecord
 		result2 error
 	}
-	FetchRouteListStub        func(context.Context, client.Client) ([]repositories.RouteRecord, error)
+	FetchRouteListStub        func(context.Context, client.Client, repositories.RouteListMessage) ([]repositories.RouteRecord, int, error)
 	fetchRouteListMutex       sync.RWMutex
 	fetchRouteListArgsForCall []struct {
 		arg1 context.Context
 		arg2 client.Client
+		arg3 repositories.RouteListMessage
 	}
 	fetchRouteListReturns struct {
 		result1 []repositories.RouteRecord
-		result2 error
+		result2 int
+		result3 error
 	}
 	fetchRouteListReturnsOnCall map[int]struct {
 		result1 []repositories.RouteRecord
-		result2 error
+		result2 int
+		result3 error
 	}
-	FetchRoutesForAppStub        func(context.Context, client.Client, string, string) ([]repositories.RouteRecord, error)
+	FetchRoutesForAppStub        func(context.Context, client.Client, string, string, labels.Selector) ([]repositories.RouteRecord, error)
 	fetchRoutesForAppMutex       sync.RWMutex
 	fetchRoutesForAppArgsForCall []struct {
 		arg1 context.Context
 		arg2 client.Client
 		arg3 string
 		arg4 string
+		arg5 labels.Selector
 	}
 	fetchRoutesForAppReturns struct {
 		result1 []repositories.RouteRecord
@@ -71,10 +91,91 @@ type CFRouteRepository struct {
 		result1 []repositories.RouteRecord
 		result2 error
 	}
+	PatchRouteMetadataStub        func(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.RouteRecord, error)
+	patchRouteMetadataMutex       sync.RWMutex
+	patchRouteMetadataArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.MetadataPatchMessage
+	}
+	patchRouteMetadataReturns struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}
+	patchRouteMetadataReturnsOnCall map[int]struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *CFRouteRepository) AddDestinationsToRoute(arg1 context.Context, arg2 client.Client, arg3 repositories.RouteAddDestinationsMessage) (repositories.RouteRecord, error) {
+	fake.addDestinationsToRouteMutex.Lock()
+	ret, specificReturn := fake.addDestinationsToRouteReturnsOnCall[len(fake.addDestinationsToRouteArgsForCall)]
+	fake.addDestinationsToRouteArgsForCall = append(fake.addDestinationsToRouteArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.RouteAddDestinationsMessage
+	}{arg1, arg2, arg3})
+	stub := fake.AddDestinationsToRouteStub
+	fakeReturns := fake.addDestinationsToRouteReturns
+	fake.recordInvocation("AddDestinationsToRoute", []interface{}{arg1, arg2, arg3})
+	fake.addDestinationsToRouteMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFRouteRepository) AddDestinationsToRouteCallCount() int {
+	fake.addDestinationsToRouteMutex.RLock()
+	defer fake.addDestinationsToRouteMutex.RUnlock()
+	return len(fake.addDestinationsToRouteArgsForCall)
+}
+
+func (fake *CFRouteRepository) AddDestinationsToRouteCalls(stub func(context.Context, client.Client, repositories.RouteAddDestinationsMessage) (repositories.RouteRecord, error)) {
+	fake.addDestinationsToRouteMutex.Lock()
+	defer fake.addDestinationsToRouteMutex.Unlock()
+	fake.AddDestinationsToRouteStub = stub
+}
+
+func (fake *CFRouteRepository) AddDestinationsToRouteArgsForCall(i int) (context.Context, client.Client, repositories.RouteAddDestinationsMessage) {
+	fake.addDestinationsToRouteMutex.RLock()
+	defer fake.addDestinationsToRouteMutex.RUnlock()
+	argsForCall := fake.addDestinationsToRouteArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFRouteRepository) AddDestinationsToRouteReturns(result1 repositories.RouteRecord, result2 error) {
+	fake.addDestinationsToRouteMutex.Lock()
+	defer fake.addDestinationsToRouteMutex.Unlock()
+	fake.AddDestinationsToRouteStub = nil
+	fake.addDestinationsToRouteReturns = struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRouteRepository) AddDestinationsToRouteReturnsOnCall(i int, result1 repositories.RouteRecord, result2 error) {
+	fake.addDestinationsToRouteMutex.Lock()
+	defer fake.addDestinationsToRouteMutex.Unlock()
+	fake.AddDestinationsToRouteStub = nil
+	if fake.addDestinationsToRouteReturnsOnCall == nil {
+		fake.addDestinationsToRouteReturnsOnCall = make(map[int]struct {
+			result1 repositories.RouteRecord
+			result2 error
+		})
+	}
+	fake.addDestinationsToRouteReturnsOnCall[i] = struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFRouteRepository) CreateRoute(arg1 context.Context, arg2 client.Client, arg3 repositories.RouteRecord) (repositories.RouteRecord, error) {
 	fake.createRouteMutex.Lock()
 	ret, specificReturn := fake.createRouteReturnsOnCall[len(fake.createRouteArgsForCall)]
@@ -207,24 +308,25 @@ func (fake *CFRouteRepository) FetchRouteReturnsOnCall(i int, result1 repositori
 	}{result1, result2}
 }
 
-func (fake *CFRouteRepository) FetchRouteList(arg1 context.Context, arg2 client.Client) ([]repositories.RouteRecord, error) {
+func (fake *CFRouteRepository) FetchRouteList(arg1 context.Context, arg2 client.Client, arg3 repositories.RouteListMessage) ([]repositories.RouteRecord, int, error) {
 	fake.fetchRouteListMutex.Lock()
 	ret, specificReturn := fake.fetchRouteListReturnsOnCall[len(fake.fetchRouteListArgsForCall)]
 	fake.fetchRouteListArgsForCall = append(fake.fetchRouteListArgsForCall, struct {
 		arg1 context.Context
 		arg2 client.Client
-	}{arg1, arg2})
+		arg3 repositories.RouteListMessage
+	}{arg1, arg2, arg3})
 	stub := fake.FetchRouteListStub
 	fakeReturns := fake.fetchRouteListReturns
-	fake.recordInvocation("FetchRouteList", []interface{}{arg1, arg2})
+	fake.recordInvocation("FetchRouteList", []interface{}{arg1, arg2, arg3})
 	fake.fetchRouteListMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
 func (fake *CFRouteRepository) FetchRouteListCallCount() int {
@@ -233,46 +335,49 @@ func (fake *CFRouteRepository) FetchRouteListCallCount() int {
 	return len(fake.fetchRouteListArgsForCall)
 }
 
-func (fake *CFRouteRepository) FetchRouteListCalls(stub func(context.Context, client.Client) ([]repositories.RouteRecord, error)) {
+func (fake *CFRouteRepository) FetchRouteListCalls(stub func(context.Context, client.Client, repositories.RouteListMessage) ([]repositories.RouteRecord, int, error)) {
 	fake.fetchRouteListMutex.Lock()
 	defer fake.fetchRouteListMutex.Unlock()
 	fake.FetchRouteListStub = stub
 }
 
-func (fake *CFRouteRepository) FetchRouteListArgsForCall(i int) (context.Context, client.Client) {
+func (fake *CFRouteRepository) FetchRouteListArgsForCall(i int) (context.Context, client.Client, repositories.RouteListMessage) {
 	fake.fetchRouteListMutex.RLock()
 	defer fake.fetchRouteListMutex.RUnlock()
 	argsForCall := fake.fetchRouteListArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *CFRouteRepository) FetchRouteListReturns(result1 []repositories.RouteRecord, result2 error) {
+func (fake *CFRouteRepository) FetchRouteListReturns(result1 []repositories.RouteRecord, result2 int, result3 error) {
 	fake.fetchRouteListMutex.Lock()
 	defer fake.fetchRouteListMutex.Unlock()
 	fake.FetchRouteListStub = nil
 	fake.fetchRouteListReturns = struct {
 		result1 []repositories.RouteRecord
-		result2 error
-	}{result1, result2}
+		result2 int
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *CFRouteRepository) FetchRouteListReturnsOnCall(i int, result1 []repositories.RouteRecord, result2 error) {
+func (fake *CFRouteRepository) FetchRouteListReturnsOnCall(i int, result1 []repositories.RouteRecord, result2 int, result3 error) {
 	fake.fetchRouteListMutex.Lock()
 	defer fake.fetchRouteListMutex.Unlock()
 	fake.FetchRouteListStub = nil
 	if fake.fetchRouteListReturnsOnCall == nil {
 		fake.fetchRouteListReturnsOnCall = make(map[int]struct {
 			result1 []repositories.RouteRecord
-			result2 error
+			result2 int
+			result3 error
 		})
 	}
 	fake.fetchRouteListReturnsOnCall[i] = struct {
 		result1 []repositories.RouteRecord
-		result2 error
-	}{result1, result2}
+		result2 int
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *CFRouteRepository) FetchRoutesForApp(arg1 context.Context, arg2 client.Client, arg3 string, arg4 string) ([]repositories.RouteRecord, error) {
+func (fake *CFRouteRepository) FetchRoutesForApp(arg1 context.Context, arg2 client.Client, arg3 string, arg4 string, arg5 labels.Selector) ([]repositories.RouteRecord, error) {
 	fake.fetchRoutesForAppMutex.Lock()
 	ret, specificReturn := fake.fetchRoutesForAppReturnsOnCall[len(fake.fetchRoutesForAppArgsForCall)]
 	fake.fetchRoutesForAppArgsForCall = append(fake.fetchRoutesForAppArgsForCall, struct {
@@ -280,13 +385,14 @@ func (fake *CFRouteRepository) FetchRoutesForApp(arg1 context.Context, arg2 clie
 		arg2 client.Client
 		arg3 string
 		arg4 string
-	}{arg1, arg2, arg3, arg4})
+		arg5 labels.Selector
+	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.FetchRoutesForAppStub
 	fakeReturns := fake.fetchRoutesForAppReturns
-	fake.recordInvocation("FetchRoutesForApp", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("FetchRoutesForApp", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.fetchRoutesForAppMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -300,17 +406,17 @@ func (fake *CFRouteRepository) FetchRoutesForAppCallCount() int {
 	return len(fake.fetchRoutesForAppArgsForCall)
 }
 
-func (fake *CFRouteRepository) FetchRoutesForAppCalls(stub func(context.Context, client.Client, string, string) ([]repositories.RouteRecord, error)) {
+func (fake *CFRouteRepository) FetchRoutesForAppCalls(stub func(context.Context, client.Client, string, string, labels.Selector) ([]repositories.RouteRecord, error)) {
 	fake.fetchRoutesForAppMutex.Lock()
 	defer fake.fetchRoutesForAppMutex.Unlock()
 	fake.FetchRoutesForAppStub = stub
 }
 
-func (fake *CFRouteRepository) FetchRoutesForAppArgsForCall(i int) (context.Context, client.Client, string, string) {
+func (fake *CFRouteRepository) FetchRoutesForAppArgsForCall(i int) (context.Context, client.Client, string, string, labels.Selector) {
 	fake.fetchRoutesForAppMutex.RLock()
 	defer fake.fetchRoutesForAppMutex.RUnlock()
 	argsForCall := fake.fetchRoutesForAppArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *CFRouteRepository) FetchRoutesForAppReturns(result1 []repositories.RouteRecord, result2 error) {
@@ -339,9 +445,77 @@ func (fake *CFRouteRepository) FetchRoutesForAppReturnsOnCall(i int, result1 []r
 	}{result1, result2}
 }
 
+func (fake *CFRouteRepository) PatchRouteMetadata(arg1 context.Context, arg2 client.Client, arg3 repositories.MetadataPatchMessage) (repositories.RouteRecord, error) {
+	fake.patchRouteMetadataMutex.Lock()
+	ret, specificReturn := fake.patchRouteMetadataReturnsOnCall[len(fake.patchRouteMetadataArgsForCall)]
+	fake.patchRouteMetadataArgsForCall = append(fake.patchRouteMetadataArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.MetadataPatchMessage
+	}{arg1, arg2, arg3})
+	stub := fake.PatchRouteMetadataStub
+	fakeReturns := fake.patchRouteMetadataReturns
+	fake.recordInvocation("PatchRouteMetadata", []interface{}{arg1, arg2, arg3})
+	fake.patchRouteMetadataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFRouteRepository) PatchRouteMetadataCallCount() int {
+	fake.patchRouteMetadataMutex.RLock()
+	defer fake.patchRouteMetadataMutex.RUnlock()
+	return len(fake.patchRouteMetadataArgsForCall)
+}
+
+func (fake *CFRouteRepository) PatchRouteMetadataCalls(stub func(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.RouteRecord, error)) {
+	fake.patchRouteMetadataMutex.Lock()
+	defer fake.patchRouteMetadataMutex.Unlock()
+	fake.PatchRouteMetadataStub = stub
+}
+
+func (fake *CFRouteRepository) PatchRouteMetadataArgsForCall(i int) (context.Context, client.Client, repositories.MetadataPatchMessage) {
+	fake.patchRouteMetadataMutex.RLock()
+	defer fake.patchRouteMetadataMutex.RUnlock()
+	argsForCall := fake.patchRouteMetadataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFRouteRepository) PatchRouteMetadataReturns(result1 repositories.RouteRecord, result2 error) {
+	fake.patchRouteMetadataMutex.Lock()
+	defer fake.patchRouteMetadataMutex.Unlock()
+	fake.PatchRouteMetadataStub = nil
+	fake.patchRouteMetadataReturns = struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFRouteRepository) PatchRouteMetadataReturnsOnCall(i int, result1 repositories.RouteRecord, result2 error) {
+	fake.patchRouteMetadataMutex.Lock()
+	defer fake.patchRouteMetadataMutex.Unlock()
+	fake.PatchRouteMetadataStub = nil
+	if fake.patchRouteMetadataReturnsOnCall == nil {
+		fake.patchRouteMetadataReturnsOnCall = make(map[int]struct {
+			result1 repositories.RouteRecord
+			result2 error
+		})
+	}
+	fake.patchRouteMetadataReturnsOnCall[i] = struct {
+		result1 repositories.RouteRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFRouteRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.addDestinationsToRouteMutex.RLock()
+	defer fake.addDestinationsToRouteMutex.RUnlock()
 	fake.createRouteMutex.RLock()
 	defer fake.createRouteMutex.RUnlock()
 	fake.fetchRouteMutex.RLock()
@@ -350,6 +524,8 @@ func (fake *CFRouteRepository) Invocations() map[string][][]interface{} {
 	defer fake.fetchRouteListMutex.RUnlock()
 	fake.fetchRoutesForAppMutex.RLock()
 	defer fake.fetchRoutesForAppMutex.RUnlock()
+	fake.patchRouteMetadataMutex.RLock()
+	defer fake.patchRouteMetadataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
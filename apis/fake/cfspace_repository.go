@@ -7,6 +7,7 @@ import (
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type CFSpaceRepository struct {
@@ -24,12 +25,27 @@ type CFSpaceRepository struct {
 		result1 repositories.SpaceRecord
 		result2 error
 	}
-	FetchSpacesStub        func(context.Context, []string, []string) ([]repositories.SpaceRecord, error)
+	FetchSpaceStub        func(context.Context, string) (repositories.SpaceRecord, error)
+	fetchSpaceMutex       sync.RWMutex
+	fetchSpaceArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	fetchSpaceReturns struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	fetchSpaceReturnsOnCall map[int]struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	FetchSpacesStub        func(context.Context, []string, []string, labels.Selector) ([]repositories.SpaceRecord, error)
 	fetchSpacesMutex       sync.RWMutex
 	fetchSpacesArgsForCall []struct {
 		arg1 context.Context
 		arg2 []string
 		arg3 []string
+		arg4 labels.Selector
 	}
 	fetchSpacesReturns struct {
 		result1 []repositories.SpaceRecord
@@ -39,6 +55,20 @@ type CFSpaceRepository struct {
 		result1 []repositories.SpaceRecord
 		result2 error
 	}
+	PatchSpaceMetadataStub        func(context.Context, repositories.MetadataPatchMessage) (repositories.SpaceRecord, error)
+	patchSpaceMetadataMutex       sync.RWMutex
+	patchSpaceMetadataArgsForCall []struct {
+		arg1 context.Context
+		arg2 repositories.MetadataPatchMessage
+	}
+	patchSpaceMetadataReturns struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	patchSpaceMetadataReturnsOnCall map[int]struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -108,7 +138,72 @@ func (fake *CFSpaceRepository) CreateSpaceReturnsOnCall(i int, result1 repositor
 	}{result1, result2}
 }
 
-func (fake *CFSpaceRepository) FetchSpaces(arg1 context.Context, arg2 []string, arg3 []string) ([]repositories.SpaceRecord, error) {
+func (fake *CFSpaceRepository) FetchSpace(arg1 context.Context, arg2 string) (repositories.SpaceRecord, error) {
+	fake.fetchSpaceMutex.Lock()
+	ret, specificReturn := fake.fetchSpaceReturnsOnCall[len(fake.fetchSpaceArgsForCall)]
+	fake.fetchSpaceArgsForCall = append(fake.fetchSpaceArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.FetchSpaceStub
+	fakeReturns := fake.fetchSpaceReturns
+	fake.recordInvocation("FetchSpace", []interface{}{arg1, arg2})
+	fake.fetchSpaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFSpaceRepository) FetchSpaceCallCount() int {
+	fake.fetchSpaceMutex.RLock()
+	defer fake.fetchSpaceMutex.RUnlock()
+	return len(fake.fetchSpaceArgsForCall)
+}
+
+func (fake *CFSpaceRepository) FetchSpaceCalls(stub func(context.Context, string) (repositories.SpaceRecord, error)) {
+	fake.fetchSpaceMutex.Lock()
+	defer fake.fetchSpaceMutex.Unlock()
+	fake.FetchSpaceStub = stub
+}
+
+func (fake *CFSpaceRepository) FetchSpaceArgsForCall(i int) (context.Context, string) {
+	fake.fetchSpaceMutex.RLock()
+	defer fake.fetchSpaceMutex.RUnlock()
+	argsForCall := fake.fetchSpaceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CFSpaceRepository) FetchSpaceReturns(result1 repositories.SpaceRecord, result2 error) {
+	fake.fetchSpaceMutex.Lock()
+	defer fake.fetchSpaceMutex.Unlock()
+	fake.FetchSpaceStub = nil
+	fake.fetchSpaceReturns = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) FetchSpaceReturnsOnCall(i int, result1 repositories.SpaceRecord, result2 error) {
+	fake.fetchSpaceMutex.Lock()
+	defer fake.fetchSpaceMutex.Unlock()
+	fake.FetchSpaceStub = nil
+	if fake.fetchSpaceReturnsOnCall == nil {
+		fake.fetchSpaceReturnsOnCall = make(map[int]struct {
+			result1 repositories.SpaceRecord
+			result2 error
+		})
+	}
+	fake.fetchSpaceReturnsOnCall[i] = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) FetchSpaces(arg1 context.Context, arg2 []string, arg3 []string, arg4 labels.Selector) ([]repositories.SpaceRecord, error) {
 	var arg2Copy []string
 	if arg2 != nil {
 		arg2Copy = make([]string, len(arg2))
@@ -125,13 +220,14 @@ func (fake *CFSpaceRepository) FetchSpaces(arg1 context.Context, arg2 []string,
 		arg1 context.Context
 		arg2 []string
 		arg3 []string
-	}{arg1, arg2Copy, arg3Copy})
+		arg4 labels.Selector
+	}{arg1, arg2Copy, arg3Copy, arg4})
 	stub := fake.FetchSpacesStub
 	fakeReturns := fake.fetchSpacesReturns
-	fake.recordInvocation("FetchSpaces", []interface{}{arg1, arg2Copy, arg3Copy})
+	fake.recordInvocation("FetchSpaces", []interface{}{arg1, arg2Copy, arg3Copy, arg4})
 	fake.fetchSpacesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -145,17 +241,17 @@ func (fake *CFSpaceRepository) FetchSpacesCallCount() int {
 	return len(fake.fetchSpacesArgsForCall)
 }
 
-func (fake *CFSpaceRepository) FetchSpacesCalls(stub func(context.Context, []string, []string) ([]repositories.SpaceRecord, error)) {
+func (fake *CFSpaceRepository) FetchSpacesCalls(stub func(context.Context, []string, []string, labels.Selector) ([]repositories.SpaceRecord, error)) {
 	fake.fetchSpacesMutex.Lock()
 	defer fake.fetchSpacesMutex.Unlock()
 	fake.FetchSpacesStub = stub
 }
 
-func (fake *CFSpaceRepository) FetchSpacesArgsForCall(i int) (context.Context, []string, []string) {
+func (fake *CFSpaceRepository) FetchSpacesArgsForCall(i int) (context.Context, []string, []string, labels.Selector) {
 	fake.fetchSpacesMutex.RLock()
 	defer fake.fetchSpacesMutex.RUnlock()
 	argsForCall := fake.fetchSpacesArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *CFSpaceRepository) FetchSpacesReturns(result1 []repositories.SpaceRecord, result2 error) {
@@ -184,13 +280,82 @@ func (fake *CFSpaceRepository) FetchSpacesReturnsOnCall(i int, result1 []reposit
 	}{result1, result2}
 }
 
+func (fake *CFSpaceRepository) PatchSpaceMetadata(arg1 context.Context, arg2 repositories.MetadataPatchMessage) (repositories.SpaceRecord, error) {
+	fake.patchSpaceMetadataMutex.Lock()
+	ret, specificReturn := fake.patchSpaceMetadataReturnsOnCall[len(fake.patchSpaceMetadataArgsForCall)]
+	fake.patchSpaceMetadataArgsForCall = append(fake.patchSpaceMetadataArgsForCall, struct {
+		arg1 context.Context
+		arg2 repositories.MetadataPatchMessage
+	}{arg1, arg2})
+	stub := fake.PatchSpaceMetadataStub
+	fakeReturns := fake.patchSpaceMetadataReturns
+	fake.recordInvocation("PatchSpaceMetadata", []interface{}{arg1, arg2})
+	fake.patchSpaceMetadataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataCallCount() int {
+	fake.patchSpaceMetadataMutex.RLock()
+	defer fake.patchSpaceMetadataMutex.RUnlock()
+	return len(fake.patchSpaceMetadataArgsForCall)
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataCalls(stub func(context.Context, repositories.MetadataPatchMessage) (repositories.SpaceRecord, error)) {
+	fake.patchSpaceMetadataMutex.Lock()
+	defer fake.patchSpaceMetadataMutex.Unlock()
+	fake.PatchSpaceMetadataStub = stub
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataArgsForCall(i int) (context.Context, repositories.MetadataPatchMessage) {
+	fake.patchSpaceMetadataMutex.RLock()
+	defer fake.patchSpaceMetadataMutex.RUnlock()
+	argsForCall := fake.patchSpaceMetadataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataReturns(result1 repositories.SpaceRecord, result2 error) {
+	fake.patchSpaceMetadataMutex.Lock()
+	defer fake.patchSpaceMetadataMutex.Unlock()
+	fake.PatchSpaceMetadataStub = nil
+	fake.patchSpaceMetadataReturns = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataReturnsOnCall(i int, result1 repositories.SpaceRecord, result2 error) {
+	fake.patchSpaceMetadataMutex.Lock()
+	defer fake.patchSpaceMetadataMutex.Unlock()
+	fake.PatchSpaceMetadataStub = nil
+	if fake.patchSpaceMetadataReturnsOnCall == nil {
+		fake.patchSpaceMetadataReturnsOnCall = make(map[int]struct {
+			result1 repositories.SpaceRecord
+			result2 error
+		})
+	}
+	fake.patchSpaceMetadataReturnsOnCall[i] = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *CFSpaceRepository) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.createSpaceMutex.RLock()
 	defer fake.createSpaceMutex.RUnlock()
+	fake.fetchSpaceMutex.RLock()
+	defer fake.fetchSpaceMutex.RUnlock()
 	fake.fetchSpacesMutex.RLock()
 	defer fake.fetchSpacesMutex.RUnlock()
+	fake.patchSpaceMetadataMutex.RLock()
+	defer fake.patchSpaceMetadataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
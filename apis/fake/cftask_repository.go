@@ -0,0 +1,372 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type CFTaskRepository struct {
+	CancelTaskStub        func(context.Context, client.Client, repositories.TaskRecord) (repositories.TaskRecord, error)
+	cancelTaskMutex       sync.RWMutex
+	cancelTaskArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.TaskRecord
+	}
+	cancelTaskReturns struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}
+	cancelTaskReturnsOnCall map[int]struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}
+	CreateTaskStub        func(context.Context, client.Client, repositories.TaskCreateMessage) (repositories.TaskRecord, error)
+	createTaskMutex       sync.RWMutex
+	createTaskArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.TaskCreateMessage
+	}
+	createTaskReturns struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}
+	createTaskReturnsOnCall map[int]struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}
+	FetchTaskStub        func(context.Context, client.Client, string) (repositories.TaskRecord, error)
+	fetchTaskMutex       sync.RWMutex
+	fetchTaskArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}
+	fetchTaskReturns struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}
+	fetchTaskReturnsOnCall map[int]struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}
+	FetchTaskListStub        func(context.Context, client.Client, repositories.TaskListMessage) ([]repositories.TaskRecord, error)
+	fetchTaskListMutex       sync.RWMutex
+	fetchTaskListArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.TaskListMessage
+	}
+	fetchTaskListReturns struct {
+		result1 []repositories.TaskRecord
+		result2 error
+	}
+	fetchTaskListReturnsOnCall map[int]struct {
+		result1 []repositories.TaskRecord
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CFTaskRepository) CancelTask(arg1 context.Context, arg2 client.Client, arg3 repositories.TaskRecord) (repositories.TaskRecord, error) {
+	fake.cancelTaskMutex.Lock()
+	ret, specificReturn := fake.cancelTaskReturnsOnCall[len(fake.cancelTaskArgsForCall)]
+	fake.cancelTaskArgsForCall = append(fake.cancelTaskArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.TaskRecord
+	}{arg1, arg2, arg3})
+	stub := fake.CancelTaskStub
+	fakeReturns := fake.cancelTaskReturns
+	fake.recordInvocation("CancelTask", []interface{}{arg1, arg2, arg3})
+	fake.cancelTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFTaskRepository) CancelTaskCallCount() int {
+	fake.cancelTaskMutex.RLock()
+	defer fake.cancelTaskMutex.RUnlock()
+	return len(fake.cancelTaskArgsForCall)
+}
+
+func (fake *CFTaskRepository) CancelTaskCalls(stub func(context.Context, client.Client, repositories.TaskRecord) (repositories.TaskRecord, error)) {
+	fake.cancelTaskMutex.Lock()
+	defer fake.cancelTaskMutex.Unlock()
+	fake.CancelTaskStub = stub
+}
+
+func (fake *CFTaskRepository) CancelTaskArgsForCall(i int) (context.Context, client.Client, repositories.TaskRecord) {
+	fake.cancelTaskMutex.RLock()
+	defer fake.cancelTaskMutex.RUnlock()
+	argsForCall := fake.cancelTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFTaskRepository) CancelTaskReturns(result1 repositories.TaskRecord, result2 error) {
+	fake.cancelTaskMutex.Lock()
+	defer fake.cancelTaskMutex.Unlock()
+	fake.CancelTaskStub = nil
+	fake.cancelTaskReturns = struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) CancelTaskReturnsOnCall(i int, result1 repositories.TaskRecord, result2 error) {
+	fake.cancelTaskMutex.Lock()
+	defer fake.cancelTaskMutex.Unlock()
+	fake.CancelTaskStub = nil
+	if fake.cancelTaskReturnsOnCall == nil {
+		fake.cancelTaskReturnsOnCall = make(map[int]struct {
+			result1 repositories.TaskRecord
+			result2 error
+		})
+	}
+	fake.cancelTaskReturnsOnCall[i] = struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) CreateTask(arg1 context.Context, arg2 client.Client, arg3 repositories.TaskCreateMessage) (repositories.TaskRecord, error) {
+	fake.createTaskMutex.Lock()
+	ret, specificReturn := fake.createTaskReturnsOnCall[len(fake.createTaskArgsForCall)]
+	fake.createTaskArgsForCall = append(fake.createTaskArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.TaskCreateMessage
+	}{arg1, arg2, arg3})
+	stub := fake.CreateTaskStub
+	fakeReturns := fake.createTaskReturns
+	fake.recordInvocation("CreateTask", []interface{}{arg1, arg2, arg3})
+	fake.createTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFTaskRepository) CreateTaskCallCount() int {
+	fake.createTaskMutex.RLock()
+	defer fake.createTaskMutex.RUnlock()
+	return len(fake.createTaskArgsForCall)
+}
+
+func (fake *CFTaskRepository) CreateTaskCalls(stub func(context.Context, client.Client, repositories.TaskCreateMessage) (repositories.TaskRecord, error)) {
+	fake.createTaskMutex.Lock()
+	defer fake.createTaskMutex.Unlock()
+	fake.CreateTaskStub = stub
+}
+
+func (fake *CFTaskRepository) CreateTaskArgsForCall(i int) (context.Context, client.Client, repositories.TaskCreateMessage) {
+	fake.createTaskMutex.RLock()
+	defer fake.createTaskMutex.RUnlock()
+	argsForCall := fake.createTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFTaskRepository) CreateTaskReturns(result1 repositories.TaskRecord, result2 error) {
+	fake.createTaskMutex.Lock()
+	defer fake.createTaskMutex.Unlock()
+	fake.CreateTaskStub = nil
+	fake.createTaskReturns = struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) CreateTaskReturnsOnCall(i int, result1 repositories.TaskRecord, result2 error) {
+	fake.createTaskMutex.Lock()
+	defer fake.createTaskMutex.Unlock()
+	fake.CreateTaskStub = nil
+	if fake.createTaskReturnsOnCall == nil {
+		fake.createTaskReturnsOnCall = make(map[int]struct {
+			result1 repositories.TaskRecord
+			result2 error
+		})
+	}
+	fake.createTaskReturnsOnCall[i] = struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) FetchTask(arg1 context.Context, arg2 client.Client, arg3 string) (repositories.TaskRecord, error) {
+	fake.fetchTaskMutex.Lock()
+	ret, specificReturn := fake.fetchTaskReturnsOnCall[len(fake.fetchTaskArgsForCall)]
+	fake.fetchTaskArgsForCall = append(fake.fetchTaskArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.FetchTaskStub
+	fakeReturns := fake.fetchTaskReturns
+	fake.recordInvocation("FetchTask", []interface{}{arg1, arg2, arg3})
+	fake.fetchTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFTaskRepository) FetchTaskCallCount() int {
+	fake.fetchTaskMutex.RLock()
+	defer fake.fetchTaskMutex.RUnlock()
+	return len(fake.fetchTaskArgsForCall)
+}
+
+func (fake *CFTaskRepository) FetchTaskCalls(stub func(context.Context, client.Client, string) (repositories.TaskRecord, error)) {
+	fake.fetchTaskMutex.Lock()
+	defer fake.fetchTaskMutex.Unlock()
+	fake.FetchTaskStub = stub
+}
+
+func (fake *CFTaskRepository) FetchTaskArgsForCall(i int) (context.Context, client.Client, string) {
+	fake.fetchTaskMutex.RLock()
+	defer fake.fetchTaskMutex.RUnlock()
+	argsForCall := fake.fetchTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFTaskRepository) FetchTaskReturns(result1 repositories.TaskRecord, result2 error) {
+	fake.fetchTaskMutex.Lock()
+	defer fake.fetchTaskMutex.Unlock()
+	fake.FetchTaskStub = nil
+	fake.fetchTaskReturns = struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) FetchTaskReturnsOnCall(i int, result1 repositories.TaskRecord, result2 error) {
+	fake.fetchTaskMutex.Lock()
+	defer fake.fetchTaskMutex.Unlock()
+	fake.FetchTaskStub = nil
+	if fake.fetchTaskReturnsOnCall == nil {
+		fake.fetchTaskReturnsOnCall = make(map[int]struct {
+			result1 repositories.TaskRecord
+			result2 error
+		})
+	}
+	fake.fetchTaskReturnsOnCall[i] = struct {
+		result1 repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) FetchTaskList(arg1 context.Context, arg2 client.Client, arg3 repositories.TaskListMessage) ([]repositories.TaskRecord, error) {
+	fake.fetchTaskListMutex.Lock()
+	ret, specificReturn := fake.fetchTaskListReturnsOnCall[len(fake.fetchTaskListArgsForCall)]
+	fake.fetchTaskListArgsForCall = append(fake.fetchTaskListArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 repositories.TaskListMessage
+	}{arg1, arg2, arg3})
+	stub := fake.FetchTaskListStub
+	fakeReturns := fake.fetchTaskListReturns
+	fake.recordInvocation("FetchTaskList", []interface{}{arg1, arg2, arg3})
+	fake.fetchTaskListMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFTaskRepository) FetchTaskListCallCount() int {
+	fake.fetchTaskListMutex.RLock()
+	defer fake.fetchTaskListMutex.RUnlock()
+	return len(fake.fetchTaskListArgsForCall)
+}
+
+func (fake *CFTaskRepository) FetchTaskListCalls(stub func(context.Context, client.Client, repositories.TaskListMessage) ([]repositories.TaskRecord, error)) {
+	fake.fetchTaskListMutex.Lock()
+	defer fake.fetchTaskListMutex.Unlock()
+	fake.FetchTaskListStub = stub
+}
+
+func (fake *CFTaskRepository) FetchTaskListArgsForCall(i int) (context.Context, client.Client, repositories.TaskListMessage) {
+	fake.fetchTaskListMutex.RLock()
+	defer fake.fetchTaskListMutex.RUnlock()
+	argsForCall := fake.fetchTaskListArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFTaskRepository) FetchTaskListReturns(result1 []repositories.TaskRecord, result2 error) {
+	fake.fetchTaskListMutex.Lock()
+	defer fake.fetchTaskListMutex.Unlock()
+	fake.FetchTaskListStub = nil
+	fake.fetchTaskListReturns = struct {
+		result1 []repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) FetchTaskListReturnsOnCall(i int, result1 []repositories.TaskRecord, result2 error) {
+	fake.fetchTaskListMutex.Lock()
+	defer fake.fetchTaskListMutex.Unlock()
+	fake.FetchTaskListStub = nil
+	if fake.fetchTaskListReturnsOnCall == nil {
+		fake.fetchTaskListReturnsOnCall = make(map[int]struct {
+			result1 []repositories.TaskRecord
+			result2 error
+		})
+	}
+	fake.fetchTaskListReturnsOnCall[i] = struct {
+		result1 []repositories.TaskRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFTaskRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cancelTaskMutex.RLock()
+	defer fake.cancelTaskMutex.RUnlock()
+	fake.createTaskMutex.RLock()
+	defer fake.createTaskMutex.RUnlock()
+	fake.fetchTaskMutex.RLock()
+	defer fake.fetchTaskMutex.RUnlock()
+	fake.fetchTaskListMutex.RLock()
+	defer fake.fetchTaskListMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CFTaskRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.CFTaskRepository = new(CFTaskRepository)
@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"net/http"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+)
+
+type FingerprintRepositoryProvider struct {
+	FingerprintRepoForRequestStub        func(*http.Request) (apis.CFFingerprintRepository, error)
+	fingerprintRepoForRequestMutex       sync.RWMutex
+	fingerprintRepoForRequestArgsForCall []struct {
+		arg1 *http.Request
+	}
+	fingerprintRepoForRequestReturns struct {
+		result1 apis.CFFingerprintRepository
+		result2 error
+	}
+	fingerprintRepoForRequestReturnsOnCall map[int]struct {
+		result1 apis.CFFingerprintRepository
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FingerprintRepositoryProvider) FingerprintRepoForRequest(arg1 *http.Request) (apis.CFFingerprintRepository, error) {
+	fake.fingerprintRepoForRequestMutex.Lock()
+	ret, specificReturn := fake.fingerprintRepoForRequestReturnsOnCall[len(fake.fingerprintRepoForRequestArgsForCall)]
+	fake.fingerprintRepoForRequestArgsForCall = append(fake.fingerprintRepoForRequestArgsForCall, struct {
+		arg1 *http.Request
+	}{arg1})
+	stub := fake.FingerprintRepoForRequestStub
+	fakeReturns := fake.fingerprintRepoForRequestReturns
+	fake.recordInvocation("FingerprintRepoForRequest", []interface{}{arg1})
+	fake.fingerprintRepoForRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FingerprintRepositoryProvider) FingerprintRepoForRequestCallCount() int {
+	fake.fingerprintRepoForRequestMutex.RLock()
+	defer fake.fingerprintRepoForRequestMutex.RUnlock()
+	return len(fake.fingerprintRepoForRequestArgsForCall)
+}
+
+func (fake *FingerprintRepositoryProvider) FingerprintRepoForRequestCalls(stub func(*http.Request) (apis.CFFingerprintRepository, error)) {
+	fake.fingerprintRepoForRequestMutex.Lock()
+	defer fake.fingerprintRepoForRequestMutex.Unlock()
+	fake.FingerprintRepoForRequestStub = stub
+}
+
+func (fake *FingerprintRepositoryProvider) FingerprintRepoForRequestArgsForCall(i int) *http.Request {
+	fake.fingerprintRepoForRequestMutex.RLock()
+	defer fake.fingerprintRepoForRequestMutex.RUnlock()
+	argsForCall := fake.fingerprintRepoForRequestArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FingerprintRepositoryProvider) FingerprintRepoForRequestReturns(result1 apis.CFFingerprintRepository, result2 error) {
+	fake.fingerprintRepoForRequestMutex.Lock()
+	defer fake.fingerprintRepoForRequestMutex.Unlock()
+	fake.FingerprintRepoForRequestStub = nil
+	fake.fingerprintRepoForRequestReturns = struct {
+		result1 apis.CFFingerprintRepository
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FingerprintRepositoryProvider) FingerprintRepoForRequestReturnsOnCall(i int, result1 apis.CFFingerprintRepository, result2 error) {
+	fake.fingerprintRepoForRequestMutex.Lock()
+	defer fake.fingerprintRepoForRequestMutex.Unlock()
+	fake.FingerprintRepoForRequestStub = nil
+	if fake.fingerprintRepoForRequestReturnsOnCall == nil {
+		fake.fingerprintRepoForRequestReturnsOnCall = make(map[int]struct {
+			result1 apis.CFFingerprintRepository
+			result2 error
+		})
+	}
+	fake.fingerprintRepoForRequestReturnsOnCall[i] = struct {
+		result1 apis.CFFingerprintRepository
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FingerprintRepositoryProvider) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fingerprintRepoForRequestMutex.RLock()
+	defer fake.fingerprintRepoForRequestMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FingerprintRepositoryProvider) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.FingerprintRepositoryProvider = new(FingerprintRepositoryProvider)
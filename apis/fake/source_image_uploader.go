@@ -2,7 +2,7 @@
 package fake
 
 import (
-	"mime/multipart"
+	"io"
 	"sync"
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
@@ -10,11 +10,11 @@ import (
 )
 
 type SourceImageUploader struct {
-	Stub        func(string, multipart.File, remote.Option) (string, error)
+	Stub        func(string, io.Reader, remote.Option) (string, error)
 	mutex       sync.RWMutex
 	argsForCall []struct {
 		arg1 string
-		arg2 multipart.File
+		arg2 io.Reader
 		arg3 remote.Option
 	}
 	returns struct {
@@ -29,12 +29,12 @@ type SourceImageUploader struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *SourceImageUploader) Spy(arg1 string, arg2 multipart.File, arg3 remote.Option) (string, error) {
+func (fake *SourceImageUploader) Spy(arg1 string, arg2 io.Reader, arg3 remote.Option) (string, error) {
 	fake.mutex.Lock()
 	ret, specificReturn := fake.returnsOnCall[len(fake.argsForCall)]
 	fake.argsForCall = append(fake.argsForCall, struct {
 		arg1 string
-		arg2 multipart.File
+		arg2 io.Reader
 		arg3 remote.Option
 	}{arg1, arg2, arg3})
 	stub := fake.Stub
@@ -56,13 +56,13 @@ func (fake *SourceImageUploader) CallCount() int {
 	return len(fake.argsForCall)
 }
 
-func (fake *SourceImageUploader) Calls(stub func(string, multipart.File, remote.Option) (string, error)) {
+func (fake *SourceImageUploader) Calls(stub func(string, io.Reader, remote.Option) (string, error)) {
 	fake.mutex.Lock()
 	defer fake.mutex.Unlock()
 	fake.Stub = stub
 }
 
-func (fake *SourceImageUploader) ArgsForCall(i int) (string, multipart.File, remote.Option) {
+func (fake *SourceImageUploader) ArgsForCall(i int) (string, io.Reader, remote.Option) {
 	fake.mutex.RLock()
 	defer fake.mutex.RUnlock()
 	return fake.argsForCall[i].arg1, fake.argsForCall[i].arg2, fake.argsForCall[i].arg3
@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"net/http"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+)
+
+type SpaceRepositoryProvider struct {
+	SpaceRepoForRequestStub        func(*http.Request) (apis.CFSpaceRepository, error)
+	spaceRepoForRequestMutex       sync.RWMutex
+	spaceRepoForRequestArgsForCall []struct {
+		arg1 *http.Request
+	}
+	spaceRepoForRequestReturns struct {
+		result1 apis.CFSpaceRepository
+		result2 error
+	}
+	spaceRepoForRequestReturnsOnCall map[int]struct {
+		result1 apis.CFSpaceRepository
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *SpaceRepositoryProvider) SpaceRepoForRequest(arg1 *http.Request) (apis.CFSpaceRepository, error) {
+	fake.spaceRepoForRequestMutex.Lock()
+	ret, specificReturn := fake.spaceRepoForRequestReturnsOnCall[len(fake.spaceRepoForRequestArgsForCall)]
+	fake.spaceRepoForRequestArgsForCall = append(fake.spaceRepoForRequestArgsForCall, struct {
+		arg1 *http.Request
+	}{arg1})
+	stub := fake.SpaceRepoForRequestStub
+	fakeReturns := fake.spaceRepoForRequestReturns
+	fake.recordInvocation("SpaceRepoForRequest", []interface{}{arg1})
+	fake.spaceRepoForRequestMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *SpaceRepositoryProvider) SpaceRepoForRequestCallCount() int {
+	fake.spaceRepoForRequestMutex.RLock()
+	defer fake.spaceRepoForRequestMutex.RUnlock()
+	return len(fake.spaceRepoForRequestArgsForCall)
+}
+
+func (fake *SpaceRepositoryProvider) SpaceRepoForRequestCalls(stub func(*http.Request) (apis.CFSpaceRepository, error)) {
+	fake.spaceRepoForRequestMutex.Lock()
+	defer fake.spaceRepoForRequestMutex.Unlock()
+	fake.SpaceRepoForRequestStub = stub
+}
+
+func (fake *SpaceRepositoryProvider) SpaceRepoForRequestArgsForCall(i int) *http.Request {
+	fake.spaceRepoForRequestMutex.RLock()
+	defer fake.spaceRepoForRequestMutex.RUnlock()
+	argsForCall := fake.spaceRepoForRequestArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *SpaceRepositoryProvider) SpaceRepoForRequestReturns(result1 apis.CFSpaceRepository, result2 error) {
+	fake.spaceRepoForRequestMutex.Lock()
+	defer fake.spaceRepoForRequestMutex.Unlock()
+	fake.SpaceRepoForRequestStub = nil
+	fake.spaceRepoForRequestReturns = struct {
+		result1 apis.CFSpaceRepository
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *SpaceRepositoryProvider) SpaceRepoForRequestReturnsOnCall(i int, result1 apis.CFSpaceRepository, result2 error) {
+	fake.spaceRepoForRequestMutex.Lock()
+	defer fake.spaceRepoForRequestMutex.Unlock()
+	fake.SpaceRepoForRequestStub = nil
+	if fake.spaceRepoForRequestReturnsOnCall == nil {
+		fake.spaceRepoForRequestReturnsOnCall = make(map[int]struct {
+			result1 apis.CFSpaceRepository
+			result2 error
+		})
+	}
+	fake.spaceRepoForRequestReturnsOnCall[i] = struct {
+		result1 apis.CFSpaceRepository
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *SpaceRepositoryProvider) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.spaceRepoForRequestMutex.RLock()
+	defer fake.spaceRepoForRequestMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *SpaceRepositoryProvider) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ apis.SpaceRepositoryProvider = new(SpaceRepositoryProvider)
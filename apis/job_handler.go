@@ -0,0 +1,88 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"github.com/go-http-utils/headers"
+	"github.com/go-logr/logr"
+	"github.com/gorilla/mux"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	JobGetEndpoint = "/v3/jobs/{guid}"
+)
+
+//counterfeiter:generate -o fake -fake-name CFJobRepository . CFJobRepository
+type CFJobRepository interface {
+	FetchJob(context.Context, client.Client, string) (repositories.JobRecord, error)
+	RunJob(context.Context, string, string, repositories.JobTask) (repositories.JobRecord, error)
+}
+
+type JobHandler struct {
+	logger      logr.Logger
+	serverURL   url.URL
+	jobRepo     CFJobRepository
+	buildClient ClientBuilder
+	k8sConfig   *rest.Config
+}
+
+func NewJobHandler(
+	logger logr.Logger,
+	serverURL url.URL,
+	jobRepo CFJobRepository,
+	buildClient ClientBuilder,
+	k8sConfig *rest.Config) *JobHandler {
+	return &JobHandler{
+		logger:      logger,
+		serverURL:   serverURL,
+		jobRepo:     jobRepo,
+		buildClient: buildClient,
+		k8sConfig:   k8sConfig,
+	}
+}
+
+func (h *JobHandler) jobGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	jobGUID := mux.Vars(r)["guid"]
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "JobGUID", jobGUID)
+		return
+	}
+
+	job, err := h.jobRepo.FetchJob(r.Context(), client, jobGUID)
+	if err != nil {
+		switch err.(type) {
+		case repositories.NotFoundError:
+			h.logger.Info("Job not found", "JobGUID", jobGUID)
+			writeNotFoundErrorResponse(w, "Job")
+			return
+		default:
+			h.logger.Error(err, "Failed to fetch job", "JobGUID", jobGUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+	}
+
+	responseBody, err := json.Marshal(presenter.ForJob(job, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "JobGUID", jobGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *JobHandler) RegisterRoutes(router *mux.Router) {
+	router.Path(JobGetEndpoint).Methods("GET").HandlerFunc(h.jobGetHandler)
+}
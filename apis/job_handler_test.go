@@ -0,0 +1,171 @@
+package apis_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+
+	"github.com/go-http-utils/headers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("JobHandler", func() {
+	const (
+		jobGUID = "test-job-guid"
+	)
+
+	var (
+		jobRepo       *fake.CFJobRepository
+		clientBuilder *fake.ClientBuilder
+	)
+
+	BeforeEach(func() {
+		jobRepo = new(fake.CFJobRepository)
+		clientBuilder = new(fake.ClientBuilder)
+
+		jobHandler := NewJobHandler(
+			logf.Log.WithName("TestJobHandler"),
+			*serverURL,
+			jobRepo,
+			clientBuilder.Spy,
+			&rest.Config{},
+		)
+		jobHandler.RegisterRoutes(router)
+
+		var err error
+		req, err = http.NewRequest("GET", "/v3/jobs/"+jobGUID, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Add(headers.Authorization, authHeader)
+	})
+
+	JustBeforeEach(func() {
+		router.ServeHTTP(rr, req)
+	})
+
+	Describe("the GET /v3/jobs/:guid endpoint", func() {
+		When("the job is processing", func() {
+			BeforeEach(func() {
+				jobRepo.FetchJobReturns(repositories.JobRecord{
+					GUID:      jobGUID,
+					Operation: repositories.AppDeleteJobOperation,
+					State:     repositories.JobStateProcessing,
+					CreatedAt: "2021-10-18T13:12:00Z",
+					UpdatedAt: "2021-10-18T13:12:00Z",
+				}, nil)
+			})
+
+			It("fetches the job by its guid as the user of the request", func() {
+				Expect(clientBuilder.CallCount()).To(Equal(1))
+				_, actualAuthHeader := clientBuilder.ArgsForCall(0)
+				Expect(actualAuthHeader).To(Equal(authHeader))
+
+				Expect(jobRepo.FetchJobCallCount()).To(Equal(1))
+				_, _, guid := jobRepo.FetchJobArgsForCall(0)
+				Expect(guid).To(Equal(jobGUID))
+			})
+
+			It("returns the job", func() {
+				expectJSONResponse(http.StatusOK, `{
+					"guid": "test-job-guid",
+					"created_at": "2021-10-18T13:12:00Z",
+					"updated_at": "2021-10-18T13:12:00Z",
+					"operation": "app.delete",
+					"state": "PROCESSING",
+					"errors": [],
+					"warnings": [],
+					"links": {
+						"self": {
+							"href": "`+defaultServerURL+`/v3/jobs/test-job-guid"
+						}
+					}
+				}`)
+			})
+		})
+
+		When("the job has failed", func() {
+			BeforeEach(func() {
+				jobRepo.FetchJobReturns(repositories.JobRecord{
+					GUID:      jobGUID,
+					Operation: repositories.AppDeleteJobOperation,
+					State:     repositories.JobStateFailed,
+					Errors: []repositories.JobError{
+						{Detail: "boom"},
+						{Title: "CF-ResourceNotFound", Detail: "App not found", Code: 10010},
+					},
+					CreatedAt: "2021-10-18T13:12:00Z",
+					UpdatedAt: "2021-10-18T13:12:05Z",
+				}, nil)
+			})
+
+			It("returns the job with its errors", func() {
+				expectJSONResponse(http.StatusOK, `{
+					"guid": "test-job-guid",
+					"created_at": "2021-10-18T13:12:00Z",
+					"updated_at": "2021-10-18T13:12:05Z",
+					"operation": "app.delete",
+					"state": "FAILED",
+					"errors": [
+						{
+							"detail": "boom",
+							"title": "UnknownError",
+							"code": 10001
+						},
+						{
+							"detail": "App not found",
+							"title": "CF-ResourceNotFound",
+							"code": 10010
+						}
+					],
+					"warnings": [],
+					"links": {
+						"self": {
+							"href": "`+defaultServerURL+`/v3/jobs/test-job-guid"
+						}
+					}
+				}`)
+			})
+		})
+
+		When("the job does not exist", func() {
+			BeforeEach(func() {
+				jobRepo.FetchJobReturns(repositories.JobRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Job not found")
+			})
+		})
+
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+
+			It("doesn't fetch the job", func() {
+				Expect(jobRepo.FetchJobCallCount()).To(Equal(0))
+			})
+		})
+
+		When("fetching the job errors", func() {
+			BeforeEach(func() {
+				jobRepo.FetchJobReturns(repositories.JobRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 5000
+)
+
+// parsePageRequest reads the page and per_page query parameters of a list request
+func parsePageRequest(r *http.Request) (repositories.PageRequest, error) {
+	pageRequest := repositories.PageRequest{Page: 1, PerPage: defaultPerPage}
+	query := r.URL.Query()
+
+	if _, ok := query["page"]; ok {
+		page, err := strconv.Atoi(query.Get("page"))
+		if err != nil || page < 1 {
+			return repositories.PageRequest{}, errors.New("Page must be a positive integer")
+		}
+		pageRequest.Page = page
+	}
+
+	if _, ok := query["per_page"]; ok {
+		perPage, err := strconv.Atoi(query.Get("per_page"))
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return repositories.PageRequest{}, errors.New("Per page must be between 1 and 5000")
+		}
+		pageRequest.PerPage = perPage
+	}
+
+	return pageRequest, nil
+}
+
+// checkQueryParameters rejects list requests with query parameters the endpoint does not support.
+// Every list endpoint supports the pagination parameters and label_selector.
+func checkQueryParameters(r *http.Request, supported ...string) error {
+	supported = append([]string{"page", "per_page", "label_selector"}, supported...)
+	supportedMap := map[string]struct{}{}
+	for _, key := range supported {
+		supportedMap[key] = struct{}{}
+	}
+
+	var unknown []string
+	for key := range r.URL.Query() {
+		if _, ok := supportedMap[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return fmt.Errorf("Unknown query parameter(s): '%s'. Valid parameters are: '%s'", strings.Join(unknown, "', '"), strings.Join(supported, "', '"))
+}
+
+// parseOrderBy reads the order_by query parameter, which names one of the orderable fields, optionally prefixed
+// with "+" for ascending or "-" for descending order. The result is normalized to have no "+" prefix.
+func parseOrderBy(r *http.Request, orderable ...string) (string, error) {
+	orderBy := strings.TrimPrefix(r.URL.Query().Get("order_by"), "+")
+	if orderBy == "" {
+		return "", nil
+	}
+
+	field := strings.TrimPrefix(orderBy, "-")
+	for _, orderableField := range orderable {
+		if field == orderableField {
+			return orderBy, nil
+		}
+	}
+
+	return "", fmt.Errorf("Order by can only be: '%s'", strings.Join(orderable, "', '"))
+}
+
+// parseLabelSelector reads the label_selector query parameter. CF label selectors have the same syntax as Kubernetes
+// label selectors: equality (=, == and !=), set based (in and notin) and existence (key and !key) requirements.
+// The result is nil when no selector is given.
+func parseLabelSelector(r *http.Request) (labels.Selector, error) {
+	query := r.URL.Query()
+	if _, ok := query["label_selector"]; !ok {
+		return nil, nil
+	}
+
+	selector, err := labels.Parse(query.Get("label_selector"))
+	if err != nil {
+		return nil, errors.New("Invalid label_selector value")
+	}
+	return selector, nil
+}
+
+func parseCommaSeparatedList(list string) []string {
+	var elements []string
+	for _, element := range strings.Split(list, ",") {
+		if element != "" {
+			elements = append(elements, element)
+		}
+	}
+
+	return elements
+}
+
+// matchesFilter reports whether value is one of the values of a list filter. An empty filter matches every value.
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	for _, filterValue := range filter {
+		if filterValue == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newListPage(r *http.Request, pageRequest repositories.PageRequest, totalResults int) presenter.ListPage {
+	return presenter.ListPage{
+		Page:         pageRequest.Page,
+		PerPage:      pageRequest.PerPage,
+		TotalResults: totalResults,
+		Query:        r.URL.Query(),
+	}
+}
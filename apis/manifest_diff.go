@@ -0,0 +1,239 @@
+package apis
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// jsonPointerEscaper escapes a key, such as the name of an environment variable, for a JSON Pointer path
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+type manifestDiff struct {
+	entries []presenter.ManifestDiffEntry
+}
+
+func (d *manifestDiff) add(path string, value interface{}) {
+	d.entries = append(d.entries, presenter.ManifestDiffEntry{Op: "add", Path: path, Value: value})
+}
+
+func (d *manifestDiff) replace(path string, was, value interface{}) {
+	d.entries = append(d.entries, presenter.ManifestDiffEntry{Op: "replace", Path: path, Was: was, Value: value})
+}
+
+// diffManifest returns the changes that applying manifest would make to the apps in current, which holds the
+// manifests of the existing apps of the space by name. Applying a manifest only adds and updates, so fields that the
+// manifest leaves out are not compared and the diff has no remove operations.
+func diffManifest(manifest payloads.Manifest, current map[string]presenter.ManifestApplication) []presenter.ManifestDiffEntry {
+	diff := &manifestDiff{entries: []presenter.ManifestDiffEntry{}}
+
+	for i, manifestApp := range manifest.Applications {
+		path := fmt.Sprintf("/applications/%d", i)
+		currentApp, ok := current[manifestApp.Name]
+		if !ok {
+			diff.add(path, presentManifestApplication(manifestApp))
+			continue
+		}
+
+		if manifestApp.Stack != nil && *manifestApp.Stack != currentApp.Stack {
+			diff.addOrReplace(path+"/stack", currentApp.Stack, *manifestApp.Stack)
+		}
+		if manifestApp.Buildpacks != nil && !sameBuildpacks(currentApp.Buildpacks, manifestApp.Buildpacks) {
+			diff.addOrReplace(path+"/buildpacks", currentApp.Buildpacks, manifestApp.Buildpacks)
+		}
+
+		envKeys := make([]string, 0, len(manifestApp.Env))
+		for key := range manifestApp.Env {
+			envKeys = append(envKeys, key)
+		}
+		sort.Strings(envKeys)
+		for _, key := range envKeys {
+			envPath := path + "/env/" + jsonPointerEscaper.Replace(key)
+			was, ok := currentApp.Env[key]
+			if !ok {
+				diff.add(envPath, manifestApp.Env[key])
+			} else if was != manifestApp.Env[key] {
+				diff.replace(envPath, was, manifestApp.Env[key])
+			}
+		}
+
+		// the process fields of the application configure its web process
+		diff.fields(path, presentManifestProcess(manifestApplicationWebFields(manifestApp)), findManifestProcess(currentApp.Processes, "web"))
+
+		for j, process := range manifestApp.Processes {
+			processPath := fmt.Sprintf("%s/processes/%d", path, j)
+			currentProcess := findManifestProcess(currentApp.Processes, process.Type)
+			if currentProcess == nil {
+				diff.add(processPath, presentManifestProcess(process))
+				continue
+			}
+			diff.fields(processPath, presentManifestProcess(process), currentProcess)
+		}
+
+		for j, route := range manifestApp.Routes {
+			if !hasManifestRoute(currentApp.Routes, route.Route) {
+				diff.add(fmt.Sprintf("%s/routes/%d", path, j), presenter.ManifestRoute{Route: route.Route})
+			}
+		}
+	}
+
+	return diff.entries
+}
+
+// addOrReplace adds value when was is empty, and replaces was with it otherwise
+func (d *manifestDiff) addOrReplace(path string, was, value interface{}) {
+	if reflect.ValueOf(was).IsZero() {
+		d.add(path, value)
+		return
+	}
+	d.replace(path, was, value)
+}
+
+func sameBuildpacks(current, submitted []string) bool {
+	return len(current) == len(submitted) && (len(current) == 0 || reflect.DeepEqual(current, submitted))
+}
+
+// fields compares the fields that submitted sets with those of current, which may be nil. The type of processes
+// identifies them, so it is not compared.
+func (d *manifestDiff) fields(path string, submitted, current interface{}) {
+	keys, values := manifestFields(submitted)
+	_, currentValues := manifestFields(current)
+	for _, key := range keys {
+		if key == "type" {
+			continue
+		}
+		was, ok := currentValues[key]
+		if !ok {
+			d.add(path+"/"+key, values[key])
+		} else if !sameManifestValue(key, was, values[key]) {
+			d.replace(path+"/"+key, was, values[key])
+		}
+	}
+}
+
+// manifestFields returns the YAML keys that v, a part of a presented manifest, sets, in order, and their values
+func manifestFields(v interface{}) ([]string, map[string]interface{}) {
+	values := map[string]interface{}{}
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return nil, values
+	}
+
+	var node yaml.Node
+	if err := node.Encode(v); err != nil { // untested, as presented manifests always encode
+		return nil, values
+	}
+
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value interface{}
+		if err := node.Content[i+1].Decode(&value); err != nil { // untested
+			continue
+		}
+		keys = append(keys, node.Content[i].Value)
+		values[node.Content[i].Value] = value
+	}
+	return keys, values
+}
+
+// sameManifestValue compares the values of a field of a manifest, where memory and disk may use different units and
+// the "none" health check type is the "process" type
+func sameManifestValue(key string, was, value interface{}) bool {
+	switch key {
+	case "memory", "disk_quota":
+		wasMB, wasErr := payloads.ParseMegabytes(fmt.Sprint(was))
+		valueMB, valueErr := payloads.ParseMegabytes(fmt.Sprint(value))
+		return wasErr == nil && valueErr == nil && wasMB == valueMB
+	case "health-check-type":
+		return healthCheckTypeOf(fmt.Sprint(was)) == healthCheckTypeOf(fmt.Sprint(value))
+	default:
+		return reflect.DeepEqual(was, value)
+	}
+}
+
+func healthCheckTypeOf(manifestType string) string {
+	if manifestType == "none" {
+		return "process"
+	}
+	return manifestType
+}
+
+func manifestApplicationWebFields(manifestApp payloads.ManifestApplication) payloads.ManifestApplicationProcess {
+	return payloads.ManifestApplicationProcess{
+		Command:                      manifestApp.Command,
+		DiskQuota:                    manifestApp.DiskQuota,
+		HealthCheckHTTPEndpoint:      manifestApp.HealthCheckHTTPEndpoint,
+		HealthCheckInvocationTimeout: manifestApp.HealthCheckInvocationTimeout,
+		HealthCheckType:              manifestApp.HealthCheckType,
+		Instances:                    manifestApp.Instances,
+		Memory:                       manifestApp.Memory,
+		Timeout:                      manifestApp.Timeout,
+	}
+}
+
+func presentManifestApplication(manifestApp payloads.ManifestApplication) presenter.ManifestApplication {
+	presented := presenter.ManifestApplication{
+		Name:       manifestApp.Name,
+		Stack:      stringOrEmpty(manifestApp.Stack),
+		Buildpacks: manifestApp.Buildpacks,
+		Env:        manifestApp.Env,
+	}
+	for _, process := range manifestApp.AllProcesses() {
+		presented.Processes = append(presented.Processes, *presentManifestProcess(process))
+	}
+	for _, route := range manifestApp.Routes {
+		presented.Routes = append(presented.Routes, presenter.ManifestRoute{Route: route.Route})
+	}
+	return presented
+}
+
+func presentManifestProcess(process payloads.ManifestApplicationProcess) *presenter.ManifestApplicationProcess {
+	return &presenter.ManifestApplicationProcess{
+		Type:                         process.Type,
+		Instances:                    process.Instances,
+		Memory:                       stringOrEmpty(process.Memory),
+		DiskQuota:                    stringOrEmpty(process.DiskQuota),
+		Command:                      stringOrEmpty(process.Command),
+		HealthCheckType:              stringOrEmpty(process.HealthCheckType),
+		HealthCheckHTTPEndpoint:      stringOrEmpty(process.HealthCheckHTTPEndpoint),
+		Timeout:                      int64OrZero(process.Timeout),
+		HealthCheckInvocationTimeout: int64OrZero(process.HealthCheckInvocationTimeout),
+	}
+}
+
+func findManifestProcess(processes []presenter.ManifestApplicationProcess, processType string) *presenter.ManifestApplicationProcess {
+	for i := range processes {
+		if processes[i].Type == processType {
+			return &processes[i]
+		}
+	}
+	return nil
+}
+
+func hasManifestRoute(routes []presenter.ManifestRoute, route string) bool {
+	for _, currentRoute := range routes {
+		if currentRoute.Route == strings.TrimSuffix(route, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func int64OrZero(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
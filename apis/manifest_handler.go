@@ -0,0 +1,577 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"github.com/go-http-utils/headers"
+	"github.com/go-logr/logr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	SpaceApplyManifestEndpoint = "/v3/spaces/{guid}/actions/apply_manifest"
+	SpaceManifestDiffEndpoint  = "/v3/spaces/{guid}/manifest_diff"
+	AppManifestEndpoint        = "/v3/apps/{guid}/manifest"
+
+	// sidecarsNotSupportedDetail rejects manifests with sidecars, which the workload controllers can't run
+	sidecarsNotSupportedDetail = "Sidecars are not supported"
+)
+
+type ManifestHandler struct {
+	logger       logr.Logger
+	serverURL    url.URL
+	appRepo      CFAppRepository
+	processRepo  CFProcessRepository
+	routeRepo    CFRouteRepository
+	domainRepo   CFDomainRepository
+	revisionRepo CFRevisionRepository
+	jobRepo      CFJobRepository
+	buildClient  ClientBuilder
+	k8sConfig    *rest.Config
+}
+
+func NewManifestHandler(
+	logger logr.Logger,
+	serverURL url.URL,
+	appRepo CFAppRepository,
+	processRepo CFProcessRepository,
+	routeRepo CFRouteRepository,
+	domainRepo CFDomainRepository,
+	revisionRepo CFRevisionRepository,
+	jobRepo CFJobRepository,
+	buildClient ClientBuilder,
+	k8sConfig *rest.Config) *ManifestHandler {
+	return &ManifestHandler{
+		logger:       logger,
+		serverURL:    serverURL,
+		appRepo:      appRepo,
+		processRepo:  processRepo,
+		routeRepo:    routeRepo,
+		domainRepo:   domainRepo,
+		revisionRepo: revisionRepo,
+		jobRepo:      jobRepo,
+		buildClient:  buildClient,
+		k8sConfig:    k8sConfig,
+	}
+}
+
+func (h *ManifestHandler) applyManifestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	spaceGUID := mux.Vars(r)["guid"]
+
+	var manifest payloads.Manifest
+	rme := decodeAndValidateManifest(r, &manifest)
+	if rme != nil {
+		h.logger.Info("Invalid manifest", "SpaceGUID", spaceGUID)
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := h.appRepo.FetchNamespace(ctx, client, spaceGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.PermissionDeniedOrNotFoundError)) {
+			h.logger.Info("Namespace not found", "SpaceGUID", spaceGUID)
+			writeNotFoundErrorResponse(w, "Space")
+			return
+		}
+		h.logger.Error(err, "Failed to fetch namespace from Kubernetes", "SpaceGUID", spaceGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	job, err := h.jobRepo.RunJob(ctx, repositories.SpaceApplyManifestJobOperation, spaceGUID, func(ctx context.Context) error {
+		for _, manifestApp := range manifest.Applications {
+			err := h.applyApplication(ctx, client, spaceGUID, manifestApp)
+			if err != nil {
+				h.logger.Error(err, "Error applying manifest", "SpaceGUID", spaceGUID, "App Name", manifestApp.Name)
+				presentedErr := newUnprocessableEntityError(fmt.Sprintf("For application '%s': %s", manifestApp.Name, err)).Errors[0]
+				return repositories.JobError{Title: presentedErr.Title, Detail: presentedErr.Detail, Code: presentedErr.Code}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.Error(err, "Error starting apply manifest job", "SpaceGUID", spaceGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Header().Set("Location", presenter.JobURL(job.GUID, h.serverURL))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// applyApplication creates the app of a manifest, or updates the app of the same name in the space. Environment
+// variables, processes and routes that the manifest leaves out are kept.
+func (h *ManifestHandler) applyApplication(ctx context.Context, client client.Client, spaceGUID string, manifestApp payloads.ManifestApplication) error {
+	app, err := h.fetchOrCreateApp(ctx, client, spaceGUID, manifestApp)
+	if err != nil {
+		return err
+	}
+
+	if len(manifestApp.Env) > 0 {
+		_, err = h.appRepo.PatchAppEnvVars(ctx, client, manifestApp.ToEnvVarsMessage(app))
+		if err != nil {
+			return fmt.Errorf("error setting environment variables: %w", err)
+		}
+	}
+
+	err = h.applyProcesses(ctx, client, app, manifestApp.AllProcesses())
+	if err != nil {
+		return err
+	}
+
+	for _, route := range manifestApp.Routes {
+		err = h.applyRoute(ctx, client, app, route.Route)
+		if err != nil {
+			return err
+		}
+	}
+
+	recordRevision(ctx, h.logger, h.revisionRepo, client, app.GUID, app.SpaceGUID)
+	return nil
+}
+
+func (h *ManifestHandler) fetchOrCreateApp(ctx context.Context, client client.Client, spaceGUID string, manifestApp payloads.ManifestApplication) (repositories.AppRecord, error) {
+	apps, _, err := h.appRepo.FetchAppList(ctx, client, repositories.AppListMessage{
+		Names:      []string{manifestApp.Name},
+		SpaceGUIDs: []string{spaceGUID},
+	})
+	if err != nil {
+		return repositories.AppRecord{}, fmt.Errorf("error fetching app: %w", err)
+	}
+	if len(apps) > 0 {
+		if !manifestApp.HasLifecycle() {
+			return apps[0], nil
+		}
+		app, err := h.appRepo.PatchApp(ctx, client, manifestApp.ToAppPatchMessage(apps[0]))
+		if err != nil {
+			return repositories.AppRecord{}, fmt.Errorf("error updating app: %w", err)
+		}
+		return app, nil
+	}
+
+	appRecord := manifestApp.ToAppRecord(spaceGUID)
+	appRecord.GUID = uuid.NewString()
+	app, err := h.appRepo.CreateApp(ctx, client, appRecord)
+	if err != nil {
+		return repositories.AppRecord{}, fmt.Errorf("error creating app: %w", err)
+	}
+	return app, nil
+}
+
+// applyProcesses scales and patches the processes of app, and creates those that its droplet does not have yet
+func (h *ManifestHandler) applyProcesses(ctx context.Context, client client.Client, app repositories.AppRecord, manifestProcesses []payloads.ManifestApplicationProcess) error {
+	if len(manifestProcesses) == 0 {
+		return nil
+	}
+
+	processes, err := h.processRepo.FetchProcessesForApp(ctx, client, app.GUID, app.SpaceGUID, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching processes: %w", err)
+	}
+	processGUIDs := map[string]string{}
+	for _, process := range processes {
+		processGUIDs[process.Type] = process.GUID
+	}
+
+	for _, manifestProcess := range manifestProcesses {
+		processGUID, ok := processGUIDs[manifestProcess.Type]
+		if !ok {
+			_, err = h.processRepo.CreateProcess(ctx, client, manifestProcess.ToProcessCreateMessage(app.GUID, app.SpaceGUID))
+			if err != nil {
+				return fmt.Errorf("Process %q: %w", manifestProcess.Type, err)
+			}
+			continue
+		}
+
+		if manifestProcess.HasScale() {
+			_, err = h.processRepo.ScaleProcess(ctx, client, manifestProcess.ToProcessScaleMessage(processGUID, app.SpaceGUID))
+			if err != nil {
+				return fmt.Errorf("Process %q: %w", manifestProcess.Type, err)
+			}
+		}
+		if manifestProcess.HasPatch() {
+			_, err = h.processRepo.PatchProcess(ctx, client, manifestProcess.ToProcessPatchMessage(processGUID, app.SpaceGUID))
+			if err != nil {
+				return fmt.Errorf("Process %q: %w", manifestProcess.Type, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyRoute maps a route of the manifest, such as "my-app.apps.example.org/path", to the web process of app. The
+// route is created in the space of the app when it does not exist. A route that is already mapped to a process of app
+// is left as it is, so that routes of other process types, as in the manifests of apps, are not mapped to web as well.
+func (h *ManifestHandler) applyRoute(ctx context.Context, client client.Client, app repositories.AppRecord, route string) error {
+	routeURL, err := url.Parse("//" + route)
+	if err != nil { // untested, as the manifest has been validated
+		return err
+	}
+	path := strings.TrimSuffix(routeURL.Path, "/")
+
+	host, domain, err := h.findDomain(ctx, client, routeURL.Hostname())
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			return fmt.Errorf("The route '%s' did not match any existing domains.", route)
+		}
+		return fmt.Errorf("error fetching domain of route '%s': %w", route, err)
+	}
+
+	routes, _, err := h.routeRepo.FetchRouteList(ctx, client, repositories.RouteListMessage{
+		Hosts:       []string{host},
+		Paths:       []string{path},
+		DomainGUIDs: []string{domain.GUID},
+		SpaceGUIDs:  []string{app.SpaceGUID},
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching route '%s': %w", route, err)
+	}
+
+	var routeRecord repositories.RouteRecord
+	if len(routes) > 0 {
+		routeRecord = routes[0]
+		for _, destination := range routeRecord.Destinations {
+			if destination.AppGUID == app.GUID {
+				return nil
+			}
+		}
+	} else {
+		routeRecord, err = h.routeRepo.CreateRoute(ctx, client, repositories.RouteRecord{
+			GUID:      uuid.NewString(),
+			SpaceGUID: app.SpaceGUID,
+			DomainRef: domain,
+			Host:      host,
+			Path:      path,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating route '%s': %w", route, err)
+		}
+	}
+
+	_, err = h.routeRepo.AddDestinationsToRoute(ctx, client, repositories.RouteAddDestinationsMessage{
+		RouteGUID:       routeRecord.GUID,
+		SpaceGUID:       app.SpaceGUID,
+		NewDestinations: []repositories.DestinationMessage{{AppGUID: app.GUID, ProcessType: "web"}},
+	})
+	if err != nil {
+		return fmt.Errorf("error mapping route '%s': %w", route, err)
+	}
+	return nil
+}
+
+// findDomain splits hostname into the host and domain of a route. As in the CF API, the whole hostname is tried as
+// a domain, with an empty host, before its first label is taken as the host.
+func (h *ManifestHandler) findDomain(ctx context.Context, client client.Client, hostname string) (string, repositories.DomainRecord, error) {
+	domain, err := h.domainRepo.FetchDomainByName(ctx, client, hostname)
+	if err == nil || !errors.As(err, new(repositories.NotFoundError)) {
+		return "", domain, err
+	}
+
+	parts := strings.SplitN(hostname, ".", 2)
+	if len(parts) < 2 {
+		return "", repositories.DomainRecord{}, err
+	}
+	domain, err = h.domainRepo.FetchDomainByName(ctx, client, parts[1])
+	return parts[0], domain, err
+}
+
+func (h *ManifestHandler) manifestDiffHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	spaceGUID := mux.Vars(r)["guid"]
+
+	var manifest payloads.Manifest
+	rme := decodeAndValidateManifest(r, &manifest)
+	if rme != nil {
+		h.logger.Info("Invalid manifest", "SpaceGUID", spaceGUID)
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := h.appRepo.FetchNamespace(ctx, client, spaceGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.PermissionDeniedOrNotFoundError)) {
+			h.logger.Info("Namespace not found", "SpaceGUID", spaceGUID)
+			writeNotFoundErrorResponse(w, "Space")
+			return
+		}
+		h.logger.Error(err, "Failed to fetch namespace from Kubernetes", "SpaceGUID", spaceGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	names := make([]string, 0, len(manifest.Applications))
+	for _, manifestApp := range manifest.Applications {
+		names = append(names, manifestApp.Name)
+	}
+	apps, _, err := h.appRepo.FetchAppList(ctx, client, repositories.AppListMessage{
+		Names:      names,
+		SpaceGUIDs: []string{spaceGUID},
+	})
+	if err != nil {
+		h.logger.Error(err, "Failed to fetch apps from Kubernetes", "SpaceGUID", spaceGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	current := map[string]presenter.ManifestApplication{}
+	for _, app := range apps {
+		current[app.Name], err = h.presentApp(ctx, client, app)
+		if err != nil {
+			h.logger.Error(err, "Failed to fetch configuration of app", "AppGUID", app.GUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+	}
+
+	responseBody, err := json.Marshal(presenter.ManifestDiffResponse{Diff: diffManifest(manifest, current)})
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "SpaceGUID", spaceGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(responseBody)
+}
+
+func (h *ManifestHandler) appManifestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	appGUID := mux.Vars(r)["guid"]
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	app, err := h.appRepo.FetchApp(ctx, client, appGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("App not found", "AppGUID", appGUID)
+			w.Header().Set("Content-Type", "application/json")
+			writeNotFoundErrorResponse(w, "App")
+			return
+		}
+		h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
+		w.Header().Set("Content-Type", "application/json")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	manifestApp, err := h.presentApp(ctx, client, app)
+	if err != nil {
+		h.logger.Error(err, "Failed to fetch configuration of app", "AppGUID", appGUID)
+		w.Header().Set("Content-Type", "application/json")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	responseBody, err := yaml.Marshal(presenter.ManifestResponse{Applications: []presenter.ManifestApplication{manifestApp}})
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "AppGUID", appGUID)
+		w.Header().Set("Content-Type", "application/json")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-yaml")
+	w.Write(append([]byte("---\n"), responseBody...))
+}
+
+// presentApp renders the live configuration of app as an application of a manifest
+func (h *ManifestHandler) presentApp(ctx context.Context, client client.Client, app repositories.AppRecord) (presenter.ManifestApplication, error) {
+	envVars, err := h.appRepo.FetchAppEnvVars(ctx, client, app)
+	if err != nil {
+		return presenter.ManifestApplication{}, fmt.Errorf("error fetching environment variables: %w", err)
+	}
+
+	processes, err := h.processRepo.FetchProcessesForApp(ctx, client, app.GUID, app.SpaceGUID, nil)
+	if err != nil {
+		return presenter.ManifestApplication{}, fmt.Errorf("error fetching processes: %w", err)
+	}
+
+	routes, err := h.routeRepo.FetchRoutesForApp(ctx, client, app.GUID, app.SpaceGUID, nil)
+	if err != nil {
+		return presenter.ManifestApplication{}, fmt.Errorf("error fetching routes: %w", err)
+	}
+	domains := map[string]repositories.DomainRecord{}
+	for i, route := range routes {
+		domain, ok := domains[route.DomainRef.GUID]
+		if !ok {
+			domain, err = h.domainRepo.FetchDomain(ctx, client, route.DomainRef.GUID)
+			if err != nil {
+				return presenter.ManifestApplication{}, fmt.Errorf("error fetching domain of route %q: %w", route.GUID, err)
+			}
+			domains[route.DomainRef.GUID] = domain
+		}
+		routes[i] = route.UpdateDomainRef(domain)
+	}
+
+	return presenter.ForManifestApplication(app, envVars.EnvironmentVariables, processes, routes), nil
+}
+
+func (h *ManifestHandler) client(w http.ResponseWriter, r *http.Request) (client.Client, bool) {
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (h *ManifestHandler) RegisterRoutes(router *mux.Router) {
+	router.Path(SpaceApplyManifestEndpoint).Methods("POST").HandlerFunc(h.applyManifestHandler)
+	router.Path(SpaceManifestDiffEndpoint).Methods("POST").HandlerFunc(h.manifestDiffHandler)
+	router.Path(AppManifestEndpoint).Methods("GET").HandlerFunc(h.appManifestHandler)
+}
+
+// decodeAndValidateManifest reads a YAML manifest from the body of r. All the problems with the manifest are reported
+// at once, each in an error of its own that names the application, process or sidecar it is about.
+func decodeAndValidateManifest(r *http.Request, manifest *payloads.Manifest) *requestMalformedError {
+	err := yaml.NewDecoder(r.Body).Decode(manifest)
+	if err != nil && err != io.EOF {
+		var typeError *yaml.TypeError
+		if errors.As(err, &typeError) {
+			return &requestMalformedError{
+				httpStatus:    http.StatusUnprocessableEntity,
+				errorResponse: newUnprocessableEntityErrors(typeError.Errors),
+			}
+		}
+		Logger.Error(err, fmt.Sprintf("Unable to parse the YAML body: %T: %q", err, err.Error()))
+		return &requestMalformedError{
+			httpStatus:    http.StatusBadRequest,
+			errorResponse: newMessageParseError(),
+		}
+	}
+
+	v := validator.New()
+	v.RegisterTagNameFunc(yamlFieldName)
+	v.RegisterValidation("megabytes", megabytesValidation)
+	trans := registerDefaultTranslator(v)
+	v.RegisterTranslation("megabytes", trans, func(ut ut.Translator) error {
+		return nil
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		if _, err := payloads.ParseMegabytes(fe.Value().(string)); err != nil {
+			return fmt.Sprintf("%s %s", fe.Field(), err.Error())
+		}
+		return fmt.Sprintf("%s must be greater than 0MB", fe.Field())
+	})
+
+	errorMessages := validationMessages(v.Struct(manifest), trans, "")
+	for _, app := range manifest.Applications {
+		prefix := fmt.Sprintf("For application '%s': ", app.Name)
+		errorMessages = append(errorMessages, validationMessages(v.Struct(app), trans, prefix)...)
+
+		envKeys := make([]string, 0, len(app.Env))
+		for key := range app.Env {
+			envKeys = append(envKeys, key)
+		}
+		sort.Strings(envKeys)
+		for _, key := range envKeys {
+			if msg := envVarKeyError(key); msg != "" {
+				errorMessages = append(errorMessages, prefix+msg)
+			}
+		}
+
+		seenProcessTypes := map[string]bool{}
+		for _, process := range app.Processes {
+			processPrefix := fmt.Sprintf("%sProcess %q: ", prefix, process.Type)
+			if seenProcessTypes[process.Type] {
+				errorMessages = append(errorMessages, processPrefix+"Process types must be unique")
+			}
+			seenProcessTypes[process.Type] = true
+			errorMessages = append(errorMessages, validationMessages(v.Struct(process), trans, processPrefix)...)
+		}
+
+		for _, route := range app.Routes {
+			if msg := manifestRouteError(route.Route); msg != "" {
+				errorMessages = append(errorMessages, prefix+msg)
+			}
+		}
+
+		if len(app.Sidecars) > 0 {
+			errorMessages = append(errorMessages, prefix+sidecarsNotSupportedDetail)
+		}
+	}
+
+	if len(errorMessages) > 0 {
+		return &requestMalformedError{
+			httpStatus:    http.StatusUnprocessableEntity,
+			errorResponse: newUnprocessableEntityErrors(errorMessages),
+		}
+	}
+
+	return nil
+}
+
+// validationMessages translates the errors of a struct validation, in the order of the fields of the struct
+func validationMessages(err error, trans ut.Translator, prefix string) []string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	var messages []string
+	for _, fieldError := range validationErrors {
+		messages = append(messages, prefix+fieldError.Translate(trans))
+	}
+	return messages
+}
+
+func yamlFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func megabytesValidation(fl validator.FieldLevel) bool {
+	megabytes, err := payloads.ParseMegabytes(fl.Field().String())
+	return err == nil && megabytes > 0
+}
+
+// manifestRouteError checks that a route of a manifest is a host name, without a scheme or port, optionally followed
+// by a path
+func manifestRouteError(route string) string {
+	routeURL, err := url.Parse("//" + route)
+	if err != nil || routeURL.Hostname() == "" || routeURL.Port() != "" || routeURL.User != nil ||
+		routeURL.RawQuery != "" || routeURL.Fragment != "" || strings.Contains(route, "://") {
+		return fmt.Sprintf("The route '%s' is not a properly formed URL", route)
+	}
+	return ""
+}
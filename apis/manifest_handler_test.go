@@ -0,0 +1,641 @@
+package apis_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	. "code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("ManifestHandler", func() {
+	const (
+		spaceGUID = "space-guid"
+		appGUID   = "app-guid"
+	)
+
+	var (
+		appRepo       *fake.CFAppRepository
+		processRepo   *fake.CFProcessRepository
+		routeRepo     *fake.CFRouteRepository
+		domainRepo    *fake.CFDomainRepository
+		revisionRepo  *fake.CFRevisionRepository
+		jobRepo       *fake.CFJobRepository
+		clientBuilder *fake.ClientBuilder
+		appRecord     repositories.AppRecord
+		jobErr        error
+	)
+
+	makeRequest := func(manifest string) {
+		var err error
+		req, err = http.NewRequest("POST", "/v3/spaces/"+spaceGUID+"/actions/apply_manifest", strings.NewReader(manifest))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Type", "application/x-yaml")
+	}
+
+	BeforeEach(func() {
+		appRepo = new(fake.CFAppRepository)
+		processRepo = new(fake.CFProcessRepository)
+		routeRepo = new(fake.CFRouteRepository)
+		domainRepo = new(fake.CFDomainRepository)
+		revisionRepo = new(fake.CFRevisionRepository)
+		jobRepo = new(fake.CFJobRepository)
+		clientBuilder = new(fake.ClientBuilder)
+
+		appRecord = repositories.AppRecord{GUID: appGUID, Name: "my-app", SpaceGUID: spaceGUID, EnvSecretName: "app-guid-env"}
+		appRepo.FetchAppListReturns([]repositories.AppRecord{appRecord}, 1, nil)
+		appRepo.PatchAppReturns(appRecord, nil)
+
+		jobErr = nil
+		jobRepo.RunJobStub = func(ctx context.Context, operation string, _ string, task repositories.JobTask) (repositories.JobRecord, error) {
+			jobErr = task(ctx)
+			return repositories.JobRecord{GUID: "test-job-guid", Operation: operation}, nil
+		}
+
+		apiHandler := NewManifestHandler(
+			logf.Log.WithName("TestManifestHandler"),
+			*serverURL,
+			appRepo,
+			processRepo,
+			routeRepo,
+			domainRepo,
+			revisionRepo,
+			jobRepo,
+			clientBuilder.Spy,
+			&rest.Config{},
+		)
+		apiHandler.RegisterRoutes(router)
+	})
+
+	JustBeforeEach(func() {
+		router.ServeHTTP(rr, req)
+	})
+
+	Describe("the POST /v3/spaces/:guid/actions/apply_manifest endpoint", func() {
+		When("the manifest configures an existing app", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+					{GUID: "web-guid", Type: "web"},
+					{GUID: "worker-guid", Type: "worker"},
+				}, nil)
+
+				makeRequest(`---
+applications:
+- name: my-app
+  buildpacks: [ruby_buildpack]
+  env:
+    RAILS_ENV: production
+    WORKERS: 2
+  instances: 2
+  memory: 512M
+  processes:
+  - type: worker
+    command: bundle exec sidekiq
+    disk_quota: 2G
+    health-check-type: none
+`)
+			})
+
+			It("responds with a job", func() {
+				Expect(rr.Code).To(Equal(http.StatusAccepted))
+				Expect(rr.Header().Get("Location")).To(Equal(defaultServerURI("/v3/jobs/test-job-guid")))
+
+				_, operation, resourceNamespace, _ := jobRepo.RunJobArgsForCall(0)
+				Expect(operation).To(Equal(repositories.SpaceApplyManifestJobOperation))
+				Expect(resourceNamespace).To(Equal(spaceGUID))
+				Expect(jobErr).NotTo(HaveOccurred())
+			})
+
+			It("looks the app up by name in the space", func() {
+				_, _, message := appRepo.FetchAppListArgsForCall(0)
+				Expect(message.Names).To(ConsistOf("my-app"))
+				Expect(message.SpaceGUIDs).To(ConsistOf(spaceGUID))
+				Expect(appRepo.CreateAppCallCount()).To(Equal(0))
+			})
+
+			It("adds the environment variables of the manifest", func() {
+				Expect(appRepo.PatchAppEnvVarsCallCount()).To(Equal(1))
+				_, _, message := appRepo.PatchAppEnvVarsArgsForCall(0)
+				Expect(message.AppGUID).To(Equal(appGUID))
+				Expect(message.EnvSecretName).To(Equal("app-guid-env"))
+				Expect(message.EnvironmentVariables).To(HaveLen(2))
+				Expect(*message.EnvironmentVariables["RAILS_ENV"]).To(Equal("production"))
+				Expect(*message.EnvironmentVariables["WORKERS"]).To(Equal("2"))
+			})
+
+			It("scales and patches the processes", func() {
+				Expect(processRepo.CreateProcessCallCount()).To(Equal(0))
+
+				Expect(processRepo.ScaleProcessCallCount()).To(Equal(2))
+				_, _, webScale := processRepo.ScaleProcessArgsForCall(0)
+				Expect(webScale.GUID).To(Equal("web-guid"))
+				Expect(*webScale.Instances).To(Equal(2))
+				Expect(*webScale.MemoryMB).To(BeEquivalentTo(512))
+				Expect(webScale.DiskMB).To(BeNil())
+				_, _, workerScale := processRepo.ScaleProcessArgsForCall(1)
+				Expect(workerScale.GUID).To(Equal("worker-guid"))
+				Expect(*workerScale.DiskMB).To(BeEquivalentTo(2048))
+
+				Expect(processRepo.PatchProcessCallCount()).To(Equal(1))
+				_, _, workerPatch := processRepo.PatchProcessArgsForCall(0)
+				Expect(workerPatch.ProcessGUID).To(Equal("worker-guid"))
+				Expect(*workerPatch.Command).To(Equal("bundle exec sidekiq"))
+				Expect(*workerPatch.HealthCheckType).To(Equal("process"))
+			})
+
+			It("sets the buildpacks of the app", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(1))
+				_, _, message := appRepo.PatchAppArgsForCall(0)
+				Expect(message.AppGUID).To(Equal(appGUID))
+				Expect(message.SpaceGUID).To(Equal(spaceGUID))
+				Expect(*message.Buildpacks).To(Equal([]string{"ruby_buildpack"}))
+				Expect(message.Stack).To(BeNil())
+			})
+
+			It("records a revision of the app", func() {
+				Expect(revisionRepo.RecordRevisionCallCount()).To(Equal(1))
+				_, _, message := revisionRepo.RecordRevisionArgsForCall(0)
+				Expect(message.AppGUID).To(Equal(appGUID))
+			})
+		})
+
+		When("the manifest sets the stack of an existing app", func() {
+			BeforeEach(func() {
+				makeRequest("applications:\n- name: my-app\n  stack: cflinuxfs4\n")
+			})
+
+			It("sets the stack and keeps the buildpacks", func() {
+				Expect(jobErr).NotTo(HaveOccurred())
+				_, _, message := appRepo.PatchAppArgsForCall(0)
+				Expect(*message.Stack).To(Equal("cflinuxfs4"))
+				Expect(message.Buildpacks).To(BeNil())
+			})
+		})
+
+		When("the manifest leaves out the buildpacks and stack of an existing app", func() {
+			BeforeEach(func() {
+				makeRequest("applications:\n- name: my-app\n  memory: 1G\n")
+			})
+
+			It("doesn't change the app", func() {
+				Expect(appRepo.PatchAppCallCount()).To(Equal(0))
+			})
+		})
+
+		When("updating the app fails", func() {
+			BeforeEach(func() {
+				appRepo.PatchAppReturns(repositories.AppRecord{}, errors.New("boom"))
+				makeRequest("applications:\n- name: my-app\n  stack: cflinuxfs4\n")
+			})
+
+			It("fails the job", func() {
+				Expect(jobErr).To(MatchError(ContainSubstring("error updating app: boom")))
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppListReturns([]repositories.AppRecord{}, 0, nil)
+				appRepo.CreateAppStub = func(_ context.Context, _ client.Client, record repositories.AppRecord) (repositories.AppRecord, error) {
+					return record, nil
+				}
+
+				makeRequest(`applications:
+- name: new-app
+  buildpacks: [go_buildpack]
+  stack: cflinuxfs4
+  memory: 1G
+  health-check-type: http
+  health-check-http-endpoint: /health
+`)
+			})
+
+			It("creates the app and its web process", func() {
+				Expect(jobErr).NotTo(HaveOccurred())
+
+				Expect(appRepo.CreateAppCallCount()).To(Equal(1))
+				_, _, record := appRepo.CreateAppArgsForCall(0)
+				Expect(record.Name).To(Equal("new-app"))
+				Expect(record.SpaceGUID).To(Equal(spaceGUID))
+				Expect(record.GUID).NotTo(BeEmpty())
+				Expect(record.State).To(Equal(repositories.StoppedState))
+				Expect(record.Lifecycle.Data.Buildpacks).To(Equal([]string{"go_buildpack"}))
+				Expect(record.Lifecycle.Data.Stack).To(Equal("cflinuxfs4"))
+
+				Expect(processRepo.CreateProcessCallCount()).To(Equal(1))
+				_, _, message := processRepo.CreateProcessArgsForCall(0)
+				Expect(message).To(Equal(repositories.ProcessCreateMessage{
+					AppGUID:     record.GUID,
+					SpaceGUID:   spaceGUID,
+					Type:        "web",
+					Instances:   1,
+					MemoryMB:    1024,
+					DiskQuotaMB: 1024,
+					HealthCheck: repositories.HealthCheck{
+						Type: "http",
+						Data: repositories.HealthCheckData{HTTPEndpoint: "/health"},
+					},
+				}))
+			})
+		})
+
+		When("the manifest has routes", func() {
+			BeforeEach(func() {
+				domainRepo.FetchDomainByNameStub = func(_ context.Context, _ client.Client, name string) (repositories.DomainRecord, error) {
+					if name == "apps.example.org" {
+						return repositories.DomainRecord{GUID: "domain-guid", Name: name}, nil
+					}
+					return repositories.DomainRecord{}, repositories.NotFoundError{}
+				}
+				routeRepo.FetchRouteListReturns([]repositories.RouteRecord{}, 0, nil)
+				routeRepo.CreateRouteStub = func(_ context.Context, _ client.Client, record repositories.RouteRecord) (repositories.RouteRecord, error) {
+					return record, nil
+				}
+
+				makeRequest(`applications:
+- name: my-app
+  routes:
+  - route: my-app.apps.example.org/api
+`)
+			})
+
+			It("creates the route and maps it to the web process", func() {
+				Expect(jobErr).NotTo(HaveOccurred())
+
+				_, _, listMessage := routeRepo.FetchRouteListArgsForCall(0)
+				Expect(listMessage.Hosts).To(ConsistOf("my-app"))
+				Expect(listMessage.Paths).To(ConsistOf("/api"))
+				Expect(listMessage.DomainGUIDs).To(ConsistOf("domain-guid"))
+				Expect(listMessage.SpaceGUIDs).To(ConsistOf(spaceGUID))
+
+				Expect(routeRepo.CreateRouteCallCount()).To(Equal(1))
+				_, _, route := routeRepo.CreateRouteArgsForCall(0)
+				Expect(route.Host).To(Equal("my-app"))
+				Expect(route.Path).To(Equal("/api"))
+				Expect(route.DomainRef.GUID).To(Equal("domain-guid"))
+
+				Expect(routeRepo.AddDestinationsToRouteCallCount()).To(Equal(1))
+				_, _, message := routeRepo.AddDestinationsToRouteArgsForCall(0)
+				Expect(message.RouteGUID).To(Equal(route.GUID))
+				Expect(message.NewDestinations).To(Equal([]repositories.DestinationMessage{{AppGUID: appGUID, ProcessType: "web"}}))
+			})
+
+			When("the route exists", func() {
+				BeforeEach(func() {
+					routeRepo.FetchRouteListReturns([]repositories.RouteRecord{{GUID: "route-guid"}}, 1, nil)
+				})
+
+				It("maps the existing route", func() {
+					Expect(routeRepo.CreateRouteCallCount()).To(Equal(0))
+					_, _, message := routeRepo.AddDestinationsToRouteArgsForCall(0)
+					Expect(message.RouteGUID).To(Equal("route-guid"))
+				})
+			})
+
+			When("the route is mapped to another process of the app", func() {
+				BeforeEach(func() {
+					routeRepo.FetchRouteListReturns([]repositories.RouteRecord{{
+						GUID:         "route-guid",
+						Destinations: []repositories.Destination{{AppGUID: appGUID, ProcessType: "api"}},
+					}}, 1, nil)
+				})
+
+				It("keeps the route mapped to that process only", func() {
+					Expect(jobErr).NotTo(HaveOccurred())
+					Expect(routeRepo.AddDestinationsToRouteCallCount()).To(Equal(0))
+				})
+			})
+
+			When("the route is mapped to another app only", func() {
+				BeforeEach(func() {
+					routeRepo.FetchRouteListReturns([]repositories.RouteRecord{{
+						GUID:         "route-guid",
+						Destinations: []repositories.Destination{{AppGUID: "other-app-guid", ProcessType: "api"}},
+					}}, 1, nil)
+				})
+
+				It("maps it to the web process of the app", func() {
+					_, _, message := routeRepo.AddDestinationsToRouteArgsForCall(0)
+					Expect(message.NewDestinations).To(Equal([]repositories.DestinationMessage{{AppGUID: appGUID, ProcessType: "web"}}))
+				})
+			})
+
+			When("no domain matches the route", func() {
+				BeforeEach(func() {
+					domainRepo.FetchDomainByNameStub = nil
+					domainRepo.FetchDomainByNameReturns(repositories.DomainRecord{}, repositories.NotFoundError{})
+				})
+
+				It("fails the job", func() {
+					Expect(rr.Code).To(Equal(http.StatusAccepted))
+					Expect(jobErr).To(MatchError("For application 'my-app': The route 'my-app.apps.example.org/api' did not match any existing domains."))
+				})
+			})
+		})
+
+		When("scaling a process fails", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{{GUID: "web-guid", Type: "web"}}, nil)
+				processRepo.ScaleProcessReturns(repositories.ProcessRecord{}, repositories.QuotaExceededError{Reason: "memory space_quota_exceeded"})
+
+				makeRequest("applications:\n- name: my-app\n  memory: 4G\n")
+			})
+
+			It("fails the job with the reason", func() {
+				Expect(jobErr).To(MatchError(`For application 'my-app': Process "web": memory space_quota_exceeded`))
+				Expect(jobErr).To(BeAssignableToTypeOf(repositories.JobError{}))
+				Expect(jobErr.(repositories.JobError).Code).To(Equal(10008))
+				Expect(revisionRepo.RecordRevisionCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the manifest is invalid", func() {
+			BeforeEach(func() {
+				makeRequest(`applications:
+- name: my-app
+  memory: 512Q
+  env:
+    VCAP_SERVICES: "{}"
+  routes:
+  - route: https://my-app.example.org
+  processes:
+  - type: worker
+    instances: -1
+  sidecars:
+  - name: my-sidecar
+- instances: 1
+`)
+			})
+
+			It("returns all the problems at once", func() {
+				expectJSONResponse(http.StatusUnprocessableEntity, `{
+					"errors": [
+						{
+							"detail": "For application 'my-app': memory must use a supported unit: B, K, KB, M, MB, G, GB, T, or TB",
+							"title": "CF-UnprocessableEntity",
+							"code": 10008
+						},
+						{
+							"detail": "For application 'my-app': Var cannot start with VCAP_",
+							"title": "CF-UnprocessableEntity",
+							"code": 10008
+						},
+						{
+							"detail": "For application 'my-app': Process \"worker\": instances must be 0 or greater",
+							"title": "CF-UnprocessableEntity",
+							"code": 10008
+						},
+						{
+							"detail": "For application 'my-app': The route 'https://my-app.example.org' is not a properly formed URL",
+							"title": "CF-UnprocessableEntity",
+							"code": 10008
+						},
+						{
+							"detail": "For application 'my-app': Sidecars are not supported",
+							"title": "CF-UnprocessableEntity",
+							"code": 10008
+						},
+						{
+							"detail": "For application '': name is a required field",
+							"title": "CF-UnprocessableEntity",
+							"code": 10008
+						}
+					]
+				}`)
+				Expect(jobRepo.RunJobCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the manifest has no applications", func() {
+			BeforeEach(func() {
+				makeRequest("---\nversion: 1\n")
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("applications is a required field")
+			})
+		})
+
+		When("a value has the wrong type", func() {
+			BeforeEach(func() {
+				makeRequest("applications:\n- name: my-app\n  instances: many\n")
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("line 3: cannot unmarshal !!str `many` into int")
+			})
+		})
+
+		When("the manifest is not YAML", func() {
+			BeforeEach(func() {
+				makeRequest("applications: [")
+			})
+
+			It("returns an error", func() {
+				expectBadRequestError()
+			})
+		})
+
+		When("the space doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchNamespaceReturns(repositories.SpaceRecord{}, repositories.PermissionDeniedOrNotFoundError{})
+				makeRequest("applications:\n- name: my-app\n")
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Space not found")
+				Expect(jobRepo.RunJobCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the job cannot be started", func() {
+			BeforeEach(func() {
+				jobRepo.RunJobStub = nil
+				jobRepo.RunJobReturns(repositories.JobRecord{}, errors.New("boom"))
+				makeRequest("applications:\n- name: my-app\n")
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the POST /v3/spaces/:guid/manifest_diff endpoint", func() {
+		BeforeEach(func() {
+			appRepo.FetchAppEnvVarsReturns(repositories.AppEnvVarsRecord{
+				EnvironmentVariables: map[string]string{"RAILS_ENV": "staging"},
+			}, nil)
+			processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+				{GUID: "web-guid", Type: "web", Instances: 1, MemoryMB: 1024, DiskQuotaMB: 1024, HealthCheck: repositories.HealthCheck{Type: "port"}},
+			}, nil)
+			routeRepo.FetchRoutesForAppReturns([]repositories.RouteRecord{
+				{GUID: "route-guid", Host: "my-app", DomainRef: repositories.DomainRecord{GUID: "domain-guid"}},
+			}, nil)
+			domainRepo.FetchDomainReturns(repositories.DomainRecord{GUID: "domain-guid", Name: "apps.example.org"}, nil)
+			appRecord.Lifecycle.Data.Stack = "cflinuxfs3"
+			appRepo.FetchAppListReturns([]repositories.AppRecord{appRecord}, 1, nil)
+
+			var err error
+			req, err = http.NewRequest("POST", "/v3/spaces/"+spaceGUID+"/manifest_diff", strings.NewReader(`---
+applications:
+- name: my-app
+  stack: cflinuxfs4
+  buildpacks: [ruby_buildpack]
+  env:
+    RAILS_ENV: production
+    WORKERS: 2
+  memory: 1G
+  instances: 3
+  routes:
+  - route: my-app.apps.example.org
+  - route: my-app.apps.example.org/api
+  processes:
+  - type: worker
+    command: bundle exec sidekiq
+- name: new-app
+`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the changes that applying the manifest would make", func() {
+			expectJSONResponse(http.StatusCreated, `{
+				"diff": [
+					{"op": "replace", "path": "/applications/0/stack", "was": "cflinuxfs3", "value": "cflinuxfs4"},
+					{"op": "add", "path": "/applications/0/buildpacks", "value": ["ruby_buildpack"]},
+					{"op": "replace", "path": "/applications/0/env/RAILS_ENV", "was": "staging", "value": "production"},
+					{"op": "add", "path": "/applications/0/env/WORKERS", "value": "2"},
+					{"op": "replace", "path": "/applications/0/instances", "was": 1, "value": 3},
+					{"op": "add", "path": "/applications/0/processes/0", "value": {"type": "worker", "command": "bundle exec sidekiq"}},
+					{"op": "add", "path": "/applications/0/routes/1", "value": {"route": "my-app.apps.example.org/api"}},
+					{"op": "add", "path": "/applications/1", "value": {"name": "new-app"}}
+				]
+			}`)
+		})
+
+		It("looks the apps up by name in the space", func() {
+			_, _, message := appRepo.FetchAppListArgsForCall(0)
+			Expect(message.Names).To(Equal([]string{"my-app", "new-app"}))
+			Expect(message.SpaceGUIDs).To(ConsistOf(spaceGUID))
+		})
+
+		It("doesn't change anything", func() {
+			Expect(jobRepo.RunJobCallCount()).To(Equal(0))
+			Expect(appRepo.PatchAppEnvVarsCallCount()).To(Equal(0))
+			Expect(processRepo.ScaleProcessCallCount()).To(Equal(0))
+		})
+
+		When("the space doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchNamespaceReturns(repositories.SpaceRecord{}, repositories.PermissionDeniedOrNotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Space not found")
+			})
+		})
+
+		When("fetching the processes of an app fails", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessesForAppReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/apps/:guid/manifest endpoint", func() {
+		BeforeEach(func() {
+			appRecord.Lifecycle.Data.Buildpacks = []string{"ruby_buildpack"}
+			appRecord.Lifecycle.Data.Stack = "cflinuxfs3"
+			appRepo.FetchAppReturns(appRecord, nil)
+			appRepo.FetchAppEnvVarsReturns(repositories.AppEnvVarsRecord{
+				EnvironmentVariables: map[string]string{"RAILS_ENV": "production"},
+			}, nil)
+			processRepo.FetchProcessesForAppReturns([]repositories.ProcessRecord{
+				{GUID: "worker-guid", Type: "worker", Command: "bundle exec sidekiq", Instances: 0, MemoryMB: 256, DiskQuotaMB: 1024, HealthCheck: repositories.HealthCheck{Type: "process"}},
+				{GUID: "web-guid", Type: "web", Instances: 2, MemoryMB: 512, DiskQuotaMB: 1024, HealthCheck: repositories.HealthCheck{
+					Type: "http",
+					Data: repositories.HealthCheckData{HTTPEndpoint: "/health", TimeoutSeconds: 60},
+				}},
+			}, nil)
+			routeRepo.FetchRoutesForAppReturns([]repositories.RouteRecord{
+				{GUID: "route-guid", Host: "my-app", Path: "/api", DomainRef: repositories.DomainRecord{GUID: "domain-guid"}},
+			}, nil)
+			domainRepo.FetchDomainReturns(repositories.DomainRecord{GUID: "domain-guid", Name: "apps.example.org"}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/manifest", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("renders the configuration of the app as a manifest", func() {
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Header().Get("Content-Type")).To(Equal("application/x-yaml"))
+			Expect(rr.Body.String()).To(Equal(`---
+applications:
+    - name: my-app
+      stack: cflinuxfs3
+      buildpacks:
+        - ruby_buildpack
+      env:
+        RAILS_ENV: production
+      processes:
+        - type: web
+          instances: 2
+          memory: 512M
+          disk_quota: 1024M
+          health-check-type: http
+          health-check-http-endpoint: /health
+          timeout: 60
+        - type: worker
+          instances: 0
+          memory: 256M
+          disk_quota: 1024M
+          command: bundle exec sidekiq
+          health-check-type: process
+      routes:
+        - route: my-app.apps.example.org/api
+`))
+		})
+
+		It("fetches the app's configuration from its space", func() {
+			_, _, fetchedGUID := appRepo.FetchAppArgsForCall(0)
+			Expect(fetchedGUID).To(Equal(appGUID))
+			_, _, processAppGUID, processSpaceGUID, _ := processRepo.FetchProcessesForAppArgsForCall(0)
+			Expect(processAppGUID).To(Equal(appGUID))
+			Expect(processSpaceGUID).To(Equal(spaceGUID))
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+
+		When("fetching a domain fails", func() {
+			BeforeEach(func() {
+				domainRepo.FetchDomainReturns(repositories.DomainRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+})
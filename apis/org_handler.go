@@ -3,6 +3,7 @@ package apis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -16,11 +17,13 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/labels"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 )
 
 const (
-	OrgListEndpoint = "/v3/organizations"
+	OrgListEndpoint  = "/v3/organizations"
+	OrgPatchEndpoint = "/v3/organizations/{guid}"
 )
 
 //counterfeiter:generate -o fake -fake-name CFOrgRepository . CFOrgRepository
@@ -28,7 +31,8 @@ const (
 
 type CFOrgRepository interface {
 	CreateOrg(context context.Context, org repositories.OrgRecord) (repositories.OrgRecord, error)
-	FetchOrgs(context context.Context, orgNames []string) ([]repositories.OrgRecord, error)
+	FetchOrgs(context context.Context, orgNames []string, labelSelector labels.Selector) ([]repositories.OrgRecord, error)
+	PatchOrgMetadata(context context.Context, message repositories.MetadataPatchMessage) (repositories.OrgRecord, error)
 }
 
 type OrgRepositoryProvider interface {
@@ -92,7 +96,7 @@ func (h *OrgHandler) orgCreateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	orgResponse := presenter.ForCreateOrg(record, h.apiBaseURL)
+	orgResponse := presenter.ForOrg(record, h.apiBaseURL)
 	json.NewEncoder(w).Encode(orgResponse)
 }
 
@@ -100,6 +104,20 @@ func (h *OrgHandler) orgListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	labelSelector, err := parseLabelSelector(r)
+	if err != nil {
+		h.logger.Info("Invalid label selector", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
 	var names []string
 	namesList := r.URL.Query().Get("names")
 	if len(namesList) > 0 {
@@ -121,7 +139,7 @@ func (h *OrgHandler) orgListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	orgs, err := orgRepo.FetchOrgs(ctx, names)
+	orgs, err := orgRepo.FetchOrgs(ctx, names, labelSelector)
 	if err != nil {
 		h.logger.Error(err, "failed to fetch orgs")
 		writeUnknownErrorResponse(w)
@@ -129,11 +147,55 @@ func (h *OrgHandler) orgListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	orgList := presenter.ForOrgList(orgs, h.apiBaseURL)
+	start, end := pageRequest.Bounds(len(orgs))
+	orgList := presenter.ForOrgList(orgs[start:end], h.apiBaseURL, newListPage(r, pageRequest, len(orgs)))
 	json.NewEncoder(w).Encode(orgList)
 }
 
+func (h *OrgHandler) orgPatchHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	orgGUID := mux.Vars(r)["guid"]
+
+	var payload payloads.OrgPatch
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+
+		return
+	}
+
+	orgRepo, err := h.orgRepoProvider.OrgRepoForRequest(r)
+	if err != nil {
+		if authorization.IsUnauthorized(err) {
+			h.logger.Error(err, "unauthorized to patch org")
+			writeUnauthorizedErrorResponse(w)
+
+			return
+		}
+
+		h.logger.Error(err, "failed to create org repo for the authorization header")
+		writeUnknownErrorResponse(w)
+
+		return
+	}
+
+	record, err := orgRepo.PatchOrgMetadata(r.Context(), payload.ToMessage(orgGUID))
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Org not found", "OrgGUID", orgGUID)
+			writeNotFoundErrorResponse(w, "Organization")
+			return
+		}
+		h.logger.Error(err, "Failed to patch org metadata", "OrgGUID", orgGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	json.NewEncoder(w).Encode(presenter.ForOrg(record, h.apiBaseURL))
+}
+
 func (h *OrgHandler) RegisterRoutes(router *mux.Router) {
 	router.Path(OrgListEndpoint).Methods("GET").HandlerFunc(h.orgListHandler)
 	router.Path(OrgListEndpoint).Methods("POST").HandlerFunc(h.orgCreateHandler)
+	router.Path(OrgPatchEndpoint).Methods("PATCH").HandlerFunc(h.orgPatchHandler)
 }
@@ -2,6 +2,7 @@ package apis_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 	"code.cloudfoundry.org/cf-k8s-controllers/webhooks/workloads"
@@ -21,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -349,7 +352,7 @@ var _ = Describe("OrgHandler", func() {
 
 			It("lists orgs using the repository", func() {
 				Expect(orgRepo.FetchOrgsCallCount()).To(Equal(1))
-				_, names := orgRepo.FetchOrgsArgsForCall(0)
+				_, names, _ := orgRepo.FetchOrgsArgsForCall(0)
 				Expect(names).To(BeEmpty())
 			})
 
@@ -360,10 +363,10 @@ var _ = Describe("OrgHandler", func() {
                         "total_results": 2,
                         "total_pages": 1,
                         "first": {
-                            "href": "%[1]s/v3/organizations?page=1"
+                            "href": "%[1]s/v3/organizations?page=1&per_page=50"
                         },
                         "last": {
-                            "href": "%[1]s/v3/organizations?page=1"
+                            "href": "%[1]s/v3/organizations?page=1&per_page=50"
                         },
                         "next": null,
                         "previous": null
@@ -421,11 +424,64 @@ var _ = Describe("OrgHandler", func() {
 
 			It("filters by them", func() {
 				Expect(orgRepo.FetchOrgsCallCount()).To(Equal(1))
-				_, names := orgRepo.FetchOrgsArgsForCall(0)
+				_, names, _ := orgRepo.FetchOrgsArgsForCall(0)
 				Expect(names).To(ConsistOf("foo", "bar"))
 			})
 		})
 
+		When("a label selector is specified", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = url.Values{"label_selector": []string{"env in (prod,staging),!deprecated"}}.Encode()
+				router.ServeHTTP(rr, req)
+			})
+
+			It("filters by it", func() {
+				_, _, labelSelector := orgRepo.FetchOrgsArgsForCall(0)
+				Expect(labelSelector.Matches(labels.Set{"env": "prod"})).To(BeTrue())
+				Expect(labelSelector.Matches(labels.Set{"env": "dev"})).To(BeFalse())
+				Expect(labelSelector.Matches(labels.Set{"env": "prod", "deprecated": "true"})).To(BeFalse())
+			})
+		})
+
+		When("the label selector is invalid", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = url.Values{"label_selector": []string{"env in prod"}}.Encode()
+				router.ServeHTTP(rr, req)
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Invalid label_selector value")
+				Expect(orgRepo.FetchOrgsCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the second page of one org is requested", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "page=2&per_page=1"
+				router.ServeHTTP(rr, req)
+			})
+
+			It("returns only the second org", func() {
+				var response presenter.OrgListResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Resources).To(HaveLen(1))
+				Expect(response.Resources[0].GUID).To(Equal("b-o-b"))
+				Expect(response.Pagination.TotalResults).To(Equal(2))
+				Expect(response.Pagination.TotalPages).To(Equal(2))
+			})
+		})
+
+		When("the page is not a number", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "page=first"
+				router.ServeHTTP(rr, req)
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Page must be a positive integer")
+			})
+		})
+
 		When("fetching the orgs fails", func() {
 			BeforeEach(func() {
 				orgRepo.FetchOrgsReturns(nil, errors.New("boom!"))
@@ -477,4 +533,105 @@ var _ = Describe("OrgHandler", func() {
 			})
 		})
 	})
+
+	Describe("Patching an Org", func() {
+		makePatchRequest := func(requestBody string) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPatch, orgsBase+"/t-h-e-o-r-g", strings.NewReader(requestBody))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, "Bearer my-token")
+
+			router.ServeHTTP(rr, req)
+		}
+
+		BeforeEach(func() {
+			orgRepo.PatchOrgMetadataReturns(repositories.OrgRecord{
+				Name:        "the-org",
+				GUID:        "t-h-e-o-r-g",
+				Labels:      map[string]string{"env": "prod"},
+				Annotations: map[string]string{"example.org/owner": "payments"},
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}, nil)
+		})
+
+		When("happy path", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{"metadata": {"labels": {"env": "prod", "tier": null}, "annotations": {"example.org/owner": "payments"}}}`)
+			})
+
+			It("returns 200 with the patched org", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(rr.Body.String()).To(MatchJSON(fmt.Sprintf(`{
+					"guid": "t-h-e-o-r-g",
+					"name": "the-org",
+					"created_at": "2021-09-17T15:23:10Z",
+					"updated_at": "2021-09-17T15:23:10Z",
+					"suspended": false,
+					"metadata": {
+						"labels": {"env": "prod"},
+						"annotations": {"example.org/owner": "payments"}
+					},
+					"relationships": {},
+					"links": {
+						"self": {
+							"href": "%[1]s/v3/organizations/t-h-e-o-r-g"
+						}
+					}
+				}`, rootURL)))
+			})
+
+			It("patches the org metadata", func() {
+				Expect(orgRepo.PatchOrgMetadataCallCount()).To(Equal(1))
+				_, message := orgRepo.PatchOrgMetadataArgsForCall(0)
+				Expect(message.GUID).To(Equal("t-h-e-o-r-g"))
+				Expect(*message.Labels["env"]).To(Equal("prod"))
+				Expect(message.Labels).To(HaveKeyWithValue("tier", BeNil()))
+				Expect(*message.Annotations["example.org/owner"]).To(Equal("payments"))
+			})
+		})
+
+		When("a label key is invalid", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{"metadata": {"labels": {"cloudfoundry.org/org-name": "foo"}}}`)
+			})
+
+			It("returns an error without patching the org", func() {
+				expectUnprocessableEntityError("Metadata label key error: prefix 'cloudfoundry.org' is reserved")
+				Expect(orgRepo.PatchOrgMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the org does not exist", func() {
+			BeforeEach(func() {
+				orgRepo.PatchOrgMetadataReturns(repositories.OrgRecord{}, repositories.NotFoundError{})
+				makePatchRequest(`{"metadata": {"labels": {"env": "prod"}}}`)
+			})
+
+			It("returns a not found error", func() {
+				expectNotFoundError("Organization not found")
+			})
+		})
+
+		When("patching the org fails", func() {
+			BeforeEach(func() {
+				orgRepo.PatchOrgMetadataReturns(repositories.OrgRecord{}, errors.New("boom"))
+				makePatchRequest(`{"metadata": {"labels": {"env": "prod"}}}`)
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+
+		When("not authorized", func() {
+			BeforeEach(func() {
+				orgRepoProvider.OrgRepoForRequestReturns(nil, authorization.UnauthorizedErr{})
+				makePatchRequest(`{"metadata": {"labels": {"env": "prod"}}}`)
+			})
+
+			It("returns Unauthorized error", func() {
+				Expect(rr.Result().StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
 })
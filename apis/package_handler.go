@@ -5,14 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"mime/multipart"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 
 	"code.cloudfoundry.org/cf-k8s-api/payloads"
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
+	"github.com/go-http-utils/headers"
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/gorilla/mux"
@@ -23,6 +27,7 @@ import (
 const (
 	PackageCreateEndpoint = "/v3/packages"
 	PackageUploadEndpoint = "/v3/packages/{guid}/upload"
+	PackagePatchEndpoint  = "/v3/packages/{guid}"
 )
 
 //counterfeiter:generate -o fake -fake-name CFPackageRepository . CFPackageRepository
@@ -31,11 +36,12 @@ type CFPackageRepository interface {
 	FetchPackage(context.Context, client.Client, string) (repositories.PackageRecord, error)
 	CreatePackage(context.Context, client.Client, repositories.PackageCreateMessage) (repositories.PackageRecord, error)
 	UpdatePackageSource(ctx context.Context, client client.Client, message repositories.PackageUpdateSourceMessage) (repositories.PackageRecord, error)
+	PatchPackageMetadata(ctx context.Context, client client.Client, message repositories.MetadataPatchMessage) (repositories.PackageRecord, error)
 }
 
 //counterfeiter:generate -o fake -fake-name SourceImageUploader . SourceImageUploader
 
-type SourceImageUploader func(imageRef string, packageSrcFile multipart.File, credentialOption remote.Option) (imageRefWithDigest string, err error)
+type SourceImageUploader func(imageRef string, sourceTar io.Reader, credentialOption remote.Option) (imageRefWithDigest string, err error)
 
 //counterfeiter:generate -o fake -fake-name RegistryAuthBuilder . RegistryAuthBuilder
 
@@ -46,12 +52,15 @@ type PackageHandler struct {
 	serverURL          url.URL
 	packageRepo        CFPackageRepository
 	appRepo            CFAppRepository
+	fingerprintRepos   FingerprintRepositoryProvider
 	buildClient        ClientBuilder
 	uploadSourceImage  SourceImageUploader
 	buildRegistryAuth  RegistryAuthBuilder
 	k8sConfig          *rest.Config
 	registryBase       string
 	registrySecretName string
+	maxUploadSizeMB    int64
+	uploadSlots        chan struct{}
 }
 
 func NewPackageHandler(
@@ -59,23 +68,29 @@ func NewPackageHandler(
 	serverURL url.URL,
 	packageRepo CFPackageRepository,
 	appRepo CFAppRepository,
+	fingerprintRepos FingerprintRepositoryProvider,
 	buildClient ClientBuilder,
 	uploadSourceImage SourceImageUploader,
 	buildRegistryAuth RegistryAuthBuilder,
 	k8sConfig *rest.Config,
 	registryBase string,
-	registrySecretName string) *PackageHandler {
+	registrySecretName string,
+	maxUploadSizeMB int64,
+	maxConcurrentUploads int) *PackageHandler {
 	return &PackageHandler{
 		logger:             logger,
 		serverURL:          serverURL,
 		packageRepo:        packageRepo,
 		appRepo:            appRepo,
+		fingerprintRepos:   fingerprintRepos,
 		buildClient:        buildClient,
 		uploadSourceImage:  uploadSourceImage,
 		buildRegistryAuth:  buildRegistryAuth,
 		k8sConfig:          k8sConfig,
 		registryBase:       registryBase,
 		registrySecretName: registrySecretName,
+		maxUploadSizeMB:    maxUploadSizeMB,
+		uploadSlots:        make(chan struct{}, maxConcurrentUploads),
 	}
 }
 
@@ -89,10 +104,9 @@ func (h PackageHandler) packageCreateHandler(w http.ResponseWriter, req *http.Re
 		return
 	}
 
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, req.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Info("Error building k8s client", "error", err.Error())
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
 
@@ -129,24 +143,22 @@ func (h PackageHandler) packageCreateHandler(w http.ResponseWriter, req *http.Re
 func (h PackageHandler) packageUploadHandler(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	packageGUID := mux.Vars(req)["guid"]
-	err := req.ParseForm()
-	if err != nil { // untested - couldn't find a way to trigger this branch
-		h.logger.Info("Error parsing multipart form", "error", err.Error())
-		writeInvalidRequestError(w, "Unable to parse body as multipart form")
-		return
-	}
 
-	bitsFile, _, err := req.FormFile("bits")
+	// the user and the package are checked before the form is read, so that nothing is read off unauthorized uploads
+	client, err := h.buildClient(h.k8sConfig, req.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Info("Error reading form file \"bits\"", "error", err.Error())
-		writeUnprocessableEntityError(w, "Upload must include bits")
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
-	defer bitsFile.Close()
 
-	client, err := h.buildClient(h.k8sConfig)
+	fingerprintRepo, err := h.fingerprintRepos.FingerprintRepoForRequest(req)
 	if err != nil {
-		h.logger.Info("Error building k8s client", "error", err.Error())
+		if authorization.IsUnauthorized(err) {
+			h.logger.Info("Unauthorized to read fingerprints", "reason", err.Error())
+			writeUnauthorizedErrorResponse(w)
+			return
+		}
+		h.logger.Error(err, "Unable to create fingerprint repo for the authorization header")
 		writeUnknownErrorResponse(w)
 		return
 	}
@@ -169,6 +181,50 @@ func (h PackageHandler) packageUploadHandler(w http.ResponseWriter, req *http.Re
 		return
 	}
 
+	maxUploadSize := h.maxUploadSizeMB * 1024 * 1024
+	if req.ContentLength > maxUploadSize {
+		h.logger.Info("Upload is too large", "packageGUID", packageGUID, "contentLength", req.ContentLength)
+		writePackageBitsTooLargeError(w, h.maxUploadSizeMB)
+		return
+	}
+	// the bits are read as they are uploaded, so uploads without a Content-Length are only cut off once too much is read
+	body := &uploadLimitReader{r: req.Body, remaining: maxUploadSize}
+	req.Body = ioutil.NopCloser(body)
+
+	resources, bitsFile, err := readPackageUploadForm(req)
+	if err != nil {
+		h.logger.Info("Error reading package upload form", "error", err.Error())
+		var rme *requestMalformedError
+		switch {
+		case body.limitExceeded():
+			writePackageBitsTooLargeError(w, h.maxUploadSizeMB)
+		case errors.As(err, &rme):
+			writeErrorResponse(w, rme)
+		default:
+			writeUnprocessableEntityError(w, "Upload must include either resources or bits")
+		}
+		return
+	}
+	if bitsFile == nil && len(resources.Resources) == 0 {
+		h.logger.Info("Package upload has neither resources nor bits", "packageGUID", packageGUID)
+		writeUnprocessableEntityError(w, "Upload must include either resources or bits")
+		return
+	}
+
+	cachedResources := resources.ToRecords()
+	matches, err := fingerprintRepo.MatchResources(cachedResources)
+	if err != nil {
+		h.logger.Info("Error matching resources", "error", err.Error())
+		writeUnknownErrorResponse(w)
+		return
+	}
+	if len(matches) < len(cachedResources) {
+		missing := missingResource(cachedResources, matches)
+		h.logger.Info("Package upload includes resources that are not cached", "packageGUID", packageGUID, "path", missing.Path)
+		writeUnprocessableEntityError(w, fmt.Sprintf("Resource %q with checksum %s was not found", missing.Path, missing.SHA1))
+		return
+	}
+
 	registryAuth, err := h.buildRegistryAuth(req.Context())
 	if err != nil {
 		h.logger.Info("Error calling buildRegistryAuth", "error", err.Error())
@@ -178,9 +234,24 @@ func (h PackageHandler) packageUploadHandler(w http.ResponseWriter, req *http.Re
 
 	imageRef := fmt.Sprintf("%s/%s", h.registryBase, packageGUID)
 
-	uploadedImageRef, err := h.uploadSourceImage(imageRef, bitsFile, registryAuth)
+	select {
+	case h.uploadSlots <- struct{}{}:
+		defer func() { <-h.uploadSlots }()
+	case <-req.Context().Done():
+		h.logger.Info("Request ended while waiting for other uploads to finish", "packageGUID", packageGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	sourceTar := fingerprintRepo.ReadPackageAsTar(bitsFile, cachedResources, "/")
+	uploadedImageRef, err := h.uploadSourceImage(imageRef, sourceTar, registryAuth)
+	sourceTar.Close()
 	if err != nil {
 		h.logger.Info("Error calling uploadSourceImage", "error", err.Error())
+		if body.limitExceeded() {
+			writePackageBitsTooLargeError(w, h.maxUploadSizeMB)
+			return
+		}
 		writeUnknownErrorResponse(w)
 		return
 	}
@@ -206,7 +277,137 @@ func (h PackageHandler) packageUploadHandler(w http.ResponseWriter, req *http.Re
 	}
 }
 
+func (h PackageHandler) packagePatchHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	packageGUID := mux.Vars(req)["guid"]
+
+	var payload payloads.PackagePatch
+	rme := DecodeAndValidatePayload(req, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, req.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	record, err := h.packageRepo.FetchPackage(req.Context(), client, packageGUID)
+	if err != nil {
+		switch {
+		case errors.As(err, &repositories.NotFoundError{}):
+			writeNotFoundErrorResponse(w, "Package")
+		default:
+			h.logger.Info("Error fetching package with repository", "error", err.Error())
+			writeUnknownErrorResponse(w)
+		}
+		return
+	}
+
+	record, err = h.packageRepo.PatchPackageMetadata(req.Context(), client, payload.ToMessage(packageGUID, record.SpaceGUID))
+	if err != nil {
+		switch {
+		case errors.As(err, &repositories.NotFoundError{}):
+			writeNotFoundErrorResponse(w, "Package")
+		default:
+			h.logger.Info("Error patching package metadata with repository", "error", err.Error())
+			writeUnknownErrorResponse(w)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(presenter.ForPackage(record, h.serverURL))
+	if err != nil { // untested
+		h.logger.Info("Error encoding JSON response", "error", err.Error())
+		writeUnknownErrorResponse(w)
+		return
+	}
+}
+
+// readPackageUploadForm reads the multipart form of a package upload until the part of the bits file, which is returned
+// to be read as it is uploaded rather than read into memory or a temp file as http.Request.FormFile does. The resources
+// field must therefore come before the bits. The bits are nil when the form has none.
+func readPackageUploadForm(req *http.Request) (payloads.ResourceMatches, io.Reader, error) {
+	var resources payloads.ResourceMatches
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return resources, nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return resources, nil, nil
+		}
+		if err != nil {
+			return resources, nil, err
+		}
+
+		switch {
+		case part.FormName() == "bits" && part.FileName() != "":
+			return resources, part, nil
+		case part.FormName() == "resources":
+			if err := json.NewDecoder(part).Decode(&resources.Resources); err != nil {
+				return resources, nil, &requestMalformedError{
+					httpStatus:    http.StatusUnprocessableEntity,
+					errorResponse: newUnprocessableEntityError("Resources must be a JSON array of resource matches"),
+				}
+			}
+			if rme := validatePayload(&resources); rme != nil {
+				return resources, nil, rme
+			}
+		}
+	}
+}
+
+func missingResource(resources, matches []repositories.ResourceRecord) repositories.ResourceRecord {
+	matched := map[string]bool{}
+	for _, match := range matches {
+		matched[match.Path] = true
+	}
+	for _, resource := range resources {
+		if !matched[resource.Path] {
+			return resource
+		}
+	}
+	return repositories.ResourceRecord{}
+}
+
+var errUploadTooLarge = errors.New("upload is too large")
+
+// uploadLimitReader reads from r until more than remaining bytes have been read. Whether the limit was exceeded can be
+// checked while another goroutine is still reading.
+type uploadLimitReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  int32
+}
+
+func (l *uploadLimitReader) Read(p []byte) (int, error) {
+	if l.limitExceeded() {
+		return 0, errUploadTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		atomic.StoreInt32(&l.exceeded, 1)
+		return int(l.remaining), errUploadTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func (l *uploadLimitReader) limitExceeded() bool {
+	return atomic.LoadInt32(&l.exceeded) == 1
+}
+
 func (h *PackageHandler) RegisterRoutes(router *mux.Router) {
 	router.Path(PackageCreateEndpoint).Methods("POST").HandlerFunc(h.packageCreateHandler)
 	router.Path(PackageUploadEndpoint).Methods("POST").HandlerFunc(h.packageUploadHandler)
+	router.Path(PackagePatchEndpoint).Methods("PATCH").HandlerFunc(h.packagePatchHandler)
 }
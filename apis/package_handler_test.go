@@ -2,19 +2,23 @@ package apis_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 
+	"github.com/go-http-utils/headers"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	. "github.com/onsi/ginkgo"
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/rest"
@@ -36,6 +40,7 @@ var _ = Describe("PackageHandler", func() {
 		makePostRequest := func(body string) {
 			req, err := http.NewRequest("POST", "/v3/packages", strings.NewReader(body))
 			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
 
 			router.ServeHTTP(rr, req)
 		}
@@ -82,10 +87,12 @@ var _ = Describe("PackageHandler", func() {
 				*serverURL,
 				packageRepo,
 				appRepo,
+				new(fake.FingerprintRepositoryProvider),
 				clientBuilder.Spy,
 				nil, nil,
 				&rest.Config{},
 				"", "",
+				0, 0,
 			)
 			apiHandler.RegisterRoutes(router)
 		})
@@ -104,8 +111,10 @@ var _ = Describe("PackageHandler", func() {
 				Expect(contentTypeHeader).To(Equal(jsonHeader), "Matching Content-Type header:")
 			})
 
-			It("configures the client", func() {
+			It("configures the client with the request's authorization header", func() {
 				Expect(clientBuilder.CallCount()).To(Equal(1))
+				_, actualAuthHeader := clientBuilder.ArgsForCall(0)
+				Expect(actualAuthHeader).To(Equal(authHeader))
 			})
 
 			It("creates a CFPackage", func() {
@@ -191,10 +200,32 @@ var _ = Describe("PackageHandler", func() {
 			itDoesntCreateAPackage()
 		})
 
+		When("the package is a docker image", func() {
+			BeforeEach(func() {
+				makePostRequest(`{"type": "docker", "data": {"image": "registry.example.org/team/app:v1"}, "relationships": {"app": {"data": {"guid": "` + appGUID + `"}}}}`)
+			})
+
+			It("returns an error, as docker lifecycles are not supported", func() {
+				expectUnprocessableEntityError("Feature Disabled: diego_docker")
+			})
+			itDoesntCreateAPackage()
+		})
+
+		When("a bits package has data", func() {
+			BeforeEach(func() {
+				makePostRequest(`{"type": "bits", "data": {"image": "nginx"}, "relationships": {"app": {"data": {"guid": "` + appGUID + `"}}}}`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Data must be empty if provided for bits packages")
+			})
+			itDoesntCreateAPackage()
+		})
+
 		When("the type is invalid", func() {
 			const (
 				bodyWithInvalidType = `{
-					"type": "docker",
+					"type": "foo",
 					"relationships": {
 						"app": {
 							"data": {
@@ -210,7 +241,7 @@ var _ = Describe("PackageHandler", func() {
 			})
 
 			It("returns an error", func() {
-				expectUnprocessableEntityError("Type must be one of ['bits']")
+				expectUnprocessableEntityError("Type must be one of [bits docker]")
 			})
 		})
 
@@ -282,6 +313,18 @@ var _ = Describe("PackageHandler", func() {
 			itDoesntCreateAPackage()
 		})
 
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+				makePostRequest(validBody)
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+			itDoesntCreateAPackage()
+		})
+
 		When("creating the package in the repo errors", func() {
 			BeforeEach(func() {
 				packageRepo.CreatePackageReturns(repositories.PackageRecord{}, errors.New("boom"))
@@ -299,6 +342,8 @@ var _ = Describe("PackageHandler", func() {
 			packageRepo       *fake.CFPackageRepository
 			appRepo           *fake.CFAppRepository
 			uploadImageSource *fake.SourceImageUploader
+			fingerprintRepo   *fake.CFFingerprintRepository
+			fingerprintRepos  *fake.FingerprintRepositoryProvider
 			buildRegistryAuth *fake.RegistryAuthBuilder
 			credentialOption  remote.Option
 			clientBuilder     *fake.ClientBuilder
@@ -330,6 +375,8 @@ var _ = Describe("PackageHandler", func() {
 			srcFileContents            = "the-src-file-contents"
 			packageRegistryBase        = "some-org"
 			packageImagePullSecretName = "package-image-pull-secret"
+			maxUploadSizeMB            = 1
+			maxConcurrentUploads       = 1
 		)
 
 		BeforeEach(func() {
@@ -356,6 +403,19 @@ var _ = Describe("PackageHandler", func() {
 			uploadImageSource = new(fake.SourceImageUploader)
 			uploadImageSource.Returns(imageRefWithDigest, nil)
 
+			fingerprintRepo = new(fake.CFFingerprintRepository)
+			fingerprintRepo.MatchResourcesStub = func(resources []repositories.ResourceRecord) ([]repositories.ResourceRecord, error) {
+				return resources, nil
+			}
+			fingerprintRepo.ReadPackageAsTarStub = func(src io.Reader, _ []repositories.ResourceRecord, _ string) io.ReadCloser {
+				if src == nil {
+					return io.NopCloser(strings.NewReader(""))
+				}
+				return io.NopCloser(src)
+			}
+			fingerprintRepos = new(fake.FingerprintRepositoryProvider)
+			fingerprintRepos.FingerprintRepoForRequestReturns(fingerprintRepo, nil)
+
 			appRepo = new(fake.CFAppRepository)
 			clientBuilder = new(fake.ClientBuilder)
 			credentialOption = remote.WithUserAgent("for-test-use-only") // real one should have credentials
@@ -367,12 +427,15 @@ var _ = Describe("PackageHandler", func() {
 				*serverURL,
 				packageRepo,
 				appRepo,
+				fingerprintRepos,
 				clientBuilder.Spy,
 				uploadImageSource.Spy,
 				buildRegistryAuth.Spy,
 				&rest.Config{},
 				packageRegistryBase,
 				packageImagePullSecretName,
+				maxUploadSizeMB,
+				maxConcurrentUploads,
 			)
 
 			apiHandler.RegisterRoutes(router)
@@ -405,14 +468,22 @@ var _ = Describe("PackageHandler", func() {
 
 			It("uploads the image source", func() {
 				Expect(uploadImageSource.CallCount()).To(Equal(1))
-				imageRef, srcFile, actualCredentialOption := uploadImageSource.ArgsForCall(0)
+				imageRef, sourceTar, actualCredentialOption := uploadImageSource.ArgsForCall(0)
 				Expect(imageRef).To(Equal(fmt.Sprintf("%s/%s", packageRegistryBase, packageGUID)))
-				actualSrcContents, err := io.ReadAll(srcFile)
+				actualSrcContents, err := io.ReadAll(sourceTar)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(string(actualSrcContents)).To(Equal(srcFileContents))
 				Expect(actualCredentialOption).NotTo(BeNil())
 			})
 
+			It("converts the bits with the fingerprints of the user of the request", func() {
+				Expect(fingerprintRepos.FingerprintRepoForRequestCallCount()).To(Equal(1))
+				Expect(fingerprintRepo.ReadPackageAsTarCallCount()).To(Equal(1))
+				_, cachedResources, basePath := fingerprintRepo.ReadPackageAsTarArgsForCall(0)
+				Expect(cachedResources).To(BeEmpty())
+				Expect(basePath).To(Equal("/"))
+			})
+
 			It("saves the uploaded image reference on the package", func() {
 				Expect(packageRepo.UpdatePackageSourceCallCount()).To(Equal(1))
 				_, _, message := packageRepo.UpdatePackageSourceArgsForCall(0)
@@ -502,6 +573,42 @@ var _ = Describe("PackageHandler", func() {
 			itDoesntUpdateAnyPackages()
 		})
 
+		When("the authorization header is not valid", func() {
+			var body *countingReader
+
+			BeforeEach(func() {
+				clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+
+				body = &countingReader{r: strings.NewReader("--boundary\r\n")}
+				req, err := http.NewRequest("POST", fmt.Sprintf("/v3/packages/%s/upload", packageGUID), body)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Content-Type", "multipart/form-data; boundary=boundary")
+
+				router.ServeHTTP(rr, req)
+			})
+
+			It("returns an unauthorized error without reading the upload", func() {
+				expectUnauthorizedError()
+				Expect(body.read).To(BeZero())
+			})
+			itDoesntBuildAnImageFromSource()
+			itDoesntUpdateAnyPackages()
+		})
+
+		When("getting the fingerprints of the user is unauthorized", func() {
+			BeforeEach(func() {
+				fingerprintRepos.FingerprintRepoForRequestReturns(nil, authorization.UnauthorizedErr{})
+
+				makeUploadRequest(packageGUID, strings.NewReader("the-zip-contents"))
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+			itDoesntBuildAnImageFromSource()
+			itDoesntUpdateAnyPackages()
+		})
+
 		When("fetching the package errors", func() {
 			BeforeEach(func() {
 				packageRepo.FetchPackageReturns(repositories.PackageRecord{}, errors.New("boom"))
@@ -516,6 +623,109 @@ var _ = Describe("PackageHandler", func() {
 			itDoesntUpdateAnyPackages()
 		})
 
+		When("the upload includes cached resources", func() {
+			var resources string
+
+			makeResourcesUploadRequest := func(withBits bool) {
+				var b bytes.Buffer
+				writer := multipart.NewWriter(&b)
+				Expect(writer.WriteField("resources", resources)).To(Succeed())
+				if withBits {
+					part, err := writer.CreateFormFile("bits", "unused.zip")
+					Expect(err).NotTo(HaveOccurred())
+					_, err = part.Write([]byte(srcFileContents))
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(writer.Close()).To(Succeed())
+
+				req, err := http.NewRequest("POST", fmt.Sprintf("/v3/packages/%s/upload", packageGUID), &b)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Content-Type", writer.FormDataContentType())
+
+				router.ServeHTTP(rr, req)
+			}
+
+			BeforeEach(func() {
+				resources = `[
+					{"checksum": {"value": "B907173290DB6A155949AB4DC9B2D019DEA0C901"}, "size_in_bytes": 123456, "path": "lib/big.jar", "mode": "644"}
+				]`
+			})
+
+			It("merges the cached resources into the package", func() {
+				makeResourcesUploadRequest(true)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				expectedResources := []repositories.ResourceRecord{
+					{SHA1: "b907173290db6a155949ab4dc9b2d019dea0c901", SizeInBytes: 123456, Path: "lib/big.jar", Mode: "644"},
+				}
+				Expect(fingerprintRepo.MatchResourcesArgsForCall(0)).To(Equal(expectedResources))
+
+				srcFile, cachedResources, _ := fingerprintRepo.ReadPackageAsTarArgsForCall(0)
+				Expect(srcFile).NotTo(BeNil())
+				Expect(cachedResources).To(Equal(expectedResources))
+			})
+
+			It("accepts an upload without bits", func() {
+				makeResourcesUploadRequest(false)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				srcFile, cachedResources, _ := fingerprintRepo.ReadPackageAsTarArgsForCall(0)
+				Expect(srcFile).To(BeNil())
+				Expect(cachedResources).To(HaveLen(1))
+			})
+
+			When("a resource is not cached", func() {
+				BeforeEach(func() {
+					fingerprintRepo.MatchResourcesStub = nil
+					fingerprintRepo.MatchResourcesReturns([]repositories.ResourceRecord{}, nil)
+					makeResourcesUploadRequest(true)
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError(`Resource "lib/big.jar" with checksum b907173290db6a155949ab4dc9b2d019dea0c901 was not found`)
+				})
+				itDoesntBuildAnImageFromSource()
+				itDoesntUpdateAnyPackages()
+			})
+
+			When("matching the resources errors", func() {
+				BeforeEach(func() {
+					fingerprintRepo.MatchResourcesStub = nil
+					fingerprintRepo.MatchResourcesReturns(nil, errors.New("boom"))
+					makeResourcesUploadRequest(true)
+				})
+
+				It("returns an error", func() {
+					expectUnknownError()
+				})
+				itDoesntBuildAnImageFromSource()
+			})
+
+			When("the resources are not a JSON array", func() {
+				BeforeEach(func() {
+					resources = `{"resources": []}`
+					makeResourcesUploadRequest(true)
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Resources must be a JSON array of resource matches")
+				})
+				itDoesntBuildAnImageFromSource()
+			})
+
+			When("a resource is invalid", func() {
+				BeforeEach(func() {
+					resources = `[{"checksum": {"value": "not-a-sha"}, "size_in_bytes": 1, "path": "file"}]`
+					makeResourcesUploadRequest(true)
+				})
+
+				It("returns an error", func() {
+					expectUnprocessableEntityError("Value must be 40 characters in length")
+				})
+				itDoesntBuildAnImageFromSource()
+			})
+		})
+
 		When("no bits file is given", func() {
 			BeforeEach(func() {
 				var b bytes.Buffer
@@ -530,7 +740,7 @@ var _ = Describe("PackageHandler", func() {
 			})
 
 			It("returns an error", func() {
-				expectUnprocessableEntityError("Upload must include bits")
+				expectUnprocessableEntityError("Upload must include either resources or bits")
 			})
 			itDoesntBuildAnImageFromSource()
 			itDoesntUpdateAnyPackages()
@@ -563,6 +773,103 @@ var _ = Describe("PackageHandler", func() {
 			itDoesntUpdateAnyPackages()
 		})
 
+		When("the upload is larger than the maximum size", func() {
+			BeforeEach(func() {
+				makeUploadRequest(packageGUID, bytes.NewReader(make([]byte, 2*1024*1024)))
+			})
+
+			It("returns an error", func() {
+				expectJSONResponse(http.StatusUnprocessableEntity, `{
+					"errors": [
+						{
+							"title": "CF-AppPackageInvalid",
+							"detail": "The app package is invalid: Package may not be larger than 1MB",
+							"code": 150001
+						}
+					]
+				}`)
+			})
+			itDoesntBuildAnImageFromSource()
+			itDoesntUpdateAnyPackages()
+		})
+
+		When("an upload of unknown length turns out larger than the maximum size", func() {
+			BeforeEach(func() {
+				uploadImageSource.Stub = func(_ string, sourceTar io.Reader, _ remote.Option) (string, error) {
+					_, err := io.Copy(io.Discard, sourceTar)
+					return "", err
+				}
+
+				var b bytes.Buffer
+				writer := multipart.NewWriter(&b)
+				part, err := writer.CreateFormFile("bits", "unused.zip")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = part.Write(make([]byte, 2*1024*1024))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(writer.Close()).To(Succeed())
+
+				req, err := http.NewRequest("POST", fmt.Sprintf("/v3/packages/%s/upload", packageGUID), io.MultiReader(&b))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Content-Type", writer.FormDataContentType())
+				Expect(req.ContentLength).To(BeZero())
+
+				router.ServeHTTP(rr, req)
+			})
+
+			It("stops reading it and returns an error", func() {
+				Expect(rr.Code).To(Equal(http.StatusUnprocessableEntity))
+				Expect(rr.Body.String()).To(ContainSubstring("CF-AppPackageInvalid"))
+			})
+			itDoesntUpdateAnyPackages()
+		})
+
+		When("as many uploads as allowed are already in progress", func() {
+			var releaseUpload chan struct{}
+
+			newUploadRequest := func(ctx context.Context) *http.Request {
+				var b bytes.Buffer
+				writer := multipart.NewWriter(&b)
+				_, err := writer.CreateFormFile("bits", "unused.zip")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(writer.Close()).To(Succeed())
+
+				req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("/v3/packages/%s/upload", packageGUID), &b)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Add("Content-Type", writer.FormDataContentType())
+				return req
+			}
+
+			BeforeEach(func() {
+				releaseUpload = make(chan struct{})
+				uploadStarted := make(chan struct{})
+				uploadImageSource.Stub = func(string, io.Reader, remote.Option) (string, error) {
+					close(uploadStarted)
+					<-releaseUpload
+					return imageRefWithDigest, nil
+				}
+
+				firstReq := newUploadRequest(context.Background())
+				go func() {
+					defer GinkgoRecover()
+					router.ServeHTTP(httptest.NewRecorder(), firstReq)
+				}()
+				Eventually(uploadStarted).Should(BeClosed())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				router.ServeHTTP(rr, newUploadRequest(ctx))
+			})
+
+			AfterEach(func() {
+				close(releaseUpload)
+			})
+
+			It("waits for one of them to finish before uploading", func() {
+				expectUnknownError()
+				Expect(uploadImageSource.CallCount()).To(Equal(1))
+			})
+		})
+
 		When("updating the package source registry errors", func() {
 			BeforeEach(func() {
 				packageRepo.UpdatePackageSourceReturns(repositories.PackageRecord{}, errors.New("boom"))
@@ -610,4 +917,107 @@ var _ = Describe("PackageHandler", func() {
 			})
 		})
 	})
+
+	Describe("the PATCH /v3/packages/:guid endpoint", func() {
+		const (
+			packageGUID = "the-package-guid"
+			spaceGUID   = "the-space-guid"
+		)
+
+		var (
+			packageRepo *fake.CFPackageRepository
+			body        string
+		)
+
+		BeforeEach(func() {
+			packageRepo = new(fake.CFPackageRepository)
+			packageRepo.FetchPackageReturns(repositories.PackageRecord{GUID: packageGUID, SpaceGUID: spaceGUID}, nil)
+			packageRepo.PatchPackageMetadataReturns(repositories.PackageRecord{
+				GUID:      packageGUID,
+				SpaceGUID: spaceGUID,
+				Type:      "bits",
+				State:     "READY",
+				Labels:    map[string]string{"env": "prod"},
+			}, nil)
+
+			apiHandler := NewPackageHandler(
+				logf.Log.WithName(testPackageHandlerLoggerName),
+				*serverURL,
+				packageRepo,
+				new(fake.CFAppRepository),
+				new(fake.FingerprintRepositoryProvider),
+				new(fake.ClientBuilder).Spy,
+				nil, nil,
+				&rest.Config{},
+				"", "",
+				0, 0,
+			)
+			apiHandler.RegisterRoutes(router)
+
+			body = `{"metadata": {"labels": {"env": "prod"}}}`
+		})
+
+		JustBeforeEach(func() {
+			req, err := http.NewRequest("PATCH", "/v3/packages/"+packageGUID, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
+			router.ServeHTTP(rr, req)
+		})
+
+		It("patches the package metadata in the space of the package", func() {
+			Expect(packageRepo.PatchPackageMetadataCallCount()).To(Equal(1))
+			_, _, message := packageRepo.PatchPackageMetadataArgsForCall(0)
+			Expect(message.GUID).To(Equal(packageGUID))
+			Expect(message.SpaceGUID).To(Equal(spaceGUID))
+			Expect(*message.Labels["env"]).To(Equal("prod"))
+		})
+
+		It("responds with the patched package", func() {
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(ContainSubstring(`"labels":{"env":"prod"}`))
+		})
+
+		When("the metadata is invalid", func() {
+			BeforeEach(func() {
+				body = `{"metadata": {"labels": {"a/b/c": "prod"}}}`
+			})
+
+			It("returns an error without patching the package", func() {
+				expectUnprocessableEntityError("Metadata label key error: key has more than one '/'")
+				Expect(packageRepo.PatchPackageMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the package cannot be found", func() {
+			BeforeEach(func() {
+				packageRepo.FetchPackageReturns(repositories.PackageRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Package not found")
+			})
+		})
+
+		When("patching the package fails", func() {
+			BeforeEach(func() {
+				packageRepo.PatchPackageMetadataReturns(repositories.PackageRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
 })
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
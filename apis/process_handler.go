@@ -3,50 +3,66 @@ package apis
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"net/url"
+	"strconv"
 
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 
+	"github.com/go-http-utils/headers"
 	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	ProcessGetEndpoint         = "/v3/processes/{guid}"
+	ProcessPatchEndpoint       = "/v3/processes/{guid}"
 	ProcessGetSidecarsEndpoint = "/v3/processes/{guid}/sidecars"
+	ProcessScaleEndpoint       = "/v3/processes/{guid}/actions/scale"
+	ProcessStatsEndpoint       = "/v3/processes/{guid}/stats"
+	ProcessInstanceEndpoint    = "/v3/processes/{guid}/instances/{index}"
 )
 
 //counterfeiter:generate -o fake -fake-name CFProcessRepository . CFProcessRepository
 type CFProcessRepository interface {
 	FetchProcess(context.Context, client.Client, string) (repositories.ProcessRecord, error)
-	FetchProcessesForApp(context.Context, client.Client, string, string) ([]repositories.ProcessRecord, error)
+	FetchProcessesForApp(context.Context, client.Client, string, string, labels.Selector) ([]repositories.ProcessRecord, error)
+	ScaleProcess(context.Context, client.Client, repositories.ProcessScaleMessage) (repositories.ProcessRecord, error)
+	PatchProcess(context.Context, client.Client, repositories.ProcessPatchMessage) (repositories.ProcessRecord, error)
+	CreateProcess(context.Context, client.Client, repositories.ProcessCreateMessage) (repositories.ProcessRecord, error)
+	FetchProcessStats(context.Context, client.Client, repositories.ProcessRecord) ([]repositories.ProcessInstanceStatsRecord, error)
+	TerminateProcessInstance(context.Context, client.Client, repositories.ProcessRecord, int) error
 }
 
 type ProcessHandler struct {
-	logger      logr.Logger
-	serverURL   url.URL
-	processRepo CFProcessRepository
-	buildClient ClientBuilder
-	k8sConfig   *rest.Config // TODO: this would be global for all requests, not what we want
+	logger       logr.Logger
+	serverURL    url.URL
+	processRepo  CFProcessRepository
+	revisionRepo CFRevisionRepository
+	buildClient  ClientBuilder
+	k8sConfig    *rest.Config
 }
 
 func NewProcessHandler(
 	logger logr.Logger,
 	serverURL url.URL,
 	processRepo CFProcessRepository,
+	revisionRepo CFRevisionRepository,
 	buildClient ClientBuilder,
 	k8sConfig *rest.Config) *ProcessHandler {
 	return &ProcessHandler{
-		logger:      logger,
-		serverURL:   serverURL,
-		processRepo: processRepo,
-		buildClient: buildClient,
-		k8sConfig:   k8sConfig,
+		logger:       logger,
+		serverURL:    serverURL,
+		processRepo:  processRepo,
+		revisionRepo: revisionRepo,
+		buildClient:  buildClient,
+		k8sConfig:    k8sConfig,
 	}
 }
 
@@ -57,10 +73,9 @@ func (h *ProcessHandler) processGetHandler(w http.ResponseWriter, r *http.Reques
 	vars := mux.Vars(r)
 	processGUID := vars["guid"]
 
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "ProcessGUID", processGUID)
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err, "ProcessGUID", processGUID)
 		return
 	}
 
@@ -87,12 +102,16 @@ func (h *ProcessHandler) processGetSidecarsHandler(w http.ResponseWriter, r *htt
 	vars := mux.Vars(r)
 	processGUID := vars["guid"]
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	pageRequest, err := parsePageRequest(r)
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client", "ProcessGUID", processGUID)
-		writeUnknownErrorResponse(w)
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "ProcessGUID", processGUID)
 		return
 	}
 
@@ -102,21 +121,153 @@ func (h *ProcessHandler) processGetSidecarsHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	w.Write([]byte(fmt.Sprintf(`{
-					"pagination": {
-						"total_results": 0,
-						"total_pages": 1,
-						"first": {
-							"href": "%[1]s/v3/processes/%[2]s/sidecars?page=1"
-						},
-						"last": {
-							"href": "%[1]s/v3/processes/%[2]s/sidecars?page=1"
-						},
-						"next": null,
-						"previous": null
-					},
-					"resources": []
-				}`, h.serverURL.String(), processGUID)))
+	// sidecars are not supported, so no process has any
+	listPage := newListPage(r, pageRequest, 0)
+	responseBody, err := json.Marshal(presenter.ForProcessSidecarList(h.serverURL, processGUID, listPage))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "ProcessGUID", processGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *ProcessHandler) processPatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	processGUID := vars["guid"]
+
+	var payload payloads.ProcessPatch
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "ProcessGUID", processGUID)
+		return
+	}
+
+	process, err := h.processRepo.FetchProcess(ctx, client, processGUID)
+	if err != nil {
+		h.LogError(w, processGUID, err)
+		return
+	}
+
+	process, err = h.processRepo.PatchProcess(ctx, client, payload.ToMessage(process.GUID, process.SpaceGUID))
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Process not found", "ProcessGUID", processGUID)
+			writeNotFoundErrorResponse(w, "Process")
+			return
+		}
+		h.logger.Error(err, "Failed to patch process", "ProcessGUID", processGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+	if payload.Command != nil {
+		recordRevision(ctx, h.logger, h.revisionRepo, client, process.AppGUID, process.SpaceGUID)
+	}
+
+	responseBody, err := json.Marshal(presenter.ForProcess(process, h.serverURL))
+	if err != nil {
+		h.logger.Error(err, "Failed to render response", "ProcessGUID", processGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *ProcessHandler) processScaleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	processGUID := vars["guid"]
+
+	var payload payloads.ProcessScale
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "ProcessGUID", processGUID)
+		return
+	}
+
+	process, err := h.processRepo.FetchProcess(ctx, client, processGUID)
+	if err != nil {
+		h.LogError(w, processGUID, err)
+		return
+	}
+
+	process, err = h.processRepo.ScaleProcess(ctx, client, payload.ToMessage(process.GUID, process.SpaceGUID))
+	if err != nil {
+		writeScaleProcessError(h.logger, w, processGUID, err)
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForProcess(process, h.serverURL))
+	if err != nil {
+		h.logger.Error(err, "Failed to render response", "ProcessGUID", processGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *ProcessHandler) processStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	processGUID := vars["guid"]
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "ProcessGUID", processGUID)
+		return
+	}
+
+	process, err := h.processRepo.FetchProcess(ctx, client, processGUID)
+	if err != nil {
+		h.LogError(w, processGUID, err)
+		return
+	}
+
+	writeProcessStats(ctx, h.logger, w, h.processRepo, client, process)
+}
+
+func (h *ProcessHandler) processTerminateInstanceHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	processGUID := vars["guid"]
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err, "ProcessGUID", processGUID)
+		return
+	}
+
+	process, err := h.processRepo.FetchProcess(ctx, client, processGUID)
+	if err != nil {
+		h.LogError(w, processGUID, err)
+		return
+	}
+
+	terminateProcessInstance(ctx, h.logger, w, h.processRepo, client, process, vars["index"])
 }
 
 func (h *ProcessHandler) LogError(w http.ResponseWriter, processGUID string, err error) {
@@ -132,5 +283,66 @@ func (h *ProcessHandler) LogError(w http.ResponseWriter, processGUID string, err
 
 func (h *ProcessHandler) RegisterRoutes(router *mux.Router) {
 	router.Path(ProcessGetEndpoint).Methods("GET").HandlerFunc(h.processGetHandler)
+	router.Path(ProcessPatchEndpoint).Methods("PATCH").HandlerFunc(h.processPatchHandler)
 	router.Path(ProcessGetSidecarsEndpoint).Methods("GET").HandlerFunc(h.processGetSidecarsHandler)
+	router.Path(ProcessScaleEndpoint).Methods("POST").HandlerFunc(h.processScaleHandler)
+	router.Path(ProcessStatsEndpoint).Methods("GET").HandlerFunc(h.processStatsHandler)
+	router.Path(ProcessInstanceEndpoint).Methods("DELETE").HandlerFunc(h.processTerminateInstanceHandler)
+}
+
+// terminateProcessInstance terminates the instance of process with the given index, which is shared by the process
+// and app process instance endpoints. Indexes that are not below the number of instances of the process are not found.
+func terminateProcessInstance(ctx context.Context, logger logr.Logger, w http.ResponseWriter, processRepo CFProcessRepository, client client.Client, process repositories.ProcessRecord, indexParam string) {
+	index, err := strconv.Atoi(indexParam)
+	if err != nil || index < 0 || index >= process.Instances {
+		logger.Info("Instance not found", "ProcessGUID", process.GUID, "Index", indexParam)
+		writeNotFoundErrorResponse(w, "Instance")
+		return
+	}
+
+	err = processRepo.TerminateProcessInstance(ctx, client, process, index)
+	if err != nil {
+		logger.Error(err, "Failed to terminate process instance", "ProcessGUID", process.GUID, "Index", index)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeProcessStats writes the stats of the instances of process, which are shared by the process and app process
+// stats endpoints
+func writeProcessStats(ctx context.Context, logger logr.Logger, w http.ResponseWriter, processRepo CFProcessRepository, client client.Client, process repositories.ProcessRecord) {
+	stats, err := processRepo.FetchProcessStats(ctx, client, process)
+	if err != nil {
+		logger.Error(err, "Failed to fetch process stats", "ProcessGUID", process.GUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForProcessStats(stats))
+	if err != nil {
+		logger.Error(err, "Failed to render response", "ProcessGUID", process.GUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+// writeScaleProcessError writes the response to a failure to scale a process, which is shared by the process and
+// app scale endpoints
+func writeScaleProcessError(logger logr.Logger, w http.ResponseWriter, processGUID string, err error) {
+	quotaErr := new(repositories.QuotaExceededError)
+	switch {
+	case errors.As(err, new(repositories.NotFoundError)):
+		logger.Info("Process not found", "ProcessGUID", processGUID)
+		writeNotFoundErrorResponse(w, "Process")
+	case errors.As(err, quotaErr):
+		logger.Info("Process scale exceeds quota", "ProcessGUID", processGUID, "Reason", quotaErr.Reason)
+		writeUnprocessableEntityError(w, quotaErr.Reason)
+	default:
+		logger.Error(err, "Failed to scale process", "ProcessGUID", processGUID)
+		writeUnknownErrorResponse(w)
+	}
 }
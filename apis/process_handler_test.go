@@ -1,14 +1,19 @@
 package apis_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
+	"github.com/go-http-utils/headers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/rest"
@@ -22,17 +27,20 @@ var _ = Describe("ProcessHandler", func() {
 
 	var (
 		processRepo   *fake.CFProcessRepository
+		revisionRepo  *fake.CFRevisionRepository
 		clientBuilder *fake.ClientBuilder
 	)
 
 	BeforeEach(func() {
 		processRepo = new(fake.CFProcessRepository)
+		revisionRepo = new(fake.CFRevisionRepository)
 		clientBuilder = new(fake.ClientBuilder)
 
 		apiHandler := NewProcessHandler(
 			logf.Log.WithName(testAppHandlerLoggerName),
 			*serverURL,
 			processRepo,
+			revisionRepo,
 			clientBuilder.Spy,
 			&rest.Config{},
 		)
@@ -88,6 +96,7 @@ var _ = Describe("ProcessHandler", func() {
 			var err error
 			req, err = http.NewRequest("GET", "/v3/processes/"+processGUID, nil)
 			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
 		})
 
 		When("on the happy path", func() {
@@ -95,6 +104,12 @@ var _ = Describe("ProcessHandler", func() {
 				Expect(rr.Code).To(Equal(http.StatusOK), "Matching HTTP response code:")
 			})
 
+			It("configures the client with the request's authorization header", func() {
+				Expect(clientBuilder.CallCount()).To(Equal(1))
+				_, actualAuthHeader := clientBuilder.ArgsForCall(0)
+				Expect(actualAuthHeader).To(Equal(authHeader))
+			})
+
 			It("returns a process", func() {
 				contentTypeHeader := rr.Header().Get("Content-Type")
 				Expect(contentTypeHeader).To(Equal(jsonHeader), "Matching Content-Type header:")
@@ -159,6 +174,16 @@ var _ = Describe("ProcessHandler", func() {
 				})
 			})
 
+			When("the authorization header is not valid", func() {
+				BeforeEach(func() {
+					clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+				})
+
+				It("returns an unauthorized error", func() {
+					expectUnauthorizedError()
+				})
+			})
+
 			When("there is some other error fetching the process", func() {
 				BeforeEach(func() {
 					processRepo.FetchProcessReturns(repositories.ProcessRecord{}, errors.New("unknown!"))
@@ -173,7 +198,7 @@ var _ = Describe("ProcessHandler", func() {
 
 	Describe("the GET /v3/processes/:guid/sidecars endpoint", func() {
 		BeforeEach(func() {
-			processRepo.FetchProcessReturns(repositories.ProcessRecord{}, nil)
+			processRepo.FetchProcessReturns(repositories.ProcessRecord{GUID: processGUID, SpaceGUID: "space-guid", AppGUID: "app-guid", Type: "web"}, nil)
 
 			var err error
 			req, err = http.NewRequest("GET", "/v3/processes/"+processGUID+"/sidecars", nil)
@@ -181,11 +206,8 @@ var _ = Describe("ProcessHandler", func() {
 		})
 
 		When("on the happy path", func() {
-			It("returns status 200 OK", func() {
+			It("returns an empty list, as sidecars are not supported", func() {
 				Expect(rr.Code).To(Equal(http.StatusOK), "Matching HTTP response code:")
-			})
-
-			It("returns a canned response with the processGUID in it", func() {
 				contentTypeHeader := rr.Header().Get("Content-Type")
 				Expect(contentTypeHeader).To(Equal(jsonHeader), "Matching Content-Type header:")
 
@@ -194,10 +216,10 @@ var _ = Describe("ProcessHandler", func() {
 						"total_results": 0,
 						"total_pages": 1,
 						"first": {
-							"href": "%[1]s/v3/processes/%[2]s/sidecars?page=1"
+							"href": "%[1]s/v3/processes/%[2]s/sidecars?page=1&per_page=50"
 						},
 						"last": {
-							"href": "%[1]s/v3/processes/%[2]s/sidecars?page=1"
+							"href": "%[1]s/v3/processes/%[2]s/sidecars?page=1&per_page=50"
 						},
 						"next": null,
 						"previous": null
@@ -229,4 +251,486 @@ var _ = Describe("ProcessHandler", func() {
 			})
 		})
 	})
+
+	Describe("the PATCH /v3/processes/:guid endpoint", func() {
+		const spaceGUID = "space-guid"
+
+		BeforeEach(func() {
+			processRepo.FetchProcessReturns(repositories.ProcessRecord{GUID: processGUID, SpaceGUID: spaceGUID}, nil)
+			processRepo.PatchProcessReturns(repositories.ProcessRecord{
+				GUID:      processGUID,
+				SpaceGUID: spaceGUID,
+				AppGUID:   "app-guid",
+				Type:      "web",
+				Command:   "bundle exec rackup",
+				HealthCheck: repositories.HealthCheck{
+					Type: "http",
+					Data: repositories.HealthCheckData{
+						HTTPEndpoint:             "/healthz",
+						InvocationTimeoutSeconds: 5,
+						TimeoutSeconds:           60,
+					},
+				},
+			}, nil)
+		})
+
+		makePatchRequest := func(body string) {
+			var err error
+			req, err = http.NewRequest("PATCH", "/v3/processes/"+processGUID, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		When("on the happy path", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{
+					"command": "bundle exec rackup",
+					"health_check": {
+						"type": "http",
+						"data": { "endpoint": "/healthz", "timeout": 60, "invocation_timeout": 5 }
+					}
+				}`)
+			})
+
+			It("patches the process in its space", func() {
+				Expect(processRepo.PatchProcessCallCount()).To(Equal(1))
+				_, _, message := processRepo.PatchProcessArgsForCall(0)
+				Expect(message.ProcessGUID).To(Equal(processGUID))
+				Expect(message.SpaceGUID).To(Equal(spaceGUID))
+				Expect(*message.Command).To(Equal("bundle exec rackup"))
+				Expect(*message.HealthCheckType).To(Equal("http"))
+				Expect(*message.HealthCheckHTTPEndpoint).To(Equal("/healthz"))
+				Expect(*message.HealthCheckTimeoutSeconds).To(BeEquivalentTo(60))
+				Expect(*message.HealthCheckInvocationTimeoutSeconds).To(BeEquivalentTo(5))
+			})
+
+			It("records a revision of the app of the process", func() {
+				Expect(revisionRepo.RecordRevisionCallCount()).To(Equal(1))
+				_, _, message := revisionRepo.RecordRevisionArgsForCall(0)
+				Expect(message).To(Equal(repositories.RevisionCreateMessage{AppGUID: "app-guid", SpaceGUID: spaceGUID}))
+			})
+
+			When("recording the revision fails", func() {
+				BeforeEach(func() {
+					revisionRepo.RecordRevisionReturns(repositories.RevisionRecord{}, errors.New("boom"))
+				})
+
+				It("returns the patched process, as it has been changed", func() {
+					Expect(rr.Code).To(Equal(http.StatusOK))
+				})
+			})
+
+			It("returns the patched process", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(rr.Header().Get("Content-Type")).To(Equal(jsonHeader))
+
+				var response map[string]interface{}
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response["command"]).To(Equal("bundle exec rackup"))
+				Expect(response["health_check"]).To(Equal(map[string]interface{}{
+					"type": "http",
+					"data": map[string]interface{}{
+						"endpoint":           "/healthz",
+						"timeout":            float64(60),
+						"invocation_timeout": float64(5),
+					},
+				}))
+			})
+		})
+
+		When("only the health check type is given", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "health_check": { "type": "process" } }`)
+			})
+
+			It("doesn't record a revision", func() {
+				Expect(revisionRepo.RecordRevisionCallCount()).To(Equal(0))
+			})
+
+			It("leaves the other fields as they are", func() {
+				_, _, message := processRepo.PatchProcessArgsForCall(0)
+				Expect(message.Command).To(BeNil())
+				Expect(*message.HealthCheckType).To(Equal("process"))
+				Expect(message.HealthCheckHTTPEndpoint).To(BeNil())
+				Expect(message.HealthCheckTimeoutSeconds).To(BeNil())
+			})
+		})
+
+		When("the health check type is invalid", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "health_check": { "type": "tcp" } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Type must be one of [port process http]")
+			})
+
+			It("doesn't patch the process", func() {
+				Expect(processRepo.PatchProcessCallCount()).To(Equal(0))
+			})
+		})
+
+		When("an endpoint is given for a health check that is not http", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "health_check": { "type": "port", "data": { "endpoint": "/healthz" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError(`Health check type must be "http" to set a health check HTTP endpoint`)
+			})
+		})
+
+		When("an endpoint is given without a health check type", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "health_check": { "data": { "endpoint": "/healthz" } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError(`Health check type must be "http" to set a health check HTTP endpoint`)
+			})
+		})
+
+		When("a timeout is not positive", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "health_check": { "type": "port", "data": { "timeout": 0 } } }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Timeout must be 1 or greater")
+			})
+		})
+
+		When("the command is empty", func() {
+			BeforeEach(func() {
+				makePatchRequest(`{ "command": "" }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Command must be at least 1 character in length")
+			})
+		})
+
+		When("the process doesn't exist", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessReturns(repositories.ProcessRecord{}, repositories.NotFoundError{})
+				makePatchRequest(`{ "command": "start" }`)
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+		})
+
+		When("patching the process fails", func() {
+			BeforeEach(func() {
+				processRepo.PatchProcessReturns(repositories.ProcessRecord{}, errors.New("boom"))
+				makePatchRequest(`{ "command": "start" }`)
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the POST /v3/processes/:guid/actions/scale endpoint", func() {
+		const spaceGUID = "space-guid"
+
+		BeforeEach(func() {
+			processRepo.FetchProcessReturns(repositories.ProcessRecord{GUID: processGUID, SpaceGUID: spaceGUID}, nil)
+			processRepo.ScaleProcessReturns(repositories.ProcessRecord{
+				GUID:        processGUID,
+				SpaceGUID:   spaceGUID,
+				Type:        "web",
+				Instances:   3,
+				MemoryMB:    512,
+				DiskQuotaMB: 2048,
+				HealthCheck: repositories.HealthCheck{Type: "port"},
+			}, nil)
+		})
+
+		makeScaleRequest := func(body string) {
+			var err error
+			req, err = http.NewRequest("POST", "/v3/processes/"+processGUID+"/actions/scale", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		When("on the happy path", func() {
+			BeforeEach(func() {
+				makeScaleRequest(`{ "instances": 3, "memory_in_mb": 512, "disk_in_mb": 2048 }`)
+			})
+
+			It("scales the process in its space", func() {
+				Expect(processRepo.ScaleProcessCallCount()).To(Equal(1))
+				_, _, message := processRepo.ScaleProcessArgsForCall(0)
+				Expect(message.GUID).To(Equal(processGUID))
+				Expect(message.SpaceGUID).To(Equal(spaceGUID))
+				Expect(*message.Instances).To(Equal(3))
+				Expect(*message.MemoryMB).To(BeEquivalentTo(512))
+				Expect(*message.DiskMB).To(BeEquivalentTo(2048))
+			})
+
+			It("returns the scaled process", func() {
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(rr.Header().Get("Content-Type")).To(Equal(jsonHeader))
+
+				var response presenter.ProcessResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.GUID).To(Equal(processGUID))
+				Expect(response.Instances).To(Equal(3))
+				Expect(response.MemoryMB).To(BeEquivalentTo(512))
+				Expect(response.DiskQuotaMB).To(BeEquivalentTo(2048))
+			})
+		})
+
+		When("only the instances are given", func() {
+			BeforeEach(func() {
+				makeScaleRequest(`{ "instances": 0 }`)
+			})
+
+			It("leaves the memory and disk as they are", func() {
+				_, _, message := processRepo.ScaleProcessArgsForCall(0)
+				Expect(*message.Instances).To(Equal(0))
+				Expect(message.MemoryMB).To(BeNil())
+				Expect(message.DiskMB).To(BeNil())
+			})
+		})
+
+		When("the instances are negative", func() {
+			BeforeEach(func() {
+				makeScaleRequest(`{ "instances": -1 }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Instances must be 0 or greater")
+			})
+
+			It("doesn't scale the process", func() {
+				Expect(processRepo.ScaleProcessCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the memory is not positive", func() {
+			BeforeEach(func() {
+				makeScaleRequest(`{ "memory_in_mb": 0 }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("MemoryMB must be greater than 0")
+			})
+		})
+
+		When("the process doesn't exist", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessReturns(repositories.ProcessRecord{}, repositories.NotFoundError{})
+				makeScaleRequest(`{ "instances": 3 }`)
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+		})
+
+		When("the new scale exceeds a quota", func() {
+			BeforeEach(func() {
+				processRepo.ScaleProcessReturns(repositories.ProcessRecord{}, repositories.QuotaExceededError{Reason: "memory space_quota_exceeded"})
+				makeScaleRequest(`{ "instances": 30 }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("memory space_quota_exceeded")
+			})
+		})
+
+		When("scaling the process fails", func() {
+			BeforeEach(func() {
+				processRepo.ScaleProcessReturns(repositories.ProcessRecord{}, errors.New("boom"))
+				makeScaleRequest(`{ "instances": 3 }`)
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/processes/:guid/stats endpoint", func() {
+		const spaceGUID = "space-guid"
+
+		BeforeEach(func() {
+			processRepo.FetchProcessReturns(repositories.ProcessRecord{GUID: processGUID, SpaceGUID: spaceGUID, Type: "web"}, nil)
+			processRepo.FetchProcessStatsReturns([]repositories.ProcessInstanceStatsRecord{
+				{
+					Type:  "web",
+					Index: 0,
+					State: repositories.ProcessInstanceRunning,
+					Usage: &repositories.ProcessInstanceUsageRecord{
+						Time:        "2021-10-12T15:00:00Z",
+						CPU:         0.25,
+						MemoryBytes: 104857600,
+					},
+					Host:           "10.0.0.1",
+					Ports:          []int32{8080},
+					UptimeSeconds:  120,
+					Restarts:       1,
+					MemQuotaBytes:  536870912,
+					DiskQuotaBytes: 1073741824,
+				},
+				{
+					Type:           "web",
+					Index:          1,
+					State:          repositories.ProcessInstanceDown,
+					Ports:          []int32{8080},
+					MemQuotaBytes:  536870912,
+					DiskQuotaBytes: 1073741824,
+				},
+			}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/processes/"+processGUID+"/stats", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("fetches the stats of the process", func() {
+				Expect(processRepo.FetchProcessStatsCallCount()).To(Equal(1))
+				_, _, process := processRepo.FetchProcessStatsArgsForCall(0)
+				Expect(process.GUID).To(Equal(processGUID))
+			})
+
+			It("returns the stats of each instance", func() {
+				expectJSONResponse(http.StatusOK, `{
+					"resources": [
+						{
+							"type": "web",
+							"index": 0,
+							"state": "RUNNING",
+							"usage": {
+								"time": "2021-10-12T15:00:00Z",
+								"cpu": 0.25,
+								"mem": 104857600
+							},
+							"host": "10.0.0.1",
+							"instance_ports": [{ "external": 8080, "internal": 8080 }],
+							"uptime": 120,
+							"mem_quota": 536870912,
+							"disk_quota": 1073741824,
+							"fds_quota": 16384,
+							"restarts": 1,
+							"details": null
+						},
+						{
+							"type": "web",
+							"index": 1,
+							"state": "DOWN",
+							"usage": {},
+							"host": "",
+							"instance_ports": [],
+							"uptime": 0,
+							"mem_quota": 536870912,
+							"disk_quota": 1073741824,
+							"fds_quota": 16384,
+							"restarts": 0,
+							"details": null
+						}
+					]
+				}`)
+			})
+		})
+
+		When("the process doesn't exist", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessReturns(repositories.ProcessRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+		})
+
+		When("fetching the stats fails", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessStatsReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the DELETE /v3/processes/:guid/instances/:index endpoint", func() {
+		BeforeEach(func() {
+			processRepo.FetchProcessReturns(repositories.ProcessRecord{GUID: processGUID, SpaceGUID: "space-guid", Instances: 2}, nil)
+		})
+
+		makeTerminateRequest := func(index string) {
+			var err error
+			req, err = http.NewRequest("DELETE", "/v3/processes/"+processGUID+"/instances/"+index, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		When("on the happy path", func() {
+			BeforeEach(func() {
+				makeTerminateRequest("1")
+			})
+
+			It("terminates the instance with the given index", func() {
+				Expect(processRepo.TerminateProcessInstanceCallCount()).To(Equal(1))
+				_, _, process, index := processRepo.TerminateProcessInstanceArgsForCall(0)
+				Expect(process.GUID).To(Equal(processGUID))
+				Expect(index).To(Equal(1))
+			})
+
+			It("returns no content", func() {
+				Expect(rr.Code).To(Equal(http.StatusNoContent))
+				Expect(rr.Body.String()).To(BeEmpty())
+			})
+		})
+
+		When("the index is not below the number of instances", func() {
+			BeforeEach(func() {
+				makeTerminateRequest("2")
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Instance not found")
+			})
+
+			It("doesn't terminate an instance", func() {
+				Expect(processRepo.TerminateProcessInstanceCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the index is not a number", func() {
+			BeforeEach(func() {
+				makeTerminateRequest("first")
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Instance not found")
+			})
+		})
+
+		When("the process doesn't exist", func() {
+			BeforeEach(func() {
+				processRepo.FetchProcessReturns(repositories.ProcessRecord{}, repositories.NotFoundError{})
+				makeTerminateRequest("0")
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Process not found")
+			})
+		})
+
+		When("terminating the instance fails", func() {
+			BeforeEach(func() {
+				processRepo.TerminateProcessInstanceReturns(errors.New("boom"))
+				makeTerminateRequest("0")
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
 })
@@ -1,8 +1,16 @@
 package apis
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+
+	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
 )
 
@@ -10,18 +18,77 @@ const (
 	ResourceMatchesEndpoint = "/v3/resource_matches"
 )
 
+//counterfeiter:generate -o fake -fake-name CFFingerprintRepository . CFFingerprintRepository
+
+type CFFingerprintRepository interface {
+	MatchResources([]repositories.ResourceRecord) ([]repositories.ResourceRecord, error)
+	ReadPackageAsTar(src io.Reader, cachedResources []repositories.ResourceRecord, basePath string) io.ReadCloser
+}
+
+//counterfeiter:generate -o fake -fake-name FingerprintRepositoryProvider . FingerprintRepositoryProvider
+
+// FingerprintRepositoryProvider returns the fingerprints of the files uploaded by the user of a request, so that users
+// only match, and have merged into their packages, the files they uploaded themselves
+type FingerprintRepositoryProvider interface {
+	FingerprintRepoForRequest(request *http.Request) (CFFingerprintRepository, error)
+}
+
 type ResourceMatchesHandler struct {
-	serverURL string
+	logger                  logr.Logger
+	serverURL               string
+	fingerprintRepoProvider FingerprintRepositoryProvider
 }
 
-func NewResourceMatchesHandler(serverURL string) *ResourceMatchesHandler {
-	return &ResourceMatchesHandler{serverURL: serverURL}
+func NewResourceMatchesHandler(
+	logger logr.Logger,
+	serverURL string,
+	fingerprintRepoProvider FingerprintRepositoryProvider) *ResourceMatchesHandler {
+	return &ResourceMatchesHandler{
+		logger:                  logger,
+		serverURL:               serverURL,
+		fingerprintRepoProvider: fingerprintRepoProvider,
+	}
 }
 
 func (h *ResourceMatchesHandler) resourceMatchesPostHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+
+	var payload payloads.ResourceMatches
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	fingerprintRepo, err := h.fingerprintRepoProvider.FingerprintRepoForRequest(r)
+	if err != nil {
+		if authorization.IsUnauthorized(err) {
+			h.logger.Error(err, "unauthorized to match resources")
+			writeUnauthorizedErrorResponse(w)
+			return
+		}
+
+		h.logger.Error(err, "failed to create fingerprint repo for the authorization header")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	matches, err := fingerprintRepo.MatchResources(payload.ToRecords())
+	if err != nil {
+		h.logger.Error(err, "Failed to match resources")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForResourceMatches(matches))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(`{"resources":[]}`))
+	w.Write(responseBody)
 }
 
 func (h *ResourceMatchesHandler) RegisterRoutes(router *mux.Router) {
@@ -1,32 +1,56 @@
 package apis_test
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
+	"github.com/go-http-utils/headers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var _ = Describe("ResourceMatchesHandler", func() {
 	Describe("Get Resource Match Endpoint", func() {
+		var (
+			fingerprintRepo         *fake.CFFingerprintRepository
+			fingerprintRepoProvider *fake.FingerprintRepositoryProvider
+		)
+
 		makePostRequest := func(body string) {
 			req, err := http.NewRequest("POST", "/v3/resource_matches", strings.NewReader(body))
 			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
 
 			router.ServeHTTP(rr, req)
 		}
 
 		BeforeEach(func() {
-			apiHandler := NewResourceMatchesHandler("foo://my-server")
+			fingerprintRepo = new(fake.CFFingerprintRepository)
+			fingerprintRepo.MatchResourcesReturns([]repositories.ResourceRecord{
+				{SHA1: "b907173290db6a155949ab4dc9b2d019dea0c901", SizeInBytes: 123456, Path: "lib/big.jar", Mode: "644"},
+			}, nil)
+			fingerprintRepoProvider = new(fake.FingerprintRepositoryProvider)
+			fingerprintRepoProvider.FingerprintRepoForRequestReturns(fingerprintRepo, nil)
+
+			apiHandler := NewResourceMatchesHandler(logf.Log.WithName("TestResourceMatchesHandler"), "foo://my-server", fingerprintRepoProvider)
 			apiHandler.RegisterRoutes(router)
 		})
 
 		When("ResourceMatchesHandler is called", func() {
 			BeforeEach(func() {
-				makePostRequest(`{}`)
+				makePostRequest(`{
+					"resources": [
+						{"checksum": {"value": "b907173290db6a155949ab4dc9b2d019dea0c901"}, "size_in_bytes": 123456, "path": "lib/big.jar", "mode": "644"},
+						{"checksum": {"value": "002d760bea1be268e27077412e11a320d0f164d3"}, "size_in_bytes": 70000, "path": "lib/new.jar", "mode": "644"}
+					]
+				}`)
 			})
 
 			It("returns status 201 Created", func() {
@@ -38,10 +62,79 @@ var _ = Describe("ResourceMatchesHandler", func() {
 				Expect(contentTypeHeader).To(Equal(jsonHeader), "Matching Content-Type header:")
 			})
 
-			It("returns a CF API formatted Error response", func() {
+			It("looks the resources up in the fingerprints of the user of the request", func() {
+				Expect(fingerprintRepoProvider.FingerprintRepoForRequestCallCount()).To(Equal(1))
+				Expect(fingerprintRepoProvider.FingerprintRepoForRequestArgsForCall(0).Header.Get(headers.Authorization)).To(Equal(authHeader))
+
+				Expect(fingerprintRepo.MatchResourcesArgsForCall(0)).To(Equal([]repositories.ResourceRecord{
+					{SHA1: "b907173290db6a155949ab4dc9b2d019dea0c901", SizeInBytes: 123456, Path: "lib/big.jar", Mode: "644"},
+					{SHA1: "002d760bea1be268e27077412e11a320d0f164d3", SizeInBytes: 70000, Path: "lib/new.jar", Mode: "644"},
+				}))
+			})
+
+			It("returns the resources that matched", func() {
 				Expect(rr.Body.String()).To(MatchJSON(`{
-				"resources": []
-			  }`), "Response body matches response:")
+					"resources": [
+						{"checksum": {"value": "b907173290db6a155949ab4dc9b2d019dea0c901"}, "size_in_bytes": 123456, "path": "lib/big.jar", "mode": "644"}
+					]
+				}`), "Response body matches response:")
+			})
+		})
+
+		When("no resources match", func() {
+			BeforeEach(func() {
+				fingerprintRepo.MatchResourcesReturns([]repositories.ResourceRecord{}, nil)
+				makePostRequest(`{"resources": []}`)
+			})
+
+			It("returns an empty list", func() {
+				Expect(rr.Code).To(Equal(http.StatusCreated))
+				Expect(rr.Body.String()).To(MatchJSON(`{"resources": []}`))
+			})
+		})
+
+		When("a resource is invalid", func() {
+			BeforeEach(func() {
+				makePostRequest(`{"resources": [{"checksum": {"value": "abc"}, "size_in_bytes": 1, "path": "file"}]}`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Value must be 40 characters in length")
+				Expect(fingerprintRepo.MatchResourcesCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				fingerprintRepoProvider.FingerprintRepoForRequestReturns(nil, authorization.UnauthorizedErr{})
+				makePostRequest(`{"resources": []}`)
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+				Expect(fingerprintRepo.MatchResourcesCallCount()).To(Equal(0))
+			})
+		})
+
+		When("getting the fingerprints of the user fails", func() {
+			BeforeEach(func() {
+				fingerprintRepoProvider.FingerprintRepoForRequestReturns(nil, errors.New("boom"))
+				makePostRequest(`{"resources": []}`)
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+
+		When("matching the resources fails", func() {
+			BeforeEach(func() {
+				fingerprintRepo.MatchResourcesReturns(nil, errors.New("boom"))
+				makePostRequest(`{"resources": []}`)
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
 			})
 		})
 	})
@@ -0,0 +1,218 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"github.com/go-http-utils/headers"
+	"github.com/go-logr/logr"
+	"github.com/gorilla/mux"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	AppRevisionListEndpoint         = "/v3/apps/{guid}/revisions"
+	AppDeployedRevisionListEndpoint = "/v3/apps/{guid}/revisions/deployed"
+	RevisionGetEndpoint             = "/v3/revisions/{guid}"
+	RevisionGetEnvVarsEndpoint      = "/v3/revisions/{guid}/environment_variables"
+)
+
+//counterfeiter:generate -o fake -fake-name CFRevisionRepository . CFRevisionRepository
+
+type CFRevisionRepository interface {
+	RecordRevision(context.Context, client.Client, repositories.RevisionCreateMessage) (repositories.RevisionRecord, error)
+	FetchRevision(context.Context, client.Client, string) (repositories.RevisionRecord, error)
+	FetchRevisionsForApp(context.Context, client.Client, string, string) ([]repositories.RevisionRecord, error)
+	FetchDeployedRevisions(context.Context, client.Client, repositories.AppRecord) ([]repositories.RevisionRecord, error)
+}
+
+type RevisionHandler struct {
+	logger       logr.Logger
+	serverURL    url.URL
+	revisionRepo CFRevisionRepository
+	appRepo      CFAppRepository
+	buildClient  ClientBuilder
+	k8sConfig    *rest.Config
+}
+
+func NewRevisionHandler(
+	logger logr.Logger,
+	serverURL url.URL,
+	revisionRepo CFRevisionRepository,
+	appRepo CFAppRepository,
+	buildClient ClientBuilder,
+	k8sConfig *rest.Config) *RevisionHandler {
+	return &RevisionHandler{
+		logger:       logger,
+		serverURL:    serverURL,
+		revisionRepo: revisionRepo,
+		appRepo:      appRepo,
+		buildClient:  buildClient,
+		k8sConfig:    k8sConfig,
+	}
+}
+
+func (h *RevisionHandler) appRevisionListHandler(w http.ResponseWriter, r *http.Request) {
+	h.listAppRevisions(w, r, func(ctx context.Context, client client.Client, app repositories.AppRecord) ([]repositories.RevisionRecord, error) {
+		return h.revisionRepo.FetchRevisionsForApp(ctx, client, app.GUID, app.SpaceGUID)
+	}, presenter.ForAppRevisionList)
+}
+
+func (h *RevisionHandler) appDeployedRevisionListHandler(w http.ResponseWriter, r *http.Request) {
+	h.listAppRevisions(w, r, h.revisionRepo.FetchDeployedRevisions, presenter.ForAppDeployedRevisionList)
+}
+
+func (h *RevisionHandler) listAppRevisions(
+	w http.ResponseWriter,
+	r *http.Request,
+	fetch func(context.Context, client.Client, repositories.AppRecord) ([]repositories.RevisionRecord, error),
+	present func([]repositories.RevisionRecord, url.URL, string, presenter.ListPage) presenter.RevisionListResponse,
+) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	appGUID := mux.Vars(r)["guid"]
+
+	if err := checkQueryParameters(r); err != nil {
+		h.logger.Info("Unknown query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	app, err := h.appRepo.FetchApp(ctx, client, appGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("App not found", "AppGUID", appGUID)
+			writeNotFoundErrorResponse(w, "App")
+			return
+		}
+		h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	revisions, err := fetch(ctx, client, app)
+	if err != nil {
+		h.logger.Error(err, "Failed to fetch revisions of app", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	start, end := pageRequest.Bounds(len(revisions))
+	listPage := newListPage(r, pageRequest, len(revisions))
+	responseBody, err := json.Marshal(present(revisions[start:end], h.serverURL, appGUID, listPage))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *RevisionHandler) revisionGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	revision, ok := h.fetchRevision(w, r, mux.Vars(r)["guid"])
+	if !ok {
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForRevision(revision, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "RevisionGUID", revision.GUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *RevisionHandler) revisionGetEnvVarsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	revision, ok := h.fetchRevision(w, r, mux.Vars(r)["guid"])
+	if !ok {
+		return
+	}
+
+	responseBody, err := json.Marshal(presenter.ForRevisionEnvVars(revision, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "RevisionGUID", revision.GUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+// fetchRevision fetches a revision with a client for the user of r. The error response has been written when ok is
+// false.
+func (h *RevisionHandler) fetchRevision(w http.ResponseWriter, r *http.Request, revisionGUID string) (repositories.RevisionRecord, bool) {
+	client, ok := h.client(w, r)
+	if !ok {
+		return repositories.RevisionRecord{}, false
+	}
+
+	revision, err := h.revisionRepo.FetchRevision(r.Context(), client, revisionGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Revision not found", "RevisionGUID", revisionGUID)
+			writeNotFoundErrorResponse(w, "Revision")
+			return repositories.RevisionRecord{}, false
+		}
+		h.logger.Error(err, "Failed to fetch revision from Kubernetes", "RevisionGUID", revisionGUID)
+		writeUnknownErrorResponse(w)
+		return repositories.RevisionRecord{}, false
+	}
+
+	return revision, true
+}
+
+func (h *RevisionHandler) client(w http.ResponseWriter, r *http.Request) (client.Client, bool) {
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (h *RevisionHandler) RegisterRoutes(router *mux.Router) {
+	router.Path(AppRevisionListEndpoint).Methods("GET").HandlerFunc(h.appRevisionListHandler)
+	router.Path(AppDeployedRevisionListEndpoint).Methods("GET").HandlerFunc(h.appDeployedRevisionListHandler)
+	router.Path(RevisionGetEndpoint).Methods("GET").HandlerFunc(h.revisionGetHandler)
+	router.Path(RevisionGetEnvVarsEndpoint).Methods("GET").HandlerFunc(h.revisionGetEnvVarsHandler)
+}
+
+// recordRevision records a revision of an app after its droplet, environment variables or process commands have
+// changed. The change has been made by then, so a revision that cannot be recorded is only logged and the request
+// succeeds. The revision recorded at the next change of the app includes the change.
+func recordRevision(ctx context.Context, logger logr.Logger, revisionRepo CFRevisionRepository, client client.Client, appGUID, spaceGUID string) {
+	_, err := revisionRepo.RecordRevision(ctx, client, repositories.RevisionCreateMessage{
+		AppGUID:   appGUID,
+		SpaceGUID: spaceGUID,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to record revision", "AppGUID", appGUID)
+	}
+}
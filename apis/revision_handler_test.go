@@ -0,0 +1,227 @@
+package apis_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("RevisionHandler", func() {
+	const (
+		appGUID      = "app-guid"
+		spaceGUID    = "space-guid"
+		revisionGUID = "revision-guid"
+	)
+
+	var (
+		revisionRepo   *fake.CFRevisionRepository
+		appRepo        *fake.CFAppRepository
+		clientBuilder  *fake.ClientBuilder
+		appRecord      repositories.AppRecord
+		revisionRecord repositories.RevisionRecord
+	)
+
+	BeforeEach(func() {
+		revisionRepo = new(fake.CFRevisionRepository)
+		appRepo = new(fake.CFAppRepository)
+		clientBuilder = new(fake.ClientBuilder)
+
+		appRecord = repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID}
+		revisionRecord = repositories.RevisionRecord{
+			GUID:                 revisionGUID,
+			Version:              2,
+			AppGUID:              appGUID,
+			SpaceGUID:            spaceGUID,
+			DropletGUID:          "droplet-guid",
+			EnvironmentVariables: map[string]string{"RAILS_ENV": "production"},
+			Processes:            map[string]string{"web": "bundle exec rackup", "worker": ""},
+			Description:          "New droplet deployed.",
+			CreatedAt:            "2021-10-12T15:00:00Z",
+			UpdatedAt:            "2021-10-12T15:00:01Z",
+		}
+		appRepo.FetchAppReturns(appRecord, nil)
+		revisionRepo.FetchRevisionReturns(revisionRecord, nil)
+
+		apiHandler := NewRevisionHandler(
+			logf.Log.WithName("TestRevisionHandler"),
+			*serverURL,
+			revisionRepo,
+			appRepo,
+			clientBuilder.Spy,
+			&rest.Config{},
+		)
+		apiHandler.RegisterRoutes(router)
+	})
+
+	JustBeforeEach(func() {
+		router.ServeHTTP(rr, req)
+	})
+
+	revisionJSON := `{
+		"guid": "revision-guid",
+		"version": 2,
+		"droplet": { "guid": "droplet-guid" },
+		"processes": {
+			"web": { "command": "bundle exec rackup" },
+			"worker": { "command": null }
+		},
+		"description": "New droplet deployed.",
+		"deployable": true,
+		"relationships": {
+			"app": { "data": { "guid": "app-guid" } }
+		},
+		"metadata": { "labels": {}, "annotations": {} },
+		"created_at": "2021-10-12T15:00:00Z",
+		"updated_at": "2021-10-12T15:00:01Z",
+		"links": {
+			"self": { "href": "https://api.example.org/v3/revisions/revision-guid" },
+			"app": { "href": "https://api.example.org/v3/apps/app-guid" },
+			"environment_variables": { "href": "https://api.example.org/v3/revisions/revision-guid/environment_variables" }
+		}
+	}`
+
+	makeGetRequest := func(path string) {
+		var err error
+		req, err = http.NewRequest("GET", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Describe("the GET /v3/apps/:guid/revisions endpoint", func() {
+		BeforeEach(func() {
+			revisionRepo.FetchRevisionsForAppReturns([]repositories.RevisionRecord{revisionRecord}, nil)
+			makeGetRequest("/v3/apps/" + appGUID + "/revisions")
+		})
+
+		It("returns the revisions of the app", func() {
+			Expect(revisionRepo.FetchRevisionsForAppCallCount()).To(Equal(1))
+			_, _, actualAppGUID, actualSpaceGUID := revisionRepo.FetchRevisionsForAppArgsForCall(0)
+			Expect(actualAppGUID).To(Equal(appGUID))
+			Expect(actualSpaceGUID).To(Equal(spaceGUID))
+
+			expectJSONResponse(http.StatusOK, `{
+				"pagination": {
+					"total_results": 1,
+					"total_pages": 1,
+					"first": { "href": "https://api.example.org/v3/apps/app-guid/revisions?page=1&per_page=50" },
+					"last": { "href": "https://api.example.org/v3/apps/app-guid/revisions?page=1&per_page=50" },
+					"next": null,
+					"previous": null
+				},
+				"resources": [`+revisionJSON+`]
+			}`)
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+
+		When("fetching the revisions fails", func() {
+			BeforeEach(func() {
+				revisionRepo.FetchRevisionsForAppReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/apps/:guid/revisions/deployed endpoint", func() {
+		BeforeEach(func() {
+			revisionRepo.FetchDeployedRevisionsReturns([]repositories.RevisionRecord{revisionRecord}, nil)
+			makeGetRequest("/v3/apps/" + appGUID + "/revisions/deployed")
+		})
+
+		It("returns the deployed revisions of the app", func() {
+			Expect(revisionRepo.FetchDeployedRevisionsCallCount()).To(Equal(1))
+			_, _, actualApp := revisionRepo.FetchDeployedRevisionsArgsForCall(0)
+			Expect(actualApp).To(Equal(appRecord))
+
+			expectJSONResponse(http.StatusOK, `{
+				"pagination": {
+					"total_results": 1,
+					"total_pages": 1,
+					"first": { "href": "https://api.example.org/v3/apps/app-guid/revisions/deployed?page=1&per_page=50" },
+					"last": { "href": "https://api.example.org/v3/apps/app-guid/revisions/deployed?page=1&per_page=50" },
+					"next": null,
+					"previous": null
+				},
+				"resources": [`+revisionJSON+`]
+			}`)
+		})
+	})
+
+	Describe("the GET /v3/revisions/:guid endpoint", func() {
+		BeforeEach(func() {
+			makeGetRequest("/v3/revisions/" + revisionGUID)
+		})
+
+		It("returns the revision", func() {
+			_, _, actualRevisionGUID := revisionRepo.FetchRevisionArgsForCall(0)
+			Expect(actualRevisionGUID).To(Equal(revisionGUID))
+
+			expectJSONResponse(http.StatusOK, revisionJSON)
+		})
+
+		When("the revision doesn't exist", func() {
+			BeforeEach(func() {
+				revisionRepo.FetchRevisionReturns(repositories.RevisionRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Revision not found")
+			})
+		})
+
+		When("fetching the revision fails", func() {
+			BeforeEach(func() {
+				revisionRepo.FetchRevisionReturns(repositories.RevisionRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/revisions/:guid/environment_variables endpoint", func() {
+		BeforeEach(func() {
+			makeGetRequest("/v3/revisions/" + revisionGUID + "/environment_variables")
+		})
+
+		It("returns the environment variables of the revision", func() {
+			expectJSONResponse(http.StatusOK, `{
+				"var": { "RAILS_ENV": "production" },
+				"links": {
+					"self": { "href": "https://api.example.org/v3/revisions/revision-guid/environment_variables" },
+					"revision": { "href": "https://api.example.org/v3/revisions/revision-guid" },
+					"app": { "href": "https://api.example.org/v3/apps/app-guid" }
+				}
+			}`)
+		})
+
+		When("the revision doesn't exist", func() {
+			BeforeEach(func() {
+				revisionRepo.FetchRevisionReturns(repositories.RevisionRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Revision not found")
+			})
+		})
+	})
+})
@@ -11,9 +11,11 @@ import (
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
 
+	"github.com/go-http-utils/headers"
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -23,21 +25,25 @@ const (
 	RouteGetListEndpoint         = "/v3/routes"
 	RouteGetDestinationsEndpoint = "/v3/routes/{guid}/destinations"
 	RouteCreateEndpoint          = "/v3/routes"
+	RoutePatchEndpoint           = "/v3/routes/{guid}"
 )
 
 //counterfeiter:generate -o fake -fake-name CFRouteRepository . CFRouteRepository
 
 type CFRouteRepository interface {
 	FetchRoute(context.Context, client.Client, string) (repositories.RouteRecord, error)
-	FetchRouteList(context.Context, client.Client) ([]repositories.RouteRecord, error)
-	FetchRoutesForApp(context.Context, client.Client, string, string) ([]repositories.RouteRecord, error)
+	FetchRouteList(context.Context, client.Client, repositories.RouteListMessage) ([]repositories.RouteRecord, int, error)
+	FetchRoutesForApp(context.Context, client.Client, string, string, labels.Selector) ([]repositories.RouteRecord, error)
 	CreateRoute(context.Context, client.Client, repositories.RouteRecord) (repositories.RouteRecord, error)
+	PatchRouteMetadata(context.Context, client.Client, repositories.MetadataPatchMessage) (repositories.RouteRecord, error)
+	AddDestinationsToRoute(context.Context, client.Client, repositories.RouteAddDestinationsMessage) (repositories.RouteRecord, error)
 }
 
 //counterfeiter:generate -o fake -fake-name CFDomainRepository . CFDomainRepository
 
 type CFDomainRepository interface {
 	FetchDomain(context.Context, client.Client, string) (repositories.DomainRecord, error)
+	FetchDomainByName(context.Context, client.Client, string) (repositories.DomainRecord, error)
 }
 
 type RouteHandler struct {
@@ -47,7 +53,7 @@ type RouteHandler struct {
 	domainRepo  CFDomainRepository
 	appRepo     CFAppRepository
 	buildClient ClientBuilder
-	k8sConfig   *rest.Config // TODO: this would be global for all requests, not what we want
+	k8sConfig   *rest.Config
 }
 
 func NewRouteHandler(
@@ -76,7 +82,13 @@ func (h *RouteHandler) routeGetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	routeGUID := vars["guid"]
 
-	route, err := h.lookupRouteAndDomain(ctx, routeGUID)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	route, err := h.lookupRouteAndDomain(ctx, client, routeGUID)
 	if err != nil {
 		switch err.(type) {
 		case repositories.NotFoundError:
@@ -104,14 +116,27 @@ func (h *RouteHandler) routeGetListHandler(w http.ResponseWriter, r *http.Reques
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	routes, err := h.lookupRouteAndDomainList(ctx)
+	routeListMessage, err := parseRouteListMessage(r)
+	if err != nil {
+		h.logger.Info("Invalid route list query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	routes, totalResults, err := h.lookupRouteAndDomainList(ctx, client, routeListMessage)
 	if err != nil {
 		h.logger.Error(err, "Failed to fetch route or domains from Kubernetes")
 		writeUnknownErrorResponse(w)
 		return
 	}
 
-	responseBody, err := json.Marshal(presenter.ForRouteList(routes, h.serverURL))
+	responseBody, err := json.Marshal(presenter.ForRouteList(routes, h.serverURL, newListPage(r, routeListMessage.Page, totalResults)))
 	if err != nil {
 		h.logger.Error(err, "Failed to render response")
 		writeUnknownErrorResponse(w)
@@ -128,7 +153,13 @@ func (h *RouteHandler) routeGetDestinationsHandler(w http.ResponseWriter, r *htt
 	vars := mux.Vars(r)
 	routeGUID := vars["guid"]
 
-	route, err := h.lookupRouteAndDomain(ctx, routeGUID)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	route, err := h.lookupRouteAndDomain(ctx, client, routeGUID)
 	if err != nil {
 		switch err.(type) {
 		case repositories.NotFoundError:
@@ -153,14 +184,7 @@ func (h *RouteHandler) routeGetDestinationsHandler(w http.ResponseWriter, r *htt
 }
 
 // Fetch Route and compose related Domain information within
-func (h *RouteHandler) lookupRouteAndDomain(ctx context.Context, routeGUID string) (repositories.RouteRecord, error) {
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
-	if err != nil {
-		return repositories.RouteRecord{}, err
-	}
-
+func (h *RouteHandler) lookupRouteAndDomain(ctx context.Context, client client.Client, routeGUID string) (repositories.RouteRecord, error) {
 	route, err := h.routeRepo.FetchRoute(ctx, client, routeGUID)
 	if err != nil {
 		return repositories.RouteRecord{}, err
@@ -179,20 +203,49 @@ func (h *RouteHandler) lookupRouteAndDomain(ctx context.Context, routeGUID strin
 	return route, nil
 }
 
-func (h *RouteHandler) lookupRouteAndDomainList(ctx context.Context) ([]repositories.RouteRecord, error) {
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+// parseRouteListMessage reads the filters, ordering and page of a route list request
+func parseRouteListMessage(r *http.Request) (repositories.RouteListMessage, error) {
+	err := checkQueryParameters(r, "hosts", "paths", "domain_guids", "space_guids", "app_guids", "order_by")
 	if err != nil {
-		return []repositories.RouteRecord{}, err
+		return repositories.RouteListMessage{}, err
 	}
 
-	routeRecords, err := h.routeRepo.FetchRouteList(ctx, client)
+	orderBy, err := parseOrderBy(r, "created_at", "updated_at")
 	if err != nil {
-		return []repositories.RouteRecord{}, err
+		return repositories.RouteListMessage{}, err
 	}
 
-	return getDomainsForRoutes(ctx, h.domainRepo, client, routeRecords)
+	labelSelector, err := parseLabelSelector(r)
+	if err != nil {
+		return repositories.RouteListMessage{}, err
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		return repositories.RouteListMessage{}, err
+	}
+
+	query := r.URL.Query()
+	return repositories.RouteListMessage{
+		Hosts:         parseCommaSeparatedList(query.Get("hosts")),
+		Paths:         parseCommaSeparatedList(query.Get("paths")),
+		DomainGUIDs:   parseCommaSeparatedList(query.Get("domain_guids")),
+		SpaceGUIDs:    parseCommaSeparatedList(query.Get("space_guids")),
+		AppGUIDs:      parseCommaSeparatedList(query.Get("app_guids")),
+		LabelSelector: labelSelector,
+		OrderBy:       orderBy,
+		Page:          pageRequest,
+	}, nil
+}
+
+func (h *RouteHandler) lookupRouteAndDomainList(ctx context.Context, client client.Client, routeListMessage repositories.RouteListMessage) ([]repositories.RouteRecord, int, error) {
+	routeRecords, totalResults, err := h.routeRepo.FetchRouteList(ctx, client, routeListMessage)
+	if err != nil {
+		return []repositories.RouteRecord{}, 0, err
+	}
+
+	routeRecords, err = getDomainsForRoutes(ctx, h.domainRepo, client, routeRecords)
+	return routeRecords, totalResults, err
 }
 
 func getDomainsForRoutes(ctx context.Context, domainRepo CFDomainRepository, client client.Client, routeRecords []repositories.RouteRecord) ([]repositories.RouteRecord, error) {
@@ -226,12 +279,9 @@ func (h *RouteHandler) routeCreateHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// TODO: Instantiate config based on bearer token
-	// Spike code from EMEA folks around this: https://github.com/cloudfoundry/cf-crd-explorations/blob/136417fbff507eb13c92cd67e6fed6b061071941/cfshim/handlers/app_handler.go#L78
-	client, err := h.buildClient(h.k8sConfig)
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
 	if err != nil {
-		h.logger.Error(err, "Unable to create Kubernetes client")
-		writeUnknownErrorResponse(w)
+		writeClientBuildErrorResponse(w, h.logger, err)
 		return
 	}
 
@@ -290,9 +340,69 @@ func (h *RouteHandler) routeCreateHandler(w http.ResponseWriter, r *http.Request
 	w.Write(responseBody)
 }
 
+func (h *RouteHandler) routePatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	routeGUID := vars["guid"]
+
+	var payload payloads.RoutePatch
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return
+	}
+
+	route, err := h.lookupRouteAndDomain(ctx, client, routeGUID)
+	if err != nil {
+		switch err.(type) {
+		case repositories.NotFoundError:
+			h.logger.Info("Route not found", "RouteGUID", routeGUID)
+			writeNotFoundErrorResponse(w, "Route")
+			return
+		default:
+			h.logger.Error(err, "Failed to fetch route from Kubernetes", "RouteGUID", routeGUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+	}
+
+	patchedRoute, err := h.routeRepo.PatchRouteMetadata(ctx, client, payload.ToMessage(routeGUID, route.SpaceGUID))
+	if err != nil {
+		switch err.(type) {
+		case repositories.NotFoundError:
+			h.logger.Info("Route not found", "RouteGUID", routeGUID)
+			writeNotFoundErrorResponse(w, "Route")
+			return
+		default:
+			h.logger.Error(err, "Failed to patch route metadata", "RouteGUID", routeGUID)
+			writeUnknownErrorResponse(w)
+			return
+		}
+	}
+	patchedRoute = patchedRoute.UpdateDomainRef(route.DomainRef)
+
+	responseBody, err := json.Marshal(presenter.ForRoute(patchedRoute, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "RouteGUID", routeGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	_, _ = w.Write(responseBody)
+}
+
 func (h *RouteHandler) RegisterRoutes(router *mux.Router) {
 	router.Path(RouteGetEndpoint).Methods("GET").HandlerFunc(h.routeGetHandler)
 	router.Path(RouteGetListEndpoint).Methods("GET").HandlerFunc(h.routeGetListHandler)
 	router.Path(RouteGetDestinationsEndpoint).Methods("GET").HandlerFunc(h.routeGetDestinationsHandler)
 	router.Path(RouteCreateEndpoint).Methods("POST").HandlerFunc(h.routeCreateHandler)
+	router.Path(RoutePatchEndpoint).Methods("PATCH").HandlerFunc(h.routePatchHandler)
 }
@@ -9,8 +9,11 @@ import (
 
 	. "code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
+	"github.com/go-http-utils/headers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/rest"
@@ -224,7 +227,7 @@ var _ = Describe("RouteHandler", func() {
 			}
 			routeRepo.FetchRouteListReturns([]repositories.RouteRecord{
 				*routeRecord,
-			}, nil)
+			}, 1, nil)
 
 			domainRecord = &repositories.DomainRecord{
 				GUID: testDomainGUID,
@@ -268,10 +271,10 @@ var _ = Describe("RouteHandler", func() {
 					"total_results": 1,
 					"total_pages": 1,
 					"first": {
-						"href": "%[1]s/v3/routes?page=1"
+						"href": "%[1]s/v3/routes?page=1&per_page=50"
 					},
 					"last": {
-						"href": "%[1]s/v3/routes?page=1"
+						"href": "%[1]s/v3/routes?page=1&per_page=50"
 					},
 					"next": null,
 					"previous": null
@@ -325,7 +328,7 @@ var _ = Describe("RouteHandler", func() {
 
 		When("no routes exist", func() {
 			BeforeEach(func() {
-				routeRepo.FetchRouteListReturns([]repositories.RouteRecord{}, nil)
+				routeRepo.FetchRouteListReturns([]repositories.RouteRecord{}, 0, nil)
 			})
 
 			It("returns status 200 OK", func() {
@@ -343,10 +346,10 @@ var _ = Describe("RouteHandler", func() {
 						"total_results": 0,
 						"total_pages": 1,
 						"first": {
-							"href": "%[1]s/v3/routes?page=1"
+							"href": "%[1]s/v3/routes?page=1&per_page=50"
 						},
 						"last": {
-							"href": "%[1]s/v3/routes?page=1"
+							"href": "%[1]s/v3/routes?page=1&per_page=50"
 						},
 						"next": null,
 						"previous": null
@@ -359,9 +362,82 @@ var _ = Describe("RouteHandler", func() {
 			})
 		})
 
+		When("a page is requested", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "page=4&per_page=10"
+			})
+
+			It("fetches that page of routes", func() {
+				Expect(routeRepo.FetchRouteListCallCount()).To(Equal(1))
+				_, _, message := routeRepo.FetchRouteListArgsForCall(0)
+				Expect(message.Page).To(Equal(repositories.PageRequest{Page: 4, PerPage: 10}))
+			})
+		})
+
+		When("filters and an order are requested", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "hosts=host1,host2&paths=/path&domain_guids=domain1&space_guids=space1&app_guids=app1,app2&order_by=-updated_at"
+			})
+
+			It("passes them to the repository", func() {
+				_, _, message := routeRepo.FetchRouteListArgsForCall(0)
+				Expect(message).To(Equal(repositories.RouteListMessage{
+					Hosts:       []string{"host1", "host2"},
+					Paths:       []string{"/path"},
+					DomainGUIDs: []string{"domain1"},
+					SpaceGUIDs:  []string{"space1"},
+					AppGUIDs:    []string{"app1", "app2"},
+					OrderBy:     "-updated_at",
+					Page:        repositories.PageRequest{Page: 1, PerPage: 50},
+				}))
+			})
+		})
+
+		When("a label selector is requested", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "label_selector=!internal"
+			})
+
+			It("passes it to the repository", func() {
+				_, _, message := routeRepo.FetchRouteListArgsForCall(0)
+				Expect(message.LabelSelector.String()).To(Equal("!internal"))
+			})
+		})
+
+		When("order_by names a field routes cannot be ordered by", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "order_by=host"
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Order by can only be: 'created_at', 'updated_at'")
+			})
+		})
+
+		When("an unknown query parameter is given", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "names=my-route"
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Unknown query parameter(s): 'names'. Valid parameters are: 'page', 'per_page', 'label_selector', 'hosts', 'paths', 'domain_guids', 'space_guids', 'app_guids', 'order_by'")
+				Expect(routeRepo.FetchRouteListCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the page is negative", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "page=-1"
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Page must be a positive integer")
+			})
+		})
+
 		When("there is a failure Listing Routes", func() {
 			BeforeEach(func() {
-				routeRepo.FetchRouteListReturns([]repositories.RouteRecord{}, errors.New("unknown!"))
+				routeRepo.FetchRouteListReturns([]repositories.RouteRecord{}, 0, errors.New("unknown!"))
 			})
 
 			It("returns an error", func() {
@@ -400,6 +476,7 @@ var _ = Describe("RouteHandler", func() {
 		makePostRequest := func(requestBody string) {
 			req, err := http.NewRequest("POST", "/v3/routes", strings.NewReader(requestBody))
 			Expect(err).NotTo(HaveOccurred())
+			req.Header.Add(headers.Authorization, authHeader)
 
 			router.ServeHTTP(rr, req)
 		}
@@ -758,6 +835,23 @@ var _ = Describe("RouteHandler", func() {
 			})
 		})
 
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				clientBuilder.Returns(nil, authorization.UnauthorizedErr{})
+
+				requestBody := initializeCreateRouteRequestBody(testRouteHost, testRoutePath, testSpaceGUID, testDomainGUID, nil, nil)
+				makePostRequest(requestBody)
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+
+			It("does not create a route", func() {
+				Expect(routeRepo.CreateRouteCallCount()).To(Equal(0))
+			})
+		})
+
 		When("the space does not exist", func() {
 			BeforeEach(func() {
 				appRepo.FetchNamespaceReturns(repositories.SpaceRecord{},
@@ -1033,6 +1127,110 @@ var _ = Describe("RouteHandler", func() {
 			})
 		})
 	})
+
+	Describe("the PATCH /v3/routes/:guid endpoint", func() {
+		const (
+			testDomainGUID = "test-domain-guid"
+			testRouteGUID  = "test-route-guid"
+			testSpaceGUID  = "test-space-guid"
+		)
+
+		var (
+			routeRepo  *fake.CFRouteRepository
+			domainRepo *fake.CFDomainRepository
+			body       string
+		)
+
+		BeforeEach(func() {
+			routeRepo = new(fake.CFRouteRepository)
+			domainRepo = new(fake.CFDomainRepository)
+
+			routeRepo.FetchRouteReturns(repositories.RouteRecord{
+				GUID:      testRouteGUID,
+				SpaceGUID: testSpaceGUID,
+				DomainRef: repositories.DomainRecord{GUID: testDomainGUID},
+			}, nil)
+			routeRepo.PatchRouteMetadataReturns(repositories.RouteRecord{
+				GUID:      testRouteGUID,
+				SpaceGUID: testSpaceGUID,
+				DomainRef: repositories.DomainRecord{GUID: testDomainGUID},
+				Host:      "test-route-host",
+				Labels:    map[string]string{"env": "prod"},
+			}, nil)
+			domainRepo.FetchDomainReturns(repositories.DomainRecord{
+				GUID: testDomainGUID,
+				Name: "example.org",
+			}, nil)
+
+			routeHandler := NewRouteHandler(
+				logf.Log.WithName("TestRouteHandler"),
+				*serverURL,
+				routeRepo,
+				domainRepo,
+				new(fake.CFAppRepository),
+				new(fake.ClientBuilder).Spy,
+				&rest.Config{},
+			)
+			routeHandler.RegisterRoutes(router)
+
+			body = `{"metadata": {"labels": {"env": "prod"}}}`
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("PATCH", "/v3/routes/"+testRouteGUID, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			router.ServeHTTP(rr, req)
+		})
+
+		It("patches the route metadata in the space of the route", func() {
+			Expect(routeRepo.PatchRouteMetadataCallCount()).To(Equal(1))
+			_, _, message := routeRepo.PatchRouteMetadataArgsForCall(0)
+			Expect(message.GUID).To(Equal(testRouteGUID))
+			Expect(message.SpaceGUID).To(Equal(testSpaceGUID))
+			Expect(*message.Labels["env"]).To(Equal("prod"))
+		})
+
+		It("responds with the patched route and its domain", func() {
+			Expect(rr.Code).To(Equal(http.StatusOK))
+
+			var response presenter.RouteResponse
+			Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+			Expect(response.URL).To(Equal("test-route-host.example.org"))
+			Expect(response.Metadata.Labels).To(Equal(map[string]string{"env": "prod"}))
+		})
+
+		When("the metadata is invalid", func() {
+			BeforeEach(func() {
+				body = `{"metadata": {"labels": {"env": "` + strings.Repeat("a", 64) + `"}}}`
+			})
+
+			It("returns an error without patching the route", func() {
+				expectUnprocessableEntityError("Metadata label value error: '" + strings.Repeat("a", 64) + "' is greater than 63 characters")
+				Expect(routeRepo.PatchRouteMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the route cannot be found", func() {
+			BeforeEach(func() {
+				routeRepo.FetchRouteReturns(repositories.RouteRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Route not found")
+			})
+		})
+
+		When("patching the route fails", func() {
+			BeforeEach(func() {
+				routeRepo.PatchRouteMetadataReturns(repositories.RouteRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
 })
 
 func initializeCreateRouteRequestBody(host, path string, spaceGUID, domainGUID string, labels, annotations map[string]string) string {
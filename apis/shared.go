@@ -5,14 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
 
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 
+	"github.com/go-logr/logr"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,7 +30,10 @@ import (
 var Logger = ctrl.Log.WithName("Shared Handler Functions")
 
 //counterfeiter:generate -o fake -fake-name ClientBuilder . ClientBuilder
-type ClientBuilder func(*rest.Config) (client.Client, error)
+
+// ClientBuilder builds a Kubernetes client for a single request.
+// The string argument is the value of the request's Authorization header.
+type ClientBuilder func(*rest.Config, string) (client.Client, error)
 
 type requestMalformedError struct {
 	httpStatus    int
@@ -63,14 +73,50 @@ func DecodeAndValidatePayload(r *http.Request, object interface{}) *requestMalfo
 		}
 	}
 
+	return validatePayload(object)
+}
+
+// validatePayload validates a payload that was decoded from somewhere other than a JSON body, such as a form field
+func validatePayload(object interface{}) *requestMalformedError {
 	v := validator.New()
 
 	// Register custom validators
 	v.RegisterValidation("routepathstartswithslash", routePathStartsWithSlash)
+	v.RegisterStructValidation(metadataPatchValidation, payloads.MetadataPatch{})
+	v.RegisterStructValidation(metadataValidation, payloads.Metadata{})
+	v.RegisterStructValidation(envVarsPatchValidation, payloads.AppPatchEnvVars{})
+	v.RegisterStructValidation(healthCheckPatchValidation, payloads.ProcessPatchHealthCheck{})
+	v.RegisterStructValidation(lifecycleValidation, payloads.Lifecycle{})
+	v.RegisterStructValidation(packageCreateValidation, payloads.PackageCreate{})
 
 	trans := registerDefaultTranslator(v)
-
-	err = v.Struct(object)
+	v.RegisterTranslation("cfmetadata", trans, func(ut ut.Translator) error {
+		return nil
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		return fe.Param()
+	})
+	v.RegisterTranslation("cfenvvars", trans, func(ut ut.Translator) error {
+		return nil
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		return fe.Param()
+	})
+	v.RegisterTranslation("cfhealthcheck", trans, func(ut ut.Translator) error {
+		return nil
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		return fe.Param()
+	})
+	v.RegisterTranslation("cfpackage", trans, func(ut ut.Translator) error {
+		return nil
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		return fe.Param()
+	})
+	v.RegisterTranslation("cffeature", trans, func(ut ut.Translator) error {
+		return nil
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		return fe.Param()
+	})
+
+	err := v.Struct(object)
 	if err != nil {
 		errorMap := err.(validator.ValidationErrors).Translate(trans)
 		var errorMessages []string
@@ -113,6 +159,32 @@ func newUnknownError() presenter.ErrorsResponse {
 	}}}
 }
 
+func newNotAuthorizedError() presenter.ErrorsResponse {
+	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
+		Title:  "CF-NotAuthorized",
+		Detail: "You are not authorized to perform the requested action",
+		Code:   10003,
+	}}}
+}
+
+// newJobError gives the error of a job task the CF error that a request failing with it would respond with. Errors
+// without a CF counterpart are returned as they are and reported as unknown errors.
+func newJobError(err error, resourceName string) error {
+	var presentedErr presenter.PresentedError
+	switch {
+	case errors.As(err, new(repositories.NotFoundError)):
+		presentedErr = newNotFoundError(resourceName).Errors[0]
+	case errors.As(err, new(repositories.ConflictError)):
+		presentedErr = newConflictError(fmt.Sprintf("The %s was changed by another request. Retry the request.", strings.ToLower(resourceName))).Errors[0]
+	case k8serrors.IsForbidden(err):
+		presentedErr = newNotAuthorizedError().Errors[0]
+	default:
+		return err
+	}
+
+	return repositories.JobError{Title: presentedErr.Title, Detail: presentedErr.Detail, Code: presentedErr.Code}
+}
+
 func newUnauthenticatedError() presenter.ErrorsResponse {
 	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
 		Title:  "CF-NotAuthenticated",
@@ -137,6 +209,15 @@ func newUnprocessableEntityError(detail string) presenter.ErrorsResponse {
 	}}}
 }
 
+// newUnprocessableEntityErrors reports several problems with a request at once, one error for each of details
+func newUnprocessableEntityErrors(details []string) presenter.ErrorsResponse {
+	response := presenter.ErrorsResponse{Errors: []presenter.PresentedError{}}
+	for _, detail := range details {
+		response.Errors = append(response.Errors, newUnprocessableEntityError(detail).Errors...)
+	}
+	return response
+}
+
 func newUniquenessError(detail string) presenter.ErrorsResponse {
 	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
 		Title:  "CF-UniquenessError",
@@ -153,6 +234,22 @@ func newInvalidRequestError(detail string) presenter.ErrorsResponse {
 	}}}
 }
 
+func newBadQueryParameterError(detail string) presenter.ErrorsResponse {
+	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
+		Title:  "CF-BadQueryParameter",
+		Detail: fmt.Sprintf("The query parameter is invalid: %s", detail),
+		Code:   10005,
+	}}}
+}
+
+func newConflictError(detail string) presenter.ErrorsResponse {
+	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
+		Title:  "CF-ConcurrencyError",
+		Detail: detail,
+		Code:   10018,
+	}}}
+}
+
 func newPackageBitsAlreadyUploadedError() presenter.ErrorsResponse {
 	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
 		Title:  "CF-PackageBitsAlreadyUploaded",
@@ -161,6 +258,14 @@ func newPackageBitsAlreadyUploadedError() presenter.ErrorsResponse {
 	}}}
 }
 
+func newPackageBitsTooLargeError(maxSizeMB int64) presenter.ErrorsResponse {
+	return presenter.ErrorsResponse{Errors: []presenter.PresentedError{{
+		Title:  "CF-AppPackageInvalid",
+		Detail: fmt.Sprintf("The app package is invalid: Package may not be larger than %dMB", maxSizeMB),
+		Code:   150001,
+	}}}
+}
+
 func writeNotFoundErrorResponse(w http.ResponseWriter, resourceName string) {
 	responseBody, err := json.Marshal(newNotFoundError(resourceName))
 	if err != nil {
@@ -189,6 +294,27 @@ func writeUnauthorizedErrorResponse(w http.ResponseWriter) {
 	_, _ = w.Write(responseBody)
 }
 
+func writeNotAuthorizedErrorResponse(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+	responseBody, err := json.Marshal(newNotAuthorizedError())
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(responseBody)
+}
+
+// writeClientBuildErrorResponse responds to a request whose Kubernetes client could not be built. Callers that could not
+// be authenticated get a 401, anything else is an unknown error.
+func writeClientBuildErrorResponse(w http.ResponseWriter, logger logr.Logger, err error, keysAndValues ...interface{}) {
+	if authorization.IsUnauthorized(err) {
+		logger.Info("Unauthorized to create Kubernetes client", "reason", err.Error())
+		writeUnauthorizedErrorResponse(w)
+		return
+	}
+	logger.Error(err, "Unable to create Kubernetes client", keysAndValues...)
+	writeUnknownErrorResponse(w)
+}
+
 func writeErrorResponse(w http.ResponseWriter, rme *requestMalformedError) {
 	w.WriteHeader(rme.httpStatus)
 	responseBody, err := json.Marshal(rme.errorResponse)
@@ -226,6 +352,26 @@ func writeInvalidRequestError(w http.ResponseWriter, detail string) {
 	w.Write(responseBody)
 }
 
+func writeBadQueryParameterError(w http.ResponseWriter, detail string) {
+	w.WriteHeader(http.StatusBadRequest)
+
+	responseBody, err := json.Marshal(newBadQueryParameterError(detail))
+	if err != nil {
+		return
+	}
+	w.Write(responseBody)
+}
+
+func writeConflictError(w http.ResponseWriter, detail string) {
+	w.WriteHeader(http.StatusConflict)
+
+	responseBody, err := json.Marshal(newConflictError(detail))
+	if err != nil {
+		return
+	}
+	w.Write(responseBody)
+}
+
 func writePackageBitsAlreadyUploadedError(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusBadRequest)
 
@@ -236,6 +382,16 @@ func writePackageBitsAlreadyUploadedError(w http.ResponseWriter) {
 	w.Write(responseBody)
 }
 
+func writePackageBitsTooLargeError(w http.ResponseWriter, maxSizeMB int64) {
+	w.WriteHeader(http.StatusUnprocessableEntity)
+
+	responseBody, err := json.Marshal(newPackageBitsTooLargeError(maxSizeMB))
+	if err != nil {
+		return
+	}
+	w.Write(responseBody)
+}
+
 // Custom field validators
 func routePathStartsWithSlash(fl validator.FieldLevel) bool {
 	if fl.Field().String() == "" {
@@ -248,3 +404,201 @@ func routePathStartsWithSlash(fl validator.FieldLevel) bool {
 
 	return true
 }
+
+// dockerDisabledMessage is the error of requests for docker apps and packages, which the CF API reports when its
+// diego_docker feature flag is disabled. The controllers only run droplets built with buildpacks.
+const (
+	dockerType            = "docker"
+	dockerDisabledMessage = "Feature Disabled: diego_docker"
+)
+
+const (
+	metadataNameMaxLength            = 63
+	metadataPrefixMaxLength          = 253
+	metadataAnnotationValueMaxLength = 5000
+)
+
+var (
+	metadataNameRegex   = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+	metadataPrefixRegex = regexp.MustCompile(`^(([A-Za-z0-9]|[A-Za-z0-9][-A-Za-z0-9]*[A-Za-z0-9])\.)*([A-Za-z0-9]|[A-Za-z0-9][-A-Za-z0-9]*[A-Za-z0-9])$`)
+)
+
+// metadataPatchValidation checks labels and annotations against the rules of the CF API, which are those of
+// Kubernetes plus the reserved cloudfoundry.org prefix. Only the first invalid key or value of each map is reported.
+func metadataPatchValidation(sl validator.StructLevel) {
+	metadata := sl.Current().Interface().(payloads.MetadataPatch)
+	validateMetadata(sl, metadata.Labels, metadata.Annotations)
+}
+
+// metadataValidation checks the labels and annotations of created resources like metadataPatchValidation
+func metadataValidation(sl validator.StructLevel) {
+	metadata := sl.Current().Interface().(payloads.Metadata)
+	validateMetadata(sl, metadataValues(metadata.Labels), metadataValues(metadata.Annotations))
+}
+
+func validateMetadata(sl validator.StructLevel, labels, annotations map[string]*string) {
+	for _, key := range sortedKeys(labels) {
+		if msg := validateMetadataKey(key); msg != "" {
+			sl.ReportError(labels, "Labels", "Labels", "cfmetadata", "Metadata label key error: "+msg)
+			break
+		}
+		if value := labels[key]; value != nil {
+			if msg := validateLabelValue(*value); msg != "" {
+				sl.ReportError(labels, "Labels", "Labels", "cfmetadata", "Metadata label value error: "+msg)
+				break
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(annotations) {
+		if msg := validateMetadataKey(key); msg != "" {
+			sl.ReportError(annotations, "Annotations", "Annotations", "cfmetadata", "Metadata annotation key error: "+msg)
+			break
+		}
+		if value := annotations[key]; value != nil && len(*value) > metadataAnnotationValueMaxLength {
+			sl.ReportError(annotations, "Annotations", "Annotations", "cfmetadata",
+				fmt.Sprintf("Metadata annotation value error: value is greater than %d characters", metadataAnnotationValueMaxLength))
+			break
+		}
+	}
+}
+
+func metadataValues(metadata map[string]string) map[string]*string {
+	values := make(map[string]*string, len(metadata))
+	for key := range metadata {
+		value := metadata[key]
+		values[key] = &value
+	}
+	return values
+}
+
+func validateMetadataKey(key string) string {
+	if key == "" {
+		return "key cannot be empty string"
+	}
+
+	parts := strings.Split(key, "/")
+	if len(parts) > 2 {
+		return "key has more than one '/'"
+	}
+
+	name := parts[len(parts)-1]
+	if len(parts) == 2 {
+		prefix := parts[0]
+		if repositories.IsReservedMetadataKey(key) {
+			return fmt.Sprintf("prefix '%s' is reserved", repositories.MetadataReservedDomain)
+		}
+		if len(prefix) > metadataPrefixMaxLength {
+			return fmt.Sprintf("prefix '%s' is greater than %d characters", prefix, metadataPrefixMaxLength)
+		}
+		if !metadataPrefixRegex.MatchString(prefix) {
+			return fmt.Sprintf("prefix '%s' must be in valid dns format", prefix)
+		}
+	}
+
+	if len(name) > metadataNameMaxLength {
+		return fmt.Sprintf("'%s' is greater than %d characters", name, metadataNameMaxLength)
+	}
+	if !metadataNameRegex.MatchString(name) {
+		return fmt.Sprintf("'%s' contains invalid characters", name)
+	}
+
+	return ""
+}
+
+func validateLabelValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) > metadataNameMaxLength {
+		return fmt.Sprintf("'%s' is greater than %d characters", value, metadataNameMaxLength)
+	}
+	if !metadataNameRegex.MatchString(value) {
+		return fmt.Sprintf("'%s' contains invalid characters", value)
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]*string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// envVarKeyRegex matches the keys that a Secret, which holds the environment variables of an app, can store
+var envVarKeyRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// envVarsPatchValidation checks the names of environment variables against the rules of the CF API. Only the first
+// invalid name is reported.
+func envVarsPatchValidation(sl validator.StructLevel) {
+	envVars := sl.Current().Interface().(payloads.AppPatchEnvVars)
+
+	for _, key := range sortedKeys(envVars.Var) {
+		if msg := envVarKeyError(key); msg != "" {
+			sl.ReportError(envVars.Var, "Var", "Var", "cfenvvars", msg)
+			break
+		}
+	}
+}
+
+// envVarKeyError returns the problem with the name of an environment variable, or an empty string when it is valid
+func envVarKeyError(key string) string {
+	switch {
+	case key == "":
+		return "Var key must be a minimum length of 1"
+	case strings.HasPrefix(strings.ToUpper(key), "VCAP_"):
+		return "Var cannot start with VCAP_"
+	case strings.HasPrefix(strings.ToUpper(key), "VMC_"):
+		return "Var cannot start with VMC_"
+	case strings.ToUpper(key) == "PORT":
+		return "Var cannot set PORT"
+	case !envVarKeyRegex.MatchString(key):
+		return fmt.Sprintf("Var key '%s' contains invalid characters", key)
+	}
+	return ""
+}
+
+// healthCheckPatchValidation checks that an HTTP endpoint is only set together with the "http" health check type, as
+// in the CF API
+func healthCheckPatchValidation(sl validator.StructLevel) {
+	healthCheck := sl.Current().Interface().(payloads.ProcessPatchHealthCheck)
+
+	if healthCheck.Data == nil || healthCheck.Data.Endpoint == nil {
+		return
+	}
+	if healthCheck.Type == nil || *healthCheck.Type != "http" {
+		sl.ReportError(healthCheck.Data.Endpoint, "Endpoint", "Endpoint", "cfhealthcheck", `Health check type must be "http" to set a health check HTTP endpoint`)
+	}
+}
+
+// lifecycleValidation requires the buildpacks and stack of buildpack lifecycles, and rejects docker lifecycles
+func lifecycleValidation(sl validator.StructLevel) {
+	lifecycle := sl.Current().Interface().(payloads.Lifecycle)
+
+	if lifecycle.Type == dockerType {
+		sl.ReportError(lifecycle.Type, "Type", "Type", "cffeature", dockerDisabledMessage)
+		return
+	}
+	if lifecycle.Data.Buildpacks == nil {
+		sl.ReportError(lifecycle.Data.Buildpacks, "Buildpacks", "Buildpacks", "required", "")
+	}
+	if lifecycle.Data.Stack == "" {
+		sl.ReportError(lifecycle.Data.Stack, "Stack", "Stack", "required", "")
+	}
+}
+
+// packageCreateValidation rejects docker packages and requires no data for bits packages
+func packageCreateValidation(sl validator.StructLevel) {
+	packageCreate := sl.Current().Interface().(payloads.PackageCreate)
+
+	if packageCreate.Type == dockerType {
+		sl.ReportError(packageCreate.Type, "Type", "Type", "cffeature", dockerDisabledMessage)
+		return
+	}
+	if packageCreate.Data != nil && *packageCreate.Data != (payloads.PackageData{}) {
+		sl.ReportError(packageCreate.Data, "Data", "Data", "cfpackage", "Data must be empty if provided for bits packages")
+	}
+}
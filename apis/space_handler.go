@@ -3,46 +3,75 @@ package apis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"strings"
 
 	"code.cloudfoundry.org/cf-k8s-api/payloads"
 	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 	"code.cloudfoundry.org/cf-k8s-controllers/webhooks/workloads"
 	"github.com/go-logr/logr"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/labels"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 )
 
 const (
-	SpacesEndpoint = "/v3/spaces"
+	SpacesEndpoint     = "/v3/spaces"
+	SpacePatchEndpoint = "/v3/spaces/{guid}"
 )
 
 //counterfeiter:generate -o fake -fake-name CFSpaceRepository . CFSpaceRepository
+//counterfeiter:generate -o fake -fake-name SpaceRepositoryProvider . SpaceRepositoryProvider
 
 type CFSpaceRepository interface {
 	CreateSpace(context.Context, repositories.SpaceRecord) (repositories.SpaceRecord, error)
-	FetchSpaces(context.Context, []string, []string) ([]repositories.SpaceRecord, error)
+	FetchSpace(context.Context, string) (repositories.SpaceRecord, error)
+	FetchSpaces(context.Context, []string, []string, labels.Selector) ([]repositories.SpaceRecord, error)
+	PatchSpaceMetadata(context.Context, repositories.MetadataPatchMessage) (repositories.SpaceRecord, error)
+}
+
+type SpaceRepositoryProvider interface {
+	SpaceRepoForRequest(request *http.Request) (CFSpaceRepository, error)
 }
 
 type SpaceHandler struct {
-	spaceRepo  CFSpaceRepository
-	logger     logr.Logger
-	apiBaseURL url.URL
+	spaceRepoProvider SpaceRepositoryProvider
+	logger            logr.Logger
+	apiBaseURL        url.URL
 }
 
-func NewSpaceHandler(spaceRepo CFSpaceRepository, apiBaseURL url.URL) *SpaceHandler {
+func NewSpaceHandler(spaceRepoProvider SpaceRepositoryProvider, apiBaseURL url.URL) *SpaceHandler {
 	return &SpaceHandler{
-		spaceRepo:  spaceRepo,
-		apiBaseURL: apiBaseURL,
-		logger:     controllerruntime.Log.WithName("Org Handler"),
+		spaceRepoProvider: spaceRepoProvider,
+		apiBaseURL:        apiBaseURL,
+		logger:            controllerruntime.Log.WithName("Space Handler"),
 	}
 }
 
+// spaceRepoForRequest returns the space repository of the user of a request. It responds to the request itself and
+// returns false when there is none.
+func (h *SpaceHandler) spaceRepoForRequest(w http.ResponseWriter, r *http.Request) (CFSpaceRepository, bool) {
+	spaceRepo, err := h.spaceRepoProvider.SpaceRepoForRequest(r)
+	if err != nil {
+		if authorization.IsUnauthorized(err) {
+			h.logger.Info("Unauthorized to access spaces", "reason", err.Error())
+			writeUnauthorizedErrorResponse(w)
+			return nil, false
+		}
+
+		h.logger.Error(err, "failed to create space repo for the authorization header")
+		writeUnknownErrorResponse(w)
+		return nil, false
+	}
+
+	return spaceRepo, true
+}
+
 func (h *SpaceHandler) SpaceCreateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
@@ -54,10 +83,15 @@ func (h *SpaceHandler) SpaceCreateHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	spaceRepo, ok := h.spaceRepoForRequest(w, r)
+	if !ok {
+		return
+	}
+
 	space := payload.ToRecord()
 	space.GUID = uuid.NewString()
 
-	record, err := h.spaceRepo.CreateSpace(ctx, space)
+	record, err := spaceRepo.CreateSpace(ctx, space)
 	if err != nil {
 		if workloads.HasErrorCode(err, workloads.DuplicateSpaceNameError) {
 			errorDetail := fmt.Sprintf("Space '%s' already exists.", space.Name)
@@ -66,13 +100,19 @@ func (h *SpaceHandler) SpaceCreateHandler(w http.ResponseWriter, r *http.Request
 			return
 		}
 
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Org namespace not found", "Org GUID", space.OrganizationGUID)
+			writeUnprocessableEntityError(w, "Invalid organization. Ensure the organization exists and you have access to it.")
+			return
+		}
+
 		h.logger.Error(err, "Failed to create space", "Space Name", space.Name)
 		writeUnknownErrorResponse(w)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	spaceResponse := presenter.ForCreateSpace(record, h.apiBaseURL)
+	spaceResponse := presenter.ForSpace(record, h.apiBaseURL)
 
 	err = json.NewEncoder(w).Encode(spaceResponse)
 	if err != nil {
@@ -84,32 +124,86 @@ func (h *SpaceHandler) SpaceListHandler(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	labelSelector, err := parseLabelSelector(r)
+	if err != nil {
+		h.logger.Info("Invalid label selector", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	spaceRepo, ok := h.spaceRepoForRequest(w, r)
+	if !ok {
+		return
+	}
+
 	orgUIDs := parseCommaSeparatedList(r.URL.Query().Get("organization_guids"))
 	names := parseCommaSeparatedList(r.URL.Query().Get("names"))
 
-	spaces, err := h.spaceRepo.FetchSpaces(ctx, orgUIDs, names)
+	spaces, err := spaceRepo.FetchSpaces(ctx, orgUIDs, names, labelSelector)
 	if err != nil {
+		h.logger.Error(err, "Failed to fetch spaces")
 		writeUnknownErrorResponse(w)
 
 		return
 	}
 
-	spaceList := presenter.ForSpaceList(spaces, h.apiBaseURL)
+	start, end := pageRequest.Bounds(len(spaces))
+	spaceList := presenter.ForSpaceList(spaces[start:end], h.apiBaseURL, newListPage(r, pageRequest, len(spaces)))
 	json.NewEncoder(w).Encode(spaceList)
 }
 
-func (h *SpaceHandler) RegisterRoutes(router *mux.Router) {
-	router.Path(SpacesEndpoint).Methods("GET").HandlerFunc(h.SpaceListHandler)
-	router.Path(SpacesEndpoint).Methods("POST").HandlerFunc(h.SpaceCreateHandler)
-}
+func (h *SpaceHandler) SpacePatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	spaceGUID := mux.Vars(r)["guid"]
 
-func parseCommaSeparatedList(list string) []string {
-	var elements []string
-	for _, element := range strings.Split(list, ",") {
-		if element != "" {
-			elements = append(elements, element)
+	var payload payloads.SpacePatch
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		h.logger.Error(rme, "Failed to decode and validate payload")
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	spaceRepo, ok := h.spaceRepoForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := spaceRepo.PatchSpaceMetadata(ctx, payload.ToMessage(spaceGUID))
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Space not found", "SpaceGUID", spaceGUID)
+			writeNotFoundErrorResponse(w, "Space")
+			return
 		}
+
+		if errors.As(err, new(repositories.ForbiddenError)) {
+			h.logger.Info("Not allowed to patch space metadata", "SpaceGUID", spaceGUID)
+			writeNotAuthorizedErrorResponse(w)
+			return
+		}
+
+		h.logger.Error(err, "Failed to patch space metadata", "SpaceGUID", spaceGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(presenter.ForSpace(record, h.apiBaseURL))
+	if err != nil {
+		h.logger.Error(err, "Failed to write response")
 	}
+}
 
-	return elements
+func (h *SpaceHandler) RegisterRoutes(router *mux.Router) {
+	router.Path(SpacesEndpoint).Methods("GET").HandlerFunc(h.SpaceListHandler)
+	router.Path(SpacesEndpoint).Methods("POST").HandlerFunc(h.SpaceCreateHandler)
+	router.Path(SpacePatchEndpoint).Methods("PATCH").HandlerFunc(h.SpacePatchHandler)
 }
@@ -1,6 +1,7 @@
 package apis_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,7 +10,9 @@ import (
 
 	"code.cloudfoundry.org/cf-k8s-api/apis"
 	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
 	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
 	"code.cloudfoundry.org/cf-k8s-controllers/webhooks/workloads"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,12 +24,13 @@ var _ = Describe("Spaces", func() {
 	const spacesBase = "/v3/spaces"
 
 	var (
-		now           time.Time
-		spaceHandler  *apis.SpaceHandler
-		spaceRepo     *fake.CFSpaceRepository
-		requestMethod string
-		requestBody   string
-		requestPath   string
+		now               time.Time
+		spaceHandler      *apis.SpaceHandler
+		spaceRepoProvider *fake.SpaceRepositoryProvider
+		spaceRepo         *fake.CFSpaceRepository
+		requestMethod     string
+		requestBody       string
+		requestPath       string
 	)
 
 	BeforeEach(func() {
@@ -34,7 +38,9 @@ var _ = Describe("Spaces", func() {
 		requestBody = ""
 		requestPath = spacesBase
 		spaceRepo = new(fake.CFSpaceRepository)
-		spaceHandler = apis.NewSpaceHandler(spaceRepo, *serverURL)
+		spaceRepoProvider = new(fake.SpaceRepositoryProvider)
+		spaceRepoProvider.SpaceRepoForRequestReturns(spaceRepo, nil)
+		spaceHandler = apis.NewSpaceHandler(spaceRepoProvider, *serverURL)
 		spaceHandler.RegisterRoutes(router)
 	})
 
@@ -163,6 +169,16 @@ var _ = Describe("Spaces", func() {
 			})
 		})
 
+		When("the org namespace does not exist yet", func() {
+			BeforeEach(func() {
+				spaceRepo.CreateSpaceReturns(repositories.SpaceRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Invalid organization. Ensure the organization exists and you have access to it.")
+			})
+		})
+
 		When("the space repo returns another error", func() {
 			BeforeEach(func() {
 				spaceRepo.CreateSpaceReturns(repositories.SpaceRecord{}, errors.New("boom"))
@@ -172,6 +188,17 @@ var _ = Describe("Spaces", func() {
 				expectUnknownError()
 			})
 		})
+
+		When("the user is not authenticated", func() {
+			BeforeEach(func() {
+				spaceRepoProvider.SpaceRepoForRequestReturns(nil, authorization.UnauthorizedErr{})
+			})
+
+			It("returns an unauthorized error without creating the space", func() {
+				expectUnauthorizedError()
+				Expect(spaceRepo.CreateSpaceCallCount()).To(Equal(0))
+			})
+		})
 	})
 
 	Describe("Listing Spaces", func() {
@@ -203,10 +230,10 @@ var _ = Describe("Spaces", func() {
                     "total_results": 2,
                     "total_pages": 1,
                     "first": {
-                        "href": "%[1]s/v3/spaces?page=1"
+                        "href": "%[1]s/v3/spaces?page=1&per_page=50"
                     },
                     "last": {
-                        "href": "%[1]s/v3/spaces?page=1"
+                        "href": "%[1]s/v3/spaces?page=1&per_page=50"
                     },
                     "next": null,
                     "previous": null
@@ -266,11 +293,36 @@ var _ = Describe("Spaces", func() {
             }`, defaultServerURL)))
 
 			Expect(spaceRepo.FetchSpacesCallCount()).To(Equal(1))
-			_, organizationGUIDs, names := spaceRepo.FetchSpacesArgsForCall(0)
+			_, organizationGUIDs, names, _ := spaceRepo.FetchSpacesArgsForCall(0)
 			Expect(organizationGUIDs).To(BeEmpty())
 			Expect(names).To(BeEmpty())
 		})
 
+		When("a page past the end of the list is requested", func() {
+			BeforeEach(func() {
+				requestPath = spacesBase + "?page=3&per_page=1"
+			})
+
+			It("returns no spaces and a link to the previous page", func() {
+				var response presenter.SpaceListResponse
+				Expect(json.Unmarshal(rr.Body.Bytes(), &response)).To(Succeed())
+				Expect(response.Resources).To(BeEmpty())
+				Expect(response.Pagination.TotalPages).To(Equal(2))
+				Expect(response.Pagination.Next).To(BeNil())
+				Expect(response.Pagination.Previous.HREF).To(Equal(defaultServerURI(spacesBase, "?page=2&per_page=1")))
+			})
+		})
+
+		When("per_page is zero", func() {
+			BeforeEach(func() {
+				requestPath = spacesBase + "?per_page=0"
+			})
+
+			It("returns a bad query parameter error", func() {
+				expectBadQueryParameterError("Per page must be between 1 and 5000")
+			})
+		})
+
 		When("fetching the spaces fails", func() {
 			BeforeEach(func() {
 				spaceRepo.FetchSpacesReturns(nil, errors.New("boom!"))
@@ -281,6 +333,26 @@ var _ = Describe("Spaces", func() {
 			})
 		})
 
+		When("the user is not authenticated", func() {
+			BeforeEach(func() {
+				spaceRepoProvider.SpaceRepoForRequestReturns(nil, authorization.UnauthorizedErr{})
+			})
+
+			It("returns an unauthorized error", func() {
+				expectUnauthorizedError()
+			})
+		})
+
+		When("the space repository cannot be built", func() {
+			BeforeEach(func() {
+				spaceRepoProvider.SpaceRepoForRequestReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+
 		When("organization_guids are provided as a comma-separated list", func() {
 			BeforeEach(func() {
 				requestPath = spacesBase + "?organization_guids=foo,,bar,"
@@ -288,7 +360,7 @@ var _ = Describe("Spaces", func() {
 
 			It("filters spaces by them", func() {
 				Expect(spaceRepo.FetchSpacesCallCount()).To(Equal(1))
-				_, organizationGUIDs, names := spaceRepo.FetchSpacesArgsForCall(0)
+				_, organizationGUIDs, names, _ := spaceRepo.FetchSpacesArgsForCall(0)
 				Expect(organizationGUIDs).To(ConsistOf("foo", "bar"))
 				Expect(names).To(BeEmpty())
 			})
@@ -301,10 +373,135 @@ var _ = Describe("Spaces", func() {
 
 			It("filters spaces by them", func() {
 				Expect(spaceRepo.FetchSpacesCallCount()).To(Equal(1))
-				_, organizationGUIDs, names := spaceRepo.FetchSpacesArgsForCall(0)
+				_, organizationGUIDs, names, _ := spaceRepo.FetchSpacesArgsForCall(0)
 				Expect(organizationGUIDs).To(ConsistOf("org1"))
 				Expect(names).To(ConsistOf("foo", "bar"))
 			})
 		})
+
+		When("a label selector is provided", func() {
+			BeforeEach(func() {
+				requestPath = spacesBase + "?label_selector=team%3Dpayments"
+			})
+
+			It("filters spaces by it", func() {
+				_, _, _, labelSelector := spaceRepo.FetchSpacesArgsForCall(0)
+				Expect(labelSelector.String()).To(Equal("team=payments"))
+			})
+		})
+	})
+
+	Describe("Patching a Space", func() {
+		BeforeEach(func() {
+			requestMethod = http.MethodPatch
+			requestPath = spacesBase + "/t-h-e-s-p-a-c-e"
+			requestBody = `{"metadata": {"labels": {"env": "prod", "tier": null}}}`
+
+			spaceRepo.PatchSpaceMetadataReturns(repositories.SpaceRecord{
+				Name:             "the-space",
+				GUID:             "t-h-e-s-p-a-c-e",
+				OrganizationGUID: "the-org",
+				Labels:           map[string]string{"env": "prod"},
+				CreatedAt:        now,
+				UpdatedAt:        now,
+			}, nil)
+		})
+
+		It("patches the space metadata", func() {
+			Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(1))
+			_, message := spaceRepo.PatchSpaceMetadataArgsForCall(0)
+			Expect(message.GUID).To(Equal("t-h-e-s-p-a-c-e"))
+			Expect(*message.Labels["env"]).To(Equal("prod"))
+			Expect(message.Labels).To(HaveKeyWithValue("tier", BeNil()))
+		})
+
+		It("returns 200 with the patched space", func() {
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(MatchJSON(fmt.Sprintf(`{
+				"guid": "t-h-e-s-p-a-c-e",
+				"name": "the-space",
+				"created_at": "2021-09-17T15:23:10Z",
+				"updated_at": "2021-09-17T15:23:10Z",
+				"metadata": {
+					"labels": {"env": "prod"},
+					"annotations": {}
+				},
+				"relationships": {
+					"organization": {
+						"data": {
+							"guid": "the-org"
+						}
+					}
+				},
+				"links": {
+					"self": {
+						"href": "%[1]s/v3/spaces/t-h-e-s-p-a-c-e"
+					},
+					"organization": {
+						"href": "%[1]s/v3/organizations/the-org"
+					}
+				}
+			}`, defaultServerURL)))
+		})
+
+		When("an annotation value is too long", func() {
+			BeforeEach(func() {
+				requestBody = `{"metadata": {"annotations": {"description": "` + strings.Repeat("a", 5001) + `"}}}`
+			})
+
+			It("returns an error without patching the space", func() {
+				expectUnprocessableEntityError("Metadata annotation value error: value is greater than 5000 characters")
+				Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the space does not exist", func() {
+			BeforeEach(func() {
+				spaceRepo.PatchSpaceMetadataReturns(repositories.SpaceRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns a not found error", func() {
+				expectNotFoundError("Space not found")
+			})
+		})
+
+		When("patching the space fails", func() {
+			BeforeEach(func() {
+				spaceRepo.PatchSpaceMetadataReturns(repositories.SpaceRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+
+		When("the user is not authenticated", func() {
+			BeforeEach(func() {
+				spaceRepoProvider.SpaceRepoForRequestReturns(nil, authorization.UnauthorizedErr{})
+			})
+
+			It("returns an unauthorized error without patching the space", func() {
+				expectUnauthorizedError()
+				Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the user may not patch the space", func() {
+			BeforeEach(func() {
+				spaceRepo.PatchSpaceMetadataReturns(repositories.SpaceRecord{}, repositories.ForbiddenError{})
+			})
+
+			It("returns a not authorized error", func() {
+				expectJSONResponse(http.StatusForbidden, `{
+					"errors": [
+						{
+							"title": "CF-NotAuthorized",
+							"detail": "You are not authorized to perform the requested action",
+							"code": 10003
+						}
+					]
+				}`)
+			})
+		})
 	})
 })
@@ -0,0 +1,321 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/payloads"
+	"code.cloudfoundry.org/cf-k8s-api/presenter"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	"github.com/go-http-utils/headers"
+	"github.com/go-logr/logr"
+	"github.com/gorilla/mux"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	AppTaskCreateEndpoint = "/v3/apps/{guid}/tasks"
+	AppTaskListEndpoint   = "/v3/apps/{guid}/tasks"
+	TaskListEndpoint      = "/v3/tasks"
+	TaskGetEndpoint       = "/v3/tasks/{guid}"
+	TaskCancelEndpoint    = "/v3/tasks/{guid}/actions/cancel"
+
+	taskWithoutDropletErrorDetail = "Task must have a droplet. Assign current droplet to app."
+)
+
+//counterfeiter:generate -o fake -fake-name CFTaskRepository . CFTaskRepository
+
+type CFTaskRepository interface {
+	CreateTask(context.Context, client.Client, repositories.TaskCreateMessage) (repositories.TaskRecord, error)
+	FetchTask(context.Context, client.Client, string) (repositories.TaskRecord, error)
+	FetchTaskList(context.Context, client.Client, repositories.TaskListMessage) ([]repositories.TaskRecord, error)
+	CancelTask(context.Context, client.Client, repositories.TaskRecord) (repositories.TaskRecord, error)
+}
+
+type TaskHandler struct {
+	logger      logr.Logger
+	serverURL   url.URL
+	taskRepo    CFTaskRepository
+	appRepo     CFAppRepository
+	dropletRepo CFDropletRepository
+	buildClient ClientBuilder
+	k8sConfig   *rest.Config
+}
+
+func NewTaskHandler(
+	logger logr.Logger,
+	serverURL url.URL,
+	taskRepo CFTaskRepository,
+	appRepo CFAppRepository,
+	dropletRepo CFDropletRepository,
+	buildClient ClientBuilder,
+	k8sConfig *rest.Config) *TaskHandler {
+	return &TaskHandler{
+		logger:      logger,
+		serverURL:   serverURL,
+		taskRepo:    taskRepo,
+		appRepo:     appRepo,
+		dropletRepo: dropletRepo,
+		buildClient: buildClient,
+		k8sConfig:   k8sConfig,
+	}
+}
+
+// appTaskCreateHandler runs the task with the current droplet of the app. The task runs asynchronously, so the
+// response is 202 Accepted.
+func (h *TaskHandler) appTaskCreateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	appGUID := mux.Vars(r)["guid"]
+
+	var payload payloads.TaskCreate
+	rme := DecodeAndValidatePayload(r, &payload)
+	if rme != nil {
+		writeErrorResponse(w, rme)
+		return
+	}
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
+
+	if app.DropletGUID == "" {
+		h.logger.Info("App has no current droplet", "AppGUID", appGUID)
+		writeUnprocessableEntityError(w, taskWithoutDropletErrorDetail)
+		return
+	}
+
+	droplet, err := h.dropletRepo.FetchDroplet(ctx, client, app.DropletGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Current droplet of app not found", "AppGUID", appGUID, "DropletGUID", app.DropletGUID)
+			writeUnprocessableEntityError(w, taskWithoutDropletErrorDetail)
+			return
+		}
+		h.logger.Error(err, "Failed to fetch droplet from Kubernetes", "DropletGUID", app.DropletGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	task, err := h.taskRepo.CreateTask(ctx, client, payload.ToMessage(app, droplet))
+	if err != nil {
+		h.logger.Error(err, "Failed to create task", "AppGUID", appGUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	h.writeTask(w, http.StatusAccepted, task)
+}
+
+func (h *TaskHandler) appTaskListHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	appGUID := mux.Vars(r)["guid"]
+
+	if err := checkQueryParameters(r); err != nil {
+		h.logger.Info("Unknown query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, app, ok := h.clientAndApp(w, r, appGUID)
+	if !ok {
+		return
+	}
+
+	tasks, ok := h.fetchTaskList(w, r, client, repositories.TaskListMessage{AppGUIDs: []string{app.GUID}})
+	if !ok {
+		return
+	}
+
+	start, end := pageRequest.Bounds(len(tasks))
+	h.writeTaskList(w, presenter.ForAppTaskList(tasks[start:end], h.serverURL, appGUID, newListPage(r, pageRequest, len(tasks))))
+}
+
+func (h *TaskHandler) taskListHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := checkQueryParameters(r, "app_guids"); err != nil {
+		h.logger.Info("Unknown query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	pageRequest, err := parsePageRequest(r)
+	if err != nil {
+		h.logger.Info("Invalid pagination query parameters", "error", err.Error())
+		writeBadQueryParameterError(w, err.Error())
+		return
+	}
+
+	client, ok := h.client(w, r)
+	if !ok {
+		return
+	}
+
+	tasks, ok := h.fetchTaskList(w, r, client, repositories.TaskListMessage{
+		AppGUIDs: parseCommaSeparatedList(r.URL.Query().Get("app_guids")),
+	})
+	if !ok {
+		return
+	}
+
+	start, end := pageRequest.Bounds(len(tasks))
+	h.writeTaskList(w, presenter.ForTaskList(tasks[start:end], h.serverURL, newListPage(r, pageRequest, len(tasks))))
+}
+
+func (h *TaskHandler) taskGetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, task, ok := h.clientAndTask(w, r, mux.Vars(r)["guid"])
+	if !ok {
+		return
+	}
+
+	h.writeTask(w, http.StatusOK, task)
+}
+
+// taskCancelHandler stops a task that is still pending or running. The task is CANCELING until its pod is gone, so
+// the response is 202 Accepted.
+func (h *TaskHandler) taskCancelHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	taskGUID := mux.Vars(r)["guid"]
+
+	client, task, ok := h.clientAndTask(w, r, taskGUID)
+	if !ok {
+		return
+	}
+
+	task, err := h.taskRepo.CancelTask(ctx, client, task)
+	if err != nil {
+		var notCancelableErr repositories.TaskNotCancelableError
+		switch {
+		case errors.As(err, &notCancelableErr):
+			h.logger.Info("Task cannot be canceled", "TaskGUID", taskGUID, "State", notCancelableErr.State)
+			writeUnprocessableEntityError(w, notCancelableErr.Error())
+		case errors.As(err, new(repositories.NotFoundError)):
+			h.logger.Info("Task not found", "TaskGUID", taskGUID)
+			writeNotFoundErrorResponse(w, "Task")
+		default:
+			h.logger.Error(err, "Failed to cancel task", "TaskGUID", taskGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return
+	}
+
+	h.writeTask(w, http.StatusAccepted, task)
+}
+
+// clientAndApp builds a client for the user of r and fetches the app with it. The error response has been written
+// when ok is false.
+func (h *TaskHandler) clientAndApp(w http.ResponseWriter, r *http.Request, appGUID string) (client.Client, repositories.AppRecord, bool) {
+	client, ok := h.client(w, r)
+	if !ok {
+		return nil, repositories.AppRecord{}, false
+	}
+
+	app, err := h.appRepo.FetchApp(r.Context(), client, appGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("App not found", "AppGUID", appGUID)
+			writeNotFoundErrorResponse(w, "App")
+		} else {
+			h.logger.Error(err, "Failed to fetch app from Kubernetes", "AppGUID", appGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return nil, repositories.AppRecord{}, false
+	}
+
+	return client, app, true
+}
+
+// clientAndTask builds a client for the user of r and fetches the task with it. The error response has been written
+// when ok is false.
+func (h *TaskHandler) clientAndTask(w http.ResponseWriter, r *http.Request, taskGUID string) (client.Client, repositories.TaskRecord, bool) {
+	client, ok := h.client(w, r)
+	if !ok {
+		return nil, repositories.TaskRecord{}, false
+	}
+
+	task, err := h.taskRepo.FetchTask(r.Context(), client, taskGUID)
+	if err != nil {
+		if errors.As(err, new(repositories.NotFoundError)) {
+			h.logger.Info("Task not found", "TaskGUID", taskGUID)
+			writeNotFoundErrorResponse(w, "Task")
+		} else {
+			h.logger.Error(err, "Failed to fetch task from Kubernetes", "TaskGUID", taskGUID)
+			writeUnknownErrorResponse(w)
+		}
+		return nil, repositories.TaskRecord{}, false
+	}
+
+	return client, task, true
+}
+
+func (h *TaskHandler) client(w http.ResponseWriter, r *http.Request) (client.Client, bool) {
+	client, err := h.buildClient(h.k8sConfig, r.Header.Get(headers.Authorization))
+	if err != nil {
+		writeClientBuildErrorResponse(w, h.logger, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (h *TaskHandler) fetchTaskList(w http.ResponseWriter, r *http.Request, client client.Client, message repositories.TaskListMessage) ([]repositories.TaskRecord, bool) {
+	tasks, err := h.taskRepo.FetchTaskList(r.Context(), client, message)
+	if err != nil {
+		h.logger.Error(err, "Failed to fetch tasks from Kubernetes")
+		writeUnknownErrorResponse(w)
+		return nil, false
+	}
+
+	return tasks, true
+}
+
+func (h *TaskHandler) writeTask(w http.ResponseWriter, status int, task repositories.TaskRecord) {
+	responseBody, err := json.Marshal(presenter.ForTask(task, h.serverURL))
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response", "TaskGUID", task.GUID)
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(responseBody)
+}
+
+func (h *TaskHandler) writeTaskList(w http.ResponseWriter, taskList presenter.TaskListResponse) {
+	responseBody, err := json.Marshal(taskList)
+	if err != nil { // untested
+		h.logger.Error(err, "Failed to render response")
+		writeUnknownErrorResponse(w)
+		return
+	}
+
+	w.Write(responseBody)
+}
+
+func (h *TaskHandler) RegisterRoutes(router *mux.Router) {
+	router.Path(AppTaskCreateEndpoint).Methods("POST").HandlerFunc(h.appTaskCreateHandler)
+	router.Path(AppTaskListEndpoint).Methods("GET").HandlerFunc(h.appTaskListHandler)
+	router.Path(TaskListEndpoint).Methods("GET").HandlerFunc(h.taskListHandler)
+	router.Path(TaskGetEndpoint).Methods("GET").HandlerFunc(h.taskGetHandler)
+	router.Path(TaskCancelEndpoint).Methods("POST").HandlerFunc(h.taskCancelHandler)
+}
@@ -0,0 +1,392 @@
+package apis_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	. "code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/apis/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("TaskHandler", func() {
+	const (
+		appGUID     = "app-guid"
+		spaceGUID   = "space-guid"
+		dropletGUID = "droplet-guid"
+		taskGUID    = "task-guid"
+	)
+
+	var (
+		taskRepo      *fake.CFTaskRepository
+		appRepo       *fake.CFAppRepository
+		dropletRepo   *fake.CFDropletRepository
+		clientBuilder *fake.ClientBuilder
+		appRecord     repositories.AppRecord
+		dropletRecord repositories.DropletRecord
+		taskRecord    repositories.TaskRecord
+	)
+
+	BeforeEach(func() {
+		taskRepo = new(fake.CFTaskRepository)
+		appRepo = new(fake.CFAppRepository)
+		dropletRepo = new(fake.CFDropletRepository)
+		clientBuilder = new(fake.ClientBuilder)
+
+		appRecord = repositories.AppRecord{GUID: appGUID, SpaceGUID: spaceGUID, DropletGUID: dropletGUID, EnvSecretName: "app-env"}
+		dropletRecord = repositories.DropletRecord{GUID: dropletGUID, Image: "registry/droplet:latest"}
+		taskRecord = repositories.TaskRecord{
+			GUID:        taskGUID,
+			SequenceID:  3,
+			Name:        "migrate",
+			Command:     "rake db:migrate",
+			State:       repositories.TaskStateRunning,
+			MemoryMB:    512,
+			DiskMB:      1024,
+			AppGUID:     appGUID,
+			DropletGUID: dropletGUID,
+			SpaceGUID:   spaceGUID,
+			CreatedAt:   "2021-10-12T15:00:00Z",
+			UpdatedAt:   "2021-10-12T15:00:01Z",
+		}
+		appRepo.FetchAppReturns(appRecord, nil)
+		dropletRepo.FetchDropletReturns(dropletRecord, nil)
+		taskRepo.FetchTaskReturns(taskRecord, nil)
+
+		apiHandler := NewTaskHandler(
+			logf.Log.WithName("TestTaskHandler"),
+			*serverURL,
+			taskRepo,
+			appRepo,
+			dropletRepo,
+			clientBuilder.Spy,
+			&rest.Config{},
+		)
+		apiHandler.RegisterRoutes(router)
+	})
+
+	JustBeforeEach(func() {
+		router.ServeHTTP(rr, req)
+	})
+
+	taskJSON := func(command string) string {
+		return `{
+			"guid": "task-guid",
+			"sequence_id": 3,
+			"name": "migrate",
+			"command": "` + command + `",
+			"state": "RUNNING",
+			"memory_in_mb": 512,
+			"disk_in_mb": 1024,
+			"result": { "failure_reason": null },
+			"droplet_guid": "droplet-guid",
+			"relationships": {
+				"app": { "data": { "guid": "app-guid" } }
+			},
+			"metadata": { "labels": {}, "annotations": {} },
+			"created_at": "2021-10-12T15:00:00Z",
+			"updated_at": "2021-10-12T15:00:01Z",
+			"links": {
+				"self": { "href": "` + defaultServerURL + `/v3/tasks/task-guid" },
+				"app": { "href": "` + defaultServerURL + `/v3/apps/app-guid" },
+				"cancel": { "href": "` + defaultServerURL + `/v3/tasks/task-guid/actions/cancel", "method": "POST" },
+				"droplet": { "href": "` + defaultServerURL + `/v3/droplets/droplet-guid" }
+			}
+		}`
+	}
+
+	Describe("the POST /v3/apps/:guid/tasks endpoint", func() {
+		makeCreateRequest := func(body string) {
+			var err error
+			req, err = http.NewRequest("POST", "/v3/apps/"+appGUID+"/tasks", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			taskRepo.CreateTaskReturns(taskRecord, nil)
+			makeCreateRequest(`{ "name": "migrate", "command": "rake db:migrate", "memory_in_mb": 512 }`)
+		})
+
+		When("on the happy path", func() {
+			It("runs the task with the current droplet of the app", func() {
+				Expect(dropletRepo.FetchDropletCallCount()).To(Equal(1))
+				_, _, actualDropletGUID := dropletRepo.FetchDropletArgsForCall(0)
+				Expect(actualDropletGUID).To(Equal(dropletGUID))
+
+				Expect(taskRepo.CreateTaskCallCount()).To(Equal(1))
+				_, _, message := taskRepo.CreateTaskArgsForCall(0)
+				Expect(message).To(Equal(repositories.TaskCreateMessage{
+					Name:     "migrate",
+					Command:  "rake db:migrate",
+					MemoryMB: 512,
+					DiskMB:   1024,
+					App:      appRecord,
+					Droplet:  dropletRecord,
+				}))
+			})
+
+			It("returns the task", func() {
+				expectJSONResponse(http.StatusAccepted, taskJSON("rake db:migrate"))
+			})
+		})
+
+		When("the task has no name", func() {
+			BeforeEach(func() {
+				makeCreateRequest(`{ "command": "rake db:migrate" }`)
+			})
+
+			It("gives the task a random name", func() {
+				Expect(taskRepo.CreateTaskCallCount()).To(Equal(1))
+				_, _, message := taskRepo.CreateTaskArgsForCall(0)
+				Expect(message.Name).To(MatchRegexp("^[0-9a-f]{8}$"))
+			})
+		})
+
+		When("the command is missing", func() {
+			BeforeEach(func() {
+				makeCreateRequest(`{ "name": "migrate" }`)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Command is a required field")
+			})
+
+			It("doesn't create a task", func() {
+				Expect(taskRepo.CreateTaskCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+
+		When("the app has no current droplet", func() {
+			BeforeEach(func() {
+				appRecord.DropletGUID = ""
+				appRepo.FetchAppReturns(appRecord, nil)
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Task must have a droplet. Assign current droplet to app.")
+			})
+
+			It("doesn't create a task", func() {
+				Expect(taskRepo.CreateTaskCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the current droplet of the app doesn't exist", func() {
+			BeforeEach(func() {
+				dropletRepo.FetchDropletReturns(repositories.DropletRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Task must have a droplet. Assign current droplet to app.")
+			})
+		})
+
+		When("creating the task fails", func() {
+			BeforeEach(func() {
+				taskRepo.CreateTaskReturns(repositories.TaskRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/tasks endpoint", func() {
+		BeforeEach(func() {
+			taskRepo.FetchTaskListReturns([]repositories.TaskRecord{taskRecord}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/tasks", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("returns the tasks with their commands hidden", func() {
+				expectJSONResponse(http.StatusOK, `{
+					"pagination": {
+						"total_results": 1,
+						"total_pages": 1,
+						"first": { "href": "`+defaultServerURL+`/v3/tasks?page=1&per_page=50" },
+						"last": { "href": "`+defaultServerURL+`/v3/tasks?page=1&per_page=50" },
+						"next": null,
+						"previous": null
+					},
+					"resources": [`+taskJSON("[PRIVATE DATA HIDDEN IN LISTS]")+`]
+				}`)
+			})
+		})
+
+		When("the tasks are filtered by app", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "app_guids=app1,app2"
+			})
+
+			It("passes the filter to the repository", func() {
+				Expect(taskRepo.FetchTaskListCallCount()).To(Equal(1))
+				_, _, message := taskRepo.FetchTaskListArgsForCall(0)
+				Expect(message).To(Equal(repositories.TaskListMessage{AppGUIDs: []string{"app1", "app2"}}))
+			})
+		})
+
+		When("an unknown query parameter is given", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "states=RUNNING"
+			})
+
+			It("returns an error", func() {
+				expectBadQueryParameterError("Unknown query parameter(s): 'states'. Valid parameters are: 'page', 'per_page', 'label_selector', 'app_guids'")
+			})
+		})
+
+		When("fetching the tasks fails", func() {
+			BeforeEach(func() {
+				taskRepo.FetchTaskListReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the GET /v3/apps/:guid/tasks endpoint", func() {
+		BeforeEach(func() {
+			taskRepo.FetchTaskListReturns([]repositories.TaskRecord{taskRecord}, nil)
+
+			var err error
+			req, err = http.NewRequest("GET", "/v3/apps/"+appGUID+"/tasks", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("fetches the tasks of the app", func() {
+				Expect(taskRepo.FetchTaskListCallCount()).To(Equal(1))
+				_, _, message := taskRepo.FetchTaskListArgsForCall(0)
+				Expect(message).To(Equal(repositories.TaskListMessage{AppGUIDs: []string{appGUID}}))
+			})
+
+			It("returns the tasks", func() {
+				expectJSONResponse(http.StatusOK, `{
+					"pagination": {
+						"total_results": 1,
+						"total_pages": 1,
+						"first": { "href": "`+defaultServerURL+`/v3/apps/app-guid/tasks?page=1&per_page=50" },
+						"last": { "href": "`+defaultServerURL+`/v3/apps/app-guid/tasks?page=1&per_page=50" },
+						"next": null,
+						"previous": null
+					},
+					"resources": [`+taskJSON("[PRIVATE DATA HIDDEN IN LISTS]")+`]
+				}`)
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			BeforeEach(func() {
+				appRepo.FetchAppReturns(repositories.AppRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("App not found")
+			})
+		})
+	})
+
+	Describe("the GET /v3/tasks/:guid endpoint", func() {
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("GET", "/v3/tasks/"+taskGUID, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("returns the task", func() {
+				expectJSONResponse(http.StatusOK, taskJSON("rake db:migrate"))
+			})
+		})
+
+		When("the task doesn't exist", func() {
+			BeforeEach(func() {
+				taskRepo.FetchTaskReturns(repositories.TaskRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Task not found")
+			})
+		})
+
+		When("fetching the task fails", func() {
+			BeforeEach(func() {
+				taskRepo.FetchTaskReturns(repositories.TaskRecord{}, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				expectUnknownError()
+			})
+		})
+	})
+
+	Describe("the POST /v3/tasks/:guid/actions/cancel endpoint", func() {
+		BeforeEach(func() {
+			canceledTask := taskRecord
+			canceledTask.State = repositories.TaskStateCanceling
+			taskRepo.CancelTaskReturns(canceledTask, nil)
+
+			var err error
+			req, err = http.NewRequest("POST", "/v3/tasks/"+taskGUID+"/actions/cancel", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("on the happy path", func() {
+			It("cancels the task", func() {
+				Expect(taskRepo.CancelTaskCallCount()).To(Equal(1))
+				_, _, actualTask := taskRepo.CancelTaskArgsForCall(0)
+				Expect(actualTask).To(Equal(taskRecord))
+			})
+
+			It("returns the canceling task", func() {
+				expectJSONResponse(http.StatusAccepted, strings.Replace(taskJSON("rake db:migrate"), `"RUNNING"`, `"CANCELING"`, 1))
+			})
+		})
+
+		When("the task has already finished", func() {
+			BeforeEach(func() {
+				taskRepo.CancelTaskReturns(repositories.TaskRecord{}, repositories.TaskNotCancelableError{State: repositories.TaskStateSucceeded})
+			})
+
+			It("returns an error", func() {
+				expectUnprocessableEntityError("Task state is SUCCEEDED and therefore cannot be canceled")
+			})
+		})
+
+		When("the task doesn't exist", func() {
+			BeforeEach(func() {
+				taskRepo.FetchTaskReturns(repositories.TaskRecord{}, repositories.NotFoundError{})
+			})
+
+			It("returns an error", func() {
+				expectNotFoundError("Task not found")
+			})
+
+			It("doesn't cancel anything", func() {
+				Expect(taskRepo.CancelTaskCallCount()).To(Equal(0))
+			})
+		})
+	})
+})
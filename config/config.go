@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
 	"gopkg.in/yaml.v3"
@@ -12,10 +13,23 @@ type Config struct {
 	RootNamespace             string `yaml:"rootNamespace"`
 	PackageRegistryBase       string `yaml:"packageRegistryBase"`
 	PackageRegistrySecretName string `yaml:"packageRegistrySecretName"`
+	// PackageUploadMaxSizeMB limits the size of package bits uploads
+	PackageUploadMaxSizeMB int64 `yaml:"packageUploadMaxSizeMB"`
+	// PackageUploadConcurrency is the number of package bits uploads pushed to the registry at once. Further uploads
+	// wait for one of them to finish. It must be at least 1.
+	PackageUploadConcurrency int `yaml:"packageUploadConcurrency"`
+	// ResourceCacheDir is where the files of uploaded packages are kept for resource matching. Nothing is kept, and no
+	// resources match, when it is not set. Resources only match files kept by the same instance of the shim, so it has
+	// to be a volume shared by every instance when the shim runs more than one.
+	ResourceCacheDir string `yaml:"resourceCacheDir"`
+	// ResourceCacheMaxSizeMB limits the size of ResourceCacheDir, evicting the least recently used files beyond it
+	ResourceCacheMaxSizeMB int64 `yaml:"resourceCacheMaxSizeMB"`
 
 	DefaultLifecycleConfig DefaultLifecycleConfig `yaml:"defaultLifecycleConfig"`
 
 	AuthEnabled bool `yaml:"authEnabled"`
+	// InformerCacheEnabled serves reads of CF resources from a shared informer cache instead of the API server
+	InformerCacheEnabled bool `yaml:"informerCacheEnabled"`
 }
 
 // DefaultLifecycleConfig contains default values of the Lifecycle block of CFApps and Builds created by the Shim
@@ -26,8 +40,18 @@ type DefaultLifecycleConfig struct {
 	StagingDiskMB   int    `yaml:"stagingDiskMB"`
 }
 
+const (
+	defaultPackageUploadMaxSizeMB   = 1024
+	defaultPackageUploadConcurrency = 4
+	defaultResourceCacheMaxSizeMB   = 4096
+)
+
 func LoadFromPath(path string) (*Config, error) {
-	var config Config
+	config := Config{
+		PackageUploadMaxSizeMB:   defaultPackageUploadMaxSizeMB,
+		PackageUploadConcurrency: defaultPackageUploadConcurrency,
+		ResourceCacheMaxSizeMB:   defaultResourceCacheMaxSizeMB,
+	}
 	configFile, err := os.Open(path)
 	if err != nil {
 		return &config, err
@@ -35,5 +59,19 @@ func LoadFromPath(path string) (*Config, error) {
 	defer configFile.Close()
 	decoder := yaml.NewDecoder(configFile)
 	err = decoder.Decode(&config)
-	return &config, err
+	if err != nil {
+		return &config, err
+	}
+	return &config, config.validate()
+}
+
+// validate rejects settings that would keep the shim from serving requests
+func (c Config) validate() error {
+	if c.PackageUploadConcurrency < 1 {
+		return fmt.Errorf("packageUploadConcurrency must be at least 1, but is %d", c.PackageUploadConcurrency)
+	}
+	if c.ResourceCacheDir != "" && c.ResourceCacheMaxSizeMB < 1 {
+		return fmt.Errorf("resourceCacheMaxSizeMB must be at least 1, but is %d", c.ResourceCacheMaxSizeMB)
+	}
+	return nil
 }
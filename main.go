@@ -27,13 +27,24 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	hnsv1alpha2 "sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
 )
 
-var createTimeout = time.Second * 30
+var (
+	createTimeout               = time.Second * 30
+	restartTimeout              = time.Minute * 2
+	jobLeaseDuration            = time.Second * 30
+	jobTTL                      = time.Hour * 24
+	taskTTL                     = time.Hour * 24 * 7
+	deploymentLeaseDuration     = time.Second * 30
+	deploymentTimeout           = time.Minute * 10
+	fingerprintEvictionInterval = time.Minute
+	accessReviewTTL             = time.Second * 30
+)
 
 func init() {
 	utilruntime.Must(workloadsv1alpha1.AddToScheme(scheme.Scheme))
@@ -78,74 +89,164 @@ func main() {
 		panic(fmt.Sprintf("could not parse server URL: %v", err))
 	}
 
-	orgRepo := repositories.NewOrgRepo(config.RootNamespace, privilegedCRClient, createTimeout)
+	identityProvider := authorization.NewIdentityProvider(authorization.NewTokenReviewer(privilegedCRClient))
+	nsProvider := authorization.NewOrg(privilegedCRClient)
+
+	clientBuilder := repositories.BuildPrivilegedCRClient
+	if config.AuthEnabled {
+		clientBuilder = repositories.BuildNamespaceScopedCRClient(identityProvider, nsProvider)
+	}
+
+	var orgRepoClient client.WithWatch = privilegedCRClient
+	if config.InformerCacheEnabled {
+		informerCache := startInformerCache(k8sClientConfig)
+		orgRepoClient = informerCache.WithCachedReads(privilegedCRClient)
+
+		clientBuilder = informerCache.BuildPrivilegedCRClient
+		if config.AuthEnabled {
+			accessReviewer := authorization.NewAccessReviewer(k8sClientConfig, accessReviewTTL)
+			clientBuilder = informerCache.BuildCRClient(accessReviewer, identityProvider, nsProvider)
+		}
+	}
+
+	namespaceCache := repositories.NewGUIDNamespaceCache()
+	appRepo := repositories.NewAppRepo(namespaceCache, restartTimeout)
+	routeRepo := repositories.NewRouteRepo(namespaceCache)
+	processRepo := repositories.NewProcessRepository(namespaceCache, privilegedCRClient, repositories.MetricsAPIFetcher{})
+	packageRepo := repositories.NewPackageRepo(namespaceCache)
+	buildRepo := repositories.NewBuildRepo(namespaceCache)
+	dropletRepo := repositories.NewDropletRepo(namespaceCache)
+	taskRepo := repositories.NewTaskRepo(namespaceCache, taskTTL)
+	revisionRepo := repositories.NewRevisionRepo(namespaceCache, privilegedCRClient)
+	deploymentRepo := repositories.NewDeploymentRepo(namespaceCache, privilegedCRClient, revisionRepo, deploymentLeaseDuration, deploymentTimeout)
+	fingerprintStore := repositories.NewFingerprintStore(config.ResourceCacheDir, config.ResourceCacheMaxSizeMB*1024*1024)
+	go fingerprintStore.EvictPeriodically(context.Background(), fingerprintEvictionInterval)
+	fingerprintRepoProvider := wireFingerprintRepoProvider(fingerprintStore, privilegedCRClient, config.AuthEnabled)
+	jobRepo := repositories.NewJobRepo(config.RootNamespace, privilegedCRClient, jobLeaseDuration, jobTTL)
+	go jobRepo.CleanUpJobsPeriodically(context.Background(), jobLeaseDuration)
+	go deploymentRepo.ResumeDeploymentsPeriodically(context.Background(), deploymentLeaseDuration)
+
+	orgRepo := repositories.NewOrgRepo(config.RootNamespace, orgRepoClient, createTimeout)
 	handlers := []APIHandler{
 		apis.NewRootV3Handler(config.ServerURL),
 		apis.NewRootHandler(
 			ctrl.Log.WithName("RootHandler"),
 			config.ServerURL,
 		),
-		apis.NewResourceMatchesHandler(config.ServerURL),
+		apis.NewResourceMatchesHandler(
+			ctrl.Log.WithName("ResourceMatchesHandler"),
+			config.ServerURL,
+			fingerprintRepoProvider,
+		),
 		apis.NewAppHandler(
 			ctrl.Log.WithName("AppHandler"),
 			*serverURL,
-			new(repositories.AppRepo),
-			new(repositories.DropletRepo),
-			new(repositories.ProcessRepository),
-			new(repositories.RouteRepo),
+			appRepo,
+			dropletRepo,
+			processRepo,
+			routeRepo,
 			new(repositories.DomainRepo),
-			repositories.BuildCRClient,
+			jobRepo,
+			revisionRepo,
+			clientBuilder,
+			k8sClientConfig,
+		),
+		apis.NewJobHandler(
+			ctrl.Log.WithName("JobHandler"),
+			*serverURL,
+			jobRepo,
+			clientBuilder,
 			k8sClientConfig,
 		),
 		apis.NewRouteHandler(
 			ctrl.Log.WithName("RouteHandler"),
 			*serverURL,
-			new(repositories.RouteRepo),
+			routeRepo,
 			new(repositories.DomainRepo),
-			new(repositories.AppRepo),
-			repositories.BuildCRClient,
+			appRepo,
+			clientBuilder,
 			k8sClientConfig,
 		),
 		apis.NewPackageHandler(
 			ctrl.Log.WithName("PackageHandler"),
 			*serverURL,
-			new(repositories.PackageRepo),
-			new(repositories.AppRepo),
-			repositories.BuildCRClient,
+			packageRepo,
+			appRepo,
+			fingerprintRepoProvider,
+			clientBuilder,
 			repositories.UploadSourceImage,
 			newRegistryAuthBuilder(privilegedK8sClient, config),
 			k8sClientConfig,
 			config.PackageRegistryBase,
 			config.PackageRegistrySecretName,
+			config.PackageUploadMaxSizeMB,
+			config.PackageUploadConcurrency,
 		),
 		apis.NewBuildHandler(
 			ctrl.Log.WithName("BuildHandler"),
 			*serverURL,
-			new(repositories.BuildRepo),
-			new(repositories.PackageRepo),
-			repositories.BuildCRClient,
+			buildRepo,
+			packageRepo,
+			clientBuilder,
 			k8sClientConfig,
 		),
 		apis.NewDropletHandler(
 			ctrl.Log.WithName("DropletHandler"),
 			*serverURL,
-			new(repositories.DropletRepo),
-			repositories.BuildCRClient,
+			dropletRepo,
+			clientBuilder,
 			k8sClientConfig,
 		),
 		apis.NewProcessHandler(
 			ctrl.Log.WithName("ProcessHandler"),
 			*serverURL,
-			new(repositories.ProcessRepository),
-			repositories.BuildCRClient,
+			processRepo,
+			revisionRepo,
+			clientBuilder,
 			k8sClientConfig,
 		),
-
-		wireOrgHandler(*serverURL, orgRepo, privilegedCRClient, config.AuthEnabled),
-		apis.NewSpaceHandler(
-			repositories.NewOrgRepo(config.RootNamespace, privilegedCRClient, createTimeout),
+		apis.NewTaskHandler(
+			ctrl.Log.WithName("TaskHandler"),
+			*serverURL,
+			taskRepo,
+			appRepo,
+			dropletRepo,
+			clientBuilder,
+			k8sClientConfig,
+		),
+		apis.NewDeploymentHandler(
+			ctrl.Log.WithName("DeploymentHandler"),
 			*serverURL,
+			deploymentRepo,
+			appRepo,
+			dropletRepo,
+			revisionRepo,
+			clientBuilder,
+			k8sClientConfig,
+		),
+		apis.NewRevisionHandler(
+			ctrl.Log.WithName("RevisionHandler"),
+			*serverURL,
+			revisionRepo,
+			appRepo,
+			clientBuilder,
+			k8sClientConfig,
 		),
+		apis.NewManifestHandler(
+			ctrl.Log.WithName("ManifestHandler"),
+			*serverURL,
+			appRepo,
+			processRepo,
+			routeRepo,
+			new(repositories.DomainRepo),
+			revisionRepo,
+			jobRepo,
+			clientBuilder,
+			k8sClientConfig,
+		),
+
+		wireOrgHandler(*serverURL, orgRepo, privilegedCRClient, config.AuthEnabled),
+		wireSpaceHandler(*serverURL, orgRepo, privilegedCRClient, k8sClientConfig, config.AuthEnabled),
 	}
 
 	router := mux.NewRouter()
@@ -157,6 +258,25 @@ func main() {
 	log.Fatal(http.ListenAndServe(portString, router))
 }
 
+func startInformerCache(k8sClientConfig *rest.Config) *repositories.InformerCache {
+	ctx := context.Background()
+	informerCache, err := repositories.NewInformerCache(ctx, k8sClientConfig)
+	if err != nil {
+		panic(fmt.Sprintf("could not create informer cache: %v", err))
+	}
+
+	go func() {
+		if err := informerCache.Start(ctx); err != nil {
+			panic(fmt.Sprintf("informer cache stopped: %v", err))
+		}
+	}()
+	if !informerCache.WaitForCacheSync(ctx) {
+		panic("informer cache failed to sync")
+	}
+
+	return informerCache
+}
+
 func newRegistryAuthBuilder(privilegedK8sClient k8sclient.Interface, config *config.Config) func(ctx context.Context) (remote.Option, error) {
 	return func(ctx context.Context) (remote.Option, error) {
 		keychainFactory, err := k8sdockercreds.NewSecretKeychainFactory(privilegedK8sClient)
@@ -186,3 +306,25 @@ func wireOrgHandler(serverUrl url.URL, orgRepo *repositories.OrgRepo, client cli
 
 	return apis.NewOrgHandler(serverUrl, orgRepoProvider)
 }
+
+func wireSpaceHandler(serverUrl url.URL, orgRepo *repositories.OrgRepo, client client.Client, k8sConfig *rest.Config, authEnabled bool) *apis.SpaceHandler {
+	var spaceRepoProvider apis.SpaceRepositoryProvider = provider.NewPrivilegedSpace(orgRepo)
+	if authEnabled {
+		authNsProvider := authorization.NewOrg(client)
+		tokenReviewer := authorization.NewTokenReviewer(client)
+		identityProvider := authorization.NewIdentityProvider(tokenReviewer)
+		accessReviewer := authorization.NewAccessReviewer(k8sConfig, accessReviewTTL)
+		spaceRepoProvider = provider.NewSpace(orgRepo, authNsProvider, identityProvider, accessReviewer)
+	}
+
+	return apis.NewSpaceHandler(spaceRepoProvider, serverUrl)
+}
+
+func wireFingerprintRepoProvider(fingerprintStore *repositories.FingerprintStore, client client.Client, authEnabled bool) apis.FingerprintRepositoryProvider {
+	if !authEnabled {
+		return provider.NewPrivilegedFingerprint(fingerprintStore)
+	}
+
+	tokenReviewer := authorization.NewTokenReviewer(client)
+	return provider.NewFingerprint(fingerprintStore, authorization.NewIdentityProvider(tokenReviewer))
+}
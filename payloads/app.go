@@ -53,3 +53,50 @@ func (p AppCreate) ToRecord() repositories.AppRecord {
 type AppSetCurrentDroplet struct {
 	Relationship `json:",inline" validate:"required"`
 }
+
+type AppPatch struct {
+	Name      *string         `json:"name" validate:"omitempty,min=1"`
+	Lifecycle *LifecyclePatch `json:"lifecycle"`
+	Metadata  MetadataPatch   `json:"metadata"`
+}
+
+// LifecyclePatch changes the buildpacks and stack of an app. Fields that are left out are not changed.
+type LifecyclePatch struct {
+	Type string              `json:"type" validate:"omitempty,oneof=buildpack"`
+	Data *LifecycleDataPatch `json:"data"`
+}
+
+type LifecycleDataPatch struct {
+	Buildpacks *[]string `json:"buildpacks"`
+	Stack      *string   `json:"stack"`
+}
+
+// ToMessage returns the changes to app, which is the version of the app that they are made against
+func (p AppPatch) ToMessage(app repositories.AppRecord) repositories.PatchAppMessage {
+	message := repositories.PatchAppMessage{
+		AppGUID:         app.GUID,
+		SpaceGUID:       app.SpaceGUID,
+		ResourceVersion: app.ResourceVersion,
+		Name:            p.Name,
+		MetadataPatch:   p.Metadata.toMessage(app.GUID, app.SpaceGUID).MetadataPatch,
+	}
+	if p.Lifecycle != nil && p.Lifecycle.Data != nil {
+		message.Buildpacks = p.Lifecycle.Data.Buildpacks
+		message.Stack = p.Lifecycle.Data.Stack
+	}
+	return message
+}
+
+// AppPatchEnvVars changes the environment variables of an app. A null value removes the variable.
+type AppPatchEnvVars struct {
+	Var map[string]*string `json:"var" validate:"required"`
+}
+
+func (p AppPatchEnvVars) ToMessage(app repositories.AppRecord) repositories.PatchAppEnvVarsMessage {
+	return repositories.PatchAppEnvVarsMessage{
+		AppGUID:              app.GUID,
+		SpaceGUID:            app.SpaceGUID,
+		EnvSecretName:        app.EnvSecretName,
+		EnvironmentVariables: p.Var,
+	}
+}
@@ -30,3 +30,11 @@ func (c *BuildCreate) ToMessage(appGUID string, spaceGUID string) repositories.B
 
 	return toReturn
 }
+
+type BuildPatch struct {
+	Metadata MetadataPatch `json:"metadata"`
+}
+
+func (p BuildPatch) ToMessage(buildGUID string, spaceGUID string) repositories.MetadataPatchMessage {
+	return p.Metadata.toMessage(buildGUID, spaceGUID)
+}
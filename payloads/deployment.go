@@ -0,0 +1,30 @@
+package payloads
+
+import "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+type DeploymentCreate struct {
+	// Droplet defaults to the current droplet of the app, or the droplet of Revision
+	Droplet *RelationshipData `json:"droplet"`
+	// Revision is the revision to roll the app back to
+	Revision      *RelationshipData        `json:"revision"`
+	Strategy      string                   `json:"strategy" validate:"omitempty,oneof=rolling canary"`
+	Relationships *DeploymentRelationships `json:"relationships" validate:"required"`
+}
+
+type DeploymentRelationships struct {
+	App *Relationship `json:"app" validate:"required"`
+}
+
+func (p DeploymentCreate) ToMessage(app repositories.AppRecord, droplet repositories.DropletRecord, revision *repositories.RevisionRecord) repositories.DeploymentCreateMessage {
+	strategy := p.Strategy
+	if strategy == "" {
+		strategy = repositories.DeploymentStrategyRolling
+	}
+
+	return repositories.DeploymentCreateMessage{
+		App:      app,
+		Droplet:  droplet,
+		Strategy: strategy,
+		Revision: revision,
+	}
+}
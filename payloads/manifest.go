@@ -0,0 +1,312 @@
+package payloads
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+// The scale and health check of a process that a manifest creates, where the manifest leaves them out, as in the CF API
+const (
+	defaultWebInstances     = 1
+	defaultProcessMemoryMB  = 1024
+	defaultProcessDiskMB    = 1024
+	defaultWebHealthCheck   = "port"
+	defaultOtherHealthCheck = "process"
+)
+
+// Manifest is an app manifest, as applied by the CF CLI before it pushes the apps. Only the fields that are applied
+// are declared, so the others, such as services, are ignored.
+type Manifest struct {
+	Applications []ManifestApplication `yaml:"applications" validate:"required,min=1"`
+}
+
+// ManifestApplication is an app of a manifest. Its process fields configure the web process, unless the web entry of
+// Processes sets them as well.
+type ManifestApplication struct {
+	Name                         string                       `yaml:"name" validate:"required"`
+	Buildpacks                   []string                     `yaml:"buildpacks"`
+	Stack                        *string                      `yaml:"stack" validate:"omitempty,min=1"`
+	Env                          map[string]string            `yaml:"env"`
+	Command                      *string                      `yaml:"command" validate:"omitempty,min=1,max=4096"`
+	DiskQuota                    *string                      `yaml:"disk_quota" validate:"omitempty,megabytes"`
+	HealthCheckHTTPEndpoint      *string                      `yaml:"health-check-http-endpoint" validate:"omitempty,startswith=/"`
+	HealthCheckInvocationTimeout *int64                       `yaml:"health-check-invocation-timeout" validate:"omitempty,gte=1"`
+	HealthCheckType              *string                      `yaml:"health-check-type" validate:"omitempty,oneof=none process port http"`
+	Instances                    *int                         `yaml:"instances" validate:"omitempty,gte=0"`
+	Memory                       *string                      `yaml:"memory" validate:"omitempty,megabytes"`
+	Timeout                      *int64                       `yaml:"timeout" validate:"omitempty,gte=1"`
+	Processes                    []ManifestApplicationProcess `yaml:"processes"`
+	Routes                       []ManifestRoute              `yaml:"routes"`
+	Sidecars                     []ManifestApplicationSidecar `yaml:"sidecars"`
+}
+
+type ManifestApplicationProcess struct {
+	Type                         string  `yaml:"type" validate:"required"`
+	Command                      *string `yaml:"command" validate:"omitempty,min=1,max=4096"`
+	DiskQuota                    *string `yaml:"disk_quota" validate:"omitempty,megabytes"`
+	HealthCheckHTTPEndpoint      *string `yaml:"health-check-http-endpoint" validate:"omitempty,startswith=/"`
+	HealthCheckInvocationTimeout *int64  `yaml:"health-check-invocation-timeout" validate:"omitempty,gte=1"`
+	HealthCheckType              *string `yaml:"health-check-type" validate:"omitempty,oneof=none process port http"`
+	Instances                    *int    `yaml:"instances" validate:"omitempty,gte=0"`
+	Memory                       *string `yaml:"memory" validate:"omitempty,megabytes"`
+	Timeout                      *int64  `yaml:"timeout" validate:"omitempty,gte=1"`
+}
+
+type ManifestRoute struct {
+	Route string `yaml:"route" validate:"required"`
+}
+
+// ManifestApplicationSidecar is only decoded to reject manifests with sidecars, which are not supported
+type ManifestApplicationSidecar struct {
+	Name string `yaml:"name"`
+}
+
+func (a ManifestApplication) ToAppRecord(spaceGUID string) repositories.AppRecord {
+	stack := DefaultLifecycleConfig.Stack
+	if a.Stack != nil {
+		stack = *a.Stack
+	}
+
+	return repositories.AppRecord{
+		Name:      a.Name,
+		SpaceGUID: spaceGUID,
+		State:     repositories.StoppedState,
+		Lifecycle: repositories.Lifecycle{
+			Type: DefaultLifecycleConfig.Type,
+			Data: repositories.LifecycleData{
+				Buildpacks: a.Buildpacks,
+				Stack:      stack,
+			},
+		},
+	}
+}
+
+// HasLifecycle reports whether the manifest sets the buildpacks or stack of the app
+func (a ManifestApplication) HasLifecycle() bool {
+	return a.Buildpacks != nil || a.Stack != nil
+}
+
+// ToAppPatchMessage sets the buildpacks and stack of the manifest on app. Those that the manifest leaves out are kept.
+func (a ManifestApplication) ToAppPatchMessage(app repositories.AppRecord) repositories.PatchAppMessage {
+	message := repositories.PatchAppMessage{
+		AppGUID:   app.GUID,
+		SpaceGUID: app.SpaceGUID,
+		Stack:     a.Stack,
+	}
+	if a.Buildpacks != nil {
+		buildpacks := a.Buildpacks
+		message.Buildpacks = &buildpacks
+	}
+	return message
+}
+
+// ToEnvVarsMessage adds the environment variables of the manifest to those of app. Variables that the manifest leaves
+// out are kept.
+func (a ManifestApplication) ToEnvVarsMessage(app repositories.AppRecord) repositories.PatchAppEnvVarsMessage {
+	envVars := map[string]*string{}
+	for key, value := range a.Env {
+		value := value
+		envVars[key] = &value
+	}
+
+	return repositories.PatchAppEnvVarsMessage{
+		AppGUID:              app.GUID,
+		SpaceGUID:            app.SpaceGUID,
+		EnvSecretName:        app.EnvSecretName,
+		EnvironmentVariables: envVars,
+	}
+}
+
+// AllProcesses returns the processes of the app, with the process fields of the app merged into its web process
+func (a ManifestApplication) AllProcesses() []ManifestApplicationProcess {
+	web := ManifestApplicationProcess{
+		Type:                         "web",
+		Command:                      a.Command,
+		DiskQuota:                    a.DiskQuota,
+		HealthCheckHTTPEndpoint:      a.HealthCheckHTTPEndpoint,
+		HealthCheckInvocationTimeout: a.HealthCheckInvocationTimeout,
+		HealthCheckType:              a.HealthCheckType,
+		Instances:                    a.Instances,
+		Memory:                       a.Memory,
+		Timeout:                      a.Timeout,
+	}
+
+	var processes []ManifestApplicationProcess
+	webDeclared := false
+	for _, process := range a.Processes {
+		if process.Type == "web" {
+			process = web.overriddenBy(process)
+			webDeclared = true
+		}
+		processes = append(processes, process)
+	}
+	if !webDeclared && web != (ManifestApplicationProcess{Type: "web"}) {
+		processes = append([]ManifestApplicationProcess{web}, processes...)
+	}
+
+	return processes
+}
+
+func (p ManifestApplicationProcess) overriddenBy(other ManifestApplicationProcess) ManifestApplicationProcess {
+	if other.Command != nil {
+		p.Command = other.Command
+	}
+	if other.DiskQuota != nil {
+		p.DiskQuota = other.DiskQuota
+	}
+	if other.HealthCheckHTTPEndpoint != nil {
+		p.HealthCheckHTTPEndpoint = other.HealthCheckHTTPEndpoint
+	}
+	if other.HealthCheckInvocationTimeout != nil {
+		p.HealthCheckInvocationTimeout = other.HealthCheckInvocationTimeout
+	}
+	if other.HealthCheckType != nil {
+		p.HealthCheckType = other.HealthCheckType
+	}
+	if other.Instances != nil {
+		p.Instances = other.Instances
+	}
+	if other.Memory != nil {
+		p.Memory = other.Memory
+	}
+	if other.Timeout != nil {
+		p.Timeout = other.Timeout
+	}
+	return p
+}
+
+// HasScale reports whether the manifest sets the instances, memory or disk of the process
+func (p ManifestApplicationProcess) HasScale() bool {
+	return p.Instances != nil || p.Memory != nil || p.DiskQuota != nil
+}
+
+// HasPatch reports whether the manifest sets the command or health check of the process
+func (p ManifestApplicationProcess) HasPatch() bool {
+	return p.Command != nil || p.HealthCheckType != nil || p.HealthCheckHTTPEndpoint != nil ||
+		p.HealthCheckInvocationTimeout != nil || p.Timeout != nil
+}
+
+func (p ManifestApplicationProcess) ToProcessCreateMessage(appGUID, spaceGUID string) repositories.ProcessCreateMessage {
+	message := repositories.ProcessCreateMessage{
+		AppGUID:     appGUID,
+		SpaceGUID:   spaceGUID,
+		Type:        p.Type,
+		MemoryMB:    defaultProcessMemoryMB,
+		DiskQuotaMB: defaultProcessDiskMB,
+		HealthCheck: repositories.HealthCheck{Type: defaultOtherHealthCheck},
+	}
+	if p.Type == "web" {
+		message.Instances = defaultWebInstances
+		message.HealthCheck.Type = defaultWebHealthCheck
+	}
+
+	scale := p.ToProcessScaleMessage("", spaceGUID)
+	if scale.Instances != nil {
+		message.Instances = *scale.Instances
+	}
+	if scale.MemoryMB != nil {
+		message.MemoryMB = *scale.MemoryMB
+	}
+	if scale.DiskMB != nil {
+		message.DiskQuotaMB = *scale.DiskMB
+	}
+
+	patch := p.ToProcessPatchMessage("", spaceGUID)
+	if patch.Command != nil {
+		message.Command = *patch.Command
+	}
+	if patch.HealthCheckType != nil {
+		message.HealthCheck.Type = *patch.HealthCheckType
+	}
+	if patch.HealthCheckHTTPEndpoint != nil {
+		message.HealthCheck.Data.HTTPEndpoint = *patch.HealthCheckHTTPEndpoint
+	}
+	if patch.HealthCheckTimeoutSeconds != nil {
+		message.HealthCheck.Data.TimeoutSeconds = *patch.HealthCheckTimeoutSeconds
+	}
+	if patch.HealthCheckInvocationTimeoutSeconds != nil {
+		message.HealthCheck.Data.InvocationTimeoutSeconds = *patch.HealthCheckInvocationTimeoutSeconds
+	}
+
+	return message
+}
+
+// ToProcessScaleMessage returns the scale of the process. It expects a validated manifest, so memory and disk that
+// cannot be parsed are left as they are.
+func (p ManifestApplicationProcess) ToProcessScaleMessage(processGUID, spaceGUID string) repositories.ProcessScaleMessage {
+	return repositories.ProcessScaleMessage{
+		GUID:      processGUID,
+		SpaceGUID: spaceGUID,
+		ProcessScaleValues: repositories.ProcessScaleValues{
+			Instances: p.Instances,
+			MemoryMB:  megabytesOrNil(p.Memory),
+			DiskMB:    megabytesOrNil(p.DiskQuota),
+		},
+	}
+}
+
+// ToProcessPatchMessage returns the command and health check of the process. The "none" health check type of
+// manifests is the "process" type of the CF API.
+func (p ManifestApplicationProcess) ToProcessPatchMessage(processGUID, spaceGUID string) repositories.ProcessPatchMessage {
+	healthCheckType := p.HealthCheckType
+	if healthCheckType != nil && *healthCheckType == "none" {
+		processType := defaultOtherHealthCheck
+		healthCheckType = &processType
+	}
+
+	return repositories.ProcessPatchMessage{
+		ProcessGUID:                         processGUID,
+		SpaceGUID:                           spaceGUID,
+		Command:                             p.Command,
+		HealthCheckType:                     healthCheckType,
+		HealthCheckHTTPEndpoint:             p.HealthCheckHTTPEndpoint,
+		HealthCheckTimeoutSeconds:           p.Timeout,
+		HealthCheckInvocationTimeoutSeconds: p.HealthCheckInvocationTimeout,
+	}
+}
+
+var megabytesRegex = regexp.MustCompile(`^(\d+)\s*([KMGT]?B?)$`)
+
+var megabytesPerUnit = map[string]float64{
+	"B":  1.0 / (1024 * 1024),
+	"K":  1.0 / 1024,
+	"KB": 1.0 / 1024,
+	"M":  1,
+	"MB": 1,
+	"G":  1024,
+	"GB": 1024,
+	"T":  1024 * 1024,
+	"TB": 1024 * 1024,
+}
+
+// ParseMegabytes parses an amount of memory or disk of a manifest, such as "256M" or "1G", into megabytes. Amounts
+// that are not whole megabytes are rounded down.
+func ParseMegabytes(amount string) (int64, error) {
+	match := megabytesRegex.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(amount)))
+	if match == nil || match[2] == "" {
+		return 0, errors.New("must use a supported unit: B, K, KB, M, MB, G, GB, T, or TB")
+	}
+
+	value, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("is too large: %w", err)
+	}
+
+	return int64(float64(value) * megabytesPerUnit[match[2]]), nil
+}
+
+func megabytesOrNil(amount *string) *int64 {
+	if amount == nil {
+		return nil
+	}
+	megabytes, err := ParseMegabytes(*amount)
+	if err != nil {
+		return nil
+	}
+	return &megabytes
+}
@@ -18,3 +18,11 @@ func (p OrgCreate) ToRecord() repositories.OrgRecord {
 		Annotations: p.Metadata.Annotations,
 	}
 }
+
+type OrgPatch struct {
+	Metadata MetadataPatch `json:"metadata"`
+}
+
+func (p OrgPatch) ToMessage(orgGUID string) repositories.MetadataPatchMessage {
+	return p.Metadata.toMessage(orgGUID, "")
+}
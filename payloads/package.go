@@ -3,8 +3,17 @@ package payloads
 import "code.cloudfoundry.org/cf-k8s-api/repositories"
 
 type PackageCreate struct {
-	Type          string                `json:"type" validate:"required,oneof='bits'"`
+	Type          string                `json:"type" validate:"required,oneof=bits docker"`
 	Relationships *PackageRelationships `json:"relationships" validate:"required"`
+	Data          *PackageData          `json:"data"`
+}
+
+// PackageData is the image of a docker package and the credentials of its registry. Docker packages are rejected and
+// bits packages must leave the data out, so it is only decoded to be validated.
+type PackageData struct {
+	Image    string  `json:"image"`
+	Username *string `json:"username"`
+	Password *string `json:"password"`
 }
 
 type PackageRelationships struct {
@@ -18,3 +27,11 @@ func (m PackageCreate) ToMessage(spaceGUID string) repositories.PackageCreateMes
 		SpaceGUID: spaceGUID,
 	}
 }
+
+type PackagePatch struct {
+	Metadata MetadataPatch `json:"metadata"`
+}
+
+func (p PackagePatch) ToMessage(packageGUID string, spaceGUID string) repositories.MetadataPatchMessage {
+	return p.Metadata.toMessage(packageGUID, spaceGUID)
+}
@@ -0,0 +1,54 @@
+package payloads
+
+import "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+type ProcessScale struct {
+	Instances *int   `json:"instances" validate:"omitempty,gte=0"`
+	MemoryMB  *int64 `json:"memory_in_mb" validate:"omitempty,gt=0"`
+	DiskMB    *int64 `json:"disk_in_mb" validate:"omitempty,gt=0"`
+}
+
+func (p ProcessScale) ToMessage(processGUID string, spaceGUID string) repositories.ProcessScaleMessage {
+	return repositories.ProcessScaleMessage{
+		GUID:      processGUID,
+		SpaceGUID: spaceGUID,
+		ProcessScaleValues: repositories.ProcessScaleValues{
+			Instances: p.Instances,
+			MemoryMB:  p.MemoryMB,
+			DiskMB:    p.DiskMB,
+		},
+	}
+}
+
+type ProcessPatch struct {
+	Command     *string                  `json:"command" validate:"omitempty,min=1,max=4096"`
+	HealthCheck *ProcessPatchHealthCheck `json:"health_check"`
+}
+
+type ProcessPatchHealthCheck struct {
+	Type *string                      `json:"type" validate:"omitempty,oneof=port process http"`
+	Data *ProcessPatchHealthCheckData `json:"data"`
+}
+
+type ProcessPatchHealthCheckData struct {
+	Timeout           *int64  `json:"timeout" validate:"omitempty,gte=1"`
+	InvocationTimeout *int64  `json:"invocation_timeout" validate:"omitempty,gte=1"`
+	Endpoint          *string `json:"endpoint" validate:"omitempty,startswith=/"`
+}
+
+func (p ProcessPatch) ToMessage(processGUID string, spaceGUID string) repositories.ProcessPatchMessage {
+	message := repositories.ProcessPatchMessage{
+		ProcessGUID: processGUID,
+		SpaceGUID:   spaceGUID,
+		Command:     p.Command,
+	}
+	if p.HealthCheck != nil {
+		message.HealthCheckType = p.HealthCheck.Type
+		if p.HealthCheck.Data != nil {
+			message.HealthCheckHTTPEndpoint = p.HealthCheck.Data.Endpoint
+			message.HealthCheckTimeoutSeconds = p.HealthCheck.Data.Timeout
+			message.HealthCheckInvocationTimeoutSeconds = p.HealthCheck.Data.InvocationTimeout
+		}
+	}
+	return message
+}
@@ -0,0 +1,37 @@
+package payloads
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+type ResourceMatches struct {
+	Resources []ResourceMatch `json:"resources" validate:"required,dive"`
+}
+
+// ResourceMatch is the fingerprint of a file of an app, as sent to match resources and with the cached files of a
+// package upload
+type ResourceMatch struct {
+	Checksum    ResourceChecksum `json:"checksum"`
+	SizeInBytes int64            `json:"size_in_bytes" validate:"gte=0"`
+	Path        string           `json:"path" validate:"required"`
+	Mode        string           `json:"mode"`
+}
+
+type ResourceChecksum struct {
+	Value string `json:"value" validate:"len=40,hexadecimal"`
+}
+
+func (m ResourceMatches) ToRecords() []repositories.ResourceRecord {
+	records := make([]repositories.ResourceRecord, 0, len(m.Resources))
+	for _, resource := range m.Resources {
+		records = append(records, repositories.ResourceRecord{
+			SHA1:        strings.ToLower(resource.Checksum.Value),
+			SizeInBytes: resource.SizeInBytes,
+			Path:        resource.Path,
+			Mode:        resource.Mode,
+		})
+	}
+	return records
+}
@@ -31,3 +31,11 @@ func (p RouteCreate) ToRecord() repositories.RouteRecord {
 		UpdatedAt:   "",
 	}
 }
+
+type RoutePatch struct {
+	Metadata MetadataPatch `json:"metadata"`
+}
+
+func (p RoutePatch) ToMessage(routeGUID string, spaceGUID string) repositories.MetadataPatchMessage {
+	return p.Metadata.toMessage(routeGUID, spaceGUID)
+}
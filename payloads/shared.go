@@ -1,13 +1,17 @@
 package payloads
 
+import "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+// Lifecycle is how the droplets of an app are made. The data is required for buildpack lifecycles. Docker lifecycles
+// are rejected, so their data isn't required.
 type Lifecycle struct {
-	Type string        `json:"type" validate:"required"`
-	Data LifecycleData `json:"data" validate:"required"`
+	Type string        `json:"type" validate:"required,oneof=buildpack docker"`
+	Data LifecycleData `json:"data"`
 }
 
 type LifecycleData struct {
-	Buildpacks []string `json:"buildpacks" validate:"required"`
-	Stack      string   `json:"stack" validate:"required"`
+	Buildpacks []string `json:"buildpacks"`
+	Stack      string   `json:"stack"`
 }
 
 type Relationship struct {
@@ -22,3 +26,20 @@ type Metadata struct {
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
 }
+
+// MetadataPatch is the metadata of a PATCH request. A null value deletes the label or annotation.
+type MetadataPatch struct {
+	Labels      map[string]*string `json:"labels"`
+	Annotations map[string]*string `json:"annotations"`
+}
+
+func (p MetadataPatch) toMessage(guid string, spaceGUID string) repositories.MetadataPatchMessage {
+	return repositories.MetadataPatchMessage{
+		GUID:      guid,
+		SpaceGUID: spaceGUID,
+		MetadataPatch: repositories.MetadataPatch{
+			Labels:      p.Labels,
+			Annotations: p.Annotations,
+		},
+	}
+}
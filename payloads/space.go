@@ -22,3 +22,11 @@ func (p SpaceCreate) ToRecord() repositories.SpaceRecord {
 		Annotations:      p.Metadata.Annotations,
 	}
 }
+
+type SpacePatch struct {
+	Metadata MetadataPatch `json:"metadata"`
+}
+
+func (p SpacePatch) ToMessage(spaceGUID string) repositories.MetadataPatchMessage {
+	return p.Metadata.toMessage(spaceGUID, "")
+}
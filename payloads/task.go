@@ -0,0 +1,41 @@
+package payloads
+
+import (
+	"github.com/google/uuid"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+const (
+	DefaultTaskMemoryMB = 1024
+	DefaultTaskDiskMB   = 1024
+)
+
+type TaskCreate struct {
+	Name     string `json:"name" validate:"max=255"`
+	Command  string `json:"command" validate:"required,max=4096"`
+	MemoryMB *int64 `json:"memory_in_mb" validate:"omitempty,gt=0"`
+	DiskMB   *int64 `json:"disk_in_mb" validate:"omitempty,gt=0"`
+}
+
+// ToMessage fills in the defaults for the fields the payload leaves out. Tasks without a name get a random one.
+func (p TaskCreate) ToMessage(app repositories.AppRecord, droplet repositories.DropletRecord) repositories.TaskCreateMessage {
+	message := repositories.TaskCreateMessage{
+		Name:     p.Name,
+		Command:  p.Command,
+		MemoryMB: DefaultTaskMemoryMB,
+		DiskMB:   DefaultTaskDiskMB,
+		App:      app,
+		Droplet:  droplet,
+	}
+	if message.Name == "" {
+		message.Name = uuid.NewString()[:8]
+	}
+	if p.MemoryMB != nil {
+		message.MemoryMB = *p.MemoryMB
+	}
+	if p.DiskMB != nil {
+		message.DiskMB = *p.DiskMB
+	}
+	return message
+}
@@ -66,8 +66,8 @@ func ForApp(responseApp repositories.AppRecord, baseURL url.URL) AppResponse {
 			},
 		},
 		Metadata: Metadata{
-			Labels:      map[string]string{},
-			Annotations: map[string]string{},
+			Labels:      userMetadata(responseApp.Labels),
+			Annotations: userMetadata(responseApp.Annotations),
 		},
 		Links: AppLinks{
 			Self: Link{
@@ -115,24 +115,15 @@ func ForApp(responseApp repositories.AppRecord, baseURL url.URL) AppResponse {
 	}
 }
 
-func ForAppList(appRecordList []repositories.AppRecord, baseURL url.URL) AppListResponse {
+func ForAppList(appRecordList []repositories.AppRecord, baseURL url.URL, listPage ListPage) AppListResponse {
 	appResponses := make([]AppResponse, 0, len(appRecordList))
 	for _, app := range appRecordList {
 		appResponses = append(appResponses, ForApp(app, baseURL))
 	}
 
 	appListResponse := AppListResponse{
-		PaginationData: PaginationData{
-			TotalResults: len(appResponses),
-			TotalPages:   1,
-			First: PageRef{
-				HREF: buildURL(baseURL).appendPath(appsBase).setQuery("page=1").build(),
-			},
-			Last: PageRef{
-				HREF: buildURL(baseURL).appendPath(appsBase).setQuery("page=1").build(),
-			},
-		},
-		Resources: appResponses,
+		PaginationData: forPagination(buildURL(baseURL).appendPath(appsBase), listPage),
+		Resources:      appResponses,
 	}
 
 	return appListResponse
@@ -165,3 +156,64 @@ func ForCurrentDroplet(record repositories.CurrentDropletRecord, baseURL url.URL
 		},
 	}
 }
+
+type AppEnvVarsResponse struct {
+	Var   map[string]string `json:"var"`
+	Links AppEnvVarsLinks   `json:"links"`
+}
+
+type AppEnvVarsLinks struct {
+	Self Link `json:"self"`
+	App  Link `json:"app"`
+}
+
+func ForAppEnvVars(record repositories.AppEnvVarsRecord, baseURL url.URL) AppEnvVarsResponse {
+	return AppEnvVarsResponse{
+		Var: orEmptyMap(record.EnvironmentVariables),
+		Links: AppEnvVarsLinks{
+			Self: Link{
+				HREF: buildURL(baseURL).appendPath(appsBase, record.AppGUID, "environment_variables").build(),
+			},
+			App: Link{
+				HREF: buildURL(baseURL).appendPath(appsBase, record.AppGUID).build(),
+			},
+		},
+	}
+}
+
+type AppEnvResponse struct {
+	StagingEnvJSON       map[string]string      `json:"staging_env_json"`
+	RunningEnvJSON       map[string]string      `json:"running_env_json"`
+	EnvironmentVariables map[string]string      `json:"environment_variables"`
+	SystemEnvJSON        map[string]interface{} `json:"system_env_json"`
+	ApplicationEnvJSON   map[string]interface{} `json:"application_env_json"`
+}
+
+// ForAppEnv presents the environment of an app. There are no environment variable groups or service bindings yet, so
+// the staging and running groups and VCAP_SERVICES are always empty.
+func ForAppEnv(app repositories.AppRecord, envVars repositories.AppEnvVarsRecord, routes []repositories.RouteRecord, baseURL url.URL) AppEnvResponse {
+	uris := []string{}
+	for _, route := range routes {
+		uris = append(uris, routeURL(route))
+	}
+
+	return AppEnvResponse{
+		StagingEnvJSON:       map[string]string{},
+		RunningEnvJSON:       map[string]string{},
+		EnvironmentVariables: orEmptyMap(envVars.EnvironmentVariables),
+		SystemEnvJSON: map[string]interface{}{
+			"VCAP_SERVICES": map[string]interface{}{},
+		},
+		ApplicationEnvJSON: map[string]interface{}{
+			"VCAP_APPLICATION": map[string]interface{}{
+				"application_id":   app.GUID,
+				"application_name": app.Name,
+				"name":             app.Name,
+				"space_id":         app.SpaceGUID,
+				"application_uris": uris,
+				"uris":             uris,
+				"cf_api":           buildURL(baseURL).build(),
+			},
+		},
+	}
+}
@@ -56,8 +56,8 @@ func ForBuild(buildRecord repositories.BuildRecord, baseURL url.URL) BuildRespon
 			},
 		},
 		Metadata: Metadata{
-			Labels:      map[string]string{},
-			Annotations: map[string]string{},
+			Labels:      userMetadata(buildRecord.Labels),
+			Annotations: userMetadata(buildRecord.Annotations),
 		},
 		Links: map[string]Link{
 			"self": {
@@ -0,0 +1,137 @@
+package presenter
+
+import (
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+const deploymentsBase = "/v3/deployments"
+
+type DeploymentResponse struct {
+	GUID            string                      `json:"guid"`
+	State           string                      `json:"state"`
+	Status          DeploymentStatus            `json:"status"`
+	Strategy        string                      `json:"strategy"`
+	Droplet         RelationshipData            `json:"droplet"`
+	PreviousDroplet RelationshipData            `json:"previous_droplet"`
+	NewProcesses    []DeploymentProcessResponse `json:"new_processes"`
+	Relationships   Relationships               `json:"relationships"`
+	Metadata        Metadata                    `json:"metadata"`
+	CreatedAt       string                      `json:"created_at"`
+	UpdatedAt       string                      `json:"updated_at"`
+	Links           DeploymentLinks             `json:"links"`
+}
+
+type DeploymentStatus struct {
+	Value   string                  `json:"value"`
+	Reason  string                  `json:"reason"`
+	Details DeploymentStatusDetails `json:"details"`
+}
+
+type DeploymentStatusDetails struct {
+	LastStatusChange string `json:"last_status_change"`
+}
+
+type DeploymentProcessResponse struct {
+	GUID string `json:"guid"`
+	Type string `json:"type"`
+}
+
+type DeploymentLinks struct {
+	Self     Link  `json:"self"`
+	App      Link  `json:"app"`
+	Cancel   Link  `json:"cancel"`
+	Continue *Link `json:"continue,omitempty"`
+}
+
+type DeploymentListResponse struct {
+	PaginationData PaginationData       `json:"pagination"`
+	Resources      []DeploymentResponse `json:"resources"`
+}
+
+func ForDeployment(deployment repositories.DeploymentRecord, baseURL url.URL) DeploymentResponse {
+	var continueLink *Link
+	if deployment.Strategy == repositories.DeploymentStrategyCanary {
+		continueLink = &Link{
+			HREF:   buildURL(baseURL).appendPath(deploymentsBase, deployment.GUID, "actions", "continue").build(),
+			Method: http.MethodPost,
+		}
+	}
+
+	return DeploymentResponse{
+		GUID:  deployment.GUID,
+		State: deploymentState(deployment),
+		Status: DeploymentStatus{
+			Value:  deployment.StatusValue,
+			Reason: deployment.StatusReason,
+			Details: DeploymentStatusDetails{
+				LastStatusChange: deployment.LastStatusChange,
+			},
+		},
+		Strategy:        deployment.Strategy,
+		Droplet:         RelationshipData{GUID: deployment.DropletGUID},
+		PreviousDroplet: RelationshipData{GUID: deployment.PreviousDropletGUID},
+		NewProcesses:    forDeploymentProcesses(deployment.NewProcesses),
+		Relationships: Relationships{
+			"app": {
+				Data: RelationshipData{
+					GUID: deployment.AppGUID,
+				},
+			},
+		},
+		Metadata: Metadata{
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		CreatedAt: deployment.CreatedAt,
+		UpdatedAt: deployment.UpdatedAt,
+		Links: DeploymentLinks{
+			Self: Link{
+				HREF: buildURL(baseURL).appendPath(deploymentsBase, deployment.GUID).build(),
+			},
+			App: Link{
+				HREF: buildURL(baseURL).appendPath(appsBase, deployment.AppGUID).build(),
+			},
+			Cancel: Link{
+				HREF:   buildURL(baseURL).appendPath(deploymentsBase, deployment.GUID, "actions", "cancel").build(),
+				Method: http.MethodPost,
+			},
+			Continue: continueLink,
+		},
+	}
+}
+
+func forDeploymentProcesses(processes []repositories.DeploymentProcessRecord) []DeploymentProcessResponse {
+	processResponses := make([]DeploymentProcessResponse, 0, len(processes))
+	for _, process := range processes {
+		processResponses = append(processResponses, DeploymentProcessResponse{
+			GUID: process.GUID,
+			Type: process.Type,
+		})
+	}
+	return processResponses
+}
+
+func ForDeploymentList(deployments []repositories.DeploymentRecord, baseURL url.URL, listPage ListPage) DeploymentListResponse {
+	deploymentResponses := make([]DeploymentResponse, 0, len(deployments))
+	for _, deployment := range deployments {
+		deploymentResponses = append(deploymentResponses, ForDeployment(deployment, baseURL))
+	}
+
+	return DeploymentListResponse{
+		PaginationData: forPagination(buildURL(baseURL).appendPath(deploymentsBase), listPage),
+		Resources:      deploymentResponses,
+	}
+}
+
+// deploymentState is the deprecated state of a deployment, which older clients read instead of its status
+func deploymentState(deployment repositories.DeploymentRecord) string {
+	switch deployment.StatusReason {
+	case repositories.DeploymentStatusReasonPaused, repositories.DeploymentStatusReasonCanceling, repositories.DeploymentStatusReasonDeployed, repositories.DeploymentStatusReasonCanceled:
+		return deployment.StatusReason
+	default:
+		return repositories.DeploymentStatusReasonDeploying
+	}
+}
@@ -0,0 +1,62 @@
+package presenter
+
+import (
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+const (
+	jobsBase = "/v3/jobs"
+)
+
+type JobResponse struct {
+	GUID      string           `json:"guid"`
+	CreatedAt string           `json:"created_at"`
+	UpdatedAt string           `json:"updated_at"`
+	Operation string           `json:"operation"`
+	State     string           `json:"state"`
+	Errors    []PresentedError `json:"errors"`
+	Warnings  []JobWarning     `json:"warnings"`
+	Links     map[string]Link  `json:"links"`
+}
+
+type JobWarning struct {
+	Detail string `json:"detail"`
+}
+
+func ForJob(job repositories.JobRecord, baseURL url.URL) JobResponse {
+	errors := []PresentedError{}
+	for _, jobErr := range job.Errors {
+		presentedErr := PresentedError{
+			Detail: jobErr.Detail,
+			Title:  jobErr.Title,
+			Code:   jobErr.Code,
+		}
+		if presentedErr.Code == 0 {
+			presentedErr.Title = "UnknownError"
+			presentedErr.Code = 10001
+		}
+		errors = append(errors, presentedErr)
+	}
+
+	return JobResponse{
+		GUID:      job.GUID,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+		Operation: job.Operation,
+		State:     job.State,
+		Errors:    errors,
+		Warnings:  []JobWarning{},
+		Links: map[string]Link{
+			"self": {
+				HREF: JobURL(job.GUID, baseURL),
+			},
+		},
+	}
+}
+
+// JobURL is the URL that a job can be polled at, which is returned in the Location header of asynchronous requests
+func JobURL(jobGUID string, baseURL url.URL) string {
+	return buildURL(baseURL).appendPath(jobsBase, jobGUID).build()
+}
@@ -0,0 +1,100 @@
+package presenter
+
+import (
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+// ManifestResponse is an app manifest, rendered as YAML by the manifest export and as JSON in manifest diffs
+type ManifestResponse struct {
+	Applications []ManifestApplication `json:"applications" yaml:"applications"`
+}
+
+type ManifestApplication struct {
+	Name       string                       `json:"name" yaml:"name"`
+	Stack      string                       `json:"stack,omitempty" yaml:"stack,omitempty"`
+	Buildpacks []string                     `json:"buildpacks,omitempty" yaml:"buildpacks,omitempty"`
+	Env        map[string]string            `json:"env,omitempty" yaml:"env,omitempty"`
+	Processes  []ManifestApplicationProcess `json:"processes,omitempty" yaml:"processes,omitempty"`
+	Routes     []ManifestRoute              `json:"routes,omitempty" yaml:"routes,omitempty"`
+}
+
+type ManifestApplicationProcess struct {
+	Type                         string `json:"type,omitempty" yaml:"type,omitempty"`
+	Instances                    *int   `json:"instances,omitempty" yaml:"instances,omitempty"`
+	Memory                       string `json:"memory,omitempty" yaml:"memory,omitempty"`
+	DiskQuota                    string `json:"disk_quota,omitempty" yaml:"disk_quota,omitempty"`
+	Command                      string `json:"command,omitempty" yaml:"command,omitempty"`
+	HealthCheckType              string `json:"health-check-type,omitempty" yaml:"health-check-type,omitempty"`
+	HealthCheckHTTPEndpoint      string `json:"health-check-http-endpoint,omitempty" yaml:"health-check-http-endpoint,omitempty"`
+	Timeout                      int64  `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	HealthCheckInvocationTimeout int64  `json:"health-check-invocation-timeout,omitempty" yaml:"health-check-invocation-timeout,omitempty"`
+}
+
+type ManifestRoute struct {
+	Route string `json:"route" yaml:"route"`
+}
+
+// ManifestDiffResponse lists the changes that applying a manifest would make, as JSON Patch operations on the
+// manifest of the current state of its apps
+type ManifestDiffResponse struct {
+	Diff []ManifestDiffEntry `json:"diff"`
+}
+
+type ManifestDiffEntry struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Was   interface{} `json:"was,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ForManifestApplication renders the live configuration of an app as an application of a manifest. The domains of
+// routes must be filled in, and processes are ordered by type.
+func ForManifestApplication(
+	app repositories.AppRecord,
+	envVars map[string]string,
+	processes []repositories.ProcessRecord,
+	routes []repositories.RouteRecord,
+) ManifestApplication {
+	manifestApp := ManifestApplication{
+		Name:       app.Name,
+		Stack:      app.Lifecycle.Data.Stack,
+		Buildpacks: app.Lifecycle.Data.Buildpacks,
+	}
+	if len(envVars) > 0 {
+		manifestApp.Env = envVars
+	}
+
+	for _, process := range processes {
+		instances := process.Instances
+		manifestApp.Processes = append(manifestApp.Processes, ManifestApplicationProcess{
+			Type:                         process.Type,
+			Instances:                    &instances,
+			Memory:                       megabytesString(process.MemoryMB),
+			DiskQuota:                    megabytesString(process.DiskQuotaMB),
+			Command:                      process.Command,
+			HealthCheckType:              process.HealthCheck.Type,
+			HealthCheckHTTPEndpoint:      process.HealthCheck.Data.HTTPEndpoint,
+			Timeout:                      process.HealthCheck.Data.TimeoutSeconds,
+			HealthCheckInvocationTimeout: process.HealthCheck.Data.InvocationTimeoutSeconds,
+		})
+	}
+	sort.Slice(manifestApp.Processes, func(i, j int) bool {
+		return manifestApp.Processes[i].Type < manifestApp.Processes[j].Type
+	})
+
+	for _, route := range routes {
+		manifestApp.Routes = append(manifestApp.Routes, ManifestRoute{Route: routeURL(route)})
+	}
+
+	return manifestApp
+}
+
+func megabytesString(megabytes int64) string {
+	if megabytes == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dM", megabytes)
+}
@@ -35,6 +35,7 @@ type OrgLinks struct {
 	Domains       *Link `json:"domains,omitempty"`
 	DefaultDomain *Link `json:"default_domain,omitempty"`
 	Quota         *Link `json:"quota,omitempty"`
+	Job           *Link `json:"job,omitempty"`
 }
 
 type SpaceListResponse struct {
@@ -57,11 +58,11 @@ type SpaceLinks struct {
 	Organization *Link `json:"organization"`
 }
 
-func ForCreateOrg(org repositories.OrgRecord, apiBaseURL url.URL) OrgResponse {
+func ForOrg(org repositories.OrgRecord, apiBaseURL url.URL) OrgResponse {
 	return toOrgResponse(org, apiBaseURL)
 }
 
-func ForOrgList(orgs []repositories.OrgRecord, apiBaseURL url.URL) OrgListResponse {
+func ForOrgList(orgs []repositories.OrgRecord, apiBaseURL url.URL, listPage ListPage) OrgListResponse {
 	orgResponses := []OrgResponse{}
 
 	for _, org := range orgs {
@@ -69,44 +70,25 @@ func ForOrgList(orgs []repositories.OrgRecord, apiBaseURL url.URL) OrgListRespon
 	}
 
 	return OrgListResponse{
-		Pagination: PaginationData{
-			TotalResults: len(orgs),
-			TotalPages:   1,
-			First: PageRef{
-				HREF: buildURL(apiBaseURL).appendPath(orgsBase).setQuery("page=1").build(),
-			},
-			Last: PageRef{
-				HREF: buildURL(apiBaseURL).appendPath(orgsBase).setQuery("page=1").build(),
-			},
-		},
-		Resources: orgResponses,
+		Pagination: forPagination(buildURL(apiBaseURL).appendPath(orgsBase), listPage),
+		Resources:  orgResponses,
 	}
 }
 
-func ForCreateSpace(space repositories.SpaceRecord, apiBaseURL url.URL) SpaceResponse {
+func ForSpace(space repositories.SpaceRecord, apiBaseURL url.URL) SpaceResponse {
 	return toSpaceResponse(space, apiBaseURL)
 }
 
-func ForSpaceList(spaces []repositories.SpaceRecord, apiBaseURL url.URL) SpaceListResponse {
+func ForSpaceList(spaces []repositories.SpaceRecord, apiBaseURL url.URL, listPage ListPage) SpaceListResponse {
 	spaceResponses := []SpaceResponse{}
 
 	for _, space := range spaces {
 		spaceResponses = append(spaceResponses, toSpaceResponse(space, apiBaseURL))
 	}
 
-	paginationURL := buildURL(apiBaseURL).appendPath(spacesBase).setQuery("page=1").build()
 	return SpaceListResponse{
-		Pagination: PaginationData{
-			TotalResults: len(spaces),
-			TotalPages:   1,
-			First: PageRef{
-				HREF: paginationURL,
-			},
-			Last: PageRef{
-				HREF: paginationURL,
-			},
-		},
-		Resources: spaceResponses,
+		Pagination: forPagination(buildURL(apiBaseURL).appendPath(spacesBase), listPage),
+		Resources:  spaceResponses,
 	}
 }
 
@@ -117,8 +99,8 @@ func toSpaceResponse(space repositories.SpaceRecord, apiBaseURL url.URL) SpaceRe
 		CreatedAt: space.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt: space.CreatedAt.UTC().Format(time.RFC3339),
 		Metadata: Metadata{
-			Labels:      map[string]string{},
-			Annotations: map[string]string{},
+			Labels:      userMetadata(space.Labels),
+			Annotations: userMetadata(space.Annotations),
 		},
 		Relationships: Relationships{
 			"organization": Relationship{
@@ -146,8 +128,8 @@ func toOrgResponse(org repositories.OrgRecord, apiBaseURL url.URL) OrgResponse {
 		UpdatedAt: org.CreatedAt.UTC().Format(time.RFC3339),
 		Suspended: org.Suspended,
 		Metadata: Metadata{
-			Labels:      orEmptyMap(org.Labels),
-			Annotations: orEmptyMap(org.Annotations),
+			Labels:      userMetadata(org.Labels),
+			Annotations: userMetadata(org.Annotations),
 		},
 		Relationships: Relationships{},
 		Links: OrgLinks{
@@ -158,6 +140,18 @@ func toOrgResponse(org repositories.OrgRecord, apiBaseURL url.URL) OrgResponse {
 	}
 }
 
+// userMetadata returns the labels or annotations of a resource without the reserved ones, which hold the state of the
+// shim and the controllers
+func userMetadata(m map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range m {
+		if !repositories.IsReservedMetadataKey(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
 func orEmptyMap(m map[string]string) map[string]string {
 	if m == nil {
 		return map[string]string{}
@@ -62,8 +62,8 @@ func ForPackage(record repositories.PackageRecord, baseURL url.URL) PackageRespo
 			},
 		},
 		Metadata: Metadata{
-			Labels:      map[string]string{},
-			Annotations: map[string]string{},
+			Labels:      userMetadata(record.Labels),
+			Annotations: userMetadata(record.Annotations),
 		},
 	}
 }
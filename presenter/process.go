@@ -123,8 +123,8 @@ func ForProcess(responseProcess repositories.ProcessRecord, baseURL url.URL) Pro
 			},
 		},
 		Metadata: Metadata{
-			Labels:      responseProcess.Labels,
-			Annotations: responseProcess.Annotations,
+			Labels:      userMetadata(responseProcess.Labels),
+			Annotations: userMetadata(responseProcess.Annotations),
 		},
 		CreatedAt: responseProcess.CreatedAt,
 		UpdatedAt: responseProcess.UpdatedAt,
@@ -149,7 +149,7 @@ func ForProcess(responseProcess repositories.ProcessRecord, baseURL url.URL) Pro
 	}
 }
 
-func ForProcessList(processRecordList []repositories.ProcessRecord, baseURL url.URL, appGUID string) ProcessListResponse {
+func ForProcessList(processRecordList []repositories.ProcessRecord, baseURL url.URL, appGUID string, listPage ListPage) ProcessListResponse {
 	processResponses := make([]ProcessResponse, 0, len(processRecordList))
 	for _, process := range processRecordList {
 		processResponse := ForProcess(process, baseURL)
@@ -157,20 +157,83 @@ func ForProcessList(processRecordList []repositories.ProcessRecord, baseURL url.
 		processResponses = append(processResponses, processResponse)
 	}
 
-	pageHREF := buildURL(baseURL).appendPath(appsBase, appGUID, "processes").setQuery("page=1").build()
 	processListResponse := ProcessListResponse{
-		PaginationData: PaginationData{
-			TotalResults: len(processResponses),
-			TotalPages:   1,
-			First: PageRef{
-				HREF: pageHREF,
-			},
-			Last: PageRef{
-				HREF: pageHREF,
-			},
-		},
-		Resources: processResponses,
+		PaginationData: forPagination(buildURL(baseURL).appendPath(appsBase, appGUID, "processes"), listPage),
+		Resources:      processResponses,
 	}
 
 	return processListResponse
 }
+
+type ProcessStatsResponse struct {
+	Resources []ProcessInstanceStatsResponse `json:"resources"`
+}
+
+type ProcessInstanceStatsResponse struct {
+	Type          string                        `json:"type"`
+	Index         int                           `json:"index"`
+	State         string                        `json:"state"`
+	Usage         ProcessInstanceUsageResponse  `json:"usage"`
+	Host          string                        `json:"host"`
+	InstancePorts []ProcessInstancePortResponse `json:"instance_ports"`
+	Uptime        int64                         `json:"uptime"`
+	MemQuota      int64                         `json:"mem_quota"`
+	DiskQuota     int64                         `json:"disk_quota"`
+	FDSQuota      int64                         `json:"fds_quota"`
+	Restarts      int                           `json:"restarts"`
+	Details       *string                       `json:"details"`
+}
+
+// ProcessInstanceUsageResponse is empty when there are no metrics for an instance
+type ProcessInstanceUsageResponse struct {
+	Time string  `json:"time,omitempty"`
+	CPU  float64 `json:"cpu,omitempty"`
+	Mem  int64   `json:"mem,omitempty"`
+	Disk int64   `json:"disk,omitempty"`
+}
+
+type ProcessInstancePortResponse struct {
+	External int32 `json:"external"`
+	Internal int32 `json:"internal"`
+}
+
+// processFDSQuota is the limit on file descriptors of the containers of every process
+const processFDSQuota = 16384
+
+func ForProcessStats(instances []repositories.ProcessInstanceStatsRecord) ProcessStatsResponse {
+	resources := make([]ProcessInstanceStatsResponse, 0, len(instances))
+	for _, instance := range instances {
+		instanceResponse := ProcessInstanceStatsResponse{
+			Type:          instance.Type,
+			Index:         instance.Index,
+			State:         instance.State,
+			Host:          instance.Host,
+			InstancePorts: []ProcessInstancePortResponse{},
+			Uptime:        instance.UptimeSeconds,
+			MemQuota:      instance.MemQuotaBytes,
+			DiskQuota:     instance.DiskQuotaBytes,
+			FDSQuota:      processFDSQuota,
+			Restarts:      instance.Restarts,
+		}
+		if instance.Usage != nil {
+			instanceResponse.Usage = ProcessInstanceUsageResponse{
+				Time: instance.Usage.Time,
+				CPU:  instance.Usage.CPU,
+				Mem:  instance.Usage.MemoryBytes,
+				Disk: instance.Usage.DiskBytes,
+			}
+		}
+		if instance.State != repositories.ProcessInstanceDown {
+			for _, port := range instance.Ports {
+				instanceResponse.InstancePorts = append(instanceResponse.InstancePorts, ProcessInstancePortResponse{External: port, Internal: port})
+			}
+		}
+		if instance.Details != "" {
+			details := instance.Details
+			instanceResponse.Details = &details
+		}
+		resources = append(resources, instanceResponse)
+	}
+
+	return ProcessStatsResponse{Resources: resources}
+}
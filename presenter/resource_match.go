@@ -0,0 +1,31 @@
+package presenter
+
+import "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+type ResourceMatchesResponse struct {
+	Resources []ResourceMatchResponse `json:"resources"`
+}
+
+type ResourceMatchResponse struct {
+	Checksum    ResourceChecksum `json:"checksum"`
+	SizeInBytes int64            `json:"size_in_bytes"`
+	Path        string           `json:"path"`
+	Mode        string           `json:"mode"`
+}
+
+type ResourceChecksum struct {
+	Value string `json:"value"`
+}
+
+func ForResourceMatches(records []repositories.ResourceRecord) ResourceMatchesResponse {
+	response := ResourceMatchesResponse{Resources: []ResourceMatchResponse{}}
+	for _, record := range records {
+		response.Resources = append(response.Resources, ResourceMatchResponse{
+			Checksum:    ResourceChecksum{Value: record.SHA1},
+			SizeInBytes: record.SizeInBytes,
+			Path:        record.Path,
+			Mode:        record.Mode,
+		})
+	}
+	return response
+}
@@ -0,0 +1,132 @@
+package presenter
+
+import (
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+const revisionsBase = "/v3/revisions"
+
+type RevisionResponse struct {
+	GUID          string                     `json:"guid"`
+	Version       int                        `json:"version"`
+	Droplet       RelationshipData           `json:"droplet"`
+	Processes     map[string]RevisionProcess `json:"processes"`
+	Description   string                     `json:"description"`
+	Deployable    bool                       `json:"deployable"`
+	Relationships Relationships              `json:"relationships"`
+	Metadata      Metadata                   `json:"metadata"`
+	CreatedAt     string                     `json:"created_at"`
+	UpdatedAt     string                     `json:"updated_at"`
+	Links         RevisionLinks              `json:"links"`
+}
+
+type RevisionProcess struct {
+	// Command is null when the process runs the command of the droplet
+	Command *string `json:"command"`
+}
+
+type RevisionLinks struct {
+	Self                 Link `json:"self"`
+	App                  Link `json:"app"`
+	EnvironmentVariables Link `json:"environment_variables"`
+}
+
+type RevisionListResponse struct {
+	PaginationData PaginationData     `json:"pagination"`
+	Resources      []RevisionResponse `json:"resources"`
+}
+
+type RevisionEnvVarsResponse struct {
+	Var   map[string]string    `json:"var"`
+	Links RevisionEnvVarsLinks `json:"links"`
+}
+
+type RevisionEnvVarsLinks struct {
+	Self     Link `json:"self"`
+	Revision Link `json:"revision"`
+	App      Link `json:"app"`
+}
+
+func ForRevision(revision repositories.RevisionRecord, baseURL url.URL) RevisionResponse {
+	processes := map[string]RevisionProcess{}
+	for processType, command := range revision.Processes {
+		command := command
+		process := RevisionProcess{}
+		if command != "" {
+			process.Command = &command
+		}
+		processes[processType] = process
+	}
+
+	return RevisionResponse{
+		GUID:        revision.GUID,
+		Version:     revision.Version,
+		Droplet:     RelationshipData{GUID: revision.DropletGUID},
+		Processes:   processes,
+		Description: revision.Description,
+		Deployable:  revision.DropletGUID != "",
+		Relationships: Relationships{
+			"app": {
+				Data: RelationshipData{
+					GUID: revision.AppGUID,
+				},
+			},
+		},
+		Metadata: Metadata{
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		CreatedAt: revision.CreatedAt,
+		UpdatedAt: revision.UpdatedAt,
+		Links: RevisionLinks{
+			Self: Link{
+				HREF: buildURL(baseURL).appendPath(revisionsBase, revision.GUID).build(),
+			},
+			App: Link{
+				HREF: buildURL(baseURL).appendPath(appsBase, revision.AppGUID).build(),
+			},
+			EnvironmentVariables: Link{
+				HREF: buildURL(baseURL).appendPath(revisionsBase, revision.GUID, "environment_variables").build(),
+			},
+		},
+	}
+}
+
+func ForAppRevisionList(revisions []repositories.RevisionRecord, baseURL url.URL, appGUID string, listPage ListPage) RevisionListResponse {
+	return forRevisionList(revisions, baseURL, buildURL(baseURL).appendPath(appsBase, appGUID, "revisions"), listPage)
+}
+
+func ForAppDeployedRevisionList(revisions []repositories.RevisionRecord, baseURL url.URL, appGUID string, listPage ListPage) RevisionListResponse {
+	return forRevisionList(revisions, baseURL, buildURL(baseURL).appendPath(appsBase, appGUID, "revisions", "deployed"), listPage)
+}
+
+func forRevisionList(revisions []repositories.RevisionRecord, baseURL url.URL, listURL buildURL, listPage ListPage) RevisionListResponse {
+	revisionResponses := make([]RevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, ForRevision(revision, baseURL))
+	}
+
+	return RevisionListResponse{
+		PaginationData: forPagination(listURL, listPage),
+		Resources:      revisionResponses,
+	}
+}
+
+func ForRevisionEnvVars(revision repositories.RevisionRecord, baseURL url.URL) RevisionEnvVarsResponse {
+	return RevisionEnvVarsResponse{
+		Var: orEmptyMap(revision.EnvironmentVariables),
+		Links: RevisionEnvVarsLinks{
+			Self: Link{
+				HREF: buildURL(baseURL).appendPath(revisionsBase, revision.GUID, "environment_variables").build(),
+			},
+			Revision: Link{
+				HREF: buildURL(baseURL).appendPath(revisionsBase, revision.GUID).build(),
+			},
+			App: Link{
+				HREF: buildURL(baseURL).appendPath(appsBase, revision.AppGUID).build(),
+			},
+		},
+	}
+}
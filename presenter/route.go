@@ -94,8 +94,8 @@ func ForRoute(route repositories.RouteRecord, baseURL url.URL) RouteResponse {
 		},
 		Destinations: destinations,
 		Metadata: Metadata{
-			Labels:      map[string]string{},
-			Annotations: map[string]string{},
+			Labels:      userMetadata(route.Labels),
+			Annotations: userMetadata(route.Annotations),
 		},
 		Links: routeLinks{
 			Self: Link{
@@ -114,47 +114,29 @@ func ForRoute(route repositories.RouteRecord, baseURL url.URL) RouteResponse {
 	}
 }
 
-func ForRouteList(routeRecordList []repositories.RouteRecord, baseURL url.URL) RouteListResponse {
+func ForRouteList(routeRecordList []repositories.RouteRecord, baseURL url.URL, listPage ListPage) RouteListResponse {
 	routeResponses := make([]RouteResponse, 0, len(routeRecordList))
 	for _, routeRecord := range routeRecordList {
 		routeResponses = append(routeResponses, ForRoute(routeRecord, baseURL))
 	}
 
 	routeListResponse := RouteListResponse{
-		PaginationData: PaginationData{
-			TotalResults: len(routeResponses),
-			TotalPages:   1,
-			First: PageRef{
-				HREF: buildURL(baseURL).appendPath(routesBase).setQuery("page=1").build(),
-			},
-			Last: PageRef{
-				HREF: buildURL(baseURL).appendPath(routesBase).setQuery("page=1").build(),
-			},
-		},
-		Resources: routeResponses,
+		PaginationData: forPagination(buildURL(baseURL).appendPath(routesBase), listPage),
+		Resources:      routeResponses,
 	}
 
 	return routeListResponse
 }
 
-func ForAppRouteList(routeRecordList []repositories.RouteRecord, baseURL url.URL, appGUID string) RouteListResponse {
+func ForAppRouteList(routeRecordList []repositories.RouteRecord, baseURL url.URL, appGUID string, listPage ListPage) RouteListResponse {
 	routeResponses := make([]RouteResponse, 0, len(routeRecordList))
 	for _, routeRecord := range routeRecordList {
 		routeResponses = append(routeResponses, ForRoute(routeRecord, baseURL))
 	}
 
 	routeListResponse := RouteListResponse{
-		PaginationData: PaginationData{
-			TotalResults: len(routeResponses),
-			TotalPages:   1,
-			First: PageRef{
-				HREF: buildURL(baseURL).appendPath(appsBase, appGUID, "routes").setQuery("page=1").build(),
-			},
-			Last: PageRef{
-				HREF: buildURL(baseURL).appendPath(appsBase, appGUID, "routes").setQuery("page=1").build(),
-			},
-		},
-		Resources: routeResponses,
+		PaginationData: forPagination(buildURL(baseURL).appendPath(appsBase, appGUID, "routes"), listPage),
+		Resources:      routeResponses,
 	}
 
 	return routeListResponse
@@ -3,6 +3,7 @@ package presenter
 import (
 	"net/url"
 	"path"
+	"strconv"
 )
 
 type Lifecycle struct {
@@ -40,14 +41,55 @@ type PaginationData struct {
 	TotalPages   int     `json:"total_pages"`
 	First        PageRef `json:"first"`
 	Last         PageRef `json:"last"`
-	Next         *int    `json:"next"`
-	Previous     *int    `json:"previous"`
+	Next         *PageRef `json:"next"`
+	Previous     *PageRef `json:"previous"`
 }
 
 type PageRef struct {
 	HREF string `json:"href"`
 }
 
+// ListPage describes the page of a list that is presented. The query of the list request is repeated in the pagination links.
+type ListPage struct {
+	Page         int
+	PerPage      int
+	TotalResults int
+	Query        url.Values
+}
+
+func forPagination(listURL buildURL, listPage ListPage) PaginationData {
+	totalPages := (listPage.TotalResults + listPage.PerPage - 1) / listPage.PerPage
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	pageRef := func(page int) *PageRef {
+		query := url.Values{}
+		for key, values := range listPage.Query {
+			query[key] = values
+		}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(listPage.PerPage))
+
+		return &PageRef{HREF: listURL.setQuery(query.Encode()).build()}
+	}
+
+	paginationData := PaginationData{
+		TotalResults: listPage.TotalResults,
+		TotalPages:   totalPages,
+		First:        *pageRef(1),
+		Last:         *pageRef(totalPages),
+	}
+	if listPage.Page > 1 {
+		paginationData.Previous = pageRef(listPage.Page - 1)
+	}
+	if listPage.Page < totalPages {
+		paginationData.Next = pageRef(listPage.Page + 1)
+	}
+
+	return paginationData
+}
+
 type buildURL url.URL
 
 func (u buildURL) appendPath(subpath ...string) buildURL {
@@ -0,0 +1,18 @@
+package presenter
+
+import (
+	"net/url"
+)
+
+// SidecarListResponse lists the sidecars of a process. Sidecars are not supported, so the list is always empty.
+type SidecarListResponse struct {
+	PaginationData PaginationData `json:"pagination"`
+	Resources      []interface{}  `json:"resources"`
+}
+
+func ForProcessSidecarList(baseURL url.URL, processGUID string, listPage ListPage) SidecarListResponse {
+	return SidecarListResponse{
+		PaginationData: forPagination(buildURL(baseURL).appendPath(processesBase, processGUID, "sidecars"), listPage),
+		Resources:      []interface{}{},
+	}
+}
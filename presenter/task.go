@@ -0,0 +1,113 @@
+package presenter
+
+import (
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+)
+
+const tasksBase = "/v3/tasks"
+
+type TaskResponse struct {
+	GUID          string        `json:"guid"`
+	SequenceID    int           `json:"sequence_id"`
+	Name          string        `json:"name"`
+	Command       string        `json:"command"`
+	State         string        `json:"state"`
+	MemoryMB      int64         `json:"memory_in_mb"`
+	DiskMB        int64         `json:"disk_in_mb"`
+	Result        TaskResult    `json:"result"`
+	DropletGUID   string        `json:"droplet_guid"`
+	Relationships Relationships `json:"relationships"`
+	Metadata      Metadata      `json:"metadata"`
+	CreatedAt     string        `json:"created_at"`
+	UpdatedAt     string        `json:"updated_at"`
+	Links         TaskLinks     `json:"links"`
+}
+
+type TaskResult struct {
+	FailureReason *string `json:"failure_reason"`
+}
+
+type TaskLinks struct {
+	Self    Link `json:"self"`
+	App     Link `json:"app"`
+	Cancel  Link `json:"cancel"`
+	Droplet Link `json:"droplet"`
+}
+
+type TaskListResponse struct {
+	PaginationData PaginationData `json:"pagination"`
+	Resources      []TaskResponse `json:"resources"`
+}
+
+func ForTask(task repositories.TaskRecord, baseURL url.URL) TaskResponse {
+	var failureReason *string
+	if task.FailureReason != "" {
+		failureReason = &task.FailureReason
+	}
+
+	return TaskResponse{
+		GUID:        task.GUID,
+		SequenceID:  task.SequenceID,
+		Name:        task.Name,
+		Command:     task.Command,
+		State:       task.State,
+		MemoryMB:    task.MemoryMB,
+		DiskMB:      task.DiskMB,
+		Result:      TaskResult{FailureReason: failureReason},
+		DropletGUID: task.DropletGUID,
+		Relationships: Relationships{
+			"app": {
+				Data: RelationshipData{
+					GUID: task.AppGUID,
+				},
+			},
+		},
+		Metadata: Metadata{
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		CreatedAt: task.CreatedAt,
+		UpdatedAt: task.UpdatedAt,
+		Links: TaskLinks{
+			Self: Link{
+				HREF: buildURL(baseURL).appendPath(tasksBase, task.GUID).build(),
+			},
+			App: Link{
+				HREF: buildURL(baseURL).appendPath(appsBase, task.AppGUID).build(),
+			},
+			Cancel: Link{
+				HREF:   buildURL(baseURL).appendPath(tasksBase, task.GUID, "actions", "cancel").build(),
+				Method: http.MethodPost,
+			},
+			Droplet: Link{
+				HREF: buildURL(baseURL).appendPath(dropletsBase, task.DropletGUID).build(),
+			},
+		},
+	}
+}
+
+func ForTaskList(tasks []repositories.TaskRecord, baseURL url.URL, listPage ListPage) TaskListResponse {
+	return forTaskList(tasks, baseURL, buildURL(baseURL).appendPath(tasksBase), listPage)
+}
+
+func ForAppTaskList(tasks []repositories.TaskRecord, baseURL url.URL, appGUID string, listPage ListPage) TaskListResponse {
+	return forTaskList(tasks, baseURL, buildURL(baseURL).appendPath(appsBase, appGUID, "tasks"), listPage)
+}
+
+// forTaskList hides the commands of the tasks, which may hold secrets, like ForProcessList does
+func forTaskList(tasks []repositories.TaskRecord, baseURL url.URL, listURL buildURL, listPage ListPage) TaskListResponse {
+	taskResponses := make([]TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		taskResponse := ForTask(task, baseURL)
+		taskResponse.Command = "[PRIVATE DATA HIDDEN IN LISTS]"
+		taskResponses = append(taskResponses, taskResponse)
+	}
+
+	return TaskListResponse{
+		PaginationData: forPagination(listURL, listPage),
+		Resources:      taskResponses,
+	}
+}
@@ -5,24 +5,44 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	networkingv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/networking/v1alpha1"
 	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/util/retry"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //+kubebuilder:rbac:groups=workloads.cloudfoundry.org,resources=cfapps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=workloads.cloudfoundry.org,resources=cfapps/status,verbs=get
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=create;patch;delete
 
-type AppRepo struct{}
+type AppRepo struct {
+	namespaceCache *GUIDNamespaceCache
+	// restartTimeout limits how long RestartApp waits for the processes of an app to stop, and how long it takes to
+	// start the app again
+	restartTimeout time.Duration
+}
+
+func NewAppRepo(namespaceCache *GUIDNamespaceCache, restartTimeout time.Duration) *AppRepo {
+	return &AppRepo{
+		namespaceCache: namespaceCache,
+		restartTimeout: restartTimeout,
+	}
+}
 
 const (
 	StartedState DesiredState = "STARTED"
@@ -32,6 +52,8 @@ const (
 	APIVersion      string = "workloads.cloudfoundry.org/v1alpha1"
 	TimestampFormat string = time.RFC3339
 	CFAppGUIDLabel  string = "apps.cloudfoundry.org/appGuid"
+
+	processStopPollInterval = time.Second
 )
 
 type AppRecord struct {
@@ -44,8 +66,28 @@ type AppRecord struct {
 	State         DesiredState
 	Lifecycle     Lifecycle
 	EnvSecretName string
-	CreatedAt     string
-	UpdatedAt     string
+	// ResourceVersion is the Kubernetes resource version of the CFApp, see PatchAppMessage
+	ResourceVersion string
+	CreatedAt       string
+	UpdatedAt       string
+}
+
+// AppListMessage selects the apps to list. Empty filters match every app. OrderBy names the field to order the
+// apps by, as in the CF API, prefixed with "-" for descending order.
+type AppListMessage struct {
+	Names      []string
+	GUIDs      []string
+	SpaceGUIDs []string
+	OrgGUIDs   []string
+	Stacks     []string
+	// LabelSelector selects apps by their labels. It may be nil.
+	LabelSelector labels.Selector
+	OrderBy       string
+	Page          PageRequest
+}
+
+func (m AppListMessage) isFiltered() bool {
+	return len(m.Names) > 0 || len(m.GUIDs) > 0 || len(m.SpaceGUIDs) > 0 || len(m.OrgGUIDs) > 0 || len(m.Stacks) > 0 || m.OrderBy != ""
 }
 
 type DesiredState string
@@ -73,13 +115,25 @@ type CurrentDropletRecord struct {
 }
 
 func (f *AppRepo) FetchApp(ctx context.Context, client client.Client, appGUID string) (AppRecord, error) {
-	// TODO: Could look up namespace from guid => namespace cache to do Get
+	cfApp := &workloadsv1alpha1.CFApp{}
+	found, err := f.namespaceCache.fetch(ctx, client, appGUID, cfApp)
+	if err != nil {
+		return AppRecord{}, err
+	}
+	if found {
+		if _, ok := cfApp.Labels[DeploymentGUIDLabel]; ok {
+			return AppRecord{}, NotFoundError{}
+		}
+		return cfAppToAppRecord(*cfApp), nil
+	}
+
 	appList := &workloadsv1alpha1.CFAppList{}
-	err := client.List(ctx, appList)
+	err = client.List(ctx, appList, appListOptions(nil)...)
 	if err != nil { // untested
 		return AppRecord{}, err
 	}
 	allApps := appList.Items
+	f.cacheNamespaces(allApps)
 	matches := filterAppsByMetadataName(allApps, appGUID)
 
 	return returnApp(matches)
@@ -91,25 +145,89 @@ func (f *AppRepo) CreateApp(ctx context.Context, client client.Client, appRecord
 	if err != nil {
 		return AppRecord{}, err
 	}
+	f.namespaceCache.Set(cfApp.Name, cfApp.Namespace)
 	return cfAppToAppRecord(cfApp), err
 }
 
-func (f *AppRepo) FetchAppList(ctx context.Context, client client.Client) ([]AppRecord, error) {
-	// TODO: add checks for allowed namespaces with completion of initial auth work
-	// Currently we assume the client has full cluster access and naively returns all records
+// FetchAppList returns the page of apps selected by message, along with the total number of matching apps.
+// Space and org filters only list the namespaces of the matching spaces and the label selector is passed on to the
+// List. The other filters and the ordering are applied in memory, so only unfiltered lists are read from the API server a page at a time.
+func (f *AppRepo) FetchAppList(ctx context.Context, client client.Client, message AppListMessage) ([]AppRecord, int, error) {
 	appList := &workloadsv1alpha1.CFAppList{}
-	err := client.List(ctx, appList)
+	if !message.isFiltered() {
+		totalResults, err := listPage(ctx, client, appList, message.Page, appListOptions(message.LabelSelector)...)
+		if err != nil {
+			return []AppRecord{}, 0, err
+		}
+		f.cacheNamespaces(appList.Items)
+
+		return appListToAppRecords(appList.Items), totalResults, nil
+	}
+
+	namespaces, err := namespacesToList(ctx, client, message.SpaceGUIDs, message.OrgGUIDs)
 	if err != nil {
-		return []AppRecord{}, err
+		return []AppRecord{}, 0, err
 	}
-	allApps := appList.Items
+	err = listInNamespaces(ctx, client, appList, namespaces, appListOptions(message.LabelSelector)...)
+	if err != nil {
+		return []AppRecord{}, 0, err
+	}
+	f.cacheNamespaces(appList.Items)
 
-	appRecordList := make([]AppRecord, 0, len(allApps))
-	for _, app := range allApps {
-		appRecordList = append(appRecordList, cfAppToAppRecord(app))
+	nameFilter := toMap(message.Names)
+	guidFilter := toMap(message.GUIDs)
+	stackFilter := toMap(message.Stacks)
+	var filtered []workloadsv1alpha1.CFApp
+	for _, app := range appList.Items {
+		if matchFilter(nameFilter, app.Spec.Name) &&
+			matchFilter(guidFilter, app.Name) &&
+			matchFilter(stackFilter, app.Spec.Lifecycle.Data.Stack) {
+			filtered = append(filtered, app)
+		}
 	}
 
-	return appRecordList, nil
+	appRecords := appListToAppRecords(filtered)
+	sort.SliceStable(appRecords, lessForOrderBy(message.OrderBy, func(i int, field string) string {
+		return appRecordOrderValue(appRecords[i], field)
+	}))
+
+	start, end := message.Page.Bounds(len(appRecords))
+	return appRecords[start:end], len(appRecords), nil
+}
+
+// appListOptions selects the apps that match labelSelector, leaving out the deployment apps that run the droplets of
+// deployments
+func appListOptions(labelSelector labels.Selector) []client.ListOption {
+	if labelSelector == nil {
+		labelSelector = labels.Everything()
+	}
+	notDeploymentApp, err := labels.NewRequirement(DeploymentGUIDLabel, selection.DoesNotExist, nil)
+	if err != nil {
+		// the label key is valid
+		panic(err)
+	}
+	return []client.ListOption{client.MatchingLabelsSelector{Selector: labelSelector.Add(*notDeploymentApp)}}
+}
+
+func appListToAppRecords(apps []workloadsv1alpha1.CFApp) []AppRecord {
+	appRecords := make([]AppRecord, 0, len(apps))
+	for _, app := range apps {
+		appRecords = append(appRecords, cfAppToAppRecord(app))
+	}
+	return appRecords
+}
+
+func appRecordOrderValue(app AppRecord, field string) string {
+	switch field {
+	case "name":
+		return app.Name
+	case "state":
+		return string(app.State)
+	case "updated_at":
+		return app.UpdatedAt
+	default:
+		return app.CreatedAt
+	}
 }
 
 func (f *AppRepo) FetchNamespace(ctx context.Context, client client.Client, nsGUID string) (SpaceRecord, error) {
@@ -137,6 +255,125 @@ func (f *AppRepo) CreateAppEnvironmentVariables(ctx context.Context, client clie
 	return appEnvVarsSecretToRecord(secretObj), nil
 }
 
+// FetchAppEnvVars returns the user-provided environment variables of app, which are empty when the app has no env
+// Secret yet
+func (f *AppRepo) FetchAppEnvVars(ctx context.Context, c client.Client, app AppRecord) (AppEnvVarsRecord, error) {
+	if app.EnvSecretName == "" {
+		return AppEnvVarsRecord{
+			AppGUID:              app.GUID,
+			SpaceGUID:            app.SpaceGUID,
+			EnvironmentVariables: map[string]string{},
+		}, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: app.EnvSecretName, Namespace: app.SpaceGUID}, secret)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return AppEnvVarsRecord{}, NotFoundError{Err: err}
+		}
+		return AppEnvVarsRecord{}, err
+	}
+
+	return appEnvVarsSecretToRecord(*secret), nil
+}
+
+// PatchAppEnvVarsMessage changes the environment variables of an app. Variables with a nil value are removed and
+// variables that are not mentioned are left as they are.
+type PatchAppEnvVarsMessage struct {
+	AppGUID              string
+	SpaceGUID            string
+	EnvSecretName        string
+	EnvironmentVariables map[string]*string
+}
+
+// PatchAppEnvVars applies the changes in message to the env Secret of the app. The Secret is created, and set on the
+// app, when the app was made without environment variables.
+func (f *AppRepo) PatchAppEnvVars(ctx context.Context, c client.Client, message PatchAppEnvVarsMessage) (AppEnvVarsRecord, error) {
+	if message.EnvSecretName == "" {
+		return f.createAppEnvVars(ctx, c, message)
+	}
+
+	secretData := map[string]interface{}{}
+	for key, value := range message.EnvironmentVariables {
+		if value == nil {
+			secretData[key] = nil
+		} else {
+			secretData[key] = []byte(*value)
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{"data": secretData})
+	if err != nil {
+		return AppEnvVarsRecord{}, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.EnvSecretName,
+			Namespace: message.SpaceGUID,
+		},
+	}
+	err = c.Patch(ctx, secret, client.RawPatch(types.MergePatchType, patch))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return AppEnvVarsRecord{}, NotFoundError{Err: err}
+		}
+		return AppEnvVarsRecord{}, fmt.Errorf("error patching app env secret: %w", err)
+	}
+
+	return appEnvVarsSecretToRecord(*secret), nil
+}
+
+func (f *AppRepo) createAppEnvVars(ctx context.Context, c client.Client, message PatchAppEnvVarsMessage) (AppEnvVarsRecord, error) {
+	envVars := map[string]string{}
+	for key, value := range message.EnvironmentVariables {
+		if value != nil {
+			envVars[key] = *value
+		}
+	}
+
+	envVarsRecord, err := f.CreateAppEnvironmentVariables(ctx, c, AppEnvVarsRecord{
+		AppGUID:              message.AppGUID,
+		SpaceGUID:            message.SpaceGUID,
+		EnvironmentVariables: envVars,
+	})
+	if k8serrors.IsAlreadyExists(err) {
+		// a concurrent request created the Secret first, so the changes are patched into its Secret instead
+		envVarsRecord, err = f.patchExistingAppEnvVars(ctx, c, message)
+	}
+	if err != nil {
+		return AppEnvVarsRecord{}, fmt.Errorf("error creating app env secret: %w", err)
+	}
+
+	cfApp := &workloadsv1alpha1.CFApp{}
+	err = c.Get(ctx, types.NamespacedName{Name: message.AppGUID, Namespace: message.SpaceGUID}, cfApp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return AppEnvVarsRecord{}, NotFoundError{Err: err}
+		}
+		return AppEnvVarsRecord{}, err
+	}
+	baseCFApp := cfApp.DeepCopy()
+	cfApp.Spec.EnvSecretName = envVarsRecord.Name
+	err = c.Patch(ctx, cfApp, client.MergeFrom(baseCFApp))
+	if err != nil {
+		return AppEnvVarsRecord{}, fmt.Errorf("error setting env secret of app: %w", err)
+	}
+
+	return envVarsRecord, nil
+}
+
+func (f *AppRepo) patchExistingAppEnvVars(ctx context.Context, c client.Client, message PatchAppEnvVarsMessage) (AppEnvVarsRecord, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: message.AppGUID + "-env", Namespace: message.SpaceGUID}, secret)
+	if err != nil {
+		return AppEnvVarsRecord{}, err
+	}
+
+	message.EnvSecretName = secret.Name
+	return f.PatchAppEnvVars(ctx, c, message)
+}
+
 type SetCurrentDropletMessage struct {
 	AppGUID     string
 	DropletGUID string
@@ -187,6 +424,405 @@ func (f *AppRepo) SetAppDesiredState(ctx context.Context, c client.Client, messa
 	return cfAppToAppRecord(*cfApp), nil
 }
 
+// The desired state of an app moves between STOPPED and STARTED:
+//
+//	STOPPED --StartApp--> STARTED    only when the current droplet of the app exists and staged successfully
+//	STARTED --StopApp---> STOPPED
+//	any ----RestartApp--> STOPPED, until no process of the app has ready pods, then STARTED
+//
+// StartApp and RestartApp return an AppNotStartableError, without changing the app, when it cannot be started.
+
+// AppNotStartableError is returned when an app is started without a droplet that can run. Reason explains what the
+// user has to do.
+type AppNotStartableError struct {
+	Reason string
+}
+
+func (e AppNotStartableError) Error() string {
+	return e.Reason
+}
+
+// StartApp starts app, which must have a current droplet that staged successfully
+func (f *AppRepo) StartApp(ctx context.Context, c client.Client, app AppRecord) (AppRecord, error) {
+	err := checkAppStartable(ctx, c, app)
+	if err != nil {
+		return AppRecord{}, err
+	}
+
+	return f.SetAppDesiredState(ctx, c, SetAppDesiredStateMessage{
+		AppGUID:      app.GUID,
+		SpaceGUID:    app.SpaceGUID,
+		DesiredState: string(StartedState),
+	})
+}
+
+func (f *AppRepo) StopApp(ctx context.Context, c client.Client, app AppRecord) (AppRecord, error) {
+	return f.SetAppDesiredState(ctx, c, SetAppDesiredStateMessage{
+		AppGUID:      app.GUID,
+		SpaceGUID:    app.SpaceGUID,
+		DesiredState: string(StoppedState),
+	})
+}
+
+// RestartApp stops app, waits for its processes to stop running and starts it again. Once the app has been stopped,
+// it is always started again, even when its processes do not stop within the restart timeout or ctx is canceled, in
+// which case the error is returned once the app has been started.
+func (f *AppRepo) RestartApp(ctx context.Context, c client.Client, app AppRecord) (AppRecord, error) {
+	err := checkAppStartable(ctx, c, app)
+	if err != nil {
+		return AppRecord{}, err
+	}
+
+	_, err = f.StopApp(ctx, c, app)
+	if err != nil {
+		return AppRecord{}, err
+	}
+
+	waitErr := f.waitForProcessesToStop(ctx, c, app)
+
+	// the app is started with a context of its own, so that a request that is canceled while waiting doesn't leave
+	// the app stopped
+	startCtx, cancelFn := context.WithTimeout(context.Background(), f.restartTimeout)
+	defer cancelFn()
+
+	startedApp, err := f.SetAppDesiredState(startCtx, c, SetAppDesiredStateMessage{
+		AppGUID:      app.GUID,
+		SpaceGUID:    app.SpaceGUID,
+		DesiredState: string(StartedState),
+	})
+	if err != nil {
+		return AppRecord{}, err
+	}
+	if waitErr != nil {
+		return AppRecord{}, waitErr
+	}
+
+	return startedApp, nil
+}
+
+func checkAppStartable(ctx context.Context, c client.Client, app AppRecord) error {
+	if app.DropletGUID == "" {
+		return AppNotStartableError{Reason: "Assign a droplet before starting this app."}
+	}
+
+	// droplets are the status of the CFBuild that staged them
+	cfBuild := &workloadsv1alpha1.CFBuild{}
+	err := c.Get(ctx, types.NamespacedName{Name: app.DropletGUID, Namespace: app.SpaceGUID}, cfBuild)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return AppNotStartableError{Reason: "The current droplet of this app no longer exists. Assign a droplet before starting this app."}
+		}
+		return fmt.Errorf("error fetching the current droplet of app %q: %w", app.GUID, err)
+	}
+
+	if getConditionValue(&cfBuild.Status.Conditions, SucceededConditionType) != metav1.ConditionTrue {
+		return AppNotStartableError{Reason: "The current droplet of this app did not stage successfully. Assign a droplet before starting this app."}
+	}
+
+	return nil
+}
+
+func (f *AppRepo) waitForProcessesToStop(ctx context.Context, c client.Client, app AppRecord) error {
+	timeoutCtx, cancelFn := context.WithTimeout(ctx, f.restartTimeout)
+	defer cancelFn()
+
+	ticker := time.NewTicker(processStopPollInterval)
+	defer ticker.Stop()
+
+	for {
+		processList := &workloadsv1alpha1.CFProcessList{}
+		err := c.List(timeoutCtx, processList, listOptionsForApp(c, app.SpaceGUID, app.GUID)...)
+		if err != nil {
+			return fmt.Errorf("error listing the processes of app %q: %w", app.GUID, err)
+		}
+
+		running := false
+		for _, process := range filterProcessesByAppGUID(processList.Items, app.GUID) {
+			running, err = hasReadyPods(timeoutCtx, c, process)
+			if err != nil {
+				return err
+			}
+			if running {
+				break
+			}
+		}
+		if !running {
+			return nil
+		}
+
+		select {
+		case <-timeoutCtx.Done():
+			if ctx.Err() != nil {
+				return fmt.Errorf("stopped waiting for the processes of app %q to stop: %w", app.GUID, ctx.Err())
+			}
+			return fmt.Errorf("processes of app %q did not stop within %s", app.GUID, f.restartTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasReadyPods reports whether any instance of process still runs, which is when its pod is ready. The workload
+// controllers don't report running instances in the status of processes.
+func hasReadyPods(ctx context.Context, c client.Client, process workloadsv1alpha1.CFProcess) (bool, error) {
+	podList := &corev1.PodList{}
+	err := c.List(ctx, podList, client.InNamespace(process.Namespace), client.MatchingLabels{ProcessGUIDPodLabel: process.Name})
+	if err != nil {
+		return false, fmt.Errorf("error listing the pods of process %q: %w", process.Name, err)
+	}
+
+	now := time.Now()
+	for _, pod := range podList.Items {
+		if state, _, _, _ := podState(pod, now); state == ProcessInstanceRunning {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PatchAppMessage changes the name, lifecycle and metadata of an app. Nil fields are left as they are.
+type PatchAppMessage struct {
+	AppGUID   string
+	SpaceGUID string
+	// ResourceVersion is the version of the app that the changes were made against. The patch fails with a
+	// ConflictError when the app has changed since.
+	ResourceVersion string
+	Name            *string
+	Buildpacks      *[]string
+	Stack           *string
+	MetadataPatch
+}
+
+func (f *AppRepo) PatchApp(ctx context.Context, c client.Client, message PatchAppMessage) (AppRecord, error) {
+	cfApp := &workloadsv1alpha1.CFApp{}
+	err := c.Get(ctx, types.NamespacedName{Name: message.AppGUID, Namespace: message.SpaceGUID}, cfApp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return AppRecord{}, NotFoundError{Err: err}
+		}
+		return AppRecord{}, err
+	}
+
+	baseCFApp := cfApp.DeepCopy()
+	if message.ResourceVersion != "" {
+		baseCFApp.ResourceVersion = message.ResourceVersion
+	}
+
+	if message.Name != nil {
+		cfApp.Spec.Name = *message.Name
+	}
+	if message.Buildpacks != nil {
+		cfApp.Spec.Lifecycle.Data.Buildpacks = *message.Buildpacks
+	}
+	if message.Stack != nil {
+		cfApp.Spec.Lifecycle.Data.Stack = *message.Stack
+	}
+	cfApp.Labels = applyMetadataPatch(cfApp.Labels, message.Labels)
+	cfApp.Annotations = applyMetadataPatch(cfApp.Annotations, message.Annotations)
+
+	err = c.Patch(ctx, cfApp, client.MergeFromWithOptions(baseCFApp, client.MergeFromWithOptimisticLock{}))
+	if err != nil {
+		switch {
+		case k8serrors.IsNotFound(err):
+			return AppRecord{}, NotFoundError{Err: err}
+		case k8serrors.IsConflict(err):
+			return AppRecord{}, ConflictError{Err: err}
+		}
+		return AppRecord{}, err
+	}
+
+	return cfAppToAppRecord(*cfApp), nil
+}
+
+type DeleteAppMessage struct {
+	AppGUID   string
+	SpaceGUID string
+}
+
+// DeleteApp deletes an app with its processes, packages, builds and environment variables, and removes the app from
+// the destinations of its routes. The app is deleted last, so that a failed delete can be retried.
+func (f *AppRepo) DeleteApp(ctx context.Context, c client.Client, message DeleteAppMessage) error {
+	childLists := []client.ObjectList{
+		&workloadsv1alpha1.CFProcessList{},
+		&workloadsv1alpha1.CFPackageList{},
+		&workloadsv1alpha1.CFBuildList{},
+	}
+	for _, list := range childLists {
+		err := deleteAppChildren(ctx, c, list, message)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := removeAppDestinations(ctx, c, message)
+	if err != nil {
+		return err
+	}
+
+	err = c.Delete(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.AppGUID + "-env",
+			Namespace: message.SpaceGUID,
+		},
+	})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting app env secret: %w", err)
+	}
+
+	err = c.Delete(ctx, &workloadsv1alpha1.CFApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.AppGUID,
+			Namespace: message.SpaceGUID,
+		},
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return NotFoundError{Err: err}
+		}
+		return fmt.Errorf("error deleting app: %w", err)
+	}
+	f.namespaceCache.Delete(message.AppGUID)
+
+	return nil
+}
+
+func deleteAppChildren(ctx context.Context, c client.Client, list client.ObjectList, message DeleteAppMessage) error {
+	err := c.List(ctx, list, listOptionsForApp(c, message.SpaceGUID, message.AppGUID)...)
+	if err != nil {
+		return err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		var appGUID string
+		switch child := item.(type) {
+		case *workloadsv1alpha1.CFProcess:
+			appGUID = child.Spec.AppRef.Name
+		case *workloadsv1alpha1.CFPackage:
+			appGUID = child.Spec.AppRef.Name
+		case *workloadsv1alpha1.CFBuild:
+			appGUID = child.Spec.AppRef.Name
+		}
+		if appGUID != message.AppGUID {
+			continue
+		}
+
+		obj := item.(client.Object)
+		err = c.Delete(ctx, obj)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting %T %q: %w", obj, obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+func removeAppDestinations(ctx context.Context, c client.Client, message DeleteAppMessage) error {
+	routeList := &networkingv1alpha1.CFRouteList{}
+	err := c.List(ctx, routeList, listOptionsForApp(c, message.SpaceGUID, message.AppGUID)...)
+	if err != nil {
+		return err
+	}
+
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		baseRoute := route.DeepCopy()
+
+		var destinations []networkingv1alpha1.Destination
+		for _, destination := range route.Spec.Destinations {
+			if destination.AppRef.Name != message.AppGUID {
+				destinations = append(destinations, destination)
+			}
+		}
+		if len(destinations) == len(route.Spec.Destinations) {
+			continue
+		}
+
+		route.Spec.Destinations = destinations
+		err = c.Patch(ctx, route, client.MergeFrom(baseRoute))
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error removing app from destinations of route %q: %w", route.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// nextAppCounterValue increments the counter in an annotation of the CFApp of an app and returns its new value. The
+// CFApp is patched with an optimistic lock, so callers never get the same value even when they run concurrently. A
+// counter that doesn't exist yet starts after the value that initial returns.
+func nextAppCounterValue(ctx context.Context, c client.Client, appGUID, spaceGUID, annotation string, initial func() (int, error)) (int, error) {
+	var value int
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cfApp := &workloadsv1alpha1.CFApp{}
+		err := c.Get(ctx, types.NamespacedName{Name: appGUID, Namespace: spaceGUID}, cfApp)
+		if err != nil {
+			return err
+		}
+
+		counter, ok := cfApp.Annotations[annotation]
+		if ok {
+			value, err = strconv.Atoi(counter)
+			if err != nil {
+				return fmt.Errorf("error parsing annotation %q of app %q: %w", annotation, appGUID, err)
+			}
+		} else {
+			value, err = initial()
+			if err != nil {
+				return err
+			}
+		}
+		value++
+
+		baseCFApp := cfApp.DeepCopy()
+		cfApp.Annotations = withAnnotation(cfApp.Annotations, annotation, strconv.Itoa(value))
+		return c.Patch(ctx, cfApp, client.MergeFromWithOptions(baseCFApp, client.MergeFromWithOptimisticLock{}))
+	})
+	if err != nil {
+		switch {
+		case k8serrors.IsNotFound(err):
+			return 0, NotFoundError{Err: err}
+		case k8serrors.IsConflict(err):
+			return 0, ConflictError{Err: err}
+		}
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// appOwnerReference returns a reference to cfApp as the owner of the objects of an app that aren't CF resources, such
+// as the Jobs of its tasks, so that Kubernetes deletes them with the app
+func appOwnerReference(cfApp *workloadsv1alpha1.CFApp) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Name:       cfApp.Name,
+		UID:        cfApp.UID,
+	}
+}
+
+// fetchAppOwnerReference returns the appOwnerReference of the CFApp of an app
+func fetchAppOwnerReference(ctx context.Context, c client.Client, appGUID, spaceGUID string) (metav1.OwnerReference, error) {
+	cfApp := &workloadsv1alpha1.CFApp{}
+	err := c.Get(ctx, types.NamespacedName{Name: appGUID, Namespace: spaceGUID}, cfApp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return metav1.OwnerReference{}, NotFoundError{Err: err}
+		}
+		return metav1.OwnerReference{}, fmt.Errorf("error fetching app %q: %w", appGUID, err)
+	}
+	return appOwnerReference(cfApp), nil
+}
+
+func (f *AppRepo) cacheNamespaces(apps []workloadsv1alpha1.CFApp) {
+	for _, app := range apps {
+		f.namespaceCache.Set(app.Name, app.Namespace)
+	}
+}
+
 func appRecordToCFApp(appRecord AppRecord) workloadsv1alpha1.CFApp {
 	return workloadsv1alpha1.CFApp{
 		TypeMeta: metav1.TypeMeta{
@@ -218,14 +854,17 @@ func cfAppToAppRecord(cfApp workloadsv1alpha1.CFApp) AppRecord {
 	updatedAtTime, _ := getTimeLastUpdatedTimestamp(&cfApp.ObjectMeta)
 
 	return AppRecord{
-		GUID:        cfApp.Name,
-		Name:        cfApp.Spec.Name,
-		SpaceGUID:   cfApp.Namespace,
-		DropletGUID: cfApp.Spec.CurrentDropletRef.Name,
-		Labels:      cfApp.Labels,
-		Annotations: cfApp.Annotations,
-		State:       DesiredState(cfApp.Spec.DesiredState),
+		GUID:            cfApp.Name,
+		Name:            cfApp.Spec.Name,
+		SpaceGUID:       cfApp.Namespace,
+		DropletGUID:     cfApp.Spec.CurrentDropletRef.Name,
+		Labels:          cfApp.Labels,
+		Annotations:     cfApp.Annotations,
+		State:           DesiredState(cfApp.Spec.DesiredState),
+		EnvSecretName:   cfApp.Spec.EnvSecretName,
+		ResourceVersion: cfApp.ResourceVersion,
 		Lifecycle: Lifecycle{
+			Type: string(cfApp.Spec.Lifecycle.Type),
 			Data: LifecycleData{
 				Buildpacks: cfApp.Spec.Lifecycle.Data.Buildpacks,
 				Stack:      cfApp.Spec.Lifecycle.Data.Stack,
@@ -290,9 +929,10 @@ func appEnvVarsSecretToRecord(envVars corev1.Secret) AppEnvVarsRecord {
 }
 
 func convertByteSliceValuesToStrings(inputMap map[string][]byte) map[string]string {
-	// StringData is a write-only field of a corev1.Secret, the real data lives in .Data and is []byte & base64 encoded
-	marshalledData, _ := json.Marshal(inputMap)
-	outputMap := make(map[string]string)
-	json.Unmarshal(marshalledData, &outputMap)
+	// StringData is a write-only field of a corev1.Secret, the real data lives in .Data as []byte
+	outputMap := make(map[string]string, len(inputMap))
+	for key, value := range inputMap {
+		outputMap[key] = string(value)
+	}
 	return outputMap
 }
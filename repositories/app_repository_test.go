@@ -2,32 +2,41 @@ package repositories_test
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	networkingv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/networking/v1alpha1"
 	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	hnsv1alpha2 "sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
 )
 
 var _ = Describe("AppRepository", func() {
 	var (
-		testCtx context.Context
-		appRepo *AppRepo
-		client  client.Client
+		testCtx        context.Context
+		namespaceCache *GUIDNamespaceCache
+		appRepo        *AppRepo
+		client         client.Client
 	)
 
 	BeforeEach(func() {
 		testCtx = context.Background()
 
-		appRepo = new(AppRepo)
+		namespaceCache = NewGUIDNamespaceCache()
+		appRepo = NewAppRepo(namespaceCache, 2*time.Second)
 		var err error
-		client, err = BuildCRClient(k8sConfig)
+		client, err = BuildPrivilegedCRClient(k8sConfig, "")
 		Expect(err).ToNot(HaveOccurred())
 	})
 
@@ -129,6 +138,55 @@ var _ = Describe("AppRepository", func() {
 				Expect(app.Lifecycle).To(Equal(expectedLifecycle))
 			})
 
+			It("caches the namespace of the App", func() {
+				_, err := appRepo.FetchApp(testCtx, client, app2GUID)
+				Expect(err).NotTo(HaveOccurred())
+
+				namespace, ok := namespaceCache.Get(app2GUID)
+				Expect(ok).To(BeTrue())
+				Expect(namespace).To(Equal(namespace2.Name))
+			})
+
+			When("the namespace of the App is cached", func() {
+				BeforeEach(func() {
+					namespaceCache.Set(app2GUID, namespace2.Name)
+				})
+
+				It("fetches the App from that namespace", func() {
+					app, err := appRepo.FetchApp(testCtx, client, app2GUID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(app.GUID).To(Equal(app2GUID))
+					Expect(app.SpaceGUID).To(Equal(namespace2.Name))
+				})
+			})
+
+			When("the namespace of the App is cached and the user may not get it", func() {
+				BeforeEach(func() {
+					namespaceCache.Set(app2GUID, namespace2.Name)
+				})
+
+				It("returns a NotFoundError and keeps the cache entry", func() {
+					_, err := appRepo.FetchApp(testCtx, forbiddenGetClient{Client: client}, app2GUID)
+					Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+
+					_, ok := namespaceCache.Get(app2GUID)
+					Expect(ok).To(BeTrue())
+				})
+			})
+
+			When("the cached namespace of the App is stale", func() {
+				BeforeEach(func() {
+					namespaceCache.Set(app2GUID, namespace1.Name)
+				})
+
+				It("returns a NotFoundError and drops the cache entry", func() {
+					_, err := appRepo.FetchApp(testCtx, client, app2GUID)
+					Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+
+					_, ok := namespaceCache.Get(app2GUID)
+					Expect(ok).To(BeFalse())
+				})
+			})
 		})
 
 		When("duplicate Apps exist across namespaces with the same GUIDs", func() {
@@ -220,6 +278,7 @@ var _ = Describe("AppRepository", func() {
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      app1GUID,
 						Namespace: namespace,
+						Labels:    map[string]string{"team": "payments"},
 					},
 					Spec: workloadsv1alpha1.CFAppSpec{
 						Name:         "test-app1",
@@ -263,16 +322,132 @@ var _ = Describe("AppRepository", func() {
 
 			// TODO: Update this test annotation to reflect proper filtering by caller permissions when that is available
 			It("returns all the AppRecord CRs", func() {
-				appList, err := appRepo.FetchAppList(testCtx, client)
+				appList, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(appList).To(HaveLen(2), "repository should return 2 app records")
+				Expect(totalResults).To(Equal(2))
 				// TODO: Assert on equality for each expected appRecord? Could just hardcode checks for each app?
 			})
+
+			It("returns the requested page of AppRecords and the total number of apps", func() {
+				firstPage, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{Page: PageRequest{Page: 1, PerPage: 1}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(firstPage).To(HaveLen(1))
+				Expect(totalResults).To(Equal(2))
+
+				secondPage, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{Page: PageRequest{Page: 2, PerPage: 1}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(secondPage).To(HaveLen(1))
+				Expect(totalResults).To(Equal(2))
+				Expect(secondPage[0].GUID).NotTo(Equal(firstPage[0].GUID))
+
+				thirdPage, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{Page: PageRequest{Page: 3, PerPage: 1}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(thirdPage).To(BeEmpty())
+				Expect(totalResults).To(Equal(2))
+			})
+
+			It("selects the apps by label", func() {
+				selector, err := labels.Parse("team=payments")
+				Expect(err).NotTo(HaveOccurred())
+
+				appList, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{LabelSelector: selector})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appList).To(HaveLen(1))
+				Expect(appList[0].GUID).To(Equal(app1GUID))
+				Expect(totalResults).To(Equal(1))
+
+				selector, err = labels.Parse("!team")
+				Expect(err).NotTo(HaveOccurred())
+
+				appList, _, err = appRepo.FetchAppList(testCtx, client, AppListMessage{LabelSelector: selector, OrderBy: "name"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appList).To(HaveLen(1))
+				Expect(appList[0].GUID).To(Equal(app2GUID))
+			})
+
+			It("filters the apps by name", func() {
+				appList, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{Names: []string{"test-app2"}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appList).To(HaveLen(1))
+				Expect(appList[0].GUID).To(Equal(app2GUID))
+				Expect(totalResults).To(Equal(1))
+			})
+
+			It("filters the apps by GUID", func() {
+				appList, _, err := appRepo.FetchAppList(testCtx, client, AppListMessage{GUIDs: []string{app1GUID, "some-other-guid"}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appList).To(HaveLen(1))
+				Expect(appList[0].GUID).To(Equal(app1GUID))
+			})
+
+			It("orders the apps by the order_by field", func() {
+				appList, _, err := appRepo.FetchAppList(testCtx, client, AppListMessage{OrderBy: "-name"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appList).To(HaveLen(2))
+				Expect(appList[0].GUID).To(Equal(app2GUID))
+				Expect(appList[1].GUID).To(Equal(app1GUID))
+			})
+
+			It("pages the filtered apps", func() {
+				appList, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{OrderBy: "name", Page: PageRequest{Page: 2, PerPage: 1}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(appList).To(HaveLen(1))
+				Expect(appList[0].GUID).To(Equal(app2GUID))
+				Expect(totalResults).To(Equal(2))
+			})
+
+			When("an app exists in another space", func() {
+				var (
+					orgNamespace   *corev1.Namespace
+					spaceNamespace *corev1.Namespace
+					app3GUID       string
+				)
+
+				BeforeEach(func() {
+					orgNamespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+					Expect(k8sClient.Create(testCtx, orgNamespace)).To(Succeed())
+					spaceNamespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+					Expect(k8sClient.Create(testCtx, spaceNamespace)).To(Succeed())
+					Expect(k8sClient.Create(testCtx, &hnsv1alpha2.SubnamespaceAnchor{
+						ObjectMeta: metav1.ObjectMeta{Name: spaceNamespace.Name, Namespace: orgNamespace.Name},
+					})).To(Succeed())
+
+					app3GUID = generateGUID()
+					Expect(k8sClient.Create(testCtx, initializeAppCR("test-app3", app3GUID, spaceNamespace.Name))).To(Succeed())
+				})
+
+				AfterEach(func() {
+					Expect(k8sClient.Delete(testCtx, spaceNamespace)).To(Succeed())
+					Expect(k8sClient.Delete(testCtx, orgNamespace)).To(Succeed())
+				})
+
+				It("only lists the apps of the spaces in the space filter", func() {
+					appList, _, err := appRepo.FetchAppList(testCtx, client, AppListMessage{SpaceGUIDs: []string{spaceNamespace.Name}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(appList).To(HaveLen(1))
+					Expect(appList[0].GUID).To(Equal(app3GUID))
+				})
+
+				It("only lists the apps of the spaces of the orgs in the org filter", func() {
+					appList, _, err := appRepo.FetchAppList(testCtx, client, AppListMessage{OrgGUIDs: []string{orgNamespace.Name}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(appList).To(HaveLen(1))
+					Expect(appList[0].GUID).To(Equal(app3GUID))
+				})
+
+				It("lists no apps when the space is not in the org filter", func() {
+					appList, totalResults, err := appRepo.FetchAppList(testCtx, client, AppListMessage{SpaceGUIDs: []string{namespace}, OrgGUIDs: []string{orgNamespace.Name}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(appList).To(BeEmpty())
+					Expect(totalResults).To(Equal(0))
+				})
+			})
 		})
 
 		When("no Apps exist", func() {
 			It("returns an error", func() {
-				_, err := appRepo.FetchAppList(testCtx, client)
+				_, _, err := appRepo.FetchAppList(testCtx, client, AppListMessage{})
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -324,6 +499,16 @@ var _ = Describe("AppRepository", func() {
 					Expect(cleanupApp(k8sClient, testCtx, testAppGUID, defaultNamespace)).To(Succeed())
 				})
 
+				It("caches the namespace of the new app", func() {
+					_, err := appRepo.CreateApp(testCtx, client, appRecord)
+					Expect(err).NotTo(HaveOccurred())
+
+					namespace, ok := namespaceCache.Get(testAppGUID)
+					Expect(ok).To(BeTrue())
+					Expect(namespace).To(Equal(defaultNamespace))
+					Expect(cleanupApp(k8sClient, testCtx, testAppGUID, defaultNamespace)).To(Succeed())
+				})
+
 				When("an app is created with the repository", func() {
 					var (
 						beforeCreationTime time.Time
@@ -707,4 +892,490 @@ var _ = Describe("AppRepository", func() {
 		})
 	})
 
+	Describe("PatchApp", func() {
+		const spaceGUID = "default"
+
+		var (
+			appGUID string
+			appCR   *workloadsv1alpha1.CFApp
+		)
+
+		BeforeEach(func() {
+			appGUID = generateGUID()
+			appCR = initializeAppCR("some-app", appGUID, spaceGUID)
+			appCR.Labels = map[string]string{"env": "dev", "tier": "web"}
+			appCR.Annotations = map[string]string{"example.org/contact": "jane"}
+			Expect(k8sClient.Create(testCtx, appCR)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, appCR)).To(Succeed())
+		})
+
+		It("adds, updates and deletes labels and annotations", func() {
+			prod := "prod"
+			owner := "payments"
+			appRecord, err := appRepo.PatchApp(testCtx, client, PatchAppMessage{
+				AppGUID:   appGUID,
+				SpaceGUID: spaceGUID,
+				MetadataPatch: MetadataPatch{
+					Labels:      map[string]*string{"env": &prod, "tier": nil},
+					Annotations: map[string]*string{"example.org/owner": &owner},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(appRecord.GUID).To(Equal(appGUID))
+			Expect(appRecord.Labels).To(Equal(map[string]string{"env": "prod"}))
+			Expect(appRecord.Annotations).To(Equal(map[string]string{
+				"example.org/contact": "jane",
+				"example.org/owner":   "payments",
+			}))
+
+			updatedApp := new(workloadsv1alpha1.CFApp)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: appGUID, Namespace: spaceGUID}, updatedApp)).To(Succeed())
+			Expect(updatedApp.Labels).To(Equal(map[string]string{"env": "prod"}))
+		})
+
+		It("leaves reserved annotations as they are", func() {
+			appCR.Annotations["cloudfoundry.org/task-sequence-id"] = "4"
+			Expect(k8sClient.Update(testCtx, appCR)).To(Succeed())
+
+			reset := "0"
+			appRecord, err := appRepo.PatchApp(testCtx, client, PatchAppMessage{
+				AppGUID:   appGUID,
+				SpaceGUID: spaceGUID,
+				MetadataPatch: MetadataPatch{
+					Annotations: map[string]*string{
+						"cloudfoundry.org/task-sequence-id": &reset,
+						"cloudfoundry.org/revision-version": &reset,
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(appRecord.Annotations).To(Equal(map[string]string{
+				"example.org/contact":               "jane",
+				"cloudfoundry.org/task-sequence-id": "4",
+			}))
+		})
+
+		It("changes the name and lifecycle of the app", func() {
+			newName := "new-name"
+			buildpacks := []string{"java_buildpack"}
+			stack := "cflinuxfs3"
+			appRecord, err := appRepo.PatchApp(testCtx, client, PatchAppMessage{
+				AppGUID:    appGUID,
+				SpaceGUID:  spaceGUID,
+				Name:       &newName,
+				Buildpacks: &buildpacks,
+				Stack:      &stack,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(appRecord.Name).To(Equal("new-name"))
+			Expect(appRecord.Lifecycle.Data.Buildpacks).To(Equal([]string{"java_buildpack"}))
+			Expect(appRecord.Lifecycle.Data.Stack).To(Equal("cflinuxfs3"))
+			Expect(appRecord.Labels).To(Equal(map[string]string{"env": "dev", "tier": "web"}))
+
+			updatedApp := new(workloadsv1alpha1.CFApp)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: appGUID, Namespace: spaceGUID}, updatedApp)).To(Succeed())
+			Expect(updatedApp.Spec.Name).To(Equal("new-name"))
+			Expect(updatedApp.Spec.Lifecycle.Data.Stack).To(Equal("cflinuxfs3"))
+		})
+
+		When("the app was changed since the given resource version", func() {
+			var staleResourceVersion string
+
+			BeforeEach(func() {
+				staleResourceVersion = appCR.ResourceVersion
+
+				updatedApp := appCR.DeepCopy()
+				updatedApp.Spec.Name = "changed-name"
+				Expect(k8sClient.Update(testCtx, updatedApp)).To(Succeed())
+			})
+
+			It("returns a ConflictError and leaves the app as it is", func() {
+				newName := "new-name"
+				_, err := appRepo.PatchApp(testCtx, client, PatchAppMessage{
+					AppGUID:         appGUID,
+					SpaceGUID:       spaceGUID,
+					ResourceVersion: staleResourceVersion,
+					Name:            &newName,
+				})
+				Expect(err).To(BeAssignableToTypeOf(ConflictError{}))
+
+				updatedApp := new(workloadsv1alpha1.CFApp)
+				Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: appGUID, Namespace: spaceGUID}, updatedApp)).To(Succeed())
+				Expect(updatedApp.Spec.Name).To(Equal("changed-name"))
+			})
+		})
+
+		When("the app doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				_, err := appRepo.PatchApp(testCtx, client, PatchAppMessage{
+					AppGUID:   "no-such-app",
+					SpaceGUID: spaceGUID,
+				})
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+	})
+
+	Describe("DeleteApp", func() {
+		var (
+			namespace    *corev1.Namespace
+			appGUID      string
+			otherAppGUID string
+			route        networkingv1alpha1.CFRoute
+		)
+
+		BeforeEach(func() {
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+			Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+			appGUID = generateGUID()
+			otherAppGUID = generateGUID()
+			Expect(k8sClient.Create(testCtx, initializeAppCR("some-app", appGUID, namespace.Name))).To(Succeed())
+			Expect(k8sClient.Create(testCtx, initializeAppCR("other-app", otherAppGUID, namespace.Name))).To(Succeed())
+			Expect(k8sClient.Create(testCtx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      generateAppEnvSecretName(appGUID),
+					Namespace: namespace.Name,
+					Labels:    map[string]string{CFAppGUIDLabel: appGUID},
+				},
+			})).To(Succeed())
+
+			Expect(k8sClient.Create(testCtx, initializeProcessCR("process-of-app", namespace.Name, appGUID))).To(Succeed())
+			Expect(k8sClient.Create(testCtx, initializeProcessCR("process-of-other-app", namespace.Name, otherAppGUID))).To(Succeed())
+			build := initializeDropletCR("build-of-app", appGUID, namespace.Name)
+			Expect(k8sClient.Create(testCtx, &build)).To(Succeed())
+
+			route = initializeRouteCR("my-host", "", generateGUID(), generateGUID(), namespace.Name)
+			route.Spec.Destinations = []networkingv1alpha1.Destination{
+				{GUID: "destination-of-app", AppRef: corev1.LocalObjectReference{Name: appGUID}, ProcessType: "web", Port: 8080},
+				{GUID: "destination-of-other-app", AppRef: corev1.LocalObjectReference{Name: otherAppGUID}, ProcessType: "web", Port: 8080},
+			}
+			Expect(k8sClient.Create(testCtx, &route)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+		})
+
+		It("deletes the app together with its env secret, processes and builds", func() {
+			Expect(appRepo.DeleteApp(testCtx, client, DeleteAppMessage{AppGUID: appGUID, SpaceGUID: namespace.Name})).To(Succeed())
+
+			err := k8sClient.Get(testCtx, types.NamespacedName{Name: appGUID, Namespace: namespace.Name}, new(workloadsv1alpha1.CFApp))
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(testCtx, types.NamespacedName{Name: generateAppEnvSecretName(appGUID), Namespace: namespace.Name}, new(corev1.Secret))
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(testCtx, types.NamespacedName{Name: "process-of-app", Namespace: namespace.Name}, new(workloadsv1alpha1.CFProcess))
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(testCtx, types.NamespacedName{Name: "build-of-app", Namespace: namespace.Name}, new(workloadsv1alpha1.CFBuild))
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("leaves the resources of other apps alone", func() {
+			Expect(appRepo.DeleteApp(testCtx, client, DeleteAppMessage{AppGUID: appGUID, SpaceGUID: namespace.Name})).To(Succeed())
+
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: otherAppGUID, Namespace: namespace.Name}, new(workloadsv1alpha1.CFApp))).To(Succeed())
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: "process-of-other-app", Namespace: namespace.Name}, new(workloadsv1alpha1.CFProcess))).To(Succeed())
+		})
+
+		It("removes the app from the destinations of its routes", func() {
+			Expect(appRepo.DeleteApp(testCtx, client, DeleteAppMessage{AppGUID: appGUID, SpaceGUID: namespace.Name})).To(Succeed())
+
+			updatedRoute := new(networkingv1alpha1.CFRoute)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: route.Name, Namespace: namespace.Name}, updatedRoute)).To(Succeed())
+			Expect(updatedRoute.Spec.Destinations).To(HaveLen(1))
+			Expect(updatedRoute.Spec.Destinations[0].GUID).To(Equal("destination-of-other-app"))
+		})
+
+		When("the app doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				err := appRepo.DeleteApp(testCtx, client, DeleteAppMessage{AppGUID: "no-such-app", SpaceGUID: namespace.Name})
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+	})
+
+	Describe("App environment variables", func() {
+		var (
+			namespace *corev1.Namespace
+			appGUID   string
+			appCR     *workloadsv1alpha1.CFApp
+		)
+
+		BeforeEach(func() {
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+			Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+			appGUID = generateGUID()
+			appCR = initializeAppCR("some-app", appGUID, namespace.Name)
+			Expect(k8sClient.Create(testCtx, appCR)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+		})
+
+		fetchAppRecord := func() AppRecord {
+			app, err := appRepo.FetchApp(testCtx, client, appGUID)
+			Expect(err).NotTo(HaveOccurred())
+			return app
+		}
+
+		value := func(s string) *string {
+			return &s
+		}
+
+		When("the app has no env secret", func() {
+			It("fetches no environment variables", func() {
+				envVars, err := appRepo.FetchAppEnvVars(testCtx, client, fetchAppRecord())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars.EnvironmentVariables).To(BeEmpty())
+			})
+
+			It("creates the env secret and sets it on the app when the variables are patched", func() {
+				envVars, err := appRepo.PatchAppEnvVars(testCtx, client, PatchAppEnvVarsMessage{
+					AppGUID:              appGUID,
+					SpaceGUID:            namespace.Name,
+					EnvironmentVariables: map[string]*string{"RAILS_ENV": value("production"), "DEBUG": nil},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "production"}))
+
+				app := fetchAppRecord()
+				Expect(app.EnvSecretName).To(Equal(generateAppEnvSecretName(appGUID)))
+
+				secret := new(corev1.Secret)
+				Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: app.EnvSecretName, Namespace: namespace.Name}, secret)).To(Succeed())
+				Expect(secret.Labels).To(HaveKeyWithValue(CFAppGUIDLabel, appGUID))
+			})
+
+			When("a concurrent request created the env secret first", func() {
+				BeforeEach(func() {
+					Expect(k8sClient.Create(testCtx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: generateAppEnvSecretName(appGUID), Namespace: namespace.Name},
+						Data:       map[string][]byte{"DEBUG": []byte("true"), "LOG_LEVEL": []byte("info")},
+					})).To(Succeed())
+				})
+
+				It("patches the variables into the existing secret and sets it on the app", func() {
+					envVars, err := appRepo.PatchAppEnvVars(testCtx, client, PatchAppEnvVarsMessage{
+						AppGUID:              appGUID,
+						SpaceGUID:            namespace.Name,
+						EnvironmentVariables: map[string]*string{"RAILS_ENV": value("production"), "DEBUG": nil},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(envVars.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "production", "LOG_LEVEL": "info"}))
+					Expect(fetchAppRecord().EnvSecretName).To(Equal(generateAppEnvSecretName(appGUID)))
+				})
+			})
+		})
+
+		When("the app has an env secret", func() {
+			BeforeEach(func() {
+				_, err := appRepo.PatchAppEnvVars(testCtx, client, PatchAppEnvVarsMessage{
+					AppGUID:              appGUID,
+					SpaceGUID:            namespace.Name,
+					EnvironmentVariables: map[string]*string{"RAILS_ENV": value("development"), "DEBUG": value("true")},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("fetches the environment variables", func() {
+				envVars, err := appRepo.FetchAppEnvVars(testCtx, client, fetchAppRecord())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "development", "DEBUG": "true"}))
+			})
+
+			It("updates and removes variables, leaving the others alone", func() {
+				envVars, err := appRepo.PatchAppEnvVars(testCtx, client, PatchAppEnvVarsMessage{
+					AppGUID:              appGUID,
+					SpaceGUID:            namespace.Name,
+					EnvSecretName:        fetchAppRecord().EnvSecretName,
+					EnvironmentVariables: map[string]*string{"RAILS_ENV": value("production"), "DEBUG": nil, "LOG_LEVEL": value("info")},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(envVars.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "production", "LOG_LEVEL": "info"}))
+			})
+		})
+	})
+
+	Describe("app state transitions", func() {
+		var (
+			namespace *corev1.Namespace
+			appGUID   string
+			build     workloadsv1alpha1.CFBuild
+			app       AppRecord
+		)
+
+		setBuildSucceeded := func(status metav1.ConditionStatus) {
+			meta.SetStatusCondition(&build.Status.Conditions, metav1.Condition{
+				Type:    SucceededConditionType,
+				Status:  status,
+				Reason:  "kpack",
+				Message: "kpack",
+			})
+			Expect(k8sClient.Status().Update(testCtx, &build)).To(Succeed())
+		}
+
+		fetchDesiredState := func() workloadsv1alpha1.DesiredState {
+			cfApp := new(workloadsv1alpha1.CFApp)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: appGUID, Namespace: namespace.Name}, cfApp)).To(Succeed())
+			return cfApp.Spec.DesiredState
+		}
+
+		BeforeEach(func() {
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+			Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+			appGUID = generateGUID()
+			Expect(k8sClient.Create(testCtx, initializeAppCR("some-app", appGUID, namespace.Name))).To(Succeed())
+
+			build = initializeDropletCR(generateGUID(), appGUID, namespace.Name)
+			Expect(k8sClient.Create(testCtx, &build)).To(Succeed())
+
+			var err error
+			app, err = appRepo.FetchApp(testCtx, client, appGUID)
+			Expect(err).NotTo(HaveOccurred())
+			app.DropletGUID = build.Name
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+		})
+
+		Describe("StartApp", func() {
+			When("the droplet staged successfully", func() {
+				BeforeEach(func() {
+					setBuildSucceeded(metav1.ConditionTrue)
+				})
+
+				It("starts the app", func() {
+					appRecord, err := appRepo.StartApp(testCtx, client, app)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(appRecord.State).To(Equal(StartedState))
+					Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StartedState))
+				})
+			})
+
+			When("the app has no droplet", func() {
+				BeforeEach(func() {
+					app.DropletGUID = ""
+				})
+
+				It("returns an AppNotStartableError", func() {
+					_, err := appRepo.StartApp(testCtx, client, app)
+					Expect(err).To(MatchError(AppNotStartableError{Reason: "Assign a droplet before starting this app."}))
+				})
+			})
+
+			When("the droplet was deleted", func() {
+				BeforeEach(func() {
+					Expect(k8sClient.Delete(testCtx, &build)).To(Succeed())
+				})
+
+				It("returns an AppNotStartableError and leaves the app stopped", func() {
+					_, err := appRepo.StartApp(testCtx, client, app)
+					Expect(err).To(BeAssignableToTypeOf(AppNotStartableError{}))
+					Expect(err.Error()).To(ContainSubstring("no longer exists"))
+					Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StoppedState))
+				})
+			})
+
+			When("the build of the droplet failed", func() {
+				BeforeEach(func() {
+					setBuildSucceeded(metav1.ConditionFalse)
+				})
+
+				It("returns an AppNotStartableError and leaves the app stopped", func() {
+					_, err := appRepo.StartApp(testCtx, client, app)
+					Expect(err).To(BeAssignableToTypeOf(AppNotStartableError{}))
+					Expect(err.Error()).To(ContainSubstring("did not stage successfully"))
+					Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StoppedState))
+				})
+			})
+		})
+
+		Describe("RestartApp", func() {
+			var process *workloadsv1alpha1.CFProcess
+
+			BeforeEach(func() {
+				setBuildSucceeded(metav1.ConditionTrue)
+
+				process = initializeProcessCR(generateGUID(), namespace.Name, appGUID)
+				Expect(k8sClient.Create(testCtx, process)).To(Succeed())
+				Expect(createReadyPod(k8sClient, testCtx, process.Name, namespace.Name)).To(Succeed())
+			})
+
+			It("waits for the processes of the app to stop before starting it", func() {
+				restarted := make(chan AppRecord)
+				go func() {
+					defer GinkgoRecover()
+					appRecord, err := appRepo.RestartApp(testCtx, client, app)
+					Expect(err).NotTo(HaveOccurred())
+					restarted <- appRecord
+				}()
+
+				Eventually(fetchDesiredState).Should(Equal(workloadsv1alpha1.StoppedState))
+				Consistently(restarted).ShouldNot(Receive())
+
+				Expect(deletePods(k8sClient, testCtx, process.Name, namespace.Name)).To(Succeed())
+
+				var appRecord AppRecord
+				Eventually(restarted, 5*time.Second).Should(Receive(&appRecord))
+				Expect(appRecord.State).To(Equal(StartedState))
+				Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StartedState))
+			})
+
+			When("the processes don't stop in time", func() {
+				It("returns an error and starts the app again", func() {
+					_, err := appRepo.RestartApp(testCtx, client, app)
+					Expect(err).To(MatchError(ContainSubstring("did not stop")))
+					Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StartedState))
+				})
+			})
+
+			When("the request is canceled while the processes stop", func() {
+				It("returns an error and starts the app again", func() {
+					requestCtx, cancelFn := context.WithCancel(testCtx)
+					go func() {
+						defer GinkgoRecover()
+						Eventually(fetchDesiredState).Should(Equal(workloadsv1alpha1.StoppedState))
+						cancelFn()
+					}()
+
+					_, err := appRepo.RestartApp(requestCtx, client, app)
+					Expect(err).To(MatchError(ContainSubstring("context canceled")))
+					Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StartedState))
+				})
+			})
+
+			When("the droplet was deleted", func() {
+				BeforeEach(func() {
+					Expect(k8sClient.Delete(testCtx, &build)).To(Succeed())
+				})
+
+				It("doesn't stop the app", func() {
+					cfApp := new(workloadsv1alpha1.CFApp)
+					Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: appGUID, Namespace: namespace.Name}, cfApp)).To(Succeed())
+					cfApp.Spec.DesiredState = workloadsv1alpha1.StartedState
+					Expect(k8sClient.Update(testCtx, cfApp)).To(Succeed())
+
+					_, err := appRepo.RestartApp(testCtx, client, app)
+					Expect(err).To(BeAssignableToTypeOf(AppNotStartableError{}))
+					Expect(fetchDesiredState()).To(Equal(workloadsv1alpha1.StartedState))
+				})
+			})
+		})
+	})
 })
+
+// forbiddenGetClient is the client of a user who may not get any object.
+type forbiddenGetClient struct {
+	client.Client
+}
+
+func (c forbiddenGetClient) Get(_ context.Context, key client.ObjectKey, _ client.Object) error {
+	return k8serrors.NewForbidden(schema.GroupResource{}, key.Name, errors.New("no access"))
+}
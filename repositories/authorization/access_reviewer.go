@@ -0,0 +1,140 @@
+package authorization
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//counterfeiter:generate -o fake -fake-name AccessChecker . AccessChecker
+
+// AccessChecker checks whether the user of a request may perform an action
+type AccessChecker interface {
+	Allowed(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// maxAccessReviewAnswers bounds the number of answers an AccessReviewer keeps. Expired answers are dropped when it is
+// reached, and all answers when none has expired.
+const maxAccessReviewAnswers = 10000
+
+// AccessReviewer asks the API server whether the user of a client may perform an action, with SelfSubjectAccessReviews
+// that the API server answers with its own RBAC rules. Answers are kept for a while per authorization header, so that
+// the requests of a user that check the same access in quick succession don't review it each time.
+type AccessReviewer struct {
+	config  *rest.Config
+	ttl     time.Duration
+	mutex   sync.Mutex
+	answers map[accessReviewKey]accessReviewAnswer
+}
+
+type accessReviewKey struct {
+	authorizationHeader [sha256.Size]byte
+	attributes          authorizationv1.ResourceAttributes
+}
+
+type accessReviewAnswer struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+func NewAccessReviewer(config *rest.Config, ttl time.Duration) *AccessReviewer {
+	return &AccessReviewer{
+		config:  config,
+		ttl:     ttl,
+		answers: map[accessReviewKey]accessReviewAnswer{},
+	}
+}
+
+// ForAuthorizationHeader returns the AccessChecker of the user identified by the bearer token in authorizationHeader
+func (r *AccessReviewer) ForAuthorizationHeader(authorizationHeader string) (AccessChecker, error) {
+	userConfig, err := ConfigForAuthorizationHeader(r.config, authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	userClient, err := client.New(userConfig, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	return &userAccessReviewer{
+		accessReviewer:      r,
+		userClient:          userClient,
+		authorizationHeader: authorizationHeader,
+	}, nil
+}
+
+// Allowed reports whether the user that userClient authenticates as with authorizationHeader may perform the action
+// described by attributes
+func (r *AccessReviewer) Allowed(ctx context.Context, userClient client.Client, authorizationHeader string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	key := accessReviewKey{
+		authorizationHeader: sha256.Sum256([]byte(authorizationHeader)),
+		attributes:          attributes,
+	}
+
+	now := time.Now()
+	if allowed, ok := r.cachedAnswer(key, now); ok {
+		return allowed, nil
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+	}
+	err := userClient.Create(ctx, review)
+	if err != nil {
+		if k8serrors.IsUnauthorized(err) {
+			return false, UnauthorizedErr{Err: err}
+		}
+		return false, fmt.Errorf("failed to review access: %w", err)
+	}
+
+	r.storeAnswer(key, review.Status.Allowed, now)
+	return review.Status.Allowed, nil
+}
+
+func (r *AccessReviewer) cachedAnswer(key accessReviewKey, now time.Time) (bool, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	answer, ok := r.answers[key]
+	if !ok || now.After(answer.expiresAt) {
+		return false, false
+	}
+	return answer.allowed, true
+}
+
+func (r *AccessReviewer) storeAnswer(key accessReviewKey, allowed bool, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.answers) >= maxAccessReviewAnswers {
+		for k, answer := range r.answers {
+			if now.After(answer.expiresAt) {
+				delete(r.answers, k)
+			}
+		}
+		if len(r.answers) >= maxAccessReviewAnswers {
+			r.answers = map[accessReviewKey]accessReviewAnswer{}
+		}
+	}
+
+	r.answers[key] = accessReviewAnswer{allowed: allowed, expiresAt: now.Add(r.ttl)}
+}
+
+type userAccessReviewer struct {
+	accessReviewer      *AccessReviewer
+	userClient          client.Client
+	authorizationHeader string
+}
+
+func (r *userAccessReviewer) Allowed(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	return r.accessReviewer.Allowed(ctx, r.userClient, r.authorizationHeader, attributes)
+}
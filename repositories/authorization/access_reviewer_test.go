@@ -0,0 +1,108 @@
+package authorization_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reviewingClient answers SelfSubjectAccessReviews by allowing the verbs in allowedVerbs
+type reviewingClient struct {
+	client.Client
+	allowedVerbs map[string]bool
+	reviews      int
+	err          error
+}
+
+func (c *reviewingClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.reviews++
+	if c.err != nil {
+		return c.err
+	}
+	review := obj.(*authorizationv1.SelfSubjectAccessReview)
+	review.Status.Allowed = c.allowedVerbs[review.Spec.ResourceAttributes.Verb]
+	return nil
+}
+
+var _ = Describe("AccessReviewer", func() {
+	var (
+		ctx            context.Context
+		accessReviewer *authorization.AccessReviewer
+		userClient     *reviewingClient
+		attributes     authorizationv1.ResourceAttributes
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		accessReviewer = authorization.NewAccessReviewer(&rest.Config{}, time.Minute)
+		userClient = &reviewingClient{allowedVerbs: map[string]bool{"get": true}}
+		attributes = authorizationv1.ResourceAttributes{Namespace: "space-guid", Verb: "get", Resource: "cfapps"}
+	})
+
+	It("answers with the access review of the API server", func() {
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+
+		attributes.Verb = "delete"
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeFalse())
+	})
+
+	It("reuses answers for the same authorization header and action", func() {
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+		Expect(userClient.reviews).To(Equal(1))
+
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer other-token", attributes)).To(BeTrue())
+		attributes.Namespace = "other-space-guid"
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+		Expect(userClient.reviews).To(Equal(3))
+	})
+
+	It("reviews the access again once the answer has expired", func() {
+		accessReviewer = authorization.NewAccessReviewer(&rest.Config{}, 0)
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+		time.Sleep(time.Millisecond)
+		Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+		Expect(userClient.reviews).To(Equal(2))
+	})
+
+	Describe("ForAuthorizationHeader", func() {
+		It("returns an unauthorized error for a malformed authorization header", func() {
+			_, err := accessReviewer.ForAuthorizationHeader("not-a-bearer-token")
+			Expect(authorization.IsUnauthorized(err)).To(BeTrue())
+		})
+	})
+
+	When("the token is not valid", func() {
+		BeforeEach(func() {
+			userClient.err = k8serrors.NewUnauthorized("invalid token")
+		})
+
+		It("returns an unauthorized error", func() {
+			_, err := accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)
+			Expect(authorization.IsUnauthorized(err)).To(BeTrue())
+		})
+	})
+
+	When("the access cannot be reviewed", func() {
+		BeforeEach(func() {
+			userClient.err = k8serrors.NewInternalError(errors.New("boom"))
+		})
+
+		It("returns the error and doesn't keep an answer", func() {
+			_, err := accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)
+			Expect(err).To(MatchError(ContainSubstring("boom")))
+			Expect(authorization.IsUnauthorized(err)).To(BeFalse())
+
+			userClient.err = nil
+			Expect(accessReviewer.Allowed(ctx, userClient, "Bearer my-token", attributes)).To(BeTrue())
+		})
+	})
+})
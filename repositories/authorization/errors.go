@@ -2,10 +2,20 @@ package authorization
 
 import "errors"
 
-type UnauthorizedErr struct{}
+// UnauthorizedErr means the caller of a request could not be authenticated. Err, when set, says why.
+type UnauthorizedErr struct {
+	Err error
+}
 
 func (e UnauthorizedErr) Error() string {
-	return "unauthorized"
+	if e.Err == nil {
+		return "unauthorized"
+	}
+	return "unauthorized: " + e.Err.Error()
+}
+
+func (e UnauthorizedErr) Unwrap() error {
+	return e.Err
 }
 
 func IsUnauthorized(err error) bool {
@@ -0,0 +1,120 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	v1 "k8s.io/api/authorization/v1"
+)
+
+type AccessChecker struct {
+	AllowedStub        func(context.Context, v1.ResourceAttributes) (bool, error)
+	allowedMutex       sync.RWMutex
+	allowedArgsForCall []struct {
+		arg1 context.Context
+		arg2 v1.ResourceAttributes
+	}
+	allowedReturns struct {
+		result1 bool
+		result2 error
+	}
+	allowedReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *AccessChecker) Allowed(arg1 context.Context, arg2 v1.ResourceAttributes) (bool, error) {
+	fake.allowedMutex.Lock()
+	ret, specificReturn := fake.allowedReturnsOnCall[len(fake.allowedArgsForCall)]
+	fake.allowedArgsForCall = append(fake.allowedArgsForCall, struct {
+		arg1 context.Context
+		arg2 v1.ResourceAttributes
+	}{arg1, arg2})
+	stub := fake.AllowedStub
+	fakeReturns := fake.allowedReturns
+	fake.recordInvocation("Allowed", []interface{}{arg1, arg2})
+	fake.allowedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *AccessChecker) AllowedCallCount() int {
+	fake.allowedMutex.RLock()
+	defer fake.allowedMutex.RUnlock()
+	return len(fake.allowedArgsForCall)
+}
+
+func (fake *AccessChecker) AllowedCalls(stub func(context.Context, v1.ResourceAttributes) (bool, error)) {
+	fake.allowedMutex.Lock()
+	defer fake.allowedMutex.Unlock()
+	fake.AllowedStub = stub
+}
+
+func (fake *AccessChecker) AllowedArgsForCall(i int) (context.Context, v1.ResourceAttributes) {
+	fake.allowedMutex.RLock()
+	defer fake.allowedMutex.RUnlock()
+	argsForCall := fake.allowedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *AccessChecker) AllowedReturns(result1 bool, result2 error) {
+	fake.allowedMutex.Lock()
+	defer fake.allowedMutex.Unlock()
+	fake.AllowedStub = nil
+	fake.allowedReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AccessChecker) AllowedReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.allowedMutex.Lock()
+	defer fake.allowedMutex.Unlock()
+	fake.AllowedStub = nil
+	if fake.allowedReturnsOnCall == nil {
+		fake.allowedReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.allowedReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AccessChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.allowedMutex.RLock()
+	defer fake.allowedMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *AccessChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ authorization.AccessChecker = new(AccessChecker)
@@ -43,14 +43,14 @@ func (p *IdentityProvider) GetIdentity(ctx context.Context, authorizationHeader
 	case bearerScheme:
 		return p.tokenInspector.WhoAmI(ctx, value)
 	default:
-		return Identity{}, errors.New("unsupported authentication scheme")
+		return Identity{}, UnauthorizedErr{Err: errors.New("unsupported authentication scheme")}
 	}
 }
 
 func parseAuthorizationHeader(headerValue string) (string, string, error) {
 	values := strings.Split(headerValue, " ")
 	if len(values) != 2 {
-		return "", "", errors.New("failed to parse authorization header")
+		return "", "", UnauthorizedErr{Err: errors.New("failed to parse authorization header")}
 	}
 	return values[0], values[1], nil
 }
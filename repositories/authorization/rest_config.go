@@ -0,0 +1,30 @@
+package authorization
+
+import (
+	"errors"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// ConfigForAuthorizationHeader returns a copy of config that carries no credentials
+// of its own and authenticates with the bearer token from the given Authorization header.
+func ConfigForAuthorizationHeader(config *rest.Config, authorizationHeader string) (*rest.Config, error) {
+	if authorizationHeader == "" {
+		return nil, UnauthorizedErr{}
+	}
+
+	scheme, value, err := parseAuthorizationHeader(authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(scheme) != bearerScheme {
+		return nil, UnauthorizedErr{Err: errors.New("unsupported authentication scheme")}
+	}
+
+	userConfig := rest.AnonymousClientConfig(config)
+	userConfig.BearerToken = value
+
+	return userConfig, nil
+}
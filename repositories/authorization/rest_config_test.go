@@ -0,0 +1,98 @@
+package authorization_test
+
+import (
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("ConfigForAuthorizationHeader", func() {
+	var (
+		authHeader     string
+		privConfig     *rest.Config
+		userConfig     *rest.Config
+		err            error
+		privilegedCert = []byte("privileged-cert")
+	)
+
+	BeforeEach(func() {
+		authHeader = "Bearer my-token"
+		privConfig = &rest.Config{
+			Host:        "https://api.example.org",
+			BearerToken: "privileged-token",
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData:   []byte("ca-data"),
+				CertData: privilegedCert,
+				KeyData:  []byte("privileged-key"),
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		userConfig, err = authorization.ConfigForAuthorizationHeader(privConfig, authHeader)
+	})
+
+	It("uses the token from the header", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(userConfig.BearerToken).To(Equal("my-token"))
+	})
+
+	It("keeps the server address and CA", func() {
+		Expect(userConfig.Host).To(Equal("https://api.example.org"))
+		Expect(userConfig.CAData).To(Equal([]byte("ca-data")))
+	})
+
+	It("drops the privileged client credentials", func() {
+		Expect(userConfig.CertData).To(BeEmpty())
+		Expect(userConfig.KeyData).To(BeEmpty())
+	})
+
+	It("does not modify the original config", func() {
+		Expect(privConfig.BearerToken).To(Equal("privileged-token"))
+		Expect(privConfig.CertData).To(Equal(privilegedCert))
+	})
+
+	When("the scheme is lowercase", func() {
+		BeforeEach(func() {
+			authHeader = "bearer my-token"
+		})
+
+		It("uses the token from the header", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userConfig.BearerToken).To(Equal("my-token"))
+		})
+	})
+
+	When("the authorization header is not set", func() {
+		BeforeEach(func() {
+			authHeader = ""
+		})
+
+		It("returns an UnauthorizedErr", func() {
+			Expect(err).To(BeAssignableToTypeOf(authorization.UnauthorizedErr{}))
+		})
+	})
+
+	When("the authorization header uses an unsupported authentication scheme", func() {
+		BeforeEach(func() {
+			authHeader = "Scarer boo"
+		})
+
+		It("returns an UnauthorizedErr", func() {
+			Expect(authorization.IsUnauthorized(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("unsupported authentication scheme")))
+		})
+	})
+
+	When("the authorization header is not recognized", func() {
+		BeforeEach(func() {
+			authHeader = "foo"
+		})
+
+		It("returns an UnauthorizedErr", func() {
+			Expect(authorization.IsUnauthorized(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("failed to parse authorization header")))
+		})
+	})
+})
@@ -46,6 +46,7 @@ type BuildRecord struct {
 	PackageGUID     string
 	DropletGUID     string
 	AppGUID         string
+	SpaceGUID       string
 	Labels          map[string]string
 	Annotations     map[string]string
 }
@@ -54,16 +55,30 @@ type BuildRecord struct {
 //+kubebuilder:rbac:groups=workloads.cloudfoundry.org,resources=cfbuilds/status,verbs=get
 
 type BuildRepo struct {
+	namespaceCache *GUIDNamespaceCache
+}
+
+func NewBuildRepo(namespaceCache *GUIDNamespaceCache) *BuildRepo {
+	return &BuildRepo{namespaceCache: namespaceCache}
 }
 
 func (b *BuildRepo) FetchBuild(ctx context.Context, k8sClient client.Client, buildGUID string) (BuildRecord, error) {
-	// TODO: Could look up namespace from guid => namespace cache to do Get
+	cfBuild := &workloadsv1alpha1.CFBuild{}
+	found, err := b.namespaceCache.fetch(ctx, k8sClient, buildGUID, cfBuild)
+	if err != nil {
+		return BuildRecord{}, err
+	}
+	if found {
+		return b.cfBuildToBuildRecord(*cfBuild), nil
+	}
+
 	buildList := &workloadsv1alpha1.CFBuildList{}
-	err := k8sClient.List(ctx, buildList)
+	err = k8sClient.List(ctx, buildList)
 	if err != nil { // untested
 		return BuildRecord{}, err
 	}
 	allBuilds := buildList.Items
+	cacheBuildNamespaces(b.namespaceCache, allBuilds)
 	matches := filterBuildsByMetadataName(allBuilds, buildGUID)
 
 	return b.returnBuild(matches)
@@ -101,6 +116,7 @@ func (b *BuildRepo) cfBuildToBuildRecord(cfBuild workloadsv1alpha1.CFBuild) Buil
 		PackageGUID: cfBuild.Spec.PackageRef.Name,
 		DropletGUID: "",
 		AppGUID:     cfBuild.Spec.AppRef.Name,
+		SpaceGUID:   cfBuild.Namespace,
 		Labels:      cfBuild.Labels,
 		Annotations: cfBuild.Annotations,
 	}
@@ -126,6 +142,13 @@ func (b *BuildRepo) cfBuildToBuildRecord(cfBuild workloadsv1alpha1.CFBuild) Buil
 	return toReturn
 }
 
+// cacheBuildNamespaces is shared with the DropletRepo, as droplets are read from the CFBuild they were staged by
+func cacheBuildNamespaces(namespaceCache *GUIDNamespaceCache, builds []workloadsv1alpha1.CFBuild) {
+	for _, build := range builds {
+		namespaceCache.Set(build.Name, build.Namespace)
+	}
+}
+
 func filterBuildsByMetadataName(builds []workloadsv1alpha1.CFBuild, name string) []workloadsv1alpha1.CFBuild {
 	var filtered []workloadsv1alpha1.CFBuild
 	for i, build := range builds {
@@ -142,9 +165,25 @@ func (b *BuildRepo) CreateBuild(ctx context.Context, k8sClient client.Client, me
 	if err != nil { // untested!!!
 		return BuildRecord{}, err
 	}
+	b.namespaceCache.Set(cfBuild.Name, cfBuild.Namespace)
 	return b.cfBuildToBuildRecord(cfBuild), nil
 }
 
+func (b *BuildRepo) PatchBuildMetadata(ctx context.Context, k8sClient client.Client, message MetadataPatchMessage) (BuildRecord, error) {
+	cfBuild := &workloadsv1alpha1.CFBuild{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.GUID,
+			Namespace: message.SpaceGUID,
+		},
+	}
+
+	err := patchMetadata(ctx, k8sClient, cfBuild, message.MetadataPatch)
+	if err != nil {
+		return BuildRecord{}, err
+	}
+	return b.cfBuildToBuildRecord(*cfBuild), nil
+}
+
 func (b *BuildRepo) buildCreateToCFBuild(message BuildCreateMessage) workloadsv1alpha1.CFBuild {
 	guid := uuid.New().String()
 	return workloadsv1alpha1.CFBuild{
@@ -49,7 +49,7 @@ var _ = Describe("BuildRepository", func() {
 
 			buildRepo = new(BuildRepo)
 			var err error
-			client, err = BuildCRClient(k8sConfig)
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -377,7 +377,7 @@ var _ = Describe("BuildRepository", func() {
 			buildRepo = new(BuildRepo)
 
 			var err error
-			client, err = BuildCRClient(k8sConfig)
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			beforeCtx := context.Background()
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+
 	k8sclient "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -11,6 +13,19 @@ func BuildK8sClient(config *rest.Config) (k8sclient.Interface, error) {
 	return k8sclient.NewForConfig(config)
 }
 
-func BuildCRClient(config *rest.Config) (crclient.Client, error) {
+// BuildCRClient builds a client that talks to the API server as the user identified by the
+// bearer token in authorizationHeader, so that Kubernetes RBAC applies to every request it makes
+func BuildCRClient(config *rest.Config, authorizationHeader string) (crclient.Client, error) {
+	userConfig, err := authorization.ConfigForAuthorizationHeader(config, authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return crclient.New(userConfig, crclient.Options{Scheme: scheme.Scheme})
+}
+
+// BuildPrivilegedCRClient ignores the authorization header and uses the shim's own credentials.
+// It is only meant to be used when authentication is disabled.
+func BuildPrivilegedCRClient(config *rest.Config, _ string) (crclient.Client, error) {
 	return crclient.New(config, crclient.Options{Scheme: scheme.Scheme})
 }
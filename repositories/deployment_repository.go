@@ -0,0 +1,1534 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	networkingv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/networking/v1alpha1"
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DeploymentStatusValueActive    = "ACTIVE"
+	DeploymentStatusValueFinalized = "FINALIZED"
+
+	DeploymentStatusReasonDeploying = "DEPLOYING"
+	DeploymentStatusReasonPaused    = "PAUSED"
+	DeploymentStatusReasonCanceling = "CANCELING"
+	DeploymentStatusReasonDeployed  = "DEPLOYED"
+	DeploymentStatusReasonCanceled  = "CANCELED"
+
+	DeploymentStrategyRolling = "rolling"
+	DeploymentStrategyCanary  = "canary"
+
+	// DeploymentLabel marks the ConfigMaps that hold deployments. The app of a deployment is in the CFAppGUIDLabelKey
+	// label.
+	DeploymentLabel = "cloudfoundry.org/deployment"
+	// DeploymentGUIDLabel marks the CFApp and the CFProcesses that run the droplet of a deployment beside its app. They
+	// are not CF resources, so they are hidden from the apps of the API.
+	DeploymentGUIDLabel = "cloudfoundry.org/deployment-guid"
+
+	// deploymentOwnerAnnotation is the instance of the shim that moves an active deployment along
+	deploymentOwnerAnnotation = "cloudfoundry.org/deployment-owner"
+
+	deploymentStrategyKey         = "strategy"
+	deploymentDropletKey          = "droplet_guid"
+	deploymentPreviousDropletKey  = "previous_droplet_guid"
+	deploymentStatusValueKey      = "status_value"
+	deploymentStatusReasonKey     = "status_reason"
+	deploymentLastStatusChangeKey = "last_status_change"
+	deploymentRollbackVersionKey  = "rollback_version"
+	deploymentPhaseKey            = "phase"
+	deploymentLeaseRenewedAtKey   = "lease_renewed_at"
+	deploymentNewProcessesKey     = "new_processes"
+	deploymentInstancesKey        = "instances"
+	deploymentDestinationsKey     = "destinations"
+	deploymentContinuedKey        = "continued"
+	deploymentPromotedAtKey       = "promoted_at"
+
+	// The phases of an active deployment of a started app. Instances move from the app to the deployment app while it
+	// is rolling, and back to the app, which then runs the new droplet, while it is promoting.
+	deploymentPhaseRolling   = "rolling"
+	deploymentPhasePromoting = "promoting"
+
+	// processTypeWeb is the process type that a canary deployment runs one instance of before it pauses
+	processTypeWeb = "web"
+
+	defaultDeploymentProcessMemoryMB = 1024
+	defaultDeploymentProcessDiskMB   = 1024
+	deploymentProcessHealthCheckType = "process"
+
+	deploymentPollInterval = time.Second
+)
+
+// Deployments move a started app to a new droplet without downtime. They are stored in ConfigMaps named after the
+// deployment GUID in the namespace of the space of their app.
+//
+// The workload controllers run every process of an app with the current droplet of the app, so a deployment runs its
+// droplet in a CFApp of its own, the deployment app, which is named after the deployment and labeled with
+// DeploymentGUIDLabel. The deployment app gets a process for each process type of the app and of the droplet, and a
+// destination beside each route destination of the app. Routes send requests to the instances of every destination
+// that runs any, so the instances of both apps serve the routes of the app while the deployment rolls.
+//
+// A rolling deployment scales the processes of the deployment app up one instance at a time, and scales the processes
+// of the app down by one instance once each new instance is ready. The destinations of the app are removed from its
+// routes before its last instance stops. The droplet then becomes the current droplet of the app, and the instances
+// move back to the app in the same way. Finally the destinations of the app are restored, the deployment app is
+// deleted, and a revision of the app is recorded. A canary deployment pauses once the first instance of the web
+// process of the deployment app is ready, with the instances of the app still running, until it is continued.
+//
+// A deployment of a revision rolls an app back to it: the deployment app runs with the environment variables and the
+// process commands of the revision, which the app gets as well once it is promoted. A deployment of a stopped app
+// makes the droplet the current droplet of the app and starts it at once, and is created DEPLOYED.
+//
+// Canceling a deployment moves the instances back to the app, which still runs its previous droplet, and deletes the
+// deployment app. A deployment can't be canceled once it is promoting. A deployment that is not deployed within the
+// deployment timeout is canceled, and one that doesn't finish canceling or promoting within the timeout either is
+// finalized at once, with the app scaled to its instances.
+//
+// Each active deployment is moved along by a single instance of the shim, which holds a lease on it. Instances adopt
+// the active deployments whose lease has expired, as the instance that held it has stopped.
+//
+//	ACTIVE/DEPLOYING --> FINALIZED/DEPLOYED
+//	ACTIVE/DEPLOYING --> ACTIVE/PAUSED --continue--> ACTIVE/DEPLOYING      canary deployments only
+//	ACTIVE/DEPLOYING or PAUSED --cancel or timeout--> ACTIVE/CANCELING --> FINALIZED/CANCELED
+
+type DeploymentRecord struct {
+	GUID                string
+	AppGUID             string
+	SpaceGUID           string
+	Strategy            string
+	DropletGUID         string
+	PreviousDropletGUID string
+	StatusValue         string
+	StatusReason        string
+	LastStatusChange    string
+	NewProcesses        []DeploymentProcessRecord
+	CreatedAt           string
+	UpdatedAt           string
+}
+
+// DeploymentProcessRecord is a process of the deployment app of a deployment
+type DeploymentProcessRecord struct {
+	GUID string `json:"guid"`
+	Type string `json:"type"`
+}
+
+type DeploymentCreateMessage struct {
+	App      AppRecord
+	Droplet  DropletRecord
+	Strategy string
+	// Revision is the revision that the app is rolled back to, if any
+	Revision *RevisionRecord
+}
+
+// DeploymentListMessage filters lists of deployments. Empty filters match every deployment.
+type DeploymentListMessage struct {
+	AppGUIDs     []string
+	StatusValues []string
+}
+
+// ActiveDeploymentError is returned when a deployment is created for an app that is being deployed
+type ActiveDeploymentError struct{}
+
+func (e ActiveDeploymentError) Error() string {
+	return "The app has an active deployment. Cancel it or wait for it to finish before creating a new deployment."
+}
+
+// DeploymentStatusError is returned when a deployment is canceled or continued in a status that doesn't allow it
+type DeploymentStatusError struct {
+	Action string
+	Value  string
+	Reason string
+}
+
+func (e DeploymentStatusError) Error() string {
+	return fmt.Sprintf("Cannot %s a deployment with status: %s and reason: %s.", e.Action, e.Value, e.Reason)
+}
+
+// DeploymentPromotingError is returned when a deployment is canceled after its app has started running the new
+// droplet
+type DeploymentPromotingError struct{}
+
+func (e DeploymentPromotingError) Error() string {
+	return "Cannot cancel a deployment once its app runs the new droplet."
+}
+
+// deploymentDestination is a route destination of the app of a deployment, along with the GUID of the destination of
+// the deployment app beside it
+type deploymentDestination struct {
+	RouteGUID          string                         `json:"route_guid"`
+	Destination        networkingv1alpha1.Destination `json:"destination"`
+	NewDestinationGUID string                         `json:"new_destination_guid"`
+}
+
+type DeploymentRepo struct {
+	namespaceCache *GUIDNamespaceCache
+	// privilegedClient finds the namespace of a deployment that is not in the namespace cache, as users may not be
+	// allowed to list ConfigMaps across namespaces, and moves deployments along after the request that created them
+	privilegedClient client.Client
+	revisionRepo     *RevisionRepo
+	// owner identifies this instance of the shim in the leases of the deployments it moves along
+	owner         string
+	leaseDuration time.Duration
+	// timeout limits how long a deployment deploys, and then how long it cancels
+	timeout time.Duration
+}
+
+func NewDeploymentRepo(
+	namespaceCache *GUIDNamespaceCache,
+	privilegedClient client.Client,
+	revisionRepo *RevisionRepo,
+	leaseDuration time.Duration,
+	timeout time.Duration,
+) *DeploymentRepo {
+	return &DeploymentRepo{
+		namespaceCache:   namespaceCache,
+		privilegedClient: privilegedClient,
+		revisionRepo:     revisionRepo,
+		owner:            uuid.NewString(),
+		leaseDuration:    leaseDuration,
+		timeout:          timeout,
+	}
+}
+
+// CreateDeployment starts moving an app to a droplet. The deployment app is created with the client of the user, so
+// that users who may not change the app cannot deploy it.
+func (r *DeploymentRepo) CreateDeployment(ctx context.Context, c client.Client, message DeploymentCreateMessage) (DeploymentRecord, error) {
+	deployments, err := r.fetchDeploymentsForApp(ctx, c, message.App)
+	if err != nil {
+		return DeploymentRecord{}, err
+	}
+	for _, deployment := range deployments {
+		if deployment.StatusValue == DeploymentStatusValueActive {
+			return DeploymentRecord{}, ActiveDeploymentError{}
+		}
+	}
+
+	ownerReference, err := fetchAppOwnerReference(ctx, c, message.App.GUID, message.App.SpaceGUID)
+	if err != nil {
+		return DeploymentRecord{}, err
+	}
+
+	deployment := DeploymentRecord{
+		GUID:                uuid.NewString(),
+		AppGUID:             message.App.GUID,
+		SpaceGUID:           message.App.SpaceGUID,
+		Strategy:            message.Strategy,
+		DropletGUID:         message.Droplet.GUID,
+		PreviousDropletGUID: message.App.DropletGUID,
+		StatusValue:         DeploymentStatusValueActive,
+		StatusReason:        DeploymentStatusReasonDeploying,
+		LastStatusChange:    time.Now().UTC().Format(TimestampFormat),
+	}
+
+	rollbackVersion := ""
+	if message.Revision != nil {
+		rollbackVersion = strconv.Itoa(message.Revision.Version)
+	}
+
+	configMap := deploymentToConfigMap(deployment)
+	configMap.OwnerReferences = []metav1.OwnerReference{ownerReference}
+	if rollbackVersion != "" {
+		configMap.Data[deploymentRollbackVersionKey] = rollbackVersion
+	}
+
+	if message.App.State == StoppedState {
+		err = r.deployStoppedApp(ctx, c, message, rollbackVersion)
+		if err != nil {
+			return DeploymentRecord{}, err
+		}
+		configMap.Data[deploymentStatusValueKey] = DeploymentStatusValueFinalized
+		configMap.Data[deploymentStatusReasonKey] = DeploymentStatusReasonDeployed
+	} else {
+		err = r.createDeploymentApp(ctx, c, message, deployment.GUID, ownerReference, configMap)
+		if err != nil {
+			// best effort, the objects that were created are deleted with the app otherwise
+			_ = deleteDeploymentApp(ctx, c, deployment.SpaceGUID, deployment.GUID)
+			return DeploymentRecord{}, err
+		}
+		configMap.Annotations = map[string]string{deploymentOwnerAnnotation: r.owner}
+		configMap.Data[deploymentLeaseRenewedAtKey] = time.Now().UTC().Format(time.RFC3339Nano)
+		configMap.Data[deploymentPhaseKey] = deploymentPhaseRolling
+	}
+
+	err = c.Create(ctx, configMap)
+	if err != nil {
+		if configMap.Data[deploymentStatusValueKey] == DeploymentStatusValueActive {
+			_ = deleteDeploymentApp(ctx, c, deployment.SpaceGUID, deployment.GUID)
+		}
+		return DeploymentRecord{}, fmt.Errorf("error creating deployment: %w", err)
+	}
+	r.namespaceCache.Set(configMap.Name, configMap.Namespace)
+
+	if configMap.Data[deploymentStatusValueKey] == DeploymentStatusValueActive {
+		go r.run(configMap.Namespace, configMap.Name)
+	}
+
+	return configMapToDeploymentRecord(*configMap), nil
+}
+
+// deployStoppedApp makes the droplet of a deployment the current droplet of its stopped app and starts the app
+func (r *DeploymentRepo) deployStoppedApp(ctx context.Context, c client.Client, message DeploymentCreateMessage, rollbackVersion string) error {
+	if message.Revision != nil {
+		err := restoreEnvironmentVariables(ctx, c, message.App, message.Revision.EnvironmentVariables)
+		if err != nil {
+			return err
+		}
+		err = patchProcessCommands(ctx, c, message.App, revisionCommands(message.Droplet, *message.Revision))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := patchAppDroplet(ctx, c, message.App.GUID, message.App.SpaceGUID, message.Droplet.GUID, StartedState)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.revisionRepo.RecordRevision(ctx, c, RevisionCreateMessage{
+		AppGUID:     message.App.GUID,
+		SpaceGUID:   message.App.SpaceGUID,
+		Description: rollbackDescription(rollbackVersion),
+	})
+	return err
+}
+
+// createDeploymentApp creates the deployment app of a deployment with a process for each process type of the app and
+// of the droplet, without any instances. The processes are created ahead of the CFApp, so that the app controller
+// doesn't create processes of its own for it. The instances that the processes of the deployment app get, the
+// processes and the route destinations of the app are stored in configMap.
+func (r *DeploymentRepo) createDeploymentApp(
+	ctx context.Context,
+	c client.Client,
+	message DeploymentCreateMessage,
+	deploymentGUID string,
+	ownerReference metav1.OwnerReference,
+	configMap *corev1.ConfigMap,
+) error {
+	app := message.App
+
+	cfApp := &workloadsv1alpha1.CFApp{}
+	err := c.Get(ctx, types.NamespacedName{Name: app.GUID, Namespace: app.SpaceGUID}, cfApp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return NotFoundError{Err: err}
+		}
+		return fmt.Errorf("error fetching app %q: %w", app.GUID, err)
+	}
+
+	processList := &workloadsv1alpha1.CFProcessList{}
+	err = c.List(ctx, processList, listOptionsForApp(c, app.SpaceGUID, app.GUID)...)
+	if err != nil {
+		return fmt.Errorf("error listing the processes of app %q: %w", app.GUID, err)
+	}
+	processes := map[string]workloadsv1alpha1.CFProcess{}
+	for _, process := range filterProcessesByAppGUID(processList.Items, app.GUID) {
+		processes[process.Spec.ProcessType] = process
+	}
+
+	commands, err := r.deploymentCommands(ctx, c, message, processes)
+	if err != nil {
+		return err
+	}
+
+	envSecretName := cfApp.Spec.EnvSecretName
+	if message.Revision != nil {
+		secret := appEnvVarsRecordToSecret(AppEnvVarsRecord{
+			AppGUID:              deploymentGUID,
+			SpaceGUID:            app.SpaceGUID,
+			EnvironmentVariables: message.Revision.EnvironmentVariables,
+		})
+		secret.OwnerReferences = []metav1.OwnerReference{ownerReference}
+		err = c.Create(ctx, &secret)
+		if err != nil {
+			return fmt.Errorf("error creating env secret of deployment: %w", err)
+		}
+		envSecretName = secret.Name
+	}
+
+	instances := map[string]int{}
+	newProcesses := []DeploymentProcessRecord{}
+	for _, processType := range sortedKeys(commands) {
+		process, ok := processes[processType]
+		if ok {
+			instances[processType] = process.Spec.DesiredInstances
+		} else {
+			process = defaultDeploymentProcess(processType)
+			if processType == processTypeWeb {
+				instances[processType] = 1
+			}
+		}
+
+		newProcess := &workloadsv1alpha1.CFProcess{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uuid.NewString(),
+				Namespace: app.SpaceGUID,
+				Labels: map[string]string{
+					DeploymentGUIDLabel:                     deploymentGUID,
+					workloadsv1alpha1.CFAppGUIDLabelKey:     deploymentGUID,
+					workloadsv1alpha1.CFProcessTypeLabelKey: processType,
+				},
+				OwnerReferences: []metav1.OwnerReference{ownerReference},
+			},
+			Spec: process.Spec,
+		}
+		newProcess.Labels[workloadsv1alpha1.CFProcessGUIDLabelKey] = newProcess.Name
+		newProcess.Spec.AppRef = corev1.LocalObjectReference{Name: deploymentGUID}
+		newProcess.Spec.ProcessType = processType
+		newProcess.Spec.Command = commands[processType]
+		newProcess.Spec.DesiredInstances = 0
+		err = c.Create(ctx, newProcess)
+		if err != nil {
+			return fmt.Errorf("error creating %s process of deployment: %w", processType, err)
+		}
+		newProcesses = append(newProcesses, DeploymentProcessRecord{GUID: newProcess.Name, Type: processType})
+	}
+
+	newApp := &workloadsv1alpha1.CFApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            deploymentGUID,
+			Namespace:       app.SpaceGUID,
+			Labels:          map[string]string{DeploymentGUIDLabel: deploymentGUID},
+			OwnerReferences: []metav1.OwnerReference{ownerReference},
+		},
+		Spec: workloadsv1alpha1.CFAppSpec{
+			// app names are unique in a space, and deployment apps are not listed among the apps of the space
+			Name:              cfApp.Spec.Name + "-" + deploymentGUID,
+			DesiredState:      workloadsv1alpha1.StartedState,
+			CurrentDropletRef: corev1.LocalObjectReference{Name: message.Droplet.GUID},
+			EnvSecretName:     envSecretName,
+			Lifecycle:         cfApp.Spec.Lifecycle,
+		},
+	}
+	err = c.Create(ctx, newApp)
+	if err != nil {
+		return fmt.Errorf("error creating app of deployment: %w", err)
+	}
+
+	destinations, err := appRouteDestinations(ctx, c, app)
+	if err != nil {
+		return err
+	}
+
+	return setDeploymentJSONData(configMap, map[string]interface{}{
+		deploymentInstancesKey:    instances,
+		deploymentNewProcessesKey: newProcesses,
+		deploymentDestinationsKey: destinations,
+	})
+}
+
+// deploymentCommands returns the commands that the processes of the deployment app run, keyed by process type. The
+// processes of a rollback run the commands of the revision. Other processes run the command of their process type in
+// the droplet, unless their process in the app has a command that isn't the one of the current droplet of the app.
+func (r *DeploymentRepo) deploymentCommands(
+	ctx context.Context,
+	c client.Client,
+	message DeploymentCreateMessage,
+	processes map[string]workloadsv1alpha1.CFProcess,
+) (map[string]string, error) {
+	commands := map[string]string{}
+	for processType, process := range processes {
+		commands[processType] = process.Spec.Command
+	}
+
+	if message.Revision != nil {
+		for processType, command := range revisionCommands(message.Droplet, *message.Revision) {
+			commands[processType] = command
+		}
+		return commands, nil
+	}
+
+	currentCommands, err := dropletProcessTypes(ctx, c, message.App.SpaceGUID, message.App.DropletGUID)
+	if err != nil {
+		return nil, err
+	}
+	for processType, command := range message.Droplet.ProcessTypes {
+		current, ok := commands[processType]
+		if !ok || current == "" || current == currentCommands[processType] {
+			commands[processType] = command
+		}
+	}
+
+	return commands, nil
+}
+
+// dropletProcessTypes returns the commands of the process types of a droplet. It returns none when the droplet doesn't
+// exist.
+func dropletProcessTypes(ctx context.Context, c client.Client, namespace, dropletGUID string) (map[string]string, error) {
+	commands := map[string]string{}
+	if dropletGUID == "" {
+		return commands, nil
+	}
+
+	cfBuild := &workloadsv1alpha1.CFBuild{}
+	err := c.Get(ctx, types.NamespacedName{Name: dropletGUID, Namespace: namespace}, cfBuild)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return commands, nil
+		}
+		return nil, fmt.Errorf("error fetching droplet %q: %w", dropletGUID, err)
+	}
+
+	if cfBuild.Status.BuildDropletStatus != nil {
+		for _, processType := range cfBuild.Status.BuildDropletStatus.ProcessTypes {
+			commands[processType.Type] = processType.Command
+		}
+	}
+	return commands, nil
+}
+
+// defaultDeploymentProcess returns a process with the settings that the app controller gives the processes it creates
+func defaultDeploymentProcess(processType string) workloadsv1alpha1.CFProcess {
+	return workloadsv1alpha1.CFProcess{
+		Spec: workloadsv1alpha1.CFProcessSpec{
+			ProcessType: processType,
+			HealthCheck: workloadsv1alpha1.HealthCheck{Type: deploymentProcessHealthCheckType},
+			MemoryMB:    defaultDeploymentProcessMemoryMB,
+			DiskQuotaMB: defaultDeploymentProcessDiskMB,
+			Ports:       []int32{defaultProcessPort},
+		},
+	}
+}
+
+// appRouteDestinations returns the route destinations of an app, each with a new GUID for the destination of the
+// deployment app beside it
+func appRouteDestinations(ctx context.Context, c client.Client, app AppRecord) ([]deploymentDestination, error) {
+	routeList := &networkingv1alpha1.CFRouteList{}
+	err := c.List(ctx, routeList, listOptionsForApp(c, app.SpaceGUID, app.GUID)...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing the routes of app %q: %w", app.GUID, err)
+	}
+
+	destinations := []deploymentDestination{}
+	for _, route := range routeList.Items {
+		for _, destination := range route.Spec.Destinations {
+			if destination.AppRef.Name != app.GUID {
+				continue
+			}
+			destinations = append(destinations, deploymentDestination{
+				RouteGUID:          route.Name,
+				Destination:        destination,
+				NewDestinationGUID: uuid.NewString(),
+			})
+		}
+	}
+	return destinations, nil
+}
+
+func (r *DeploymentRepo) FetchDeployment(ctx context.Context, c client.Client, guid string) (DeploymentRecord, error) {
+	configMap := &corev1.ConfigMap{}
+	found, err := r.namespaceCache.fetch(ctx, c, guid, configMap)
+	if err != nil {
+		return DeploymentRecord{}, err
+	}
+
+	if !found {
+		configMapList := &corev1.ConfigMapList{}
+		err = r.privilegedClient.List(ctx, configMapList, client.HasLabels{DeploymentLabel}, client.MatchingFields{"metadata.name": guid})
+		if err != nil {
+			return DeploymentRecord{}, fmt.Errorf("error finding deployment %q: %w", guid, err)
+		}
+		if len(configMapList.Items) == 0 {
+			return DeploymentRecord{}, NotFoundError{}
+		}
+
+		namespace := configMapList.Items[0].Namespace
+		err = c.Get(ctx, types.NamespacedName{Name: guid, Namespace: namespace}, configMap)
+		if err != nil {
+			if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) {
+				return DeploymentRecord{}, NotFoundError{Err: err}
+			}
+			return DeploymentRecord{}, err
+		}
+		r.namespaceCache.Set(guid, namespace)
+	}
+
+	if _, ok := configMap.Labels[DeploymentLabel]; !ok {
+		return DeploymentRecord{}, NotFoundError{}
+	}
+
+	return configMapToDeploymentRecord(*configMap), nil
+}
+
+// FetchDeploymentList returns the deployments of the apps the user can read, oldest first
+func (r *DeploymentRepo) FetchDeploymentList(ctx context.Context, c client.Client, message DeploymentListMessage) ([]DeploymentRecord, error) {
+	appList := &workloadsv1alpha1.CFAppList{}
+	err := c.List(ctx, appList)
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+
+	appFilter := toMap(message.AppGUIDs)
+	namespaces := []string{}
+	seen := map[string]struct{}{}
+	for _, app := range appList.Items {
+		if _, ok := seen[app.Namespace]; ok || !matchFilter(appFilter, app.Name) {
+			continue
+		}
+		seen[app.Namespace] = struct{}{}
+		namespaces = append(namespaces, app.Namespace)
+	}
+
+	configMapList := &corev1.ConfigMapList{}
+	err = listInNamespaces(ctx, c, configMapList, namespaces, client.HasLabels{DeploymentLabel})
+	if err != nil {
+		return nil, fmt.Errorf("error listing deployments: %w", err)
+	}
+
+	statusFilter := toMap(message.StatusValues)
+	deployments := []DeploymentRecord{}
+	for _, configMap := range configMapList.Items {
+		deployment := configMapToDeploymentRecord(configMap)
+		if matchFilter(appFilter, deployment.AppGUID) && matchFilter(statusFilter, deployment.StatusValue) {
+			deployments = append(deployments, deployment)
+		}
+	}
+	sortDeployments(deployments)
+
+	return deployments, nil
+}
+
+// CancelDeployment stops a deploying or paused deployment. The deployment is CANCELING until its instances have moved
+// back to the processes of its app.
+func (r *DeploymentRepo) CancelDeployment(ctx context.Context, c client.Client, deployment DeploymentRecord) (DeploymentRecord, error) {
+	return r.changeStatus(ctx, c, deployment, "cancel", func(configMap *corev1.ConfigMap) error {
+		value, reason := configMap.Data[deploymentStatusValueKey], configMap.Data[deploymentStatusReasonKey]
+		if value != DeploymentStatusValueActive || (reason != DeploymentStatusReasonDeploying && reason != DeploymentStatusReasonPaused) {
+			return DeploymentStatusError{Action: "cancel", Value: value, Reason: reason}
+		}
+		if configMap.Data[deploymentPhaseKey] == deploymentPhasePromoting {
+			return DeploymentPromotingError{}
+		}
+		return nil
+	}, map[string]string{
+		deploymentStatusReasonKey: DeploymentStatusReasonCanceling,
+	})
+}
+
+// ContinueDeployment lets a paused canary deployment roll the rest of its instances
+func (r *DeploymentRepo) ContinueDeployment(ctx context.Context, c client.Client, deployment DeploymentRecord) (DeploymentRecord, error) {
+	return r.changeStatus(ctx, c, deployment, "continue", func(configMap *corev1.ConfigMap) error {
+		value, reason := configMap.Data[deploymentStatusValueKey], configMap.Data[deploymentStatusReasonKey]
+		if value != DeploymentStatusValueActive || reason != DeploymentStatusReasonPaused {
+			return DeploymentStatusError{Action: "continue", Value: value, Reason: reason}
+		}
+		return nil
+	}, map[string]string{
+		deploymentStatusReasonKey: DeploymentStatusReasonDeploying,
+		deploymentContinuedKey:    "true",
+	})
+}
+
+// ResumeDeployments adopts the active deployments whose lease has expired and moves them along. Deployments that were
+// stored before deployments had leases are adopted at once.
+func (r *DeploymentRepo) ResumeDeployments(ctx context.Context) error {
+	configMapList := &corev1.ConfigMapList{}
+	err := r.privilegedClient.List(ctx, configMapList, client.HasLabels{DeploymentLabel})
+	if err != nil {
+		return fmt.Errorf("error listing deployments: %w", err)
+	}
+
+	now := time.Now()
+	for i := range configMapList.Items {
+		configMap := &configMapList.Items[i]
+		owner := configMap.Annotations[deploymentOwnerAnnotation]
+		if configMap.Data[deploymentStatusValueKey] != DeploymentStatusValueActive || owner == r.owner ||
+			(owner != "" && !r.leaseExpired(*configMap, now)) {
+			continue
+		}
+
+		baseConfigMap := configMap.DeepCopy()
+		configMap.Annotations = withAnnotation(configMap.Annotations, deploymentOwnerAnnotation, r.owner)
+		configMap.Data[deploymentLeaseRenewedAtKey] = now.UTC().Format(time.RFC3339Nano)
+		err = r.privilegedClient.Patch(ctx, configMap, client.MergeFromWithOptions(baseConfigMap, client.MergeFromWithOptimisticLock{}))
+		if err != nil {
+			if k8serrors.IsConflict(err) || k8serrors.IsNotFound(err) {
+				// another instance has adopted the deployment, or it has been deleted
+				continue
+			}
+			return fmt.Errorf("error adopting deployment %q: %w", configMap.Name, err)
+		}
+
+		go r.run(configMap.Namespace, configMap.Name)
+	}
+
+	return nil
+}
+
+// ResumeDeploymentsPeriodically calls ResumeDeployments every interval until ctx is done. Errors are retried at the
+// next interval.
+func (r *DeploymentRepo) ResumeDeploymentsPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = r.ResumeDeployments(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaseExpired reports whether the instance that moves a deployment along has stopped renewing its lease
+func (r *DeploymentRepo) leaseExpired(configMap corev1.ConfigMap, now time.Time) bool {
+	renewedAt, err := time.Parse(time.RFC3339Nano, configMap.Data[deploymentLeaseRenewedAtKey])
+	if err != nil {
+		return true
+	}
+	return now.Sub(renewedAt) > r.leaseDuration
+}
+
+// changeStatus applies changes to the data of a deployment when check allows the action in its current status
+func (r *DeploymentRepo) changeStatus(
+	ctx context.Context,
+	c client.Client,
+	deployment DeploymentRecord,
+	action string,
+	check func(configMap *corev1.ConfigMap) error,
+	changes map[string]string,
+) (DeploymentRecord, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Name: deployment.GUID, Namespace: deployment.SpaceGUID}, configMap)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return DeploymentRecord{}, NotFoundError{Err: err}
+		}
+		return DeploymentRecord{}, err
+	}
+
+	err = check(configMap)
+	if err != nil {
+		return DeploymentRecord{}, err
+	}
+
+	err = patchDeploymentData(ctx, c, configMap, changes)
+	if err != nil {
+		return DeploymentRecord{}, fmt.Errorf("failed to %s deployment: %w", action, err)
+	}
+
+	return configMapToDeploymentRecord(*configMap), nil
+}
+
+func (r *DeploymentRepo) fetchDeploymentsForApp(ctx context.Context, c client.Client, app AppRecord) ([]DeploymentRecord, error) {
+	configMapList := &corev1.ConfigMapList{}
+	err := c.List(ctx, configMapList,
+		client.InNamespace(app.SpaceGUID),
+		client.HasLabels{DeploymentLabel},
+		client.MatchingLabels{workloadsv1alpha1.CFAppGUIDLabelKey: app.GUID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing deployments of app %q: %w", app.GUID, err)
+	}
+
+	deployments := make([]DeploymentRecord, 0, len(configMapList.Items))
+	for _, configMap := range configMapList.Items {
+		deployments = append(deployments, configMapToDeploymentRecord(configMap))
+	}
+
+	return deployments, nil
+}
+
+// run moves a deployment along until it is finalized or another instance of the shim has adopted it
+func (r *DeploymentRepo) run(namespace, guid string) {
+	ctx := context.Background()
+	ticker := time.NewTicker(deploymentPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// errors are usually caused by concurrent changes, so the step is retried at the next tick. The deployment
+		// timeout still applies, as it is checked before anything that can fail for long.
+		done, err := r.step(ctx, namespace, guid)
+		if err == nil && done {
+			return
+		}
+	}
+}
+
+// step moves a deployment one step further. It returns true when there is nothing left to do.
+func (r *DeploymentRepo) step(ctx context.Context, namespace, guid string) (bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := r.privilegedClient.Get(ctx, types.NamespacedName{Name: guid, Namespace: namespace}, configMap)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	deployment := configMapToDeploymentRecord(*configMap)
+	if deployment.StatusValue == DeploymentStatusValueFinalized || configMap.Annotations[deploymentOwnerAnnotation] != r.owner {
+		return true, nil
+	}
+
+	now := time.Now()
+	err = r.renewLease(ctx, configMap, now)
+	if err != nil {
+		return false, err
+	}
+
+	rollout, err := r.fetchRollout(ctx, configMap)
+	if err != nil {
+		if _, ok := err.(NotFoundError); ok {
+			if rollout.app.cfApp == nil {
+				// the app has been deleted, and the deployment app is deleted with it
+				return r.finalize(ctx, rollout, DeploymentStatusReasonCanceled)
+			}
+			// the deployment app has been deleted, so the app takes all the instances back
+			return r.finish(ctx, rollout, DeploymentStatusReasonCanceled)
+		}
+		return false, err
+	}
+
+	phase := configMap.Data[deploymentPhaseKey]
+	switch {
+	case deployment.StatusReason == DeploymentStatusReasonCanceling && timedOut(deployment.LastStatusChange, r.timeout, now):
+		return r.finish(ctx, rollout, DeploymentStatusReasonCanceled)
+	case phase == deploymentPhasePromoting && timedOut(configMap.Data[deploymentPromotedAtKey], r.timeout, now):
+		return r.finish(ctx, rollout, DeploymentStatusReasonDeployed)
+	case deployment.StatusReason == DeploymentStatusReasonDeploying && phase == deploymentPhaseRolling && timedOut(deployment.LastStatusChange, r.timeout, now):
+		return false, patchDeploymentData(ctx, r.privilegedClient, configMap, map[string]string{
+			deploymentStatusReasonKey: DeploymentStatusReasonCanceling,
+		})
+	}
+
+	switch {
+	case deployment.StatusReason == DeploymentStatusReasonCanceling:
+		done, err := r.rollOver(ctx, rollout, rollout.newApp, rollout.app, nil)
+		if err != nil || !done {
+			return false, err
+		}
+		return r.finish(ctx, rollout, DeploymentStatusReasonCanceled)
+
+	case phase == deploymentPhasePromoting:
+		done, err := r.rollOver(ctx, rollout, rollout.newApp, rollout.app, nil)
+		if err != nil || !done {
+			return false, err
+		}
+		return r.finish(ctx, rollout, DeploymentStatusReasonDeployed)
+
+	case deployment.StatusReason == DeploymentStatusReasonPaused:
+		return false, nil
+
+	default:
+		var canaryInstances map[string]int
+		if deployment.Strategy == DeploymentStrategyCanary && configMap.Data[deploymentContinuedKey] != "true" {
+			canaryInstances = map[string]int{processTypeWeb: 1}
+		}
+		done, err := r.rollOver(ctx, rollout, rollout.app, rollout.newApp, canaryInstances)
+		if err != nil || !done {
+			return false, err
+		}
+		if canaryInstances != nil {
+			return false, patchDeploymentData(ctx, r.privilegedClient, configMap, map[string]string{
+				deploymentStatusReasonKey: DeploymentStatusReasonPaused,
+			})
+		}
+		return false, r.promote(ctx, rollout)
+	}
+}
+
+// deploymentRollout is the state of an active deployment of a started app
+type deploymentRollout struct {
+	configMap  *corev1.ConfigMap
+	deployment DeploymentRecord
+	// instances are the instances that each process type runs once the deployment is done
+	instances    map[string]int
+	destinations []deploymentDestination
+	app          rolloutApp
+	newApp       rolloutApp
+}
+
+// rolloutApp is the app or the deployment app of a deployment, with its processes by process type and its route
+// destinations
+type rolloutApp struct {
+	cfApp        *workloadsv1alpha1.CFApp
+	processes    map[string]*workloadsv1alpha1.CFProcess
+	destinations []routeDestination
+}
+
+type routeDestination struct {
+	routeGUID   string
+	destination networkingv1alpha1.Destination
+}
+
+// fetchRollout reads the state of a deployment from its ConfigMap and its apps. It returns a NotFoundError when the
+// app of the deployment has been deleted, along with the part of the state that is stored in the ConfigMap.
+func (r *DeploymentRepo) fetchRollout(ctx context.Context, configMap *corev1.ConfigMap) (*deploymentRollout, error) {
+	rollout := &deploymentRollout{
+		configMap:  configMap,
+		deployment: configMapToDeploymentRecord(*configMap),
+	}
+	err := getDeploymentJSONData(configMap, map[string]interface{}{
+		deploymentInstancesKey:    &rollout.instances,
+		deploymentDestinationsKey: &rollout.destinations,
+	})
+	if err != nil {
+		return rollout, err
+	}
+
+	for _, destination := range rollout.destinations {
+		newDestination := destination.Destination
+		newDestination.GUID = destination.NewDestinationGUID
+		newDestination.AppRef = corev1.LocalObjectReference{Name: rollout.deployment.GUID}
+		rollout.app.destinations = append(rollout.app.destinations, routeDestination{routeGUID: destination.RouteGUID, destination: destination.Destination})
+		rollout.newApp.destinations = append(rollout.newApp.destinations, routeDestination{routeGUID: destination.RouteGUID, destination: newDestination})
+	}
+
+	rollout.app.cfApp, rollout.app.processes, err = r.fetchRolloutApp(ctx, rollout.deployment.SpaceGUID, rollout.deployment.AppGUID)
+	if err != nil {
+		return rollout, err
+	}
+	rollout.newApp.cfApp, rollout.newApp.processes, err = r.fetchRolloutApp(ctx, rollout.deployment.SpaceGUID, rollout.deployment.GUID)
+	return rollout, err
+}
+
+func (r *DeploymentRepo) fetchRolloutApp(ctx context.Context, namespace, appGUID string) (*workloadsv1alpha1.CFApp, map[string]*workloadsv1alpha1.CFProcess, error) {
+	cfApp := &workloadsv1alpha1.CFApp{}
+	err := r.privilegedClient.Get(ctx, types.NamespacedName{Name: appGUID, Namespace: namespace}, cfApp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil, NotFoundError{Err: err}
+		}
+		return nil, nil, fmt.Errorf("error fetching app %q: %w", appGUID, err)
+	}
+
+	processList := &workloadsv1alpha1.CFProcessList{}
+	err = r.privilegedClient.List(ctx, processList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{workloadsv1alpha1.CFAppGUIDLabelKey: appGUID},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing the processes of app %q: %w", appGUID, err)
+	}
+
+	processes := map[string]*workloadsv1alpha1.CFProcess{}
+	for i := range processList.Items {
+		process := &processList.Items[i]
+		processes[process.Spec.ProcessType] = process
+	}
+	return cfApp, processes, nil
+}
+
+// rollOver moves the instances of each process type from the processes of one app of a deployment to those of the
+// other, one instance at a time. A process of to gets another instance once all its instances are ready, and the
+// process of from loses an instance for each ready instance of to. The destinations of to are added to the routes of
+// the app once it has a ready instance, and those of from are removed before its last instance stops. limits caps the
+// instances of to and leaves the instances of from alone, for the canary of a deployment. rollOver returns true once
+// every process of to runs all its instances and from runs none, or to runs its limits.
+func (r *DeploymentRepo) rollOver(ctx context.Context, rollout *deploymentRollout, from, to rolloutApp, limits map[string]int) (bool, error) {
+	done := true
+	for _, processType := range sortedKeys(rollout.instances) {
+		target := rollout.instances[processType]
+		if limits != nil {
+			target = minInt(target, limits[processType])
+		}
+
+		toProcess, ok := to.processes[processType]
+		if !ok {
+			return false, fmt.Errorf("app %q has no %s process", to.cfApp.Name, processType)
+		}
+		ready, err := r.readyInstances(ctx, toProcess)
+		if err != nil {
+			return false, err
+		}
+		if ready < toProcess.Spec.DesiredInstances {
+			done = false
+			continue
+		}
+
+		if ready > 0 {
+			err = r.addDestinations(ctx, rollout.deployment.SpaceGUID, destinationsOfType(to.destinations, processType))
+			if err != nil {
+				return false, err
+			}
+		}
+
+		fromProcess, ok := from.processes[processType]
+		if ok && limits == nil {
+			fromInstances := maxInt(rollout.instances[processType]-toProcess.Spec.DesiredInstances, 0)
+			if fromInstances == 0 {
+				removed, err := r.removeDestinations(ctx, rollout.deployment.SpaceGUID, destinationsOfType(from.destinations, processType))
+				if err != nil {
+					return false, err
+				}
+				if removed {
+					// the instances stop once the routes no longer send them requests
+					done = false
+					continue
+				}
+			}
+			if fromProcess.Spec.DesiredInstances > fromInstances {
+				err = r.scaleProcess(ctx, fromProcess, fromInstances)
+				if err != nil {
+					return false, err
+				}
+			}
+			if fromInstances > 0 {
+				done = false
+			}
+		}
+
+		if toProcess.Spec.DesiredInstances < target {
+			err = r.scaleProcess(ctx, toProcess, toProcess.Spec.DesiredInstances+1)
+			if err != nil {
+				return false, err
+			}
+			done = false
+		}
+	}
+
+	return done, nil
+}
+
+// promote makes the droplet of a deployment the current droplet of its app, once the deployment app runs all the
+// instances. The processes of the app get the commands of the processes of the deployment app, and the app gets the
+// environment variables of a rollback. The app runs no instances at that point, so none of them restart.
+func (r *DeploymentRepo) promote(ctx context.Context, rollout *deploymentRollout) error {
+	app, newApp := rollout.app, rollout.newApp
+	deployment := rollout.deployment
+
+	if newApp.cfApp.Spec.EnvSecretName != "" && newApp.cfApp.Spec.EnvSecretName != app.cfApp.Spec.EnvSecretName {
+		secret := &corev1.Secret{}
+		err := r.privilegedClient.Get(ctx, types.NamespacedName{Name: newApp.cfApp.Spec.EnvSecretName, Namespace: deployment.SpaceGUID}, secret)
+		if err != nil {
+			return fmt.Errorf("error fetching env secret of deployment %q: %w", deployment.GUID, err)
+		}
+		err = restoreEnvironmentVariables(ctx, r.privilegedClient, cfAppToAppRecord(*app.cfApp), convertByteSliceValuesToStrings(secret.Data))
+		if err != nil {
+			return err
+		}
+	}
+
+	commands := map[string]string{}
+	for _, processType := range sortedKeys(rollout.instances) {
+		newProcess := newApp.processes[processType]
+		commands[processType] = newProcess.Spec.Command
+		if _, ok := app.processes[processType]; ok {
+			continue
+		}
+
+		process := &workloadsv1alpha1.CFProcess{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      uuid.NewString(),
+				Namespace: deployment.SpaceGUID,
+				Labels: map[string]string{
+					workloadsv1alpha1.CFAppGUIDLabelKey:     deployment.AppGUID,
+					workloadsv1alpha1.CFProcessTypeLabelKey: processType,
+				},
+			},
+			Spec: newProcess.Spec,
+		}
+		process.Labels[workloadsv1alpha1.CFProcessGUIDLabelKey] = process.Name
+		process.Spec.AppRef = corev1.LocalObjectReference{Name: deployment.AppGUID}
+		process.Spec.DesiredInstances = 0
+		err := r.privilegedClient.Create(ctx, process)
+		if err != nil {
+			return fmt.Errorf("error creating %s process of app %q: %w", processType, deployment.AppGUID, err)
+		}
+	}
+
+	err := patchProcessCommands(ctx, r.privilegedClient, cfAppToAppRecord(*app.cfApp), commands)
+	if err != nil {
+		return err
+	}
+
+	err = patchAppDroplet(ctx, r.privilegedClient, deployment.AppGUID, deployment.SpaceGUID, deployment.DropletGUID, StartedState)
+	if err != nil {
+		return err
+	}
+
+	return patchDeploymentData(ctx, r.privilegedClient, rollout.configMap, map[string]string{
+		deploymentPhaseKey:      deploymentPhasePromoting,
+		deploymentPromotedAtKey: time.Now().UTC().Format(TimestampFormat),
+	})
+}
+
+// finish scales the processes of the app of a deployment to their instances, restores the route destinations of the
+// app, deletes the deployment app and finalizes the deployment with reason. A revision of the app is recorded when it
+// is DEPLOYED.
+func (r *DeploymentRepo) finish(ctx context.Context, rollout *deploymentRollout, reason string) (bool, error) {
+	deployment := rollout.deployment
+
+	for _, processType := range sortedKeys(rollout.instances) {
+		process, ok := rollout.app.processes[processType]
+		if !ok || process.Spec.DesiredInstances == rollout.instances[processType] {
+			continue
+		}
+		err := r.scaleProcess(ctx, process, rollout.instances[processType])
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err := r.addDestinations(ctx, deployment.SpaceGUID, rollout.app.destinations)
+	if err != nil {
+		return false, err
+	}
+
+	if reason == DeploymentStatusReasonDeployed {
+		_, err = r.revisionRepo.RecordRevision(ctx, r.privilegedClient, RevisionCreateMessage{
+			AppGUID:     deployment.AppGUID,
+			SpaceGUID:   deployment.SpaceGUID,
+			Description: rollbackDescription(rollout.configMap.Data[deploymentRollbackVersionKey]),
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return r.finalize(ctx, rollout, reason)
+}
+
+// finalize removes the deployment app of a deployment and its route destinations, and ends the deployment with
+// reason
+func (r *DeploymentRepo) finalize(ctx context.Context, rollout *deploymentRollout, reason string) (bool, error) {
+	deployment := rollout.deployment
+
+	_, err := r.removeDestinations(ctx, deployment.SpaceGUID, rollout.newApp.destinations)
+	if err != nil {
+		return false, err
+	}
+
+	err = deleteDeploymentApp(ctx, r.privilegedClient, deployment.SpaceGUID, deployment.GUID)
+	if err != nil {
+		return false, err
+	}
+
+	err = patchDeploymentData(ctx, r.privilegedClient, rollout.configMap, map[string]string{
+		deploymentStatusValueKey:  DeploymentStatusValueFinalized,
+		deploymentStatusReasonKey: reason,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// renewLease renews the lease of this instance on a deployment when a third of its duration has passed
+func (r *DeploymentRepo) renewLease(ctx context.Context, configMap *corev1.ConfigMap, now time.Time) error {
+	renewedAt, err := time.Parse(time.RFC3339Nano, configMap.Data[deploymentLeaseRenewedAtKey])
+	if err == nil && now.Sub(renewedAt) < r.leaseDuration/3 {
+		return nil
+	}
+
+	baseConfigMap := configMap.DeepCopy()
+	configMap.Data[deploymentLeaseRenewedAtKey] = now.UTC().Format(time.RFC3339Nano)
+	err = r.privilegedClient.Patch(ctx, configMap, client.MergeFromWithOptions(baseConfigMap, client.MergeFromWithOptimisticLock{}))
+	if err != nil {
+		return fmt.Errorf("error renewing lease of deployment %q: %w", configMap.Name, err)
+	}
+	return nil
+}
+
+// readyInstances returns the number of instances of a process whose pod is ready
+func (r *DeploymentRepo) readyInstances(ctx context.Context, process *workloadsv1alpha1.CFProcess) (int, error) {
+	podList := &corev1.PodList{}
+	err := r.privilegedClient.List(ctx, podList, client.InNamespace(process.Namespace), client.MatchingLabels{ProcessGUIDPodLabel: process.Name})
+	if err != nil {
+		return 0, fmt.Errorf("error listing pods of process %q: %w", process.Name, err)
+	}
+
+	now := time.Now()
+	ready := 0
+	for _, pod := range podList.Items {
+		if state, _, _, _ := podState(pod, now); state == ProcessInstanceRunning {
+			ready++
+		}
+	}
+	return ready, nil
+}
+
+func (r *DeploymentRepo) scaleProcess(ctx context.Context, process *workloadsv1alpha1.CFProcess, instances int) error {
+	baseProcess := process.DeepCopy()
+	process.Spec.DesiredInstances = instances
+	err := r.privilegedClient.Patch(ctx, process, client.MergeFromWithOptions(baseProcess, client.MergeFromWithOptimisticLock{}))
+	if err != nil {
+		return fmt.Errorf("error scaling process %q: %w", process.Name, err)
+	}
+	return nil
+}
+
+// addDestinations adds destinations to their routes, unless they are there already. Routes that have been deleted are
+// skipped.
+func (r *DeploymentRepo) addDestinations(ctx context.Context, namespace string, destinations []routeDestination) error {
+	return r.patchRouteDestinations(ctx, namespace, destinations, func(route *networkingv1alpha1.CFRoute, destination networkingv1alpha1.Destination) bool {
+		for _, existing := range route.Spec.Destinations {
+			if existing.GUID == destination.GUID {
+				return false
+			}
+		}
+		route.Spec.Destinations = append(route.Spec.Destinations, destination)
+		return true
+	})
+}
+
+// removeDestinations removes destinations from their routes. It reports whether any of them was still there.
+func (r *DeploymentRepo) removeDestinations(ctx context.Context, namespace string, destinations []routeDestination) (bool, error) {
+	removed := false
+	err := r.patchRouteDestinations(ctx, namespace, destinations, func(route *networkingv1alpha1.CFRoute, destination networkingv1alpha1.Destination) bool {
+		for i, existing := range route.Spec.Destinations {
+			if existing.GUID == destination.GUID {
+				route.Spec.Destinations = append(route.Spec.Destinations[:i:i], route.Spec.Destinations[i+1:]...)
+				removed = true
+				return true
+			}
+		}
+		return false
+	})
+	return removed, err
+}
+
+// patchRouteDestinations applies change to the routes of destinations, and patches the routes that it changes. The
+// patches fail when a route has changed since it was read, so that concurrent changes to its destinations are not lost.
+func (r *DeploymentRepo) patchRouteDestinations(
+	ctx context.Context,
+	namespace string,
+	destinations []routeDestination,
+	change func(route *networkingv1alpha1.CFRoute, destination networkingv1alpha1.Destination) bool,
+) error {
+	routeGUIDs := []string{}
+	destinationsByRoute := map[string][]networkingv1alpha1.Destination{}
+	for _, destination := range destinations {
+		if _, ok := destinationsByRoute[destination.routeGUID]; !ok {
+			routeGUIDs = append(routeGUIDs, destination.routeGUID)
+		}
+		destinationsByRoute[destination.routeGUID] = append(destinationsByRoute[destination.routeGUID], destination.destination)
+	}
+
+	for _, routeGUID := range routeGUIDs {
+		route := &networkingv1alpha1.CFRoute{}
+		err := r.privilegedClient.Get(ctx, types.NamespacedName{Name: routeGUID, Namespace: namespace}, route)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("error fetching route %q: %w", routeGUID, err)
+		}
+
+		baseRoute := route.DeepCopy()
+		changed := false
+		for _, destination := range destinationsByRoute[routeGUID] {
+			if change(route, destination) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		err = r.privilegedClient.Patch(ctx, route, client.MergeFromWithOptions(baseRoute, client.MergeFromWithOptimisticLock{}))
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error changing the destinations of route %q: %w", routeGUID, err)
+		}
+	}
+
+	return nil
+}
+
+func destinationsOfType(destinations []routeDestination, processType string) []routeDestination {
+	result := []routeDestination{}
+	for _, destination := range destinations {
+		if destination.destination.ProcessType == processType {
+			result = append(result, destination)
+		}
+	}
+	return result
+}
+
+// deleteDeploymentApp deletes the deployment app of a deployment, its processes and the env secret of a rollback. They
+// are owned by the app of the deployment, so they are not deleted with the deployment app.
+func deleteDeploymentApp(ctx context.Context, c client.Client, namespace, deploymentGUID string) error {
+	processList := &workloadsv1alpha1.CFProcessList{}
+	err := c.List(ctx, processList, client.InNamespace(namespace), client.MatchingLabels{DeploymentGUIDLabel: deploymentGUID})
+	if err != nil {
+		return fmt.Errorf("error listing the processes of deployment %q: %w", deploymentGUID, err)
+	}
+	for i := range processList.Items {
+		err = c.Delete(ctx, &processList.Items[i])
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting process %q: %w", processList.Items[i].Name, err)
+		}
+	}
+
+	err = c.Delete(ctx, &workloadsv1alpha1.CFApp{ObjectMeta: metav1.ObjectMeta{Name: deploymentGUID, Namespace: namespace}})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting app of deployment %q: %w", deploymentGUID, err)
+	}
+
+	envSecret := appEnvVarsRecordToSecret(AppEnvVarsRecord{AppGUID: deploymentGUID, SpaceGUID: namespace})
+	err = c.Delete(ctx, &envSecret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting env secret of deployment %q: %w", deploymentGUID, err)
+	}
+	return nil
+}
+
+// timedOut reports whether more than timeout has passed since timestamp. Timestamps that can't be parsed never time
+// out.
+func timedOut(timestamp string, timeout time.Duration, now time.Time) bool {
+	t, err := time.Parse(TimestampFormat, timestamp)
+	return err == nil && now.Sub(t) > timeout
+}
+
+// setDeploymentJSONData stores values in the data of the ConfigMap of a deployment as JSON
+func setDeploymentJSONData(configMap *corev1.ConfigMap, values map[string]interface{}) error {
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("error encoding %s of deployment %q: %w", key, configMap.Name, err)
+		}
+		configMap.Data[key] = string(data)
+	}
+	return nil
+}
+
+// getDeploymentJSONData reads values stored with setDeploymentJSONData. Missing values are left alone.
+func getDeploymentJSONData(configMap *corev1.ConfigMap, values map[string]interface{}) error {
+	for key, value := range values {
+		data, ok := configMap.Data[key]
+		if !ok {
+			continue
+		}
+		err := json.Unmarshal([]byte(data), value)
+		if err != nil {
+			return fmt.Errorf("error decoding %s of deployment %q: %w", key, configMap.Name, err)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	switch typed := m.(type) {
+	case map[string]int:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	case map[string]string:
+		for key := range typed {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// revisionCommands returns the commands of the process types of droplet, replaced by the commands of revision
+func revisionCommands(droplet DropletRecord, revision RevisionRecord) map[string]string {
+	commands := map[string]string{}
+	for processType, command := range droplet.ProcessTypes {
+		commands[processType] = command
+	}
+	for processType, command := range revision.Processes {
+		commands[processType] = command
+	}
+	return commands
+}
+
+// rollbackDescription describes the revision that a deployment rolls back to. It is empty when the deployment is not
+// a rollback.
+func rollbackDescription(version string) string {
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("Rolled back to revision %s.", version)
+}
+
+// restoreEnvironmentVariables replaces the environment variables of an app with envVars
+func restoreEnvironmentVariables(ctx context.Context, c client.Client, app AppRecord, envVars map[string]string) error {
+	if app.EnvSecretName == "" {
+		if len(envVars) == 0 {
+			return nil
+		}
+
+		secret := appEnvVarsRecordToSecret(AppEnvVarsRecord{
+			AppGUID:              app.GUID,
+			SpaceGUID:            app.SpaceGUID,
+			EnvironmentVariables: envVars,
+		})
+		err := c.Create(ctx, &secret)
+		if err != nil {
+			return fmt.Errorf("error creating app env secret: %w", err)
+		}
+
+		baseCFApp := &workloadsv1alpha1.CFApp{ObjectMeta: metav1.ObjectMeta{Name: app.GUID, Namespace: app.SpaceGUID}}
+		cfApp := baseCFApp.DeepCopy()
+		cfApp.Spec.EnvSecretName = secret.Name
+		err = c.Patch(ctx, cfApp, client.MergeFrom(baseCFApp))
+		if err != nil {
+			return fmt.Errorf("error setting env secret of app: %w", err)
+		}
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: app.EnvSecretName, Namespace: app.SpaceGUID}, secret)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return NotFoundError{Err: err}
+		}
+		return err
+	}
+
+	baseSecret := secret.DeepCopy()
+	secret.Data = map[string][]byte{}
+	for key, value := range envVars {
+		secret.Data[key] = []byte(value)
+	}
+	err = c.Patch(ctx, secret, client.MergeFrom(baseSecret))
+	if err != nil {
+		return fmt.Errorf("error restoring environment variables of app %q: %w", app.GUID, err)
+	}
+	return nil
+}
+
+// patchProcessCommands changes the commands of the processes of an app to commands, which are keyed by process type
+func patchProcessCommands(ctx context.Context, c client.Client, app AppRecord, commands map[string]string) error {
+	processList := &workloadsv1alpha1.CFProcessList{}
+	err := c.List(ctx, processList, listOptionsForApp(c, app.SpaceGUID, app.GUID)...)
+	if err != nil {
+		return fmt.Errorf("error listing the processes of app %q: %w", app.GUID, err)
+	}
+
+	for _, process := range filterProcessesByAppGUID(processList.Items, app.GUID) {
+		command, ok := commands[process.Spec.ProcessType]
+		if !ok || process.Spec.Command == command {
+			continue
+		}
+		baseProcess := process.DeepCopy()
+		process.Spec.Command = command
+		err = c.Patch(ctx, &process, client.MergeFrom(baseProcess))
+		if err != nil {
+			return fmt.Errorf("error changing the command of process %q: %w", process.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// patchAppDroplet makes droplet the current droplet of an app and changes its desired state, unless desiredState is
+// empty
+func patchAppDroplet(ctx context.Context, c client.Client, appGUID, spaceGUID, dropletGUID string, desiredState DesiredState) error {
+	baseCFApp := &workloadsv1alpha1.CFApp{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appGUID,
+			Namespace: spaceGUID,
+		},
+	}
+	cfApp := baseCFApp.DeepCopy()
+	cfApp.Spec.CurrentDropletRef = corev1.LocalObjectReference{Name: dropletGUID}
+	cfApp.Spec.DesiredState = workloadsv1alpha1.DesiredState(desiredState)
+
+	err := c.Patch(ctx, cfApp, client.MergeFrom(baseCFApp))
+	if err != nil {
+		return fmt.Errorf("error setting the current droplet of app %q: %w", appGUID, err)
+	}
+	return nil
+}
+
+// patchDeploymentData changes the data of a deployment, and its last status change when its status reason changes.
+// The patch fails with a ConflictError when the deployment has changed since it was read, so that concurrent changes
+// are not lost.
+func patchDeploymentData(ctx context.Context, c client.Client, configMap *corev1.ConfigMap, changes map[string]string) error {
+	baseConfigMap := configMap.DeepCopy()
+	for key, value := range changes {
+		configMap.Data[key] = value
+	}
+	if _, ok := changes[deploymentStatusReasonKey]; ok {
+		configMap.Data[deploymentLastStatusChangeKey] = time.Now().UTC().Format(TimestampFormat)
+	}
+
+	err := c.Patch(ctx, configMap, client.MergeFromWithOptions(baseConfigMap, client.MergeFromWithOptimisticLock{}))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return NotFoundError{Err: err}
+		}
+		if k8serrors.IsConflict(err) {
+			return ConflictError{Err: err}
+		}
+		return fmt.Errorf("error changing deployment %q: %w", configMap.Name, err)
+	}
+	return nil
+}
+
+func sortDeployments(deployments []DeploymentRecord) {
+	sort.SliceStable(deployments, func(i, j int) bool {
+		if deployments[i].CreatedAt != deployments[j].CreatedAt {
+			return deployments[i].CreatedAt < deployments[j].CreatedAt
+		}
+		return deployments[i].GUID < deployments[j].GUID
+	})
+}
+
+func deploymentToConfigMap(deployment DeploymentRecord) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.GUID,
+			Namespace: deployment.SpaceGUID,
+			Labels: map[string]string{
+				DeploymentLabel:                     "true",
+				workloadsv1alpha1.CFAppGUIDLabelKey: deployment.AppGUID,
+			},
+		},
+		Data: map[string]string{
+			deploymentStrategyKey:         deployment.Strategy,
+			deploymentDropletKey:          deployment.DropletGUID,
+			deploymentPreviousDropletKey:  deployment.PreviousDropletGUID,
+			deploymentStatusValueKey:      deployment.StatusValue,
+			deploymentStatusReasonKey:     deployment.StatusReason,
+			deploymentLastStatusChangeKey: deployment.LastStatusChange,
+		},
+	}
+}
+
+func configMapToDeploymentRecord(configMap corev1.ConfigMap) DeploymentRecord {
+	updatedAt, _ := getTimeLastUpdatedTimestamp(&configMap.ObjectMeta)
+
+	// deployments of stopped apps have no deployment app, and so no new processes
+	newProcesses := []DeploymentProcessRecord{}
+	_ = getDeploymentJSONData(&configMap, map[string]interface{}{deploymentNewProcessesKey: &newProcesses})
+
+	return DeploymentRecord{
+		GUID:                configMap.Name,
+		AppGUID:             configMap.Labels[workloadsv1alpha1.CFAppGUIDLabelKey],
+		SpaceGUID:           configMap.Namespace,
+		Strategy:            configMap.Data[deploymentStrategyKey],
+		DropletGUID:         configMap.Data[deploymentDropletKey],
+		PreviousDropletGUID: configMap.Data[deploymentPreviousDropletKey],
+		StatusValue:         configMap.Data[deploymentStatusValueKey],
+		StatusReason:        configMap.Data[deploymentStatusReasonKey],
+		LastStatusChange:    configMap.Data[deploymentLastStatusChangeKey],
+		NewProcesses:        newProcesses,
+		CreatedAt:           formatTimestamp(configMap.CreationTimestamp),
+		UpdatedAt:           updatedAt,
+	}
+}
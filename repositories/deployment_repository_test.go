@@ -0,0 +1,430 @@
+package repositories_test
+
+import (
+	"context"
+	"time"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	networkingv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/networking/v1alpha1"
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("DeploymentRepo", func() {
+	const (
+		timeout  = 30 * time.Second
+		interval = 250 * time.Millisecond
+	)
+
+	var (
+		testCtx        context.Context
+		deploymentRepo *DeploymentRepo
+		namespace      *corev1.Namespace
+		cfApp          *workloadsv1alpha1.CFApp
+		webProcess     *workloadsv1alpha1.CFProcess
+		cfRoute        *networkingv1alpha1.CFRoute
+		app            AppRecord
+		droplet        DropletRecord
+	)
+
+	fetchApp := func() *workloadsv1alpha1.CFApp {
+		app := &workloadsv1alpha1.CFApp{}
+		Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: cfApp.Name, Namespace: namespace.Name}, app)).To(Succeed())
+		return app
+	}
+
+	fetchConfigMap := func(guid string) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: guid, Namespace: namespace.Name}, configMap)).To(Succeed())
+		return configMap
+	}
+
+	fetchRoute := func() *networkingv1alpha1.CFRoute {
+		route := &networkingv1alpha1.CFRoute{}
+		Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: cfRoute.Name, Namespace: namespace.Name}, route)).To(Succeed())
+		return route
+	}
+
+	// desiredInstances returns the desired instances of the processes of an app, keyed by process type
+	desiredInstances := func(appGUID string) map[string]int {
+		processList := &workloadsv1alpha1.CFProcessList{}
+		Expect(k8sClient.List(testCtx, processList, client.InNamespace(namespace.Name), client.MatchingLabels{workloadsv1alpha1.CFAppGUIDLabelKey: appGUID})).To(Succeed())
+		instances := map[string]int{}
+		for _, process := range processList.Items {
+			instances[process.Spec.ProcessType] = process.Spec.DesiredInstances
+		}
+		return instances
+	}
+
+	// runInstances does what the workload controllers and the pods of the processes would do: each process gets a ready
+	// pod for each of its desired instances
+	runInstances := func() {
+		processList := &workloadsv1alpha1.CFProcessList{}
+		Expect(k8sClient.List(testCtx, processList, client.InNamespace(namespace.Name))).To(Succeed())
+
+		for _, process := range processList.Items {
+			podList := &corev1.PodList{}
+			Expect(k8sClient.List(testCtx, podList, client.InNamespace(namespace.Name), client.MatchingLabels{ProcessGUIDPodLabel: process.Name})).To(Succeed())
+
+			for i := len(podList.Items); i < process.Spec.DesiredInstances; i++ {
+				Expect(createReadyPod(k8sClient, testCtx, process.Name, namespace.Name)).To(Succeed())
+			}
+			for i := process.Spec.DesiredInstances; i < len(podList.Items); i++ {
+				Expect(k8sClient.Delete(testCtx, &podList.Items[i])).To(Succeed())
+			}
+		}
+	}
+
+	// statusReason runs the instances of the processes before it returns the status reason of a deployment, so that
+	// the deployment moves along while it is polled
+	statusReason := func(guid string) func() string {
+		return func() string {
+			runInstances()
+			deployment, err := deploymentRepo.FetchDeployment(testCtx, k8sClient, guid)
+			Expect(err).NotTo(HaveOccurred())
+			return deployment.StatusReason
+		}
+	}
+
+	createDeployment := func(strategy string) DeploymentRecord {
+		deployment, err := deploymentRepo.CreateDeployment(testCtx, k8sClient, DeploymentCreateMessage{
+			App:      app,
+			Droplet:  droplet,
+			Strategy: strategy,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return deployment
+	}
+
+	expectDeploymentAppDeleted := func(deployment DeploymentRecord) {
+		err := k8sClient.Get(testCtx, types.NamespacedName{Name: deployment.GUID, Namespace: namespace.Name}, &workloadsv1alpha1.CFApp{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		Expect(desiredInstances(deployment.GUID)).To(BeEmpty())
+	}
+
+	BeforeEach(func() {
+		testCtx = context.Background()
+		deploymentRepo = NewDeploymentRepo(NewGUIDNamespaceCache(), k8sClient, NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient), time.Minute, time.Minute)
+
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+		cfApp = initializeAppCR("my-app", generateGUID(), namespace.Name)
+		cfApp.Spec.DesiredState = workloadsv1alpha1.StartedState
+		cfApp.Spec.CurrentDropletRef = corev1.LocalObjectReference{Name: "old-droplet-guid"}
+		Expect(k8sClient.Create(testCtx, cfApp)).To(Succeed())
+
+		webProcess = initializeProcessCR(generateGUID(), namespace.Name, cfApp.Name)
+		webProcess.Spec.DesiredInstances = 2
+		Expect(k8sClient.Create(testCtx, webProcess)).To(Succeed())
+
+		route := initializeRouteCR("my-app", "", generateGUID(), generateGUID(), namespace.Name)
+		cfRoute = &route
+		cfRoute.Spec.Destinations = []networkingv1alpha1.Destination{{
+			GUID:        "destination-guid",
+			Port:        8080,
+			AppRef:      corev1.LocalObjectReference{Name: cfApp.Name},
+			ProcessType: "web",
+		}}
+		Expect(k8sClient.Create(testCtx, cfRoute)).To(Succeed())
+		runInstances()
+
+		app = AppRecord{GUID: cfApp.Name, SpaceGUID: namespace.Name, DropletGUID: "old-droplet-guid", State: StartedState}
+		droplet = DropletRecord{GUID: "new-droplet-guid", AppGUID: cfApp.Name, ProcessTypes: map[string]string{"web": "bundle exec rackup"}}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+	})
+
+	Describe("CreateDeployment", func() {
+		It("runs the droplet in a deployment app beside the app, without any instances yet", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+			Expect(deployment.StatusValue).To(Equal(DeploymentStatusValueActive))
+			Expect(deployment.StatusReason).To(Equal(DeploymentStatusReasonDeploying))
+			Expect(deployment.PreviousDropletGUID).To(Equal("old-droplet-guid"))
+			Expect(deployment.NewProcesses).To(HaveLen(1))
+			Expect(deployment.NewProcesses[0].Type).To(Equal("web"))
+
+			newApp := &workloadsv1alpha1.CFApp{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: deployment.GUID, Namespace: namespace.Name}, newApp)).To(Succeed())
+			Expect(newApp.Labels).To(HaveKeyWithValue(DeploymentGUIDLabel, deployment.GUID))
+			Expect(newApp.Spec.CurrentDropletRef.Name).To(Equal("new-droplet-guid"))
+			Expect(newApp.Spec.DesiredState).To(Equal(workloadsv1alpha1.StartedState))
+
+			newProcess := &workloadsv1alpha1.CFProcess{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: deployment.NewProcesses[0].GUID, Namespace: namespace.Name}, newProcess)).To(Succeed())
+			Expect(newProcess.Spec.AppRef.Name).To(Equal(deployment.GUID))
+			Expect(newProcess.Spec.Command).To(Equal("bundle exec rackup"))
+			Expect(newProcess.Spec.DesiredInstances).To(Equal(0))
+
+			Expect(fetchApp().Spec.CurrentDropletRef.Name).To(Equal("old-droplet-guid"))
+			Expect(desiredInstances(cfApp.Name)).To(Equal(map[string]int{"web": 2}))
+		})
+
+		It("hides the deployment app from the apps of the space", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+
+			_, err := NewAppRepo(NewGUIDNamespaceCache(), time.Minute).FetchApp(testCtx, k8sClient, deployment.GUID)
+			Expect(err).To(MatchError(NotFoundError{}))
+		})
+
+		It("makes the app the owner of the deployment, so it is deleted with the app", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+
+			app := fetchApp()
+			Expect(fetchConfigMap(deployment.GUID).OwnerReferences).To(Equal([]metav1.OwnerReference{{
+				APIVersion: APIVersion,
+				Kind:       Kind,
+				Name:       app.Name,
+				UID:        app.UID,
+			}}))
+		})
+
+		It("moves the instances to the new droplet without stopping the app, and finishes once they run it", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+
+			Eventually(func() string {
+				reason := statusReason(deployment.GUID)()
+				instances := desiredInstances(cfApp.Name)["web"] + desiredInstances(deployment.GUID)["web"]
+				Expect(instances).To(BeNumerically(">=", 2), "the instances of the app don't drop while it is deployed")
+				Expect(fetchRoute().Spec.Destinations).NotTo(BeEmpty(), "the route always has a destination")
+				return reason
+			}, timeout, interval).Should(Equal(DeploymentStatusReasonDeployed))
+
+			Expect(fetchApp().Spec.CurrentDropletRef.Name).To(Equal("new-droplet-guid"))
+			Expect(desiredInstances(cfApp.Name)).To(Equal(map[string]int{"web": 2}))
+			Expect(fetchRoute().Spec.Destinations).To(Equal(cfRoute.Spec.Destinations))
+			expectDeploymentAppDeleted(deployment)
+
+			revisions, err := NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient).FetchRevisionsForApp(testCtx, k8sClient, cfApp.Name, namespace.Name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(HaveLen(1))
+			Expect(revisions[0].DropletGUID).To(Equal("new-droplet-guid"))
+		})
+
+		It("returns an ActiveDeploymentError while the app is being deployed", func() {
+			createDeployment(DeploymentStrategyRolling)
+
+			_, err := deploymentRepo.CreateDeployment(testCtx, k8sClient, DeploymentCreateMessage{App: app, Droplet: droplet, Strategy: DeploymentStrategyRolling})
+			Expect(err).To(MatchError(ActiveDeploymentError{}))
+		})
+
+		When("the strategy is canary", func() {
+			It("pauses once an instance runs the new droplet, with the instances of the app still running", func() {
+				deployment := createDeployment(DeploymentStrategyCanary)
+
+				Eventually(statusReason(deployment.GUID), timeout, interval).Should(Equal(DeploymentStatusReasonPaused))
+				Expect(desiredInstances(cfApp.Name)).To(Equal(map[string]int{"web": 2}))
+				Expect(desiredInstances(deployment.GUID)).To(Equal(map[string]int{"web": 1}))
+				Expect(fetchRoute().Spec.Destinations).To(HaveLen(2))
+				Consistently(statusReason(deployment.GUID), 2*time.Second, interval).Should(Equal(DeploymentStatusReasonPaused))
+			})
+
+			It("deploys the rest of the instances once it is continued", func() {
+				deployment := createDeployment(DeploymentStrategyCanary)
+				Eventually(statusReason(deployment.GUID), timeout, interval).Should(Equal(DeploymentStatusReasonPaused))
+
+				deployment, err := deploymentRepo.FetchDeployment(testCtx, k8sClient, deployment.GUID)
+				Expect(err).NotTo(HaveOccurred())
+				continued, err := deploymentRepo.ContinueDeployment(testCtx, k8sClient, deployment)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(continued.StatusReason).To(Equal(DeploymentStatusReasonDeploying))
+
+				Eventually(statusReason(deployment.GUID), timeout, interval).Should(Equal(DeploymentStatusReasonDeployed))
+				Expect(fetchApp().Spec.CurrentDropletRef.Name).To(Equal("new-droplet-guid"))
+				Expect(desiredInstances(cfApp.Name)).To(Equal(map[string]int{"web": 2}))
+				expectDeploymentAppDeleted(deployment)
+			})
+		})
+
+		When("the new instances don't become ready within the deployment timeout", func() {
+			BeforeEach(func() {
+				deploymentRepo = NewDeploymentRepo(NewGUIDNamespaceCache(), k8sClient, NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient), time.Minute, 2*time.Second)
+			})
+
+			It("cancels the deployment, leaving the app running its previous droplet", func() {
+				deployment := createDeployment(DeploymentStrategyRolling)
+
+				Eventually(func() string {
+					deployment, err := deploymentRepo.FetchDeployment(testCtx, k8sClient, deployment.GUID)
+					Expect(err).NotTo(HaveOccurred())
+					return deployment.StatusReason
+				}, timeout, interval).Should(Equal(DeploymentStatusReasonCanceled))
+
+				Expect(fetchApp().Spec.CurrentDropletRef.Name).To(Equal("old-droplet-guid"))
+				Expect(desiredInstances(cfApp.Name)).To(Equal(map[string]int{"web": 2}))
+				Expect(fetchRoute().Spec.Destinations).To(Equal(cfRoute.Spec.Destinations))
+				expectDeploymentAppDeleted(deployment)
+			})
+		})
+
+		When("the app is stopped", func() {
+			BeforeEach(func() {
+				app.State = StoppedState
+			})
+
+			It("starts the app with the droplet at once", func() {
+				deployment := createDeployment(DeploymentStrategyRolling)
+				Expect(deployment.StatusValue).To(Equal(DeploymentStatusValueFinalized))
+				Expect(deployment.StatusReason).To(Equal(DeploymentStatusReasonDeployed))
+				Expect(deployment.NewProcesses).To(BeEmpty())
+
+				Expect(fetchApp().Spec.CurrentDropletRef.Name).To(Equal("new-droplet-guid"))
+				Expect(fetchApp().Spec.DesiredState).To(Equal(workloadsv1alpha1.StartedState))
+			})
+
+			It("records a revision of the app", func() {
+				createDeployment(DeploymentStrategyRolling)
+
+				revisions, err := NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient).FetchRevisionsForApp(testCtx, k8sClient, cfApp.Name, namespace.Name)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(1))
+				Expect(revisions[0].DropletGUID).To(Equal("new-droplet-guid"))
+			})
+
+			It("rolls the app back to a revision", func() {
+				deployment, err := deploymentRepo.CreateDeployment(testCtx, k8sClient, DeploymentCreateMessage{
+					App:      app,
+					Droplet:  droplet,
+					Strategy: DeploymentStrategyRolling,
+					Revision: &RevisionRecord{
+						Version:              3,
+						DropletGUID:          "new-droplet-guid",
+						EnvironmentVariables: map[string]string{"RAILS_ENV": "production"},
+						Processes:            map[string]string{"web": "bundle exec puma"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment.StatusReason).To(Equal(DeploymentStatusReasonDeployed))
+
+				process := &workloadsv1alpha1.CFProcess{}
+				Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: webProcess.Name, Namespace: namespace.Name}, process)).To(Succeed())
+				Expect(process.Spec.Command).To(Equal("bundle exec puma"))
+				revisions, err := NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient).FetchRevisionsForApp(testCtx, k8sClient, cfApp.Name, namespace.Name)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(revisions).To(HaveLen(1))
+				Expect(revisions[0].Description).To(Equal("Rolled back to revision 3."))
+				Expect(revisions[0].EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "production"}))
+			})
+		})
+	})
+
+	Describe("CancelDeployment", func() {
+		It("moves the instances back to the app, which still runs its previous droplet", func() {
+			deployment := createDeployment(DeploymentStrategyCanary)
+			Eventually(statusReason(deployment.GUID), timeout, interval).Should(Equal(DeploymentStatusReasonPaused))
+
+			deployment, err := deploymentRepo.FetchDeployment(testCtx, k8sClient, deployment.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			canceling, err := deploymentRepo.CancelDeployment(testCtx, k8sClient, deployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(canceling.StatusReason).To(Equal(DeploymentStatusReasonCanceling))
+
+			Eventually(statusReason(deployment.GUID), timeout, interval).Should(Equal(DeploymentStatusReasonCanceled))
+			Expect(fetchApp().Spec.CurrentDropletRef.Name).To(Equal("old-droplet-guid"))
+			Expect(desiredInstances(cfApp.Name)).To(Equal(map[string]int{"web": 2}))
+			Expect(fetchRoute().Spec.Destinations).To(Equal(cfRoute.Spec.Destinations))
+			expectDeploymentAppDeleted(deployment)
+		})
+
+		It("cannot cancel a deployment that is canceling", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+			_, err := deploymentRepo.CancelDeployment(testCtx, k8sClient, deployment)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = deploymentRepo.CancelDeployment(testCtx, k8sClient, deployment)
+			Expect(err).To(MatchError(DeploymentStatusError{
+				Action: "cancel",
+				Value:  DeploymentStatusValueActive,
+				Reason: DeploymentStatusReasonCanceling,
+			}))
+		})
+
+		It("cannot cancel a deployment once its app runs the new droplet", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+
+			configMap := fetchConfigMap(deployment.GUID)
+			configMap.Annotations["cloudfoundry.org/deployment-owner"] = "other-instance"
+			configMap.Data["phase"] = "promoting"
+			Expect(k8sClient.Update(testCtx, configMap)).To(Succeed())
+
+			_, err := deploymentRepo.CancelDeployment(testCtx, k8sClient, deployment)
+			Expect(err).To(MatchError(DeploymentPromotingError{}))
+		})
+	})
+
+	Describe("ContinueDeployment", func() {
+		It("cannot continue a deployment that is not paused", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+
+			_, err := deploymentRepo.ContinueDeployment(testCtx, k8sClient, deployment)
+			Expect(err).To(MatchError(DeploymentStatusError{
+				Action: "continue",
+				Value:  DeploymentStatusValueActive,
+				Reason: DeploymentStatusReasonDeploying,
+			}))
+		})
+	})
+
+	Describe("ResumeDeployments", func() {
+		var (
+			otherRepo  *DeploymentRepo
+			deployment DeploymentRecord
+		)
+
+		BeforeEach(func() {
+			otherRepo = NewDeploymentRepo(NewGUIDNamespaceCache(), k8sClient, NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient), time.Minute, time.Minute)
+			deployment = createDeployment(DeploymentStrategyRolling)
+		})
+
+		It("leaves deployments whose lease is held by another instance alone", func() {
+			owner := fetchConfigMap(deployment.GUID).Annotations["cloudfoundry.org/deployment-owner"]
+			Expect(owner).NotTo(BeEmpty())
+
+			Expect(otherRepo.ResumeDeployments(testCtx)).To(Succeed())
+			Expect(fetchConfigMap(deployment.GUID).Annotations).To(HaveKeyWithValue("cloudfoundry.org/deployment-owner", owner))
+		})
+
+		When("the lease on a deployment has expired", func() {
+			BeforeEach(func() {
+				configMap := fetchConfigMap(deployment.GUID)
+				configMap.Annotations["cloudfoundry.org/deployment-owner"] = "stopped-instance"
+				configMap.Data["lease_renewed_at"] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+				Expect(k8sClient.Update(testCtx, configMap)).To(Succeed())
+			})
+
+			It("adopts the deployment and moves it along", func() {
+				Expect(otherRepo.ResumeDeployments(testCtx)).To(Succeed())
+				Expect(fetchConfigMap(deployment.GUID).Annotations["cloudfoundry.org/deployment-owner"]).NotTo(Equal("stopped-instance"))
+
+				Eventually(statusReason(deployment.GUID), timeout, interval).Should(Equal(DeploymentStatusReasonDeployed))
+			})
+		})
+	})
+
+	Describe("FetchDeploymentList", func() {
+		It("filters the deployments by app and status", func() {
+			deployment := createDeployment(DeploymentStrategyRolling)
+
+			deployments, err := deploymentRepo.FetchDeploymentList(testCtx, k8sClient, DeploymentListMessage{AppGUIDs: []string{cfApp.Name}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments).To(HaveLen(1))
+			Expect(deployments[0].GUID).To(Equal(deployment.GUID))
+
+			deployments, err = deploymentRepo.FetchDeploymentList(testCtx, k8sClient, DeploymentListMessage{
+				AppGUIDs:     []string{cfApp.Name},
+				StatusValues: []string{DeploymentStatusValueFinalized},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployments).To(BeEmpty())
+		})
+	})
+})
@@ -41,6 +41,24 @@ func (f *DomainRepo) FetchDomain(ctx context.Context, client client.Client, doma
 	return f.cfDomainToDomainRecord(domain), nil
 }
 
+// FetchDomainByName returns the domain with the given name, such as "apps.example.org", or a NotFoundError when there
+// is none
+func (f *DomainRepo) FetchDomainByName(ctx context.Context, client client.Client, name string) (DomainRecord, error) {
+	domainList := &networkingv1alpha1.CFDomainList{}
+	err := client.List(ctx, domainList)
+	if err != nil {
+		return DomainRecord{}, err
+	}
+
+	for i, domain := range domainList.Items {
+		if domain.Spec.Name == name {
+			return f.cfDomainToDomainRecord(&domainList.Items[i]), nil
+		}
+	}
+
+	return DomainRecord{}, NotFoundError{}
+}
+
 func (f *DomainRepo) cfDomainToDomainRecord(cfDomain *networkingv1alpha1.CFDomain) DomainRecord {
 	return DomainRecord{
 		Name:      cfDomain.Spec.Name,
@@ -51,7 +51,7 @@ var _ = Describe("DomainRepository", func() {
 
 			It("fetches the CFDomain CR we're looking for", func() {
 				domainRepo := DomainRepo{}
-				client, err := BuildCRClient(k8sConfig)
+				client, err := BuildPrivilegedCRClient(k8sConfig, "")
 				Expect(err).ToNot(HaveOccurred())
 
 				domain := DomainRecord{}
@@ -72,7 +72,7 @@ var _ = Describe("DomainRepository", func() {
 		When("no CFDomain exists", func() {
 			It("returns an error", func() {
 				domainRepo := DomainRepo{}
-				client, err := BuildCRClient(k8sConfig)
+				client, err := BuildPrivilegedCRClient(k8sConfig, "")
 				Expect(err).ToNot(HaveOccurred())
 
 				_, err = domainRepo.FetchDomain(testCtx, client, "non-existent-domain-guid")
@@ -80,4 +80,48 @@ var _ = Describe("DomainRepository", func() {
 			})
 		})
 	})
+
+	Describe("FetchDomainByName", func() {
+		var (
+			testCtx  context.Context
+			cfDomain *networkingv1alpha1.CFDomain
+		)
+
+		BeforeEach(func() {
+			testCtx = context.Background()
+			cfDomain = &networkingv1alpha1.CFDomain{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "domain-by-name-id",
+				},
+				Spec: networkingv1alpha1.CFDomainSpec{
+					Name: "apps.example.org",
+				},
+			}
+			Expect(k8sClient.Create(testCtx, cfDomain)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, cfDomain)).To(Succeed())
+		})
+
+		It("fetches the CFDomain with the name", func() {
+			client, err := BuildPrivilegedCRClient(k8sConfig, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			domain, err := new(DomainRepo).FetchDomainByName(testCtx, client, "apps.example.org")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(domain.GUID).To(Equal("domain-by-name-id"))
+			Expect(domain.Name).To(Equal("apps.example.org"))
+		})
+
+		When("no CFDomain has the name", func() {
+			It("returns a NotFoundError", func() {
+				client, err := BuildPrivilegedCRClient(k8sConfig, "")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = new(DomainRepo).FetchDomainByName(testCtx, client, "other.example.org")
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+	})
 })
@@ -25,17 +25,37 @@ type DropletRecord struct {
 	PackageGUID     string
 	Labels          map[string]string
 	Annotations     map[string]string
+
+	// Image is the container image of the droplet, which is pulled with the ImagePullSecrets
+	Image            string
+	ImagePullSecrets []string
 }
 
-type DropletRepo struct{}
+type DropletRepo struct {
+	namespaceCache *GUIDNamespaceCache
+}
+
+func NewDropletRepo(namespaceCache *GUIDNamespaceCache) *DropletRepo {
+	return &DropletRepo{namespaceCache: namespaceCache}
+}
 
 func (r *DropletRepo) FetchDroplet(ctx context.Context, k8sClient client.Client, dropletGUID string) (DropletRecord, error) {
+	cfBuild := &workloadsv1alpha1.CFBuild{}
+	found, err := r.namespaceCache.fetch(ctx, k8sClient, dropletGUID, cfBuild)
+	if err != nil {
+		return DropletRecord{}, err
+	}
+	if found {
+		return r.returnDroplet([]workloadsv1alpha1.CFBuild{*cfBuild})
+	}
+
 	buildList := &workloadsv1alpha1.CFBuildList{}
-	err := k8sClient.List(ctx, buildList)
+	err = k8sClient.List(ctx, buildList)
 	if err != nil { // untested
 		return DropletRecord{}, err
 	}
 	allBuilds := buildList.Items
+	cacheBuildNamespaces(r.namespaceCache, allBuilds)
 	matches := filterBuildsByMetadataName(allBuilds, dropletGUID)
 
 	return r.returnDroplet(matches)
@@ -62,6 +82,10 @@ func (r *DropletRepo) returnDroplet(builds []workloadsv1alpha1.CFBuild) (Droplet
 func cfBuildToDropletRecord(cfBuild workloadsv1alpha1.CFBuild) DropletRecord {
 	updatedAtTime, _ := getTimeLastUpdatedTimestamp(&cfBuild.ObjectMeta)
 	processTypesMap := make(map[string]string)
+	imagePullSecrets := []string{}
+	for _, secret := range cfBuild.Status.BuildDropletStatus.Registry.ImagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, secret.Name)
+	}
 	processTypesArrayObject := cfBuild.Status.BuildDropletStatus.ProcessTypes
 	for index := range processTypesArrayObject {
 		processTypesMap[processTypesArrayObject[index].Type] = processTypesArrayObject[index].Command
@@ -85,5 +109,8 @@ func cfBuildToDropletRecord(cfBuild workloadsv1alpha1.CFBuild) DropletRecord {
 		PackageGUID:  cfBuild.Spec.PackageRef.Name,
 		Labels:       cfBuild.Labels,
 		Annotations:  cfBuild.Annotations,
+
+		Image:            cfBuild.Status.BuildDropletStatus.Registry.Image,
+		ImagePullSecrets: imagePullSecrets,
 	}
 }
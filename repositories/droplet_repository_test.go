@@ -45,7 +45,7 @@ var _ = Describe("DropletRepository", func() {
 			dropletRepo = new(DropletRepo)
 
 			var err error
-			client, err = BuildCRClient(k8sConfig)
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).ToNot(HaveOccurred())
 
 			buildGUID = generateGUID()
@@ -170,6 +170,11 @@ var _ = Describe("DropletRepository", func() {
 							Expect(dropletRecord.ProcessTypes).To(HaveKeyWithValue(processTypesArray[index].Type, processTypesArray[index].Command))
 						}
 					})
+
+					By("returning a record with the image and image pull secrets of the CR", func() {
+						Expect(dropletRecord.Image).To(Equal(registryImage))
+						Expect(dropletRecord.ImagePullSecrets).To(Equal([]string{registryImageSecret}))
+					})
 				})
 			})
 
@@ -0,0 +1,455 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type CFSpaceRepository struct {
+	CreateSpaceStub        func(context.Context, repositories.SpaceRecord) (repositories.SpaceRecord, error)
+	createSpaceMutex       sync.RWMutex
+	createSpaceArgsForCall []struct {
+		arg1 context.Context
+		arg2 repositories.SpaceRecord
+	}
+	createSpaceReturns struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	createSpaceReturnsOnCall map[int]struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	FetchSpaceStub        func(context.Context, string) (repositories.SpaceRecord, error)
+	fetchSpaceMutex       sync.RWMutex
+	fetchSpaceArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	fetchSpaceReturns struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	fetchSpaceReturnsOnCall map[int]struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	FetchSpacesStub        func(context.Context, []string, []string, labels.Selector) ([]repositories.SpaceRecord, error)
+	fetchSpacesMutex       sync.RWMutex
+	fetchSpacesArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+		arg3 []string
+		arg4 labels.Selector
+	}
+	fetchSpacesReturns struct {
+		result1 []repositories.SpaceRecord
+		result2 error
+	}
+	fetchSpacesReturnsOnCall map[int]struct {
+		result1 []repositories.SpaceRecord
+		result2 error
+	}
+	PatchSpaceMetadataStub        func(context.Context, repositories.MetadataPatchMessage) (repositories.SpaceRecord, error)
+	patchSpaceMetadataMutex       sync.RWMutex
+	patchSpaceMetadataArgsForCall []struct {
+		arg1 context.Context
+		arg2 repositories.MetadataPatchMessage
+	}
+	patchSpaceMetadataReturns struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	patchSpaceMetadataReturnsOnCall map[int]struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}
+	WaitForSpaceStub        func(context.Context, string, string) error
+	waitForSpaceMutex       sync.RWMutex
+	waitForSpaceArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	waitForSpaceReturns struct {
+		result1 error
+	}
+	waitForSpaceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CFSpaceRepository) CreateSpace(arg1 context.Context, arg2 repositories.SpaceRecord) (repositories.SpaceRecord, error) {
+	fake.createSpaceMutex.Lock()
+	ret, specificReturn := fake.createSpaceReturnsOnCall[len(fake.createSpaceArgsForCall)]
+	fake.createSpaceArgsForCall = append(fake.createSpaceArgsForCall, struct {
+		arg1 context.Context
+		arg2 repositories.SpaceRecord
+	}{arg1, arg2})
+	stub := fake.CreateSpaceStub
+	fakeReturns := fake.createSpaceReturns
+	fake.recordInvocation("CreateSpace", []interface{}{arg1, arg2})
+	fake.createSpaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFSpaceRepository) CreateSpaceCallCount() int {
+	fake.createSpaceMutex.RLock()
+	defer fake.createSpaceMutex.RUnlock()
+	return len(fake.createSpaceArgsForCall)
+}
+
+func (fake *CFSpaceRepository) CreateSpaceCalls(stub func(context.Context, repositories.SpaceRecord) (repositories.SpaceRecord, error)) {
+	fake.createSpaceMutex.Lock()
+	defer fake.createSpaceMutex.Unlock()
+	fake.CreateSpaceStub = stub
+}
+
+func (fake *CFSpaceRepository) CreateSpaceArgsForCall(i int) (context.Context, repositories.SpaceRecord) {
+	fake.createSpaceMutex.RLock()
+	defer fake.createSpaceMutex.RUnlock()
+	argsForCall := fake.createSpaceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CFSpaceRepository) CreateSpaceReturns(result1 repositories.SpaceRecord, result2 error) {
+	fake.createSpaceMutex.Lock()
+	defer fake.createSpaceMutex.Unlock()
+	fake.CreateSpaceStub = nil
+	fake.createSpaceReturns = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) CreateSpaceReturnsOnCall(i int, result1 repositories.SpaceRecord, result2 error) {
+	fake.createSpaceMutex.Lock()
+	defer fake.createSpaceMutex.Unlock()
+	fake.CreateSpaceStub = nil
+	if fake.createSpaceReturnsOnCall == nil {
+		fake.createSpaceReturnsOnCall = make(map[int]struct {
+			result1 repositories.SpaceRecord
+			result2 error
+		})
+	}
+	fake.createSpaceReturnsOnCall[i] = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) FetchSpace(arg1 context.Context, arg2 string) (repositories.SpaceRecord, error) {
+	fake.fetchSpaceMutex.Lock()
+	ret, specificReturn := fake.fetchSpaceReturnsOnCall[len(fake.fetchSpaceArgsForCall)]
+	fake.fetchSpaceArgsForCall = append(fake.fetchSpaceArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.FetchSpaceStub
+	fakeReturns := fake.fetchSpaceReturns
+	fake.recordInvocation("FetchSpace", []interface{}{arg1, arg2})
+	fake.fetchSpaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFSpaceRepository) FetchSpaceCallCount() int {
+	fake.fetchSpaceMutex.RLock()
+	defer fake.fetchSpaceMutex.RUnlock()
+	return len(fake.fetchSpaceArgsForCall)
+}
+
+func (fake *CFSpaceRepository) FetchSpaceCalls(stub func(context.Context, string) (repositories.SpaceRecord, error)) {
+	fake.fetchSpaceMutex.Lock()
+	defer fake.fetchSpaceMutex.Unlock()
+	fake.FetchSpaceStub = stub
+}
+
+func (fake *CFSpaceRepository) FetchSpaceArgsForCall(i int) (context.Context, string) {
+	fake.fetchSpaceMutex.RLock()
+	defer fake.fetchSpaceMutex.RUnlock()
+	argsForCall := fake.fetchSpaceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CFSpaceRepository) FetchSpaceReturns(result1 repositories.SpaceRecord, result2 error) {
+	fake.fetchSpaceMutex.Lock()
+	defer fake.fetchSpaceMutex.Unlock()
+	fake.FetchSpaceStub = nil
+	fake.fetchSpaceReturns = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) FetchSpaceReturnsOnCall(i int, result1 repositories.SpaceRecord, result2 error) {
+	fake.fetchSpaceMutex.Lock()
+	defer fake.fetchSpaceMutex.Unlock()
+	fake.FetchSpaceStub = nil
+	if fake.fetchSpaceReturnsOnCall == nil {
+		fake.fetchSpaceReturnsOnCall = make(map[int]struct {
+			result1 repositories.SpaceRecord
+			result2 error
+		})
+	}
+	fake.fetchSpaceReturnsOnCall[i] = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) FetchSpaces(arg1 context.Context, arg2 []string, arg3 []string, arg4 labels.Selector) ([]repositories.SpaceRecord, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.fetchSpacesMutex.Lock()
+	ret, specificReturn := fake.fetchSpacesReturnsOnCall[len(fake.fetchSpacesArgsForCall)]
+	fake.fetchSpacesArgsForCall = append(fake.fetchSpacesArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+		arg3 []string
+		arg4 labels.Selector
+	}{arg1, arg2Copy, arg3Copy, arg4})
+	stub := fake.FetchSpacesStub
+	fakeReturns := fake.fetchSpacesReturns
+	fake.recordInvocation("FetchSpaces", []interface{}{arg1, arg2Copy, arg3Copy, arg4})
+	fake.fetchSpacesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFSpaceRepository) FetchSpacesCallCount() int {
+	fake.fetchSpacesMutex.RLock()
+	defer fake.fetchSpacesMutex.RUnlock()
+	return len(fake.fetchSpacesArgsForCall)
+}
+
+func (fake *CFSpaceRepository) FetchSpacesCalls(stub func(context.Context, []string, []string, labels.Selector) ([]repositories.SpaceRecord, error)) {
+	fake.fetchSpacesMutex.Lock()
+	defer fake.fetchSpacesMutex.Unlock()
+	fake.FetchSpacesStub = stub
+}
+
+func (fake *CFSpaceRepository) FetchSpacesArgsForCall(i int) (context.Context, []string, []string, labels.Selector) {
+	fake.fetchSpacesMutex.RLock()
+	defer fake.fetchSpacesMutex.RUnlock()
+	argsForCall := fake.fetchSpacesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *CFSpaceRepository) FetchSpacesReturns(result1 []repositories.SpaceRecord, result2 error) {
+	fake.fetchSpacesMutex.Lock()
+	defer fake.fetchSpacesMutex.Unlock()
+	fake.FetchSpacesStub = nil
+	fake.fetchSpacesReturns = struct {
+		result1 []repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) FetchSpacesReturnsOnCall(i int, result1 []repositories.SpaceRecord, result2 error) {
+	fake.fetchSpacesMutex.Lock()
+	defer fake.fetchSpacesMutex.Unlock()
+	fake.FetchSpacesStub = nil
+	if fake.fetchSpacesReturnsOnCall == nil {
+		fake.fetchSpacesReturnsOnCall = make(map[int]struct {
+			result1 []repositories.SpaceRecord
+			result2 error
+		})
+	}
+	fake.fetchSpacesReturnsOnCall[i] = struct {
+		result1 []repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadata(arg1 context.Context, arg2 repositories.MetadataPatchMessage) (repositories.SpaceRecord, error) {
+	fake.patchSpaceMetadataMutex.Lock()
+	ret, specificReturn := fake.patchSpaceMetadataReturnsOnCall[len(fake.patchSpaceMetadataArgsForCall)]
+	fake.patchSpaceMetadataArgsForCall = append(fake.patchSpaceMetadataArgsForCall, struct {
+		arg1 context.Context
+		arg2 repositories.MetadataPatchMessage
+	}{arg1, arg2})
+	stub := fake.PatchSpaceMetadataStub
+	fakeReturns := fake.patchSpaceMetadataReturns
+	fake.recordInvocation("PatchSpaceMetadata", []interface{}{arg1, arg2})
+	fake.patchSpaceMetadataMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataCallCount() int {
+	fake.patchSpaceMetadataMutex.RLock()
+	defer fake.patchSpaceMetadataMutex.RUnlock()
+	return len(fake.patchSpaceMetadataArgsForCall)
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataCalls(stub func(context.Context, repositories.MetadataPatchMessage) (repositories.SpaceRecord, error)) {
+	fake.patchSpaceMetadataMutex.Lock()
+	defer fake.patchSpaceMetadataMutex.Unlock()
+	fake.PatchSpaceMetadataStub = stub
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataArgsForCall(i int) (context.Context, repositories.MetadataPatchMessage) {
+	fake.patchSpaceMetadataMutex.RLock()
+	defer fake.patchSpaceMetadataMutex.RUnlock()
+	argsForCall := fake.patchSpaceMetadataArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataReturns(result1 repositories.SpaceRecord, result2 error) {
+	fake.patchSpaceMetadataMutex.Lock()
+	defer fake.patchSpaceMetadataMutex.Unlock()
+	fake.PatchSpaceMetadataStub = nil
+	fake.patchSpaceMetadataReturns = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) PatchSpaceMetadataReturnsOnCall(i int, result1 repositories.SpaceRecord, result2 error) {
+	fake.patchSpaceMetadataMutex.Lock()
+	defer fake.patchSpaceMetadataMutex.Unlock()
+	fake.PatchSpaceMetadataStub = nil
+	if fake.patchSpaceMetadataReturnsOnCall == nil {
+		fake.patchSpaceMetadataReturnsOnCall = make(map[int]struct {
+			result1 repositories.SpaceRecord
+			result2 error
+		})
+	}
+	fake.patchSpaceMetadataReturnsOnCall[i] = struct {
+		result1 repositories.SpaceRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CFSpaceRepository) WaitForSpace(arg1 context.Context, arg2 string, arg3 string) error {
+	fake.waitForSpaceMutex.Lock()
+	ret, specificReturn := fake.waitForSpaceReturnsOnCall[len(fake.waitForSpaceArgsForCall)]
+	fake.waitForSpaceArgsForCall = append(fake.waitForSpaceArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.WaitForSpaceStub
+	fakeReturns := fake.waitForSpaceReturns
+	fake.recordInvocation("WaitForSpace", []interface{}{arg1, arg2, arg3})
+	fake.waitForSpaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *CFSpaceRepository) WaitForSpaceCallCount() int {
+	fake.waitForSpaceMutex.RLock()
+	defer fake.waitForSpaceMutex.RUnlock()
+	return len(fake.waitForSpaceArgsForCall)
+}
+
+func (fake *CFSpaceRepository) WaitForSpaceCalls(stub func(context.Context, string, string) error) {
+	fake.waitForSpaceMutex.Lock()
+	defer fake.waitForSpaceMutex.Unlock()
+	fake.WaitForSpaceStub = stub
+}
+
+func (fake *CFSpaceRepository) WaitForSpaceArgsForCall(i int) (context.Context, string, string) {
+	fake.waitForSpaceMutex.RLock()
+	defer fake.waitForSpaceMutex.RUnlock()
+	argsForCall := fake.waitForSpaceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *CFSpaceRepository) WaitForSpaceReturns(result1 error) {
+	fake.waitForSpaceMutex.Lock()
+	defer fake.waitForSpaceMutex.Unlock()
+	fake.WaitForSpaceStub = nil
+	fake.waitForSpaceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *CFSpaceRepository) WaitForSpaceReturnsOnCall(i int, result1 error) {
+	fake.waitForSpaceMutex.Lock()
+	defer fake.waitForSpaceMutex.Unlock()
+	fake.WaitForSpaceStub = nil
+	if fake.waitForSpaceReturnsOnCall == nil {
+		fake.waitForSpaceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForSpaceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *CFSpaceRepository) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createSpaceMutex.RLock()
+	defer fake.createSpaceMutex.RUnlock()
+	fake.fetchSpaceMutex.RLock()
+	defer fake.fetchSpaceMutex.RUnlock()
+	fake.fetchSpacesMutex.RLock()
+	defer fake.fetchSpacesMutex.RUnlock()
+	fake.patchSpaceMetadataMutex.RLock()
+	defer fake.patchSpaceMetadataMutex.RUnlock()
+	fake.waitForSpaceMutex.RLock()
+	defer fake.waitForSpaceMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CFSpaceRepository) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ repositories.CFSpaceRepository = new(CFSpaceRepository)
@@ -0,0 +1,125 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type PodMetricsFetcher struct {
+	FetchPodMetricsStub        func(context.Context, client.Client, string, labels.Selector) ([]repositories.PodMetricsRecord, error)
+	fetchPodMetricsMutex       sync.RWMutex
+	fetchPodMetricsArgsForCall []struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+		arg4 labels.Selector
+	}
+	fetchPodMetricsReturns struct {
+		result1 []repositories.PodMetricsRecord
+		result2 error
+	}
+	fetchPodMetricsReturnsOnCall map[int]struct {
+		result1 []repositories.PodMetricsRecord
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *PodMetricsFetcher) FetchPodMetrics(arg1 context.Context, arg2 client.Client, arg3 string, arg4 labels.Selector) ([]repositories.PodMetricsRecord, error) {
+	fake.fetchPodMetricsMutex.Lock()
+	ret, specificReturn := fake.fetchPodMetricsReturnsOnCall[len(fake.fetchPodMetricsArgsForCall)]
+	fake.fetchPodMetricsArgsForCall = append(fake.fetchPodMetricsArgsForCall, struct {
+		arg1 context.Context
+		arg2 client.Client
+		arg3 string
+		arg4 labels.Selector
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FetchPodMetricsStub
+	fakeReturns := fake.fetchPodMetricsReturns
+	fake.recordInvocation("FetchPodMetrics", []interface{}{arg1, arg2, arg3, arg4})
+	fake.fetchPodMetricsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *PodMetricsFetcher) FetchPodMetricsCallCount() int {
+	fake.fetchPodMetricsMutex.RLock()
+	defer fake.fetchPodMetricsMutex.RUnlock()
+	return len(fake.fetchPodMetricsArgsForCall)
+}
+
+func (fake *PodMetricsFetcher) FetchPodMetricsCalls(stub func(context.Context, client.Client, string, labels.Selector) ([]repositories.PodMetricsRecord, error)) {
+	fake.fetchPodMetricsMutex.Lock()
+	defer fake.fetchPodMetricsMutex.Unlock()
+	fake.FetchPodMetricsStub = stub
+}
+
+func (fake *PodMetricsFetcher) FetchPodMetricsArgsForCall(i int) (context.Context, client.Client, string, labels.Selector) {
+	fake.fetchPodMetricsMutex.RLock()
+	defer fake.fetchPodMetricsMutex.RUnlock()
+	argsForCall := fake.fetchPodMetricsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *PodMetricsFetcher) FetchPodMetricsReturns(result1 []repositories.PodMetricsRecord, result2 error) {
+	fake.fetchPodMetricsMutex.Lock()
+	defer fake.fetchPodMetricsMutex.Unlock()
+	fake.FetchPodMetricsStub = nil
+	fake.fetchPodMetricsReturns = struct {
+		result1 []repositories.PodMetricsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PodMetricsFetcher) FetchPodMetricsReturnsOnCall(i int, result1 []repositories.PodMetricsRecord, result2 error) {
+	fake.fetchPodMetricsMutex.Lock()
+	defer fake.fetchPodMetricsMutex.Unlock()
+	fake.FetchPodMetricsStub = nil
+	if fake.fetchPodMetricsReturnsOnCall == nil {
+		fake.fetchPodMetricsReturnsOnCall = make(map[int]struct {
+			result1 []repositories.PodMetricsRecord
+			result2 error
+		})
+	}
+	fake.fetchPodMetricsReturnsOnCall[i] = struct {
+		result1 []repositories.PodMetricsRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *PodMetricsFetcher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.fetchPodMetricsMutex.RLock()
+	defer fake.fetchPodMetricsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *PodMetricsFetcher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ repositories.PodMetricsFetcher = new(PodMetricsFetcher)
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+)
+
+const (
+	// The store only keeps files of sizes within the defaults of the resource pool of the CF API, as smaller files are
+	// cheaper to upload than to match
+	fingerprintMinimumSize = 64 * 1024
+	fingerprintMaximumSize = 512 * 1024 * 1024
+
+	// fingerprintMinimumAge keeps files that have been matched or kept recently from being evicted, so that a push
+	// finds the files that were matched for it
+	fingerprintMinimumAge = 10 * time.Minute
+
+	fingerprintIncomingPrefix = "incoming-"
+)
+
+type ResourceRecord struct {
+	SHA1        string
+	SizeInBytes int64
+	Path        string
+	Mode        string
+}
+
+// FingerprintStore keeps the files of uploaded packages by their SHA1, so that pushes can leave out the files that were
+// uploaded before and have them merged into their package from the store. Files are kept in a directory per tenant,
+// the identity that uploaded them, so that nobody can have the files of others merged into their packages by their
+// SHA1 alone. A store without a directory, or without a tenant, keeps nothing and matches nothing.
+//
+// The store holds at most maxSize bytes, evicting the least recently matched or kept files first. Files only match
+// when they are in the store of the instance of the shim that the package is uploaded to, so the directory has to be
+// shared by every instance, or the shim has to run a single instance.
+type FingerprintStore struct {
+	root    string
+	dir     string
+	maxSize int64
+}
+
+func NewFingerprintStore(root string, maxSize int64) *FingerprintStore {
+	return &FingerprintStore{root: root, maxSize: maxSize}
+}
+
+// ForIdentity returns the store of the files uploaded by identity
+func (s *FingerprintStore) ForIdentity(identity authorization.Identity) *FingerprintStore {
+	if s.root == "" {
+		return s
+	}
+
+	tenant := sha256.Sum256([]byte(identity.Kind + "/" + identity.Name))
+	return &FingerprintStore{
+		root:    s.root,
+		dir:     filepath.Join(s.root, hex.EncodeToString(tenant[:])),
+		maxSize: s.maxSize,
+	}
+}
+
+// MatchResources returns the resources whose file is in the store
+func (s *FingerprintStore) MatchResources(resources []ResourceRecord) ([]ResourceRecord, error) {
+	matches := []ResourceRecord{}
+	if s.dir == "" {
+		return matches, nil
+	}
+
+	for _, resource := range resources {
+		info, err := os.Stat(s.path(resource.SHA1))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("error from os.Stat: %w", err)
+		}
+		if info.Size() == resource.SizeInBytes {
+			matches = append(matches, resource)
+			s.touch(resource.SHA1)
+		}
+	}
+	return matches, nil
+}
+
+func (s *FingerprintStore) open(resource ResourceRecord) (*os.File, error) {
+	if s.dir == "" {
+		return nil, NotFoundError{}
+	}
+
+	file, err := os.Open(s.path(resource.SHA1))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, NotFoundError{Err: err}
+		}
+		return nil, fmt.Errorf("error from os.Open: %w", err)
+	}
+	s.touch(resource.SHA1)
+	return file, nil
+}
+
+// touch marks a file as used now, so that it is evicted last
+func (s *FingerprintStore) touch(checksum string) {
+	now := time.Now()
+	_ = os.Chtimes(s.path(checksum), now, now)
+}
+
+// EvictPeriodically evicts files from the store every interval until ctx is done
+func (s *FingerprintStore) EvictPeriodically(ctx context.Context, interval time.Duration) {
+	if s.root == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = s.Evict()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evict removes the least recently used files of every tenant until the store holds at most its maximum size. Files
+// used within fingerprintMinimumAge are kept, as pushes may be about to use them, and files that are still being
+// written are left to their writers.
+func (s *FingerprintStore) Evict() error {
+	type storedFile struct {
+		path   string
+		size   int64
+		usedAt time.Time
+	}
+
+	files := []storedFile{}
+	var total int64
+	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		total += info.Size()
+		if !strings.HasPrefix(info.Name(), fingerprintIncomingPrefix) {
+			files = append(files, storedFile{path: path, size: info.Size(), usedAt: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error from filepath.Walk: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].usedAt.Before(files[j].usedAt)
+	})
+	keepAfter := time.Now().Add(-fingerprintMinimumAge)
+	for _, file := range files {
+		if total <= s.maxSize || file.usedAt.After(keepAfter) {
+			break
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error from os.Remove: %w", err)
+		}
+		total -= file.size
+	}
+	return nil
+}
+
+// newFingerprintWriter returns a writer that adds the file written to it to the store, or nil when the store doesn't
+// keep files of its size
+func (s *FingerprintStore) newFingerprintWriter(size int64) *fingerprintWriter {
+	if s == nil || s.dir == "" || size < fingerprintMinimumSize || size > fingerprintMaximumSize || size > s.maxSize {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil
+	}
+	file, err := ioutil.TempFile(s.dir, fingerprintIncomingPrefix)
+	if err != nil {
+		return nil
+	}
+	return &fingerprintWriter{store: s, file: file, hash: sha1.New()}
+}
+
+func (s *FingerprintStore) path(checksum string) string {
+	return filepath.Join(s.dir, checksum[:2], checksum)
+}
+
+// fingerprintWriter writes a file to a temp file of the store while hashing it. Keeping files is best effort, so
+// writing never fails, and a file that couldn't be written is just not kept.
+type fingerprintWriter struct {
+	store *FingerprintStore
+	file  *os.File
+	hash  hash.Hash
+	err   error
+}
+
+func (w *fingerprintWriter) Write(p []byte) (int, error) {
+	if w.err == nil {
+		_, w.err = w.file.Write(p)
+	}
+	w.hash.Write(p)
+	return len(p), nil
+}
+
+// commit moves the written file to its place in the store
+func (w *fingerprintWriter) commit() {
+	if err := w.file.Close(); err != nil || w.err != nil {
+		os.Remove(w.file.Name())
+		return
+	}
+
+	path := w.store.path(hex.EncodeToString(w.hash.Sum(nil)))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		os.Remove(w.file.Name())
+		return
+	}
+	if err := os.Rename(w.file.Name(), path); err != nil {
+		os.Remove(w.file.Name())
+	}
+}
+
+// discard removes the written file, for files that turned out to be broken
+func (w *fingerprintWriter) discard() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// fileMode parses the octal mode of a resource, which the CF CLI sends without a leading zero
+func (r ResourceRecord) fileMode() int64 {
+	mode, err := strconv.ParseInt(r.Mode, 8, 64)
+	if err != nil || mode == 0 {
+		return sourceFileMode
+	}
+	return mode
+}
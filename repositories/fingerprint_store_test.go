@@ -0,0 +1,262 @@
+package repositories_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var _ = Describe("FingerprintStore", func() {
+	const storeMaxSize = 1024 * 1024 * 1024
+
+	var (
+		storeDir         string
+		fingerprintStore *FingerprintStore
+		bigFile          []byte
+		bigFileSHA1      string
+		alice            = authorization.Identity{Kind: rbacv1.UserKind, Name: "alice"}
+	)
+
+	// storedFiles returns the paths of the files kept for checksum by any tenant
+	storedFiles := func(checksum string) []string {
+		paths, err := filepath.Glob(filepath.Join(storeDir, "*", checksum[:2], checksum))
+		Expect(err).NotTo(HaveOccurred())
+		return paths
+	}
+
+	// readTar returns the contents of the files of a tar by name, and their modes
+	readTar := func(tarReader io.ReadCloser) (map[string]string, map[string]int64) {
+		defer tarReader.Close()
+		contents, modes := map[string]string{}, map[string]int64{}
+		tr := tar.NewReader(tarReader)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return contents, modes
+			}
+			Expect(err).NotTo(HaveOccurred())
+			fileContents, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			contents[header.Name] = string(fileContents)
+			modes[header.Name] = header.Mode
+		}
+	}
+
+	zipOf := func(files map[string][]byte) io.Reader {
+		var b bytes.Buffer
+		writer := zip.NewWriter(&b)
+		for name, contents := range files {
+			file, err := writer.Create(name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = file.Write(contents)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(writer.Close()).To(Succeed())
+		return &b
+	}
+
+	BeforeEach(func() {
+		var err error
+		storeDir, err = ioutil.TempDir("", "fingerprints")
+		Expect(err).NotTo(HaveOccurred())
+		fingerprintStore = NewFingerprintStore(storeDir, storeMaxSize).ForIdentity(alice)
+
+		bigFile = bytes.Repeat([]byte("0123456789abcdef"), 5000)
+		sum := sha1.Sum(bigFile)
+		bigFileSHA1 = hex.EncodeToString(sum[:])
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(storeDir)).To(Succeed())
+	})
+
+	Describe("ReadPackageAsTar", func() {
+		It("keeps the large files of the package", func() {
+			contents, _ := readTar(fingerprintStore.ReadPackageAsTar(zipOf(map[string][]byte{
+				"lib/big.jar": bigFile,
+				"Procfile":    []byte("web: ./start"),
+			}), nil, "/"))
+			Expect(contents).To(HaveLen(2))
+
+			Expect(storedFiles(bigFileSHA1)).To(HaveLen(1))
+			smallSum := sha1.Sum([]byte("web: ./start"))
+			Expect(storedFiles(hex.EncodeToString(smallSum[:]))).To(BeEmpty())
+		})
+
+		It("merges cached files into the package", func() {
+			readTar(fingerprintStore.ReadPackageAsTar(zipOf(map[string][]byte{"lib/big.jar": bigFile}), nil, "/"))
+
+			contents, modes := readTar(fingerprintStore.ReadPackageAsTar(zipOf(map[string][]byte{
+				"Procfile": []byte("web: ./start"),
+			}), []ResourceRecord{
+				{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "other/copy.jar", Mode: "644"},
+			}, "/"))
+			Expect(contents).To(Equal(map[string]string{
+				"/Procfile":       "web: ./start",
+				"/other/copy.jar": string(bigFile),
+			}))
+			Expect(modes["/other/copy.jar"]).To(BeEquivalentTo(0644))
+		})
+
+		It("builds a package of cached files only", func() {
+			readTar(fingerprintStore.ReadPackageAsTar(zipOf(map[string][]byte{"lib/big.jar": bigFile}), nil, "/"))
+
+			contents, _ := readTar(fingerprintStore.ReadPackageAsTar(nil, []ResourceRecord{
+				{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+			}, "/"))
+			Expect(contents).To(Equal(map[string]string{"/lib/big.jar": string(bigFile)}))
+		})
+
+		It("fails when a cached file is missing", func() {
+			tarReader := fingerprintStore.ReadPackageAsTar(nil, []ResourceRecord{
+				{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+			}, "/")
+			defer tarReader.Close()
+
+			_, err := io.Copy(io.Discard, tarReader)
+			Expect(err).To(MatchError(ContainSubstring(`error adding cached resource "lib/big.jar"`)))
+		})
+	})
+
+	Describe("MatchResources", func() {
+		BeforeEach(func() {
+			readTar(fingerprintStore.ReadPackageAsTar(zipOf(map[string][]byte{"lib/big.jar": bigFile}), nil, "/"))
+		})
+
+		It("returns the resources that the store has", func() {
+			matches, err := fingerprintStore.MatchResources([]ResourceRecord{
+				{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+				{SHA1: bigFileSHA1, SizeInBytes: 42, Path: "lib/wrong-size.jar"},
+				{SHA1: "002d760bea1be268e27077412e11a320d0f164d3", SizeInBytes: 70000, Path: "lib/new.jar"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(Equal([]ResourceRecord{
+				{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+			}))
+		})
+
+		When("the resources are matched for another identity", func() {
+			BeforeEach(func() {
+				fingerprintStore = NewFingerprintStore(storeDir, storeMaxSize).ForIdentity(authorization.Identity{Kind: rbacv1.UserKind, Name: "bob"})
+			})
+
+			It("matches nothing", func() {
+				matches, err := fingerprintStore.MatchResources([]ResourceRecord{
+					{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(matches).To(BeEmpty())
+			})
+
+			It("doesn't merge the files of the other identity into packages", func() {
+				tarReader := fingerprintStore.ReadPackageAsTar(nil, []ResourceRecord{
+					{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+				}, "/")
+				defer tarReader.Close()
+
+				_, err := io.Copy(io.Discard, tarReader)
+				Expect(err).To(MatchError(ContainSubstring(`error adding cached resource "lib/big.jar"`)))
+			})
+		})
+
+		When("the store has no identity", func() {
+			BeforeEach(func() {
+				fingerprintStore = NewFingerprintStore(storeDir, storeMaxSize)
+			})
+
+			It("matches nothing", func() {
+				matches, err := fingerprintStore.MatchResources([]ResourceRecord{
+					{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(matches).To(BeEmpty())
+			})
+		})
+
+		When("the store has no directory", func() {
+			BeforeEach(func() {
+				fingerprintStore = NewFingerprintStore("", storeMaxSize).ForIdentity(alice)
+			})
+
+			It("matches nothing", func() {
+				matches, err := fingerprintStore.MatchResources([]ResourceRecord{
+					{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(matches).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Evict", func() {
+		var (
+			otherFile     []byte
+			otherFileSHA1 string
+		)
+
+		// useAt sets the time a stored file was last used
+		useAt := func(checksum string, usedAt time.Time) {
+			for _, path := range storedFiles(checksum) {
+				Expect(os.Chtimes(path, usedAt, usedAt)).To(Succeed())
+			}
+		}
+
+		BeforeEach(func() {
+			otherFile = bytes.Repeat([]byte("fedcba9876543210"), 5000)
+			sum := sha1.Sum(otherFile)
+			otherFileSHA1 = hex.EncodeToString(sum[:])
+
+			readTar(fingerprintStore.ReadPackageAsTar(zipOf(map[string][]byte{"lib/big.jar": bigFile}), nil, "/"))
+			bob := NewFingerprintStore(storeDir, storeMaxSize).ForIdentity(authorization.Identity{Kind: rbacv1.UserKind, Name: "bob"})
+			readTar(bob.ReadPackageAsTar(zipOf(map[string][]byte{"lib/other.jar": otherFile}), nil, "/"))
+
+			fingerprintStore = NewFingerprintStore(storeDir, int64(len(bigFile)))
+		})
+
+		It("removes the least recently used files of every tenant beyond the maximum size", func() {
+			useAt(bigFileSHA1, time.Now().Add(-2*time.Hour))
+			useAt(otherFileSHA1, time.Now().Add(-time.Hour))
+
+			Expect(fingerprintStore.Evict()).To(Succeed())
+			Expect(storedFiles(bigFileSHA1)).To(BeEmpty())
+			Expect(storedFiles(otherFileSHA1)).To(HaveLen(1))
+		})
+
+		It("keeps files that were used recently", func() {
+			useAt(bigFileSHA1, time.Now().Add(-time.Minute))
+
+			Expect(fingerprintStore.Evict()).To(Succeed())
+			Expect(storedFiles(bigFileSHA1)).To(HaveLen(1))
+			Expect(storedFiles(otherFileSHA1)).To(HaveLen(1))
+		})
+
+		It("counts matching a file as using it", func() {
+			useAt(bigFileSHA1, time.Now().Add(-2*time.Hour))
+			useAt(otherFileSHA1, time.Now().Add(-time.Hour))
+
+			matches, err := NewFingerprintStore(storeDir, storeMaxSize).ForIdentity(alice).MatchResources([]ResourceRecord{
+				{SHA1: bigFileSHA1, SizeInBytes: int64(len(bigFile)), Path: "lib/big.jar"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(HaveLen(1))
+
+			Expect(fingerprintStore.Evict()).To(Succeed())
+			Expect(storedFiles(bigFileSHA1)).To(HaveLen(1))
+			Expect(storedFiles(otherFileSHA1)).To(BeEmpty())
+		})
+	})
+})
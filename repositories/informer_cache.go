@@ -0,0 +1,599 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	networkingv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/networking/v1alpha1"
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	hnsv1alpha2 "sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
+)
+
+const (
+	// AppGUIDIndex indexes CFProcesses, CFBuilds and CFPackages by Spec.AppRef and CFRoutes by the apps of their destinations
+	AppGUIDIndex = "appGUID"
+	// LabelIndex indexes objects by each of their labels, formatted as "key=value"
+	LabelIndex = "labels"
+
+	// pendingWriteTimeout limits how long the objects written by the shim are read from the API server while the
+	// informer cache hasn't caught up with the write
+	pendingWriteTimeout = 10 * time.Second
+)
+
+//counterfeiter:generate -o fake -fake-name IdentityProvider . IdentityProvider
+
+type IdentityProvider interface {
+	GetIdentity(ctx context.Context, authorizationHeader string) (authorization.Identity, error)
+}
+
+//counterfeiter:generate -o fake -fake-name AccessReviewer . AccessReviewer
+
+// AccessReviewer answers whether the user that userClient authenticates as may perform an action, as
+// authorization.AccessReviewer does
+type AccessReviewer interface {
+	Allowed(ctx context.Context, userClient client.Client, authorizationHeader string, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// authenticationAttributes is the action that is reviewed to authenticate a user. Only whether it can be reviewed
+// matters, not the answer.
+var authenticationAttributes = authorizationv1.ResourceAttributes{
+	Verb:     "get",
+	Group:    workloadsv1alpha1.GroupVersion.Group,
+	Resource: "cfapps",
+}
+
+// InformerCache serves reads of the CF custom resources and of the Jobs of tasks from memory. Objects of any other type
+// are not cached. Objects written by the clients of the InformerCache are read from the API server until the informer
+// cache has caught up with the writes.
+type InformerCache struct {
+	cache.Cache
+	// cachedKinds are the resources of the cached kinds
+	cachedKinds map[schema.GroupVersionKind]string
+	sharedKinds map[schema.GroupVersionKind]struct{}
+
+	pendingWritesMutex sync.Mutex
+	pendingWrites      map[objectKey]pendingWrite
+}
+
+type objectKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+// pendingWrite is a write of an object that the informer cache may not have seen yet
+type pendingWrite struct {
+	resourceVersion string
+	deleted         bool
+	expiresAt       time.Time
+}
+
+// NewInformerCache creates a shared informer cache for the CF custom resources and registers the indexes
+// used by the repositories. It must be started and synced before it is used to build clients.
+func NewInformerCache(ctx context.Context, config *rest.Config) (*InformerCache, error) {
+	taskSelector, err := labels.Parse(TaskLabel)
+	if err != nil {
+		return nil, err
+	}
+	informerCache, err := cache.New(config, cache.Options{
+		Scheme: scheme.Scheme,
+		// Only the Jobs that run tasks are read by the shim
+		SelectorsByObject: cache.SelectorsByObject{
+			&batchv1.Job{}: {Label: taskSelector},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating informer cache: %w", err)
+	}
+
+	cachedObjects := map[client.Object]string{
+		&workloadsv1alpha1.CFApp{}:        "cfapps",
+		&workloadsv1alpha1.CFProcess{}:    "cfprocesses",
+		&workloadsv1alpha1.CFBuild{}:      "cfbuilds",
+		&workloadsv1alpha1.CFPackage{}:    "cfpackages",
+		&networkingv1alpha1.CFRoute{}:     "cfroutes",
+		&networkingv1alpha1.CFDomain{}:    "cfdomains",
+		&hnsv1alpha2.SubnamespaceAnchor{}: "subnamespaceanchors",
+		&batchv1.Job{}:                    "jobs",
+	}
+	// Domains are shared across all orgs and spaces, so every user can read them
+	sharedObjects := []client.Object{
+		&networkingv1alpha1.CFDomain{},
+	}
+
+	c := &InformerCache{
+		Cache:         informerCache,
+		cachedKinds:   map[schema.GroupVersionKind]string{},
+		sharedKinds:   map[schema.GroupVersionKind]struct{}{},
+		pendingWrites: map[objectKey]pendingWrite{},
+	}
+
+	for obj, resource := range cachedObjects {
+		gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		c.cachedKinds[gvk] = resource
+
+		err = informerCache.IndexField(ctx, obj, LabelIndex, labelIndexValues)
+		if err != nil {
+			return nil, fmt.Errorf("error indexing %s by labels: %w", gvk.Kind, err)
+		}
+	}
+
+	for _, obj := range sharedObjects {
+		gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		c.sharedKinds[gvk] = struct{}{}
+	}
+
+	appGUIDIndexes := map[client.Object]client.IndexerFunc{
+		&workloadsv1alpha1.CFProcess{}: func(obj client.Object) []string {
+			return []string{obj.(*workloadsv1alpha1.CFProcess).Spec.AppRef.Name}
+		},
+		&workloadsv1alpha1.CFBuild{}: func(obj client.Object) []string {
+			return []string{obj.(*workloadsv1alpha1.CFBuild).Spec.AppRef.Name}
+		},
+		&workloadsv1alpha1.CFPackage{}: func(obj client.Object) []string {
+			return []string{obj.(*workloadsv1alpha1.CFPackage).Spec.AppRef.Name}
+		},
+		&networkingv1alpha1.CFRoute{}: func(obj client.Object) []string {
+			var appGUIDs []string
+			for _, destination := range obj.(*networkingv1alpha1.CFRoute).Spec.Destinations {
+				appGUIDs = append(appGUIDs, destination.AppRef.Name)
+			}
+			return appGUIDs
+		},
+	}
+	for obj, indexerFunc := range appGUIDIndexes {
+		err = informerCache.IndexField(ctx, obj, AppGUIDIndex, indexerFunc)
+		if err != nil {
+			return nil, fmt.Errorf("error indexing %T by app GUID: %w", obj, err)
+		}
+	}
+
+	return c, nil
+}
+
+func labelIndexValues(obj client.Object) []string {
+	values := make([]string, 0, len(obj.GetLabels()))
+	for key, value := range obj.GetLabels() {
+		values = append(values, labelIndexValue(key, value))
+	}
+	return values
+}
+
+func labelIndexValue(key, value string) string {
+	return key + "=" + value
+}
+
+// BuildCRClient matches the signature of BuildCRClient and builds the clients of UserClient
+func (c *InformerCache) BuildCRClient(
+	accessReviewer AccessReviewer,
+	identityProvider IdentityProvider,
+	nsProvider AuthorizedNamespacesProvider,
+) func(*rest.Config, string) (client.Client, error) {
+	return func(config *rest.Config, authorizationHeader string) (client.Client, error) {
+		userClient, err := BuildCRClient(config, authorizationHeader)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.UserClient(context.Background(), userClient, authorizationHeader, accessReviewer, identityProvider, nsProvider)
+	}
+}
+
+// UserClient wraps the client of the user identified by authorizationHeader. Reads of cached objects are served from
+// the informer cache when accessReviewer allows the user to get or list them in their namespace. All other requests
+// are made as the user. The user is authenticated with an access review, so that the answers of accessReviewer save
+// authenticating each request.
+func (c *InformerCache) UserClient(
+	ctx context.Context,
+	userClient client.Client,
+	authorizationHeader string,
+	accessReviewer AccessReviewer,
+	identityProvider IdentityProvider,
+	nsProvider AuthorizedNamespacesProvider,
+) (client.Client, error) {
+	_, err := accessReviewer.Allowed(ctx, userClient, authorizationHeader, authenticationAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &informerClient{
+		Client:              userClient,
+		informerCache:       c,
+		accessReviewer:      accessReviewer,
+		authorizationHeader: authorizationHeader,
+		identityProvider:    identityProvider,
+		nsProvider:          nsProvider,
+	}, nil
+}
+
+// BuildPrivilegedCRClient matches the signature of BuildPrivilegedCRClient. Reads of cached objects are served from the
+// informer cache without any authorization checks.
+func (c *InformerCache) BuildPrivilegedCRClient(config *rest.Config, authorizationHeader string) (client.Client, error) {
+	privilegedClient, err := BuildPrivilegedCRClient(config, authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &informerClient{
+		Client:        privilegedClient,
+		informerCache: c,
+	}, nil
+}
+
+// WithCachedReads wraps a privileged client so that reads of cached objects are served from the informer cache
+func (c *InformerCache) WithCachedReads(privilegedClient client.WithWatch) client.WithWatch {
+	return &informerWithWatchClient{
+		informerClient: &informerClient{
+			Client:        privilegedClient,
+			informerCache: c,
+		},
+		watcher: privilegedClient,
+	}
+}
+
+func (c *InformerCache) isCached(gvk schema.GroupVersionKind) bool {
+	_, ok := c.cachedKinds[gvk]
+	return ok
+}
+
+// recordWrite remembers that obj was written, so that it is read from the API server until the informer cache has
+// caught up with the write
+func (c *InformerCache) recordWrite(obj client.Object, deleted bool) {
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil || !c.isCached(gvk) {
+		return
+	}
+
+	c.pendingWritesMutex.Lock()
+	defer c.pendingWritesMutex.Unlock()
+
+	c.pendingWrites[objectKey{gvk: gvk, namespace: obj.GetNamespace(), name: obj.GetName()}] = pendingWrite{
+		resourceVersion: obj.GetResourceVersion(),
+		deleted:         deleted,
+		expiresAt:       time.Now().Add(pendingWriteTimeout),
+	}
+}
+
+// hasPendingWrite reports whether the informer cache has not caught up with the last write of the object with key yet
+func (c *InformerCache) hasPendingWrite(ctx context.Context, key objectKey) bool {
+	c.pendingWritesMutex.Lock()
+	write, ok := c.pendingWrites[key]
+	c.pendingWritesMutex.Unlock()
+	if !ok {
+		return false
+	}
+
+	if !c.caughtUp(ctx, key, write) {
+		return true
+	}
+
+	c.pendingWritesMutex.Lock()
+	defer c.pendingWritesMutex.Unlock()
+	if c.pendingWrites[key] == write {
+		delete(c.pendingWrites, key)
+	}
+	return false
+}
+
+// hasPendingWrites reports whether the informer cache has not caught up with a write of an object of kind gvk in
+// namespace yet, or in any namespace when namespace is empty
+func (c *InformerCache) hasPendingWrites(ctx context.Context, gvk schema.GroupVersionKind, namespace string) bool {
+	var keys []objectKey
+	c.pendingWritesMutex.Lock()
+	for key := range c.pendingWrites {
+		if key.gvk == gvk && (namespace == "" || key.namespace == namespace) {
+			keys = append(keys, key)
+		}
+	}
+	c.pendingWritesMutex.Unlock()
+
+	pending := false
+	for _, key := range keys {
+		if c.hasPendingWrite(ctx, key) {
+			pending = true
+		}
+	}
+	return pending
+}
+
+func (c *InformerCache) caughtUp(ctx context.Context, key objectKey, write pendingWrite) bool {
+	if time.Now().After(write.expiresAt) {
+		return true
+	}
+
+	runtimeObj, err := scheme.Scheme.New(key.gvk)
+	if err != nil {
+		return true
+	}
+	obj, ok := runtimeObj.(client.Object)
+	if !ok {
+		return true
+	}
+
+	err = c.Cache.Get(ctx, client.ObjectKey{Namespace: key.namespace, Name: key.name}, obj)
+	if write.deleted {
+		return k8serrors.IsNotFound(err)
+	}
+	return err == nil && obj.GetResourceVersion() == write.resourceVersion
+}
+
+func (c *InformerCache) isShared(gvk schema.GroupVersionKind) bool {
+	_, ok := c.sharedKinds[gvk]
+	return ok
+}
+
+type informerClient struct {
+	client.Client
+	informerCache *InformerCache
+	// accessReviewer is nil for privileged clients, which can read from every namespace
+	accessReviewer      AccessReviewer
+	authorizationHeader string
+	identityProvider    IdentityProvider
+	nsProvider          AuthorizedNamespacesProvider
+}
+
+func (c *informerClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return err
+	}
+	if !c.informerCache.isCached(gvk) {
+		return c.Client.Get(ctx, key, obj)
+	}
+
+	allowed, err := c.allowed(ctx, gvk, "get", key.Namespace)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return c.forbiddenError(gvk, key.Name, key.Namespace)
+	}
+
+	if c.informerCache.hasPendingWrite(ctx, objectKey{gvk: gvk, namespace: key.Namespace, name: key.Name}) {
+		return c.Client.Get(ctx, key, obj)
+	}
+
+	err = c.informerCache.Get(ctx, key, obj)
+	if k8serrors.IsNotFound(err) {
+		// the object may have been created by another instance of the shim since the informer cache last caught up
+		return c.Client.Get(ctx, key, obj)
+	}
+	return err
+}
+
+func (c *informerClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvk, err := apiutil.GVKForObject(list, scheme.Scheme)
+	if err != nil {
+		return err
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	if !c.informerCache.isCached(gvk) {
+		return c.Client.List(ctx, list, opts...)
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Namespace != "" {
+		allowed, err := c.allowed(ctx, gvk, "list", listOpts.Namespace)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return c.forbiddenError(gvk, "", listOpts.Namespace)
+		}
+	}
+
+	if c.informerCache.hasPendingWrites(ctx, gvk, listOpts.Namespace) {
+		return c.liveList(ctx, list, listOpts)
+	}
+
+	useLabelIndex(listOpts)
+	err = c.informerCache.List(ctx, list, listOpts)
+	if err != nil {
+		return err
+	}
+
+	if c.accessReviewer == nil || c.informerCache.isShared(gvk) || listOpts.Namespace != "" {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	allowedNamespaces := map[string]bool{}
+	authorizedItems := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			return fmt.Errorf("unexpected list item of type %T", item)
+		}
+
+		allowed, reviewed := allowedNamespaces[obj.GetNamespace()]
+		if !reviewed {
+			allowed, err = c.allowed(ctx, gvk, "list", obj.GetNamespace())
+			if err != nil {
+				return err
+			}
+			allowedNamespaces[obj.GetNamespace()] = allowed
+		}
+		if allowed {
+			authorizedItems = append(authorizedItems, item)
+		}
+	}
+
+	return meta.SetList(list, authorizedItems)
+}
+
+// liveList lists objects from the API server. Selectors of the indexes of the informer cache are dropped, as the API
+// server doesn't have them and callers filter the objects by them anyway.
+func (c *informerClient) liveList(ctx context.Context, list client.ObjectList, listOpts *client.ListOptions) error {
+	if listOpts.FieldSelector != nil {
+		for _, requirement := range listOpts.FieldSelector.Requirements() {
+			if requirement.Field == AppGUIDIndex || requirement.Field == LabelIndex {
+				listOpts.FieldSelector = nil
+				break
+			}
+		}
+	}
+
+	if c.accessReviewer == nil {
+		return c.Client.List(ctx, list, listOpts)
+	}
+	return NewNamespaceScopedClient(c.Client, c.authorizationHeader, c.identityProvider, c.nsProvider).List(ctx, list, listOpts)
+}
+
+// allowed reports whether the user may perform verb on objects of kind gvk in namespace. Privileged clients and
+// objects that are shared by all users are always allowed.
+func (c *informerClient) allowed(ctx context.Context, gvk schema.GroupVersionKind, verb, namespace string) (bool, error) {
+	if c.accessReviewer == nil || c.informerCache.isShared(gvk) {
+		return true, nil
+	}
+
+	return c.accessReviewer.Allowed(ctx, c.Client, c.authorizationHeader, authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     gvk.Group,
+		Resource:  c.informerCache.cachedKinds[gvk],
+	})
+}
+
+func (c *informerClient) forbiddenError(gvk schema.GroupVersionKind, name, namespace string) error {
+	resource := schema.GroupResource{Group: gvk.Group, Resource: c.informerCache.cachedKinds[gvk]}
+	return k8serrors.NewForbidden(resource, name, errors.New("user may not access it in namespace "+namespace))
+}
+
+func (c *informerClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	err := c.Client.Create(ctx, obj, opts...)
+	if err == nil {
+		c.informerCache.recordWrite(obj, false)
+	}
+	return err
+}
+
+func (c *informerClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	err := c.Client.Update(ctx, obj, opts...)
+	if err == nil {
+		c.informerCache.recordWrite(obj, false)
+	}
+	return err
+}
+
+func (c *informerClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	if err == nil {
+		c.informerCache.recordWrite(obj, false)
+	}
+	return err
+}
+
+func (c *informerClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := c.Client.Delete(ctx, obj, opts...)
+	if err == nil {
+		c.informerCache.recordWrite(obj, true)
+	}
+	return err
+}
+
+func (c *informerClient) Status() client.StatusWriter {
+	return &informerStatusWriter{StatusWriter: c.Client.Status(), informerCache: c.informerCache}
+}
+
+type informerStatusWriter struct {
+	client.StatusWriter
+	informerCache *InformerCache
+}
+
+func (w *informerStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	err := w.StatusWriter.Update(ctx, obj, opts...)
+	if err == nil {
+		w.informerCache.recordWrite(obj, false)
+	}
+	return err
+}
+
+func (w *informerStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	err := w.StatusWriter.Patch(ctx, obj, patch, opts...)
+	if err == nil {
+		w.informerCache.recordWrite(obj, false)
+	}
+	return err
+}
+
+func (c *informerClient) readsFromInformerCache() bool {
+	return true
+}
+
+// useLabelIndex narrows a label selected List down with the label index, as the informer cache only
+// uses indexes for field selectors. The label selector is still applied to the narrowed down results.
+func useLabelIndex(listOpts *client.ListOptions) {
+	if listOpts.FieldSelector != nil || listOpts.LabelSelector == nil {
+		return
+	}
+
+	requirements, selectable := listOpts.LabelSelector.Requirements()
+	if !selectable {
+		return
+	}
+
+	for _, requirement := range requirements {
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			values := requirement.Values().List()
+			if len(values) == 1 {
+				listOpts.FieldSelector = fields.OneTermEqualSelector(LabelIndex, labelIndexValue(requirement.Key(), values[0]))
+				return
+			}
+		}
+	}
+}
+
+type informerWithWatchClient struct {
+	*informerClient
+	watcher client.WithWatch
+}
+
+func (c *informerWithWatchClient) Watch(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+	return c.watcher.Watch(ctx, list, opts...)
+}
+
+type informerCacheReader interface {
+	readsFromInformerCache() bool
+}
+
+// listOptionsForApp narrows a List in a namespace down to the objects of a single app when the client reads from the
+// informer cache. The API server cannot select custom resources by spec fields, so callers must still filter the results.
+func listOptionsForApp(k8sClient client.Client, namespace, appGUID string) []client.ListOption {
+	options := []client.ListOption{client.InNamespace(namespace)}
+	if _, ok := k8sClient.(informerCacheReader); ok {
+		options = append(options, client.MatchingFields{AppGUIDIndex: appGUID})
+	}
+	return options
+}
@@ -0,0 +1,226 @@
+package repositories_test
+
+import (
+	"context"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/fake"
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("InformerCache", func() {
+	var (
+		ctx           context.Context
+		cancelCache   context.CancelFunc
+		informerCache *InformerCache
+
+		namespace1 *corev1.Namespace
+		namespace2 *corev1.Namespace
+		app1GUID   string
+		app2GUID   string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		namespace1 = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(ctx, namespace1)).To(Succeed())
+		namespace2 = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(ctx, namespace2)).To(Succeed())
+
+		app1GUID = generateGUID()
+		cfApp1 := initializeAppCR("test-app1", app1GUID, namespace1.Name)
+		cfApp1.Labels = map[string]string{"env": "prod"}
+		Expect(k8sClient.Create(ctx, cfApp1)).To(Succeed())
+
+		app2GUID = generateGUID()
+		cfApp2 := initializeAppCR("test-app2", app2GUID, namespace2.Name)
+		cfApp2.Labels = map[string]string{"env": "dev"}
+		Expect(k8sClient.Create(ctx, cfApp2)).To(Succeed())
+
+		var err error
+		informerCache, err = NewInformerCache(ctx, k8sConfig)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cacheCtx context.Context
+		cacheCtx, cancelCache = context.WithCancel(ctx)
+		go func() {
+			defer GinkgoRecover()
+			Expect(informerCache.Start(cacheCtx)).To(Succeed())
+		}()
+		Expect(informerCache.WaitForCacheSync(cacheCtx)).To(BeTrue())
+	})
+
+	AfterEach(func() {
+		cancelCache()
+		Expect(k8sClient.Delete(ctx, namespace1)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, namespace2)).To(Succeed())
+	})
+
+	listAppGUIDs := func(k8sClient client.Client, opts ...client.ListOption) func() []string {
+		return func() []string {
+			appList := &workloadsv1alpha1.CFAppList{}
+			if err := k8sClient.List(ctx, appList, opts...); err != nil {
+				return nil
+			}
+			var guids []string
+			for _, app := range appList.Items {
+				guids = append(guids, app.Name)
+			}
+			return guids
+		}
+	}
+
+	Describe("BuildPrivilegedCRClient", func() {
+		var privilegedClient client.Client
+
+		BeforeEach(func() {
+			var err error
+			privilegedClient, err = informerCache.BuildPrivilegedCRClient(k8sConfig, "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lists objects from every namespace", func() {
+			Eventually(listAppGUIDs(privilegedClient)).Should(ContainElements(app1GUID, app2GUID))
+		})
+
+		It("narrows lists down by label", func() {
+			Eventually(listAppGUIDs(privilegedClient, client.MatchingLabels{"env": "prod"})).Should(ContainElement(app1GUID))
+			Expect(listAppGUIDs(privilegedClient, client.MatchingLabels{"env": "prod"})()).NotTo(ContainElement(app2GUID))
+		})
+
+		It("fetches the processes of an app with the app GUID index", func() {
+			Expect(k8sClient.Create(ctx, initializeProcessCR(generateGUID(), namespace1.Name, app1GUID))).To(Succeed())
+			Expect(k8sClient.Create(ctx, initializeProcessCR(generateGUID(), namespace1.Name, generateGUID()))).To(Succeed())
+
+			Eventually(func() []string {
+				processes, err := new(ProcessRepository).FetchProcessesForApp(ctx, privilegedClient, app1GUID, namespace1.Name, nil)
+				if err != nil {
+					return nil
+				}
+				var appGUIDs []string
+				for _, process := range processes {
+					appGUIDs = append(appGUIDs, process.AppGUID)
+				}
+				return appGUIDs
+			}).Should(Equal([]string{app1GUID}))
+		})
+	})
+
+	Describe("UserClient", func() {
+		var (
+			accessReviewer   *fake.AccessReviewer
+			identityProvider *fake.IdentityProvider
+			nsProvider       *fake.AuthorizedNamespacesProvider
+			allowedVerbs     map[string]map[string]bool
+			userClient       client.Client
+			buildErr         error
+		)
+
+		BeforeEach(func() {
+			allowedVerbs = map[string]map[string]bool{
+				"":              {"get": true},
+				namespace1.Name: {"get": true, "list": true, "create": true},
+			}
+			accessReviewer = new(fake.AccessReviewer)
+			accessReviewer.AllowedStub = func(_ context.Context, _ client.Client, _ string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+				return allowedVerbs[attributes.Namespace][attributes.Verb], nil
+			}
+			identityProvider = new(fake.IdentityProvider)
+			identityProvider.GetIdentityReturns(authorization.Identity{Name: "alice", Kind: "User"}, nil)
+			nsProvider = new(fake.AuthorizedNamespacesProvider)
+			nsProvider.GetAuthorizedNamespacesReturns([]string{namespace1.Name}, nil)
+		})
+
+		JustBeforeEach(func() {
+			userClient, buildErr = informerCache.UserClient(ctx, k8sClient, "Bearer my-token", accessReviewer, identityProvider, nsProvider)
+		})
+
+		It("authenticates the user with an access review", func() {
+			Expect(buildErr).NotTo(HaveOccurred())
+
+			Expect(accessReviewer.AllowedCallCount()).To(Equal(1))
+			_, _, authHeader, _ := accessReviewer.AllowedArgsForCall(0)
+			Expect(authHeader).To(Equal("Bearer my-token"))
+		})
+
+		It("reviews the access of each read by verb", func() {
+			Eventually(listAppGUIDs(userClient, client.InNamespace(namespace1.Name))).Should(ContainElement(app1GUID))
+
+			_, _, _, attributes := accessReviewer.AllowedArgsForCall(accessReviewer.AllowedCallCount() - 1)
+			Expect(attributes).To(Equal(authorizationv1.ResourceAttributes{
+				Namespace: namespace1.Name,
+				Verb:      "list",
+				Group:     "workloads.cloudfoundry.org",
+				Resource:  "cfapps",
+			}))
+		})
+
+		It("only lists objects from namespaces the user may list them in", func() {
+			Eventually(listAppGUIDs(userClient)).Should(ContainElement(app1GUID))
+			Expect(listAppGUIDs(userClient)()).NotTo(ContainElement(app2GUID))
+		})
+
+		It("is forbidden from getting objects in other namespaces", func() {
+			err := userClient.Get(ctx, types.NamespacedName{Name: app2GUID, Namespace: namespace2.Name}, &workloadsv1alpha1.CFApp{})
+			Expect(k8serrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("is forbidden from listing objects in other namespaces", func() {
+			err := userClient.List(ctx, &workloadsv1alpha1.CFAppList{}, client.InNamespace(namespace2.Name))
+			Expect(k8serrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		When("the user may get but not list objects in a namespace", func() {
+			BeforeEach(func() {
+				allowedVerbs[namespace2.Name] = map[string]bool{"get": true}
+			})
+
+			It("gets the objects but is forbidden from listing them", func() {
+				Eventually(func() error {
+					return userClient.Get(ctx, types.NamespacedName{Name: app2GUID, Namespace: namespace2.Name}, &workloadsv1alpha1.CFApp{})
+				}).Should(Succeed())
+
+				err := userClient.List(ctx, &workloadsv1alpha1.CFAppList{}, client.InNamespace(namespace2.Name))
+				Expect(k8serrors.IsForbidden(err)).To(BeTrue())
+				Expect(listAppGUIDs(userClient)()).NotTo(ContainElement(app2GUID))
+			})
+		})
+
+		It("reads its own writes before the cache has caught up with them", func() {
+			appGUID := generateGUID()
+			Expect(userClient.Create(ctx, initializeAppCR("test-app3", appGUID, namespace1.Name))).To(Succeed())
+
+			Expect(userClient.Get(ctx, types.NamespacedName{Name: appGUID, Namespace: namespace1.Name}, &workloadsv1alpha1.CFApp{})).To(Succeed())
+			Expect(listAppGUIDs(userClient, client.InNamespace(namespace1.Name))()).To(ContainElement(appGUID))
+		})
+
+		It("gets objects that are not in the cache yet from the API server", func() {
+			appGUID := generateGUID()
+			Expect(k8sClient.Create(ctx, initializeAppCR("test-app3", appGUID, namespace1.Name))).To(Succeed())
+
+			Expect(userClient.Get(ctx, types.NamespacedName{Name: appGUID, Namespace: namespace1.Name}, &workloadsv1alpha1.CFApp{})).To(Succeed())
+		})
+
+		When("the authorization header is not valid", func() {
+			BeforeEach(func() {
+				accessReviewer.AllowedReturns(false, authorization.UnauthorizedErr{})
+				accessReviewer.AllowedStub = nil
+			})
+
+			It("returns the error", func() {
+				Expect(authorization.IsUnauthorized(buildErr)).To(BeTrue())
+			})
+		})
+	})
+})
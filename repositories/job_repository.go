@@ -0,0 +1,381 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;patch;delete
+
+const (
+	JobStateProcessing = "PROCESSING"
+	JobStateComplete   = "COMPLETE"
+	JobStateFailed     = "FAILED"
+
+	AppDeleteJobOperation          = "app.delete"
+	SpaceApplyManifestJobOperation = "space.apply_manifest"
+
+	// JobOperationLabel marks the ConfigMaps that hold jobs and records their operation
+	JobOperationLabel = "cloudfoundry.org/job-operation"
+
+	// jobOwnerAnnotation is the instance of the shim that runs the task of a job
+	jobOwnerAnnotation = "cloudfoundry.org/job-owner"
+
+	jobConfigMapPrefix = "cf-job-"
+	jobInterruptedErr  = "job was interrupted because the API instance running it stopped"
+
+	jobStateKey             = "state"
+	jobErrorsKey            = "errors"
+	jobCreatedAtKey         = "created_at"
+	jobUpdatedAtKey         = "updated_at"
+	jobLeaseRenewedAtKey    = "lease_renewed_at"
+	jobResourceNamespaceKey = "resource_namespace"
+)
+
+// jobResources are the resources that the operations of jobs act on. A user may fetch a job when they may get that
+// resource in the namespace of the job.
+var jobResources = map[string]schema.GroupResource{
+	AppDeleteJobOperation:          {Group: workloadsv1alpha1.GroupVersion.Group, Resource: "cfapps"},
+	SpaceApplyManifestJobOperation: {Group: workloadsv1alpha1.GroupVersion.Group, Resource: "cfapps"},
+}
+
+type JobRecord struct {
+	GUID      string
+	Operation string
+	// ResourceNamespace is the namespace of the resource the job acts on: the space of an app or a manifest
+	ResourceNamespace string
+	State             string
+	Errors            []JobError
+	CreatedAt         string
+	UpdatedAt         string
+}
+
+// JobError is an error of a failed job. Tasks return a JobError to report their failure with a CF error title and
+// code; any other error of a task is reported as an unknown error, which has neither.
+type JobError struct {
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail"`
+	Code   int    `json:"code,omitempty"`
+}
+
+func (e JobError) Error() string {
+	return e.Detail
+}
+
+// JobTask is the work of a job. Its context is not tied to the request that started the job.
+type JobTask func(ctx context.Context) error
+
+// JobRepo runs tasks in the background and keeps track of their state, so that clients can poll for their outcome.
+// Jobs are stored in ConfigMaps in the root namespace so that they outlive the shim, but their tasks do not. While a
+// task runs, the instance of the shim that runs it renews the lease of its job. CleanUpJobs fails the processing jobs
+// whose lease has expired, as their instance has stopped, and deletes the jobs that finished more than a TTL ago.
+type JobRepo struct {
+	rootNamespace    string
+	privilegedClient client.Client
+	owner            string
+	leaseDuration    time.Duration
+	ttl              time.Duration
+	logger           logr.Logger
+}
+
+func NewJobRepo(rootNamespace string, privilegedClient client.Client, leaseDuration, ttl time.Duration) *JobRepo {
+	return &JobRepo{
+		rootNamespace:    rootNamespace,
+		privilegedClient: privilegedClient,
+		owner:            uuid.NewString(),
+		leaseDuration:    leaseDuration,
+		ttl:              ttl,
+		logger:           controllerruntime.Log.WithName("Job Repository"),
+	}
+}
+
+// RunJob starts task in the background and returns the job that reports its progress. resourceNamespace is the
+// namespace of the resource that the job acts on, see JobRecord.
+func (r *JobRepo) RunJob(ctx context.Context, operation string, resourceNamespace string, task JobTask) (JobRecord, error) {
+	now := time.Now().UTC().Format(TimestampFormat)
+	job := JobRecord{
+		GUID:              uuid.NewString(),
+		Operation:         operation,
+		ResourceNamespace: resourceNamespace,
+		State:             JobStateProcessing,
+		Errors:            []JobError{},
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	configMap, err := jobRecordToConfigMap(job, r.rootNamespace)
+	if err != nil {
+		return JobRecord{}, err
+	}
+	configMap.Annotations = map[string]string{jobOwnerAnnotation: r.owner}
+	configMap.Data[jobLeaseRenewedAtKey] = time.Now().UTC().Format(time.RFC3339Nano)
+	err = r.privilegedClient.Create(ctx, configMap)
+	if err != nil {
+		return JobRecord{}, fmt.Errorf("error creating job: %w", err)
+	}
+
+	go func() {
+		ctx := context.Background()
+		stopRenewing := make(chan struct{})
+		renewed := make(chan struct{})
+		go func() {
+			defer close(renewed)
+			r.renewLease(ctx, configMap, stopRenewing)
+		}()
+
+		finishedJob := job
+		finishedJob.State = JobStateComplete
+		if err := task(ctx); err != nil {
+			finishedJob.State = JobStateFailed
+			jobErr := JobError{Detail: err.Error()}
+			errors.As(err, &jobErr)
+			finishedJob.Errors = []JobError{jobErr}
+		}
+		finishedJob.UpdatedAt = time.Now().UTC().Format(TimestampFormat)
+		close(stopRenewing)
+		<-renewed
+
+		// the task has already run, so an error saving its outcome leaves the job processing until its lease expires. A
+		// conflict means that the job was failed by CleanUpJobs in the meantime, which is kept.
+		_ = r.saveJob(ctx, configMap, finishedJob)
+	}()
+
+	return job, nil
+}
+
+// renewLease keeps the lease of a processing job until stop is closed, and keeps configMap up to date with it. It stops
+// renewing when the job was changed by someone else, such as CleanUpJobs failing it.
+func (r *JobRepo) renewLease(ctx context.Context, configMap *corev1.ConfigMap, stop <-chan struct{}) {
+	ticker := time.NewTicker(r.leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			baseConfigMap := configMap.DeepCopy()
+			configMap.Data[jobLeaseRenewedAtKey] = time.Now().UTC().Format(time.RFC3339Nano)
+			// a failed renewal is retried at the next tick, and the lease only expires after several of them
+			err := r.privilegedClient.Patch(ctx, configMap, client.MergeFromWithOptions(baseConfigMap, client.MergeFromWithOptimisticLock{}))
+			if k8serrors.IsConflict(err) || k8serrors.IsNotFound(err) {
+				<-stop
+				return
+			}
+		}
+	}
+}
+
+// FetchJob returns the job with the given GUID when the user of userClient may get the resource that the job acts on.
+// Jobs of other users' resources are not found.
+func (r *JobRepo) FetchJob(ctx context.Context, userClient client.Client, guid string) (JobRecord, error) {
+	configMap := &corev1.ConfigMap{}
+	err := r.privilegedClient.Get(ctx, types.NamespacedName{Name: jobConfigMapPrefix + guid, Namespace: r.rootNamespace}, configMap)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return JobRecord{}, NotFoundError{Err: err}
+		}
+		return JobRecord{}, err
+	}
+
+	if _, ok := configMap.Labels[JobOperationLabel]; !ok {
+		return JobRecord{}, NotFoundError{}
+	}
+
+	job, err := configMapToJobRecord(*configMap)
+	if err != nil {
+		return JobRecord{}, err
+	}
+
+	allowed, err := r.mayGetResourceOf(ctx, userClient, job)
+	if err != nil {
+		return JobRecord{}, err
+	}
+	if !allowed {
+		return JobRecord{}, NotFoundError{}
+	}
+
+	return job, nil
+}
+
+// mayGetResourceOf reports whether the user of userClient may get the kind of resource that job acts on in its
+// namespace. Jobs of unknown operations are only visible to users who may get the ConfigMaps of jobs.
+func (r *JobRepo) mayGetResourceOf(ctx context.Context, userClient client.Client, job JobRecord) (bool, error) {
+	resource, ok := jobResources[job.Operation]
+	if !ok {
+		resource = schema.GroupResource{Resource: "configmaps"}
+	}
+	namespace := job.ResourceNamespace
+	if namespace == "" {
+		namespace = r.rootNamespace
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "get",
+				Group:     resource.Group,
+				Resource:  resource.Resource,
+			},
+		},
+	}
+	err := userClient.Create(ctx, review)
+	if err != nil {
+		return false, fmt.Errorf("error reviewing access to job %q: %w", job.GUID, err)
+	}
+
+	return review.Status.Allowed, nil
+}
+
+// CleanUpJobs fails the processing jobs whose lease has expired and deletes the finished jobs that were last updated
+// more than the TTL ago. Every instance of the shim may call it: an orphaned job is only failed by one of them.
+func (r *JobRepo) CleanUpJobs(ctx context.Context) error {
+	configMapList := &corev1.ConfigMapList{}
+	err := r.privilegedClient.List(ctx, configMapList, client.InNamespace(r.rootNamespace), client.HasLabels{JobOperationLabel})
+	if err != nil {
+		return fmt.Errorf("error listing jobs: %w", err)
+	}
+
+	now := time.Now()
+	for i := range configMapList.Items {
+		configMap := &configMapList.Items[i]
+		job, err := configMapToJobRecord(*configMap)
+		if err != nil {
+			r.logger.Error(err, "Skipping job that cannot be read", "ConfigMap", configMap.Name)
+			continue
+		}
+
+		if job.State == JobStateProcessing {
+			if !r.leaseExpired(*configMap, now) {
+				continue
+			}
+
+			job.State = JobStateFailed
+			job.Errors = []JobError{{Detail: jobInterruptedErr}}
+			job.UpdatedAt = now.UTC().Format(TimestampFormat)
+			err = r.saveJob(ctx, configMap, job)
+			if err != nil && !k8serrors.IsConflict(err) && !k8serrors.IsNotFound(err) {
+				return err
+			}
+			continue
+		}
+
+		updatedAt, err := time.Parse(TimestampFormat, job.UpdatedAt)
+		if err != nil || now.Sub(updatedAt) < r.ttl {
+			continue
+		}
+		err = r.privilegedClient.Delete(ctx, configMap)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting job %q: %w", job.GUID, err)
+		}
+	}
+
+	return nil
+}
+
+// CleanUpJobsPeriodically calls CleanUpJobs every interval until ctx is done. Errors are retried at the next interval.
+func (r *JobRepo) CleanUpJobsPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_ = r.CleanUpJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaseExpired reports whether the instance that runs a processing job has stopped renewing its lease
+func (r *JobRepo) leaseExpired(configMap corev1.ConfigMap, now time.Time) bool {
+	renewedAt, err := time.Parse(time.RFC3339Nano, configMap.Data[jobLeaseRenewedAtKey])
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(renewedAt) > r.leaseDuration
+}
+
+// saveJob saves job unless its ConfigMap was changed since it was read, in which case a conflict is returned
+func (r *JobRepo) saveJob(ctx context.Context, configMap *corev1.ConfigMap, job JobRecord) error {
+	updatedConfigMap, err := jobRecordToConfigMap(job, r.rootNamespace)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFromWithOptions(configMap.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	for key, value := range updatedConfigMap.Data {
+		configMap.Data[key] = value
+	}
+	err = r.privilegedClient.Patch(ctx, configMap, patch)
+	if err != nil {
+		return fmt.Errorf("error saving job %q: %w", job.GUID, err)
+	}
+
+	return nil
+}
+
+func jobRecordToConfigMap(job JobRecord, namespace string) (*corev1.ConfigMap, error) {
+	jobErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobConfigMapPrefix + job.GUID,
+			Namespace: namespace,
+			Labels: map[string]string{
+				JobOperationLabel: job.Operation,
+			},
+		},
+		Data: map[string]string{
+			jobStateKey:             job.State,
+			jobErrorsKey:            string(jobErrors),
+			jobCreatedAtKey:         job.CreatedAt,
+			jobUpdatedAtKey:         job.UpdatedAt,
+			jobResourceNamespaceKey: job.ResourceNamespace,
+		},
+	}, nil
+}
+
+func configMapToJobRecord(configMap corev1.ConfigMap) (JobRecord, error) {
+	jobErrors := []JobError{}
+	if data := configMap.Data[jobErrorsKey]; data != "" {
+		err := json.Unmarshal([]byte(data), &jobErrors)
+		if err != nil {
+			return JobRecord{}, fmt.Errorf("error reading errors of job %q: %w", configMap.Name, err)
+		}
+	}
+
+	return JobRecord{
+		GUID:              strings.TrimPrefix(configMap.Name, jobConfigMapPrefix),
+		Operation:         configMap.Labels[JobOperationLabel],
+		ResourceNamespace: configMap.Data[jobResourceNamespaceKey],
+		State:             configMap.Data[jobStateKey],
+		Errors:            jobErrors,
+		CreatedAt:         configMap.Data[jobCreatedAtKey],
+		UpdatedAt:         configMap.Data[jobUpdatedAtKey],
+	}, nil
+}
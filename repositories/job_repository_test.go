@@ -0,0 +1,268 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("JobRepo", func() {
+	var (
+		testCtx       context.Context
+		rootNamespace *corev1.Namespace
+		jobRepo       *JobRepo
+	)
+
+	BeforeEach(func() {
+		testCtx = context.Background()
+		rootNamespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(testCtx, rootNamespace)).To(Succeed())
+		jobRepo = NewJobRepo(rootNamespace.Name, k8sClient, time.Minute, time.Hour)
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(testCtx, rootNamespace)).To(Succeed())
+	})
+
+	fetchJobState := func(guid string) func() string {
+		return func() string {
+			job, err := jobRepo.FetchJob(testCtx, k8sClient, guid)
+			Expect(err).NotTo(HaveOccurred())
+			return job.State
+		}
+	}
+
+	Describe("RunJob", func() {
+		It("returns a processing job while the task runs", func() {
+			done := make(chan struct{})
+			defer close(done)
+
+			job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+				<-done
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.GUID).NotTo(BeEmpty())
+			Expect(job.Operation).To(Equal(AppDeleteJobOperation))
+			Expect(job.ResourceNamespace).To(Equal("app-space"))
+			Expect(job.State).To(Equal(JobStateProcessing))
+
+			fetchedJob, err := jobRepo.FetchJob(testCtx, k8sClient, job.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetchedJob).To(Equal(job))
+		})
+
+		It("completes the job when the task succeeds", func() {
+			job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(fetchJobState(job.GUID)).Should(Equal(JobStateComplete))
+		})
+
+		It("fails the job with the error of the task", func() {
+			job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+				return errors.New("boom")
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(fetchJobState(job.GUID)).Should(Equal(JobStateFailed))
+
+			failedJob, err := jobRepo.FetchJob(testCtx, k8sClient, job.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedJob.Errors).To(Equal([]JobError{{Detail: "boom"}}))
+		})
+
+		It("keeps the CF title and code of a JobError", func() {
+			job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+				return fmt.Errorf("wrapped: %w", JobError{Title: "CF-ResourceNotFound", Detail: "App not found", Code: 10010})
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(fetchJobState(job.GUID)).Should(Equal(JobStateFailed))
+
+			failedJob, err := jobRepo.FetchJob(testCtx, k8sClient, job.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failedJob.Errors).To(Equal([]JobError{{Title: "CF-ResourceNotFound", Detail: "App not found", Code: 10010}}))
+		})
+	})
+
+	Describe("FetchJob", func() {
+		When("the job doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				_, err := jobRepo.FetchJob(testCtx, k8sClient, "no-such-job")
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+
+		When("the user may not get the resource of the job", func() {
+			It("returns a NotFoundError", func() {
+				job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = jobRepo.FetchJob(testCtx, accessDeniedClient{Client: k8sClient}, job.GUID)
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+
+		When("the job was stored by a previous shim", func() {
+			It("is still found", func() {
+				job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				restartedJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, time.Minute, time.Hour)
+				Eventually(func() string {
+					fetchedJob, err := restartedJobRepo.FetchJob(testCtx, k8sClient, job.GUID)
+					Expect(err).NotTo(HaveOccurred())
+					return fetchedJob.State
+				}).Should(Equal(JobStateComplete))
+			})
+		})
+	})
+
+	Describe("CleanUpJobs", func() {
+		var (
+			processingJob, completedJob JobRecord
+			done                        chan struct{}
+		)
+
+		BeforeEach(func() {
+			done = make(chan struct{})
+
+			var err error
+			processingJob, err = jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+				<-done
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			completedJob, err = jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(fetchJobState(completedJob.GUID)).Should(Equal(JobStateComplete))
+		})
+
+		AfterEach(func() {
+			close(done)
+		})
+
+		It("leaves processing jobs whose lease is held alone", func() {
+			Expect(jobRepo.CleanUpJobs(testCtx)).To(Succeed())
+			Expect(fetchJobState(processingJob.GUID)()).To(Equal(JobStateProcessing))
+		})
+
+		It("leaves jobs that finished within the TTL alone", func() {
+			Expect(jobRepo.CleanUpJobs(testCtx)).To(Succeed())
+			Expect(fetchJobState(completedJob.GUID)()).To(Equal(JobStateComplete))
+		})
+
+		When("the lease of a processing job has expired", func() {
+			It("fails the job", func() {
+				time.Sleep(10 * time.Millisecond)
+				otherInstanceJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, time.Millisecond, time.Hour)
+				Expect(otherInstanceJobRepo.CleanUpJobs(testCtx)).To(Succeed())
+
+				failedJob, err := jobRepo.FetchJob(testCtx, k8sClient, processingJob.GUID)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedJob.State).To(Equal(JobStateFailed))
+				Expect(failedJob.Errors).To(ConsistOf(MatchError(ContainSubstring("interrupted"))))
+			})
+
+			It("keeps the job failed when its task finishes after all", func() {
+				finish := make(chan struct{})
+				job, err := jobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+					<-finish
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				time.Sleep(10 * time.Millisecond)
+				otherInstanceJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, time.Millisecond, time.Hour)
+				Expect(otherInstanceJobRepo.CleanUpJobs(testCtx)).To(Succeed())
+				close(finish)
+
+				Consistently(fetchJobState(job.GUID), time.Second).Should(Equal(JobStateFailed))
+			})
+		})
+
+		When("the task of a job runs for longer than the lease", func() {
+			var shortLeaseJob JobRecord
+
+			BeforeEach(func() {
+				shortLeaseJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, 600*time.Millisecond, time.Hour)
+				var err error
+				shortLeaseJob, err = shortLeaseJobRepo.RunJob(testCtx, AppDeleteJobOperation, "app-space", func(ctx context.Context) error {
+					<-done
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("renews the lease so that the job is not failed", func() {
+				otherInstanceJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, 600*time.Millisecond, time.Hour)
+				Consistently(func() string {
+					Expect(otherInstanceJobRepo.CleanUpJobs(testCtx)).To(Succeed())
+					return fetchJobState(shortLeaseJob.GUID)()
+				}, 2*time.Second, 100*time.Millisecond).Should(Equal(JobStateProcessing))
+			})
+		})
+
+		When("a job cannot be read", func() {
+			BeforeEach(func() {
+				Expect(k8sClient.Create(testCtx, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "cf-job-unreadable",
+						Namespace: rootNamespace.Name,
+						Labels:    map[string]string{JobOperationLabel: AppDeleteJobOperation},
+					},
+					Data: map[string]string{"state": JobStateFailed, "errors": "not-json"},
+				})).To(Succeed())
+			})
+
+			It("cleans up the other jobs", func() {
+				expiredJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, time.Minute, 0)
+				Expect(expiredJobRepo.CleanUpJobs(testCtx)).To(Succeed())
+
+				_, err := jobRepo.FetchJob(testCtx, k8sClient, completedJob.GUID)
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+
+		When("a job finished more than the TTL ago", func() {
+			It("deletes the job", func() {
+				expiredJobRepo := NewJobRepo(rootNamespace.Name, k8sClient, time.Minute, 0)
+				Expect(expiredJobRepo.CleanUpJobs(testCtx)).To(Succeed())
+
+				_, err := jobRepo.FetchJob(testCtx, k8sClient, completedJob.GUID)
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+				Expect(fetchJobState(processingJob.GUID)()).To(Equal(JobStateProcessing))
+			})
+		})
+	})
+})
+
+// accessDeniedClient is the client of a user who may not do anything that is reviewed with a SelfSubjectAccessReview
+type accessDeniedClient struct {
+	client.Client
+}
+
+func (c accessDeniedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+		review.Status.Allowed = false
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
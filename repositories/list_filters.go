@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	hnsv1alpha2 "sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
+)
+
+// namespacesToList returns the namespaces that hold the resources of the spaces in spaceGUIDs and of the spaces of the
+// orgs in orgGUIDs, keeping only the spaces that match both filters. The result is nil when neither filter is set,
+// meaning that every namespace must be listed, and empty when no space matches.
+func namespacesToList(ctx context.Context, k8sClient client.Client, spaceGUIDs, orgGUIDs []string) ([]string, error) {
+	if len(spaceGUIDs) == 0 && len(orgGUIDs) == 0 {
+		return nil, nil
+	}
+
+	namespaces := []string{}
+	seen := map[string]struct{}{}
+	addNamespace := func(namespace string) {
+		if _, ok := seen[namespace]; !ok {
+			seen[namespace] = struct{}{}
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	if len(orgGUIDs) == 0 {
+		for _, spaceGUID := range spaceGUIDs {
+			addNamespace(spaceGUID)
+		}
+		return namespaces, nil
+	}
+
+	spaceFilter := toMap(spaceGUIDs)
+	for _, orgGUID := range orgGUIDs {
+		anchorList := &hnsv1alpha2.SubnamespaceAnchorList{}
+		err := k8sClient.List(ctx, anchorList, client.InNamespace(orgGUID))
+		if err != nil {
+			if k8serrors.IsForbidden(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, anchor := range anchorList.Items {
+			if matchFilter(spaceFilter, anchor.Name) {
+				addNamespace(anchor.Name)
+			}
+		}
+	}
+
+	return namespaces, nil
+}
+
+// labelSelectorOptions returns the options that make a List select objects by labelSelector, which may be nil
+func labelSelectorOptions(labelSelector labels.Selector) []client.ListOption {
+	if labelSelector == nil || labelSelector.Empty() {
+		return nil
+	}
+	return []client.ListOption{client.MatchingLabelsSelector{Selector: labelSelector}}
+}
+
+// listInNamespaces fills list with the objects in each of namespaces, or in every namespace when namespaces is nil.
+// Namespaces the client is forbidden from reading are skipped, as they hold nothing the user can see.
+func listInNamespaces(ctx context.Context, k8sClient client.Client, list client.ObjectList, namespaces []string, opts ...client.ListOption) error {
+	if namespaces == nil {
+		return k8sClient.List(ctx, list, opts...)
+	}
+
+	allItems := []runtime.Object{}
+	for _, namespace := range namespaces {
+		namespaceList := list.DeepCopyObject().(client.ObjectList)
+		namespaceOpts := append([]client.ListOption{client.InNamespace(namespace)}, opts...)
+		err := k8sClient.List(ctx, namespaceList, namespaceOpts...)
+		if err != nil {
+			if k8serrors.IsForbidden(err) {
+				continue
+			}
+			return err
+		}
+
+		items, err := meta.ExtractList(namespaceList)
+		if err != nil {
+			return err
+		}
+		allItems = append(allItems, items...)
+	}
+
+	return meta.SetList(list, allItems)
+}
+
+// lessForOrderBy returns a less function for sort.SliceStable that orders a list by the field named in orderBy, which
+// is prefixed with "-" for descending order. value returns the value of a field of the item at index i.
+// The list keeps its order when orderBy is empty.
+func lessForOrderBy(orderBy string, value func(i int, field string) string) func(i, j int) bool {
+	if orderBy == "" {
+		return func(i, j int) bool { return false }
+	}
+
+	field := strings.TrimPrefix(orderBy, "-")
+	if field != orderBy {
+		return func(i, j int) bool { return value(j, field) < value(i, field) }
+	}
+	return func(i, j int) bool { return value(i, field) < value(j, field) }
+}
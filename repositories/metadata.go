@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MetadataReservedDomain is the domain of the prefixes of the labels and annotations that the shim and the
+// controllers keep their own state in, such as the counters of the tasks and revisions of apps. Users can neither see
+// nor change them.
+const MetadataReservedDomain = "cloudfoundry.org"
+
+// IsReservedMetadataKey tells whether a label or annotation key has a prefix in MetadataReservedDomain
+func IsReservedMetadataKey(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	prefix := key[:i]
+	return prefix == MetadataReservedDomain || strings.HasSuffix(prefix, "."+MetadataReservedDomain)
+}
+
+// MetadataPatch holds changes to the labels and annotations of a resource. Keys with a nil value are deleted and keys
+// that are not mentioned are left as they are, as in a JSON merge patch.
+type MetadataPatch struct {
+	Labels      map[string]*string
+	Annotations map[string]*string
+}
+
+// MetadataPatchMessage selects the resource to apply a MetadataPatch to. SpaceGUID is the namespace of resources
+// that live in a space and is ignored for orgs and spaces.
+type MetadataPatchMessage struct {
+	GUID      string
+	SpaceGUID string
+	MetadataPatch
+}
+
+// patchMetadata applies metadata to obj, which must have its name and namespace set, with a JSON merge patch and
+// fills obj with the patched object. Reserved keys are left as they are.
+func patchMetadata(ctx context.Context, k8sClient client.Client, obj client.Object, metadata MetadataPatch) error {
+	metadataFields := map[string]interface{}{}
+	if labels := withoutReservedKeys(metadata.Labels); len(labels) > 0 {
+		metadataFields["labels"] = labels
+	}
+	if annotations := withoutReservedKeys(metadata.Annotations); len(annotations) > 0 {
+		metadataFields["annotations"] = annotations
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"metadata": metadataFields})
+	if err != nil {
+		return err
+	}
+
+	err = k8sClient.Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return NotFoundError{Err: err}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// applyMetadataPatch returns current with the changes of patch applied, for patching labels or annotations of an
+// object that is also changed in other ways. Reserved keys are left as they are.
+func applyMetadataPatch(current map[string]string, patch map[string]*string) map[string]string {
+	patch = withoutReservedKeys(patch)
+	if len(patch) == 0 {
+		return current
+	}
+
+	result := make(map[string]string, len(current)+len(patch))
+	for key, value := range current {
+		result[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+		} else {
+			result[key] = *value
+		}
+	}
+	return result
+}
+
+// withoutReservedKeys returns the changes of patch to keys that aren't reserved
+func withoutReservedKeys(patch map[string]*string) map[string]*string {
+	result := make(map[string]*string, len(patch))
+	for key, value := range patch {
+		if !IsReservedMetadataKey(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// withAnnotation returns a copy of annotations with key set to value
+func withAnnotation(annotations map[string]string, key, value string) map[string]string {
+	result := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
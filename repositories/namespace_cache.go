@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GUIDNamespaceCache maps the GUIDs of CF resources to the namespaces they live in, so that
+// fetching a single resource can be a namespaced Get instead of a List across the cluster.
+// See docs/architecture-decisions/0001-cf-resource-guid-format.md
+//
+// The cache is in-memory and local to each instance of the shim. A nil cache is valid and never has any entries.
+type GUIDNamespaceCache struct {
+	mutex      sync.RWMutex
+	namespaces map[string]string
+}
+
+func NewGUIDNamespaceCache() *GUIDNamespaceCache {
+	return &GUIDNamespaceCache{
+		namespaces: map[string]string{},
+	}
+}
+
+func (c *GUIDNamespaceCache) Get(guid string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	namespace, ok := c.namespaces[guid]
+	return namespace, ok
+}
+
+func (c *GUIDNamespaceCache) Set(guid, namespace string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.namespaces[guid] = namespace
+}
+
+func (c *GUIDNamespaceCache) Delete(guid string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.namespaces, guid)
+}
+
+// fetch fills obj with a namespaced Get when the namespace of guid is cached and reports whether it did so.
+// The cache is shared by all users, so the Get is always made with the caller's client: a cached entry only saves the
+// List and never grants access. When the object no longer exists the entry is dropped and a NotFoundError is returned.
+// When the caller may not get the object a NotFoundError is returned too, so that it is not revealed to them.
+func (c *GUIDNamespaceCache) fetch(ctx context.Context, k8sClient client.Client, guid string, obj client.Object) (bool, error) {
+	namespace, ok := c.Get(guid)
+	if !ok {
+		return false, nil
+	}
+
+	err := k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: guid}, obj)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			c.Delete(guid)
+			return false, NotFoundError{Err: err}
+		}
+		if k8serrors.IsForbidden(err) {
+			return false, NotFoundError{Err: err}
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package repositories_test
+
+import (
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GUIDNamespaceCache", func() {
+	var namespaceCache *GUIDNamespaceCache
+
+	BeforeEach(func() {
+		namespaceCache = NewGUIDNamespaceCache()
+	})
+
+	It("returns the namespace that was set for a GUID", func() {
+		namespaceCache.Set("some-guid", "some-namespace")
+
+		namespace, ok := namespaceCache.Get("some-guid")
+		Expect(ok).To(BeTrue())
+		Expect(namespace).To(Equal("some-namespace"))
+	})
+
+	It("reports a miss for unknown GUIDs", func() {
+		_, ok := namespaceCache.Get("unknown-guid")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("forgets deleted GUIDs", func() {
+		namespaceCache.Set("some-guid", "some-namespace")
+		namespaceCache.Delete("some-guid")
+
+		_, ok := namespaceCache.Get("some-guid")
+		Expect(ok).To(BeFalse())
+	})
+
+	When("the cache is nil", func() {
+		BeforeEach(func() {
+			namespaceCache = nil
+		})
+
+		It("never has any entries", func() {
+			namespaceCache.Set("some-guid", "some-namespace")
+
+			_, ok := namespaceCache.Get("some-guid")
+			Expect(ok).To(BeFalse())
+			namespaceCache.Delete("some-guid")
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuildNamespaceScopedCRClient matches the signature of BuildCRClient. Its clients talk to the API server as the user,
+// and list objects in each namespace the user has a role in when the user may not list them in all namespaces, as users
+// that only have roles in orgs and spaces may not.
+func BuildNamespaceScopedCRClient(
+	identityProvider IdentityProvider,
+	nsProvider AuthorizedNamespacesProvider,
+) func(*rest.Config, string) (client.Client, error) {
+	return func(config *rest.Config, authorizationHeader string) (client.Client, error) {
+		userClient, err := BuildCRClient(config, authorizationHeader)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNamespaceScopedClient(userClient, authorizationHeader, identityProvider, nsProvider), nil
+	}
+}
+
+// NewNamespaceScopedClient wraps the client of the user identified by authorizationHeader. The namespaces of the user
+// are only looked up when a List in all namespaces is forbidden.
+func NewNamespaceScopedClient(
+	userClient client.Client,
+	authorizationHeader string,
+	identityProvider IdentityProvider,
+	nsProvider AuthorizedNamespacesProvider,
+) client.Client {
+	return &namespaceScopedClient{
+		Client:              userClient,
+		authorizationHeader: authorizationHeader,
+		identityProvider:    identityProvider,
+		nsProvider:          nsProvider,
+	}
+}
+
+type namespaceScopedClient struct {
+	client.Client
+	authorizationHeader string
+	identityProvider    IdentityProvider
+	nsProvider          AuthorizedNamespacesProvider
+}
+
+func (c *namespaceScopedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	err := c.Client.List(ctx, list, opts...)
+	if !k8serrors.IsForbidden(err) {
+		return err
+	}
+
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Namespace != "" {
+		return err
+	}
+
+	identity, err := c.identityProvider.GetIdentity(ctx, c.authorizationHeader)
+	if err != nil {
+		return err
+	}
+	namespaces, err := c.nsProvider.GetAuthorizedNamespaces(ctx, identity)
+	if err != nil {
+		return err
+	}
+	if namespaces == nil {
+		namespaces = []string{}
+	}
+
+	// a continue token only continues the List it came from, so each namespace is listed in full
+	listOpts.Limit = 0
+	listOpts.Continue = ""
+	return listInNamespaces(ctx, c.Client, list, namespaces, listOpts)
+}
@@ -0,0 +1,189 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("NamespaceScopedClient", func() {
+	var (
+		ctx              context.Context
+		namespace1       *corev1.Namespace
+		namespace2       *corev1.Namespace
+		identityProvider *fake.IdentityProvider
+		nsProvider       *fake.AuthorizedNamespacesProvider
+		userClient       client.Client
+		app1GUID         string
+		app2GUID         string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		namespace1 = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(ctx, namespace1)).To(Succeed())
+		namespace2 = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(ctx, namespace2)).To(Succeed())
+
+		app1GUID = generateGUID()
+		Expect(k8sClient.Create(ctx, initializeAppCR("app1", app1GUID, namespace1.Name))).To(Succeed())
+		Expect(k8sClient.Create(ctx, initializeAppCR("app1b", generateGUID(), namespace1.Name))).To(Succeed())
+		app2GUID = generateGUID()
+		Expect(k8sClient.Create(ctx, initializeAppCR("app2", app2GUID, namespace2.Name))).To(Succeed())
+
+		identityProvider = new(fake.IdentityProvider)
+		identityProvider.GetIdentityReturns(authorization.Identity{Name: "space-developer", Kind: "User"}, nil)
+		nsProvider = new(fake.AuthorizedNamespacesProvider)
+		nsProvider.GetAuthorizedNamespacesReturns([]string{namespace1.Name}, nil)
+
+		userClient = NewNamespaceScopedClient(
+			namespaceScopedUserClient{Client: k8sClient, namespaces: map[string]bool{namespace1.Name: true}},
+			"Bearer space-developer-token",
+			identityProvider,
+			nsProvider,
+		)
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, namespace1)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, namespace2)).To(Succeed())
+	})
+
+	It("lists the apps in the namespaces the user has a role in", func() {
+		apps, totalResults, err := NewAppRepo(NewGUIDNamespaceCache(), 0).FetchAppList(ctx, userClient, AppListMessage{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(totalResults).To(Equal(2))
+		Expect(apps).To(ConsistOf(
+			MatchFields(IgnoreExtras, Fields{"Name": Equal("app1")}),
+			MatchFields(IgnoreExtras, Fields{"Name": Equal("app1b")}),
+		))
+
+		_, identity := nsProvider.GetAuthorizedNamespacesArgsForCall(0)
+		Expect(identity.Name).To(Equal("space-developer"))
+		_, authorizationHeader := identityProvider.GetIdentityArgsForCall(0)
+		Expect(authorizationHeader).To(Equal("Bearer space-developer-token"))
+	})
+
+	It("lists a page of the apps", func() {
+		apps, totalResults, err := NewAppRepo(NewGUIDNamespaceCache(), 0).FetchAppList(ctx, userClient, AppListMessage{
+			Page: PageRequest{Page: 2, PerPage: 1},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(totalResults).To(Equal(2))
+		Expect(apps).To(HaveLen(1))
+	})
+
+	It("fetches an app by GUID only in the namespaces the user has a role in", func() {
+		appRepo := NewAppRepo(NewGUIDNamespaceCache(), 0)
+
+		app, err := appRepo.FetchApp(ctx, userClient, app1GUID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(app.Name).To(Equal("app1"))
+
+		_, err = appRepo.FetchApp(ctx, userClient, app2GUID)
+		Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+	})
+
+	It("fetches the tasks in the namespaces the user has a role in", func() {
+		taskGUID := generateGUID()
+		Expect(k8sClient.Create(ctx, &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      taskGUID,
+				Namespace: namespace1.Name,
+				Labels:    map[string]string{TaskLabel: "true"},
+			},
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers:    []corev1.Container{{Name: "task", Image: "my-image"}},
+					},
+				},
+			},
+		})).To(Succeed())
+
+		taskRepo := NewTaskRepo(NewGUIDNamespaceCache(), time.Hour)
+		tasks, err := taskRepo.FetchTaskList(ctx, userClient, TaskListMessage{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks).To(ConsistOf(MatchFields(IgnoreExtras, Fields{"GUID": Equal(taskGUID)})))
+
+		task, err := taskRepo.FetchTask(ctx, userClient, taskGUID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.SpaceGUID).To(Equal(namespace1.Name))
+	})
+
+	When("the user has no role in any namespace", func() {
+		BeforeEach(func() {
+			nsProvider.GetAuthorizedNamespacesReturns(nil, nil)
+		})
+
+		It("lists nothing", func() {
+			apps, totalResults, err := NewAppRepo(NewGUIDNamespaceCache(), 0).FetchAppList(ctx, userClient, AppListMessage{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(totalResults).To(BeZero())
+			Expect(apps).To(BeEmpty())
+		})
+	})
+
+	When("the user may list in all namespaces", func() {
+		BeforeEach(func() {
+			userClient = NewNamespaceScopedClient(k8sClient, "Bearer admin-token", identityProvider, nsProvider)
+		})
+
+		It("lists all namespaces at once", func() {
+			_, totalResults, err := NewAppRepo(NewGUIDNamespaceCache(), 0).FetchAppList(ctx, userClient, AppListMessage{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(totalResults).To(BeNumerically(">=", 3))
+			Expect(nsProvider.GetAuthorizedNamespacesCallCount()).To(BeZero())
+		})
+	})
+
+	When("the namespaces of the user cannot be looked up", func() {
+		BeforeEach(func() {
+			nsProvider.GetAuthorizedNamespacesReturns(nil, errors.New("boom"))
+		})
+
+		It("returns the error", func() {
+			_, _, err := NewAppRepo(NewGUIDNamespaceCache(), 0).FetchAppList(ctx, userClient, AppListMessage{})
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+})
+
+// namespaceScopedUserClient is the client of a user who only has roles in namespaces, and may not list anything in all
+// namespaces
+type namespaceScopedUserClient struct {
+	client.Client
+	namespaces map[string]bool
+}
+
+func (c namespaceScopedUserClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if !c.namespaces[key.Namespace] {
+		return k8serrors.NewForbidden(schema.GroupResource{}, key.Name, errors.New("no role in namespace"))
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c namespaceScopedUserClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if !c.namespaces[listOpts.Namespace] {
+		return k8serrors.NewForbidden(schema.GroupResource{}, "", errors.New("no role in namespace"))
+	}
+	return c.Client.List(ctx, list, opts...)
+}
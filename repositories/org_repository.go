@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -12,7 +13,7 @@ import (
 	"sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
 )
 
-//+kubebuilder:rbac:groups=hnc.x-k8s.io,resources=subnamespaceanchors,verbs=list;create
+//+kubebuilder:rbac:groups=hnc.x-k8s.io,resources=subnamespaceanchors,verbs=list;watch;create;patch
 
 const (
 	OrgNameLabel   = "cloudfoundry.org/org-name"
@@ -56,11 +57,10 @@ func NewOrgRepo(rootNamespace string, privilegedClient client.WithWatch, timeout
 func (r *OrgRepo) CreateOrg(ctx context.Context, org OrgRecord) (OrgRecord, error) {
 	anchor, err := r.createSubnamespaceAnchor(ctx, &v1alpha2.SubnamespaceAnchor{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      org.GUID,
-			Namespace: r.rootNamespace,
-			Labels: map[string]string{
-				OrgNameLabel: org.Name,
-			},
+			Name:        org.GUID,
+			Namespace:   r.rootNamespace,
+			Labels:      withLabel(org.Labels, OrgNameLabel, org.Name),
+			Annotations: org.Annotations,
 		},
 	})
 	if err != nil {
@@ -77,11 +77,10 @@ func (r *OrgRepo) CreateOrg(ctx context.Context, org OrgRecord) (OrgRecord, erro
 func (r *OrgRepo) CreateSpace(ctx context.Context, space SpaceRecord) (SpaceRecord, error) {
 	anchor, err := r.createSubnamespaceAnchor(ctx, &v1alpha2.SubnamespaceAnchor{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      space.GUID,
-			Namespace: space.OrganizationGUID,
-			Labels: map[string]string{
-				SpaceNameLabel: space.Name,
-			},
+			Name:        space.GUID,
+			Namespace:   space.OrganizationGUID,
+			Labels:      withLabel(space.Labels, SpaceNameLabel, space.Name),
+			Annotations: space.Annotations,
 		},
 	})
 	if err != nil {
@@ -98,6 +97,9 @@ func (r *OrgRepo) CreateSpace(ctx context.Context, space SpaceRecord) (SpaceReco
 func (r *OrgRepo) createSubnamespaceAnchor(ctx context.Context, anchor *v1alpha2.SubnamespaceAnchor) (*v1alpha2.SubnamespaceAnchor, error) {
 	err := r.privilegedClient.Create(ctx, anchor)
 	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, NotFoundError{Err: err}
+		}
 		return nil, fmt.Errorf("failed to create subnamespaceanchor: %w", err)
 	}
 
@@ -135,21 +137,26 @@ func (r *OrgRepo) createSubnamespaceAnchor(ctx context.Context, anchor *v1alpha2
 	return createdAnchor, nil
 }
 
-func (r *OrgRepo) FetchOrgs(ctx context.Context, names []string) ([]OrgRecord, error) {
+// FetchOrgs returns the orgs with the given names that match labelSelector. Empty names and a nil labelSelector
+// match every org.
+func (r *OrgRepo) FetchOrgs(ctx context.Context, names []string, labelSelector labels.Selector) ([]OrgRecord, error) {
 	subnamespaceAnchorList := &v1alpha2.SubnamespaceAnchorList{}
 
-	options := []client.ListOption{client.InNamespace(r.rootNamespace)}
+	selector := labels.NewSelector()
+	if labelSelector != nil {
+		selector = labelSelector
+	}
 	if len(names) > 0 {
 		namesRequirement, err := labels.NewRequirement(OrgNameLabel, selection.In, names)
 		if err != nil {
 			return nil, err
 		}
-		namesSelector := client.MatchingLabelsSelector{
-			Selector: labels.NewSelector().Add(*namesRequirement),
-		}
-		options = append(options, namesSelector)
+		selector = selector.Add(*namesRequirement)
 	}
 
+	options := []client.ListOption{client.InNamespace(r.rootNamespace)}
+	options = append(options, labelSelectorOptions(selector)...)
+
 	err := r.privilegedClient.List(ctx, subnamespaceAnchorList, options...)
 	if err != nil {
 		return nil, err
@@ -161,32 +168,24 @@ func (r *OrgRepo) FetchOrgs(ctx context.Context, names []string) ([]OrgRecord, e
 			continue
 		}
 
-		records = append(records, OrgRecord{
-			Name:      anchor.Labels[OrgNameLabel],
-			GUID:      anchor.Name,
-			CreatedAt: anchor.CreationTimestamp.Time,
-			UpdatedAt: anchor.CreationTimestamp.Time,
-		})
+		records = append(records, anchorToOrgRecord(anchor))
 	}
 
 	return records, nil
 }
 
-func (r *OrgRepo) FetchSpaces(ctx context.Context, organizationGUIDs, names []string) ([]SpaceRecord, error) {
-	subnamespaceAnchorList := &v1alpha2.SubnamespaceAnchorList{}
-
-	err := r.privilegedClient.List(ctx, subnamespaceAnchorList)
+// FetchSpaces returns the spaces of the given orgs with the given names that match labelSelector. Empty filters and
+// a nil labelSelector match every space.
+func (r *OrgRepo) FetchSpaces(ctx context.Context, organizationGUIDs, names []string, labelSelector labels.Selector) ([]SpaceRecord, error) {
+	orgAnchorList := &v1alpha2.SubnamespaceAnchorList{}
+	err := r.privilegedClient.List(ctx, orgAnchorList, client.InNamespace(r.rootNamespace))
 	if err != nil {
 		return nil, err
 	}
 
 	orgsFilter := toMap(organizationGUIDs)
 	orgUIDs := map[string]struct{}{}
-	for _, anchor := range subnamespaceAnchorList.Items {
-		if anchor.Namespace != r.rootNamespace {
-			continue
-		}
-
+	for _, anchor := range orgAnchorList.Items {
 		if !matchFilter(orgsFilter, anchor.Name) {
 			continue
 		}
@@ -194,9 +193,15 @@ func (r *OrgRepo) FetchSpaces(ctx context.Context, organizationGUIDs, names []st
 		orgUIDs[anchor.Name] = struct{}{}
 	}
 
+	spaceAnchorList := &v1alpha2.SubnamespaceAnchorList{}
+	err = r.privilegedClient.List(ctx, spaceAnchorList, labelSelectorOptions(labelSelector)...)
+	if err != nil {
+		return nil, err
+	}
+
 	nameFilter := toMap(names)
 	records := []SpaceRecord{}
-	for _, anchor := range subnamespaceAnchorList.Items {
+	for _, anchor := range spaceAnchorList.Items {
 		if anchor.Status.State != v1alpha2.Ok {
 			continue
 		}
@@ -210,18 +215,119 @@ func (r *OrgRepo) FetchSpaces(ctx context.Context, organizationGUIDs, names []st
 			continue
 		}
 
-		records = append(records, SpaceRecord{
-			Name:             spaceName,
-			GUID:             anchor.Name,
-			OrganizationGUID: anchor.Namespace,
-			CreatedAt:        anchor.CreationTimestamp.Time,
-			UpdatedAt:        anchor.CreationTimestamp.Time,
-		})
+		records = append(records, anchorToSpaceRecord(anchor))
 	}
 
 	return records, nil
 }
 
+// PatchOrgMetadata applies a metadata patch to the SubnamespaceAnchor of an org
+func (r *OrgRepo) PatchOrgMetadata(ctx context.Context, message MetadataPatchMessage) (OrgRecord, error) {
+	anchor := &v1alpha2.SubnamespaceAnchor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.GUID,
+			Namespace: r.rootNamespace,
+		},
+	}
+	err := patchMetadata(ctx, r.privilegedClient, anchor, message.MetadataPatch)
+	if err != nil {
+		return OrgRecord{}, err
+	}
+
+	return anchorToOrgRecord(*anchor), nil
+}
+
+// FetchSpace returns the space with the given GUID
+func (r *OrgRepo) FetchSpace(ctx context.Context, spaceGUID string) (SpaceRecord, error) {
+	anchor, err := r.fetchSpaceAnchor(ctx, spaceGUID)
+	if err != nil {
+		return SpaceRecord{}, err
+	}
+
+	return anchorToSpaceRecord(*anchor), nil
+}
+
+// PatchSpaceMetadata applies a metadata patch to the SubnamespaceAnchor of a space, which lives in the namespace of its org
+func (r *OrgRepo) PatchSpaceMetadata(ctx context.Context, message MetadataPatchMessage) (SpaceRecord, error) {
+	anchor, err := r.fetchSpaceAnchor(ctx, message.GUID)
+	if err != nil {
+		return SpaceRecord{}, err
+	}
+
+	err = patchMetadata(ctx, r.privilegedClient, anchor, message.MetadataPatch)
+	if err != nil {
+		return SpaceRecord{}, err
+	}
+
+	return anchorToSpaceRecord(*anchor), nil
+}
+
+// fetchSpaceAnchor returns the SubnamespaceAnchor of a space. Spaces are looked up in every org, as the GUID of a space
+// doesn't say which org it belongs to.
+func (r *OrgRepo) fetchSpaceAnchor(ctx context.Context, spaceGUID string) (*v1alpha2.SubnamespaceAnchor, error) {
+	anchorList := &v1alpha2.SubnamespaceAnchorList{}
+	err := r.privilegedClient.List(ctx, anchorList)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range anchorList.Items {
+		if anchorList.Items[i].Name == spaceGUID && anchorList.Items[i].Namespace != r.rootNamespace {
+			return &anchorList.Items[i], nil
+		}
+	}
+	return nil, NotFoundError{}
+}
+
+func anchorToOrgRecord(anchor v1alpha2.SubnamespaceAnchor) OrgRecord {
+	return OrgRecord{
+		Name:        anchor.Labels[OrgNameLabel],
+		GUID:        anchor.Name,
+		Labels:      withoutLabel(anchor.Labels, OrgNameLabel),
+		Annotations: anchor.Annotations,
+		CreatedAt:   anchor.CreationTimestamp.Time,
+		UpdatedAt:   anchor.CreationTimestamp.Time,
+	}
+}
+
+func anchorToSpaceRecord(anchor v1alpha2.SubnamespaceAnchor) SpaceRecord {
+	return SpaceRecord{
+		Name:             anchor.Labels[SpaceNameLabel],
+		GUID:             anchor.Name,
+		OrganizationGUID: anchor.Namespace,
+		Labels:           withoutLabel(anchor.Labels, SpaceNameLabel),
+		Annotations:      anchor.Annotations,
+		CreatedAt:        anchor.CreationTimestamp.Time,
+		UpdatedAt:        anchor.CreationTimestamp.Time,
+	}
+}
+
+// withLabel copies the user labels of an org or space and adds the label that holds its name
+func withLabel(labels map[string]string, key, value string) map[string]string {
+	result := map[string]string{key: value}
+	for k, v := range labels {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// withoutLabel copies labels without the label that holds the name of an org or space, which is not user metadata
+func withoutLabel(labels map[string]string, key string) map[string]string {
+	var result map[string]string
+	for k, v := range labels {
+		if k == key {
+			continue
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[k] = v
+	}
+	return result
+}
+
 func matchFilter(filter map[string]struct{}, value string) bool {
 	if len(filter) == 0 {
 		return true
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 //counterfeiter:generate -o fake -fake-name CFOrgRepository . CFOrgRepository
@@ -11,7 +13,8 @@ import (
 
 type CFOrgRepository interface {
 	CreateOrg(context context.Context, org OrgRecord) (OrgRecord, error)
-	FetchOrgs(context context.Context, orgNames []string) ([]OrgRecord, error)
+	FetchOrgs(context context.Context, orgNames []string, labelSelector labels.Selector) ([]OrgRecord, error)
+	PatchOrgMetadata(context context.Context, message MetadataPatchMessage) (OrgRecord, error)
 }
 
 type AuthorizedNamespacesProvider interface {
@@ -36,8 +39,8 @@ func NewOrgRepoAuthDecorator(
 	}
 }
 
-func (r *OrgRepoAuthDecorator) FetchOrgs(ctx context.Context, names []string) ([]OrgRecord, error) {
-	orgs, err := r.CFOrgRepository.FetchOrgs(ctx, names)
+func (r *OrgRepoAuthDecorator) FetchOrgs(ctx context.Context, names []string, labelSelector labels.Selector) ([]OrgRecord, error) {
+	orgs, err := r.CFOrgRepository.FetchOrgs(ctx, names, labelSelector)
 	if err != nil {
 		return nil, err
 	}
@@ -60,3 +63,16 @@ func (r *OrgRepoAuthDecorator) FetchOrgs(ctx context.Context, names []string) ([
 
 	return result, nil
 }
+
+func (r *OrgRepoAuthDecorator) PatchOrgMetadata(ctx context.Context, message MetadataPatchMessage) (OrgRecord, error) {
+	authorizedNamespaces, err := r.nsProvider.GetAuthorizedNamespaces(ctx, r.identity)
+	if err != nil {
+		return OrgRecord{}, err
+	}
+
+	if _, ok := toMap(authorizedNamespaces)[message.GUID]; !ok {
+		return OrgRecord{}, NotFoundError{}
+	}
+
+	return r.CFOrgRepository.PatchOrgMetadata(ctx, message)
+}
@@ -80,7 +80,7 @@ var _ = Describe("OrgRepositoryAuthDecorator", func() {
 		})
 
 		JustBeforeEach(func() {
-			orgs, err = orgRepoAuthDecorator.FetchOrgs(context.Background(), []string{"foo", "bar"})
+			orgs, err = orgRepoAuthDecorator.FetchOrgs(context.Background(), []string{"foo", "bar"}, nil)
 		})
 
 		It("fetches orgs associated with the identity only", func() {
@@ -108,4 +108,54 @@ var _ = Describe("OrgRepositoryAuthDecorator", func() {
 			})
 		})
 	})
+
+	Describe("patching org metadata", func() {
+		var (
+			orgGUID string
+			org     repositories.OrgRecord
+		)
+
+		BeforeEach(func() {
+			orgRepoAuthDecorator, err = orgRepoProvider.OrgRepoForRequest(&http.Request{
+				Header: http.Header{
+					headers.Authorization: []string{"bearer the-token"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			orgGUID = "org2"
+			orgRepo.PatchOrgMetadataReturns(repositories.OrgRecord{GUID: "org2"}, nil)
+		})
+
+		JustBeforeEach(func() {
+			org, err = orgRepoAuthDecorator.PatchOrgMetadata(context.Background(), repositories.MetadataPatchMessage{GUID: orgGUID})
+		})
+
+		It("patches orgs associated with the identity", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(org).To(Equal(repositories.OrgRecord{GUID: "org2"}))
+			Expect(orgRepo.PatchOrgMetadataCallCount()).To(Equal(1))
+		})
+
+		When("the org is not associated with the identity", func() {
+			BeforeEach(func() {
+				orgGUID = "org1"
+			})
+
+			It("returns a NotFoundError without patching the org", func() {
+				Expect(err).To(BeAssignableToTypeOf(repositories.NotFoundError{}))
+				Expect(orgRepo.PatchOrgMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("fetching authorized namespaces fails", func() {
+			BeforeEach(func() {
+				nsProvider.GetAuthorizedNamespacesReturns(nil, errors.New("fetch-auth-ns-failed"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("fetch-auth-ns-failed"))
+			})
+		})
+	})
 })
@@ -160,6 +160,17 @@ var _ = Describe("OrgRepository", func() {
 				})
 			})
 
+			When("the org namespace does not exist yet", func() {
+				It("returns a NotFoundError", func() {
+					_, err := orgRepo.CreateSpace(ctx, repositories.SpaceRecord{
+						GUID:             "some-guid",
+						Name:             "our-space",
+						OrganizationGUID: "not-an-org",
+					})
+					Expect(err).To(BeAssignableToTypeOf(repositories.NotFoundError{}))
+				})
+			})
+
 			When("the client fails to create the space", func() {
 				It("returns an error", func() {
 					_, err := orgRepo.CreateSpace(ctx, repositories.SpaceRecord{
@@ -190,7 +201,7 @@ var _ = Describe("OrgRepository", func() {
 
 		Describe("Orgs", func() {
 			It("returns the 3 orgs", func() {
-				orgs, err := orgRepo.FetchOrgs(ctx, nil)
+				orgs, err := orgRepo.FetchOrgs(ctx, nil, nil)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(orgs).To(ConsistOf(
@@ -223,7 +234,7 @@ var _ = Describe("OrgRepository", func() {
 				})
 
 				It("does not list it", func() {
-					orgs, err := orgRepo.FetchOrgs(ctx, nil)
+					orgs, err := orgRepo.FetchOrgs(ctx, nil, nil)
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(orgs).NotTo(ContainElement(
@@ -239,7 +250,7 @@ var _ = Describe("OrgRepository", func() {
 
 			When("we filter for org1 and org3", func() {
 				It("returns just those", func() {
-					orgs, err := orgRepo.FetchOrgs(ctx, []string{"org1", "org3"})
+					orgs, err := orgRepo.FetchOrgs(ctx, []string{"org1", "org3"}, nil)
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(orgs).To(ConsistOf(
@@ -258,6 +269,19 @@ var _ = Describe("OrgRepository", func() {
 					))
 				})
 			})
+
+			When("we filter with a label selector", func() {
+				It("returns the orgs that match both the names and the selector", func() {
+					selector, err := labels.Parse(repositories.OrgNameLabel + " notin (org1)")
+					Expect(err).NotTo(HaveOccurred())
+
+					orgs, err := orgRepo.FetchOrgs(ctx, []string{"org1", "org3"}, selector)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(orgs).To(ConsistOf(
+						MatchFields(IgnoreExtras, Fields{"GUID": Equal(org3Anchor.Name)}),
+					))
+				})
+			})
 		})
 
 		Describe("Spaces", func() {
@@ -293,7 +317,7 @@ var _ = Describe("OrgRepository", func() {
 			})
 
 			It("returns the 6 spaces", func() {
-				spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{})
+				spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{}, nil)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(spaces).To(ConsistOf(
@@ -350,7 +374,7 @@ var _ = Describe("OrgRepository", func() {
 				})
 
 				It("does not list it", func() {
-					spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{}, nil)
 					Expect(err).NotTo(HaveOccurred())
 
 					Expect(spaces).NotTo(ContainElement(
@@ -367,7 +391,7 @@ var _ = Describe("OrgRepository", func() {
 
 			When("filtering by org guids", func() {
 				It("only retruns the spaces belonging to the specified org guids", func() {
-					spaces, err := orgRepo.FetchSpaces(ctx, []string{string(org1Anchor.Name), string(org3Anchor.Name), "does-not-exist"}, []string{})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{string(org1Anchor.Name), string(org3Anchor.Name), "does-not-exist"}, []string{}, nil)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(spaces).To(ConsistOf(
 						MatchFields(IgnoreExtras, Fields{
@@ -386,7 +410,7 @@ var _ = Describe("OrgRepository", func() {
 
 			When("filtering by space names", func() {
 				It("only retruns the spaces matching the specified names", func() {
-					spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{"space1", "space3", "does-not-exist"})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{"space1", "space3", "does-not-exist"}, nil)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(spaces).To(ConsistOf(
 						MatchFields(IgnoreExtras, Fields{
@@ -408,7 +432,7 @@ var _ = Describe("OrgRepository", func() {
 
 			When("filtering by org guids and space names", func() {
 				It("only retruns the spaces matching the specified names", func() {
-					spaces, err := orgRepo.FetchSpaces(ctx, []string{string(org1Anchor.Name), string(org2Anchor.Name)}, []string{"space1", "space2", "space4"})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{string(org1Anchor.Name), string(org2Anchor.Name)}, []string{"space1", "space2", "space4"}, nil)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(spaces).To(ConsistOf(
 						MatchFields(IgnoreExtras, Fields{
@@ -426,7 +450,7 @@ var _ = Describe("OrgRepository", func() {
 
 			When("filtering by space names that don't exist", func() {
 				It("only retruns the spaces matching the specified names", func() {
-					spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{"does-not-exist", "still-does-not-exist"})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{}, []string{"does-not-exist", "still-does-not-exist"}, nil)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(spaces).To(BeEmpty())
 				})
@@ -434,11 +458,130 @@ var _ = Describe("OrgRepository", func() {
 
 			When("filtering by org uids that don't exist", func() {
 				It("only retruns the spaces matching the specified names", func() {
-					spaces, err := orgRepo.FetchSpaces(ctx, []string{"does-not-exist", "still-does-not-exist"}, []string{})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{"does-not-exist", "still-does-not-exist"}, []string{}, nil)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(spaces).To(BeEmpty())
 				})
 			})
+
+			When("filtering by a label selector", func() {
+				It("only returns the spaces of the orgs that match the selector", func() {
+					selector := labels.SelectorFromSet(labels.Set{repositories.SpaceNameLabel: "space1"})
+					spaces, err := orgRepo.FetchSpaces(ctx, []string{org1Anchor.Name}, []string{}, selector)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(spaces).To(ConsistOf(
+						MatchFields(IgnoreExtras, Fields{"GUID": Equal(space11Anchor.Name)}),
+					))
+				})
+			})
+		})
+	})
+
+	Describe("FetchSpace", func() {
+		var (
+			orgAnchor   *hnsv1alpha2.SubnamespaceAnchor
+			spaceAnchor *hnsv1alpha2.SubnamespaceAnchor
+		)
+
+		BeforeEach(func() {
+			orgAnchor = createOrgAnchor("org")
+			spaceAnchor = &hnsv1alpha2.SubnamespaceAnchor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      uuid.NewString(),
+					Namespace: orgAnchor.Name,
+					Labels:    map[string]string{repositories.SpaceNameLabel: "space"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, spaceAnchor)).To(Succeed())
+		})
+
+		It("returns the space", func() {
+			space, err := orgRepo.FetchSpace(ctx, spaceAnchor.Name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(space.GUID).To(Equal(spaceAnchor.Name))
+			Expect(space.Name).To(Equal("space"))
+			Expect(space.OrganizationGUID).To(Equal(orgAnchor.Name))
+		})
+
+		When("the GUID is the GUID of an org", func() {
+			It("returns a NotFoundError", func() {
+				_, err := orgRepo.FetchSpace(ctx, orgAnchor.Name)
+				Expect(err).To(BeAssignableToTypeOf(repositories.NotFoundError{}))
+			})
+		})
+	})
+
+	Describe("PatchMetadata", func() {
+		var (
+			orgAnchor *hnsv1alpha2.SubnamespaceAnchor
+			prod      string
+		)
+
+		BeforeEach(func() {
+			prod = "prod"
+			orgAnchor = createOrgAnchor("org")
+		})
+
+		Describe("Org", func() {
+			It("patches the labels and annotations of the org anchor", func() {
+				org, err := orgRepo.PatchOrgMetadata(ctx, repositories.MetadataPatchMessage{
+					GUID: orgAnchor.Name,
+					MetadataPatch: repositories.MetadataPatch{
+						Labels:      map[string]*string{"env": &prod},
+						Annotations: map[string]*string{"example.org/owner": &prod},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(org.Name).To(Equal("org"))
+				Expect(org.Labels).To(Equal(map[string]string{"env": "prod"}))
+				Expect(org.Annotations).To(Equal(map[string]string{"example.org/owner": "prod"}))
+
+				anchor := &hnsv1alpha2.SubnamespaceAnchor{}
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(orgAnchor), anchor)).To(Succeed())
+				Expect(anchor.Labels).To(Equal(map[string]string{repositories.OrgNameLabel: "org", "env": "prod"}))
+			})
+
+			When("the org does not exist", func() {
+				It("returns a NotFoundError", func() {
+					_, err := orgRepo.PatchOrgMetadata(ctx, repositories.MetadataPatchMessage{GUID: "no-such-org"})
+					Expect(err).To(BeAssignableToTypeOf(repositories.NotFoundError{}))
+				})
+			})
+		})
+
+		Describe("Space", func() {
+			var spaceAnchor *hnsv1alpha2.SubnamespaceAnchor
+
+			BeforeEach(func() {
+				spaceAnchor = &hnsv1alpha2.SubnamespaceAnchor{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      uuid.NewString(),
+						Namespace: orgAnchor.Name,
+						Labels:    map[string]string{repositories.SpaceNameLabel: "space", "tier": "web"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, spaceAnchor)).To(Succeed())
+			})
+
+			It("patches the labels of the space anchor", func() {
+				space, err := orgRepo.PatchSpaceMetadata(ctx, repositories.MetadataPatchMessage{
+					GUID: spaceAnchor.Name,
+					MetadataPatch: repositories.MetadataPatch{
+						Labels: map[string]*string{"env": &prod, "tier": nil},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(space.Name).To(Equal("space"))
+				Expect(space.OrganizationGUID).To(Equal(orgAnchor.Name))
+				Expect(space.Labels).To(Equal(map[string]string{"env": "prod"}))
+			})
+
+			When("the GUID is the GUID of an org", func() {
+				It("returns a NotFoundError", func() {
+					_, err := orgRepo.PatchSpaceMetadata(ctx, repositories.MetadataPatchMessage{GUID: orgAnchor.Name})
+					Expect(err).To(BeAssignableToTypeOf(repositories.NotFoundError{}))
+				})
+			})
 		})
 	})
 })
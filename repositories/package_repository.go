@@ -40,16 +40,24 @@ type PackageUpdateSourceMessage struct {
 }
 
 type PackageRecord struct {
-	GUID      string
-	Type      string
-	AppGUID   string
-	SpaceGUID string
-	State     string
-	CreatedAt string
-	UpdatedAt string
+	GUID        string
+	Type        string
+	AppGUID     string
+	SpaceGUID   string
+	State       string
+	Labels      map[string]string
+	Annotations map[string]string
+	CreatedAt   string
+	UpdatedAt   string
 }
 
-type PackageRepo struct{}
+type PackageRepo struct {
+	namespaceCache *GUIDNamespaceCache
+}
+
+func NewPackageRepo(namespaceCache *GUIDNamespaceCache) *PackageRepo {
+	return &PackageRepo{namespaceCache: namespaceCache}
+}
 
 func (r *PackageRepo) CreatePackage(ctx context.Context, client client.Client, message PackageCreateMessage) (PackageRecord, error) {
 	cfPackage := packageCreateToCFPackage(message)
@@ -57,16 +65,29 @@ func (r *PackageRepo) CreatePackage(ctx context.Context, client client.Client, m
 	if err != nil {
 		return PackageRecord{}, err
 	}
+	r.namespaceCache.Set(cfPackage.Name, cfPackage.Namespace)
 	return cfPackageToPackageRecord(cfPackage), nil
 }
 
 func (r *PackageRepo) FetchPackage(ctx context.Context, client client.Client, guid string) (PackageRecord, error) {
+	cfPackage := &workloadsv1alpha1.CFPackage{}
+	found, err := r.namespaceCache.fetch(ctx, client, guid, cfPackage)
+	if err != nil {
+		return PackageRecord{}, err
+	}
+	if found {
+		return cfPackageToPackageRecord(*cfPackage), nil
+	}
+
 	packageList := &workloadsv1alpha1.CFPackageList{}
-	err := client.List(ctx, packageList)
+	err = client.List(ctx, packageList)
 	if err != nil { // untested
 		return PackageRecord{}, err
 	}
 	allPackages := packageList.Items
+	for _, pkg := range allPackages {
+		r.namespaceCache.Set(pkg.Name, pkg.Namespace)
+	}
 	matches := filterPackagesByMetadataName(allPackages, guid)
 
 	return returnPackage(matches)
@@ -92,6 +113,21 @@ func (r *PackageRepo) UpdatePackageSource(ctx context.Context, c client.Client,
 	return record, nil
 }
 
+func (r *PackageRepo) PatchPackageMetadata(ctx context.Context, c client.Client, message MetadataPatchMessage) (PackageRecord, error) {
+	cfPackage := &workloadsv1alpha1.CFPackage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.GUID,
+			Namespace: message.SpaceGUID,
+		},
+	}
+
+	err := patchMetadata(ctx, c, cfPackage, message.MetadataPatch)
+	if err != nil {
+		return PackageRecord{}, err
+	}
+	return cfPackageToPackageRecord(*cfPackage), nil
+}
+
 func packageCreateToCFPackage(message PackageCreateMessage) workloadsv1alpha1.CFPackage {
 	guid := uuid.New().String()
 	return workloadsv1alpha1.CFPackage{
@@ -119,13 +155,15 @@ func cfPackageToPackageRecord(cfPackage workloadsv1alpha1.CFPackage) PackageReco
 		state = PackageStateReady
 	}
 	return PackageRecord{
-		GUID:      cfPackage.ObjectMeta.Name,
-		SpaceGUID: cfPackage.ObjectMeta.Namespace,
-		Type:      string(cfPackage.Spec.Type),
-		AppGUID:   cfPackage.Spec.AppRef.Name,
-		State:     state,
-		CreatedAt: formatTimestamp(cfPackage.CreationTimestamp),
-		UpdatedAt: updatedAtTime,
+		GUID:        cfPackage.ObjectMeta.Name,
+		SpaceGUID:   cfPackage.ObjectMeta.Namespace,
+		Type:        string(cfPackage.Spec.Type),
+		AppGUID:     cfPackage.Spec.AppRef.Name,
+		State:       state,
+		Labels:      cfPackage.Labels,
+		Annotations: cfPackage.Annotations,
+		CreatedAt:   formatTimestamp(cfPackage.CreationTimestamp),
+		UpdatedAt:   updatedAtTime,
 	}
 }
 
@@ -33,7 +33,7 @@ var _ = Describe("PackageRepository", func() {
 			packageRepo = new(PackageRepo)
 
 			var err error
-			client, err = BuildCRClient(k8sConfig)
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			packageCreate = PackageCreateMessage{
@@ -113,7 +113,7 @@ var _ = Describe("PackageRepository", func() {
 
 			packageRepo = new(PackageRepo)
 			var err error
-			testClient, err = BuildCRClient(k8sConfig)
+			testClient, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -322,7 +322,7 @@ var _ = Describe("PackageRepository", func() {
 			ctx := context.Background()
 
 			var err error
-			client, err = BuildCRClient(k8sConfig)
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			existingCFPackage = workloadsv1alpha1.CFPackage{
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PageRequest selects a single page of a list. The zero value selects the whole list.
+type PageRequest struct {
+	Page    int
+	PerPage int
+}
+
+// Bounds returns the indexes of the first item of the page and the item after the last one, in a list of totalResults items
+func (p PageRequest) Bounds(totalResults int) (int, int) {
+	if p.PerPage == 0 {
+		return 0, totalResults
+	}
+
+	start := (p.Page - 1) * p.PerPage
+	if start > totalResults {
+		start = totalResults
+	}
+	end := start + p.PerPage
+	if end > totalResults {
+		end = totalResults
+	}
+
+	return start, end
+}
+
+// listPage fills list with the page of objects selected by page and returns the total number of objects.
+// The API server is read in chunks of page.PerPage objects using Limit and Continue, so that only the chunks up to the
+// requested page are transferred when the API server knows how many objects remain.
+func listPage(ctx context.Context, k8sClient client.Client, list client.ObjectList, page PageRequest, opts ...client.ListOption) (int, error) {
+	_, readsFromCache := k8sClient.(informerCacheReader)
+	if page.PerPage == 0 || readsFromCache {
+		err := k8sClient.List(ctx, list, opts...)
+		if err != nil {
+			return 0, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return 0, err
+		}
+		start, end := page.Bounds(len(items))
+		return len(items), meta.SetList(list, items[start:end])
+	}
+
+	offset := (page.Page - 1) * page.PerPage
+	pageItems := []runtime.Object{}
+	totalResults := 0
+	continueToken := ""
+	for {
+		chunk := list.DeepCopyObject().(client.ObjectList)
+		chunkOpts := append([]client.ListOption{}, opts...)
+		chunkOpts = append(chunkOpts, client.Limit(int64(page.PerPage)), client.Continue(continueToken))
+		err := k8sClient.List(ctx, chunk, chunkOpts...)
+		if err != nil {
+			return 0, err
+		}
+
+		items, err := meta.ExtractList(chunk)
+		if err != nil {
+			return 0, err
+		}
+		for _, item := range items {
+			if totalResults >= offset && len(pageItems) < page.PerPage {
+				pageItems = append(pageItems, item)
+			}
+			totalResults++
+		}
+
+		continueToken = chunk.GetContinue()
+		if continueToken == "" {
+			break
+		}
+		if remaining := chunk.GetRemainingItemCount(); remaining != nil && len(pageItems) == page.PerPage {
+			totalResults += int(*remaining)
+			break
+		}
+	}
+
+	return totalResults, meta.SetList(list, pageItems)
+}
@@ -0,0 +1,25 @@
+package repositories_test
+
+import (
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageRequest", func() {
+	DescribeTable("Bounds",
+		func(pageRequest PageRequest, totalResults, expectedStart, expectedEnd int) {
+			start, end := pageRequest.Bounds(totalResults)
+			Expect(start).To(Equal(expectedStart))
+			Expect(end).To(Equal(expectedEnd))
+		},
+		Entry("the whole list", PageRequest{}, 7, 0, 7),
+		Entry("the first page", PageRequest{Page: 1, PerPage: 3}, 7, 0, 3),
+		Entry("a page in the middle", PageRequest{Page: 2, PerPage: 3}, 7, 3, 6),
+		Entry("the last, partial page", PageRequest{Page: 3, PerPage: 3}, 7, 6, 7),
+		Entry("a page past the end", PageRequest{Page: 4, PerPage: 3}, 7, 7, 7),
+		Entry("an empty list", PageRequest{Page: 1, PerPage: 3}, 0, 0, 0),
+	)
+})
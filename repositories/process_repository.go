@@ -3,9 +3,17 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
 
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,37 +48,239 @@ type HealthCheckData struct {
 	TimeoutSeconds           int64
 }
 
-type ProcessRepository struct{}
+type ProcessRepository struct {
+	namespaceCache *GUIDNamespaceCache
+	// privilegedClient reads the org and space quotas and the processes they apply to
+	privilegedClient client.Client
+	metricsFetcher   PodMetricsFetcher
+}
+
+func NewProcessRepository(namespaceCache *GUIDNamespaceCache, privilegedClient client.Client, metricsFetcher PodMetricsFetcher) *ProcessRepository {
+	return &ProcessRepository{
+		namespaceCache:   namespaceCache,
+		privilegedClient: privilegedClient,
+		metricsFetcher:   metricsFetcher,
+	}
+}
+
+// ProcessScaleValues are the new scale of a process. Nil fields are left as they are.
+type ProcessScaleValues struct {
+	Instances *int
+	MemoryMB  *int64
+	DiskMB    *int64
+}
+
+type ProcessScaleMessage struct {
+	GUID      string
+	SpaceGUID string
+	ProcessScaleValues
+}
 
 func (r *ProcessRepository) FetchProcess(ctx context.Context, client client.Client, processGUID string) (ProcessRecord, error) {
+	cfProcess := &workloadsv1alpha1.CFProcess{}
+	found, err := r.namespaceCache.fetch(ctx, client, processGUID, cfProcess)
+	if err != nil {
+		return ProcessRecord{}, err
+	}
+	if found {
+		return cfProcessToProcessRecord(*cfProcess), nil
+	}
 
-	// TODO: Could look up namespace from guid => namespace cache to do Get
 	processList := &workloadsv1alpha1.CFProcessList{}
-	err := client.List(ctx, processList)
+	err = client.List(ctx, processList)
 	if err != nil { // untested
 		return ProcessRecord{}, err
 	}
 	allProcesses := processList.Items
+	r.cacheNamespaces(allProcesses)
 	matches := filterProcessesByMetadataName(allProcesses, processGUID)
 
 	return returnProcess(matches)
 }
 
-func (r *ProcessRepository) FetchProcessesForApp(ctx context.Context, k8sClient client.Client, appGUID, spaceGUID string) ([]ProcessRecord, error) {
+// FetchProcessesForApp returns the processes of an app that match labelSelector, which may be nil
+func (r *ProcessRepository) FetchProcessesForApp(ctx context.Context, k8sClient client.Client, appGUID, spaceGUID string, labelSelector labels.Selector) ([]ProcessRecord, error) {
 	processList := &workloadsv1alpha1.CFProcessList{}
-	options := []client.ListOption{
-		client.InNamespace(spaceGUID),
-	}
-	err := k8sClient.List(ctx, processList, options...)
+	listOptions := append(listOptionsForApp(k8sClient, spaceGUID, appGUID), labelSelectorOptions(labelSelector)...)
+	err := k8sClient.List(ctx, processList, listOptions...)
 	if err != nil { // untested
 		return []ProcessRecord{}, err
 	}
 	allProcesses := processList.Items
+	r.cacheNamespaces(allProcesses)
 	matches := filterProcessesByAppGUID(allProcesses, appGUID)
 
 	return returnProcesses(matches)
 }
 
+// ProcessPatchMessage changes the command and health check of a process. Nil fields are left as they are.
+type ProcessPatchMessage struct {
+	ProcessGUID                         string
+	SpaceGUID                           string
+	Command                             *string
+	HealthCheckType                     *string
+	HealthCheckHTTPEndpoint             *string
+	HealthCheckTimeoutSeconds           *int64
+	HealthCheckInvocationTimeoutSeconds *int64
+}
+
+// PatchProcess changes the command and health check of a process. The HTTP endpoint of the health check is removed
+// when its type changes to one that is not "http".
+func (r *ProcessRepository) PatchProcess(ctx context.Context, c client.Client, message ProcessPatchMessage) (ProcessRecord, error) {
+	cfProcess := &workloadsv1alpha1.CFProcess{}
+	err := c.Get(ctx, types.NamespacedName{Name: message.ProcessGUID, Namespace: message.SpaceGUID}, cfProcess)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ProcessRecord{}, NotFoundError{Err: err}
+		}
+		return ProcessRecord{}, err
+	}
+
+	baseCFProcess := cfProcess.DeepCopy()
+	if message.Command != nil {
+		cfProcess.Spec.Command = *message.Command
+	}
+	if message.HealthCheckType != nil {
+		cfProcess.Spec.HealthCheck.Type = workloadsv1alpha1.HealthCheckType(*message.HealthCheckType)
+		if cfProcess.Spec.HealthCheck.Type != workloadsv1alpha1.HTTPHealthCheckType {
+			cfProcess.Spec.HealthCheck.Data.HTTPEndpoint = ""
+		}
+	}
+	if message.HealthCheckHTTPEndpoint != nil {
+		cfProcess.Spec.HealthCheck.Data.HTTPEndpoint = *message.HealthCheckHTTPEndpoint
+	}
+	if message.HealthCheckTimeoutSeconds != nil {
+		cfProcess.Spec.HealthCheck.Data.TimeoutSeconds = *message.HealthCheckTimeoutSeconds
+	}
+	if message.HealthCheckInvocationTimeoutSeconds != nil {
+		cfProcess.Spec.HealthCheck.Data.InvocationTimeoutSeconds = *message.HealthCheckInvocationTimeoutSeconds
+	}
+
+	err = c.Patch(ctx, cfProcess, client.MergeFrom(baseCFProcess))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ProcessRecord{}, NotFoundError{Err: err}
+		}
+		return ProcessRecord{}, fmt.Errorf("error patching process %q: %w", message.ProcessGUID, err)
+	}
+
+	return cfProcessToProcessRecord(*cfProcess), nil
+}
+
+// ScaleProcess changes the number of instances and the memory and disk limits of a process. It returns a
+// QuotaExceededError, without changing the process, when the new scale does not fit the org or space quotas.
+func (r *ProcessRepository) ScaleProcess(ctx context.Context, c client.Client, message ProcessScaleMessage) (ProcessRecord, error) {
+	cfProcess := &workloadsv1alpha1.CFProcess{}
+	// the quotas are checked against the version of the process that is patched, so a concurrent change of the process
+	// makes the check start over
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := c.Get(ctx, types.NamespacedName{Name: message.GUID, Namespace: message.SpaceGUID}, cfProcess)
+		if err != nil {
+			return err
+		}
+
+		baseCFProcess := cfProcess.DeepCopy()
+		if message.Instances != nil {
+			cfProcess.Spec.DesiredInstances = *message.Instances
+		}
+		if message.MemoryMB != nil {
+			cfProcess.Spec.MemoryMB = *message.MemoryMB
+		}
+		if message.DiskMB != nil {
+			cfProcess.Spec.DiskQuotaMB = *message.DiskMB
+		}
+
+		err = checkProcessQuotas(ctx, r.privilegedClient, *cfProcess)
+		if err != nil {
+			return err
+		}
+
+		return c.Patch(ctx, cfProcess, client.MergeFromWithOptions(baseCFProcess, client.MergeFromWithOptimisticLock{}))
+	})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ProcessRecord{}, NotFoundError{Err: err}
+		}
+		if errors.As(err, &QuotaExceededError{}) {
+			return ProcessRecord{}, err
+		}
+		return ProcessRecord{}, fmt.Errorf("error scaling process %q: %w", message.GUID, err)
+	}
+
+	return cfProcessToProcessRecord(*cfProcess), nil
+}
+
+// ProcessCreateMessage describes a process of an app that its droplet does not create, such as one declared in a
+// manifest before the app is staged
+type ProcessCreateMessage struct {
+	AppGUID     string
+	SpaceGUID   string
+	Type        string
+	Command     string
+	Instances   int
+	MemoryMB    int64
+	DiskQuotaMB int64
+	HealthCheck HealthCheck
+}
+
+// defaultProcessPort is the port that buildpack apps listen on, which a process created ahead of its droplet exposes.
+// The workload controllers run every process with the first of its ports, so processes of every type need one.
+const defaultProcessPort = 8080
+
+// CreateProcess creates a process with the labels that the app controller gives the processes of a droplet, so the
+// controller leaves it in place once the app is staged. It returns a QuotaExceededError when the process does not fit
+// the org or space quotas.
+func (r *ProcessRepository) CreateProcess(ctx context.Context, c client.Client, message ProcessCreateMessage) (ProcessRecord, error) {
+	processGUID := uuid.NewString()
+	cfProcess := &workloadsv1alpha1.CFProcess{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      processGUID,
+			Namespace: message.SpaceGUID,
+			Labels: map[string]string{
+				workloadsv1alpha1.CFAppGUIDLabelKey:     message.AppGUID,
+				workloadsv1alpha1.CFProcessGUIDLabelKey: processGUID,
+				workloadsv1alpha1.CFProcessTypeLabelKey: message.Type,
+			},
+		},
+		Spec: workloadsv1alpha1.CFProcessSpec{
+			AppRef:      corev1.LocalObjectReference{Name: message.AppGUID},
+			ProcessType: message.Type,
+			Command:     message.Command,
+			HealthCheck: workloadsv1alpha1.HealthCheck{
+				Type: workloadsv1alpha1.HealthCheckType(message.HealthCheck.Type),
+				Data: workloadsv1alpha1.HealthCheckData{
+					HTTPEndpoint:             message.HealthCheck.Data.HTTPEndpoint,
+					InvocationTimeoutSeconds: message.HealthCheck.Data.InvocationTimeoutSeconds,
+					TimeoutSeconds:           message.HealthCheck.Data.TimeoutSeconds,
+				},
+			},
+			DesiredInstances: message.Instances,
+			MemoryMB:         message.MemoryMB,
+			DiskQuotaMB:      message.DiskQuotaMB,
+			Ports:            []int32{defaultProcessPort},
+		},
+	}
+
+	err := checkProcessQuotas(ctx, r.privilegedClient, *cfProcess)
+	if err != nil {
+		return ProcessRecord{}, err
+	}
+
+	err = c.Create(ctx, cfProcess)
+	if err != nil {
+		return ProcessRecord{}, fmt.Errorf("error creating process of type %q: %w", message.Type, err)
+	}
+	r.namespaceCache.Set(cfProcess.Name, cfProcess.Namespace)
+
+	return cfProcessToProcessRecord(*cfProcess), nil
+}
+
+func (r *ProcessRepository) cacheNamespaces(processes []workloadsv1alpha1.CFProcess) {
+	for _, process := range processes {
+		r.namespaceCache.Set(process.Name, process.Namespace)
+	}
+}
+
 func filterProcessesByMetadataName(processes []workloadsv1alpha1.CFProcess, name string) []workloadsv1alpha1.CFProcess {
 	var filtered []workloadsv1alpha1.CFProcess
 	for i, process := range processes {
@@ -8,9 +8,13 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	hnsv1alpha2 "sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
 )
 
 var _ = Describe("ProcessRepository", func() {
@@ -25,7 +29,7 @@ var _ = Describe("ProcessRepository", func() {
 
 		processRepo = new(ProcessRepository)
 		var err error
-		client, err = BuildCRClient(k8sConfig)
+		client, err = BuildPrivilegedCRClient(k8sConfig, "")
 		Expect(err).ToNot(HaveOccurred())
 	})
 
@@ -234,7 +238,7 @@ var _ = Describe("ProcessRepository", func() {
 		When("on the happy path", func() {
 
 			It("returns Process records for the AppGUID we request", func() {
-				processes, err := processRepo.FetchProcessesForApp(testCtx, client, app1GUID, namespaceGUID)
+				processes, err := processRepo.FetchProcessesForApp(testCtx, client, app1GUID, namespaceGUID, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(len(processes)).To(Equal(2))
 				By("returning a process record for each process of the app", func() {
@@ -248,7 +252,7 @@ var _ = Describe("ProcessRepository", func() {
 
 		When("no Processes exist for an app", func() {
 			It("returns an empty list", func() {
-				processes, err := processRepo.FetchProcessesForApp(testCtx, client, app2GUID, namespaceGUID)
+				processes, err := processRepo.FetchProcessesForApp(testCtx, client, app2GUID, namespaceGUID, nil)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(processes).To(BeEmpty())
 				Expect(processes).ToNot(BeNil())
@@ -257,11 +261,386 @@ var _ = Describe("ProcessRepository", func() {
 
 		When("the app does not exist", func() {
 			It("returns an empty list", func() {
-				processes, err := processRepo.FetchProcessesForApp(testCtx, client, "I don't exist", namespaceGUID)
+				processes, err := processRepo.FetchProcessesForApp(testCtx, client, "I don't exist", namespaceGUID, nil)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(processes).To(BeEmpty())
 				Expect(processes).ToNot(BeNil())
 			})
 		})
 	})
+
+	Describe("PatchProcess", func() {
+		var (
+			namespace *corev1.Namespace
+			process   *workloadsv1alpha1.CFProcess
+		)
+
+		fetchProcess := func() *workloadsv1alpha1.CFProcess {
+			updatedProcess := new(workloadsv1alpha1.CFProcess)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: process.Name, Namespace: process.Namespace}, updatedProcess)).To(Succeed())
+			return updatedProcess
+		}
+
+		BeforeEach(func() {
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+			Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+			process = initializeProcessCR(generateGUID(), namespace.Name, generateGUID())
+			process.Spec.HealthCheck = workloadsv1alpha1.HealthCheck{
+				Type: "http",
+				Data: workloadsv1alpha1.HealthCheckData{HTTPEndpoint: "/healthz", TimeoutSeconds: 30},
+			}
+			Expect(k8sClient.Create(testCtx, process)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+		})
+
+		It("changes the command and health check of the process", func() {
+			command := "bundle exec rackup"
+			endpoint := "/ready"
+			timeout := int64(60)
+			processRecord, err := processRepo.PatchProcess(testCtx, client, ProcessPatchMessage{
+				ProcessGUID:               process.Name,
+				SpaceGUID:                 namespace.Name,
+				Command:                   &command,
+				HealthCheckHTTPEndpoint:   &endpoint,
+				HealthCheckTimeoutSeconds: &timeout,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processRecord.Command).To(Equal("bundle exec rackup"))
+			Expect(processRecord.HealthCheck.Type).To(Equal("http"))
+			Expect(processRecord.HealthCheck.Data.HTTPEndpoint).To(Equal("/ready"))
+			Expect(processRecord.HealthCheck.Data.TimeoutSeconds).To(BeEquivalentTo(60))
+
+			updatedProcess := fetchProcess()
+			Expect(updatedProcess.Spec.Command).To(Equal("bundle exec rackup"))
+			Expect(updatedProcess.Spec.HealthCheck.Data.HTTPEndpoint).To(Equal("/ready"))
+		})
+
+		When("the health check type changes from http", func() {
+			It("removes the HTTP endpoint", func() {
+				healthCheckType := "port"
+				_, err := processRepo.PatchProcess(testCtx, client, ProcessPatchMessage{
+					ProcessGUID:     process.Name,
+					SpaceGUID:       namespace.Name,
+					HealthCheckType: &healthCheckType,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				updatedProcess := fetchProcess()
+				Expect(updatedProcess.Spec.HealthCheck.Type).To(BeEquivalentTo("port"))
+				Expect(updatedProcess.Spec.HealthCheck.Data.HTTPEndpoint).To(BeEmpty())
+				Expect(updatedProcess.Spec.HealthCheck.Data.TimeoutSeconds).To(BeEquivalentTo(30))
+			})
+		})
+
+		When("the process doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				_, err := processRepo.PatchProcess(testCtx, client, ProcessPatchMessage{
+					ProcessGUID: "no-such-process",
+					SpaceGUID:   namespace.Name,
+				})
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+	})
+
+	Describe("ScaleProcess", func() {
+		var (
+			orgGUID        string
+			spaceNamespace *corev1.Namespace
+			otherSpace     *corev1.Namespace
+			process        *workloadsv1alpha1.CFProcess
+			scaleRepo      *ProcessRepository
+		)
+
+		createSpaceNamespace := func() *corev1.Namespace {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        generateGUID(),
+				Annotations: map[string]string{hnsv1alpha2.SubnamespaceOf: orgGUID},
+				Labels:      map[string]string{orgGUID + hnsv1alpha2.LabelTreeDepthSuffix: "1"},
+			}}
+			Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+			return namespace
+		}
+
+		createQuota := func(namespace string, hard corev1.ResourceList) {
+			Expect(k8sClient.Create(testCtx, &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: namespace},
+				Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+			})).To(Succeed())
+		}
+
+		scale := func(instances int, memoryMB int64) (ProcessRecord, error) {
+			return scaleRepo.ScaleProcess(testCtx, client, ProcessScaleMessage{
+				GUID:      process.Name,
+				SpaceGUID: process.Namespace,
+				ProcessScaleValues: ProcessScaleValues{
+					Instances: &instances,
+					MemoryMB:  &memoryMB,
+				},
+			})
+		}
+
+		BeforeEach(func() {
+			scaleRepo = NewProcessRepository(NewGUIDNamespaceCache(), k8sClient, nil)
+
+			orgGUID = generateGUID()
+			Expect(k8sClient.Create(testCtx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   orgGUID,
+				Labels: map[string]string{orgGUID + hnsv1alpha2.LabelTreeDepthSuffix: "0"},
+			}})).To(Succeed())
+			spaceNamespace = createSpaceNamespace()
+			otherSpace = createSpaceNamespace()
+
+			process = initializeProcessCR(generateGUID(), spaceNamespace.Name, generateGUID())
+			Expect(k8sClient.Create(testCtx, process)).To(Succeed())
+
+			otherProcess := initializeProcessCR(generateGUID(), otherSpace.Name, generateGUID())
+			otherProcess.Spec.DesiredInstances = 2
+			otherProcess.Spec.MemoryMB = 256
+			Expect(k8sClient.Create(testCtx, otherProcess)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, spaceNamespace)).To(Succeed())
+			Expect(k8sClient.Delete(testCtx, otherSpace)).To(Succeed())
+			Expect(k8sClient.Delete(testCtx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: orgGUID}})).To(Succeed())
+		})
+
+		It("changes the instances, memory and disk of the process", func() {
+			instances := 3
+			memoryMB := int64(512)
+			diskMB := int64(2048)
+			processRecord, err := scaleRepo.ScaleProcess(testCtx, client, ProcessScaleMessage{
+				GUID:      process.Name,
+				SpaceGUID: process.Namespace,
+				ProcessScaleValues: ProcessScaleValues{
+					Instances: &instances,
+					MemoryMB:  &memoryMB,
+					DiskMB:    &diskMB,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processRecord.Instances).To(Equal(3))
+			Expect(processRecord.MemoryMB).To(BeEquivalentTo(512))
+			Expect(processRecord.DiskQuotaMB).To(BeEquivalentTo(2048))
+
+			updatedProcess := new(workloadsv1alpha1.CFProcess)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: process.Name, Namespace: process.Namespace}, updatedProcess)).To(Succeed())
+			Expect(updatedProcess.Spec.DesiredInstances).To(Equal(3))
+			Expect(updatedProcess.Spec.MemoryMB).To(BeEquivalentTo(512))
+			Expect(updatedProcess.Spec.DiskQuotaMB).To(BeEquivalentTo(2048))
+		})
+
+		When("the space has a quota", func() {
+			BeforeEach(func() {
+				createQuota(spaceNamespace.Name, corev1.ResourceList{
+					corev1.ResourceLimitsMemory: resource.MustParse("1Gi"),
+					corev1.ResourcePods:         resource.MustParse("4"),
+				})
+			})
+
+			It("scales the process within the quota", func() {
+				_, err := scale(4, 250)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("rejects a scale over the memory quota", func() {
+				_, err := scale(3, 512)
+				Expect(err).To(MatchError(QuotaExceededError{Reason: "memory space_quota_exceeded"}))
+			})
+
+			It("rejects a scale over the instance quota and leaves the process as it is", func() {
+				_, err := scale(5, 128)
+				Expect(err).To(MatchError(QuotaExceededError{Reason: "app_instance_limit space_quota_exceeded"}))
+
+				updatedProcess := new(workloadsv1alpha1.CFProcess)
+				Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: process.Name, Namespace: process.Namespace}, updatedProcess)).To(Succeed())
+				Expect(updatedProcess.Spec.DesiredInstances).To(Equal(process.Spec.DesiredInstances))
+			})
+
+			When("the space is already over the quota", func() {
+				BeforeEach(func() {
+					overQuotaProcess := process.DeepCopy()
+					overQuotaProcess.Spec.DesiredInstances = 3
+					Expect(k8sClient.Update(testCtx, overQuotaProcess)).To(Succeed())
+				})
+
+				It("allows a scale that reduces the usage", func() {
+					_, err := scale(2, 600)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("rejects a scale that increases the usage", func() {
+					_, err := scale(4, 500)
+					Expect(err).To(MatchError(QuotaExceededError{Reason: "memory space_quota_exceeded"}))
+				})
+			})
+
+			When("a task is running in the space", func() {
+				BeforeEach(func() {
+					Expect(k8sClient.Create(testCtx, &batchv1.Job{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      generateGUID(),
+							Namespace: spaceNamespace.Name,
+							Labels:    map[string]string{TaskLabel: "true"},
+						},
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								Spec: corev1.PodSpec{
+									RestartPolicy: corev1.RestartPolicyNever,
+									Containers: []corev1.Container{{
+										Name:  "task",
+										Image: "some-image",
+										Resources: corev1.ResourceRequirements{
+											Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("800Mi")},
+										},
+									}},
+								},
+							},
+						},
+					})).To(Succeed())
+				})
+
+				It("counts the task against the quota", func() {
+					_, err := scale(1, 600)
+					Expect(err).To(MatchError(QuotaExceededError{Reason: "memory space_quota_exceeded"}))
+				})
+			})
+
+			When("another app in the space is stopped", func() {
+				BeforeEach(func() {
+					stoppedAppGUID := generateGUID()
+					Expect(k8sClient.Create(testCtx, initializeAppCR("stopped-app", stoppedAppGUID, spaceNamespace.Name))).To(Succeed())
+					stoppedProcess := initializeProcessCR(generateGUID(), spaceNamespace.Name, stoppedAppGUID)
+					stoppedProcess.Spec.MemoryMB = 1024
+					Expect(k8sClient.Create(testCtx, stoppedProcess)).To(Succeed())
+				})
+
+				It("doesn't count the processes of the stopped app", func() {
+					_, err := scale(2, 500)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			It("counts memory in MiB", func() {
+				_, err := scale(1, 1024)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = scale(1, 1025)
+				Expect(err).To(MatchError(QuotaExceededError{Reason: "memory space_quota_exceeded"}))
+			})
+		})
+
+		When("the org has a quota", func() {
+			BeforeEach(func() {
+				createQuota(orgGUID, corev1.ResourceList{
+					corev1.ResourceLimitsMemory: resource.MustParse("1Gi"),
+				})
+			})
+
+			It("counts the processes of the other spaces of the org", func() {
+				_, err := scale(1, 400)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = scale(2, 300)
+				Expect(err).To(MatchError(QuotaExceededError{Reason: "memory quota_exceeded"}))
+			})
+		})
+
+		When("the process doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				instances := 1
+				_, err := scaleRepo.ScaleProcess(testCtx, client, ProcessScaleMessage{
+					GUID:               "no-such-process",
+					SpaceGUID:          spaceNamespace.Name,
+					ProcessScaleValues: ProcessScaleValues{Instances: &instances},
+				})
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+	})
+
+	Describe("CreateProcess", func() {
+		var (
+			namespace  *corev1.Namespace
+			createRepo *ProcessRepository
+		)
+
+		BeforeEach(func() {
+			createRepo = NewProcessRepository(NewGUIDNamespaceCache(), k8sClient, nil)
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+			Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+		})
+
+		It("creates a process with the labels of the app controller", func() {
+			processRecord, err := createRepo.CreateProcess(testCtx, client, ProcessCreateMessage{
+				AppGUID:     "app-guid",
+				SpaceGUID:   namespace.Name,
+				Type:        "web",
+				Command:     "bundle exec rackup",
+				Instances:   2,
+				MemoryMB:    512,
+				DiskQuotaMB: 1024,
+				HealthCheck: HealthCheck{Type: "port"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processRecord.AppGUID).To(Equal("app-guid"))
+			Expect(processRecord.Instances).To(Equal(2))
+			Expect(processRecord.Ports).To(Equal([]int32{8080}))
+
+			cfProcess := new(workloadsv1alpha1.CFProcess)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: processRecord.GUID, Namespace: namespace.Name}, cfProcess)).To(Succeed())
+			Expect(cfProcess.Labels).To(Equal(map[string]string{
+				workloadsv1alpha1.CFAppGUIDLabelKey:     "app-guid",
+				workloadsv1alpha1.CFProcessGUIDLabelKey: processRecord.GUID,
+				workloadsv1alpha1.CFProcessTypeLabelKey: "web",
+			}))
+			Expect(cfProcess.Spec.Command).To(Equal("bundle exec rackup"))
+			Expect(cfProcess.Spec.MemoryMB).To(BeEquivalentTo(512))
+			Expect(string(cfProcess.Spec.HealthCheck.Type)).To(Equal("port"))
+		})
+
+		It("gives processes of every type a port", func() {
+			processRecord, err := createRepo.CreateProcess(testCtx, client, ProcessCreateMessage{
+				AppGUID:   "app-guid",
+				SpaceGUID: namespace.Name,
+				Type:      "worker",
+				Command:   "bundle exec sidekiq",
+				Instances: 1,
+				MemoryMB:  256,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processRecord.Ports).To(Equal([]int32{8080}))
+		})
+
+		When("the space has a quota", func() {
+			BeforeEach(func() {
+				Expect(k8sClient.Create(testCtx, &corev1.ResourceQuota{
+					ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: namespace.Name},
+					Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+						corev1.ResourceLimitsMemory: resource.MustParse("1Gi"),
+					}},
+				})).To(Succeed())
+			})
+
+			It("rejects a process over the quota", func() {
+				_, err := createRepo.CreateProcess(testCtx, client, ProcessCreateMessage{
+					AppGUID:   "app-guid",
+					SpaceGUID: namespace.Name,
+					Type:      "worker",
+					Instances: 2,
+					MemoryMB:  1024,
+				})
+				Expect(err).To(MatchError(QuotaExceededError{Reason: "memory space_quota_exceeded"}))
+			})
+		})
+	})
 })
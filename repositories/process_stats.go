@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list;delete
+//+kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=list
+
+const (
+	ProcessInstanceRunning  = "RUNNING"
+	ProcessInstanceCrashed  = "CRASHED"
+	ProcessInstanceStarting = "STARTING"
+	ProcessInstanceDown     = "DOWN"
+
+	// ProcessGUIDPodLabel is set on the pods of a process by the LRP that runs it
+	ProcessGUIDPodLabel = "cloudfoundry.org/guid"
+)
+
+var podMetricsListGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+type ProcessInstanceStatsRecord struct {
+	Type  string
+	Index int
+	State string
+	// Usage is nil when the metrics API is not available or has no metrics for the instance yet
+	Usage          *ProcessInstanceUsageRecord
+	Host           string
+	Ports          []int32
+	UptimeSeconds  int64
+	Restarts       int
+	MemQuotaBytes  int64
+	DiskQuotaBytes int64
+	Details        string
+}
+
+type ProcessInstanceUsageRecord struct {
+	Time string
+	// CPU is the fraction of a core in use
+	CPU         float64
+	MemoryBytes int64
+	DiskBytes   int64
+}
+
+type PodMetricsRecord struct {
+	PodName   string
+	Timestamp time.Time
+	CPU       resource.Quantity
+	Memory    resource.Quantity
+}
+
+//counterfeiter:generate -o fake -fake-name PodMetricsFetcher . PodMetricsFetcher
+
+// PodMetricsFetcher reads the resource usage of the pods in a namespace that match selector. It returns no metrics
+// when the metrics API is not available.
+type PodMetricsFetcher interface {
+	FetchPodMetrics(ctx context.Context, c client.Client, namespace string, selector labels.Selector) ([]PodMetricsRecord, error)
+}
+
+// MetricsAPIFetcher is the PodMetricsFetcher that reads the metrics.k8s.io API served by metrics-server
+type MetricsAPIFetcher struct{}
+
+func (f MetricsAPIFetcher) FetchPodMetrics(ctx context.Context, c client.Client, namespace string, selector labels.Selector) ([]PodMetricsRecord, error) {
+	podMetricsList := &unstructured.UnstructuredList{}
+	podMetricsList.SetGroupVersionKind(podMetricsListGVK)
+	err := c.List(ctx, podMetricsList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing pod metrics: %w", err)
+	}
+
+	records := []PodMetricsRecord{}
+	for _, podMetrics := range podMetricsList.Items {
+		record := PodMetricsRecord{PodName: podMetrics.GetName()}
+		if timestamp, ok, _ := unstructured.NestedString(podMetrics.Object, "timestamp"); ok {
+			record.Timestamp, _ = time.Parse(time.RFC3339, timestamp)
+		}
+
+		containers, _, _ := unstructured.NestedSlice(podMetrics.Object, "containers")
+		for _, container := range containers {
+			usage, _, _ := unstructured.NestedStringMap(container.(map[string]interface{}), "usage")
+			if cpu, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+				record.CPU.Add(cpu)
+			}
+			if memory, err := resource.ParseQuantity(usage["memory"]); err == nil {
+				record.Memory.Add(memory)
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// FetchProcessStats returns the state and usage of each instance of process, read from the pods that run it.
+// Instances up to the desired number that have no pod are DOWN.
+func (r *ProcessRepository) FetchProcessStats(ctx context.Context, c client.Client, process ProcessRecord) ([]ProcessInstanceStatsRecord, error) {
+	selector := labels.SelectorFromSet(labels.Set{ProcessGUIDPodLabel: process.GUID})
+
+	podList := &corev1.PodList{}
+	err := c.List(ctx, podList, client.InNamespace(process.SpaceGUID), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods of process %q: %w", process.GUID, err)
+	}
+
+	podMetrics, err := r.metricsFetcher.FetchPodMetrics(ctx, c, process.SpaceGUID, selector)
+	if err != nil {
+		return nil, err
+	}
+	usageByPod := map[string]*ProcessInstanceUsageRecord{}
+	for _, metrics := range podMetrics {
+		usageByPod[metrics.PodName] = &ProcessInstanceUsageRecord{
+			Time:        metrics.Timestamp.UTC().Format(time.RFC3339),
+			CPU:         float64(metrics.CPU.MilliValue()) / 1000,
+			MemoryBytes: metrics.Memory.Value(),
+		}
+	}
+
+	now := time.Now()
+	instances := map[int]ProcessInstanceStatsRecord{}
+	for _, pod := range podList.Items {
+		index, ok := podIndex(pod.Name)
+		if !ok {
+			continue
+		}
+		instance := newProcessInstanceStats(process, index)
+		instance.State, instance.UptimeSeconds, instance.Restarts, instance.Details = podState(pod, now)
+		instance.Host = pod.Status.HostIP
+		instance.Usage = usageByPod[pod.Name]
+		instances[index] = instance
+	}
+	for index := 0; index < process.Instances; index++ {
+		if _, ok := instances[index]; !ok {
+			instances[index] = newProcessInstanceStats(process, index)
+		}
+	}
+
+	stats := make([]ProcessInstanceStatsRecord, 0, len(instances))
+	for _, instance := range instances {
+		stats = append(stats, instance)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Index < stats[j].Index })
+
+	return stats, nil
+}
+
+// TerminateProcessInstance deletes the pod that runs the instance of process with the given index, so that it is
+// replaced by a new pod. It does nothing when the instance has no pod.
+func (r *ProcessRepository) TerminateProcessInstance(ctx context.Context, c client.Client, process ProcessRecord, index int) error {
+	podList := &corev1.PodList{}
+	err := c.List(ctx, podList, client.InNamespace(process.SpaceGUID), client.MatchingLabels{ProcessGUIDPodLabel: process.GUID})
+	if err != nil {
+		return fmt.Errorf("error listing pods of process %q: %w", process.GUID, err)
+	}
+
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if podIndex, ok := podIndex(pod.Name); !ok || podIndex != index {
+			continue
+		}
+		err = c.Delete(ctx, pod)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("error deleting pod %q: %w", pod.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func newProcessInstanceStats(process ProcessRecord, index int) ProcessInstanceStatsRecord {
+	return ProcessInstanceStatsRecord{
+		Type:           process.Type,
+		Index:          index,
+		State:          ProcessInstanceDown,
+		Ports:          process.Ports,
+		MemQuotaBytes:  process.MemoryMB * mebibyte,
+		DiskQuotaBytes: process.DiskQuotaMB * mebibyte,
+	}
+}
+
+// podIndex returns the index of the instance run by a pod, which is the ordinal at the end of the name of the pods of
+// a StatefulSet
+func podIndex(podName string) (int, bool) {
+	separator := strings.LastIndex(podName, "-")
+	if separator == -1 {
+		return 0, false
+	}
+	index, err := strconv.Atoi(podName[separator+1:])
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// podState returns the state of the instance run by pod, how long it has been running, how often its containers
+// have restarted and the reason for a crash
+func podState(pod corev1.Pod, now time.Time) (string, int64, int, string) {
+	if pod.DeletionTimestamp != nil {
+		return ProcessInstanceDown, 0, 0, ""
+	}
+
+	restarts := 0
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		restarts += int(containerStatus.RestartCount)
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if waiting := containerStatus.State.Waiting; waiting != nil && waiting.Reason == "CrashLoopBackOff" {
+			return ProcessInstanceCrashed, 0, restarts, waiting.Message
+		}
+		if terminated := containerStatus.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return ProcessInstanceCrashed, 0, restarts, terminated.Reason
+		}
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		return ProcessInstanceStarting, 0, restarts, ""
+	}
+
+	var uptime int64
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if running := containerStatus.State.Running; running != nil {
+			uptime = int64(now.Sub(running.StartedAt.Time).Seconds())
+		}
+		if !containerStatus.Ready {
+			return ProcessInstanceStarting, uptime, restarts, ""
+		}
+	}
+
+	return ProcessInstanceRunning, uptime, restarts, ""
+}
@@ -0,0 +1,240 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("FetchProcessStats", func() {
+	var (
+		testCtx        context.Context
+		namespace      *corev1.Namespace
+		metricsFetcher *fake.PodMetricsFetcher
+		processRepo    *ProcessRepository
+		process        ProcessRecord
+
+		stats    []ProcessInstanceStatsRecord
+		statsErr error
+	)
+
+	createPod := func(name string, status corev1.PodStatus) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace.Name,
+				Labels:    map[string]string{ProcessGUIDPodLabel: process.GUID},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "opi", Image: "my-image"}},
+			},
+		}
+		Expect(k8sClient.Create(testCtx, pod)).To(Succeed())
+		pod.Status = status
+		Expect(k8sClient.Status().Update(testCtx, pod)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		testCtx = context.Background()
+
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+		metricsFetcher = new(fake.PodMetricsFetcher)
+		processRepo = NewProcessRepository(NewGUIDNamespaceCache(), k8sClient, metricsFetcher)
+		process = ProcessRecord{
+			GUID:        generateGUID(),
+			SpaceGUID:   namespace.Name,
+			Type:        "web",
+			Instances:   3,
+			MemoryMB:    256,
+			DiskQuotaMB: 1024,
+			Ports:       []int32{8080},
+		}
+
+		startedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+		createPod("my-app-0", corev1.PodStatus{
+			Phase:  corev1.PodRunning,
+			HostIP: "10.0.0.1",
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "opi",
+				Image:        "my-image",
+				ImageID:      "my-image-id",
+				Ready:        true,
+				RestartCount: 2,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: startedAt}},
+			}},
+		})
+		createPod("my-app-1", corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "opi",
+				Image:        "my-image",
+				ImageID:      "my-image-id",
+				RestartCount: 5,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "CrashLoopBackOff",
+					Message: "back-off restarting failed container",
+				}},
+			}},
+		})
+
+		metricsFetcher.FetchPodMetricsReturns([]PodMetricsRecord{{
+			PodName:   "my-app-0",
+			Timestamp: time.Date(2021, 10, 12, 15, 0, 0, 0, time.UTC),
+			CPU:       resource.MustParse("250m"),
+			Memory:    resource.MustParse("100Mi"),
+		}}, nil)
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		stats, statsErr = processRepo.FetchProcessStats(testCtx, k8sClient, process)
+	})
+
+	It("fetches the metrics of the pods of the process", func() {
+		Expect(metricsFetcher.FetchPodMetricsCallCount()).To(Equal(1))
+		_, _, metricsNamespace, selector := metricsFetcher.FetchPodMetricsArgsForCall(0)
+		Expect(metricsNamespace).To(Equal(namespace.Name))
+		Expect(selector.String()).To(Equal(ProcessGUIDPodLabel + "=" + process.GUID))
+	})
+
+	It("returns the stats of each desired instance", func() {
+		Expect(statsErr).NotTo(HaveOccurred())
+		Expect(stats).To(HaveLen(3))
+
+		Expect(stats[0].Index).To(Equal(0))
+		Expect(stats[0].Type).To(Equal("web"))
+		Expect(stats[0].State).To(Equal(ProcessInstanceRunning))
+		Expect(stats[0].Host).To(Equal("10.0.0.1"))
+		Expect(stats[0].Ports).To(Equal([]int32{8080}))
+		Expect(stats[0].UptimeSeconds).To(BeNumerically("~", 60, 5))
+		Expect(stats[0].Restarts).To(Equal(2))
+		Expect(stats[0].MemQuotaBytes).To(BeEquivalentTo(256 * 1024 * 1024))
+		Expect(stats[0].DiskQuotaBytes).To(BeEquivalentTo(1024 * 1024 * 1024))
+		Expect(stats[0].Usage).To(Equal(&ProcessInstanceUsageRecord{
+			Time:        "2021-10-12T15:00:00Z",
+			CPU:         0.25,
+			MemoryBytes: 100 * 1024 * 1024,
+		}))
+
+		Expect(stats[1].Index).To(Equal(1))
+		Expect(stats[1].State).To(Equal(ProcessInstanceCrashed))
+		Expect(stats[1].Restarts).To(Equal(5))
+		Expect(stats[1].Details).To(Equal("back-off restarting failed container"))
+		Expect(stats[1].Usage).To(BeNil())
+
+		Expect(stats[2].Index).To(Equal(2))
+		Expect(stats[2].State).To(Equal(ProcessInstanceDown))
+	})
+
+	When("a pod is not ready yet", func() {
+		BeforeEach(func() {
+			createPod("my-app-2", corev1.PodStatus{Phase: corev1.PodPending})
+		})
+
+		It("reports the instance as starting", func() {
+			Expect(statsErr).NotTo(HaveOccurred())
+			Expect(stats[2].State).To(Equal(ProcessInstanceStarting))
+		})
+	})
+
+	When("there are more pods than desired instances", func() {
+		BeforeEach(func() {
+			process.Instances = 1
+		})
+
+		It("returns the stats of every pod", func() {
+			Expect(statsErr).NotTo(HaveOccurred())
+			Expect(stats).To(HaveLen(2))
+		})
+	})
+
+	When("fetching the metrics fails", func() {
+		BeforeEach(func() {
+			metricsFetcher.FetchPodMetricsReturns(nil, errors.New("boom"))
+		})
+
+		It("returns the error", func() {
+			Expect(statsErr).To(MatchError("boom"))
+		})
+	})
+})
+
+var _ = Describe("TerminateProcessInstance", func() {
+	var (
+		testCtx     context.Context
+		namespace   *corev1.Namespace
+		processRepo *ProcessRepository
+		process     ProcessRecord
+	)
+
+	podNames := func() []string {
+		podList := &corev1.PodList{}
+		Expect(k8sClient.List(testCtx, podList, client.InNamespace(namespace.Name))).To(Succeed())
+		names := []string{}
+		for _, pod := range podList.Items {
+			if pod.DeletionTimestamp == nil {
+				names = append(names, pod.Name)
+			}
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		testCtx = context.Background()
+
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+		processRepo = NewProcessRepository(NewGUIDNamespaceCache(), k8sClient, new(fake.PodMetricsFetcher))
+		process = ProcessRecord{GUID: generateGUID(), SpaceGUID: namespace.Name, Instances: 2}
+
+		for _, pod := range []struct{ name, processGUID string }{
+			{"my-app-0", process.GUID},
+			{"my-app-1", process.GUID},
+			{"other-app-1", generateGUID()},
+		} {
+			Expect(k8sClient.Create(testCtx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.name,
+					Namespace: namespace.Name,
+					Labels:    map[string]string{ProcessGUIDPodLabel: pod.processGUID},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "opi", Image: "my-image"}},
+				},
+			})).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+	})
+
+	It("deletes the pod of the instance", func() {
+		Expect(processRepo.TerminateProcessInstance(testCtx, k8sClient, process, 1)).To(Succeed())
+		Expect(podNames()).To(ConsistOf("my-app-0", "other-app-1"))
+	})
+
+	When("the instance has no pod", func() {
+		It("does nothing", func() {
+			process.Instances = 3
+			Expect(processRepo.TerminateProcessInstance(testCtx, k8sClient, process, 2)).To(Succeed())
+			Expect(podNames()).To(HaveLen(3))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fake
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/provider"
+)
+
+type AccessReviewer struct {
+	ForAuthorizationHeaderStub        func(string) (authorization.AccessChecker, error)
+	forAuthorizationHeaderMutex       sync.RWMutex
+	forAuthorizationHeaderArgsForCall []struct {
+		arg1 string
+	}
+	forAuthorizationHeaderReturns struct {
+		result1 authorization.AccessChecker
+		result2 error
+	}
+	forAuthorizationHeaderReturnsOnCall map[int]struct {
+		result1 authorization.AccessChecker
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *AccessReviewer) ForAuthorizationHeader(arg1 string) (authorization.AccessChecker, error) {
+	fake.forAuthorizationHeaderMutex.Lock()
+	ret, specificReturn := fake.forAuthorizationHeaderReturnsOnCall[len(fake.forAuthorizationHeaderArgsForCall)]
+	fake.forAuthorizationHeaderArgsForCall = append(fake.forAuthorizationHeaderArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ForAuthorizationHeaderStub
+	fakeReturns := fake.forAuthorizationHeaderReturns
+	fake.recordInvocation("ForAuthorizationHeader", []interface{}{arg1})
+	fake.forAuthorizationHeaderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *AccessReviewer) ForAuthorizationHeaderCallCount() int {
+	fake.forAuthorizationHeaderMutex.RLock()
+	defer fake.forAuthorizationHeaderMutex.RUnlock()
+	return len(fake.forAuthorizationHeaderArgsForCall)
+}
+
+func (fake *AccessReviewer) ForAuthorizationHeaderCalls(stub func(string) (authorization.AccessChecker, error)) {
+	fake.forAuthorizationHeaderMutex.Lock()
+	defer fake.forAuthorizationHeaderMutex.Unlock()
+	fake.ForAuthorizationHeaderStub = stub
+}
+
+func (fake *AccessReviewer) ForAuthorizationHeaderArgsForCall(i int) string {
+	fake.forAuthorizationHeaderMutex.RLock()
+	defer fake.forAuthorizationHeaderMutex.RUnlock()
+	argsForCall := fake.forAuthorizationHeaderArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *AccessReviewer) ForAuthorizationHeaderReturns(result1 authorization.AccessChecker, result2 error) {
+	fake.forAuthorizationHeaderMutex.Lock()
+	defer fake.forAuthorizationHeaderMutex.Unlock()
+	fake.ForAuthorizationHeaderStub = nil
+	fake.forAuthorizationHeaderReturns = struct {
+		result1 authorization.AccessChecker
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AccessReviewer) ForAuthorizationHeaderReturnsOnCall(i int, result1 authorization.AccessChecker, result2 error) {
+	fake.forAuthorizationHeaderMutex.Lock()
+	defer fake.forAuthorizationHeaderMutex.Unlock()
+	fake.ForAuthorizationHeaderStub = nil
+	if fake.forAuthorizationHeaderReturnsOnCall == nil {
+		fake.forAuthorizationHeaderReturnsOnCall = make(map[int]struct {
+			result1 authorization.AccessChecker
+			result2 error
+		})
+	}
+	fake.forAuthorizationHeaderReturnsOnCall[i] = struct {
+		result1 authorization.AccessChecker
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *AccessReviewer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.forAuthorizationHeaderMutex.RLock()
+	defer fake.forAuthorizationHeaderMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *AccessReviewer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ provider.AccessReviewer = new(AccessReviewer)
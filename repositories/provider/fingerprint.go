@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	"github.com/go-http-utils/headers"
+)
+
+type FingerprintRepositoryProvider struct {
+	fingerprintStore *repositories.FingerprintStore
+	identityProvider IdentityProvider
+}
+
+func NewFingerprint(
+	fingerprintStore *repositories.FingerprintStore,
+	identityProvider IdentityProvider) *FingerprintRepositoryProvider {
+	return &FingerprintRepositoryProvider{
+		fingerprintStore: fingerprintStore,
+		identityProvider: identityProvider,
+	}
+}
+
+func (p *FingerprintRepositoryProvider) FingerprintRepoForRequest(request *http.Request) (apis.CFFingerprintRepository, error) {
+	identity, err := p.identityProvider.GetIdentity(request.Context(), request.Header.Get(headers.Authorization))
+	if err != nil {
+		return nil, err
+	}
+
+	return p.fingerprintStore.ForIdentity(identity), nil
+}
+
+// PrivilegedFingerprintRepositoryProvider keeps the files of every upload together, as without authentication there
+// is no telling users apart
+type PrivilegedFingerprintRepositoryProvider struct {
+	fingerprintStore *repositories.FingerprintStore
+}
+
+func NewPrivilegedFingerprint(fingerprintStore *repositories.FingerprintStore) *PrivilegedFingerprintRepositoryProvider {
+	return &PrivilegedFingerprintRepositoryProvider{
+		fingerprintStore: fingerprintStore,
+	}
+}
+
+func (p *PrivilegedFingerprintRepositoryProvider) FingerprintRepoForRequest(_ *http.Request) (apis.CFFingerprintRepository, error) {
+	return p.fingerprintStore.ForIdentity(authorization.Identity{}), nil
+}
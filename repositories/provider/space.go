@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	"github.com/go-http-utils/headers"
+)
+
+//counterfeiter:generate -o fake -fake-name AccessReviewer . AccessReviewer
+
+type AccessReviewer interface {
+	ForAuthorizationHeader(authorizationHeader string) (authorization.AccessChecker, error)
+}
+
+type SpaceRepositoryProvider struct {
+	spaceRepo        repositories.CFSpaceRepository
+	authNsProvider   repositories.AuthorizedNamespacesProvider
+	identityProvider IdentityProvider
+	accessReviewer   AccessReviewer
+}
+
+func NewSpace(
+	spaceRepo repositories.CFSpaceRepository,
+	authNsProvider repositories.AuthorizedNamespacesProvider,
+	identityProvider IdentityProvider,
+	accessReviewer AccessReviewer,
+) *SpaceRepositoryProvider {
+	return &SpaceRepositoryProvider{
+		spaceRepo:        spaceRepo,
+		authNsProvider:   authNsProvider,
+		identityProvider: identityProvider,
+		accessReviewer:   accessReviewer,
+	}
+}
+
+func (p *SpaceRepositoryProvider) SpaceRepoForRequest(request *http.Request) (apis.CFSpaceRepository, error) {
+	authorizationHeader := request.Header.Get(headers.Authorization)
+	identity, err := p.identityProvider.GetIdentity(request.Context(), authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	accessChecker, err := p.accessReviewer.ForAuthorizationHeader(authorizationHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return repositories.NewSpaceRepoAuthDecorator(p.spaceRepo, identity, p.authNsProvider, accessChecker), nil
+}
+
+type PrivilegedSpaceRepositoryProvider struct {
+	spaceRepo repositories.CFSpaceRepository
+}
+
+func NewPrivilegedSpace(spaceRepo repositories.CFSpaceRepository) *PrivilegedSpaceRepositoryProvider {
+	return &PrivilegedSpaceRepositoryProvider{
+		spaceRepo: spaceRepo,
+	}
+}
+
+func (p *PrivilegedSpaceRepositoryProvider) SpaceRepoForRequest(_ *http.Request) (apis.CFSpaceRepository, error) {
+	return p.spaceRepo, nil
+}
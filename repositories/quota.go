@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	hnsv1alpha2 "sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
+)
+
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=list
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=list
+
+// Org and space quotas are the ResourceQuotas in the namespaces of orgs and spaces. Their hard limits on memory
+// (limits.memory), disk (limits.ephemeral-storage) and instances (pods) apply to the total of the processes in a space
+// or, for org quotas, in all the spaces of an org. Memory and disk are in MiB, as in the pods of processes and tasks.
+// Stopped apps have no pods, so their processes don't count, while the pods of tasks and staging do.
+// Kubernetes enforces space quotas only when it creates the pods of a process, so scaling is checked against the quotas
+// beforehand to report a violation to the user.
+
+// QuotaExceededError is returned when a change to a process would exceed an org or space quota
+type QuotaExceededError struct {
+	Reason string
+}
+
+func (e QuotaExceededError) Error() string {
+	return e.Reason
+}
+
+var quotaResourceNames = []struct {
+	resourceName corev1.ResourceName
+	name         string
+}{
+	{corev1.ResourceLimitsMemory, "memory"},
+	{corev1.ResourceLimitsEphemeralStorage, "disk"},
+	{corev1.ResourcePods, "app_instance_limit"},
+}
+
+// checkProcessQuotas checks that the processes of the space and org of process fit their quotas once process replaces
+// its current version. The quotas and processes are read with privilegedClient, as users may not see all of them.
+func checkProcessQuotas(ctx context.Context, privilegedClient client.Client, process workloadsv1alpha1.CFProcess) error {
+	spaceNamespace := &corev1.Namespace{}
+	err := privilegedClient.Get(ctx, types.NamespacedName{Name: process.Namespace}, spaceNamespace)
+	if err != nil {
+		return fmt.Errorf("error fetching namespace of space %q: %w", process.Namespace, err)
+	}
+
+	err = checkQuotas(ctx, privilegedClient, process, process.Namespace, []string{process.Namespace}, "space_quota_exceeded")
+	if err != nil {
+		return err
+	}
+
+	orgGUID := spaceNamespace.Annotations[hnsv1alpha2.SubnamespaceOf]
+	if orgGUID == "" {
+		return nil
+	}
+
+	orgNamespaces := &corev1.NamespaceList{}
+	err = privilegedClient.List(ctx, orgNamespaces, client.HasLabels{orgGUID + hnsv1alpha2.LabelTreeDepthSuffix})
+	if err != nil {
+		return fmt.Errorf("error listing namespaces of org %q: %w", orgGUID, err)
+	}
+	namespaces := []string{}
+	for _, namespace := range orgNamespaces.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+
+	return checkQuotas(ctx, privilegedClient, process, orgGUID, namespaces, "quota_exceeded")
+}
+
+// checkQuotas checks the ResourceQuotas in quotaNamespace against the total usage of namespaces once process replaces
+// its current version. A resource is only checked when the change increases its usage, so that a space that is already
+// over a quota can still be scaled down.
+func checkQuotas(ctx context.Context, privilegedClient client.Client, process workloadsv1alpha1.CFProcess, quotaNamespace string, namespaces []string, violation string) error {
+	quotaList := &corev1.ResourceQuotaList{}
+	err := privilegedClient.List(ctx, quotaList, client.InNamespace(quotaNamespace))
+	if err != nil {
+		return fmt.Errorf("error listing quotas in namespace %q: %w", quotaNamespace, err)
+	}
+	if len(quotaList.Items) == 0 {
+		return nil
+	}
+
+	usageBefore, usageAfter, err := namespaceUsage(ctx, privilegedClient, process, namespaces)
+	if err != nil {
+		return err
+	}
+
+	for _, quota := range quotaList.Items {
+		for _, quotaResource := range quotaResourceNames {
+			hard, ok := quota.Spec.Hard[quotaResource.resourceName]
+			if !ok {
+				continue
+			}
+			before, after := usageBefore[quotaResource.resourceName], usageAfter[quotaResource.resourceName]
+			if after.Cmp(hard) > 0 && after.Cmp(before) > 0 {
+				return QuotaExceededError{Reason: fmt.Sprintf("%s %s", quotaResource.name, violation)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// namespaceUsage returns the resources used in namespaces before and after process replaces its current version. The
+// usage is that of the desired instances of the processes of started apps, the running task Jobs and any other pods,
+// such as staging pods, that count against the same quotas.
+func namespaceUsage(ctx context.Context, privilegedClient client.Client, process workloadsv1alpha1.CFProcess, namespaces []string) (corev1.ResourceList, corev1.ResourceList, error) {
+	usage := newResourceUsage()
+	var currentProcess *workloadsv1alpha1.CFProcess
+	processStopped := false
+
+	for _, namespace := range namespaces {
+		appList := &workloadsv1alpha1.CFAppList{}
+		err := privilegedClient.List(ctx, appList, client.InNamespace(namespace))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing apps in namespace %q: %w", namespace, err)
+		}
+		stoppedApps := map[string]bool{}
+		for _, app := range appList.Items {
+			if app.Spec.DesiredState == workloadsv1alpha1.StoppedState {
+				stoppedApps[app.Name] = true
+			}
+		}
+		if namespace == process.Namespace {
+			processStopped = stoppedApps[process.Spec.AppRef.Name]
+		}
+
+		processList := &workloadsv1alpha1.CFProcessList{}
+		err = privilegedClient.List(ctx, processList, client.InNamespace(namespace))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing processes in namespace %q: %w", namespace, err)
+		}
+		for i, p := range processList.Items {
+			if p.Name == process.Name && p.Namespace == process.Namespace {
+				currentProcess = &processList.Items[i]
+				continue
+			}
+			if !stoppedApps[p.Spec.AppRef.Name] {
+				usage.addProcess(p)
+			}
+		}
+
+		jobList := &batchv1.JobList{}
+		err = privilegedClient.List(ctx, jobList, client.InNamespace(namespace), client.HasLabels{TaskLabel})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing tasks in namespace %q: %w", namespace, err)
+		}
+		for _, job := range jobList.Items {
+			if state, _ := taskState(job, false); state == TaskStateSucceeded || state == TaskStateFailed {
+				continue
+			}
+			usage.addPod(job.Spec.Template.Spec)
+		}
+
+		podList := &corev1.PodList{}
+		err = privilegedClient.List(ctx, podList, client.InNamespace(namespace))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing pods in namespace %q: %w", namespace, err)
+		}
+		for _, pod := range podList.Items {
+			// the pods of processes are counted by their desired instances and those of tasks by their Jobs
+			_, isProcessPod := pod.Labels[ProcessGUIDPodLabel]
+			_, isJobPod := pod.Labels[jobNamePodLabel]
+			if isProcessPod || isJobPod || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			usage.addPod(pod.Spec)
+		}
+	}
+
+	before := usage.copy()
+	after := usage.copy()
+	if !processStopped {
+		if currentProcess != nil {
+			before.addProcess(*currentProcess)
+		}
+		after.addProcess(process)
+	}
+
+	return before.resourceList(), after.resourceList(), nil
+}
+
+const (
+	// jobNamePodLabel is set on the pods of Jobs by the Job controller
+	jobNamePodLabel = "job-name"
+
+	// mebibyte is the unit of the memory and disk of processes and tasks
+	mebibyte = 1024 * 1024
+)
+
+type resourceUsage struct {
+	memory, disk, instances *resource.Quantity
+}
+
+func newResourceUsage() resourceUsage {
+	return resourceUsage{
+		memory:    resource.NewQuantity(0, resource.BinarySI),
+		disk:      resource.NewQuantity(0, resource.BinarySI),
+		instances: resource.NewQuantity(0, resource.DecimalSI),
+	}
+}
+
+func (u resourceUsage) addProcess(p workloadsv1alpha1.CFProcess) {
+	desiredInstances := int64(p.Spec.DesiredInstances)
+	u.memory.Add(*resource.NewQuantity(desiredInstances*p.Spec.MemoryMB*mebibyte, resource.BinarySI))
+	u.disk.Add(*resource.NewQuantity(desiredInstances*p.Spec.DiskQuotaMB*mebibyte, resource.BinarySI))
+	u.instances.Add(*resource.NewQuantity(desiredInstances, resource.DecimalSI))
+}
+
+func (u resourceUsage) addPod(podSpec corev1.PodSpec) {
+	for _, container := range podSpec.Containers {
+		u.memory.Add(*container.Resources.Limits.Memory())
+		u.disk.Add(*container.Resources.Limits.StorageEphemeral())
+	}
+	u.instances.Add(*resource.NewQuantity(1, resource.DecimalSI))
+}
+
+func (u resourceUsage) copy() resourceUsage {
+	memory, disk, instances := u.memory.DeepCopy(), u.disk.DeepCopy(), u.instances.DeepCopy()
+	return resourceUsage{memory: &memory, disk: &disk, instances: &instances}
+}
+
+func (u resourceUsage) resourceList() corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceLimitsMemory:           *u.memory,
+		corev1.ResourceLimitsEphemeralStorage: *u.disk,
+		corev1.ResourcePods:                   *u.instances,
+	}
+}
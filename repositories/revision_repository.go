@@ -0,0 +1,352 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RevisionLabel marks the Secrets that hold revisions. The app of a revision is in the CFAppGUIDLabelKey label.
+	RevisionLabel        = "cloudfoundry.org/revision"
+	RevisionVersionLabel = "cloudfoundry.org/revision-version"
+	// revisionVersionAnnotation on a CFApp holds the version of the last revision of the app
+	revisionVersionAnnotation = "cloudfoundry.org/revision-version"
+
+	revisionDropletKey     = "droplet_guid"
+	revisionEnvVarsKey     = "environment_variables"
+	revisionProcessesKey   = "processes"
+	revisionDescriptionKey = "description"
+)
+
+// Revisions record what an app runs: its droplet, environment variables and the commands of its processes. A revision
+// is recorded each time one of them changes once the app has a droplet. Revisions are stored in Secrets named after
+// the revision GUID in the namespace of the space of their app, as they hold the environment variables of the app,
+// and are numbered per app from 1. Versions are allocated from a counter in an annotation of the CFApp of the app, so
+// revisions that are recorded concurrently get different versions.
+
+type RevisionRecord struct {
+	GUID        string
+	Version     int
+	AppGUID     string
+	SpaceGUID   string
+	DropletGUID string
+	// EnvironmentVariables are the environment variables of the app when the revision was recorded
+	EnvironmentVariables map[string]string
+	// Processes are the commands of the processes of the app by process type
+	Processes   map[string]string
+	Description string
+	CreatedAt   string
+	UpdatedAt   string
+}
+
+type RevisionCreateMessage struct {
+	AppGUID   string
+	SpaceGUID string
+	// Description replaces the description of the changes since the previous revision when it is not empty
+	Description string
+}
+
+type RevisionRepo struct {
+	namespaceCache *GUIDNamespaceCache
+	// privilegedClient finds the namespace of a revision that is not in the namespace cache, as users may not be
+	// allowed to list Secrets across namespaces
+	privilegedClient client.Client
+}
+
+func NewRevisionRepo(namespaceCache *GUIDNamespaceCache, privilegedClient client.Client) *RevisionRepo {
+	return &RevisionRepo{
+		namespaceCache:   namespaceCache,
+		privilegedClient: privilegedClient,
+	}
+}
+
+// RecordRevision records the droplet, environment variables and process commands that an app has now, unless they
+// are the same as in its latest revision or the app has no droplet. It returns the latest revision of the app, which
+// is empty when the app has none.
+func (r *RevisionRepo) RecordRevision(ctx context.Context, c client.Client, message RevisionCreateMessage) (RevisionRecord, error) {
+	cfApp := &workloadsv1alpha1.CFApp{}
+	err := c.Get(ctx, types.NamespacedName{Name: message.AppGUID, Namespace: message.SpaceGUID}, cfApp)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return RevisionRecord{}, NotFoundError{Err: err}
+		}
+		return RevisionRecord{}, err
+	}
+
+	revisions, err := r.FetchRevisionsForApp(ctx, c, message.AppGUID, message.SpaceGUID)
+	if err != nil {
+		return RevisionRecord{}, err
+	}
+	var latest RevisionRecord
+	if len(revisions) > 0 {
+		latest = revisions[len(revisions)-1]
+	}
+
+	if cfApp.Spec.CurrentDropletRef.Name == "" {
+		return latest, nil
+	}
+
+	revision := RevisionRecord{
+		GUID:        uuid.NewString(),
+		AppGUID:     message.AppGUID,
+		SpaceGUID:   message.SpaceGUID,
+		DropletGUID: cfApp.Spec.CurrentDropletRef.Name,
+	}
+	revision.EnvironmentVariables, err = fetchEnvironmentVariables(ctx, c, cfApp)
+	if err != nil {
+		return RevisionRecord{}, err
+	}
+	revision.Processes, err = fetchProcessCommands(ctx, c, message.AppGUID, message.SpaceGUID)
+	if err != nil {
+		return RevisionRecord{}, err
+	}
+
+	description := describeChanges(latest, revision)
+	if description == "" {
+		return latest, nil
+	}
+	revision.Description = description
+	if message.Description != "" {
+		revision.Description = message.Description
+	}
+
+	revision.Version, err = nextAppCounterValue(ctx, c, message.AppGUID, message.SpaceGUID, revisionVersionAnnotation, func() (int, error) {
+		return latest.Version, nil
+	})
+	if err != nil {
+		return RevisionRecord{}, fmt.Errorf("error allocating version of revision: %w", err)
+	}
+
+	secret, err := revisionToSecret(revision)
+	if err != nil {
+		return RevisionRecord{}, err
+	}
+	secret.OwnerReferences = []metav1.OwnerReference{appOwnerReference(cfApp)}
+	err = c.Create(ctx, secret)
+	if err != nil {
+		return RevisionRecord{}, fmt.Errorf("error creating revision of app %q: %w", message.AppGUID, err)
+	}
+	r.namespaceCache.Set(secret.Name, secret.Namespace)
+
+	return secretToRevisionRecord(*secret)
+}
+
+func (r *RevisionRepo) FetchRevision(ctx context.Context, c client.Client, guid string) (RevisionRecord, error) {
+	secret := &corev1.Secret{}
+	found, err := r.namespaceCache.fetch(ctx, c, guid, secret)
+	if err != nil {
+		return RevisionRecord{}, err
+	}
+
+	if !found {
+		secretList := &corev1.SecretList{}
+		err = r.privilegedClient.List(ctx, secretList, client.HasLabels{RevisionLabel}, client.MatchingFields{"metadata.name": guid})
+		if err != nil {
+			return RevisionRecord{}, fmt.Errorf("error finding revision %q: %w", guid, err)
+		}
+		if len(secretList.Items) == 0 {
+			return RevisionRecord{}, NotFoundError{}
+		}
+
+		namespace := secretList.Items[0].Namespace
+		err = c.Get(ctx, types.NamespacedName{Name: guid, Namespace: namespace}, secret)
+		if err != nil {
+			if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) {
+				return RevisionRecord{}, NotFoundError{Err: err}
+			}
+			return RevisionRecord{}, err
+		}
+		r.namespaceCache.Set(guid, namespace)
+	}
+
+	if _, ok := secret.Labels[RevisionLabel]; !ok {
+		return RevisionRecord{}, NotFoundError{}
+	}
+
+	return secretToRevisionRecord(*secret)
+}
+
+// FetchRevisionsForApp returns the revisions of an app, oldest first
+func (r *RevisionRepo) FetchRevisionsForApp(ctx context.Context, c client.Client, appGUID, spaceGUID string) ([]RevisionRecord, error) {
+	secretList := &corev1.SecretList{}
+	err := c.List(ctx, secretList,
+		client.InNamespace(spaceGUID),
+		client.HasLabels{RevisionLabel},
+		client.MatchingLabels{workloadsv1alpha1.CFAppGUIDLabelKey: appGUID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error listing revisions of app %q: %w", appGUID, err)
+	}
+
+	revisions := make([]RevisionRecord, 0, len(secretList.Items))
+	for _, secret := range secretList.Items {
+		revision, err := secretToRevisionRecord(secret)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Version < revisions[j].Version
+	})
+
+	return revisions, nil
+}
+
+// FetchDeployedRevisions returns the revisions that a started app runs: the latest revision with the current droplet
+// of the app. Stopped apps run no revision.
+func (r *RevisionRepo) FetchDeployedRevisions(ctx context.Context, c client.Client, app AppRecord) ([]RevisionRecord, error) {
+	if app.State != StartedState {
+		return []RevisionRecord{}, nil
+	}
+
+	revisions, err := r.FetchRevisionsForApp(ctx, c, app.GUID, app.SpaceGUID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].DropletGUID == app.DropletGUID {
+			return []RevisionRecord{revisions[i]}, nil
+		}
+	}
+
+	return []RevisionRecord{}, nil
+}
+
+func fetchEnvironmentVariables(ctx context.Context, c client.Client, cfApp *workloadsv1alpha1.CFApp) (map[string]string, error) {
+	if cfApp.Spec.EnvSecretName == "" {
+		return map[string]string{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Name: cfApp.Spec.EnvSecretName, Namespace: cfApp.Namespace}, secret)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("error fetching environment variables of app %q: %w", cfApp.Name, err)
+	}
+
+	return convertByteSliceValuesToStrings(secret.Data), nil
+}
+
+// fetchProcessCommands returns the commands of the processes of an app by process type. While an app is being
+// deployed, the newest process of each type wins.
+func fetchProcessCommands(ctx context.Context, c client.Client, appGUID, spaceGUID string) (map[string]string, error) {
+	processList := &workloadsv1alpha1.CFProcessList{}
+	err := c.List(ctx, processList, listOptionsForApp(c, spaceGUID, appGUID)...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing the processes of app %q: %w", appGUID, err)
+	}
+
+	processes := filterProcessesByAppGUID(processList.Items, appGUID)
+	sort.SliceStable(processes, func(i, j int) bool {
+		return processes[i].CreationTimestamp.Before(&processes[j].CreationTimestamp)
+	})
+
+	commands := map[string]string{}
+	for _, process := range processes {
+		commands[process.Spec.ProcessType] = process.Spec.Command
+	}
+	return commands, nil
+}
+
+// describeChanges describes how revision differs from the previous revision. It is empty when nothing has changed.
+func describeChanges(previous, revision RevisionRecord) string {
+	if previous.GUID == "" {
+		return "Initial revision."
+	}
+
+	changes := []string{}
+	if previous.DropletGUID != revision.DropletGUID {
+		changes = append(changes, "New droplet deployed.")
+	}
+	if !reflect.DeepEqual(previous.EnvironmentVariables, revision.EnvironmentVariables) {
+		changes = append(changes, "New environment variables deployed.")
+	}
+
+	processTypes := []string{}
+	for processType, command := range revision.Processes {
+		if previousCommand, ok := previous.Processes[processType]; ok && previousCommand != command {
+			processTypes = append(processTypes, processType)
+		}
+	}
+	sort.Strings(processTypes)
+	for _, processType := range processTypes {
+		changes = append(changes, fmt.Sprintf("Custom start command updated for %s process.", processType))
+	}
+
+	return strings.Join(changes, " ")
+}
+
+func revisionToSecret(revision RevisionRecord) (*corev1.Secret, error) {
+	envVars, err := json.Marshal(revision.EnvironmentVariables)
+	if err != nil {
+		return nil, err
+	}
+	processes, err := json.Marshal(revision.Processes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revision.GUID,
+			Namespace: revision.SpaceGUID,
+			Labels: map[string]string{
+				RevisionLabel:                       "true",
+				RevisionVersionLabel:                strconv.Itoa(revision.Version),
+				workloadsv1alpha1.CFAppGUIDLabelKey: revision.AppGUID,
+			},
+		},
+		Data: map[string][]byte{
+			revisionDropletKey:     []byte(revision.DropletGUID),
+			revisionEnvVarsKey:     envVars,
+			revisionProcessesKey:   processes,
+			revisionDescriptionKey: []byte(revision.Description),
+		},
+	}, nil
+}
+
+func secretToRevisionRecord(secret corev1.Secret) (RevisionRecord, error) {
+	envVars := map[string]string{}
+	err := json.Unmarshal(secret.Data[revisionEnvVarsKey], &envVars)
+	if err != nil {
+		return RevisionRecord{}, fmt.Errorf("error reading environment variables of revision %q: %w", secret.Name, err)
+	}
+	processes := map[string]string{}
+	err = json.Unmarshal(secret.Data[revisionProcessesKey], &processes)
+	if err != nil {
+		return RevisionRecord{}, fmt.Errorf("error reading processes of revision %q: %w", secret.Name, err)
+	}
+
+	version, _ := strconv.Atoi(secret.Labels[RevisionVersionLabel])
+	updatedAt, _ := getTimeLastUpdatedTimestamp(&secret.ObjectMeta)
+
+	return RevisionRecord{
+		GUID:                 secret.Name,
+		Version:              version,
+		AppGUID:              secret.Labels[workloadsv1alpha1.CFAppGUIDLabelKey],
+		SpaceGUID:            secret.Namespace,
+		DropletGUID:          string(secret.Data[revisionDropletKey]),
+		EnvironmentVariables: envVars,
+		Processes:            processes,
+		Description:          string(secret.Data[revisionDescriptionKey]),
+		CreatedAt:            formatTimestamp(secret.CreationTimestamp),
+		UpdatedAt:            updatedAt,
+	}, nil
+}
@@ -0,0 +1,199 @@
+package repositories_test
+
+import (
+	"context"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("RevisionRepo", func() {
+	var (
+		testCtx      context.Context
+		revisionRepo *RevisionRepo
+		namespace    *corev1.Namespace
+		cfApp        *workloadsv1alpha1.CFApp
+		cfProcess    *workloadsv1alpha1.CFProcess
+		envSecret    *corev1.Secret
+	)
+
+	recordRevision := func() RevisionRecord {
+		revision, err := revisionRepo.RecordRevision(testCtx, k8sClient, RevisionCreateMessage{AppGUID: cfApp.Name, SpaceGUID: namespace.Name})
+		Expect(err).NotTo(HaveOccurred())
+		return revision
+	}
+
+	BeforeEach(func() {
+		testCtx = context.Background()
+		revisionRepo = NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient)
+
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+		cfApp = initializeAppCR("my-app", generateGUID(), namespace.Name)
+		envSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: cfApp.Name + "-env", Namespace: namespace.Name},
+			StringData: map[string]string{"RAILS_ENV": "production"},
+		}
+		Expect(k8sClient.Create(testCtx, envSecret)).To(Succeed())
+		cfApp.Spec.EnvSecretName = envSecret.Name
+		cfApp.Spec.CurrentDropletRef = corev1.LocalObjectReference{Name: "droplet-guid"}
+		Expect(k8sClient.Create(testCtx, cfApp)).To(Succeed())
+
+		cfProcess = initializeProcessCR(generateGUID(), namespace.Name, cfApp.Name)
+		cfProcess.Spec.Command = "bundle exec rackup"
+		Expect(k8sClient.Create(testCtx, cfProcess)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+	})
+
+	Describe("RecordRevision", func() {
+		It("records the droplet, environment variables and process commands of the app", func() {
+			revision := recordRevision()
+			Expect(revision.GUID).NotTo(BeEmpty())
+			Expect(revision.Version).To(Equal(1))
+			Expect(revision.AppGUID).To(Equal(cfApp.Name))
+			Expect(revision.DropletGUID).To(Equal("droplet-guid"))
+			Expect(revision.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "production"}))
+			Expect(revision.Processes).To(Equal(map[string]string{"web": "bundle exec rackup"}))
+			Expect(revision.Description).To(Equal("Initial revision."))
+		})
+
+		It("makes the app the owner of the revision, so it is deleted with the app", func() {
+			revision := recordRevision()
+
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: revision.GUID, Namespace: namespace.Name}, secret)).To(Succeed())
+			Expect(secret.OwnerReferences).To(Equal([]metav1.OwnerReference{{
+				APIVersion: APIVersion,
+				Kind:       Kind,
+				Name:       cfApp.Name,
+				UID:        cfApp.UID,
+			}}))
+		})
+
+		It("doesn't record a revision when nothing has changed", func() {
+			first := recordRevision()
+			Expect(recordRevision().GUID).To(Equal(first.GUID))
+		})
+
+		It("describes the changes since the previous revision", func() {
+			recordRevision()
+
+			baseSecret := envSecret.DeepCopy()
+			envSecret.StringData = map[string]string{"RAILS_ENV": "staging"}
+			Expect(k8sClient.Patch(testCtx, envSecret, client.MergeFrom(baseSecret))).To(Succeed())
+			baseProcess := cfProcess.DeepCopy()
+			cfProcess.Spec.Command = "bundle exec puma"
+			Expect(k8sClient.Patch(testCtx, cfProcess, client.MergeFrom(baseProcess))).To(Succeed())
+
+			revision := recordRevision()
+			Expect(revision.Version).To(Equal(2))
+			Expect(revision.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "staging"}))
+			Expect(revision.Description).To(Equal("New environment variables deployed. Custom start command updated for web process."))
+		})
+
+		It("doesn't give revisions that are recorded concurrently the same version", func() {
+			const count = 5
+			done := make(chan struct{}, count)
+			for i := 0; i < count; i++ {
+				go func() {
+					defer GinkgoRecover()
+					recordRevision()
+					done <- struct{}{}
+				}()
+			}
+			for i := 0; i < count; i++ {
+				<-done
+			}
+
+			revisions, err := revisionRepo.FetchRevisionsForApp(testCtx, k8sClient, cfApp.Name, namespace.Name)
+			Expect(err).NotTo(HaveOccurred())
+			versions := map[int]bool{}
+			for _, revision := range revisions {
+				Expect(versions).NotTo(HaveKey(revision.Version))
+				versions[revision.Version] = true
+			}
+		})
+
+		It("continues from the revisions of an app that were recorded before it had a counter", func() {
+			recordRevision()
+			Expect(k8sClient.Get(testCtx, client.ObjectKeyFromObject(cfApp), cfApp)).To(Succeed())
+			cfApp.Annotations = nil
+			Expect(k8sClient.Update(testCtx, cfApp)).To(Succeed())
+
+			baseProcess := cfProcess.DeepCopy()
+			cfProcess.Spec.Command = "bundle exec puma"
+			Expect(k8sClient.Patch(testCtx, cfProcess, client.MergeFrom(baseProcess))).To(Succeed())
+			Expect(recordRevision().Version).To(Equal(2))
+		})
+
+		When("the app has no droplet", func() {
+			BeforeEach(func() {
+				baseCFApp := cfApp.DeepCopy()
+				cfApp.Spec.CurrentDropletRef = corev1.LocalObjectReference{}
+				Expect(k8sClient.Patch(testCtx, cfApp, client.MergeFrom(baseCFApp))).To(Succeed())
+			})
+
+			It("doesn't record a revision", func() {
+				Expect(recordRevision()).To(Equal(RevisionRecord{}))
+			})
+		})
+	})
+
+	Describe("FetchRevision", func() {
+		It("fetches a revision that is not in the namespace cache", func() {
+			revision := recordRevision()
+
+			fetched, err := NewRevisionRepo(NewGUIDNamespaceCache(), k8sClient).FetchRevision(testCtx, k8sClient, revision.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.GUID).To(Equal(revision.GUID))
+			Expect(fetched.EnvironmentVariables).To(Equal(map[string]string{"RAILS_ENV": "production"}))
+		})
+
+		It("returns a NotFoundError for Secrets that are not revisions", func() {
+			_, err := revisionRepo.FetchRevision(testCtx, k8sClient, envSecret.Name)
+			Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+		})
+	})
+
+	Describe("FetchDeployedRevisions", func() {
+		var revision RevisionRecord
+
+		BeforeEach(func() {
+			revision = recordRevision()
+		})
+
+		It("returns the latest revision with the current droplet of a started app", func() {
+			revisions, err := revisionRepo.FetchDeployedRevisions(testCtx, k8sClient, AppRecord{
+				GUID:        cfApp.Name,
+				SpaceGUID:   namespace.Name,
+				DropletGUID: "droplet-guid",
+				State:       StartedState,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(HaveLen(1))
+			Expect(revisions[0].GUID).To(Equal(revision.GUID))
+		})
+
+		It("returns no revisions for a stopped app", func() {
+			revisions, err := revisionRepo.FetchDeployedRevisions(testCtx, k8sClient, AppRecord{
+				GUID:        cfApp.Name,
+				SpaceGUID:   namespace.Name,
+				DropletGUID: "droplet-guid",
+				State:       StoppedState,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(revisions).To(BeEmpty())
+		})
+	})
+})
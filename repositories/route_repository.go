@@ -3,18 +3,30 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 
 	networkingv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/networking/v1alpha1"
 
+	"github.com/google/uuid"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //+kubebuilder:rbac:groups=networking.cloudfoundry.org,resources=cfroutes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.cloudfoundry.org,resources=cfroutes/status,verbs=get
 
-type RouteRepo struct{}
+type RouteRepo struct {
+	namespaceCache *GUIDNamespaceCache
+}
+
+func NewRouteRepo(namespaceCache *GUIDNamespaceCache) *RouteRepo {
+	return &RouteRepo{namespaceCache: namespaceCache}
+}
 
 type Destination struct {
 	GUID        string
@@ -24,6 +36,24 @@ type Destination struct {
 	// Weight and Protocol intentionally omitted as experimental features
 }
 
+// RouteListMessage selects the routes to list. Empty filters match every route. OrderBy names the field to order the
+// routes by, as in the CF API, prefixed with "-" for descending order.
+type RouteListMessage struct {
+	Hosts       []string
+	Paths       []string
+	DomainGUIDs []string
+	SpaceGUIDs  []string
+	AppGUIDs    []string
+	// LabelSelector selects routes by their labels. It may be nil.
+	LabelSelector labels.Selector
+	OrderBy       string
+	Page          PageRequest
+}
+
+func (m RouteListMessage) isFiltered() bool {
+	return len(m.Hosts) > 0 || len(m.Paths) > 0 || len(m.DomainGUIDs) > 0 || len(m.SpaceGUIDs) > 0 || len(m.AppGUIDs) > 0 || m.OrderBy != ""
+}
+
 type RouteRecord struct {
 	GUID         string
 	SpaceGUID    string
@@ -39,38 +69,104 @@ type RouteRecord struct {
 }
 
 func (f *RouteRepo) FetchRoute(ctx context.Context, client client.Client, routeGUID string) (RouteRecord, error) {
-	// TODO: Could look up namespace from guid => namespace cache to do Get
+	cfRoute := &networkingv1alpha1.CFRoute{}
+	found, err := f.namespaceCache.fetch(ctx, client, routeGUID, cfRoute)
+	if err != nil {
+		return RouteRecord{}, err
+	}
+	if found {
+		return cfRouteToRouteRecord(*cfRoute), nil
+	}
+
 	cfRouteList := &networkingv1alpha1.CFRouteList{}
-	err := client.List(ctx, cfRouteList)
+	err = client.List(ctx, cfRouteList)
 
 	if err != nil {
 		return RouteRecord{}, err
 	}
 
 	routeList := cfRouteList.Items
+	f.cacheNamespaces(routeList)
 	filteredRouteList := f.filterByRouteName(routeList, routeGUID)
 
 	toReturn, err := f.returnRoute(filteredRouteList)
 	return toReturn, err
 }
 
-func (f *RouteRepo) FetchRouteList(ctx context.Context, client client.Client) ([]RouteRecord, error) {
+// FetchRouteList returns the page of routes selected by page, along with the total number of routes
+// FetchRouteList returns the page of routes selected by message, along with the total number of matching routes.
+// The space filter only lists the namespaces of the matching spaces and the label selector is passed on to the List.
+// The other filters and the ordering are applied in memory, so only unfiltered lists are read from the API server a page at a time.
+func (f *RouteRepo) FetchRouteList(ctx context.Context, client client.Client, message RouteListMessage) ([]RouteRecord, int, error) {
 	cfRouteList := &networkingv1alpha1.CFRouteList{}
-	err := client.List(ctx, cfRouteList)
+	if !message.isFiltered() {
+		totalResults, err := listPage(ctx, client, cfRouteList, message.Page, labelSelectorOptions(message.LabelSelector)...)
+		if err != nil {
+			return []RouteRecord{}, 0, err
+		}
+		f.cacheNamespaces(cfRouteList.Items)
 
+		return f.returnRouteList(cfRouteList.Items), totalResults, nil
+	}
+
+	namespaces, err := namespacesToList(ctx, client, message.SpaceGUIDs, nil)
 	if err != nil {
-		return []RouteRecord{}, err
+		return []RouteRecord{}, 0, err
+	}
+	err = listInNamespaces(ctx, client, cfRouteList, namespaces, labelSelectorOptions(message.LabelSelector)...)
+	if err != nil {
+		return []RouteRecord{}, 0, err
+	}
+	f.cacheNamespaces(cfRouteList.Items)
+
+	hostFilter := toMap(message.Hosts)
+	pathFilter := toMap(message.Paths)
+	domainFilter := toMap(message.DomainGUIDs)
+	appFilter := toMap(message.AppGUIDs)
+	var filtered []networkingv1alpha1.CFRoute
+	for _, route := range cfRouteList.Items {
+		if matchFilter(hostFilter, route.Spec.Host) &&
+			matchFilter(pathFilter, route.Spec.Path) &&
+			matchFilter(domainFilter, route.Spec.DomainRef.Name) &&
+			matchDestinationApps(appFilter, route.Spec.Destinations) {
+			filtered = append(filtered, route)
+		}
 	}
 
-	return f.returnRouteList(cfRouteList.Items), nil
+	routeRecords := f.returnRouteList(filtered)
+	sort.SliceStable(routeRecords, lessForOrderBy(message.OrderBy, func(i int, field string) string {
+		if field == "updated_at" {
+			return routeRecords[i].UpdatedAt
+		}
+		return routeRecords[i].CreatedAt
+	}))
+
+	start, end := message.Page.Bounds(len(routeRecords))
+	return routeRecords[start:end], len(routeRecords), nil
 }
 
-func (f *RouteRepo) FetchRoutesForApp(ctx context.Context, k8sClient client.Client, appGUID string, spaceGUID string) ([]RouteRecord, error) {
+func matchDestinationApps(appFilter map[string]struct{}, destinations []networkingv1alpha1.Destination) bool {
+	if len(appFilter) == 0 {
+		return true
+	}
+
+	for _, destination := range destinations {
+		if _, ok := appFilter[destination.AppRef.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchRoutesForApp returns the routes to an app that match labelSelector, which may be nil
+func (f *RouteRepo) FetchRoutesForApp(ctx context.Context, k8sClient client.Client, appGUID string, spaceGUID string, labelSelector labels.Selector) ([]RouteRecord, error) {
 	cfRouteList := &networkingv1alpha1.CFRouteList{}
-	err := k8sClient.List(ctx, cfRouteList, client.InNamespace(spaceGUID))
+	listOptions := append(listOptionsForApp(k8sClient, spaceGUID, appGUID), labelSelectorOptions(labelSelector)...)
+	err := k8sClient.List(ctx, cfRouteList, listOptions...)
 	if err != nil {
 		return []RouteRecord{}, err
 	}
+	f.cacheNamespaces(cfRouteList.Items)
 	filteredRouteList := f.filterByAppDestination(cfRouteList.Items, appGUID)
 
 	return f.returnRouteList(filteredRouteList), nil
@@ -82,6 +178,12 @@ func (r RouteRecord) UpdateDomainRef(d DomainRecord) RouteRecord {
 	return r
 }
 
+func (f *RouteRepo) cacheNamespaces(routeList []networkingv1alpha1.CFRoute) {
+	for _, route := range routeList {
+		f.namespaceCache.Set(route.Name, route.Namespace)
+	}
+}
+
 func (f *RouteRepo) filterByRouteName(routeList []networkingv1alpha1.CFRoute, name string) []networkingv1alpha1.CFRoute {
 	var filtered []networkingv1alpha1.CFRoute
 
@@ -149,6 +251,8 @@ func cfRouteToRouteRecord(cfRoute networkingv1alpha1.CFRoute) RouteRecord {
 		Path:         cfRoute.Spec.Path,
 		Protocol:     "http", // TODO: Create a mutating webhook to set this default on the CFRoute
 		Destinations: destinations,
+		Labels:       cfRoute.Labels,
+		Annotations:  cfRoute.Annotations,
 		CreatedAt:    cfRoute.CreationTimestamp.UTC().Format(TimestampFormat),
 		UpdatedAt:    updatedAtTime,
 	}
@@ -169,10 +273,83 @@ func (f *RouteRepo) CreateRoute(ctx context.Context, client client.Client, route
 	if err != nil {
 		return RouteRecord{}, err
 	}
+	f.namespaceCache.Set(cfRoute.Name, cfRoute.Namespace)
 
 	return f.cfRouteToResponseRoute(cfRoute), err
 }
 
+func (f *RouteRepo) PatchRouteMetadata(ctx context.Context, client client.Client, message MetadataPatchMessage) (RouteRecord, error) {
+	cfRoute := &networkingv1alpha1.CFRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      message.GUID,
+			Namespace: message.SpaceGUID,
+		},
+	}
+
+	err := patchMetadata(ctx, client, cfRoute, message.MetadataPatch)
+	if err != nil {
+		return RouteRecord{}, err
+	}
+	return cfRouteToRouteRecord(*cfRoute), nil
+}
+
+// DestinationMessage is a destination to add to a route. A zero Port leaves the port to the default of the process.
+type DestinationMessage struct {
+	AppGUID     string
+	ProcessType string
+	Port        int
+}
+
+type RouteAddDestinationsMessage struct {
+	RouteGUID       string
+	SpaceGUID       string
+	NewDestinations []DestinationMessage
+}
+
+// AddDestinationsToRoute adds destinations to a route. Destinations that the route already has, with the same app,
+// process type and port, are left as they are.
+func (f *RouteRepo) AddDestinationsToRoute(ctx context.Context, c client.Client, message RouteAddDestinationsMessage) (RouteRecord, error) {
+	cfRoute := &networkingv1alpha1.CFRoute{}
+	err := c.Get(ctx, types.NamespacedName{Name: message.RouteGUID, Namespace: message.SpaceGUID}, cfRoute)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return RouteRecord{}, NotFoundError{Err: err}
+		}
+		return RouteRecord{}, err
+	}
+
+	baseCFRoute := cfRoute.DeepCopy()
+	for _, newDestination := range message.NewDestinations {
+		if hasDestination(cfRoute.Spec.Destinations, newDestination) {
+			continue
+		}
+		cfRoute.Spec.Destinations = append(cfRoute.Spec.Destinations, networkingv1alpha1.Destination{
+			GUID:        uuid.NewString(),
+			Port:        newDestination.Port,
+			AppRef:      v1.LocalObjectReference{Name: newDestination.AppGUID},
+			ProcessType: newDestination.ProcessType,
+		})
+	}
+
+	err = c.Patch(ctx, cfRoute, client.MergeFrom(baseCFRoute))
+	if err != nil {
+		return RouteRecord{}, fmt.Errorf("error adding destinations to route %q: %w", message.RouteGUID, err)
+	}
+
+	return cfRouteToRouteRecord(*cfRoute), nil
+}
+
+func hasDestination(destinations []networkingv1alpha1.Destination, message DestinationMessage) bool {
+	for _, destination := range destinations {
+		if destination.AppRef.Name == message.AppGUID &&
+			destination.ProcessType == message.ProcessType &&
+			destination.Port == message.Port {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *RouteRepo) routeRecordToCFRoute(routeRecord RouteRecord) networkingv1alpha1.CFRoute {
 	return networkingv1alpha1.CFRoute{
 		TypeMeta: metav1.TypeMeta{
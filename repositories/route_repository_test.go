@@ -84,7 +84,7 @@ var _ = Describe("RouteRepository", func() {
 
 			routeRepo = RouteRepo{}
 			var err error
-			repoClient, err = BuildCRClient(k8sConfig)
+			repoClient, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -197,7 +197,7 @@ var _ = Describe("RouteRepository", func() {
 
 			routeRepo = RouteRepo{}
 			var err error
-			repoClient, err = BuildCRClient(k8sConfig)
+			repoClient, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -271,7 +271,7 @@ var _ = Describe("RouteRepository", func() {
 			It("eventually returns a list of routeRecords for each CFRoute CR", func() {
 				var routeRecords []RouteRecord
 				Eventually(func() int {
-					routeRecords, _ = routeRepo.FetchRouteList(testCtx, repoClient)
+					routeRecords, _, _ = routeRepo.FetchRouteList(testCtx, repoClient, RouteListMessage{})
 					return len(routeRecords)
 				}, timeCheckThreshold*time.Second).Should(Equal(2), "returned records count should equal number of created CRs")
 
@@ -358,11 +358,32 @@ var _ = Describe("RouteRepository", func() {
 					})
 				})
 			})
+
+			It("filters the routes by host", func() {
+				routeRecords, totalResults, err := routeRepo.FetchRouteList(testCtx, repoClient, RouteListMessage{Hosts: []string{"my-subdomain-2"}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(routeRecords).To(HaveLen(1))
+				Expect(routeRecords[0].GUID).To(Equal(route2GUID))
+				Expect(totalResults).To(Equal(1))
+			})
+
+			It("filters the routes by the apps of their destinations", func() {
+				routeRecords, _, err := routeRepo.FetchRouteList(testCtx, repoClient, RouteListMessage{AppGUIDs: []string{cfRoute1.Spec.Destinations[0].AppRef.Name}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(routeRecords).To(HaveLen(1))
+				Expect(routeRecords[0].GUID).To(Equal(route1GUID))
+			})
+
+			It("lists no routes from spaces outside the space filter", func() {
+				routeRecords, _, err := routeRepo.FetchRouteList(testCtx, repoClient, RouteListMessage{SpaceGUIDs: []string{"some-other-space"}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(routeRecords).To(BeEmpty())
+			})
 		})
 
 		When("no CFRoutes exist", func() {
 			It("returns an empty list and no error", func() {
-				routeRecords, err := routeRepo.FetchRouteList(testCtx, repoClient)
+				routeRecords, _, err := routeRepo.FetchRouteList(testCtx, repoClient, RouteListMessage{})
 				Expect(err).ToNot(HaveOccurred())
 				Expect(routeRecords).To(BeEmpty())
 			})
@@ -394,7 +415,7 @@ var _ = Describe("RouteRepository", func() {
 
 			routeRepo = RouteRepo{}
 			var err error
-			repoClient, err = BuildCRClient(k8sConfig)
+			repoClient, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).ToNot(HaveOccurred())
 
 			appGUID = generateGUID()
@@ -459,7 +480,7 @@ var _ = Describe("RouteRepository", func() {
 			It("eventually returns a list of routeRecords for each CFRoute CR", func() {
 				var routeRecords []RouteRecord
 				Eventually(func() int {
-					routeRecords, _ = routeRepo.FetchRoutesForApp(testCtx, repoClient, appGUID, testNamespace)
+					routeRecords, _ = routeRepo.FetchRoutesForApp(testCtx, repoClient, appGUID, testNamespace, nil)
 					return len(routeRecords)
 				}, timeCheckThreshold*time.Second).Should(Equal(1), "returned records count should equal number of created CRs with destinations to the App")
 
@@ -511,7 +532,7 @@ var _ = Describe("RouteRepository", func() {
 
 		When("no CFRoutes exist for the app", func() {
 			It("returns an empty list and no error", func() {
-				routeRecords, err := routeRepo.FetchRoutesForApp(testCtx, repoClient, "i-dont-exist", testNamespace)
+				routeRecords, err := routeRepo.FetchRoutesForApp(testCtx, repoClient, "i-dont-exist", testNamespace, nil)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(routeRecords).To(BeEmpty())
 			})
@@ -535,7 +556,7 @@ var _ = Describe("RouteRepository", func() {
 
 		BeforeEach(func() {
 			var err error
-			client, err = BuildCRClient(k8sConfig)
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			routeRepo = RouteRepo{}
@@ -619,6 +640,70 @@ var _ = Describe("RouteRepository", func() {
 			})
 		})
 	})
+
+	Describe("AddDestinationsToRoute", func() {
+		const testNamespace = "default"
+
+		var (
+			client    client.Client
+			routeRepo *RouteRepo
+			testCtx   context.Context
+			routeGUID string
+		)
+
+		BeforeEach(func() {
+			var err error
+			client, err = BuildPrivilegedCRClient(k8sConfig, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			routeRepo = NewRouteRepo(NewGUIDNamespaceCache())
+			testCtx = context.Background()
+			routeGUID = generateGUID()
+
+			cfRoute := initializeRouteCR("my-app", "", routeGUID, generateGUID(), testNamespace)
+			cfRoute.Spec.Destinations = []networkingv1alpha1.Destination{{
+				GUID:        "existing-destination-guid",
+				AppRef:      corev1.LocalObjectReference{Name: "app-guid"},
+				ProcessType: "web",
+			}}
+			Expect(k8sClient.Create(testCtx, &cfRoute)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(cleanupRoute(k8sClient, testCtx, routeGUID, testNamespace)).To(Succeed())
+		})
+
+		It("adds the destinations that the route doesn't have", func() {
+			routeRecord, err := routeRepo.AddDestinationsToRoute(testCtx, client, RouteAddDestinationsMessage{
+				RouteGUID: routeGUID,
+				SpaceGUID: testNamespace,
+				NewDestinations: []DestinationMessage{
+					{AppGUID: "app-guid", ProcessType: "web"},
+					{AppGUID: "other-app-guid", ProcessType: "web"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routeRecord.Destinations).To(HaveLen(2))
+			Expect(routeRecord.Destinations[0].GUID).To(Equal("existing-destination-guid"))
+			Expect(routeRecord.Destinations[1].AppGUID).To(Equal("other-app-guid"))
+			Expect(routeRecord.Destinations[1].GUID).NotTo(BeEmpty())
+
+			cfRoute := new(networkingv1alpha1.CFRoute)
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: routeGUID, Namespace: testNamespace}, cfRoute)).To(Succeed())
+			Expect(cfRoute.Spec.Destinations).To(HaveLen(2))
+			Expect(cfRoute.Spec.Destinations[1].AppRef.Name).To(Equal("other-app-guid"))
+		})
+
+		When("the route doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				_, err := routeRepo.AddDestinationsToRoute(testCtx, client, RouteAddDestinationsMessage{
+					RouteGUID: "no-such-route",
+					SpaceGUID: testNamespace,
+				})
+				Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+			})
+		})
+	})
 })
 
 func initializeRouteCR(routeHost, routePath, routeGUID, domainGUID, spaceGUID string) networkingv1alpha1.CFRoute {
@@ -24,6 +24,33 @@ func (e NotFoundError) Unwrap() error {
 	return e.Err
 }
 
+// ConflictError is returned when a resource could not be changed because it was changed by someone else in the
+// meantime
+type ConflictError struct {
+	Err error
+}
+
+func (e ConflictError) Error() string {
+	return "conflict"
+}
+
+func (e ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ForbiddenError is returned when the user may see a resource, but may not perform the requested action on it
+type ForbiddenError struct {
+	Err error
+}
+
+func (e ForbiddenError) Error() string {
+	return "forbidden"
+}
+
+func (e ForbiddenError) Unwrap() error {
+	return e.Err
+}
+
 type PermissionDeniedOrNotFoundError struct {
 	Err error
 }
@@ -120,3 +120,37 @@ func cleanupApp(k8sClient client.Client, ctx context.Context, appGUID, appNamesp
 func generateAppEnvSecretName(appGUID string) string {
 	return appGUID + "-env"
 }
+
+// createReadyPod creates a pod that runs an instance of the process, as the workload controllers and the kubelet would
+func createReadyPod(k8sClient client.Client, ctx context.Context, processGUID, namespace string) error {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generateGUID(),
+			Namespace: namespace,
+			Labels:    map[string]string{ProcessGUIDPodLabel: processGUID},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "opi", Image: "my-image"}},
+		},
+	}
+	err := k8sClient.Create(ctx, pod)
+	if err != nil {
+		return err
+	}
+
+	pod.Status = corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "opi",
+			Image: "my-image",
+			Ready: true,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}},
+		}},
+	}
+	return k8sClient.Status().Update(ctx, pod)
+}
+
+// deletePods deletes the pods of the process, as the workload controllers would when it stops
+func deletePods(k8sClient client.Client, ctx context.Context, processGUID, namespace string) error {
+	return k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(namespace), client.MatchingLabels{ProcessGUIDPodLabel: processGUID})
+}
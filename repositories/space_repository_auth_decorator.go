@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/hierarchical-namespaces/api/v1alpha2"
+)
+
+//counterfeiter:generate -o fake -fake-name CFSpaceRepository . CFSpaceRepository
+
+type CFSpaceRepository interface {
+	CreateSpace(context context.Context, space SpaceRecord) (SpaceRecord, error)
+	FetchSpace(context context.Context, spaceGUID string) (SpaceRecord, error)
+	FetchSpaces(context context.Context, organizationGUIDs, names []string, labelSelector labels.Selector) ([]SpaceRecord, error)
+	PatchSpaceMetadata(context context.Context, message MetadataPatchMessage) (SpaceRecord, error)
+}
+
+type SpaceRepoAuthDecorator struct {
+	CFSpaceRepository
+	identity      authorization.Identity
+	nsProvider    AuthorizedNamespacesProvider
+	accessChecker authorization.AccessChecker
+}
+
+func NewSpaceRepoAuthDecorator(
+	repo CFSpaceRepository,
+	identity authorization.Identity,
+	nsProvider AuthorizedNamespacesProvider,
+	accessChecker authorization.AccessChecker,
+) *SpaceRepoAuthDecorator {
+	return &SpaceRepoAuthDecorator{
+		CFSpaceRepository: repo,
+		identity:          identity,
+		nsProvider:        nsProvider,
+		accessChecker:     accessChecker,
+	}
+}
+
+func (r *SpaceRepoAuthDecorator) FetchSpaces(ctx context.Context, organizationGUIDs, names []string, labelSelector labels.Selector) ([]SpaceRecord, error) {
+	spaces, err := r.CFSpaceRepository.FetchSpaces(ctx, organizationGUIDs, names, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizedNamespaces, err := r.nsProvider.GetAuthorizedNamespaces(ctx, r.identity)
+	if err != nil {
+		return nil, err
+	}
+
+	spacesFilter := toMap(authorizedNamespaces)
+
+	result := []SpaceRecord{}
+	for _, space := range spaces {
+		if _, ok := spacesFilter[space.GUID]; !ok {
+			continue
+		}
+
+		result = append(result, space)
+	}
+
+	return result, nil
+}
+
+// PatchSpaceMetadata patches the metadata of a space that the user has a role in. The user must be allowed to patch
+// the SubnamespaceAnchor of the space, which lives in the namespace of its org.
+func (r *SpaceRepoAuthDecorator) PatchSpaceMetadata(ctx context.Context, message MetadataPatchMessage) (SpaceRecord, error) {
+	authorizedNamespaces, err := r.nsProvider.GetAuthorizedNamespaces(ctx, r.identity)
+	if err != nil {
+		return SpaceRecord{}, err
+	}
+
+	if _, ok := toMap(authorizedNamespaces)[message.GUID]; !ok {
+		return SpaceRecord{}, NotFoundError{}
+	}
+
+	space, err := r.CFSpaceRepository.FetchSpace(ctx, message.GUID)
+	if err != nil {
+		return SpaceRecord{}, err
+	}
+
+	allowed, err := r.accessChecker.Allowed(ctx, authorizationv1.ResourceAttributes{
+		Namespace: space.OrganizationGUID,
+		Verb:      "patch",
+		Group:     v1alpha2.GroupVersion.Group,
+		Resource:  "subnamespaceanchors",
+		Name:      space.GUID,
+	})
+	if err != nil {
+		return SpaceRecord{}, err
+	}
+	if !allowed {
+		return SpaceRecord{}, ForbiddenError{}
+	}
+
+	return r.CFSpaceRepository.PatchSpaceMetadata(ctx, message)
+}
@@ -0,0 +1,207 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/cf-k8s-api/apis"
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/authorization"
+	authorizationfake "code.cloudfoundry.org/cf-k8s-api/repositories/authorization/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/fake"
+	"code.cloudfoundry.org/cf-k8s-api/repositories/provider"
+	providerfake "code.cloudfoundry.org/cf-k8s-api/repositories/provider/fake"
+	"github.com/go-http-utils/headers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+var _ = Describe("SpaceRepositoryAuthDecorator", func() {
+	var (
+		spaceRepo              *fake.CFSpaceRepository
+		spaceRepoAuthDecorator apis.CFSpaceRepository
+		spaceRepoProvider      *provider.SpaceRepositoryProvider
+		nsProvider             *fake.AuthorizedNamespacesProvider
+		identityProvider       *providerfake.IdentityProvider
+		accessReviewer         *providerfake.AccessReviewer
+		accessChecker          *authorizationfake.AccessChecker
+		err                    error
+	)
+
+	BeforeEach(func() {
+		identityProvider = new(providerfake.IdentityProvider)
+		identityProvider.GetIdentityReturns(authorization.Identity{Kind: rbacv1.UserKind, Name: "alice"}, nil)
+		accessChecker = new(authorizationfake.AccessChecker)
+		accessChecker.AllowedReturns(true, nil)
+		accessReviewer = new(providerfake.AccessReviewer)
+		accessReviewer.ForAuthorizationHeaderReturns(accessChecker, nil)
+		spaceRepo = new(fake.CFSpaceRepository)
+		spaceRepo.FetchSpacesReturns([]repositories.SpaceRecord{
+			{GUID: "space1", OrganizationGUID: "org1"},
+			{GUID: "space2", OrganizationGUID: "org1"},
+		}, nil)
+		nsProvider = new(fake.AuthorizedNamespacesProvider)
+		nsProvider.GetAuthorizedNamespacesReturns([]string{"space2"}, nil)
+		spaceRepoProvider = provider.NewSpace(spaceRepo, nsProvider, identityProvider, accessReviewer)
+	})
+
+	Describe("creation", func() {
+		JustBeforeEach(func() {
+			spaceRepoAuthDecorator, err = spaceRepoProvider.SpaceRepoForRequest(&http.Request{
+				Header: http.Header{
+					headers.Authorization: []string{"bearer the-token"},
+				},
+			})
+		})
+
+		It("gets built from the Authorization header", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(identityProvider.GetIdentityCallCount()).To(Equal(1))
+			_, bearerToken := identityProvider.GetIdentityArgsForCall(0)
+			Expect(bearerToken).To(Equal("bearer the-token"))
+			Expect(accessReviewer.ForAuthorizationHeaderCallCount()).To(Equal(1))
+			Expect(accessReviewer.ForAuthorizationHeaderArgsForCall(0)).To(Equal("bearer the-token"))
+		})
+
+		When("identity provider fails", func() {
+			BeforeEach(func() {
+				identityProvider.GetIdentityReturns(authorization.Identity{}, authorization.UnauthorizedErr{})
+			})
+
+			It("returns the error", func() {
+				Expect(authorization.IsUnauthorized(err)).To(BeTrue())
+			})
+		})
+
+		When("the access reviewer fails", func() {
+			BeforeEach(func() {
+				accessReviewer.ForAuthorizationHeaderReturns(nil, errors.New("access-reviewer-failure"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("access-reviewer-failure"))
+			})
+		})
+	})
+
+	Describe("fetching spaces", func() {
+		var spaces []repositories.SpaceRecord
+
+		BeforeEach(func() {
+			spaceRepoAuthDecorator, err = spaceRepoProvider.SpaceRepoForRequest(&http.Request{
+				Header: http.Header{
+					headers.Authorization: []string{"bearer the-token"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			spaces, err = spaceRepoAuthDecorator.FetchSpaces(context.Background(), nil, nil, nil)
+		})
+
+		It("fetches spaces associated with the identity only", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spaces).To(ConsistOf(repositories.SpaceRecord{GUID: "space2", OrganizationGUID: "org1"}))
+		})
+
+		When("fetching authorized namespaces fails", func() {
+			BeforeEach(func() {
+				nsProvider.GetAuthorizedNamespacesReturns(nil, errors.New("fetch-auth-ns-failed"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("fetch-auth-ns-failed"))
+			})
+		})
+	})
+
+	Describe("patching space metadata", func() {
+		var (
+			spaceGUID string
+			space     repositories.SpaceRecord
+		)
+
+		BeforeEach(func() {
+			spaceRepoAuthDecorator, err = spaceRepoProvider.SpaceRepoForRequest(&http.Request{
+				Header: http.Header{
+					headers.Authorization: []string{"bearer the-token"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			spaceGUID = "space2"
+			spaceRepo.FetchSpaceReturns(repositories.SpaceRecord{GUID: "space2", OrganizationGUID: "org1"}, nil)
+			spaceRepo.PatchSpaceMetadataReturns(repositories.SpaceRecord{GUID: "space2", Labels: map[string]string{"foo": "bar"}}, nil)
+		})
+
+		JustBeforeEach(func() {
+			space, err = spaceRepoAuthDecorator.PatchSpaceMetadata(context.Background(), repositories.MetadataPatchMessage{GUID: spaceGUID})
+		})
+
+		It("checks that the user may patch the anchor of the space in its org", func() {
+			Expect(accessChecker.AllowedCallCount()).To(Equal(1))
+			_, attributes := accessChecker.AllowedArgsForCall(0)
+			Expect(attributes).To(Equal(authorizationv1.ResourceAttributes{
+				Namespace: "org1",
+				Verb:      "patch",
+				Group:     "hnc.x-k8s.io",
+				Resource:  "subnamespaceanchors",
+				Name:      "space2",
+			}))
+		})
+
+		It("patches the space", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(space).To(Equal(repositories.SpaceRecord{GUID: "space2", Labels: map[string]string{"foo": "bar"}}))
+			Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(1))
+		})
+
+		When("the space is not associated with the identity", func() {
+			BeforeEach(func() {
+				spaceGUID = "space1"
+			})
+
+			It("returns a NotFoundError without patching the space", func() {
+				Expect(err).To(BeAssignableToTypeOf(repositories.NotFoundError{}))
+				Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the user may not patch the space", func() {
+			BeforeEach(func() {
+				accessChecker.AllowedReturns(false, nil)
+			})
+
+			It("returns a ForbiddenError without patching the space", func() {
+				Expect(err).To(BeAssignableToTypeOf(repositories.ForbiddenError{}))
+				Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("the access cannot be checked", func() {
+			BeforeEach(func() {
+				accessChecker.AllowedReturns(false, errors.New("access-check-failed"))
+			})
+
+			It("returns the error without patching the space", func() {
+				Expect(err).To(MatchError("access-check-failed"))
+				Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(0))
+			})
+		})
+
+		When("fetching the space fails", func() {
+			BeforeEach(func() {
+				spaceRepo.FetchSpaceReturns(repositories.SpaceRecord{}, errors.New("fetch-space-failed"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("fetch-space-failed"))
+				Expect(spaceRepo.PatchSpaceMetadataCallCount()).To(Equal(0))
+			})
+		})
+	})
+})
@@ -0,0 +1,414 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;patch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list
+
+const (
+	TaskStatePending   = "PENDING"
+	TaskStateRunning   = "RUNNING"
+	TaskStateSucceeded = "SUCCEEDED"
+	TaskStateFailed    = "FAILED"
+	TaskStateCanceling = "CANCELING"
+
+	// TaskLabel marks the Jobs that run tasks. The app of a task is in the CFAppGUIDLabelKey label.
+	TaskLabel           = "cloudfoundry.org/task"
+	TaskSequenceIDLabel = "cloudfoundry.org/task-sequence-id"
+
+	taskNameAnnotation        = "cloudfoundry.org/task-name"
+	taskDropletGUIDAnnotation = "cloudfoundry.org/droplet-guid"
+	taskCanceledAnnotation    = "cloudfoundry.org/task-canceled"
+	// taskSequenceAnnotation on a CFApp holds the sequence ID of the last task of the app
+	taskSequenceAnnotation = "cloudfoundry.org/task-sequence-id"
+
+	// jobNameLabel is set on the pods of a Job by the Job controller
+	jobNameLabel = "job-name"
+
+	taskContainerName = "task"
+	// taskLauncher runs the command of a task in the environment of the buildpacks of a buildpack droplet
+	taskLauncher = "/cnb/lifecycle/launcher"
+
+	taskCanceledReason = "task was cancelled"
+)
+
+// Tasks run as Jobs named after the task GUID in the namespace of the space of their app. A Job runs the command of
+// its task once in a pod with the image of the droplet of the task and the environment of the app, and is not retried.
+// Canceling a task suspends its Job, which deletes its pod. Jobs are deleted once they have finished for the TTL of the
+// TaskRepo.
+
+type TaskRecord struct {
+	GUID        string
+	SequenceID  int
+	Name        string
+	Command     string
+	State       string
+	MemoryMB    int64
+	DiskMB      int64
+	AppGUID     string
+	DropletGUID string
+	SpaceGUID   string
+	// FailureReason is empty unless the task has failed
+	FailureReason string
+	CreatedAt     string
+	UpdatedAt     string
+}
+
+type TaskCreateMessage struct {
+	Name     string
+	Command  string
+	MemoryMB int64
+	DiskMB   int64
+	App      AppRecord
+	Droplet  DropletRecord
+}
+
+// TaskListMessage filters lists of tasks. Empty filters match every task.
+type TaskListMessage struct {
+	AppGUIDs []string
+}
+
+// TaskNotCancelableError is returned when a task has already finished
+type TaskNotCancelableError struct {
+	State string
+}
+
+func (e TaskNotCancelableError) Error() string {
+	return fmt.Sprintf("Task state is %s and therefore cannot be canceled", e.State)
+}
+
+type TaskRepo struct {
+	namespaceCache *GUIDNamespaceCache
+	ttl            time.Duration
+}
+
+func NewTaskRepo(namespaceCache *GUIDNamespaceCache, ttl time.Duration) *TaskRepo {
+	return &TaskRepo{namespaceCache: namespaceCache, ttl: ttl}
+}
+
+// CreateTask starts a Job that runs the command of a task with the droplet and environment of its app. Sequence IDs
+// count the tasks of an app from 1 and are allocated from a counter in an annotation of the CFApp of the app, so tasks
+// that are created concurrently get different sequence IDs.
+func (r *TaskRepo) CreateTask(ctx context.Context, c client.Client, message TaskCreateMessage) (TaskRecord, error) {
+	sequenceID, err := nextAppCounterValue(ctx, c, message.App.GUID, message.App.SpaceGUID, taskSequenceAnnotation, func() (int, error) {
+		return highestTaskSequenceID(ctx, c, message.App)
+	})
+	if err != nil {
+		return TaskRecord{}, fmt.Errorf("error allocating sequence ID of task: %w", err)
+	}
+
+	ownerReference, err := fetchAppOwnerReference(ctx, c, message.App.GUID, message.App.SpaceGUID)
+	if err != nil {
+		return TaskRecord{}, err
+	}
+
+	job := taskJob(uuid.NewString(), sequenceID, r.ttl, message)
+	job.OwnerReferences = []metav1.OwnerReference{ownerReference}
+	err = c.Create(ctx, job)
+	if err != nil {
+		return TaskRecord{}, fmt.Errorf("error creating task: %w", err)
+	}
+	r.namespaceCache.Set(job.Name, job.Namespace)
+
+	return jobToTaskRecord(*job, false), nil
+}
+
+// highestTaskSequenceID returns the highest sequence ID of the tasks of app, for apps whose tasks were created before
+// their CFApp counted them
+func highestTaskSequenceID(ctx context.Context, c client.Client, app AppRecord) (int, error) {
+	jobList := &batchv1.JobList{}
+	err := c.List(ctx, jobList, client.InNamespace(app.SpaceGUID), client.MatchingLabels{workloadsv1alpha1.CFAppGUIDLabelKey: app.GUID}, client.HasLabels{TaskLabel})
+	if err != nil {
+		return 0, fmt.Errorf("error listing tasks of app %q: %w", app.GUID, err)
+	}
+
+	highest := 0
+	for _, job := range jobList.Items {
+		if id, err := strconv.Atoi(job.Labels[TaskSequenceIDLabel]); err == nil && id > highest {
+			highest = id
+		}
+	}
+	return highest, nil
+}
+
+func (r *TaskRepo) FetchTask(ctx context.Context, c client.Client, guid string) (TaskRecord, error) {
+	job := &batchv1.Job{}
+	found, err := r.namespaceCache.fetch(ctx, c, guid, job)
+	if err != nil {
+		return TaskRecord{}, err
+	}
+	if found {
+		if _, ok := job.Labels[TaskLabel]; !ok {
+			return TaskRecord{}, NotFoundError{}
+		}
+		return fetchTaskRecord(ctx, c, *job)
+	}
+
+	jobList := &batchv1.JobList{}
+	err = c.List(ctx, jobList, client.HasLabels{TaskLabel})
+	if err != nil {
+		return TaskRecord{}, fmt.Errorf("error listing tasks: %w", err)
+	}
+	for _, job := range jobList.Items {
+		r.namespaceCache.Set(job.Name, job.Namespace)
+	}
+	for _, job := range jobList.Items {
+		if job.Name == guid {
+			return fetchTaskRecord(ctx, c, job)
+		}
+	}
+
+	return TaskRecord{}, NotFoundError{}
+}
+
+// FetchTaskList returns the tasks selected by message in all namespaces the user can read, oldest first
+func (r *TaskRepo) FetchTaskList(ctx context.Context, c client.Client, message TaskListMessage) ([]TaskRecord, error) {
+	jobList := &batchv1.JobList{}
+	err := c.List(ctx, jobList, client.HasLabels{TaskLabel})
+	if err != nil {
+		return nil, fmt.Errorf("error listing tasks: %w", err)
+	}
+
+	appFilter := toMap(message.AppGUIDs)
+	jobs := make([]batchv1.Job, 0, len(jobList.Items))
+	for _, job := range jobList.Items {
+		if matchFilter(appFilter, job.Labels[workloadsv1alpha1.CFAppGUIDLabelKey]) {
+			jobs = append(jobs, job)
+		}
+	}
+
+	readyJobs, err := jobsWithReadyPod(ctx, c, jobs)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]TaskRecord, 0, len(jobs))
+	for _, job := range jobs {
+		tasks = append(tasks, jobToTaskRecord(job, readyJobs[client.ObjectKeyFromObject(&job)]))
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt != tasks[j].CreatedAt {
+			return tasks[i].CreatedAt < tasks[j].CreatedAt
+		}
+		return tasks[i].GUID < tasks[j].GUID
+	})
+
+	return tasks, nil
+}
+
+// CancelTask suspends the Job of a task, which deletes its pod, and marks the task as canceled. Tasks that have
+// already succeeded or failed cannot be canceled.
+func (r *TaskRepo) CancelTask(ctx context.Context, c client.Client, task TaskRecord) (TaskRecord, error) {
+	if task.State == TaskStateSucceeded || task.State == TaskStateFailed {
+		return TaskRecord{}, TaskNotCancelableError{State: task.State}
+	}
+
+	job := &batchv1.Job{}
+	err := c.Get(ctx, client.ObjectKey{Name: task.GUID, Namespace: task.SpaceGUID}, job)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return TaskRecord{}, NotFoundError{Err: err}
+		}
+		return TaskRecord{}, err
+	}
+
+	baseJob := job.DeepCopy()
+	suspend := true
+	job.Spec.Suspend = &suspend
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[taskCanceledAnnotation] = "true"
+	err = c.Patch(ctx, job, client.MergeFrom(baseJob))
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return TaskRecord{}, NotFoundError{Err: err}
+		}
+		return TaskRecord{}, fmt.Errorf("error canceling task %q: %w", task.GUID, err)
+	}
+
+	return jobToTaskRecord(*job, false), nil
+}
+
+func taskJob(guid string, sequenceID int, ttl time.Duration, message TaskCreateMessage) *batchv1.Job {
+	var backoffLimit int32 = 0
+	ttlSecondsAfterFinished := int32(ttl.Seconds())
+	memory := *resource.NewQuantity(message.MemoryMB*mebibyte, resource.BinarySI)
+	disk := *resource.NewQuantity(message.DiskMB*mebibyte, resource.BinarySI)
+
+	imagePullSecrets := []corev1.LocalObjectReference{}
+	for _, secret := range message.Droplet.ImagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+
+	container := corev1.Container{
+		Name:    taskContainerName,
+		Image:   message.Droplet.Image,
+		Command: []string{taskLauncher},
+		Args:    []string{message.Command},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory:           memory,
+				corev1.ResourceEphemeralStorage: disk,
+			},
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory:           memory,
+				corev1.ResourceEphemeralStorage: disk,
+			},
+		},
+	}
+	if message.App.EnvSecretName != "" {
+		container.EnvFrom = []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: message.App.EnvSecretName}},
+		}}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      guid,
+			Namespace: message.App.SpaceGUID,
+			Labels: map[string]string{
+				TaskLabel:                           "true",
+				TaskSequenceIDLabel:                 strconv.Itoa(sequenceID),
+				workloadsv1alpha1.CFAppGUIDLabelKey: message.App.GUID,
+			},
+			Annotations: map[string]string{
+				taskNameAnnotation:        message.Name,
+				taskDropletGUIDAnnotation: message.Droplet.GUID,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						workloadsv1alpha1.CFAppGUIDLabelKey: message.App.GUID,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Containers:       []corev1.Container{container},
+					ImagePullSecrets: imagePullSecrets,
+				},
+			},
+		},
+	}
+}
+
+// fetchTaskRecord returns the task run by job, looking up whether its pod is ready
+func fetchTaskRecord(ctx context.Context, c client.Client, job batchv1.Job) (TaskRecord, error) {
+	readyJobs, err := jobsWithReadyPod(ctx, c, []batchv1.Job{job})
+	if err != nil {
+		return TaskRecord{}, err
+	}
+	return jobToTaskRecord(job, readyJobs[client.ObjectKeyFromObject(&job)]), nil
+}
+
+// jobsWithReadyPod returns the Jobs of jobs that have a ready pod. Only the pods of Jobs with active pods are listed, as
+// the pods of other Jobs don't change the state of their tasks.
+func jobsWithReadyPod(ctx context.Context, c client.Client, jobs []batchv1.Job) (map[types.NamespacedName]bool, error) {
+	namespaces := map[string]bool{}
+	for _, job := range jobs {
+		if job.Status.Active > 0 {
+			namespaces[job.Namespace] = true
+		}
+	}
+
+	readyJobs := map[types.NamespacedName]bool{}
+	for namespace := range namespaces {
+		podList := &corev1.PodList{}
+		err := c.List(ctx, podList, client.InNamespace(namespace), client.HasLabels{jobNameLabel})
+		if err != nil {
+			return nil, fmt.Errorf("error listing task pods: %w", err)
+		}
+		for _, pod := range podList.Items {
+			if isPodReady(pod) {
+				readyJobs[types.NamespacedName{Name: pod.Labels[jobNameLabel], Namespace: pod.Namespace}] = true
+			}
+		}
+	}
+	return readyJobs, nil
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// jobToTaskRecord returns the task run by job. podReady tells whether a pod of the Job is ready, which is when the
+// command of the task has started.
+func jobToTaskRecord(job batchv1.Job, podReady bool) TaskRecord {
+	updatedAt, _ := getTimeLastUpdatedTimestamp(&job.ObjectMeta)
+	sequenceID, _ := strconv.Atoi(job.Labels[TaskSequenceIDLabel])
+
+	task := TaskRecord{
+		GUID:        job.Name,
+		SequenceID:  sequenceID,
+		Name:        job.Annotations[taskNameAnnotation],
+		AppGUID:     job.Labels[workloadsv1alpha1.CFAppGUIDLabelKey],
+		DropletGUID: job.Annotations[taskDropletGUIDAnnotation],
+		SpaceGUID:   job.Namespace,
+		CreatedAt:   formatTimestamp(job.CreationTimestamp),
+		UpdatedAt:   updatedAt,
+	}
+
+	if containers := job.Spec.Template.Spec.Containers; len(containers) > 0 {
+		if len(containers[0].Args) > 0 {
+			task.Command = containers[0].Args[0]
+		}
+		task.MemoryMB = containers[0].Resources.Limits.Memory().Value() / 1024 / 1024
+		task.DiskMB = containers[0].Resources.Limits.StorageEphemeral().Value() / 1024 / 1024
+	}
+
+	task.State, task.FailureReason = taskState(job, podReady)
+	return task
+}
+
+// taskState maps the status of the Job of a task to the state of the task and the reason it failed. An active pod is
+// still pulling its image or starting its container until it is ready, so the task is only running once it is.
+func taskState(job batchv1.Job, podReady bool) (string, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return TaskStateSucceeded, ""
+		case batchv1.JobFailed:
+			return TaskStateFailed, condition.Message
+		}
+	}
+
+	if job.Annotations[taskCanceledAnnotation] == "true" {
+		if job.Status.Active > 0 {
+			return TaskStateCanceling, ""
+		}
+		return TaskStateFailed, taskCanceledReason
+	}
+
+	if job.Status.Active > 0 && podReady {
+		return TaskStateRunning, ""
+	}
+	return TaskStatePending, ""
+}
@@ -0,0 +1,279 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+	workloadsv1alpha1 "code.cloudfoundry.org/cf-k8s-controllers/apis/workloads/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("TaskRepo", func() {
+	var (
+		testCtx   context.Context
+		taskRepo  *TaskRepo
+		namespace *corev1.Namespace
+		app       AppRecord
+		droplet   DropletRecord
+	)
+
+	createTask := func(name string) TaskRecord {
+		task, err := taskRepo.CreateTask(testCtx, k8sClient, TaskCreateMessage{
+			Name:     name,
+			Command:  "rake db:migrate",
+			MemoryMB: 256,
+			DiskMB:   512,
+			App:      app,
+			Droplet:  droplet,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return task
+	}
+
+	fetchTaskContainer := func(guid string) corev1.Container {
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: guid, Namespace: namespace.Name}, job)).To(Succeed())
+		return job.Spec.Template.Spec.Containers[0]
+	}
+
+	updateJobStatus := func(guid string, status batchv1.JobStatus) {
+		job := &batchv1.Job{}
+		Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: guid, Namespace: namespace.Name}, job)).To(Succeed())
+		job.Status = status
+		Expect(k8sClient.Status().Update(testCtx, job)).To(Succeed())
+	}
+
+	createTaskPod := func(guid string, ready corev1.ConditionStatus) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      guid + "-pod",
+				Namespace: namespace.Name,
+				Labels:    map[string]string{"job-name": guid},
+			},
+			Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers:    []corev1.Container{{Name: "task", Image: "registry/droplet:tag"}},
+			},
+		}
+		Expect(k8sClient.Create(testCtx, pod)).To(Succeed())
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+		Expect(k8sClient.Status().Update(testCtx, pod)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		testCtx = context.Background()
+		taskRepo = NewTaskRepo(NewGUIDNamespaceCache(), time.Hour)
+
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: generateGUID()}}
+		Expect(k8sClient.Create(testCtx, namespace)).To(Succeed())
+
+		app = AppRecord{GUID: generateGUID(), SpaceGUID: namespace.Name, EnvSecretName: "app-env"}
+		Expect(k8sClient.Create(testCtx, initializeAppCR("app", app.GUID, namespace.Name))).To(Succeed())
+		droplet = DropletRecord{GUID: generateGUID(), Image: "registry/droplet:tag", ImagePullSecrets: []string{"registry-secret"}}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(testCtx, namespace)).To(Succeed())
+	})
+
+	Describe("CreateTask", func() {
+		It("runs the command once with the droplet and environment of the app", func() {
+			task := createTask("migrate")
+			Expect(task.Name).To(Equal("migrate"))
+			Expect(task.SequenceID).To(Equal(1))
+			Expect(task.State).To(Equal(TaskStatePending))
+			Expect(task.MemoryMB).To(BeEquivalentTo(256))
+			Expect(task.DiskMB).To(BeEquivalentTo(512))
+			Expect(task.DropletGUID).To(Equal(droplet.GUID))
+
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: task.GUID, Namespace: namespace.Name}, job)).To(Succeed())
+			Expect(job.Labels).To(HaveKey(TaskLabel))
+			Expect(job.Labels).To(HaveKeyWithValue(workloadsv1alpha1.CFAppGUIDLabelKey, app.GUID))
+			Expect(*job.Spec.BackoffLimit).To(BeEquivalentTo(0))
+			Expect(*job.Spec.TTLSecondsAfterFinished).To(BeEquivalentTo(3600))
+			Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(job.Spec.Template.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "registry-secret"}}))
+
+			container := job.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal("registry/droplet:tag"))
+			Expect(container.Args).To(Equal([]string{"rake db:migrate"}))
+			Expect(container.EnvFrom[0].SecretRef.Name).To(Equal("app-env"))
+			Expect(container.Resources.Limits.Memory().Equal(resource.MustParse("256Mi"))).To(BeTrue())
+			Expect(container.Resources.Limits.StorageEphemeral().Equal(resource.MustParse("512Mi"))).To(BeTrue())
+		})
+
+		It("numbers the tasks of an app", func() {
+			createTask("first")
+			Expect(createTask("second").SequenceID).To(Equal(2))
+		})
+
+		It("makes the app the owner of the job, so it is deleted with the app", func() {
+			task := createTask("migrate")
+
+			cfApp := &workloadsv1alpha1.CFApp{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: app.GUID, Namespace: namespace.Name}, cfApp)).To(Succeed())
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: task.GUID, Namespace: namespace.Name}, job)).To(Succeed())
+			Expect(job.OwnerReferences).To(Equal([]metav1.OwnerReference{{
+				APIVersion: APIVersion,
+				Kind:       Kind,
+				Name:       cfApp.Name,
+				UID:        cfApp.UID,
+			}}))
+		})
+
+		It("gives tasks that are created concurrently different sequence IDs", func() {
+			const count = 5
+			sequenceIDs := make(chan int, count)
+			for i := 0; i < count; i++ {
+				go func() {
+					defer GinkgoRecover()
+					sequenceIDs <- createTask("concurrent").SequenceID
+				}()
+			}
+
+			received := []int{}
+			for i := 0; i < count; i++ {
+				received = append(received, <-sequenceIDs)
+			}
+			Expect(received).To(ConsistOf(1, 2, 3, 4, 5))
+		})
+
+		It("continues from the tasks of an app that were created before it had a counter", func() {
+			Expect(createTask("first").SequenceID).To(Equal(1))
+			cfApp := &workloadsv1alpha1.CFApp{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: app.GUID, Namespace: namespace.Name}, cfApp)).To(Succeed())
+			cfApp.Annotations = nil
+			Expect(k8sClient.Update(testCtx, cfApp)).To(Succeed())
+
+			Expect(createTask("second").SequenceID).To(Equal(2))
+		})
+
+		When("the app doesn't exist", func() {
+			It("returns a NotFoundError", func() {
+				_, err := taskRepo.CreateTask(testCtx, k8sClient, TaskCreateMessage{Name: "task", Command: "true", MemoryMB: 1, DiskMB: 1, App: AppRecord{GUID: "no-such-app", SpaceGUID: namespace.Name}, Droplet: droplet})
+				Expect(err).To(MatchError(ContainSubstring("not found")))
+				Expect(errors.As(err, new(NotFoundError))).To(BeTrue())
+			})
+		})
+
+		It("runs the command of a buildpack droplet with the buildpacks launcher", func() {
+			container := fetchTaskContainer(createTask("migrate").GUID)
+			Expect(container.Command).To(Equal([]string{"/cnb/lifecycle/launcher"}))
+		})
+	})
+
+	Describe("FetchTask", func() {
+		It("fetches a task that is not in the namespace cache", func() {
+			task := createTask("migrate")
+
+			fetched, err := NewTaskRepo(NewGUIDNamespaceCache(), time.Hour).FetchTask(testCtx, k8sClient, task.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.Name).To(Equal("migrate"))
+			Expect(fetched.Command).To(Equal("rake db:migrate"))
+			Expect(fetched.AppGUID).To(Equal(app.GUID))
+			Expect(fetched.SpaceGUID).To(Equal(namespace.Name))
+		})
+
+		It("returns a NotFoundError when the task doesn't exist", func() {
+			_, err := taskRepo.FetchTask(testCtx, k8sClient, "no-such-task")
+			Expect(err).To(BeAssignableToTypeOf(NotFoundError{}))
+		})
+
+		It("maps the status of the Job to the state of the task", func() {
+			task := createTask("migrate")
+
+			updateJobStatus(task.GUID, batchv1.JobStatus{Active: 1})
+			fetched, err := taskRepo.FetchTask(testCtx, k8sClient, task.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.State).To(Equal(TaskStatePending))
+
+			createTaskPod(task.GUID, corev1.ConditionTrue)
+			fetched, err = taskRepo.FetchTask(testCtx, k8sClient, task.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.State).To(Equal(TaskStateRunning))
+
+			updateJobStatus(task.GUID, batchv1.JobStatus{
+				Failed: 1,
+				Conditions: []batchv1.JobCondition{{
+					Type:    batchv1.JobFailed,
+					Status:  corev1.ConditionTrue,
+					Reason:  "BackoffLimitExceeded",
+					Message: "Job has reached the specified backoff limit",
+				}},
+			})
+			fetched, err = taskRepo.FetchTask(testCtx, k8sClient, task.GUID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched.State).To(Equal(TaskStateFailed))
+			Expect(fetched.FailureReason).To(Equal("Job has reached the specified backoff limit"))
+		})
+	})
+
+	Describe("FetchTaskList", func() {
+		It("filters the tasks by app", func() {
+			task := createTask("migrate")
+			otherApp := app
+			otherApp.GUID = generateGUID()
+			Expect(k8sClient.Create(testCtx, initializeAppCR("other-app", otherApp.GUID, namespace.Name))).To(Succeed())
+			_, err := taskRepo.CreateTask(testCtx, k8sClient, TaskCreateMessage{Name: "other", Command: "true", MemoryMB: 1, DiskMB: 1, App: otherApp, Droplet: droplet})
+			Expect(err).NotTo(HaveOccurred())
+
+			tasks, err := taskRepo.FetchTaskList(testCtx, k8sClient, TaskListMessage{AppGUIDs: []string{app.GUID}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].GUID).To(Equal(task.GUID))
+		})
+
+		It("reports tasks as running once their pod is ready", func() {
+			startingTask := createTask("starting")
+			updateJobStatus(startingTask.GUID, batchv1.JobStatus{Active: 1})
+			createTaskPod(startingTask.GUID, corev1.ConditionFalse)
+			runningTask := createTask("running")
+			updateJobStatus(runningTask.GUID, batchv1.JobStatus{Active: 1})
+			createTaskPod(runningTask.GUID, corev1.ConditionTrue)
+
+			tasks, err := taskRepo.FetchTaskList(testCtx, k8sClient, TaskListMessage{AppGUIDs: []string{app.GUID}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(ConsistOf(
+				MatchFields(IgnoreExtras, Fields{"GUID": Equal(startingTask.GUID), "State": Equal(TaskStatePending)}),
+				MatchFields(IgnoreExtras, Fields{"GUID": Equal(runningTask.GUID), "State": Equal(TaskStateRunning)}),
+			))
+		})
+	})
+
+	Describe("CancelTask", func() {
+		It("suspends the Job of a running task", func() {
+			task := createTask("migrate")
+			updateJobStatus(task.GUID, batchv1.JobStatus{Active: 1})
+			task.State = TaskStateRunning
+
+			canceled, err := taskRepo.CancelTask(testCtx, k8sClient, task)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(canceled.State).To(Equal(TaskStateCanceling))
+
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(testCtx, types.NamespacedName{Name: task.GUID, Namespace: namespace.Name}, job)).To(Succeed())
+			Expect(*job.Spec.Suspend).To(BeTrue())
+		})
+
+		It("returns a TaskNotCancelableError for finished tasks", func() {
+			task := createTask("migrate")
+			task.State = TaskStateSucceeded
+
+			_, err := taskRepo.CancelTask(testCtx, k8sClient, task)
+			Expect(err).To(MatchError(TaskNotCancelableError{State: TaskStateSucceeded}))
+		})
+	})
+})
@@ -1,48 +1,41 @@
 package repositories
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"mime/multipart"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/buildpacks/pack/pkg/archive"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/stream"
 )
 
-func UploadSourceImage(imageRef string, packageSrcFile multipart.File, credentialOption remote.Option) (imageRefWithDigest string, err error) {
-	tmpFile, err := ioutil.TempFile(os.TempDir(), fmt.Sprintf("source-%s", path.Base(imageRef)))
-	if err != nil {
-		return "", fmt.Errorf("error from ioutil.TempFile: %w", err)
-	}
-	defer os.Remove(tmpFile.Name())
-
-	if _, err = io.Copy(tmpFile, packageSrcFile); err != nil {
-		return "", fmt.Errorf("error from io.Copy: %w", err)
-	}
+const (
+	zipCreatorUnix   = 3
+	zipCreatorMacOSX = 19
 
-	if err := tmpFile.Close(); err != nil {
-		return "", fmt.Errorf("error from ioutil.TempFile: %w", err)
-	}
+	// sourceFileMode is the mode of the files of source images whose zip entries have no Unix permissions, as written
+	// by zip tools on Windows, and of cached resources without a mode
+	sourceFileMode = 0755
+)
 
+// UploadSourceImage pushes the tar of the app source read from sourceTar, as made by ReadPackageAsTar, as a single
+// layer image. The layer is pushed as it is read, so the source is never held in memory whole.
+func UploadSourceImage(imageRef string, sourceTar io.Reader, credentialOption remote.Option) (imageRefWithDigest string, err error) {
 	image, err := random.Image(0, 0)
 	if err != nil {
 		return "", fmt.Errorf("error from random.Image: %w", err)
 	}
 
-	noopFilter := func(string) bool { return true }
-	layer, err := tarball.LayerFromReader(archive.ReadZipAsTar(tmpFile.Name(), "/", 0, 0, -1, true, noopFilter))
-	if err != nil {
-		return "", fmt.Errorf("error from tarball.LayerFromReader: %w", err)
-	}
-
-	image, err = mutate.AppendLayers(image, layer)
+	image, err = mutate.AppendLayers(image, stream.NewLayer(ioutil.NopCloser(sourceTar)))
 	if err != nil {
 		return "", fmt.Errorf("error from mutate.AppendLayers: %w", err)
 	}
@@ -69,3 +62,174 @@ func UploadSourceImage(imageRef string, packageSrcFile multipart.File, credentia
 
 	return refWithDigest.Name(), nil
 }
+
+// ReadZipAsTar converts the zip archive read from src to a tar of its files under basePath, like archive.ReadZipAsTar
+// does for a zip file. src is spooled to a temp file in os.TempDir(), which is removed once the tar has been read.
+// Files, directories and symlinks keep the modes of their entries.
+//
+// Unlike with archive.GenerateTar, an error converting the zip is returned by the reader rather than ending the tar
+// early, so that a broken upload is never pushed. Closing the reader waits until src is no longer read.
+func ReadZipAsTar(src io.Reader, basePath string) io.ReadCloser {
+	return readPackageAsTar(src, nil, basePath, nil)
+}
+
+// ReadPackageAsTar converts the zip read from src like ReadZipAsTar, adding its files to the store, and appends
+// cachedResources from the store at their paths. src may be nil for a package made of cached resources only. The zip
+// is spooled to a temp file, as the modes of its files are in the central directory at its end.
+func (s *FingerprintStore) ReadPackageAsTar(src io.Reader, cachedResources []ResourceRecord, basePath string) io.ReadCloser {
+	return readPackageAsTar(src, cachedResources, basePath, s)
+}
+
+func readPackageAsTar(src io.Reader, cachedResources []ResourceRecord, basePath string, fingerprints *FingerprintStore) io.ReadCloser {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tw := tar.NewWriter(pw)
+		var err error
+		if src != nil {
+			err = writeZipToTar(tw, src, basePath, fingerprints)
+		}
+		if err == nil {
+			err = writeCachedResourcesToTar(tw, cachedResources, basePath, fingerprints)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return &zipTarReader{PipeReader: pr, done: done}
+}
+
+type zipTarReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+func (r *zipTarReader) Close() error {
+	err := r.PipeReader.Close()
+	<-r.done
+	return err
+}
+
+func writeZipToTar(tw *tar.Writer, src io.Reader, basePath string, fingerprints *FingerprintStore) error {
+	spool, err := ioutil.TempFile("", "package-upload-")
+	if err != nil {
+		return fmt.Errorf("error from ioutil.TempFile: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, src)
+	if err != nil {
+		return fmt.Errorf("error reading zip archive: %w", err)
+	}
+
+	zipReader, err := zip.NewReader(spool, size)
+	if err != nil {
+		return fmt.Errorf("error reading zip archive: %w", err)
+	}
+	for _, file := range zipReader.File {
+		if err := writeZipEntryToTar(tw, file, basePath, fingerprints); err != nil {
+			return fmt.Errorf("error reading zip entry %q: %w", file.Name, err)
+		}
+	}
+	return nil
+}
+
+func writeZipEntryToTar(tw *tar.Writer, file *zip.File, basePath string, fingerprints *FingerprintStore) error {
+	contents, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer contents.Close()
+
+	tarHeader := &tar.Header{
+		Name:     path.Join(basePath, file.Name),
+		Mode:     zipEntryMode(file),
+		ModTime:  archive.NormalizedDateTime,
+		Typeflag: tar.TypeReg,
+		Size:     int64(file.UncompressedSize64),
+	}
+	switch {
+	case file.Mode().IsDir() || strings.HasSuffix(file.Name, "/"):
+		tarHeader.Typeflag = tar.TypeDir
+		tarHeader.Size = 0
+		return tw.WriteHeader(tarHeader)
+
+	case file.Mode()&os.ModeSymlink != 0:
+		target, err := ioutil.ReadAll(contents)
+		if err != nil {
+			return err
+		}
+		tarHeader.Typeflag = tar.TypeSymlink
+		tarHeader.Linkname = string(target)
+		tarHeader.Size = 0
+		return tw.WriteHeader(tarHeader)
+	}
+
+	if err := tw.WriteHeader(tarHeader); err != nil {
+		return err
+	}
+
+	// the zip reader checks the size and checksum of the entry once it has been read
+	var writer io.Writer = tw
+	recorder := fingerprints.newFingerprintWriter(tarHeader.Size)
+	if recorder != nil {
+		writer = io.MultiWriter(tw, recorder)
+	}
+	_, err = io.Copy(writer, contents)
+	if err != nil {
+		if recorder != nil {
+			recorder.discard()
+		}
+		return err
+	}
+	if recorder != nil {
+		recorder.commit()
+	}
+	return nil
+}
+
+// zipEntryMode returns the permissions of a zip entry. Entries written by tools without Unix permissions get
+// sourceFileMode, as their attributes can't tell whether a file is executable.
+func zipEntryMode(file *zip.File) int64 {
+	switch file.CreatorVersion >> 8 {
+	case zipCreatorUnix, zipCreatorMacOSX:
+		if perm := file.Mode().Perm(); perm != 0 {
+			return int64(perm)
+		}
+	}
+	return sourceFileMode
+}
+
+func writeCachedResourcesToTar(tw *tar.Writer, cachedResources []ResourceRecord, basePath string, fingerprints *FingerprintStore) error {
+	for _, resource := range cachedResources {
+		if err := writeCachedResourceToTar(tw, resource, basePath, fingerprints); err != nil {
+			return fmt.Errorf("error adding cached resource %q: %w", resource.Path, err)
+		}
+	}
+	return nil
+}
+
+func writeCachedResourceToTar(tw *tar.Writer, resource ResourceRecord, basePath string, fingerprints *FingerprintStore) error {
+	file, err := fingerprints.open(resource)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:     path.Join(basePath, resource.Path),
+		Mode:     resource.fileMode(),
+		ModTime:  archive.NormalizedDateTime,
+		Typeflag: tar.TypeReg,
+		Size:     resource.SizeInBytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, file)
+	return err
+}
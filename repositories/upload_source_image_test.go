@@ -0,0 +1,171 @@
+package repositories_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+
+	. "code.cloudfoundry.org/cf-k8s-api/repositories"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadZipAsTar", func() {
+	type tarEntry struct {
+		Name     string
+		Typeflag byte
+		Mode     int64
+		Linkname string
+		Contents string
+	}
+
+	var (
+		zipBytes []byte
+		entries  []tarEntry
+		readErr  error
+	)
+
+	JustBeforeEach(func() {
+		entries = nil
+		tarReader := ReadZipAsTar(bytes.NewReader(zipBytes), "/")
+		defer tarReader.Close()
+
+		tr := tar.NewReader(tarReader)
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+			contents, err := io.ReadAll(tr)
+			Expect(err).NotTo(HaveOccurred())
+			entries = append(entries, tarEntry{Name: header.Name, Typeflag: header.Typeflag, Mode: header.Mode, Linkname: header.Linkname, Contents: string(contents)})
+		}
+	})
+
+	BeforeEach(func() {
+		readErr = nil
+	})
+
+	When("the zip is written as a stream, with the sizes of entries after their data", func() {
+		BeforeEach(func() {
+			var b bytes.Buffer
+			writer := zip.NewWriter(&b)
+			_, err := writer.Create("app/")
+			Expect(err).NotTo(HaveOccurred())
+			file, err := writer.Create("app/main.rb")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = file.Write(bytes.Repeat([]byte("puts 'hi'\n"), 1000))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = writer.Create("app/empty")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+			zipBytes = b.Bytes()
+		})
+
+		It("converts every entry", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(entries).To(Equal([]tarEntry{
+				{Name: "/app", Typeflag: tar.TypeDir, Mode: 0755},
+				{Name: "/app/main.rb", Typeflag: tar.TypeReg, Mode: 0755, Contents: string(bytes.Repeat([]byte("puts 'hi'\n"), 1000))},
+				{Name: "/app/empty", Typeflag: tar.TypeReg, Mode: 0755},
+			}))
+		})
+	})
+
+	When("the entries have Unix permissions", func() {
+		BeforeEach(func() {
+			zipBytes = zipWithModes(map[string]os.FileMode{
+				"bin/":      os.ModeDir | 0700,
+				"bin/start": 0755,
+				"Procfile":  0644,
+				"current":   os.ModeSymlink | 0777,
+			}, zip.Deflate)
+		})
+
+		It("keeps the modes of the entries", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(entries).To(ConsistOf(
+				tarEntry{Name: "/bin", Typeflag: tar.TypeDir, Mode: 0700},
+				tarEntry{Name: "/bin/start", Typeflag: tar.TypeReg, Mode: 0755, Contents: "contents of bin/start"},
+				tarEntry{Name: "/Procfile", Typeflag: tar.TypeReg, Mode: 0644, Contents: "contents of Procfile"},
+				tarEntry{Name: "/current", Typeflag: tar.TypeSymlink, Mode: 0777, Linkname: "contents of current"},
+			))
+		})
+	})
+
+	When("the entries are stored uncompressed, with their sizes after their data", func() {
+		BeforeEach(func() {
+			zipBytes = zipWithModes(map[string]os.FileMode{"Procfile": 0644}, zip.Store)
+		})
+
+		It("converts every entry", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(entries).To(Equal([]tarEntry{
+				{Name: "/Procfile", Typeflag: tar.TypeReg, Mode: 0644, Contents: "contents of Procfile"},
+			}))
+		})
+	})
+
+	When("the contents of an entry don't match its checksum", func() {
+		BeforeEach(func() {
+			zipBytes = zipWithModes(map[string]os.FileMode{"Procfile": 0644}, zip.Store)
+			checksum := make([]byte, 4)
+			binary.LittleEndian.PutUint32(checksum, crc32.ChecksumIEEE([]byte("contents of Procfile")))
+			zipBytes = bytes.ReplaceAll(zipBytes, checksum, []byte{42, 0, 0, 0})
+		})
+
+		It("returns an error", func() {
+			Expect(readErr).To(MatchError(`error reading zip entry "Procfile": zip: checksum error`))
+		})
+	})
+
+	When("the upload is not a zip", func() {
+		BeforeEach(func() {
+			zipBytes = []byte("not a zip file")
+		})
+
+		It("returns an error", func() {
+			Expect(readErr).To(MatchError("error reading zip archive: zip: not a valid zip file"))
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	When("the zip is empty", func() {
+		BeforeEach(func() {
+			var b bytes.Buffer
+			Expect(zip.NewWriter(&b).Close()).To(Succeed())
+			zipBytes = b.Bytes()
+		})
+
+		It("returns an empty tar", func() {
+			Expect(readErr).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+})
+
+// zipWithModes returns a zip of entries with the given modes, whose contents name them
+func zipWithModes(modes map[string]os.FileMode, method uint16) []byte {
+	var b bytes.Buffer
+	writer := zip.NewWriter(&b)
+	for name, mode := range modes {
+		header := &zip.FileHeader{Name: name, Method: method}
+		header.SetMode(mode)
+		file, err := writer.CreateHeader(header)
+		Expect(err).NotTo(HaveOccurred())
+		if !mode.IsDir() {
+			_, err = file.Write([]byte("contents of " + name))
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+	Expect(writer.Close()).To(Succeed())
+	return b.Bytes()
+}
@@ -0,0 +1,70 @@
+package integration_test
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/cf-k8s-api/repositories"
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("BuildCRClient", func() {
+	var (
+		ctx        context.Context
+		namespace  string
+		authHeader string
+		userClient client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		namespace = uuid.NewString()
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+
+		authHeader = "Bearer " + authProvider.GenerateJWTToken("alice")
+
+		var err error
+		userClient, err = repositories.BuildCRClient(k8sConfig, authHeader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("is forbidden from reading resources the user has no role for", func() {
+		Eventually(func() bool {
+			err := userClient.List(ctx, &corev1.SecretList{}, client.InNamespace(namespace))
+			return k8serrors.IsForbidden(err)
+		}).Should(BeTrue())
+	})
+
+	When("the user is bound to a role in the namespace", func() {
+		BeforeEach(func() {
+			role := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret-reader", Namespace: namespace},
+				Rules: []rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"secrets"},
+					Verbs:     []string{"list"},
+				}},
+			}
+			Expect(k8sClient.Create(ctx, role)).To(Succeed())
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "alice-secret-reader", Namespace: namespace},
+				Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: oidcPrefix + "alice"}},
+				RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: role.Name},
+			}
+			Expect(k8sClient.Create(ctx, roleBinding)).To(Succeed())
+		})
+
+		It("can read the resources allowed by the role", func() {
+			Eventually(func() error {
+				return userClient.List(ctx, &corev1.SecretList{}, client.InNamespace(namespace))
+			}).Should(Succeed())
+		})
+	})
+})